@@ -20,6 +20,7 @@ import (
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
 	"github.com/frain-dev/convoy/internal/pkg/middleware"
+	"github.com/frain-dev/convoy/queue"
 	redisqueue "github.com/frain-dev/convoy/queue/redis"
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -54,10 +55,11 @@ const (
 )
 
 type ApplicationHandler struct {
-	Router http.Handler
-	rm     *requestmigrations.RequestMigration
-	A      *types.APIOptions
-	cfg    config.Configuration
+	Router       http.Handler
+	rm           *requestmigrations.RequestMigration
+	A            *types.APIOptions
+	cfg          config.Configuration
+	backpressure *queue.Backpressure
 }
 
 func NewApplicationHandler(a *types.APIOptions) (*ApplicationHandler, error) {
@@ -70,6 +72,13 @@ func NewApplicationHandler(a *types.APIOptions) (*ApplicationHandler, error) {
 
 	appHandler.cfg = cfg
 
+	if bp := cfg.QueueBackpressure; bp.IsEnabled {
+		if q, ok := a.Queue.(*redisqueue.RedisQueue); ok {
+			depth := func() (int, error) { return q.Depth(convoy.CreateEventQueue) }
+			appHandler.backpressure = queue.NewBackpressure(depth, bp.HighWaterMark, bp.GetLowWaterMark(), bp.GetCheckInterval())
+		}
+	}
+
 	az, err := authz.NewAuthz(&authz.AuthzOpts{
 		AuthCtxKey: authz.AuthCtxType(middleware.AuthUserCtx),
 	})
@@ -130,9 +139,17 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 	router.Route("/ingest", func(ingestRouter chi.Router) {
 		ingestRouter.Use(middleware.RateLimiterHandler(a.A.Rate, a.cfg.ApiRateLimit))
 		ingestRouter.Get("/{maskID}", a.HandleCrcCheck)
-		ingestRouter.Post("/{maskID}", a.IngestEvent)
+		ingestRouter.With(middleware.QueueBackpressure(a.backpressure)).Post("/{maskID}", a.IngestEvent)
 	})
 
+	// Inspection sink, endpoints pointed here have test deliveries captured.
+	if a.cfg.InspectionSink.IsEnabled {
+		router.Route("/inspect", func(inspectRouter chi.Router) {
+			inspectRouter.Use(middleware.RateLimiterHandler(a.A.Rate, a.cfg.ApiRateLimit))
+			inspectRouter.Post("/{token}", a.InspectDelivery)
+		})
+	}
+
 	// Public API.
 	router.Route("/api", func(v1Router chi.Router) {
 		v1Router.Route("/v1", func(r chi.Router) {
@@ -173,10 +190,10 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 							eventRouter.Get("/countbatchreplayevents", handler.CountAffectedEvents)
 
 							// TODO(all): should the InstrumentPath change?
-							eventRouter.With(handler.RequireEnabledProject(), middleware.InstrumentPath(a.A.Licenser)).Post("/", handler.CreateEndpointEvent)
-							eventRouter.With(handler.RequireEnabledProject(), middleware.InstrumentPath(a.A.Licenser)).Post("/fanout", handler.CreateEndpointFanoutEvent)
-							eventRouter.With(handler.RequireEnabledProject(), middleware.InstrumentPath(a.A.Licenser)).Post("/broadcast", handler.CreateBroadcastEvent)
-							eventRouter.With(handler.RequireEnabledProject(), middleware.InstrumentPath(a.A.Licenser)).Post("/dynamic", handler.CreateDynamicEvent)
+							eventRouter.With(handler.RequireEnabledProject(), handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/", handler.CreateEndpointEvent)
+							eventRouter.With(handler.RequireEnabledProject(), handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/fanout", handler.CreateEndpointFanoutEvent)
+							eventRouter.With(handler.RequireEnabledProject(), handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/broadcast", handler.CreateBroadcastEvent)
+							eventRouter.With(handler.RequireEnabledProject(), handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/dynamic", handler.CreateDynamicEvent)
 							eventRouter.With(handler.RequireEnabledProject()).Post("/batchreplay", handler.BatchReplayEvents)
 
 							eventRouter.Route("/{eventID}", func(eventSubRouter chi.Router) {
@@ -194,14 +211,20 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 						eventTypesRouter.With(handler.RequireEnabledProject()).Post("/{eventTypeId}/deprecate", handler.DeprecateEventType)
 					})
 
+					projectSubRouter.Get("/inspections", handler.GetInspectedDeliveries)
+
 					projectSubRouter.Route("/eventdeliveries", func(eventDeliveryRouter chi.Router) {
 						eventDeliveryRouter.With(middleware.Pagination).Get("/", handler.GetEventDeliveriesPaged)
 						eventDeliveryRouter.With(handler.RequireEnabledProject()).Post("/forceresend", handler.ForceResendEventDeliveries)
 						eventDeliveryRouter.With(handler.RequireEnabledProject()).Post("/batchretry", handler.BatchRetryEventDelivery)
+						eventDeliveryRouter.With(handler.RequireEnabledProject()).Post("/plans", handler.CreatePlannedEventDeliveries)
+						eventDeliveryRouter.Get("/stream", handler.StreamEventDeliveries)
 
 						eventDeliveryRouter.Route("/{eventDeliveryID}", func(eventDeliverySubRouter chi.Router) {
 							eventDeliverySubRouter.Get("/", handler.GetEventDelivery)
+							eventDeliverySubRouter.Get("/document", handler.GetEventDeliveryDocument)
 							eventDeliverySubRouter.With(handler.RequireEnabledProject()).Put("/resend", handler.ResendEventDelivery)
+							eventDeliverySubRouter.With(handler.RequireEnabledProject()).Put("/replay", handler.ReplayEventDelivery)
 
 							eventDeliverySubRouter.Route("/deliveryattempts", func(deliveryRouter chi.Router) {
 								deliveryRouter.Get("/", handler.GetDeliveryAttempts)
@@ -218,6 +241,8 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 						subscriptionRouter.With(handler.RequireEnabledProject()).Delete("/{subscriptionID}", handler.DeleteSubscription)
 						subscriptionRouter.Get("/{subscriptionID}", handler.GetSubscription)
 						subscriptionRouter.With(handler.RequireEnabledProject()).Put("/{subscriptionID}", handler.UpdateSubscription)
+						subscriptionRouter.With(handler.RequireEnabledProject()).Put("/{subscriptionID}/pause", handler.PauseSubscription)
+						subscriptionRouter.With(handler.RequireEnabledProject()).Put("/{subscriptionID}/resume", handler.ResumeSubscription)
 						subscriptionRouter.Put("/{subscriptionID}/toggle_status", handler.ToggleSubscriptionStatus)
 
 						// Filter routes
@@ -393,11 +418,15 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 							eventDeliveryRouter.With(middleware.Pagination).Get("/", handler.GetEventDeliveriesPaged)
 							eventDeliveryRouter.With(handler.RequireEnabledProject()).Post("/forceresend", handler.ForceResendEventDeliveries)
 							eventDeliveryRouter.With(handler.RequireEnabledProject()).Post("/batchretry", handler.BatchRetryEventDelivery)
+							eventDeliveryRouter.With(handler.RequireEnabledProject()).Post("/plans", handler.CreatePlannedEventDeliveries)
 							eventDeliveryRouter.Get("/countbatchretryevents", handler.CountAffectedEventDeliveries)
+							eventDeliveryRouter.Get("/stream", handler.StreamEventDeliveries)
 
 							eventDeliveryRouter.Route("/{eventDeliveryID}", func(eventDeliverySubRouter chi.Router) {
 								eventDeliverySubRouter.Get("/", handler.GetEventDelivery)
+								eventDeliverySubRouter.Get("/document", handler.GetEventDeliveryDocument)
 								eventDeliverySubRouter.With(handler.RequireEnabledProject()).Put("/resend", handler.ResendEventDelivery)
+								eventDeliverySubRouter.With(handler.RequireEnabledProject()).Put("/replay", handler.ReplayEventDelivery)
 
 								eventDeliverySubRouter.Route("/deliveryattempts", func(deliveryRouter chi.Router) {
 									deliveryRouter.Get("/", handler.GetDeliveryAttempts)
@@ -414,6 +443,8 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 							subscriptionRouter.With(handler.RequireEnabledProject()).Delete("/{subscriptionID}", handler.DeleteSubscription)
 							subscriptionRouter.Get("/{subscriptionID}", handler.GetSubscription)
 							subscriptionRouter.With(handler.RequireEnabledProject()).Put("/{subscriptionID}", handler.UpdateSubscription)
+							subscriptionRouter.With(handler.RequireEnabledProject()).Put("/{subscriptionID}/pause", handler.PauseSubscription)
+							subscriptionRouter.With(handler.RequireEnabledProject()).Put("/{subscriptionID}/resume", handler.ResumeSubscription)
 
 							// Filter routes
 							subscriptionRouter.Route("/{subscriptionID}/filters", func(filterRouter chi.Router) {
@@ -562,7 +593,7 @@ func (a *ApplicationHandler) BuildControlPlaneRoutes() *chi.Mux {
 	}
 
 	if a.A.Licenser.CanExportPrometheusMetrics() {
-		router.HandleFunc("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{Registry: metrics.Reg()}).ServeHTTP)
+		router.HandleFunc("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{Registry: metrics.Reg(), EnableOpenMetrics: true}).ServeHTTP)
 	}
 
 	router.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -580,7 +611,7 @@ func (a *ApplicationHandler) BuildDataPlaneRoutes() *chi.Mux {
 	router := a.buildRouter()
 
 	if a.A.Licenser.CanExportPrometheusMetrics() {
-		router.HandleFunc("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{Registry: metrics.Reg()}).ServeHTTP)
+		router.HandleFunc("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{Registry: metrics.Reg(), EnableOpenMetrics: true}).ServeHTTP)
 	}
 
 	router.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -591,7 +622,7 @@ func (a *ApplicationHandler) BuildDataPlaneRoutes() *chi.Mux {
 	router.Route("/ingest", func(ingestRouter chi.Router) {
 		ingestRouter.Use(middleware.RateLimiterHandler(a.A.Rate, a.cfg.ApiRateLimit))
 		ingestRouter.Get("/{maskID}", a.HandleCrcCheck)
-		ingestRouter.Post("/{maskID}", a.IngestEvent)
+		ingestRouter.With(middleware.QueueBackpressure(a.backpressure)).Post("/{maskID}", a.IngestEvent)
 	})
 
 	handler := &handlers.Handler{A: a.A, RM: a.rm}
@@ -607,10 +638,10 @@ func (a *ApplicationHandler) BuildDataPlaneRoutes() *chi.Mux {
 				projectRouter.Use(middleware.RateLimiterHandler(a.A.Rate, a.cfg.ApiRateLimit))
 				projectRouter.Route("/{projectID}", func(projectSubRouter chi.Router) {
 					projectSubRouter.Route("/events", func(eventRouter chi.Router) {
-						eventRouter.With(middleware.InstrumentPath(a.A.Licenser)).Post("/", handler.CreateEndpointEvent)
-						eventRouter.With(middleware.InstrumentPath(a.A.Licenser)).Post("/fanout", handler.CreateEndpointFanoutEvent)
-						eventRouter.With(middleware.InstrumentPath(a.A.Licenser)).Post("/broadcast", handler.CreateBroadcastEvent)
-						eventRouter.With(middleware.InstrumentPath(a.A.Licenser)).Post("/dynamic", handler.CreateDynamicEvent)
+						eventRouter.With(handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/", handler.CreateEndpointEvent)
+						eventRouter.With(handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/fanout", handler.CreateEndpointFanoutEvent)
+						eventRouter.With(handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/broadcast", handler.CreateBroadcastEvent)
+						eventRouter.With(handler.RequireProjectIngestRate(), middleware.QueueBackpressure(a.backpressure), middleware.InstrumentPath(a.A.Licenser)).Post("/dynamic", handler.CreateDynamicEvent)
 						eventRouter.With(middleware.Pagination).Get("/", handler.GetEventsPaged)
 						eventRouter.Post("/batchreplay", handler.BatchReplayEvents)
 
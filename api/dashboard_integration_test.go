@@ -1589,6 +1589,22 @@ func (s *EventIntegrationTestSuite) Test_GetEventDeliveriesPaged() {
 	}
 }
 
+func (s *EventIntegrationTestSuite) Test_GetEventDeliveriesPaged_InvalidCursor() {
+	url := fmt.Sprintf("/ui/organisations/%s/projects/%s/eventdeliveries?sortField=created_at&next_page_cursor=not-a-cursor", s.DefaultProject.OrganisationID, s.DefaultProject.UID)
+	req := createRequest(http.MethodGet, url, "", nil)
+
+	err := s.AuthenticatorFn(req, s.Router)
+	require.NoError(s.T(), err)
+
+	w := httptest.NewRecorder()
+
+	// Act.
+	s.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(s.T(), http.StatusBadRequest, w.Code)
+}
+
 func TestEventIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(EventIntegrationTestSuite))
 }
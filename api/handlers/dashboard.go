@@ -217,7 +217,7 @@ func (h *Handler) computeDashboardMessages(ctx context.Context, projectID string
 	var messagesSent uint64
 
 	eventDeliveryRepo := postgres.NewEventDeliveryRepo(h.A.DB)
-	messages, err := eventDeliveryRepo.LoadEventDeliveriesIntervals(ctx, projectID, searchParams, period, endpointIds)
+	messages, err := eventDeliveryRepo.LoadEventDeliveriesIntervals(ctx, projectID, searchParams, period, endpointIds, false, time.Monday)
 	if err != nil {
 		log.FromContext(ctx).WithError(err).Error("failed to load message intervals - ")
 		return 0, nil, err
@@ -281,9 +281,10 @@ func (h *Handler) ReplayEndpointEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rs := services.ReplayEventService{
-		EndpointRepo: postgres.NewEndpointRepo(h.A.DB),
-		Queue:        h.A.Queue,
-		Event:        event,
+		EndpointRepo:      postgres.NewEndpointRepo(h.A.DB),
+		EventDeliveryRepo: postgres.NewEventDeliveryRepo(h.A.DB),
+		Queue:             h.A.Queue,
+		Event:             event,
 	}
 
 	err = rs.Run(r.Context())
@@ -331,10 +332,11 @@ func (h *Handler) BatchReplayEvents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	bs := services.BatchReplayEventService{
-		EndpointRepo: postgres.NewEndpointRepo(h.A.DB),
-		Queue:        h.A.Queue,
-		EventRepo:    postgres.NewEventRepo(h.A.DB),
-		Filter:       data.Filter,
+		EndpointRepo:      postgres.NewEndpointRepo(h.A.DB),
+		EventDeliveryRepo: postgres.NewEventDeliveryRepo(h.A.DB),
+		Queue:             h.A.Queue,
+		EventRepo:         postgres.NewEventRepo(h.A.DB),
+		Filter:            data.Filter,
 	}
 
 	successes, failures, err := bs.Run(r.Context())
@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/frain-dev/convoy/api/models"
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/pkg/middleware"
+	"github.com/frain-dev/convoy/internal/pkg/sse"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -42,6 +46,74 @@ func (h *Handler) GetEventDelivery(w http.ResponseWriter, r *http.Request) {
 		resp, http.StatusOK))
 }
 
+// GetEventDeliveryDocument
+//
+//	@Id				GetEventDeliveryDocument
+//	@Summary		Retrieve the full document of an event delivery
+//	@Description	This endpoint fetches the whole document of an event delivery, for deliveries to subscriptions with a diff key that only carried what changed.
+//	@Tags			Event Deliveries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string	true	"Project ID"
+//	@Param			eventDeliveryID	path		string	true	"event delivery id"
+//	@Success		200				{object}	util.ServerResponse{data=Stub}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/eventdeliveries/{eventDeliveryID}/document [get]
+func (h *Handler) GetEventDeliveryDocument(w http.ResponseWriter, r *http.Request) {
+	eventDelivery, err := h.retrieveEventDelivery(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
+		return
+	}
+
+	var document json.RawMessage
+	if eventDelivery.Metadata != nil {
+		document = eventDelivery.Metadata.FullDocument
+		if len(document) == 0 {
+			document = eventDelivery.Metadata.Data
+		}
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Event delivery document fetched successfully",
+		document, http.StatusOK))
+}
+
+// StreamEventDeliveries
+//
+//	@Id				StreamEventDeliveries
+//	@Summary		Stream event delivery status changes
+//	@Description	This endpoint streams event delivery status changes for a project as server-sent events.
+//	@Tags			Event Deliveries
+//	@Produce		text/event-stream
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Success		200			{object}	sse.DeliveryStatusUpdate
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/eventdeliveries/stream [get]
+func (h *Handler) StreamEventDeliveries(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	pubsub := h.A.Redis.Subscribe(r.Context(), sse.Channel(project.UID))
+	defer pubsub.Close()
+
+	stream := sse.NewStream()
+	go func() {
+		for msg := range pubsub.Channel() {
+			stream.Push([]byte(msg.Payload))
+		}
+	}()
+
+	err = stream.Serve(w, r)
+	if err != nil {
+		log.FromContext(r.Context()).WithError(err).Error("failed to stream event delivery updates")
+	}
+}
+
 // ResendEventDelivery
 //
 //	@Id				ResendEventDelivery
@@ -88,6 +160,51 @@ func (h *Handler) ResendEventDelivery(w http.ResponseWriter, r *http.Request) {
 		resp, http.StatusOK))
 }
 
+// ReplayEventDelivery
+//
+//	@Id				ReplayEventDelivery
+//	@Summary		Replay dead-lettered event delivery
+//	@Description	This endpoint replays a failed event delivery with its retries reset, subject to the project's dead letter replay policy.
+//	@Tags			Event Deliveries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string	true	"Project ID"
+//	@Param			eventDeliveryID	path		string	true	"event delivery id"
+//	@Success		200				{object}	util.ServerResponse{data=models.EventDeliveryResponse}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/eventdeliveries/{eventDeliveryID}/replay [put]
+func (h *Handler) ReplayEventDelivery(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	eventDelivery, err := h.retrieveEventDelivery(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	rs := services.ReplayDeadLetterService{
+		EventDeliveryRepo: postgres.NewEventDeliveryRepo(h.A.DB),
+		Queue:             h.A.Queue,
+		EventDelivery:     eventDelivery,
+		Project:           project,
+	}
+
+	err = rs.Run(r.Context())
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := &models.EventDeliveryResponse{EventDelivery: eventDelivery}
+	_ = render.Render(w, r, util.NewServerResponse("Event delivery replayed successfully",
+		resp, http.StatusOK))
+}
+
 // BatchRetryEventDelivery
 //
 //	@Summary		Batch retry event delivery
@@ -98,6 +215,7 @@ func (h *Handler) ResendEventDelivery(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			projectID	path		string							true	"Project ID"
 //	@Param			request		query		models.QueryListEventDelivery	false	"Query Params"
+//	@Param			allowStale	query		bool							false	"Retry deliveries older than 72 hours from the dashboard"
 //	@Success		200			{object}	util.ServerResponse{data=Stub}
 //	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
 //	@Security		ApiKeyAuth
@@ -153,12 +271,24 @@ func (h *Handler) BatchRetryEventDelivery(w http.ResponseWriter, r *http.Request
 		ProjectID:         project.UID,
 	}
 
+	// like bulk resends, batch retries from the dashboard leave old
+	// deliveries alone unless asked not to
+	if (h.IsReqWithJWT(authUser) || h.IsReqWithPortalLinkToken(authUser)) && r.URL.Query().Get("allowStale") != "true" {
+		br.MaxAge = services.DefaultRequeueMaxAge
+	}
+
 	err = br.Run(r.Context())
 	if err != nil {
 		_ = render.Render(w, r, util.NewServiceErrResponse(err))
 		return
 	}
 
+	if br.MaxAge > 0 {
+		msg := fmt.Sprintf("Batch retry processing, deliveries older than %s are skipped", br.MaxAge)
+		_ = render.Render(w, r, util.NewServerResponse(msg, nil, http.StatusOK))
+		return
+	}
+
 	_ = render.Render(w, r, util.NewServerResponse("Batch retry processing", nil, http.StatusOK))
 }
 
@@ -199,15 +329,83 @@ func (h *Handler) ForceResendEventDeliveries(w http.ResponseWriter, r *http.Requ
 		Project:           project,
 	}
 
+	// bulk retries from the dashboard don't resend old deliveries by
+	// accident, they have to be asked for
+	authUser := middleware.GetAuthUserFromContext(r.Context())
+	if (h.IsReqWithJWT(authUser) || h.IsReqWithPortalLinkToken(authUser)) && !eventDeliveryIDs.AllowStale {
+		fr.MaxAge = services.DefaultRequeueMaxAge
+	}
+
 	successes, failures, err := fr.Run(r.Context())
 	if err != nil {
 		_ = render.Render(w, r, util.NewServiceErrResponse(err))
 		return
 	}
 
+	if len(fr.Skipped) > 0 {
+		msg := fmt.Sprintf("%d successful, %d failed, %d skipped as older than %s", successes, failures, len(fr.Skipped), fr.MaxAge)
+		_ = render.Render(w, r, util.NewServerResponse(msg, fr.Skipped, http.StatusOK))
+		return
+	}
+
 	_ = render.Render(w, r, util.NewServerResponse(fmt.Sprintf("%d successful, %d failed", successes, failures), nil, http.StatusOK))
 }
 
+// CreatePlannedEventDeliveries
+//
+//	@Summary		Create event deliveries from a plan
+//	@Description	This endpoint creates event deliveries for precomputed (event, endpoints) plans without matching the events against subscriptions
+//	@Id				CreatePlannedEventDeliveries
+//	@Tags			Event Deliveries
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string					true	"Project ID"
+//	@Param			plans		body		models.DeliveryPlans	true	"Delivery plans"
+//	@Success		201			{object}	util.ServerResponse{data=Stub}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/eventdeliveries/plans [post]
+func (h *Handler) CreatePlannedEventDeliveries(w http.ResponseWriter, r *http.Request) {
+	var plans models.DeliveryPlans
+	err := util.ReadJSON(r, &plans)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	err = plans.Validate()
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	cp := services.CreatePlannedDeliveriesService{
+		EventRepo:         postgres.NewEventRepo(h.A.DB),
+		EndpointRepo:      postgres.NewEndpointRepo(h.A.DB),
+		SubRepo:           postgres.NewSubscriptionRepo(h.A.DB),
+		EventDeliveryRepo: postgres.NewEventDeliveryRepo(h.A.DB),
+		DeviceRepo:        postgres.NewDeviceRepo(h.A.DB),
+		Queue:             h.A.Queue,
+		Licenser:          h.A.Licenser,
+		Plans:             plans.Plans,
+		Project:           project,
+	}
+
+	planned, err := cp.Run(r.Context())
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse(fmt.Sprintf("%d event deliveries planned", planned), nil, http.StatusCreated))
+}
+
 // GetEventDeliveriesPaged
 //
 //	@Summary		List all event deliveries
@@ -272,8 +470,19 @@ func (h *Handler) GetEventDeliveriesPaged(w http.ResponseWriter, r *http.Request
 
 	f := data.Filter
 
-	ed, paginationData, err := postgres.NewEventDeliveryRepo(h.A.DB).LoadEventDeliveriesPaged(r.Context(), project.UID, f.EndpointIDs, f.EventID, f.SubscriptionID, f.Status, f.SearchParams, f.Pageable, f.IdempotencyKey, f.EventType)
+	readPreference, err := h.eventDeliveriesReadPreference(r.Context(), project.UID, f.EventID)
 	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	ed, paginationData, err := postgres.NewEventDeliveryRepo(h.A.DB).LoadEventDeliveriesPaged(r.Context(), project.UID, f.EndpointIDs, f.EventID, f.SubscriptionID, f.Status, f.SearchParams, f.Pageable, f.IdempotencyKey, f.EventType, f.ResponseStatusCodes, readPreference)
+	if err != nil {
+		if errors.Is(err, datastore.ErrInvalidCursor) {
+			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+			return
+		}
+
 		log.FromContext(r.Context()).WithError(err).Error("failed to fetch event deliveries")
 		_ = render.Render(w, r, util.NewErrorResponse("an error occurred while fetching event deliveries", http.StatusInternalServerError))
 		return
@@ -287,6 +496,27 @@ func (h *Handler) GetEventDeliveriesPaged(w http.ResponseWriter, r *http.Request
 		models.PagedResponse{Content: resp, Pagination: &paginationData}, http.StatusOK))
 }
 
+// eventDeliveriesReadPreference reads an event's deliveries from the primary
+// within the read after write window of the event's creation, when replicas
+// may not have its deliveries yet
+func (h *Handler) eventDeliveriesReadPreference(ctx context.Context, projectID, eventID string) (datastore.ReadPreference, error) {
+	window := time.Duration(h.A.Cfg.Database.ReadAfterWriteWindow) * time.Second
+	if util.IsStringEmpty(eventID) || window <= 0 {
+		return datastore.PreferReplica, nil
+	}
+
+	event, err := postgres.NewEventRepo(h.A.DB).FindEventByID(ctx, projectID, eventID)
+	if err != nil {
+		// the event may be too recent to have reached the replica the lookup used
+		if errors.Is(err, datastore.ErrEventNotFound) {
+			return datastore.PreferPrimary, nil
+		}
+		return "", err
+	}
+
+	return datastore.ReadPreferenceAfterWrite(event.CreatedAt, window), nil
+}
+
 func (h *Handler) CountAffectedEventDeliveries(w http.ResponseWriter, r *http.Request) {
 	var q *models.QueryListEventDelivery
 
@@ -325,7 +555,7 @@ func (h *Handler) CountAffectedEventDeliveries(w http.ResponseWriter, r *http.Re
 	}
 
 	f := data.Filter
-	count, err := postgres.NewEventDeliveryRepo(h.A.DB).CountEventDeliveries(r.Context(), project.UID, f.EndpointIDs, f.EventID, f.Status, f.SearchParams)
+	count, err := postgres.NewEventDeliveryRepo(h.A.DB).CountEventDeliveries(r.Context(), project.UID, f.EndpointIDs, nil, f.EventID, f.Status, f.SearchParams)
 	if err != nil {
 		log.FromContext(r.Context()).WithError(err).Error("an error occurred while fetching event deliveries")
 		_ = render.Render(w, r, util.NewServiceErrResponse(err))
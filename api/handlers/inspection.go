@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/frain-dev/convoy/services"
+	"github.com/frain-dev/convoy/util"
+	"github.com/go-chi/render"
+)
+
+const defaultInspectionLimit = 20
+
+// GetInspectedDeliveries
+//
+//	@Summary		List captured test deliveries
+//	@Description	This endpoint fetches the last deliveries captured by the inspection URL of a project in test mode
+//	@Id				GetInspectedDeliveries
+//	@Tags			Projects
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		string	true	"Project ID"
+//	@Param			limit		query		int		false	"How many captures to return, latest first"
+//	@Success		200			{object}	util.ServerResponse{data=[]services.InspectedDelivery}
+//	@Failure		400,401,404	{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/inspections [get]
+func (h *Handler) GetInspectedDeliveries(w http.ResponseWriter, r *http.Request) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if project.Config == nil || !project.Config.TestMode {
+		_ = render.Render(w, r, util.NewErrorResponse("project is not in test mode", http.StatusBadRequest))
+		return
+	}
+
+	limit := defaultInspectionLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			_ = render.Render(w, r, util.NewErrorResponse("limit must be a positive number", http.StatusBadRequest))
+			return
+		}
+	}
+
+	captures, err := services.NewInspectionSink(h.A.Cache, h.A.Cfg.InspectionSink).Recent(r.Context(), project.UID, limit)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Inspected deliveries fetched successfully", captures, http.StatusOK))
+}
@@ -2,13 +2,22 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/limiter"
+	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
+	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/util"
 	"github.com/go-chi/render"
 )
 
-var ErrProjectDisabled = errors.New("this project has been disabled for write operations until you re-subscribe your convoy instance")
+var (
+	ErrProjectDisabled                = errors.New("this project has been disabled for write operations until you re-subscribe your convoy instance")
+	ErrProjectIngestRateLimitExceeded = errors.New("project ingest rate limit exceeded")
+)
 
 func (h *Handler) RequireEnabledProject() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -28,3 +37,62 @@ func (h *Handler) RequireEnabledProject() func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// RequireProjectIngestRate rejects events sent to a project over its ingest
+// rate limit, see AllowProjectIngest
+func (h *Handler) RequireProjectIngestRate() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := h.retrieveProject(r)
+			if err != nil {
+				_ = render.Render(w, r, util.NewErrorResponse("failed to retrieve project", http.StatusBadRequest))
+				return
+			}
+
+			if !AllowProjectIngest(w, r, h.A.Rate, p) {
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func projectIngestRateLimitKey(projectID string) string {
+	return fmt.Sprintf("ingest:%s", projectID)
+}
+
+// AllowProjectIngest takes one event from the project's ingest rate limit,
+// it is kept apart from the instance's so a noisy project doesn't use up the
+// others' ingest rate. It reports whether the event may be ingested, when it
+// may not it has already responded with a 429 and a Retry-After of when the
+// project's bucket lets the next event through. Projects without an ingest
+// rate limit are never limited, and the event is let through when the limiter
+// can't be reached so ingestion doesn't depend on it.
+func AllowProjectIngest(w http.ResponseWriter, r *http.Request, rateLimiter limiter.RateLimiter, project *datastore.Project) bool {
+	if project.Config == nil || project.Config.IngestRateLimit == 0 {
+		return true
+	}
+
+	err := rateLimiter.Allow(r.Context(), projectIngestRateLimitKey(project.UID), int(project.Config.IngestRateLimit))
+	if err == nil {
+		return true
+	}
+
+	if !errors.Is(rlimiter.GetRawError(err), rlimiter.ErrRateLimitExceeded) {
+		log.FromContext(r.Context()).WithError(err).Errorf("failed to check the ingest rate limit of project %s", project.UID)
+		return true
+	}
+
+	retryAfter := int(math.Ceil(rlimiter.GetRetryAfter(err).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", project.Config.IngestRateLimit))
+	w.Header().Set("X-RateLimit-Remaining", "0")
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
+
+	_ = render.Render(w, r, util.NewErrorResponse(ErrProjectIngestRateLimitExceeded.Error(), http.StatusTooManyRequests))
+	return false
+}
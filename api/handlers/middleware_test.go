@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAllowProjectIngest(t *testing.T) {
+	limited := &datastore.Project{UID: "project-1", Config: &datastore.ProjectConfig{IngestRateLimit: 10}}
+
+	tests := []struct {
+		name           string
+		project        *datastore.Project
+		mockFn         func(rl *mocks.MockRateLimiter)
+		wantAllowed    bool
+		wantRetryAfter string
+	}{
+		{
+			name:        "should_not_limit_projects_without_a_limit",
+			project:     &datastore.Project{UID: "project-2", Config: &datastore.ProjectConfig{}},
+			wantAllowed: true,
+		},
+		{
+			name:    "should_allow_events_within_the_limit",
+			project: limited,
+			mockFn: func(rl *mocks.MockRateLimiter) {
+				rl.EXPECT().Allow(gomock.Any(), "ingest:project-1", 10).Return(nil)
+			},
+			wantAllowed: true,
+		},
+		{
+			name:    "should_reject_events_over_the_limit",
+			project: limited,
+			mockFn: func(rl *mocks.MockRateLimiter) {
+				rl.EXPECT().Allow(gomock.Any(), "ingest:project-1", 10).
+					Return(rlimiter.NewRedisLimiterError(1500*time.Millisecond, rlimiter.ErrRateLimitExceeded))
+			},
+			wantRetryAfter: "2",
+		},
+		{
+			name:    "should_allow_events_when_the_limiter_fails",
+			project: limited,
+			mockFn: func(rl *mocks.MockRateLimiter) {
+				rl.EXPECT().Allow(gomock.Any(), "ingest:project-1", 10).Return(errors.New("connection refused"))
+			},
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rl := mocks.NewMockRateLimiter(ctrl)
+			if tt.mockFn != nil {
+				tt.mockFn(rl)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/ingest/mask", nil)
+
+			allowed := AllowProjectIngest(w, r, rl, tt.project)
+			require.Equal(t, tt.wantAllowed, allowed)
+
+			if tt.wantAllowed {
+				require.Empty(t, w.Header().Get("Retry-After"))
+				return
+			}
+
+			require.Equal(t, http.StatusTooManyRequests, w.Code)
+			require.Equal(t, tt.wantRetryAfter, w.Header().Get("Retry-After"))
+			require.Contains(t, w.Body.String(), ErrProjectIngestRateLimitExceeded.Error())
+		})
+	}
+}
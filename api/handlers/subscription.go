@@ -388,6 +388,66 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	_ = render.Render(w, r, util.NewServerResponse("Subscription updated successfully", resp, http.StatusAccepted))
 }
 
+// PauseSubscription
+//
+//	@Summary		Pause subscription
+//	@Description	Pauses a subscription, deliveries of a paused subscription are held until it is resumed
+//	@Id				PauseSubscription
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string	true	"Project ID"
+//	@Param			subscriptionID	path		string	true	"subscription id"
+//	@Success		202				{object}	util.ServerResponse{data=models.SubscriptionResponse}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/subscriptions/{subscriptionID}/pause [put]
+func (h *Handler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	h.setSubscriptionPaused(w, r, true)
+}
+
+// ResumeSubscription
+//
+//	@Summary		Resume subscription
+//	@Description	Resumes a paused subscription, the deliveries held while it was paused are sent again
+//	@Id				ResumeSubscription
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		string	true	"Project ID"
+//	@Param			subscriptionID	path		string	true	"subscription id"
+//	@Success		202				{object}	util.ServerResponse{data=models.SubscriptionResponse}
+//	@Failure		400,401,404		{object}	util.ServerResponse{data=Stub}
+//	@Security		ApiKeyAuth
+//	@Router			/v1/projects/{projectID}/subscriptions/{subscriptionID}/resume [put]
+func (h *Handler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	h.setSubscriptionPaused(w, r, false)
+}
+
+func (h *Handler) setSubscriptionPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	project, err := h.retrieveProject(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	ps := services.PauseSubscriptionService{
+		SubRepo:        postgres.NewSubscriptionRepo(h.A.DB),
+		ProjectID:      project.UID,
+		SubscriptionID: chi.URLParam(r, "subscriptionID"),
+		Paused:         paused,
+	}
+
+	sub, err := ps.Run(r.Context())
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	resp := models.SubscriptionResponse{Subscription: sub}
+	_ = render.Render(w, r, util.NewServerResponse("Subscription status updated successfully", resp, http.StatusAccepted))
+}
+
 func (h *Handler) ToggleSubscriptionStatus(w http.ResponseWriter, r *http.Request) {
 	// For backward compatibility
 	_ = render.Render(w, r, util.NewServerResponse("Subscription status updated successfully", nil, http.StatusAccepted))
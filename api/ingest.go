@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/pkg/verifier"
 	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
 	"github.com/frain-dev/convoy/worker/task"
 	"github.com/go-chi/render"
@@ -60,6 +62,16 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// requests to a paused source don't use up the project's ingest rate
+	if source.Paused {
+		_ = render.Render(w, r, util.NewErrorResponse("source is paused", http.StatusForbidden))
+		return
+	}
+
+	if !handlers.AllowProjectIngest(w, r, a.A.Rate, project) {
+		return
+	}
+
 	if source.Type != datastore.HTTPSource {
 		_ = render.Render(w, r, util.NewErrorResponse("Source type needs to be HTTP",
 			http.StatusBadRequest))
@@ -126,6 +138,19 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 		maxIngestSize = cfg.MaxResponseSize
 	}
 
+	maxEventAge, err := getMaxEventAge(project)
+	if err != nil {
+		a.A.Logger.WithError(err).Error("failed to load config")
+		_ = render.Render(w, r, util.NewServiceErrResponse(util.NewServiceError(http.StatusInternalServerError, errors.New("failed to load config"))))
+		return
+	}
+
+	// stale events are rejected before they are queued for fan-out
+	if err = checkEventAge(r, maxEventAge, time.Now()); err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
 	// The Content-Length header indicates the size of the message body, in bytes, sent to the recipient.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Content-Length
 	// We use this to check the size of the request content, this is to ensure that we return the appropriate
@@ -158,6 +183,13 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 	// Return 400 Bad Request.
 	payload, err := extractPayloadFromIngestEventReq(r, maxIngestSize)
 	if err != nil {
+		// anyone can post a malformed payload or a bad signature, so only
+		// senders that authenticate count towards pausing the source. The
+		// payload can't be signed over here, so only verifiers that don't
+		// sign it, like basic auth and api keys, authenticate the sender.
+		if v.VerifyRequest(r, nil) == nil {
+			a.recordSourceIngestFailure(r, source, project, err)
+		}
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
 		return
 	}
@@ -167,6 +199,13 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if source.ConsecutiveIngestFailures > 0 {
+		err = postgres.NewSourceRepo(a.A.DB).ResetSourceIngestFailures(r.Context(), project.UID, source.UID)
+		if err != nil {
+			a.A.Logger.WithError(err).Error("failed to reset source ingest failures")
+		}
+	}
+
 	if len(payload) == 0 {
 		payload = []byte("{}")
 	}
@@ -237,6 +276,79 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// recordSourceIngestFailure counts an authenticated request to source that
+// failed with reason, pausing the source once it reaches its ingest failure
+// threshold.
+// The request is rejected either way, so errors are only logged.
+func (a *ApplicationHandler) recordSourceIngestFailure(r *http.Request, source *datastore.Source, project *datastore.Project, reason error) {
+	rf := services.RecordSourceIngestFailureService{
+		SourceRepo:       postgres.NewSourceRepo(a.A.DB),
+		SubscriptionRepo: postgres.NewSubscriptionRepo(a.A.DB),
+		EndpointRepo:     postgres.NewEndpointRepo(a.A.DB),
+		Queue:            a.A.Queue,
+		Project:          project,
+		Source:           source,
+		Reason:           reason,
+	}
+
+	if err := rf.Run(r.Context()); err != nil {
+		a.A.Logger.WithError(err).Error("failed to record source ingest failure")
+	}
+}
+
+// eventTimestampHeader is where sources declare when an event happened, as a
+// unix timestamp in seconds or an RFC 3339 time.
+const eventTimestampHeader = "X-Convoy-Event-Timestamp"
+
+var (
+	errEventTooOld           = errors.New("event is older than the max event age")
+	errInvalidEventTimestamp = errors.New("invalid event timestamp")
+)
+
+// getMaxEventAge returns the project's max event age, falling back to the
+// instance's. Zero means events of any age are accepted.
+func getMaxEventAge(project *datastore.Project) (time.Duration, error) {
+	if project.Config != nil && project.Config.MaxEventAgeSeconds != 0 {
+		return time.Duration(project.Config.MaxEventAgeSeconds) * time.Second, nil
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(cfg.MaxEventAgeSeconds) * time.Second, nil
+}
+
+// checkEventAge rejects requests whose declared event timestamp is older than
+// maxAge. Events that don't declare a timestamp are accepted.
+func checkEventAge(r *http.Request, maxAge time.Duration, now time.Time) error {
+	if maxAge == 0 {
+		return nil
+	}
+
+	value := strings.TrimSpace(r.Header.Get(eventTimestampHeader))
+	if util.IsStringEmpty(value) {
+		return nil
+	}
+
+	var declared time.Time
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		declared = time.Unix(seconds, 0)
+	} else {
+		declared, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errInvalidEventTimestamp, value)
+		}
+	}
+
+	if now.Sub(declared) > maxAge {
+		return errEventTooOld
+	}
+
+	return nil
+}
+
 const (
 	applicationJsonContentType   = "application/json"
 	multipartFormDataContentType = "multipart/form-data"
@@ -7,9 +7,13 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
 	"github.com/stretchr/testify/require"
 )
 
@@ -77,3 +81,81 @@ func Test_extractPayloadFromIngestEventReq(t *testing.T) {
 		require.Equal(t, jsonBody, payload)
 	})
 }
+
+func Test_checkEventAge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		maxAge    time.Duration
+		wantErr   error
+	}{
+		{
+			name:      "should_accept_a_recent_event",
+			timestamp: strconv.FormatInt(now.Add(-time.Minute).Unix(), 10),
+			maxAge:    time.Hour,
+		},
+		{
+			name:      "should_accept_a_recent_rfc3339_timestamp",
+			timestamp: now.Add(-time.Minute).Format(time.RFC3339),
+			maxAge:    time.Hour,
+		},
+		{
+			name:      "should_reject_an_event_that_is_too_old",
+			timestamp: strconv.FormatInt(now.Add(-2*time.Hour).Unix(), 10),
+			maxAge:    time.Hour,
+			wantErr:   errEventTooOld,
+		},
+		{
+			name:      "should_reject_an_old_rfc3339_timestamp",
+			timestamp: now.Add(-2 * time.Hour).Format(time.RFC3339),
+			maxAge:    time.Hour,
+			wantErr:   errEventTooOld,
+		},
+		{
+			name:      "should_accept_old_events_without_a_max_age",
+			timestamp: strconv.FormatInt(now.Add(-24*time.Hour).Unix(), 10),
+		},
+		{
+			name:   "should_accept_an_event_without_a_timestamp",
+			maxAge: time.Hour,
+		},
+		{
+			name:      "should_reject_an_invalid_timestamp",
+			timestamp: "yesterday",
+			maxAge:    time.Hour,
+			wantErr:   errInvalidEventTimestamp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+			if tt.timestamp != "" {
+				req.Header.Set(eventTimestampHeader, tt.timestamp)
+			}
+
+			err := checkEventAge(req, tt.maxAge, now)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_getMaxEventAge(t *testing.T) {
+	err := config.LoadConfig("")
+	require.NoError(t, err)
+
+	maxAge, err := getMaxEventAge(&datastore.Project{Config: &datastore.ProjectConfig{MaxEventAgeSeconds: 60}})
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, maxAge)
+
+	maxAge, err = getMaxEventAge(&datastore.Project{Config: &datastore.ProjectConfig{}})
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), maxAge)
+}
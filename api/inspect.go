@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/services"
+	"github.com/frain-dev/convoy/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// InspectDelivery captures a delivery made to a project's inspection URL,
+// an endpoint pointed at /inspect/{token} of a project in test mode has its
+// deliveries kept for a while and listed by GetInspectedDeliveries instead
+// of sent elsewhere.
+func (a *ApplicationHandler) InspectDelivery(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	project, err := postgres.NewProjectRepo(a.A.DB).FetchProjectByInspectionToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, datastore.ErrProjectNotFound) {
+			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusNotFound))
+			return
+		}
+		_ = render.Render(w, r, util.NewErrorResponse("error retrieving project", http.StatusBadRequest))
+		return
+	}
+
+	maxSize := int64(a.cfg.MaxResponseSize)
+	if r.ContentLength > maxSize {
+		_ = render.Render(w, r, util.NewErrorResponse("request body too large", http.StatusRequestEntityTooLarge))
+		return
+	}
+
+	// a chunked body has no content length, so read one byte past the limit
+	// to tell a body that fits from one that doesn't
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if int64(len(body)) > maxSize {
+		_ = render.Render(w, r, util.NewErrorResponse("request body too large", http.StatusRequestEntityTooLarge))
+		return
+	}
+
+	d := &services.InspectedDelivery{
+		ProjectID: project.UID,
+		Method:    r.Method,
+		Headers:   r.Header.Clone(),
+		Body:      string(body),
+	}
+
+	err = services.NewInspectionSink(a.A.Cache, a.cfg.InspectionSink).Capture(r.Context(), d)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Delivery captured", d, http.StatusOK))
+}
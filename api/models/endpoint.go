@@ -1,6 +1,9 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
 	"strings"
 
@@ -9,6 +12,10 @@ import (
 	"github.com/frain-dev/convoy/util"
 )
 
+// maxHttpTimeout caps how long (in seconds) a delivery to an endpoint can
+// take, so one slow endpoint can't hold a worker for too long.
+const maxHttpTimeout = 300
+
 type CreateEndpoint struct {
 	// URL is the endpoint's URL prefixed with https. non-https urls are currently
 	// not supported.
@@ -29,6 +36,31 @@ type CreateEndpoint struct {
 	// -- simple or advanced. If left unspecified, we default to false.
 	AdvancedSignatures *bool `json:"advanced_signatures"`
 
+	// BodyFormat controls how event payloads are sent to the endpoint. raw sends the
+	// payload as is, envelope wraps it as {"metadata": {...}, "data": {...}} and signs
+	// the envelope. If left unspecified, we default to raw.
+	BodyFormat datastore.EndpointBodyFormat `json:"body_format" valid:"optional,in(raw|envelope)~unsupported body format"`
+
+	// HttpMethod is the HTTP method webhooks are sent to the endpoint with, one of
+	// POST, PUT or PATCH. If left unspecified, we default to POST.
+	HttpMethod string `json:"http_method" valid:"optional,in(POST|PUT|PATCH)~unsupported http method"`
+
+	// ContentType is the content type webhooks are sent to the endpoint with, e.g.
+	// a vendor media type. Payloads are form encoded for
+	// application/x-www-form-urlencoded and sent as JSON for any other. If left
+	// unspecified, we default to application/json.
+	ContentType string `json:"content_type"`
+
+	// FormEncoding controls how payloads are form encoded. fields sends each
+	// top-level field as a form field, payload sends the whole payload as JSON in a
+	// payload field. If left unspecified, we default to fields.
+	FormEncoding datastore.EndpointFormEncoding `json:"form_encoding" valid:"optional,in(fields|payload)~unsupported form encoding"`
+
+	// PinnedSignatureVersion restricts the signature header to one of the project's
+	// signature versions (1 for v1, 2 for v2...), e.g. while the endpoint migrates
+	// between versions. If left unspecified, all versions are sent.
+	PinnedSignatureVersion int `json:"pinned_signature_version"`
+
 	// Endpoint name.
 	Name string `json:"name" valid:"required~please provide your endpoint name"`
 
@@ -53,6 +85,21 @@ type CreateEndpoint struct {
 	// Rate limit duration specifies the time range for the rate limit.
 	RateLimitDuration uint64 `json:"rate_limit_duration" copier:"-"`
 
+	// MaxConcurrentDeliveries is the most deliveries that can be in flight to the
+	// endpoint at once. If left unspecified, deliveries aren't capped.
+	MaxConcurrentDeliveries int `json:"max_concurrent_deliveries"`
+
+	// MinDeliveryIntervalMs is the least time in milliseconds between the start of two
+	// deliveries to the endpoint, deliveries that come sooner are deferred. If left
+	// unspecified, deliveries aren't spaced.
+	MinDeliveryIntervalMs uint64 `json:"min_delivery_interval_ms"`
+
+	// RetryAfterFloorSeconds and RetryAfterCeilingSeconds bound the retry delay a
+	// Retry-After header from the endpoint can set, e.g. so a 24h Retry-After isn't
+	// obeyed blindly. If left unspecified, that side isn't bounded.
+	RetryAfterFloorSeconds   uint64 `json:"retry_after_floor_seconds"`
+	RetryAfterCeilingSeconds uint64 `json:"retry_after_ceiling_seconds"`
+
 	// This is used to define any custom authentication required by the endpoint. This
 	// shouldn't be needed often because webhook endpoints usually should be exposed to
 	// the internet.
@@ -63,6 +110,22 @@ type CreateEndpoint struct {
 }
 
 func (cE *CreateEndpoint) Validate() error {
+	if cE.MaxConcurrentDeliveries < 0 {
+		return errors.New("max concurrent deliveries cannot be negative")
+	}
+
+	if cE.HttpTimeout > maxHttpTimeout {
+		return fmt.Errorf("http timeout cannot be above %d seconds", maxHttpTimeout)
+	}
+
+	if cE.RetryAfterCeilingSeconds > 0 && cE.RetryAfterFloorSeconds > cE.RetryAfterCeilingSeconds {
+		return errors.New("retry after floor cannot be above its ceiling")
+	}
+
+	if err := validateContentType(cE.ContentType); err != nil {
+		return err
+	}
+
 	return util.Validate(cE)
 }
 
@@ -86,6 +149,31 @@ type UpdateEndpoint struct {
 	// -- simple or advanced. If left unspecified, we default to false.
 	AdvancedSignatures *bool `json:"advanced_signatures"`
 
+	// BodyFormat controls how event payloads are sent to the endpoint. raw sends the
+	// payload as is, envelope wraps it as {"metadata": {...}, "data": {...}} and signs
+	// the envelope. If left unspecified, we default to raw.
+	BodyFormat datastore.EndpointBodyFormat `json:"body_format" valid:"optional,in(raw|envelope)~unsupported body format"`
+
+	// HttpMethod is the HTTP method webhooks are sent to the endpoint with, one of
+	// POST, PUT or PATCH. If left unspecified, we default to POST.
+	HttpMethod string `json:"http_method" valid:"optional,in(POST|PUT|PATCH)~unsupported http method"`
+
+	// ContentType is the content type webhooks are sent to the endpoint with, e.g.
+	// a vendor media type. Payloads are form encoded for
+	// application/x-www-form-urlencoded and sent as JSON for any other. If left
+	// unspecified, we default to application/json.
+	ContentType string `json:"content_type"`
+
+	// FormEncoding controls how payloads are form encoded. fields sends each
+	// top-level field as a form field, payload sends the whole payload as JSON in a
+	// payload field. If left unspecified, we default to fields.
+	FormEncoding datastore.EndpointFormEncoding `json:"form_encoding" valid:"optional,in(fields|payload)~unsupported form encoding"`
+
+	// PinnedSignatureVersion restricts the signature header to one of the project's
+	// signature versions (1 for v1, 2 for v2...), e.g. while the endpoint migrates
+	// between versions. Set it to 0 to send all versions again.
+	PinnedSignatureVersion *int `json:"pinned_signature_version"`
+
 	// Endpoint name.
 
 	Name *string `json:"name" valid:"required~please provide your endpointName"`
@@ -111,6 +199,19 @@ type UpdateEndpoint struct {
 	// Rate limit duration specifies the time range for the rate limit.
 	RateLimitDuration uint64 `json:"rate_limit_duration" copier:"-"`
 
+	// MaxConcurrentDeliveries is the most deliveries that can be in flight to the
+	// endpoint at once. Set it to 0 to stop capping deliveries.
+	MaxConcurrentDeliveries *int `json:"max_concurrent_deliveries"`
+
+	// MinDeliveryIntervalMs is the least time in milliseconds between the start of two
+	// deliveries to the endpoint. Set it to 0 to stop spacing deliveries.
+	MinDeliveryIntervalMs *uint64 `json:"min_delivery_interval_ms"`
+
+	// RetryAfterFloorSeconds and RetryAfterCeilingSeconds bound the retry delay a
+	// Retry-After header from the endpoint can set. Set either to 0 to unbound that side.
+	RetryAfterFloorSeconds   *uint64 `json:"retry_after_floor_seconds"`
+	RetryAfterCeilingSeconds *uint64 `json:"retry_after_ceiling_seconds"`
+
 	// This is used to define any custom authentication required by the endpoint. This
 	// shouldn't be needed often because webhook endpoints usually should be exposed to
 	// the internet.
@@ -118,9 +219,34 @@ type UpdateEndpoint struct {
 }
 
 func (uE *UpdateEndpoint) Validate() error {
+	if uE.MaxConcurrentDeliveries != nil && *uE.MaxConcurrentDeliveries < 0 {
+		return errors.New("max concurrent deliveries cannot be negative")
+	}
+
+	if uE.HttpTimeout > maxHttpTimeout {
+		return fmt.Errorf("http timeout cannot be above %d seconds", maxHttpTimeout)
+	}
+
+	if err := validateContentType(uE.ContentType); err != nil {
+		return err
+	}
+
 	return util.Validate(uE)
 }
 
+func validateContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	_, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return errors.New("please provide a valid content type")
+	}
+
+	return nil
+}
+
 type QueryListEndpoint struct {
 	// The name of the endpoint
 	Name string `json:"q" example:"endpoint-1"`
@@ -145,8 +271,9 @@ func (q *QueryListEndpoint) Transform(r *http.Request) *QueryListEndpointRespons
 }
 
 type EndpointAuthentication struct {
-	Type   datastore.EndpointAuthenticationType `json:"type,omitempty" valid:"optional,in(api_key)~unsupported authentication type"`
-	ApiKey *ApiKey                              `json:"api_key"`
+	Type       datastore.EndpointAuthenticationType `json:"type,omitempty" valid:"optional,in(api_key|digest)~unsupported authentication type"`
+	ApiKey     *ApiKey                              `json:"api_key"`
+	DigestAuth *DigestAuth                          `json:"digest_auth"`
 }
 
 func (ea *EndpointAuthentication) Transform() *datastore.EndpointAuthentication {
@@ -155,8 +282,25 @@ func (ea *EndpointAuthentication) Transform() *datastore.EndpointAuthentication
 	}
 
 	return &datastore.EndpointAuthentication{
-		Type:   ea.Type,
-		ApiKey: ea.ApiKey.transform(),
+		Type:       ea.Type,
+		ApiKey:     ea.ApiKey.transform(),
+		DigestAuth: ea.DigestAuth.transform(),
+	}
+}
+
+type DigestAuth struct {
+	Username string `json:"username" valid:"required"`
+	Password string `json:"password" valid:"required"`
+}
+
+func (da *DigestAuth) transform() *datastore.DigestAuth {
+	if da == nil {
+		return nil
+	}
+
+	return &datastore.DigestAuth{
+		Username: da.Username,
+		Password: da.Password,
 	}
 }
 
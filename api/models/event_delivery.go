@@ -1,7 +1,10 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/frain-dev/convoy/datastore"
 	m "github.com/frain-dev/convoy/internal/pkg/middleware"
@@ -21,6 +24,41 @@ var defaultPageable datastore.Pageable = datastore.Pageable{
 type IDs struct {
 	// A list of event delivery IDs to forcefully resend.
 	IDs []string `json:"ids"`
+
+	// Resend deliveries from the dashboard however old they are, by default
+	// deliveries older than 72 hours are skipped.
+	AllowStale bool `json:"allow_stale"`
+}
+
+type DeliveryPlans struct {
+	// Precomputed deliveries to create, they are not matched against subscriptions.
+	Plans []DeliveryPlan `json:"plans"`
+}
+
+type DeliveryPlan struct {
+	// The event to deliver
+	EventID string `json:"event_id"`
+
+	// The endpoints the event is delivered to
+	EndpointIDs []string `json:"endpoint_ids"`
+}
+
+func (d *DeliveryPlans) Validate() error {
+	if len(d.Plans) == 0 {
+		return errors.New("please provide at least one delivery plan")
+	}
+
+	for _, plan := range d.Plans {
+		if util.IsStringEmpty(plan.EventID) {
+			return errors.New("please provide an event id for every delivery plan")
+		}
+
+		if len(plan.EndpointIDs) == 0 {
+			return fmt.Errorf("please provide the endpoint ids event %s is delivered to", plan.EventID)
+		}
+	}
+
+	return nil
 }
 
 type QueryListEventDelivery struct {
@@ -42,6 +80,9 @@ type QueryListEventDelivery struct {
 	// A list of event delivery statuses to filter by
 	Status []string `json:"status"`
 
+	// A list of HTTP status codes to filter by, matched against each delivery's latest attempt
+	ResponseStatusCodes []int `json:"responseStatusCode"`
+
 	SearchParams
 	Pageable
 }
@@ -56,6 +97,11 @@ func (ql *QueryListEventDelivery) Transform(r *http.Request) (*QueryListEventDel
 		return nil, err
 	}
 
+	responseStatusCodes, err := getResponseStatusCodes(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &QueryListEventDeliveryResponse{
 		Filter: &datastore.Filter{
 			EndpointIDs:    getEndpointIDs(r),
@@ -66,10 +112,31 @@ func (ql *QueryListEventDelivery) Transform(r *http.Request) (*QueryListEventDel
 			Status:         getEventDeliveryStatus(r),
 			Pageable:       m.GetPageableFromContext(r.Context()),
 			SearchParams:   searchParams,
+
+			ResponseStatusCodes: responseStatusCodes,
 		},
 	}, nil
 }
 
+func getResponseStatusCodes(r *http.Request) ([]int, error) {
+	codes := make([]int, 0)
+
+	for _, c := range r.URL.Query()["responseStatusCode"] {
+		if util.IsStringEmpty(c) {
+			continue
+		}
+
+		code, err := strconv.Atoi(c)
+		if err != nil || code < 100 || code > 599 {
+			return nil, errors.New("please specify valid response status codes, e.g. responseStatusCode=429")
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
 func getEventDeliveryStatus(r *http.Request) []datastore.EventDeliveryStatus {
 	status := make([]datastore.EventDeliveryStatus, 0)
 
@@ -7,6 +7,7 @@ import (
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/util"
 	"github.com/lib/pq"
+	"gopkg.in/guregu/null.v4"
 )
 
 type CreateProject struct {
@@ -74,6 +75,52 @@ type ProjectConfig struct {
 	// MultipleEndpointSubscriptions is used to configure if multiple subscriptions
 	// can be created for the endpoint in a project
 	MultipleEndpointSubscriptions bool `json:"multiple_endpoint_subscriptions"`
+
+	// DeadLetterReplay is used to configure how the project's failed event deliveries can be replayed
+	DeadLetterReplay *DeadLetterReplayConfiguration `json:"dead_letter_replay"`
+
+	// ContentIdempotencyKeys controls if the X-Convoy-Idempotency-Key sent to endpoints is
+	// derived from the event's content instead of the idempotency key it was sent with, so
+	// the same content always gets the same key, even when it is ingested again.
+	ContentIdempotencyKeys bool `json:"content_idempotency_keys"`
+
+	// MaxEventAgeSeconds makes sources reject events whose X-Convoy-Event-Timestamp
+	// header is older than this many seconds. If left unspecified, the instance's
+	// max event age is used.
+	MaxEventAgeSeconds uint64 `json:"max_event_age_seconds"`
+
+	// MaxRetrySeconds caps the delay between retries of failed event deliveries, it
+	// cannot be greater than the instance's max retry seconds. If left unspecified,
+	// the instance's max retry seconds is used.
+	MaxRetrySeconds uint64 `json:"max_retry_seconds"`
+
+	// MetadataHeaders lists the event metadata headers sent with deliveries, any of
+	// event_id, event_type, delivery_id and subscription_id. They are sent as
+	// X-Convoy-Event-Id, X-Convoy-Event-Type, X-Convoy-Delivery-Id and
+	// X-Convoy-Subscription-Id respectively.
+	MetadataHeaders []string `json:"metadata_headers"`
+
+	// DeliveryMode is the delivery mode of the project's subscriptions that don't
+	// specify their own, one of at_least_once, at_most_once or ordered. If left unspecified,
+	// subscriptions deliver at least once.
+	DeliveryMode datastore.DeliveryMode `json:"delivery_mode"`
+
+	// IngestRateLimit caps how many events a second the project can ingest, events
+	// over it are rejected with a 429 without using up other projects' ingest rate.
+	// If left unspecified, the project's ingest rate isn't limited.
+	IngestRateLimit uint64 `json:"ingest_rate_limit"`
+
+	// MaxConcurrentDeliveries is the most deliveries that can be in flight for the
+	// project at once across all its endpoints, deliveries over it are rescheduled
+	// so one project can't take up every worker. If left unspecified, the
+	// project's in-flight deliveries aren't capped.
+	MaxConcurrentDeliveries uint64 `json:"max_concurrent_deliveries"`
+
+	// TestMode turns on the project's inspection URL, /inspect/{inspection_token},
+	// which captures what is delivered to it so test deliveries can be looked at
+	// without an external service. The token is returned in the project's config,
+	// and a new one is issued each time test mode is turned on.
+	TestMode bool `json:"test_mode"`
 }
 
 func (pc *ProjectConfig) Transform() *datastore.ProjectConfig {
@@ -93,6 +140,15 @@ func (pc *ProjectConfig) Transform() *datastore.ProjectConfig {
 		Strategy:                      pc.Strategy.transform(),
 		Signature:                     pc.Signature.transform(),
 		MetaEvent:                     pc.MetaEvent.transform(),
+		DeadLetterReplay:              pc.DeadLetterReplay.transform(),
+		ContentIdempotencyKeys:        pc.ContentIdempotencyKeys,
+		MaxEventAgeSeconds:            pc.MaxEventAgeSeconds,
+		MaxRetrySeconds:               pc.MaxRetrySeconds,
+		MetadataHeaders:               pc.MetadataHeaders,
+		DeliveryMode:                  pc.DeliveryMode,
+		IngestRateLimit:               pc.IngestRateLimit,
+		MaxConcurrentDeliveries:       pc.MaxConcurrentDeliveries,
+		TestMode:                      pc.TestMode,
 	}
 }
 
@@ -110,6 +166,22 @@ func (r *SSLConfiguration) transform() *datastore.SSLConfiguration {
 	}
 }
 
+type DeadLetterReplayConfiguration struct {
+	Policy               string `json:"policy" valid:"optional,in(manual|auto|never)~unsupported dead letter replay policy"`
+	AutoReplayAfterHours uint64 `json:"auto_replay_after_hours"`
+}
+
+func (dc *DeadLetterReplayConfiguration) transform() *datastore.DeadLetterReplayConfiguration {
+	if dc == nil {
+		return nil
+	}
+
+	return &datastore.DeadLetterReplayConfiguration{
+		Policy:               datastore.DeadLetterReplayPolicy(dc.Policy),
+		AutoReplayAfterHours: dc.AutoReplayAfterHours,
+	}
+}
+
 type RateLimitConfiguration struct {
 	Count    int    `json:"count"`
 	Duration uint64 `json:"duration"`
@@ -127,6 +199,18 @@ type StrategyConfiguration struct {
 	Type       string `json:"type" valid:"optional~please provide a valid strategy type, in(linear|exponential)~unsupported strategy type"`
 	Duration   uint64 `json:"duration" valid:"optional~please provide a valid duration in seconds,int"`
 	RetryCount uint64 `json:"retry_count" valid:"optional~please provide a valid retry count,int"`
+
+	// BackoffBase is what the exponential strategy multiplies the interval
+	// by after every attempt, 0 falls back to 2
+	BackoffBase float64 `json:"backoff_base"`
+
+	// MaxInterval caps the seconds the exponential strategy waits between
+	// two attempts
+	MaxInterval uint64 `json:"max_interval"`
+
+	// JitterPercent randomly moves every retry's delay by up to this
+	// percent of it either way
+	JitterPercent uint64 `json:"jitter_percent"`
 }
 
 func (sc *StrategyConfiguration) transform() *datastore.StrategyConfiguration {
@@ -135,15 +219,45 @@ func (sc *StrategyConfiguration) transform() *datastore.StrategyConfiguration {
 	}
 
 	return &datastore.StrategyConfiguration{
-		Type:       datastore.StrategyProvider(sc.Type),
-		Duration:   sc.Duration,
-		RetryCount: sc.RetryCount,
+		Type:          datastore.StrategyProvider(sc.Type),
+		Duration:      sc.Duration,
+		RetryCount:    sc.RetryCount,
+		BackoffBase:   sc.BackoffBase,
+		MaxInterval:   sc.MaxInterval,
+		JitterPercent: sc.JitterPercent,
 	}
 }
 
 type SignatureConfiguration struct {
 	Header   config.SignatureHeaderProvider `json:"header,omitempty" valid:"required~please provide a valid signature header"`
 	Versions []SignatureVersion             `json:"versions"`
+
+	// UnsignedEventTypes lists event types delivered without a signature
+	UnsignedEventTypes []string `json:"unsigned_event_types"`
+
+	// ProxyURL is an external signing service deliveries are signed through,
+	// so the signing keys never leave it
+	ProxyURL string `json:"proxy_url" valid:"url~please provide a valid signing proxy url,optional"`
+
+	// SignRequestTarget signs the request's method, host and path along
+	// with its body, as METHOD\nHOST\nPATH\nBODY
+	SignRequestTarget bool `json:"sign_request_target"`
+
+	// SignQueryParams signs the query params deliveries are sent with along with
+	// the body, sorted by name and then value so their order doesn't matter.
+	SignQueryParams bool `json:"sign_query_params"`
+
+	// SecretEpochHeader sends the endpoint secret's rotation epoch, which goes up
+	// by one each time the secret is rotated, in the X-Convoy-Secret-Epoch header.
+	SecretEpochHeader bool `json:"secret_epoch_header"`
+
+	// SignSecretEpoch signs the secret's rotation epoch along with the body, as
+	// EPOCH\nBODY, and sends it in the X-Convoy-Secret-Epoch header.
+	SignSecretEpoch bool `json:"sign_secret_epoch"`
+
+	// MissingSecretPolicy is what happens to deliveries to an endpoint without
+	// a secret: fail fails them (the default), unsigned sends them unsigned.
+	MissingSecretPolicy datastore.MissingSecretPolicy `json:"missing_secret_policy" valid:"optional,in(fail|unsigned)~unsupported missing secret policy"`
 }
 
 func (sc *SignatureConfiguration) transform() *datastore.SignatureConfiguration {
@@ -151,13 +265,25 @@ func (sc *SignatureConfiguration) transform() *datastore.SignatureConfiguration
 		return nil
 	}
 
-	s := &datastore.SignatureConfiguration{Header: sc.Header}
+	s := &datastore.SignatureConfiguration{
+		Header:             sc.Header,
+		UnsignedEventTypes: sc.UnsignedEventTypes,
+		ProxyURL:           sc.ProxyURL,
+		SignRequestTarget:  sc.SignRequestTarget,
+		SignQueryParams:    sc.SignQueryParams,
+		SecretEpochHeader:  sc.SecretEpochHeader,
+		SignSecretEpoch:    sc.SignSecretEpoch,
+
+		MissingSecretPolicy: sc.MissingSecretPolicy,
+	}
 	for _, version := range sc.Versions {
 		s.Versions = append(s.Versions, datastore.SignatureVersion{
-			UID:       version.UID,
-			Hash:      version.Hash,
-			Encoding:  datastore.EncodingType(version.Encoding),
-			CreatedAt: version.CreatedAt,
+			UID:         version.UID,
+			Hash:        version.Hash,
+			Encoding:    datastore.EncodingType(version.Encoding),
+			CreatedAt:   version.CreatedAt,
+			ActiveFrom:  version.ActiveFrom,
+			ActiveUntil: version.ActiveUntil,
 		})
 	}
 
@@ -169,6 +295,11 @@ type SignatureVersion struct {
 	Hash      string    `json:"hash,omitempty" valid:"required~please provide a valid hash,supported_hash~unsupported hash type"`
 	Encoding  string    `json:"encoding" valid:"required~please provide a valid signature header"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	// ActiveFrom and ActiveUntil bound when deliveries are signed with this
+	// version, so an old and a new version can overlap during a rotation
+	ActiveFrom  null.Time `json:"active_from,omitempty"`
+	ActiveUntil null.Time `json:"active_until,omitempty"`
 }
 
 type MetaEventConfiguration struct {
@@ -45,6 +45,10 @@ type CreateSource struct {
 	// Function is a javascript function used to mutate the headers
 	// immediately after ingesting an event
 	HeaderFunction *string `json:"header_function"`
+
+	// IngestFailureThreshold pauses the source after this many ingested
+	// requests in a row fail verification or can't be read, 0 never does
+	IngestFailureThreshold uint64 `json:"ingest_failure_threshold"`
 }
 
 func (cs *CreateSource) Validate() error {
@@ -167,6 +171,14 @@ type UpdateSource struct {
 	// Function is a javascript function used to mutate the headers
 	// immediately after ingesting an event
 	HeaderFunction *string `json:"header_function"`
+
+	// IngestFailureThreshold pauses the source after this many ingested
+	// requests in a row fail verification or can't be read, 0 never does
+	IngestFailureThreshold *uint64 `json:"ingest_failure_threshold"`
+
+	// Paused is set to false to resume ingesting through a source that
+	// was paused
+	Paused *bool `json:"paused"`
 }
 
 func (us *UpdateSource) Validate() error {
@@ -207,6 +219,10 @@ type Pageable struct {
 
 	// A pagination cursor to fetch the next page of a list
 	NextCursor string `json:"next_page_cursor" example:"01H0JA5MEES38RRK3HTEJC647K"`
+
+	// The field the cursors paginate on, values are `id` or `created_at`, defaults to `id`.
+	// Only supported when listing event deliveries
+	SortField string `json:"sortField" example:"id | created_at"`
 }
 
 type QueryListSourceResponse struct {
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/frain-dev/convoy/datastore"
@@ -10,6 +13,16 @@ import (
 	"github.com/lib/pq"
 )
 
+// maxPayloadTransformLength caps how long a subscription's payload transform
+// can be, as longer ones can repeat the payload that many more times.
+const maxPayloadTransformLength = 1024
+
+// diffKeyPattern matches the diff keys deliveries can carry the entity key
+// of, plain dot separated paths to a field.
+var diffKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+var errInvalidDiffKey = errors.New("diff key must be a dot separated path to a field, e.g. data.id")
+
 type CreateSubscription struct {
 	// Subscription Nme
 	Name string `json:"name" valid:"required~please provide a valid subscription name"`
@@ -40,9 +53,36 @@ type CreateSubscription struct {
 
 	// Delivery mode configuration
 	DeliveryMode datastore.DeliveryMode `json:"delivery_mode,omitempty"`
+
+	// Source request headers to forward onto the event deliveries, e.g. Stripe-Signature.
+	// All source request headers are forwarded when this is empty
+	HeaderAllowList []string `json:"header_allow_list,omitempty"`
+
+	// PayloadTransform reshapes the event payload before it is signed and sent, using
+	// gjson path syntax, e.g. "data" unwraps a top-level data envelope. Deliveries whose
+	// payload it can't be applied to are failed instead of sent untransformed.
+	PayloadTransform string `json:"payload_transform,omitempty"`
+
+	// DiffKey is a dot separated path to the entity an event is about, e.g. "data.id". When it
+	// is set, deliveries carry only the fields changed since the previous event for the
+	// same entity and the entity key, as a JSON merge patch. The full document can be
+	// fetched from the delivery's document endpoint.
+	DiffKey string `json:"diff_key,omitempty"`
 }
 
 func (cs *CreateSubscription) Validate() error {
+	if len(cs.PayloadTransform) > maxPayloadTransformLength {
+		return fmt.Errorf("payload transform cannot be longer than %d characters", maxPayloadTransformLength)
+	}
+
+	if len(cs.DiffKey) > maxPayloadTransformLength {
+		return fmt.Errorf("diff key cannot be longer than %d characters", maxPayloadTransformLength)
+	}
+
+	if cs.DiffKey != "" && !diffKeyPattern.MatchString(cs.DiffKey) {
+		return errInvalidDiffKey
+	}
+
 	return util.Validate(cs)
 }
 
@@ -77,9 +117,33 @@ type UpdateSubscription struct {
 
 	// Delivery mode configuration
 	DeliveryMode datastore.DeliveryMode `json:"delivery_mode,omitempty"`
+
+	// Source request headers to forward onto the event deliveries, e.g. Stripe-Signature.
+	// All source request headers are forwarded when this is empty
+	HeaderAllowList []string `json:"header_allow_list,omitempty"`
+
+	// PayloadTransform reshapes the event payload before it is signed and sent, using
+	// gjson path syntax. Set it to an empty string to send payloads as is again.
+	PayloadTransform *string `json:"payload_transform,omitempty"`
+
+	// DiffKey is a dot separated path to the entity an event is about. Set it to an empty
+	// string to deliver whole documents again.
+	DiffKey *string `json:"diff_key,omitempty"`
 }
 
 func (us *UpdateSubscription) Validate() error {
+	if us.PayloadTransform != nil && len(*us.PayloadTransform) > maxPayloadTransformLength {
+		return fmt.Errorf("payload transform cannot be longer than %d characters", maxPayloadTransformLength)
+	}
+
+	if us.DiffKey != nil && len(*us.DiffKey) > maxPayloadTransformLength {
+		return fmt.Errorf("diff key cannot be longer than %d characters", maxPayloadTransformLength)
+	}
+
+	if us.DiffKey != nil && *us.DiffKey != "" && !diffKeyPattern.MatchString(*us.DiffKey) {
+		return errInvalidDiffKey
+	}
+
 	return util.Validate(us)
 }
 
@@ -956,6 +956,40 @@ func (s *PublicEventIntegrationTestSuite) Test_GetEventDelivery_Valid_EventDeliv
 	require.Equal(s.T(), eventDelivery.UID, respEventDelivery.UID)
 }
 
+func (s *PublicEventIntegrationTestSuite) Test_GetEventDeliveryDocument() {
+	// Just Before.
+	endpoint, _ := testdb.SeedEndpoint(s.ConvoyApp.A.DB, s.DefaultProject, ulid.Make().String(), "", "", false, datastore.ActiveEndpointStatus)
+	event, _ := testdb.SeedEvent(s.ConvoyApp.A.DB, endpoint, s.DefaultProject.UID, ulid.Make().String(), "*", "", []byte(`{}`))
+	subscription, err := testdb.SeedSubscription(s.ConvoyApp.A.DB, s.DefaultProject, ulid.Make().String(), datastore.OutgoingProject, &datastore.Source{}, endpoint, &datastore.RetryConfiguration{}, &datastore.AlertConfiguration{}, &datastore.FilterConfiguration{
+		EventTypes: []string{"*"},
+		Filter:     datastore.FilterSchema{Headers: datastore.M{}, Body: datastore.M{}},
+	})
+	require.NoError(s.T(), err)
+
+	eventDelivery, err := testdb.SeedEventDelivery(s.ConvoyApp.A.DB, event, endpoint, s.DefaultProject.UID, "", datastore.SuccessEventStatus, subscription)
+	require.NoError(s.T(), err)
+
+	// the delivery only carried what changed
+	eventDelivery.Metadata.Data = []byte(`{"id":"cus_1","plan":"pro"}`)
+	eventDelivery.Metadata.FullDocument = []byte(`{"id":"cus_1","name":"Ada","plan":"pro"}`)
+	err = postgres.NewEventDeliveryRepo(s.ConvoyApp.A.DB).UpdateEventDeliveryMetadata(context.Background(), s.DefaultProject.UID, eventDelivery)
+	require.NoError(s.T(), err)
+
+	url := fmt.Sprintf("/api/v1/projects/%s/eventdeliveries/%s/document", s.DefaultProject.UID, eventDelivery.UID)
+	req := createRequest(http.MethodGet, url, s.APIKey, nil)
+	w := httptest.NewRecorder()
+
+	// Act.
+	s.Router.ServeHTTP(w, req)
+
+	// Assert.
+	require.Equal(s.T(), http.StatusOK, w.Code)
+
+	var document json.RawMessage
+	parseResponse(s.T(), w.Result(), &document)
+	require.JSONEq(s.T(), `{"id":"cus_1","name":"Ada","plan":"pro"}`, string(document))
+}
+
 func (s *PublicEventIntegrationTestSuite) Test_GetEventDelivery_Valid_EventDelivery_RedirectToProjects() {
 	s.T().Skip("Deprecated Redirects")
 	//	eventDeliveryID := ulid.Make().String()
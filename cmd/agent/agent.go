@@ -69,11 +69,12 @@ func AddAgentCommand(a *cli.App) *cobra.Command {
 			// start sync configuration from the database.
 			go memorystore.DefaultStore.Sync(ctx, interval)
 
-			err = workerSrv.StartWorker(ctx, a, cfg, interval)
+			stopWorker, err := workerSrv.StartWorker(ctx, a, cfg, interval)
 			if err != nil {
 				a.Logger.Errorf("Error starting data plane worker component, err: %v", err)
 				return err
 			}
+			defer stopWorker()
 
 			err = ingestSrv.StartIngest(cmd.Context(), a, cfg, interval)
 			if err != nil {
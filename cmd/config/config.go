@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
 
+	"github.com/frain-dev/convoy/cmd/hooks"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/internal/pkg/cli"
 	"github.com/frain-dev/convoy/pkg/log"
@@ -21,19 +26,91 @@ func AddConfigCommand(a *cli.App) *cobra.Command {
 			"ShouldBootstrap": "false",
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			cfg, err := config.Get()
+			printConfig()
+		},
+	}
+
+	cmd.AddCommand(addShowCommand())
+
+	return cmd
+}
+
+func addShowCommand() *cobra.Command {
+	var effective bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "show outputs your instances computed configuration",
+		Annotations: map[string]string{
+			"CheckMigration":  "false",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !effective {
+				printConfig()
+				return nil
+			}
+
+			cfgPath, err := cmd.Flags().GetString("config")
 			if err != nil {
-				log.Fatalf("Error getting config: %v\n", err)
+				return err
 			}
 
-			data, err := json.MarshalIndent(cfg, "", "    ")
+			flags, err := hooks.BuildCliConfiguration(cmd)
 			if err != nil {
-				log.Fatalf("Error printing config: %v\n", err)
+				return err
 			}
 
-			fmt.Println(string(data))
+			values, err := config.Effective(cfgPath, flags)
+			if err != nil {
+				return err
+			}
+
+			return printEffectiveConfig(os.Stdout, values)
 		},
 	}
 
+	cmd.Flags().BoolVar(&effective, "effective", false, "Print every resolved value and whether it came from the defaults, config file, env or flags")
+
 	return cmd
 }
+
+func printConfig() {
+	cfg, err := config.Get()
+	if err != nil {
+		log.Fatalf("Error getting config: %v\n", err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		log.Fatalf("Error printing config: %v\n", err)
+	}
+
+	fmt.Println(string(data))
+}
+
+func printEffectiveConfig(out io.Writer, values []config.EffectiveValue) error {
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	_, err := fmt.Fprintln(w, "Key\tValue\tSource")
+	if err != nil {
+		return err
+	}
+
+	var value bytes.Buffer
+	enc := json.NewEncoder(&value)
+	enc.SetEscapeHTML(false)
+
+	for _, v := range values {
+		value.Reset()
+		if err = enc.Encode(v.Value); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\n", v.Path, bytes.TrimSuffix(value.Bytes(), []byte("\n")), v.Source)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/internal/pkg/keys"
 	"github.com/frain-dev/convoy/internal/pkg/rdb"
 	"github.com/frain-dev/convoy/internal/telemetry"
 	"github.com/frain-dev/convoy/pkg/log"
@@ -59,7 +60,7 @@ func PreRun(app *cli.App, db *postgres.Postgres) func(cmd *cobra.Command, args [
 		}
 
 		// Override with CLI Flags
-		cliConfig, err := buildCliConfiguration(cmd)
+		cliConfig, err := BuildCliConfiguration(cmd)
 		if err != nil {
 			return err
 		}
@@ -154,7 +155,7 @@ func PreRun(app *cli.App, db *postgres.Postgres) func(cmd *cobra.Command, args [
 		metaEventRepo := postgres.NewMetaEventRepo(postgresDB)
 		attemptsRepo := postgres.NewDeliveryAttemptRepo(postgresDB)
 		endpointListener := listener.NewEndpointListener(q, projectRepo, metaEventRepo)
-		eventDeliveryListener := listener.NewEventDeliveryListener(q, projectRepo, metaEventRepo, attemptsRepo)
+		eventDeliveryListener := listener.NewEventDeliveryListener(q, projectRepo, metaEventRepo, attemptsRepo, redis.Client())
 
 		hooks.RegisterHook(datastore.EndpointCreated, endpointListener.AfterCreate)
 		hooks.RegisterHook(datastore.EndpointUpdated, endpointListener.AfterUpdate)
@@ -277,11 +278,14 @@ var skipHook = map[string]struct{}{
 	"down":    {},
 	"create":  {},
 	"config":  {},
+	"show":    {},
 	"version": {},
 }
 
 func PostRun(app *cli.App, db *postgres.Postgres) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		keys.StopRefresh()
+
 		if db == nil || db.GetDB() == nil {
 			os.Exit(0)
 		}
@@ -402,7 +406,8 @@ func ensureInstanceConfig(ctx context.Context, a *cli.App, cfg config.Configurat
 	return configuration, configRepo.UpdateConfiguration(ctx, configuration)
 }
 
-func buildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
+// BuildCliConfiguration builds a configuration from the CLI flags set on cmd.
+func BuildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
 	c := &config.Configuration{}
 
 	// CONVOY_LOGGER_LEVEL
@@ -431,6 +436,14 @@ func buildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
 
 	c.ApiRateLimit = apiRateLimit
 
+	// CONVOY_GLOBAL_EGRESS_RATE
+	globalEgressRate, err := cmd.Flags().GetInt("global-egress-rate")
+	if err != nil {
+		return nil, err
+	}
+
+	c.GlobalEgressRate = globalEgressRate
+
 	// CONVOY_LICENSE_KEY
 	licenseKey, err := cmd.Flags().GetString("license-key")
 	if err != nil {
@@ -481,6 +494,24 @@ func buildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
 		return nil, err
 	}
 
+	// CONVOY_DB_MAX_FANOUT_TRANSACTIONS
+	dbMaxFanOutTransactions, err := cmd.Flags().GetInt("db-max-fanout-transactions")
+	if err != nil {
+		return nil, err
+	}
+
+	// CONVOY_DB_MAX_REPLICA_LAG_BYTES
+	dbMaxReplicaLagBytes, err := cmd.Flags().GetInt64("db-max-replica-lag-bytes")
+	if err != nil {
+		return nil, err
+	}
+
+	// CONVOY_DB_READ_AFTER_WRITE_WINDOW
+	dbReadAfterWriteWindow, err := cmd.Flags().GetInt("db-read-after-write-window")
+	if err != nil {
+		return nil, err
+	}
+
 	// CONVOY_ENABLE_FEATURE_FLAG
 	flags, err := cmd.Flags().GetStringSlice("enable-feature-flag")
 	if err != nil {
@@ -520,6 +551,10 @@ func buildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
 		Database: dbDatabase,
 		Port:     dbPort,
 
+		MaxFanOutTransactions: dbMaxFanOutTransactions,
+		MaxReplicaLagBytes:    dbMaxReplicaLagBytes,
+		ReadAfterWriteWindow:  dbReadAfterWriteWindow,
+
 		ReadReplicas: readReplicas,
 	}
 
@@ -707,11 +742,17 @@ func buildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
 					return nil, errors.New("metrics-prometheus-sample-time must be non-zero")
 				}
 
+				deliverySummary, err := cmd.Flags().GetBool("metrics-prometheus-delivery-summary")
+				if err != nil {
+					return nil, err
+				}
+
 				c.Metrics = config.MetricsConfiguration{
 					IsEnabled: true,
 					Backend:   config.MetricsBackend(metricsBackend),
 					Prometheus: config.PrometheusMetricsConfiguration{
-						SampleTime: sampleTime,
+						SampleTime:      sampleTime,
+						DeliverySummary: deliverySummary,
 					},
 				}
 			}
@@ -917,5 +958,13 @@ func loadHCPVaultConfig(cmd *cobra.Command, vaultConfig *config.HCPVaultConfig)
 		vaultConfig.SecretName = secretName
 	}
 
+	cacheDuration, err := cmd.Flags().GetDuration("hcp-cache-duration")
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("hcp-cache-duration") || vaultConfig.CacheDuration == 0 {
+		vaultConfig.CacheDuration = cacheDuration
+	}
+
 	return nil
 }
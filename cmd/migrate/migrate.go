@@ -1,6 +1,7 @@
 package migrate
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -22,6 +23,7 @@ func AddMigrateCommand(a *cli.App) *cobra.Command {
 	cmd.AddCommand(addUpCommand())
 	cmd.AddCommand(addDownCommand())
 	cmd.AddCommand(addCreateCommand())
+	cmd.AddCommand(addEnsurePartitionsCommand())
 
 	return cmd
 }
@@ -126,3 +128,69 @@ func addCreateCommand() *cobra.Command {
 
 	return cmd
 }
+
+func addEnsurePartitionsCommand() *cobra.Command {
+	var projectID, from, to string
+
+	cmd := &cobra.Command{
+		Use:   "ensure-partitions",
+		Short: "Creates the missing event_deliveries partitions of a project",
+		Long: "Creates the event_deliveries partitions a project is missing between --from and --to, inclusive, " +
+			"so deliveries created in that range can be inserted. Partitions span a day or a month, whichever " +
+			"the table was partitioned with, and periods that already have one are skipped, so it's safe to rerun.",
+		Annotations: map[string]string{
+			"CheckMigration":  "false",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectID == "" {
+				return errors.New("please provide the project id with --project")
+			}
+
+			fromDate, err := time.Parse(time.DateOnly, from)
+			if err != nil {
+				return fmt.Errorf("invalid --from date %q, expected YYYY-MM-DD", from)
+			}
+
+			toDate, err := time.Parse(time.DateOnly, to)
+			if err != nil {
+				return fmt.Errorf("invalid --to date %q, expected YYYY-MM-DD", to)
+			}
+
+			if toDate.Before(fromDate) {
+				return errors.New("--to must not be before --from")
+			}
+
+			cfg, err := config.Get()
+			if err != nil {
+				log.WithError(err).Fatal("Error fetching the config.")
+			}
+
+			db, err := postgres.NewDB(cfg)
+			if err != nil {
+				return err
+			}
+
+			defer db.Close()
+
+			_, err = postgres.NewProjectRepo(db).FetchProjectByID(cmd.Context(), projectID)
+			if err != nil {
+				return fmt.Errorf("failed to find project %s: %w", projectID, err)
+			}
+
+			created, err := postgres.NewEventDeliveryRepo(db).EnsureEventDeliveryPartitions(cmd.Context(), projectID, fromDate, toDate)
+			if err != nil {
+				return fmt.Errorf("failed to create partitions: %w", err)
+			}
+
+			log.Infof("created %d event_deliveries partitions for project %s from %s to %s", created, projectID, from, to)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project", "", "ID of the project to create partitions for")
+	cmd.Flags().StringVar(&from, "from", "", "First day of the range, YYYY-MM-DD")
+	cmd.Flags().StringVar(&to, "to", "", "Last day of the range, YYYY-MM-DD")
+
+	return cmd
+}
@@ -14,6 +14,7 @@ func AddRetryCommand(a *cli.App) *cobra.Command {
 	var status string
 	var timeInterval string
 	var eventId string
+	var eventType string
 
 	cmd := &cobra.Command{
 		Use:   "retry",
@@ -32,12 +33,13 @@ func AddRetryCommand(a *cli.App) *cobra.Command {
 			}
 
 			statuses := []datastore.EventDeliveryStatus{datastore.EventDeliveryStatus(status)}
-			task.RetryEventDeliveries(a.DB, a.Queue, statuses, timeInterval, eventId)
+			task.RetryEventDeliveries(a.DB, a.Queue, statuses, timeInterval, eventId, eventType)
 		},
 	}
 
 	cmd.Flags().StringVar(&status, "status", "", "Status of event deliveries to requeue")
 	cmd.Flags().StringVar(&timeInterval, "time", "", "Time interval")
 	cmd.Flags().StringVar(&eventId, "eventid", "", "Requeue the informed eventId")
+	cmd.Flags().StringVar(&eventType, "event-type", "", "Only requeue deliveries of this event type")
 	return cmd
 }
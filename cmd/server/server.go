@@ -112,6 +112,7 @@ func startConvoyServer(a *cli.App) error {
 	if err = keys.Set(km); err != nil {
 		return err
 	}
+	km.StartRefresh()
 
 	apiKeyRepo := postgres.NewAPIKeyRepo(a.DB)
 	userRepo := postgres.NewUserRepo(a.DB)
@@ -168,6 +169,12 @@ func startConvoyServer(a *cli.App) error {
 	s.RegisterTask("58 23 * * *", convoy.ScheduleQueue, convoy.DeleteArchivedTasksProcessor)
 	s.RegisterTask("30 * * * *", convoy.ScheduleQueue, convoy.MonitorTwitterSources)
 	s.RegisterTask("0 * * * *", convoy.ScheduleQueue, convoy.TokenizeSearch)
+	s.RegisterTask("15 * * * *", convoy.ScheduleQueue, convoy.ReplayDeadLettersProcessor)
+
+	if cfg.EndpointRecovery.IsEnabled {
+		// the probes each endpoint is due are spaced out by the recovery backoff
+		s.RegisterTask("*/5 * * * *", convoy.ScheduleQueue, convoy.RecoverEndpointsProcessor)
+	}
 
 	// ensures that project data is backed up about 2 hours before they are deleted
 	if a.Licenser.RetentionPolicy() {
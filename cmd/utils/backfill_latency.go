@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+func AddBackfillLatencyCommand(a *cli.App) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "backfill-latency",
+		Short: "backfills latency_seconds for older event deliveries",
+		Long:  "computes latency_seconds for event deliveries that don't have it from their delivery attempts, or from the deprecated latency value",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			eventDeliveryRepo := postgres.NewEventDeliveryRepo(a.DB)
+
+			log.Infof("Backfilling event delivery latencies...")
+
+			updated, err := eventDeliveryRepo.BackfillLatencySeconds(cmd.Context(), batchSize)
+			if err != nil {
+				log.WithError(err).Errorf("backfill stopped after updating %d event deliveries", updated)
+				return err
+			}
+
+			log.Infof("Backfilled latency_seconds for %d event deliveries", updated)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&batchSize, "batch-size", 1000, "Number of event deliveries to backfill per batch")
+
+	return cmd
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/internal/pkg/fflag"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/services"
+	"github.com/spf13/cobra"
+)
+
+func AddCheckEndpointsCommand(a *cli.App) *cobra.Command {
+	var projectID string
+	var concurrency int
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "check-endpoints",
+		Short: "checks that a project's endpoints are reachable",
+		Long:  "pings every active endpoint in a project through the dispatcher, respecting its allow and block lists, and reports which endpoints are reachable along with their status codes and latencies",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectID == "" {
+				return errors.New("--project is required")
+			}
+
+			if concurrency <= 0 {
+				return errors.New("--concurrency must be greater than zero")
+			}
+
+			if timeout <= 0 {
+				return errors.New("--timeout must be greater than zero")
+			}
+
+			cfg, err := config.Get()
+			if err != nil {
+				return err
+			}
+
+			caCertTLSCfg, err := config.GetCaCert()
+			if err != nil {
+				return err
+			}
+
+			dispatcher, err := net.NewDispatcher(
+				a.Licenser,
+				fflag.NewFFlag(cfg.EnableFeatureFlag),
+				net.LoggerOption(a.Logger),
+				net.ProxyOption(cfg.Server.HTTP.HttpProxy),
+				net.AllowListOption(cfg.Dispatcher.AllowList),
+				net.BlockListOption(cfg.Dispatcher.BlockList),
+				net.TLSConfigOption(cfg.Dispatcher.InsecureSkipVerify, a.Licenser, caCertTLSCfg),
+				net.ForceHTTP2Option(cfg.Dispatcher.ForceHTTP2),
+				net.ConnectionPoolOption(cfg.Dispatcher.MaxIdleConnsPerHost, cfg.Dispatcher.GetIdleConnTimeout()),
+			)
+			if err != nil {
+				return err
+			}
+
+			ce := services.CheckEndpointsService{
+				EndpointRepo: postgres.NewEndpointRepo(a.DB),
+				Dispatcher:   dispatcher,
+				ProjectID:    projectID,
+				Concurrency:  concurrency,
+				Timeout:      timeout,
+			}
+
+			report, err := ce.Run(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			err = printEndpointReachability(cmd.OutOrStdout(), report)
+			if err != nil {
+				return err
+			}
+
+			var reachable int
+			for _, r := range report {
+				if r.Reachable {
+					reachable++
+				}
+			}
+
+			log.Infof("%d of %d endpoints are reachable", reachable, len(report))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project", "", "ID of the project whose endpoints are checked")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 10, "Maximum number of endpoints checked at once")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "How long to wait for each endpoint to respond")
+
+	return cmd
+}
+
+func printEndpointReachability(out io.Writer, report []services.EndpointReachability) error {
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	_, err := fmt.Fprintln(w, "Endpoint ID\tName\tURL\tReachable\tStatus\tLatency\tError")
+	if err != nil {
+		return err
+	}
+
+	for _, r := range report {
+		status := "-"
+		if r.StatusCode != 0 {
+			status = fmt.Sprintf("%d", r.StatusCode)
+		}
+
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n", r.EndpointID, r.Name, r.URL, r.Reachable, status, r.Latency.Round(time.Millisecond), r.Error)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+func AddCompactAttemptsCommand(a *cli.App) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "compact-attempts",
+		Short: "moves the attempts of older event deliveries into the delivery attempts table",
+		Long:  "decodes the attempts stored on event deliveries written before the delivery attempts table existed, inserts them into it and clears them from the event deliveries, so every attempt can be queried the same way",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			eventDeliveryRepo := postgres.NewEventDeliveryRepo(a.DB)
+
+			log.Infof("Compacting event delivery attempts...")
+
+			inserted, err := eventDeliveryRepo.CompactDeliveryAttempts(cmd.Context(), batchSize)
+			if err != nil {
+				log.WithError(err).Errorf("compaction stopped after inserting %d delivery attempts", inserted)
+				return err
+			}
+
+			log.Infof("Moved %d delivery attempts into the delivery attempts table", inserted)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&batchSize, "batch-size", 1000, "Number of event deliveries to compact per batch")
+
+	return cmd
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/services"
+	"github.com/spf13/cobra"
+)
+
+func AddEndpointHealthCommand(a *cli.App) *cobra.Command {
+	var projectID string
+	var window time.Duration
+	var sortBy string
+	var desc bool
+
+	cmd := &cobra.Command{
+		Use:   "endpoint-health",
+		Short: "reports the health of a project's endpoints",
+		Long:  "computes the success rate, average latency and consecutive failures of every endpoint in a project from its recent deliveries, and prints them with a health score from 0 to 100, least healthy first",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectID == "" {
+				return errors.New("--project is required")
+			}
+
+			if window <= 0 {
+				return errors.New("--window must be greater than zero")
+			}
+
+			eh := services.EndpointHealthService{
+				EndpointRepo:      postgres.NewEndpointRepo(a.DB),
+				EventDeliveryRepo: postgres.NewEventDeliveryRepo(a.DB),
+				ProjectID:         projectID,
+				Window:            window,
+				SortBy:            services.EndpointHealthSortKey(sortBy),
+				Descending:        desc,
+			}
+
+			report, err := eh.Run(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			return printEndpointHealth(cmd.OutOrStdout(), report)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project", "", "ID of the project whose endpoints are reported")
+	cmd.Flags().DurationVar(&window, "window", 24*time.Hour, "How far back deliveries are looked at")
+	cmd.Flags().StringVar(&sortBy, "sort", string(services.SortEndpointHealthByScore), "Column to sort by: score, success_rate, latency or failures")
+	cmd.Flags().BoolVar(&desc, "desc", false, "List the healthiest endpoints first")
+
+	return cmd
+}
+
+func printEndpointHealth(out io.Writer, report []services.EndpointHealthReport) error {
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	_, err := fmt.Fprintln(w, "Endpoint ID\tName\tURL\tScore\tSuccess Rate\tAvg Latency\tConsecutive Failures\tDeliveries")
+	if err != nil {
+		return err
+	}
+
+	for _, r := range report {
+		latency := time.Duration(r.AvgLatencySeconds * float64(time.Second)).Round(time.Millisecond)
+
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%.1f\t%.1f%%\t%s\t%d\t%d\n", r.EndpointID, r.Name, r.URL, r.Score, r.SuccessRate, latency, r.ConsecutiveFailures, r.Total)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
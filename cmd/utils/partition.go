@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/pkg/cli"
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
 	"github.com/spf13/cobra"
 )
 
 func AddPartitionCommand(a *cli.App) *cobra.Command {
+	var granularity string
+
 	cmd := &cobra.Command{
 		Use:   "partition",
 		Short: "partition tables",
@@ -34,6 +37,11 @@ func AddPartitionCommand(a *cli.App) *cobra.Command {
 				return fmt.Errorf("partitioning is only avaliable with a license key")
 			}
 
+			deliveriesGranularity := datastore.PartitionGranularity(granularity)
+			if !deliveriesGranularity.IsValid() {
+				return fmt.Errorf("unknown granularity %s, valid values are daily and monthly", granularity)
+			}
+
 			eventsRepo := postgres.NewEventRepo(a.DB)
 			eventDeliveryRepo := postgres.NewEventDeliveryRepo(a.DB)
 			deliveryAttemptsRepo := postgres.NewDeliveryAttemptRepo(a.DB)
@@ -50,7 +58,7 @@ func AddPartitionCommand(a *cli.App) *cobra.Command {
 					return err
 				}
 
-				err = eventDeliveryRepo.PartitionEventDeliveriesTable(cmd.Context())
+				err = eventDeliveryRepo.PartitionEventDeliveriesTable(cmd.Context(), deliveriesGranularity)
 				if err != nil {
 					return err
 				}
@@ -72,7 +80,7 @@ func AddPartitionCommand(a *cli.App) *cobra.Command {
 						return err
 					}
 				case "event_deliveries":
-					err = eventDeliveryRepo.PartitionEventDeliveriesTable(cmd.Context())
+					err = eventDeliveryRepo.PartitionEventDeliveriesTable(cmd.Context(), deliveriesGranularity)
 					if err != nil {
 						return err
 					}
@@ -90,6 +98,8 @@ func AddPartitionCommand(a *cli.App) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&granularity, "granularity", string(datastore.DailyPartitionGranularity), "Span of each event_deliveries partition, daily or monthly. Monthly creates far fewer partitions for instances with long retention, but keeps deliveries until their whole month ages out")
+
 	return cmd
 }
 
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+func AddPurgeDeliveriesCommand(a *cli.App) *cobra.Command {
+	var olderThan time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "purge-deliveries",
+		Short: "permanently deletes soft deleted event deliveries",
+		Long:  "hard deletes event deliveries that were soft deleted more than --older-than ago, live deliveries are left untouched",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if olderThan < 0 {
+				return errors.New("--older-than cannot be negative")
+			}
+
+			eventDeliveryRepo := postgres.NewEventDeliveryRepo(a.DB)
+
+			log.Infof("Purging event deliveries soft deleted more than %s ago...", olderThan)
+
+			purged, err := eventDeliveryRepo.PurgeSoftDeletedEventDeliveries(cmd.Context(), time.Now().Add(-olderThan))
+			if err != nil {
+				log.WithError(err).Errorf("purge stopped after deleting %d event deliveries", purged)
+				return err
+			}
+
+			log.Infof("Purged %d event deliveries", purged)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", 30*24*time.Hour, "Only purge deliveries that were soft deleted longer ago than this")
+
+	return cmd
+}
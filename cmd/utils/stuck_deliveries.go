@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+	redisQueue "github.com/frain-dev/convoy/queue/redis"
+	"github.com/frain-dev/convoy/worker/task"
+	"github.com/spf13/cobra"
+)
+
+func AddStuckDeliveriesCommand(a *cli.App) *cobra.Command {
+	var olderThan time.Duration
+	var projectID string
+	var limit int
+	var requeue bool
+
+	cmd := &cobra.Command{
+		Use:   "stuck-deliveries",
+		Short: "lists event deliveries stuck in processing",
+		Long:  "lists event deliveries that have been in the Processing status for longer than --older-than, and optionally requeues them",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if olderThan < 0 {
+				return errors.New("--older-than cannot be negative")
+			}
+
+			if limit <= 0 {
+				return errors.New("--limit must be greater than zero")
+			}
+
+			eventDeliveryRepo := postgres.NewEventDeliveryRepo(a.DB)
+
+			deliveries, err := eventDeliveryRepo.FindEventDeliveriesStuckInStatus(cmd.Context(), projectID, datastore.ProcessingEventStatus, olderThan, limit)
+			if err != nil {
+				return err
+			}
+
+			err = printStuckDeliveries(cmd.OutOrStdout(), deliveries)
+			if err != nil {
+				return err
+			}
+
+			if !requeue || len(deliveries) == 0 {
+				return nil
+			}
+
+			requeued, err := requeueStuckDeliveries(cmd.Context(), eventDeliveryRepo, a.Queue, deliveries)
+			if err != nil {
+				log.WithError(err).Errorf("requeue stopped after requeueing %d event deliveries", requeued)
+				return err
+			}
+
+			log.Infof("Requeued %d event deliveries", requeued)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", 5*time.Minute, "Only report deliveries that have been processing for longer than this")
+	cmd.Flags().StringVar(&projectID, "project", "", "Only report deliveries in this project")
+	cmd.Flags().IntVar(&limit, "limit", 100, "Maximum number of deliveries to report")
+	cmd.Flags().BoolVar(&requeue, "requeue", false, "Move the reported deliveries back to Scheduled and queue them for delivery")
+
+	return cmd
+}
+
+func printStuckDeliveries(out io.Writer, deliveries []datastore.EventDelivery) error {
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	_, err := fmt.Fprintln(w, "ID\tProject ID\tEndpoint ID\tCreated At\tAttempts")
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		var attempts uint64
+		if d.Metadata != nil {
+			attempts = d.Metadata.NumTrials
+		}
+
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", d.UID, d.ProjectID, d.EndpointID, d.CreatedAt.Format(time.RFC3339), attempts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func requeueStuckDeliveries(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, q queue.Queuer, deliveries []datastore.EventDelivery) (int, error) {
+	ids := make([]string, len(deliveries))
+	for i := range deliveries {
+		ids[i] = deliveries[i].UID
+	}
+
+	err := eventDeliveryRepo.UpdateStatusOfEventDeliveries(ctx, "", ids, datastore.ScheduledEventStatus)
+	if err != nil {
+		return 0, err
+	}
+
+	// drop any copies still sitting in the queue so they aren't delivered twice
+	if rq, ok := q.(*redisQueue.RedisQueue); ok {
+		err = rq.DeleteEventDeliveriesFromQueue(convoy.EventQueue, ids)
+		if err != nil {
+			log.WithError(err).Error("failed to remove stuck event deliveries from the queue")
+		}
+	}
+
+	for i := range deliveries {
+		payload := task.EventDelivery{
+			EventDeliveryID: deliveries[i].UID,
+			ProjectID:       deliveries[i].ProjectID,
+		}
+
+		data, err := msgpack.EncodeMsgPack(payload)
+		if err != nil {
+			return i, err
+		}
+
+		job := &queue.Job{
+			ID:      deliveries[i].UID,
+			Payload: data,
+			Delay:   time.Second,
+		}
+
+		err = q.Write(convoy.EventProcessor, convoy.EventQueue, job)
+		if err != nil {
+			return i, fmt.Errorf("failed to queue event delivery %s: %w", deliveries[i].UID, err)
+		}
+	}
+
+	return len(deliveries), nil
+}
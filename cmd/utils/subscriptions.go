@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/frain-dev/convoy/database/postgres"
+	"github.com/frain-dev/convoy/internal/pkg/cli"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/services"
+	"github.com/spf13/cobra"
+)
+
+func AddExportSubscriptionsCommand(a *cli.App) *cobra.Command {
+	var projectID string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export-subscriptions",
+		Short: "exports a project's subscriptions to json",
+		Long:  "exports a project's subscriptions, with their filters, configuration overrides and endpoint and source references, to json that import-subscriptions can recreate in another project",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectID == "" {
+				return errors.New("--project is required")
+			}
+
+			es := services.ExportSubscriptionsService{
+				SubRepo:    postgres.NewSubscriptionRepo(a.DB),
+				FilterRepo: postgres.NewFilterRepo(a.DB),
+				ProjectID:  projectID,
+			}
+
+			export, err := es.Run(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(export)
+			if err != nil {
+				return err
+			}
+
+			log.Infof("Exported %d subscriptions", len(export.Subscriptions))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project", "", "ID of the project to export subscriptions from")
+	cmd.Flags().StringVar(&output, "output", "", "File to write the export to, defaults to stdout")
+
+	return cmd
+}
+
+func AddImportSubscriptionsCommand(a *cli.App) *cobra.Command {
+	var projectID string
+	var file string
+	var endpointMap map[string]string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import-subscriptions",
+		Short: "recreates exported subscriptions in a project",
+		Long:  "recreates subscriptions written by export-subscriptions in a project. Endpoints are mapped with --endpoint-map and otherwise by url, sources by name, subscriptions whose endpoint or source can't be mapped are skipped and reported",
+		Annotations: map[string]string{
+			"CheckMigration":  "true",
+			"ShouldBootstrap": "false",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectID == "" {
+				return errors.New("--project is required")
+			}
+
+			if file == "" {
+				return errors.New("--file is required")
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			var export services.SubscriptionExport
+			err = json.Unmarshal(data, &export)
+			if err != nil {
+				return fmt.Errorf("failed to read subscription export: %w", err)
+			}
+
+			is := services.ImportSubscriptionsService{
+				SubRepo:      postgres.NewSubscriptionRepo(a.DB),
+				FilterRepo:   postgres.NewFilterRepo(a.DB),
+				EndpointRepo: postgres.NewEndpointRepo(a.DB),
+				SourceRepo:   postgres.NewSourceRepo(a.DB),
+				ProjectID:    projectID,
+				Export:       &export,
+				EndpointMap:  endpointMap,
+				DryRun:       dryRun,
+			}
+
+			result, err := is.Run(cmd.Context())
+			if result != nil {
+				printErr := printSubscriptionImport(cmd.OutOrStdout(), result)
+				if printErr != nil {
+					log.WithError(printErr).Error("failed to print the import result")
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				log.Infof("Would import %d of %d subscriptions", len(result.Created), len(export.Subscriptions))
+				return nil
+			}
+
+			log.Infof("Imported %d of %d subscriptions", len(result.Created), len(export.Subscriptions))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project", "", "ID of the project to import subscriptions into")
+	cmd.Flags().StringVar(&file, "file", "", "File written by export-subscriptions")
+	cmd.Flags().StringToStringVar(&endpointMap, "endpoint-map", nil, "Exported endpoint IDs mapped to endpoint IDs in the project, e.g. old-id=new-id")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Map endpoints and sources without creating any subscriptions")
+
+	return cmd
+}
+
+func printSubscriptionImport(out io.Writer, result *services.SubscriptionImportResult) error {
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	_, err := fmt.Fprintln(w, "Exported ID\tImported ID")
+	if err != nil {
+		return err
+	}
+
+	for from, to := range result.Created {
+		_, err = fmt.Fprintf(w, "%s\t%s\n", from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(result.UnmappedEndpoints) > 0 {
+		_, err = fmt.Fprintln(w, "\nUnmapped Endpoint ID\tName\tURL")
+		if err != nil {
+			return err
+		}
+
+		for _, e := range result.UnmappedEndpoints {
+			_, err = fmt.Fprintf(w, "%s\t%s\t%s\n", e.UID, e.Name, e.URL)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(result.UnmappedSources) > 0 {
+		_, err = fmt.Fprintln(w, "\nUnmapped Source ID\tName")
+		if err != nil {
+			return err
+		}
+
+		for _, s := range result.UnmappedSources {
+			_, err = fmt.Fprintf(w, "%s\t%s\n", s.UID, s.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return w.Flush()
+}
@@ -17,6 +17,14 @@ var utilsCmd = &cobra.Command{
 func AddUtilsCommand(app *cli.App) *cobra.Command {
 	utilsCmd.AddCommand(AddPartitionCommand(app))
 	utilsCmd.AddCommand(AddUnPartitionCommand(app))
+	utilsCmd.AddCommand(AddBackfillLatencyCommand(app))
+	utilsCmd.AddCommand(AddCompactAttemptsCommand(app))
+	utilsCmd.AddCommand(AddPurgeDeliveriesCommand(app))
+	utilsCmd.AddCommand(AddStuckDeliveriesCommand(app))
+	utilsCmd.AddCommand(AddExportSubscriptionsCommand(app))
+	utilsCmd.AddCommand(AddImportSubscriptionsCommand(app))
+	utilsCmd.AddCommand(AddCheckEndpointsCommand(app))
+	utilsCmd.AddCommand(AddEndpointHealthCommand(app))
 
 	utilsCmd.AddCommand(AddInitEncryptionCommand(app))
 	utilsCmd.AddCommand(AddRotateKeyCommand(app))
@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
 	"github.com/frain-dev/convoy/internal/pkg/keys"
 	"github.com/frain-dev/convoy/internal/pkg/retention"
@@ -33,7 +32,9 @@ import (
 	"github.com/frain-dev/convoy/worker/task"
 )
 
-func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, interval int) error {
+// StartWorker starts consuming tasks, the returned func stops the consumer
+// and waits for the work in flight, and the attempts it recorded, to finish.
+func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, interval int) (func(), error) {
 	lo := a.Logger.(*log.Logger)
 	lo.SetPrefix("worker")
 
@@ -41,25 +42,26 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 	if km.IsSet() {
 		if _, err := km.GetCurrentKeyFromCache(); err != nil {
 			if !errors.Is(err, keys.ErrCredentialEncryptionFeatureUnavailable) {
-				return err
+				return nil, err
 			}
 			km.Unset()
 		}
 	}
 
 	if err := keys.Set(km); err != nil {
-		return err
+		return nil, err
 	}
+	km.StartRefresh()
 
 	sc, err := smtp.NewClient(&cfg.SMTP)
 	if err != nil {
 		lo.WithError(err).Error("Failed to create smtp client")
-		return err
+		return nil, err
 	}
 
 	redis, err := rdb.NewClient(cfg.Redis.BuildDsn())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	events := map[string]int{
@@ -94,7 +96,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 
 	err = config.Override(&cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var queueNames map[string]int
@@ -106,7 +108,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 	case config.DefaultExecutionMode:
 		queueNames = both
 	default:
-		return fmt.Errorf("unknown execution mode: %s", cfg.WorkerExecutionMode)
+		return nil, fmt.Errorf("unknown execution mode: %s", cfg.WorkerExecutionMode)
 	}
 
 	opts := queue.QueueOptions{
@@ -122,7 +124,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 	ctx = log.NewContext(ctx, lo, log.Fields{})
 	lvl, err := log.ParseLevel(cfg.Logger.Level)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// register worker.
@@ -137,17 +139,27 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 	deviceRepo := postgres.NewDeviceRepo(a.DB)
 	configRepo := postgres.NewConfigRepo(a.DB)
 	attemptRepo := postgres.NewDeliveryAttemptRepo(a.DB)
+	var batchedAttemptRepo *postgres.BatchedDeliveryAttemptRepo
+	if cfg.AttemptBatch.IsEnabled {
+		batchedAttemptRepo = postgres.NewBatchedDeliveryAttemptRepo(ctx, attemptRepo, postgres.DeliveryAttemptBatchOptions{
+			Size:          cfg.AttemptBatch.Size,
+			FlushInterval: time.Duration(cfg.AttemptBatch.FlushInterval) * time.Millisecond,
+			Workers:       cfg.AttemptBatch.Workers,
+			BufferSize:    cfg.AttemptBatch.BufferSize,
+		})
+		attemptRepo = batchedAttemptRepo
+	}
 	filterRepo := postgres.NewFilterRepo(a.DB)
 	batchRetryRepo := postgres.NewBatchRetryRepo(a.DB)
 
 	rd, err := rdb.NewClient(cfg.Redis.BuildDsn())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rateLimiter, err := limiter.NewLimiter(cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	counter := &telemetry.EventsCounter{}
@@ -158,7 +170,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 	configuration, err := configRepo.LoadConfiguration(context.Background())
 	if err != nil {
 		lo.WithError(err).Fatal("Failed to instance configuration")
-		return err
+		return nil, err
 	}
 
 	subscriptionsLoader := loader.NewSubscriptionLoader(subRepo, projectRepo, lo, 0)
@@ -166,13 +178,13 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 
 	err = memorystore.DefaultStore.Register("subscriptions", subscriptionsTable)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// initial sync.
 	err = subscriptionsLoader.SyncChanges(ctx, subscriptionsTable)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	featureFlag := fflag.NewFFlag(cfg.EnableFeatureFlag)
@@ -183,7 +195,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 
 	caCertTLSCfg, err := config.GetCaCert()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	dispatcher, err := net.NewDispatcher(
@@ -196,17 +208,25 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 		net.AllowListOption(cfg.Dispatcher.AllowList),
 		net.BlockListOption(cfg.Dispatcher.BlockList),
 		net.TLSConfigOption(cfg.Dispatcher.InsecureSkipVerify, a.Licenser, caCertTLSCfg),
+		net.ForceHTTP2Option(cfg.Dispatcher.ForceHTTP2),
+		net.ConnectionPoolOption(cfg.Dispatcher.MaxIdleConnsPerHost, cfg.Dispatcher.GetIdleConnTimeout()),
+		net.TransientRetryOption(cfg.Dispatcher.TransientRetries),
 	)
 	if err != nil {
 		lo.WithError(err).Fatal("Failed to create new net dispatcher")
-		return err
+		return nil, err
 	}
 
 	var circuitBreakerManager *cb.CircuitBreakerManager
 
 	if featureFlag.CanAccessFeature(fflag.CircuitBreaker) {
+		breakerConfig := configuration.ToCircuitBreakerConfig()
+		breakerConfig.HalfOpenMaxProbes = cfg.CircuitBreaker.HalfOpenMaxProbes
+		breakerConfig.RecoveryRampDuration = cfg.CircuitBreaker.RecoveryRampDuration
+		breakerConfig.RecoveryRampRate = cfg.CircuitBreaker.RecoveryRampRate
+
 		circuitBreakerManager, err = cb.NewCircuitBreakerManager(
-			cb.ConfigOption(configuration.ToCircuitBreakerConfig()),
+			cb.ConfigOption(breakerConfig),
 			cb.StoreOption(cb.NewRedisStore(rd.Client(), clock.NewRealClock())),
 			cb.ClockOption(clock.NewRealClock()),
 			cb.LoggerOption(lo),
@@ -224,7 +244,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 
 				switch n {
 				case cb.TypeDisableResource:
-					breakerErr := endpointRepo.UpdateEndpointStatus(ctx, project.UID, endpoint.UID, datastore.InactiveEndpointStatus)
+					breakerErr := task.DisableBreakerEndpoint(ctx, endpointRepo, project.UID, endpoint.UID)
 					if breakerErr != nil {
 						return breakerErr
 					}
@@ -248,7 +268,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 		policy, _err := time.ParseDuration(cfg.RetentionPolicy.Policy)
 		if _err != nil {
 			lo.WithError(_err).Fatal("Failed to parse retention policy")
-			return _err
+			return nil, _err
 		}
 
 		ret, err = retention.NewPartitionRetentionPolicy(a.DB, lo, policy)
@@ -274,6 +294,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 		eventDeliveryRepo,
 		a.Licenser,
 		projectRepo,
+		subRepo,
 		a.Queue,
 		rateLimiter,
 		dispatcher,
@@ -299,6 +320,7 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 		eventDeliveryRepo,
 		a.Licenser,
 		projectRepo,
+		subRepo,
 		a.Queue,
 		rateLimiter,
 		dispatcher,
@@ -367,12 +389,25 @@ func StartWorker(ctx context.Context, a *cli.App, cfg config.Configuration, inte
 	consumer.RegisterHandlers(convoy.DeleteArchivedTasksProcessor, task.DeleteArchivedTasks(a.Queue, rd), nil)
 
 	consumer.RegisterHandlers(convoy.BatchRetryProcessor, task.ProcessBatchRetry(batchRetryRepo, eventDeliveryRepo, a.Queue, lo), nil)
+	consumer.RegisterHandlers(convoy.ReplayDeadLettersProcessor, task.ReplayDeadLetters(projectRepo, eventDeliveryRepo, a.Queue), nil)
+	consumer.RegisterHandlers(convoy.RecoverEndpointsProcessor, task.RecoverEndpoints(projectRepo, endpointRepo, dispatcher), nil)
 
 	metrics.RegisterQueueMetrics(a.Queue, a.DB, circuitBreakerManager)
 
+	go task.QueueStuckEventDeliveries(ctx, eventDeliveryRepo, a.Queue, cfg.WorkerPoll)
+
 	// start worker
 	consumer.Start()
 	lo.Println("Starting Convoy Consumer Pool")
 
-	return ctx.Err()
+	stop := func() {
+		consumer.Stop()
+
+		// buffered attempts are only written once the tasks recording them are done
+		if batchedAttemptRepo != nil {
+			batchedAttemptRepo.Close()
+		}
+	}
+
+	return stop, ctx.Err()
 }
@@ -19,6 +19,7 @@ import (
 const (
 	MaxResponseSizeKb                 = 50    // in kilobytes
 	MaxResponseSize                   = 51200 // in bytes
+	DefaultMaxAttemptBodyBytes        = MaxResponseSize
 	DefaultHost                       = "localhost:5005"
 	DefaultSearchTokenizationInterval = 1
 	DefaultCacheTTL                   = time.Minute * 10
@@ -137,6 +138,17 @@ type DatabaseConfiguration struct {
 	SetMaxIdleConnections int `json:"max_idle_conn" envconfig:"CONVOY_DB_MAX_IDLE_CONN"`
 	SetConnMaxLifetime    int `json:"conn_max_lifetime" envconfig:"CONVOY_DB_CONN_MAX_LIFETIME"`
 
+	// MaxFanOutTransactions caps the event delivery fan-out transactions open at once, 0 means unlimited
+	MaxFanOutTransactions int `json:"max_fanout_transactions" envconfig:"CONVOY_DB_MAX_FANOUT_TRANSACTIONS"`
+
+	// MaxReplicaLagBytes is how many bytes of WAL a read replica may lag behind the
+	// primary before reads fall back to the primary, 0 turns the check off
+	MaxReplicaLagBytes int64 `json:"max_replica_lag_bytes" envconfig:"CONVOY_DB_MAX_REPLICA_LAG_BYTES"`
+
+	// ReadAfterWriteWindow is how many seconds after a write reads that must see
+	// it are served from the primary, 0 turns it off
+	ReadAfterWriteWindow int `json:"read_after_write_window" envconfig:"CONVOY_DB_READ_AFTER_WRITE_WINDOW"`
+
 	ReadReplicas ReadReplicaConfiguration `json:"read_replicas" envconfig:"CONVOY_DB_READ_REPLICAS"`
 }
 
@@ -310,6 +322,230 @@ type DatadogConfiguration struct {
 type RetentionPolicyConfiguration struct {
 	Policy                   string `json:"policy" envconfig:"CONVOY_RETENTION_POLICY"`
 	IsRetentionPolicyEnabled bool   `json:"enabled" envconfig:"CONVOY_RETENTION_POLICY_ENABLED"`
+
+	// EventTypePolicies overrides Policy for event deliveries of the given
+	// event types, e.g. {"audit.log": "8760h", "heartbeat": "24h"}. On
+	// partitioned tables deliveries are dropped with their partition once
+	// it ages out of Policy, so only overrides shorter than Policy apply.
+	EventTypePolicies map[string]string `json:"event_type_policies" envconfig:"CONVOY_RETENTION_POLICY_EVENT_TYPES"`
+
+	// Concurrency caps how many projects are purged at the same time
+	Concurrency int `json:"concurrency" envconfig:"CONVOY_RETENTION_POLICY_CONCURRENCY"`
+
+	// PayloadPolicy is how long event delivery payloads are kept, when it is
+	// shorter than Policy successful deliveries lose their payloads after it
+	// and the rest of the row is kept until Policy, e.g. "72h"
+	PayloadPolicy string `json:"payload_policy" envconfig:"CONVOY_RETENTION_POLICY_PAYLOAD"`
+}
+
+// GetConcurrency returns the number of projects that may be purged at the
+// same time, defaulting to one project at a time.
+func (r RetentionPolicyConfiguration) GetConcurrency() int {
+	if r.Concurrency < 1 {
+		return 1
+	}
+
+	return r.Concurrency
+}
+
+// PayloadRetentionPeriod parses the payload retention policy, it is zero
+// when payloads are kept as long as their deliveries.
+func (r RetentionPolicyConfiguration) PayloadRetentionPeriod() (time.Duration, error) {
+	if r.PayloadPolicy == "" {
+		return 0, nil
+	}
+
+	period, err := time.ParseDuration(r.PayloadPolicy)
+	if err != nil {
+		return 0, fmt.Errorf("invalid payload retention policy %q: %v", r.PayloadPolicy, err)
+	}
+
+	if period <= 0 {
+		return 0, fmt.Errorf("payload retention policy must be positive, got %q", r.PayloadPolicy)
+	}
+
+	return period, nil
+}
+
+// EventTypeRetentionPeriods parses the event type retention overrides.
+func (r RetentionPolicyConfiguration) EventTypeRetentionPeriods() (map[string]time.Duration, error) {
+	periods := make(map[string]time.Duration, len(r.EventTypePolicies))
+	for eventType, policy := range r.EventTypePolicies {
+		period, err := time.ParseDuration(policy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retention policy for event type %s: %v", eventType, err)
+		}
+
+		periods[eventType] = period
+	}
+
+	return periods, nil
+}
+
+// AttemptBatchConfiguration makes workers buffer delivery attempts
+// and write them in bulk, instead of one insert per attempt
+type AttemptBatchConfiguration struct {
+	IsEnabled bool `json:"enabled" envconfig:"CONVOY_DELIVERY_ATTEMPT_BATCH_ENABLED"`
+
+	// Size is the number of attempts written in one insert, 100 by default
+	Size int `json:"size" envconfig:"CONVOY_DELIVERY_ATTEMPT_BATCH_SIZE"`
+
+	// FlushInterval is the longest, in milliseconds, an attempt is buffered
+	// for, 500 by default
+	FlushInterval int `json:"flush_interval" envconfig:"CONVOY_DELIVERY_ATTEMPT_BATCH_FLUSH_INTERVAL"`
+
+	// Workers is the number of batches written concurrently, 1 by default
+	Workers int `json:"workers" envconfig:"CONVOY_DELIVERY_ATTEMPT_BATCH_WORKERS"`
+
+	// BufferSize is the number of attempts that can be waiting to be written,
+	// 1000 by default
+	BufferSize int `json:"buffer_size" envconfig:"CONVOY_DELIVERY_ATTEMPT_BATCH_BUFFER_SIZE"`
+}
+
+// WorkerPollConfiguration spaces out the polls of the worker's loop that
+// queues stuck scheduled deliveries, it polls every MinInterval while it finds
+// deliveries and backs off by Multiplier up to MaxInterval while it doesn't
+type WorkerPollConfiguration struct {
+	// MinInterval is the time, in milliseconds, between polls under load,
+	// 1000 by default
+	MinInterval int `json:"min_interval" envconfig:"CONVOY_WORKER_POLL_MIN_INTERVAL"`
+
+	// MaxInterval is the longest time, in milliseconds, between polls while
+	// idle, 60000 by default
+	MaxInterval int `json:"max_interval" envconfig:"CONVOY_WORKER_POLL_MAX_INTERVAL"`
+
+	// Multiplier is how much longer each idle poll waits than the one
+	// before it, 2 by default
+	Multiplier float64 `json:"multiplier" envconfig:"CONVOY_WORKER_POLL_MULTIPLIER"`
+}
+
+// EndpointRecoveryConfiguration turns on probing endpoints the circuit
+// breaker disabled, a probe answered with a 2xx re-enables the endpoint. Failed probes back off from InitialBackoff, doubling up to
+// MaxBackoff, and the endpoint is left disabled after MaxAttempts of them.
+type EndpointRecoveryConfiguration struct {
+	IsEnabled bool `json:"enabled" envconfig:"CONVOY_ENDPOINT_RECOVERY_ENABLED"`
+
+	// MaxAttempts is how many probes a disabled endpoint gets, 10 by default
+	MaxAttempts uint64 `json:"max_attempts" envconfig:"CONVOY_ENDPOINT_RECOVERY_MAX_ATTEMPTS"`
+
+	// InitialBackoff is the time, in seconds, between the first and second
+	// probes, 300 by default
+	InitialBackoff uint64 `json:"initial_backoff" envconfig:"CONVOY_ENDPOINT_RECOVERY_INITIAL_BACKOFF"`
+
+	// MaxBackoff is the longest time, in seconds, between probes, 86400
+	// by default
+	MaxBackoff uint64 `json:"max_backoff" envconfig:"CONVOY_ENDPOINT_RECOVERY_MAX_BACKOFF"`
+}
+
+// GetMaxAttempts returns how many probes a disabled endpoint gets.
+func (e EndpointRecoveryConfiguration) GetMaxAttempts() uint64 {
+	if e.MaxAttempts == 0 {
+		return 10
+	}
+
+	return e.MaxAttempts
+}
+
+// Backoff returns how long to wait after a disabled endpoint failed its
+// attempts-th probe before probing it again.
+func (e EndpointRecoveryConfiguration) Backoff(attempts uint64) time.Duration {
+	initial, ceiling := e.InitialBackoff, e.MaxBackoff
+	if initial == 0 {
+		initial = 300
+	}
+
+	if ceiling == 0 {
+		ceiling = 86400
+	}
+
+	backoff := time.Duration(initial) * time.Second
+	for i := uint64(1); i < attempts && backoff < time.Duration(ceiling)*time.Second; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, time.Duration(ceiling)*time.Second)
+}
+
+// InspectionSinkConfiguration turns on the built-in inspection sink, an
+// endpoint URL on the server, /inspect/{token}, that captures what is
+// delivered to it so test deliveries can be looked at without an external
+// service. Only projects in test mode have a token. Captures are
+// dropped after TTL, and only the last MaxCaptures of a project are kept.
+type InspectionSinkConfiguration struct {
+	IsEnabled bool `json:"enabled" envconfig:"CONVOY_INSPECTION_SINK_ENABLED"`
+
+	// TTL is how long, in seconds, a capture is kept, 900 by default
+	TTL uint64 `json:"ttl" envconfig:"CONVOY_INSPECTION_SINK_TTL"`
+
+	// MaxCaptures is how many captures are kept per project, 50 by default
+	MaxCaptures uint64 `json:"max_captures" envconfig:"CONVOY_INSPECTION_SINK_MAX_CAPTURES"`
+}
+
+// GetTTL returns how long a capture is kept.
+func (i InspectionSinkConfiguration) GetTTL() time.Duration {
+	if i.TTL == 0 {
+		return 900 * time.Second
+	}
+
+	return time.Duration(i.TTL) * time.Second
+}
+
+// GetMaxCaptures returns how many captures are kept per project.
+func (i InspectionSinkConfiguration) GetMaxCaptures() int {
+	if i.MaxCaptures == 0 {
+		return 50
+	}
+
+	return int(i.MaxCaptures)
+}
+
+type QueueBackpressureConfiguration struct {
+	IsEnabled bool `json:"enabled" envconfig:"CONVOY_QUEUE_BACKPRESSURE_ENABLED"`
+
+	// HighWaterMark is the event queue depth above which new events are rejected
+	HighWaterMark int `json:"high_water_mark" envconfig:"CONVOY_QUEUE_BACKPRESSURE_HIGH_WATER_MARK"`
+
+	// LowWaterMark is the event queue depth below which ingestion resumes,
+	// it defaults to HighWaterMark
+	LowWaterMark int `json:"low_water_mark" envconfig:"CONVOY_QUEUE_BACKPRESSURE_LOW_WATER_MARK"`
+
+	// CheckInterval is how often, in seconds, the event queue depth is sampled
+	CheckInterval int `json:"check_interval" envconfig:"CONVOY_QUEUE_BACKPRESSURE_CHECK_INTERVAL"`
+}
+
+// GetLowWaterMark returns the queue depth ingestion resumes below.
+func (q QueueBackpressureConfiguration) GetLowWaterMark() int {
+	if q.LowWaterMark < 1 {
+		return q.HighWaterMark
+	}
+
+	return q.LowWaterMark
+}
+
+// GetCheckInterval returns how often the queue depth is sampled,
+// defaulting to every second.
+func (q QueueBackpressureConfiguration) GetCheckInterval() time.Duration {
+	if q.CheckInterval < 1 {
+		return time.Second
+	}
+
+	return time.Duration(q.CheckInterval) * time.Second
+}
+
+func (q QueueBackpressureConfiguration) validate() error {
+	if !q.IsEnabled {
+		return nil
+	}
+
+	if q.HighWaterMark < 1 {
+		return errors.New("queue backpressure high_water_mark must be greater than zero")
+	}
+
+	if q.GetLowWaterMark() > q.HighWaterMark {
+		return errors.New("queue backpressure low_water_mark cannot be greater than high_water_mark")
+	}
+
+	return nil
 }
 
 type CircuitBreakerConfiguration struct {
@@ -320,6 +556,34 @@ type CircuitBreakerConfiguration struct {
 	MinimumRequestCount         uint64 `json:"minimum_request_count" envconfig:"CONVOY_CIRCUIT_BREAKER_MINIMUM_REQUEST_COUNT"`
 	ObservabilityWindow         uint64 `json:"observability_window" envconfig:"CONVOY_CIRCUIT_BREAKER_OBSERVABILITY_WINDOW"`
 	ConsecutiveFailureThreshold uint64 `json:"consecutive_failure_threshold" envconfig:"CONVOY_CIRCUIT_BREAKER_CONSECUTIVE_FAILURE_THRESHOLD"`
+	// SkipForManualRetries lets manually retried deliveries through an open circuit breaker
+	SkipForManualRetries bool `json:"skip_for_manual_retries" envconfig:"CONVOY_CIRCUIT_BREAKER_SKIP_FOR_MANUAL_RETRIES"`
+	// StoreFailureMode decides whether deliveries go out (fail_open) or are deferred
+	// (fail_closed) when the breaker state can't be read from its store, defaults to fail_closed
+	StoreFailureMode CircuitBreakerStoreFailureMode `json:"store_failure_mode" envconfig:"CONVOY_CIRCUIT_BREAKER_STORE_FAILURE_MODE"`
+	// HalfOpenMaxProbes caps the deliveries let through to an endpoint while its
+	// circuit breaker is half-open, there's no cap when it is 0
+	HalfOpenMaxProbes uint64 `json:"half_open_max_probes" envconfig:"CONVOY_CIRCUIT_BREAKER_HALF_OPEN_MAX_PROBES"`
+	// RecoveryRampDuration is how long (in seconds) deliveries to an endpoint are capped
+	// at RecoveryRampRate per second after its circuit breaker closes, there's no ramp when it is 0
+	RecoveryRampDuration uint64 `json:"recovery_ramp_duration" envconfig:"CONVOY_CIRCUIT_BREAKER_RECOVERY_RAMP_DURATION"`
+	RecoveryRampRate     uint64 `json:"recovery_ramp_rate" envconfig:"CONVOY_CIRCUIT_BREAKER_RECOVERY_RAMP_RATE"`
+}
+
+type CircuitBreakerStoreFailureMode string
+
+const (
+	FailOpenStoreFailureMode   CircuitBreakerStoreFailureMode = "fail_open"
+	FailClosedStoreFailureMode CircuitBreakerStoreFailureMode = "fail_closed"
+)
+
+func (c CircuitBreakerConfiguration) validate() error {
+	switch c.StoreFailureMode {
+	case "", FailOpenStoreFailureMode, FailClosedStoreFailureMode:
+		return nil
+	default:
+		return fmt.Errorf("unknown circuit breaker store_failure_mode %q, use %s or %s", c.StoreFailureMode, FailOpenStoreFailureMode, FailClosedStoreFailureMode)
+	}
 }
 
 type AnalyticsConfiguration struct {
@@ -354,6 +618,10 @@ type MetricsConfiguration struct {
 
 type PrometheusMetricsConfiguration struct {
 	SampleTime uint64 `json:"sample_time" envconfig:"CONVOY_METRICS_SAMPLE_TIME"`
+
+	// DeliverySummary exposes per project counts of the deliveries created in
+	// the last complete minute, grouped by status
+	DeliverySummary bool `json:"delivery_summary" envconfig:"CONVOY_METRICS_DELIVERY_SUMMARY"`
 }
 
 const (
@@ -402,36 +670,55 @@ const (
 )
 
 type Configuration struct {
-	InstanceId          string                       `json:"instance_id"`
-	APIVersion          string                       `json:"api_version" envconfig:"CONVOY_API_VERSION"`
-	Auth                AuthConfiguration            `json:"auth,omitempty"`
-	Database            DatabaseConfiguration        `json:"database"`
-	Redis               RedisConfiguration           `json:"redis"`
-	Prometheus          PrometheusConfiguration      `json:"prometheus"`
-	Server              ServerConfiguration          `json:"server"`
-	MaxResponseSize     uint64                       `json:"max_response_size" envconfig:"CONVOY_MAX_RESPONSE_SIZE"`
-	SMTP                SMTPConfiguration            `json:"smtp"`
-	Environment         string                       `json:"env" envconfig:"CONVOY_ENV"`
-	Logger              LoggerConfiguration          `json:"logger"`
-	Tracer              TracerConfiguration          `json:"tracer"`
-	Host                string                       `json:"host" envconfig:"CONVOY_HOST"`
-	Pyroscope           PyroscopeConfiguration       `json:"pyroscope"`
-	CustomDomainSuffix  string                       `json:"custom_domain_suffix" envconfig:"CONVOY_CUSTOM_DOMAIN_SUFFIX"`
-	EnableFeatureFlag   []string                     `json:"enable_feature_flag" envconfig:"CONVOY_ENABLE_FEATURE_FLAG"`
-	RetentionPolicy     RetentionPolicyConfiguration `json:"retention_policy"`
-	CircuitBreaker      CircuitBreakerConfiguration  `json:"circuit_breaker"`
-	Analytics           AnalyticsConfiguration       `json:"analytics"`
-	StoragePolicy       StoragePolicyConfiguration   `json:"storage_policy"`
-	ConsumerPoolSize    int                          `json:"consumer_pool_size" envconfig:"CONVOY_CONSUMER_POOL_SIZE"`
-	EnableProfiling     bool                         `json:"enable_profiling" envconfig:"CONVOY_ENABLE_PROFILING"`
-	Metrics             MetricsConfiguration         `json:"metrics" envconfig:"CONVOY_METRICS"`
-	InstanceIngestRate  int                          `json:"instance_ingest_rate" envconfig:"CONVOY_INSTANCE_INGEST_RATE"`
-	ApiRateLimit        int                          `json:"api_rate_limit" envconfig:"CONVOY_API_RATE_LIMIT"`
-	WorkerExecutionMode ExecutionMode                `json:"worker_execution_mode" envconfig:"CONVOY_WORKER_EXECUTION_MODE"`
-	MaxRetrySeconds     uint64                       `json:"max_retry_seconds,omitempty" envconfig:"CONVOY_MAX_RETRY_SECONDS"`
-	LicenseKey          string                       `json:"license_key" envconfig:"CONVOY_LICENSE_KEY"`
-	Dispatcher          DispatcherConfiguration      `json:"dispatcher"`
-	HCPVault            HCPVaultConfig               `json:"hcp_vault"`
+	InstanceId          string                         `json:"instance_id"`
+	APIVersion          string                         `json:"api_version" envconfig:"CONVOY_API_VERSION"`
+	Auth                AuthConfiguration              `json:"auth,omitempty"`
+	Database            DatabaseConfiguration          `json:"database"`
+	Redis               RedisConfiguration             `json:"redis"`
+	Prometheus          PrometheusConfiguration        `json:"prometheus"`
+	Server              ServerConfiguration            `json:"server"`
+	MaxResponseSize     uint64                         `json:"max_response_size" envconfig:"CONVOY_MAX_RESPONSE_SIZE"`
+	MaxAttemptBodyBytes uint64                         `json:"max_attempt_body_bytes" envconfig:"CONVOY_MAX_ATTEMPT_BODY_BYTES"`
+	SMTP                SMTPConfiguration              `json:"smtp"`
+	Environment         string                         `json:"env" envconfig:"CONVOY_ENV"`
+	Logger              LoggerConfiguration            `json:"logger"`
+	Tracer              TracerConfiguration            `json:"tracer"`
+	Host                string                         `json:"host" envconfig:"CONVOY_HOST"`
+	Pyroscope           PyroscopeConfiguration         `json:"pyroscope"`
+	CustomDomainSuffix  string                         `json:"custom_domain_suffix" envconfig:"CONVOY_CUSTOM_DOMAIN_SUFFIX"`
+	EnableFeatureFlag   []string                       `json:"enable_feature_flag" envconfig:"CONVOY_ENABLE_FEATURE_FLAG"`
+	RetentionPolicy     RetentionPolicyConfiguration   `json:"retention_policy"`
+	CircuitBreaker      CircuitBreakerConfiguration    `json:"circuit_breaker"`
+	Analytics           AnalyticsConfiguration         `json:"analytics"`
+	StoragePolicy       StoragePolicyConfiguration     `json:"storage_policy"`
+	ConsumerPoolSize    int                            `json:"consumer_pool_size" envconfig:"CONVOY_CONSUMER_POOL_SIZE"`
+	EnableProfiling     bool                           `json:"enable_profiling" envconfig:"CONVOY_ENABLE_PROFILING"`
+	Metrics             MetricsConfiguration           `json:"metrics" envconfig:"CONVOY_METRICS"`
+	InstanceIngestRate  int                            `json:"instance_ingest_rate" envconfig:"CONVOY_INSTANCE_INGEST_RATE"`
+	ApiRateLimit        int                            `json:"api_rate_limit" envconfig:"CONVOY_API_RATE_LIMIT"`
+	QueueBackpressure   QueueBackpressureConfiguration `json:"queue_backpressure"`
+	AttemptBatch        AttemptBatchConfiguration      `json:"attempt_batch"`
+	WorkerPoll          WorkerPollConfiguration        `json:"worker_poll"`
+	EndpointRecovery    EndpointRecoveryConfiguration  `json:"endpoint_recovery"`
+	InspectionSink      InspectionSinkConfiguration    `json:"inspection_sink"`
+	GlobalEgressRate    int                            `json:"global_egress_rate" envconfig:"CONVOY_GLOBAL_EGRESS_RATE"`
+	WorkerExecutionMode ExecutionMode                  `json:"worker_execution_mode" envconfig:"CONVOY_WORKER_EXECUTION_MODE"`
+	MaxRetrySeconds     uint64                         `json:"max_retry_seconds,omitempty" envconfig:"CONVOY_MAX_RETRY_SECONDS"`
+	MaxEventAgeSeconds  uint64                         `json:"max_event_age_seconds,omitempty" envconfig:"CONVOY_MAX_EVENT_AGE_SECONDS"`
+	LicenseKey          string                         `json:"license_key" envconfig:"CONVOY_LICENSE_KEY"`
+	Dispatcher          DispatcherConfiguration        `json:"dispatcher"`
+	HCPVault            HCPVaultConfig                 `json:"hcp_vault"`
+}
+
+// GetMaxAttemptBodyBytes returns how many bytes of a response body are
+// stored with a delivery attempt, MaxResponseSize when MaxAttemptBodyBytes
+// is 0.
+func (c Configuration) GetMaxAttemptBodyBytes() uint64 {
+	if c.MaxAttemptBodyBytes == 0 {
+		return DefaultMaxAttemptBodyBytes
+	}
+
+	return c.MaxAttemptBodyBytes
 }
 
 type DispatcherConfiguration struct {
@@ -440,6 +727,27 @@ type DispatcherConfiguration struct {
 	BlockList          []string `json:"block_list" envconfig:"CONVOY_DISPATCHER_BLOCK_LIST"`
 	CACertPath         string   `json:"ca_cert_path" envconfig:"CONVOY_DISPATCHER_CACERT_PATH"`
 	CACertString       string   `json:"ca_cert_string" envconfig:"CONVOY_DISPATCHER_CACERT_STRING"`
+
+	// ForceHTTP2 negotiates HTTP/2 with endpoints that support it
+	ForceHTTP2 bool `json:"force_http2" envconfig:"CONVOY_DISPATCHER_FORCE_HTTP2"`
+
+	// MaxIdleConnsPerHost and IdleConnTimeout (in seconds) tune connection
+	// reuse, the dispatcher's defaults are used when they are 0
+	MaxIdleConnsPerHost int    `json:"max_idle_conns_per_host" envconfig:"CONVOY_DISPATCHER_MAX_IDLE_CONNS_PER_HOST"`
+	IdleConnTimeout     uint64 `json:"idle_conn_timeout" envconfig:"CONVOY_DISPATCHER_IDLE_CONN_TIMEOUT"`
+
+	// Timeout (in seconds) is how long a delivery to an endpoint without
+	// its own http timeout can take, defaults to 10 seconds when it is 0
+	Timeout uint64 `json:"timeout" envconfig:"CONVOY_DISPATCHER_TIMEOUT"`
+
+	// TransientRetries is how many times a request that failed on a transient
+	// network error, like a connection reset, is resent within the same attempt
+	TransientRetries int `json:"transient_retries" envconfig:"CONVOY_DISPATCHER_TRANSIENT_RETRIES"`
+}
+
+// GetIdleConnTimeout returns IdleConnTimeout as a duration.
+func (d DispatcherConfiguration) GetIdleConnTimeout() time.Duration {
+	return time.Duration(d.IdleConnTimeout) * time.Second
 }
 
 type PyroscopeConfiguration struct {
@@ -457,6 +765,10 @@ type HCPVaultConfig struct {
 	ProjectID    string `json:"project_id" envconfig:"CONVOY_HCP_PROJECT_ID"`
 	AppName      string `json:"app_name" envconfig:"CONVOY_HCP_APP_NAME"`
 	SecretName   string `json:"secret_name" envconfig:"CONVOY_HCP_SECRET_NAME"`
+
+	// CacheDuration is how long the secret is cached, it's refreshed in
+	// the background every half of it
+	CacheDuration time.Duration `json:"cache_duration" envconfig:"CONVOY_HCP_CACHE_DURATION"`
 }
 
 // Get fetches the application configuration. LoadConfig must have been called
@@ -516,19 +828,35 @@ func overrideFields(ov, nv reflect.Value) {
 // LoadConfig is used to load the configuration from either the json config file
 // or the environment variables.
 func LoadConfig(p string) error {
+	c, err := readConfig(p)
+	if err != nil {
+		return err
+	}
+
+	if err = validate(&c); err != nil {
+		return err
+	}
+
+	cfgSingleton.Store(&c)
+	return nil
+}
+
+// readConfig applies the json config file and then the environment
+// variables on top of the default configuration.
+func readConfig(p string) (Configuration, error) {
 	c := DefaultConfiguration
 
 	if _, err := os.Stat(p); err == nil {
 		f, err := os.Open(p)
 		if err != nil {
-			return err
+			return Configuration{}, err
 		}
 
 		defer f.Close()
 
 		// load config from config.json
 		if err := json.NewDecoder(f).Decode(&c); err != nil {
-			return err
+			return Configuration{}, err
 		}
 	} else if !errors.Is(err, os.ErrNotExist) {
 		log.WithError(err).Fatal("failed to check if config file exists")
@@ -537,15 +865,10 @@ func LoadConfig(p string) error {
 	// override config from environment variables
 	err := envconfig.Process(envPrefix, &c)
 	if err != nil {
-		return err
-	}
-
-	if err = validate(&c); err != nil {
-		return err
+		return Configuration{}, err
 	}
 
-	cfgSingleton.Store(&c)
-	return nil
+	return c, nil
 }
 
 func ensureSSL(s ServerConfiguration) error {
@@ -586,6 +909,18 @@ func validate(c *Configuration) error {
 		return err
 	}
 
+	if _, err := c.RetentionPolicy.EventTypeRetentionPeriods(); err != nil {
+		return err
+	}
+
+	if err := c.QueueBackpressure.validate(); err != nil {
+		return err
+	}
+
+	if err := c.CircuitBreaker.validate(); err != nil {
+		return err
+	}
+
 	if c.Metrics.IsEnabled {
 		backend := c.Metrics.Backend
 		switch backend {
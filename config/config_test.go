@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/frain-dev/convoy"
 	"github.com/stretchr/testify/require"
@@ -408,6 +409,22 @@ func TestLoadConfig(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "redis queue dsn is empty",
 		},
+		{
+			name: "should_error_for_invalid_event_type_retention_policy",
+			args: args{
+				path: "./testdata/Config/invalid-event-type-retention-policy.json",
+			},
+			wantErr:    true,
+			wantErrMsg: `invalid retention policy for event type audit.log: time: invalid duration "one year"`,
+		},
+		{
+			name: "should_error_for_invalid_circuit_breaker_store_failure_mode",
+			args: args{
+				path: "./testdata/Config/invalid-circuit-breaker-store-failure-mode.json",
+			},
+			wantErr:    true,
+			wantErrMsg: `unknown circuit breaker store_failure_mode "fail_sometimes", use fail_open or fail_closed`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -598,3 +615,52 @@ func Test_DatabaseConfigurationBuildDsn(t *testing.T) {
 		})
 	}
 }
+
+func TestRetentionPolicyConfiguration_PayloadRetentionPeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset", policy: "", want: 0},
+		{name: "valid", policy: "72h", want: 72 * time.Hour},
+		{name: "invalid", policy: "three days", wantErr: true},
+		{name: "negative", policy: "-1h", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RetentionPolicyConfiguration{PayloadPolicy: tc.policy}.PayloadRetentionPeriod()
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEndpointRecoveryConfiguration_Backoff(t *testing.T) {
+	defaults := EndpointRecoveryConfiguration{}
+	require.Equal(t, uint64(10), defaults.GetMaxAttempts())
+	require.Equal(t, 5*time.Minute, defaults.Backoff(1))
+	require.Equal(t, 10*time.Minute, defaults.Backoff(2))
+	require.Equal(t, 24*time.Hour, defaults.Backoff(20))
+
+	// the backoff doubles after every failed probe, up to the max
+	e := EndpointRecoveryConfiguration{MaxAttempts: 3, InitialBackoff: 60, MaxBackoff: 300}
+	require.Equal(t, uint64(3), e.GetMaxAttempts())
+	require.Equal(t, time.Minute, e.Backoff(1))
+	require.Equal(t, 2*time.Minute, e.Backoff(2))
+	require.Equal(t, 4*time.Minute, e.Backoff(3))
+	require.Equal(t, 5*time.Minute, e.Backoff(4))
+}
+
+func TestConfiguration_GetMaxAttemptBodyBytes(t *testing.T) {
+	// stored bodies default to no more than the dispatcher reads
+	require.Equal(t, uint64(MaxResponseSize), Configuration{}.GetMaxAttemptBodyBytes())
+	require.Equal(t, uint64(1024), Configuration{MaxAttemptBodyBytes: 1024}.GetMaxAttemptBodyBytes())
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type ValueSource string
+
+const (
+	DefaultValueSource ValueSource = "default"
+	FileValueSource    ValueSource = "file"
+	EnvValueSource     ValueSource = "env"
+	FlagValueSource    ValueSource = "flag"
+)
+
+// EffectiveValue is a resolved configuration value and where it came from.
+type EffectiveValue struct {
+	Path   string      `json:"path"`
+	Value  interface{} `json:"value"`
+	Source ValueSource `json:"source"`
+}
+
+var envDecoderType = reflect.TypeOf((*envconfig.Decoder)(nil)).Elem()
+
+// Effective resolves the configuration the same way LoadConfig and Override
+// do: defaults, then the json config file, then environment variables, then
+// CLI flags. Every value is reported with the last source that set it.
+func Effective(p string, flags *Configuration) ([]EffectiveValue, error) {
+	c, err := readConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validate(&c); err != nil {
+		return nil, err
+	}
+
+	if flags == nil {
+		flags = &Configuration{}
+	}
+	overrideFields(reflect.ValueOf(&c).Elem(), reflect.ValueOf(flags).Elem())
+
+	fileValues, err := readConfigFileValues(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []EffectiveValue
+	walkEffective(reflect.ValueOf(c), reflect.ValueOf(*flags), "", envPrefix, fileValues, &values)
+
+	return values, nil
+}
+
+// readConfigFileValues returns the raw contents of the json config file, so
+// we can tell which keys it sets.
+func readConfigFileValues(p string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func walkEffective(v, flags reflect.Value, path, envKey string, fileValues map[string]interface{}, out *[]EffectiveValue) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		// mirror how envconfig names the variables it reads
+		envTag := field.Tag.Get("envconfig")
+		key := field.Name
+		if envTag != "" {
+			key = envTag
+		}
+		key = strings.ToUpper(envKey + "_" + key)
+
+		fileValue, inFile := fileValues[name]
+
+		if field.Type.Kind() == reflect.Struct && envTag == "" && !reflect.PointerTo(field.Type).Implements(envDecoderType) {
+			nested, _ := fileValue.(map[string]interface{})
+			walkEffective(v.Field(i), flags.Field(i), fieldPath, key, nested, out)
+			continue
+		}
+
+		source := DefaultValueSource
+		if inFile {
+			source = FileValueSource
+		}
+		if isEnvSet(key, envTag) {
+			source = EnvValueSource
+		}
+		if !flags.Field(i).IsZero() {
+			source = FlagValueSource
+		}
+
+		*out = append(*out, EffectiveValue{
+			Path:   fieldPath,
+			Value:  v.Field(i).Interface(),
+			Source: source,
+		})
+	}
+}
+
+func isEnvSet(key, envTag string) bool {
+	if _, ok := os.LookupEnv(key); ok {
+		return true
+	}
+
+	if envTag == "" {
+		return false
+	}
+
+	_, ok := os.LookupEnv(strings.ToUpper(envTag))
+	return ok
+}
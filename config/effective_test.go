@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffective(t *testing.T) {
+	t.Setenv("CONVOY_REDIS_HOST", "redis-from-env")
+	t.Setenv("CONVOY_DB_HOST", "db-from-env")
+
+	flags := &Configuration{
+		Database: DatabaseConfiguration{Host: "db-from-flag"},
+		Server:   ServerConfiguration{HTTP: HTTPServerConfiguration{Port: 9090}},
+	}
+
+	values, err := Effective("./testdata/Config/valid-convoy.json", flags)
+	require.NoError(t, err)
+
+	byPath := make(map[string]EffectiveValue, len(values))
+	for _, v := range values {
+		byPath[v.Path] = v
+	}
+
+	tests := []struct {
+		path       string
+		wantValue  interface{}
+		wantSource ValueSource
+	}{
+		// set in the file, env and flags; the flag wins
+		{path: "database.host", wantValue: "db-from-flag", wantSource: FlagValueSource},
+		// set in the file and flags; the flag overrides the file
+		{path: "server.http.port", wantValue: uint32(9090), wantSource: FlagValueSource},
+		// set in the file and env
+		{path: "redis.host", wantValue: "redis-from-env", wantSource: EnvValueSource},
+		// set only in the file
+		{path: "database.username", wantValue: "postgres", wantSource: FileValueSource},
+		{path: "retention_policy.enabled", wantValue: true, wantSource: FileValueSource},
+		// set in the file to the default value
+		{path: "retention_policy.policy", wantValue: "720h", wantSource: FileValueSource},
+		// never set
+		{path: "api_rate_limit", wantValue: DefaultConfiguration.ApiRateLimit, wantSource: DefaultValueSource},
+		{path: "database.read_replicas", wantValue: ReadReplicaConfiguration(nil), wantSource: DefaultValueSource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			v, ok := byPath[tt.path]
+			require.True(t, ok)
+			require.Equal(t, tt.wantValue, v.Value)
+			require.Equal(t, tt.wantSource, v.Source)
+		})
+	}
+}
+
+func TestEffective_MatchesLoadedConfig(t *testing.T) {
+	t.Setenv("CONVOY_REDIS_HOST", "redis-from-env")
+
+	p := "./testdata/Config/valid-convoy.json"
+	flags := &Configuration{Logger: LoggerConfiguration{Level: "debug"}}
+
+	require.NoError(t, LoadConfig(p))
+	require.NoError(t, Override(flags))
+
+	cfg, err := Get()
+	require.NoError(t, err)
+
+	values, err := Effective(p, flags)
+	require.NoError(t, err)
+
+	byPath := make(map[string]interface{}, len(values))
+	for _, v := range values {
+		byPath[v.Path] = v.Value
+	}
+
+	require.Equal(t, cfg.Logger.Level, byPath["logger.level"])
+	require.Equal(t, cfg.Redis.Host, byPath["redis.host"])
+	require.Equal(t, cfg.Database.Host, byPath["database.host"])
+	require.Equal(t, cfg.MaxResponseSize, byPath["max_response_size"])
+}
@@ -3,10 +3,12 @@ package listener
 import (
 	"context"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/sse"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/frain-dev/convoy/services"
+	"github.com/redis/go-redis/v9"
 	"gopkg.in/guregu/null.v4"
 	"time"
 )
@@ -14,6 +16,7 @@ import (
 type EventDeliveryListener struct {
 	mEvent       *services.MetaEvent
 	attemptsRepo datastore.DeliveryAttemptsRepository
+	redis        redis.UniversalClient
 }
 
 type MetaEventDelivery struct {
@@ -42,9 +45,9 @@ type MetaEventDelivery struct {
 	DeletedAt       null.Time                     `json:"deleted_at,omitempty"`
 }
 
-func NewEventDeliveryListener(queue queue.Queuer, projectRepo datastore.ProjectRepository, metaEventRepo datastore.MetaEventRepository, attemptsRepo datastore.DeliveryAttemptsRepository) *EventDeliveryListener {
+func NewEventDeliveryListener(queue queue.Queuer, projectRepo datastore.ProjectRepository, metaEventRepo datastore.MetaEventRepository, attemptsRepo datastore.DeliveryAttemptsRepository, redis redis.UniversalClient) *EventDeliveryListener {
 	mEvent := services.NewMetaEvent(queue, projectRepo, metaEventRepo)
-	return &EventDeliveryListener{mEvent: mEvent, attemptsRepo: attemptsRepo}
+	return &EventDeliveryListener{mEvent: mEvent, attemptsRepo: attemptsRepo, redis: redis}
 }
 
 func (e *EventDeliveryListener) AfterUpdate(ctx context.Context, data interface{}, _ interface{}) {
@@ -54,6 +57,13 @@ func (e *EventDeliveryListener) AfterUpdate(ctx context.Context, data interface{
 		return
 	}
 
+	if e.redis != nil {
+		err := sse.Publish(ctx, e.redis, sse.NewDeliveryStatusUpdate(eventDelivery))
+		if err != nil {
+			log.WithError(err).Error("failed to publish event delivery status update")
+		}
+	}
+
 	mEventDelivery := getMetaEventDelivery(eventDelivery)
 	attempts, err := e.attemptsRepo.FindDeliveryAttempts(ctx, mEventDelivery.UID)
 	if err != nil {
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+const (
+	defaultAttemptBatchSize     = 100
+	defaultAttemptFlushInterval = 500 * time.Millisecond
+	defaultAttemptBufferSize    = 1000
+
+	// attemptFlushTimeout bounds a single bulk write
+	attemptFlushTimeout = 30 * time.Second
+)
+
+type DeliveryAttemptBatchOptions struct {
+	// Size is the number of attempts written in one bulk insert
+	Size int
+
+	// FlushInterval is the longest an attempt waits in the buffer
+	FlushInterval time.Duration
+
+	// Workers is the number of concurrent writers draining the buffer
+	Workers int
+
+	// BufferSize is how many attempts can be waiting to be written, before
+	// CreateDeliveryAttempt blocks
+	BufferSize int
+}
+
+// BatchedDeliveryAttemptRepo buffers delivery attempts in memory and writes them
+// with CreateDeliveryAttempts, when a batch is full or FlushInterval elapses.
+// CreateDeliveryAttempt returns as soon as the attempt is buffered, so an attempt
+// can be missing for up to FlushInterval after its delivery is updated.
+//
+// The buffer is flushed when the repo is closed. Attempts still in it when the
+// process dies are lost, nothing else is: they only record what happened to
+// deliveries whose status is written separately.
+type BatchedDeliveryAttemptRepo struct {
+	datastore.DeliveryAttemptsRepository
+
+	opts     DeliveryAttemptBatchOptions
+	attempts chan *datastore.DeliveryAttempt
+	wg       sync.WaitGroup
+
+	// mu guards closed, senders hold it while sending so the channel
+	// is never closed underneath them
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ datastore.DeliveryAttemptsRepository = (*BatchedDeliveryAttemptRepo)(nil)
+
+// NewBatchedDeliveryAttemptRepo starts the writers for repo. The buffer is
+// flushed and the writers stopped once ctx is done or Close is called.
+func NewBatchedDeliveryAttemptRepo(ctx context.Context, repo datastore.DeliveryAttemptsRepository, opts DeliveryAttemptBatchOptions) *BatchedDeliveryAttemptRepo {
+	if opts.Size <= 0 {
+		opts.Size = defaultAttemptBatchSize
+	}
+
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultAttemptFlushInterval
+	}
+
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultAttemptBufferSize
+	}
+
+	b := &BatchedDeliveryAttemptRepo{
+		DeliveryAttemptsRepository: repo,
+		opts:                       opts,
+		attempts:                   make(chan *datastore.DeliveryAttempt, opts.BufferSize),
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		b.wg.Add(1)
+		go b.run()
+	}
+
+	go func() {
+		<-ctx.Done()
+		b.Close()
+	}()
+
+	return b
+}
+
+// CreateDeliveryAttempt buffers the attempt for the next batch. Once the repo
+// is closed attempts are written straight away.
+func (b *BatchedDeliveryAttemptRepo) CreateDeliveryAttempt(ctx context.Context, attempt *datastore.DeliveryAttempt) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return b.DeliveryAttemptsRepository.CreateDeliveryAttempt(ctx, attempt)
+	}
+
+	// callers are free to reuse the attempt once we return
+	a := *attempt
+
+	select {
+	case b.attempts <- &a:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops buffering attempts and waits for the buffer to be written.
+func (b *BatchedDeliveryAttemptRepo) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.attempts)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+func (b *BatchedDeliveryAttemptRepo) run() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*datastore.DeliveryAttempt, 0, b.opts.Size)
+	for {
+		select {
+		case attempt, ok := <-b.attempts:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+
+			batch = append(batch, attempt)
+			if len(batch) >= b.opts.Size {
+				b.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (b *BatchedDeliveryAttemptRepo) flush(batch []*datastore.DeliveryAttempt) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), attemptFlushTimeout)
+	defer cancel()
+
+	err := b.DeliveryAttemptsRepository.CreateDeliveryAttempts(ctx, batch)
+	if err != nil {
+		log.WithError(err).Errorf("failed to write a batch of %d delivery attempts", len(batch))
+	}
+}
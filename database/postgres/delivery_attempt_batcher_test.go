@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+)
+
+// attemptRecorder keeps every batch written through CreateDeliveryAttempts
+type attemptRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (r *attemptRecorder) record(_ context.Context, attempts []*datastore.DeliveryAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(attempts))
+	for _, a := range attempts {
+		ids = append(ids, a.UID)
+	}
+	r.batches = append(r.batches, ids)
+	return nil
+}
+
+func (r *attemptRecorder) persisted() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for _, b := range r.batches {
+		ids = append(ids, b...)
+	}
+	return ids
+}
+
+func (r *attemptRecorder) batchSizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sizes := make([]int, 0, len(r.batches))
+	for _, b := range r.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func newAttempt() *datastore.DeliveryAttempt {
+	return &datastore.DeliveryAttempt{UID: ulid.Make().String(), EventDeliveryId: "delivery-1"}
+}
+
+func TestBatchedDeliveryAttemptRepo_BatchesBySize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+
+	rec := &attemptRecorder{}
+	repo.EXPECT().CreateDeliveryAttempts(gomock.Any(), gomock.Any()).DoAndReturn(rec.record).Times(3)
+
+	b := NewBatchedDeliveryAttemptRepo(context.Background(), repo, DeliveryAttemptBatchOptions{Size: 3, FlushInterval: time.Hour})
+
+	var want []string
+	for i := 0; i < 7; i++ {
+		a := newAttempt()
+		want = append(want, a.UID)
+		require.NoError(t, b.CreateDeliveryAttempt(context.Background(), a))
+	}
+
+	require.Eventually(t, func() bool { return len(rec.batchSizes()) == 2 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, []int{3, 3}, rec.batchSizes())
+
+	// closing flushes what's left in the buffer
+	b.Close()
+	require.Equal(t, []int{3, 3, 1}, rec.batchSizes())
+	require.Equal(t, want, rec.persisted())
+}
+
+func TestBatchedDeliveryAttemptRepo_FlushesOnInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+
+	rec := &attemptRecorder{}
+	repo.EXPECT().CreateDeliveryAttempts(gomock.Any(), gomock.Any()).DoAndReturn(rec.record).MinTimes(1)
+
+	b := NewBatchedDeliveryAttemptRepo(context.Background(), repo, DeliveryAttemptBatchOptions{Size: 100, FlushInterval: 10 * time.Millisecond})
+	defer b.Close()
+
+	first, second := newAttempt(), newAttempt()
+	require.NoError(t, b.CreateDeliveryAttempt(context.Background(), first))
+	require.NoError(t, b.CreateDeliveryAttempt(context.Background(), second))
+
+	require.Eventually(t, func() bool { return len(rec.persisted()) == 2 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, []string{first.UID, second.UID}, rec.persisted())
+}
+
+func TestBatchedDeliveryAttemptRepo_ConcurrentWriters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+
+	rec := &attemptRecorder{}
+	repo.EXPECT().CreateDeliveryAttempts(gomock.Any(), gomock.Any()).DoAndReturn(rec.record).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := NewBatchedDeliveryAttemptRepo(ctx, repo, DeliveryAttemptBatchOptions{Size: 10, FlushInterval: time.Hour, Workers: 4, BufferSize: 5})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				require.NoError(t, b.CreateDeliveryAttempt(context.Background(), newAttempt()))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// cancelling the context shuts the batcher down like Close does
+	cancel()
+	require.Eventually(t, func() bool { return len(rec.persisted()) == 500 }, time.Second, 5*time.Millisecond)
+
+	for _, size := range rec.batchSizes() {
+		require.LessOrEqual(t, size, 10)
+	}
+}
+
+func TestBatchedDeliveryAttemptRepo_WritesDirectlyOnceClosed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+
+	b := NewBatchedDeliveryAttemptRepo(context.Background(), repo, DeliveryAttemptBatchOptions{})
+	b.Close()
+
+	a := newAttempt()
+	repo.EXPECT().CreateDeliveryAttempt(gomock.Any(), a).Return(nil).Times(1)
+	require.NoError(t, b.CreateDeliveryAttempt(context.Background(), a))
+}
+
+func TestBatchedDeliveryAttemptRepo_FailedFlushOnlyLosesTheBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+
+	rec := &attemptRecorder{}
+	gomock.InOrder(
+		repo.EXPECT().CreateDeliveryAttempts(gomock.Any(), gomock.Any()).Return(errors.New("connection reset")).Times(1),
+		repo.EXPECT().CreateDeliveryAttempts(gomock.Any(), gomock.Any()).DoAndReturn(rec.record).Times(1),
+	)
+
+	b := NewBatchedDeliveryAttemptRepo(context.Background(), repo, DeliveryAttemptBatchOptions{Size: 2, FlushInterval: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.CreateDeliveryAttempt(context.Background(), newAttempt()))
+	}
+
+	kept := newAttempt()
+	require.NoError(t, b.CreateDeliveryAttempt(context.Background(), kept))
+
+	b.Close()
+	require.Equal(t, []string{kept.UID}, rec.persisted())
+}
@@ -28,6 +28,13 @@ const (
 	creatDeliveryAttempt = `
     INSERT INTO convoy.delivery_attempts (id, url, method, api_version, endpoint_id, event_delivery_id, project_id, ip_address, request_http_header, response_http_header, http_status, response_data, error, status)
     VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14);
+    `
+
+	// attempts already written are skipped, so a batch can safely be written again
+	createDeliveryAttempts = `
+    INSERT INTO convoy.delivery_attempts (id, url, method, api_version, endpoint_id, event_delivery_id, project_id, ip_address, request_http_header, response_http_header, http_status, response_data, error, status)
+    VALUES (:id, :url, :method, :api_version, :endpoint_id, :event_delivery_id, :project_id, :ip_address, :request_http_header, :response_http_header, :http_status, :response_data, :error, :status)
+    ON CONFLICT DO NOTHING;
     `
 
 	softDeleteProjectDeliveryAttempts = `
@@ -65,6 +72,59 @@ func (d *deliveryAttemptRepo) CreateDeliveryAttempt(ctx context.Context, attempt
 	return nil
 }
 
+// CreateDeliveryAttempts writes the attempts in bulk, in a single transaction.
+func (d *deliveryAttemptRepo) CreateDeliveryAttempts(ctx context.Context, attempts []*datastore.DeliveryAttempt) error {
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	values := make([]map[string]interface{}, 0, len(attempts))
+	for _, attempt := range attempts {
+		values = append(values, map[string]interface{}{
+			"id":                   attempt.UID,
+			"url":                  attempt.URL,
+			"method":               attempt.Method,
+			"api_version":          attempt.APIVersion,
+			"endpoint_id":          attempt.EndpointID,
+			"event_delivery_id":    attempt.EventDeliveryId,
+			"project_id":           attempt.ProjectId,
+			"ip_address":           attempt.IPAddress,
+			"request_http_header":  attempt.RequestHeader,
+			"response_http_header": attempt.ResponseHeader,
+			"http_status":          attempt.HttpResponseCode,
+			"response_data":        attempt.ResponseData,
+			"error":                attempt.Error,
+			"status":               attempt.Status,
+		})
+	}
+
+	tx, isWrapped, err := GetTx(ctx, d.db.GetDB())
+	if err != nil {
+		return err
+	}
+
+	if !isWrapped {
+		defer rollbackTx(tx)
+	}
+
+	// stay well under postgres' limit of 65535 bind parameters per statement
+	const chunkSize = 1000
+	for i := 0; i < len(values); i += chunkSize {
+		j := min(i+chunkSize, len(values))
+
+		_, err = tx.NamedExecContext(ctx, createDeliveryAttempts, values[i:j])
+		if err != nil {
+			return err
+		}
+	}
+
+	if isWrapped {
+		return nil
+	}
+
+	return tx.Commit()
+}
+
 func (d *deliveryAttemptRepo) FindDeliveryAttemptById(ctx context.Context, eventDeliveryId string, id string) (*datastore.DeliveryAttempt, error) {
 	attempt := &datastore.DeliveryAttempt{}
 	err := d.db.GetReadDB().QueryRowxContext(ctx, findOneDeliveryAttempt, id, eventDeliveryId).StructScan(attempt)
@@ -191,8 +251,8 @@ func (d *deliveryAttemptRepo) GetFailureAndSuccessCounts(ctx context.Context, lo
 	return resultsMap, nil
 }
 
-func (d *deliveryAttemptRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
-	return exportRecords(ctx, d.db.GetReadDB(), "convoy.delivery_attempts", projectID, createdAt, w)
+func (d *deliveryAttemptRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
+	return exportRecords(ctx, d.db.GetReadDB(), "convoy.delivery_attempts", projectID, createdAt, w, opts...)
 }
 
 func (d *deliveryAttemptRepo) PartitionDeliveryAttemptsTable(ctx context.Context) error {
@@ -9,6 +9,7 @@ import (
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestCreateDeliveryAttempt(t *testing.T) {
@@ -56,6 +57,67 @@ func TestCreateDeliveryAttempt(t *testing.T) {
 	require.Equal(t, att.ResponseData, attempt.ResponseData)
 }
 
+func TestCreateDeliveryAttempts(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	attemptsRepo := NewDeliveryAttemptRepo(db)
+	ctx := context.Background()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+	ed := generateEventDelivery(project, endpoint, event, device, sub)
+
+	edRepo := NewEventDeliveryRepo(db)
+	require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+	attempts := make([]*datastore.DeliveryAttempt, 0, 5)
+	for i := 0; i < 5; i++ {
+		attempts = append(attempts, &datastore.DeliveryAttempt{
+			UID:              ulid.Make().String(),
+			EventDeliveryId:  ed.UID,
+			URL:              "https://example.com",
+			Method:           "POST",
+			ProjectId:        project.UID,
+			EndpointID:       endpoint.UID,
+			APIVersion:       "2024-01-01",
+			IPAddress:        "192.0.0.1",
+			RequestHeader:    map[string]string{"Content-Type": "application/json"},
+			ResponseHeader:   map[string]string{"Content-Type": "application/json"},
+			HttpResponseCode: "500",
+			ResponseData:     []byte("{\"status\":\"failed\"}"),
+			Status:           false,
+		})
+	}
+
+	// writing the batch through the batcher persists all of it
+	batched := NewBatchedDeliveryAttemptRepo(ctx, attemptsRepo, DeliveryAttemptBatchOptions{Size: 2, FlushInterval: time.Hour})
+	for _, attempt := range attempts {
+		require.NoError(t, batched.CreateDeliveryAttempt(ctx, attempt))
+	}
+	batched.Close()
+
+	// and writing it again is a no-op
+	require.NoError(t, attemptsRepo.CreateDeliveryAttempts(ctx, attempts))
+
+	found, err := attemptsRepo.FindDeliveryAttempts(ctx, ed.UID)
+	require.NoError(t, err)
+	require.Len(t, found, len(attempts))
+
+	// attempts written in one transaction share created_at, so their order isn't fixed
+	var want, got []string
+	for i := range attempts {
+		want = append(want, attempts[i].UID)
+		got = append(got, found[i].UID)
+		require.Equal(t, attempts[i].ResponseData, found[i].ResponseData)
+	}
+	require.ElementsMatch(t, want, got)
+}
+
 func TestFindDeliveryAttempts(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -31,7 +31,11 @@ const (
                 rate_limit, rate_limit_duration, advanced_signatures, slack_webhook_url,
                 support_email, app_id, project_id, authentication_type, authentication_type_api_key_header_name,
                 authentication_type_api_key_header_value,
-                is_encrypted, secrets_cipher, authentication_type_api_key_header_value_cipher
+                is_encrypted, secrets_cipher, authentication_type_api_key_header_value_cipher,
+                body_format, pinned_signature_version, http_method, max_concurrent_deliveries, min_delivery_interval_ms,
+                retry_after_floor_seconds, retry_after_ceiling_seconds,
+                authentication_type_digest_username, authentication_type_digest_password,
+                authentication_type_digest_password_cipher, content_type, form_encoding
             )
             VALUES
               (
@@ -40,7 +44,11 @@ const (
                 $14, $15, $16, $17, CASE WHEN $19 THEN '' ELSE $18 END,
                $19,
                CASE WHEN $19 THEN pgp_sym_encrypt($4::TEXT, $20)  END, -- Ciphered values if encrypted
-               CASE WHEN $19 THEN pgp_sym_encrypt($18, $20) END
+               CASE WHEN $19 THEN pgp_sym_encrypt($18, $20) END,
+               $21, $22, $23, $24, $25, $26, $27,
+               $28, CASE WHEN $19 THEN '' ELSE $29 END,
+               CASE WHEN $19 THEN pgp_sym_encrypt($29, $20) END,
+               $30, $31
               );
             `
 
@@ -48,7 +56,7 @@ const (
 	SELECT
 	e.id, e.name, e.status, e.owner_id,
 	e.url, e.description, e.http_timeout,
-	e.rate_limit, e.rate_limit_duration, e.advanced_signatures,
+	e.rate_limit, e.rate_limit_duration, e.advanced_signatures, e.body_format, e.pinned_signature_version, e.http_method, e.max_concurrent_deliveries, e.min_delivery_interval_ms, e.retry_after_floor_seconds, e.retry_after_ceiling_seconds, e.content_type, e.form_encoding,
 	e.slack_webhook_url, e.support_email, e.app_id,
 	e.project_id,
 	CASE
@@ -60,7 +68,12 @@ const (
 	CASE
         WHEN e.is_encrypted THEN pgp_sym_decrypt(e.authentication_type_api_key_header_value_cipher::bytea, $1)::TEXT
         ELSE e.authentication_type_api_key_header_value
-    END AS "authentication.api_key.header_value"
+    END AS "authentication.api_key.header_value",
+	e.authentication_type_digest_username AS "authentication.digest_auth.username",
+	CASE
+        WHEN e.is_encrypted THEN COALESCE(pgp_sym_decrypt(e.authentication_type_digest_password_cipher::bytea, $1)::TEXT, '')
+        ELSE e.authentication_type_digest_password
+    END AS "authentication.digest_auth.password"
 	FROM convoy.endpoints AS e
 	WHERE e.deleted_at IS NULL
 	`
@@ -76,7 +89,7 @@ const (
 	fetchEndpointByTargetURL = `
     SELECT e.id, e.name, e.status, e.owner_id, e.url,
     e.description, e.http_timeout, e.rate_limit, e.rate_limit_duration,
-    e.advanced_signatures, e.slack_webhook_url, e.support_email,
+    e.advanced_signatures, e.body_format, e.pinned_signature_version, e.http_method, e.max_concurrent_deliveries, e.min_delivery_interval_ms, e.retry_after_floor_seconds, e.retry_after_ceiling_seconds, e.content_type, e.form_encoding, e.slack_webhook_url, e.support_email,
     e.app_id, e.project_id,
     CASE
         WHEN e.is_encrypted THEN pgp_sym_decrypt(e.secrets_cipher::bytea, $3)::jsonb
@@ -87,7 +100,12 @@ const (
 	CASE
         WHEN e.is_encrypted THEN pgp_sym_decrypt(e.authentication_type_api_key_header_value_cipher::bytea, $3)::TEXT
         ELSE e.authentication_type_api_key_header_value
-    END AS "authentication.api_key.header_value"
+    END AS "authentication.api_key.header_value",
+	e.authentication_type_digest_username AS "authentication.digest_auth.username",
+	CASE
+        WHEN e.is_encrypted THEN COALESCE(pgp_sym_decrypt(e.authentication_type_digest_password_cipher::bytea, $3)::TEXT, '')
+        ELSE e.authentication_type_digest_password
+    END AS "authentication.digest_auth.password"
     FROM convoy.endpoints AS e WHERE e.deleted_at IS NULL AND e.url = $1 AND e.project_id = $2;
     `
 
@@ -96,7 +114,10 @@ const (
 	name = $3, status = $4, owner_id = $5,
 	url = $6, description = $7, http_timeout = $8,
 	rate_limit = $9, rate_limit_duration = $10, advanced_signatures = $11,
-	slack_webhook_url = $12, support_email = $13,
+	slack_webhook_url = $12, support_email = $13, body_format = $19,
+	pinned_signature_version = $20, http_method = $21, max_concurrent_deliveries = $22, min_delivery_interval_ms = $23,
+	retry_after_floor_seconds = $24, retry_after_ceiling_seconds = $25,
+	content_type = $28, form_encoding = $29,
 	authentication_type = $14, authentication_type_api_key_header_name = $15,
 	authentication_type_api_key_header_value_cipher = CASE
         WHEN is_encrypted THEN pgp_sym_encrypt($16, $18)
@@ -105,6 +126,14 @@ const (
         WHEN is_encrypted THEN ''
         ELSE $16
     END,
+    authentication_type_digest_username = $26,
+    authentication_type_digest_password_cipher = CASE
+        WHEN is_encrypted THEN pgp_sym_encrypt($27, $18)
+    END,
+    authentication_type_digest_password = CASE
+        WHEN is_encrypted THEN ''
+        ELSE $27
+    END,
     secrets_cipher = CASE
         WHEN is_encrypted THEN pgp_sym_encrypt($17::jsonb::TEXT, $18)
     END,
@@ -121,7 +150,7 @@ const (
 	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL RETURNING
 	id, name, status, owner_id, url,
     description, http_timeout, rate_limit, rate_limit_duration,
-    advanced_signatures, slack_webhook_url, support_email,
+    advanced_signatures, body_format, pinned_signature_version, http_method, max_concurrent_deliveries, min_delivery_interval_ms, retry_after_floor_seconds, retry_after_ceiling_seconds, content_type, form_encoding, slack_webhook_url, support_email,
     app_id, project_id,
     CASE
         WHEN is_encrypted THEN pgp_sym_decrypt(secrets_cipher::bytea, $4)::jsonb
@@ -132,7 +161,12 @@ const (
     CASE
         WHEN is_encrypted THEN pgp_sym_decrypt(authentication_type_api_key_header_value_cipher::bytea, $4)::TEXT
         ELSE authentication_type_api_key_header_value
-    END AS "authentication.api_key.header_value";
+    END AS "authentication.api_key.header_value",
+    authentication_type_digest_username AS "authentication.digest_auth.username",
+    CASE
+        WHEN is_encrypted THEN COALESCE(pgp_sym_decrypt(authentication_type_digest_password_cipher::bytea, $4)::TEXT, '')
+        ELSE authentication_type_digest_password
+    END AS "authentication.digest_auth.password";
 	`
 
 	updateEndpointSecrets = `
@@ -148,7 +182,7 @@ const (
 	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL RETURNING
 	id, name, status, owner_id, url,
     description, http_timeout, rate_limit, rate_limit_duration,
-    advanced_signatures, slack_webhook_url, support_email,
+    advanced_signatures, body_format, pinned_signature_version, http_method, max_concurrent_deliveries, min_delivery_interval_ms, retry_after_floor_seconds, retry_after_ceiling_seconds, content_type, form_encoding, slack_webhook_url, support_email,
     app_id, project_id,
 	CASE
         WHEN is_encrypted THEN pgp_sym_decrypt(secrets_cipher::bytea, $4)::jsonb
@@ -160,7 +194,12 @@ const (
     CASE
         WHEN is_encrypted THEN pgp_sym_decrypt(authentication_type_api_key_header_value_cipher::bytea, $4)::TEXT
         ELSE authentication_type_api_key_header_value
-    END AS "authentication.api_key.header_value";
+    END AS "authentication.api_key.header_value",
+    authentication_type_digest_username AS "authentication.digest_auth.username",
+    CASE
+        WHEN is_encrypted THEN COALESCE(pgp_sym_decrypt(authentication_type_digest_password_cipher::bytea, $4)::TEXT, '')
+        ELSE authentication_type_digest_password
+    END AS "authentication.digest_auth.password";
 	`
 
 	deleteEndpoint = `
@@ -182,7 +221,7 @@ const (
 	SELECT
 	e.id, e.name, e.status, e.owner_id,
 	e.url, e.description, e.http_timeout,
-	e.rate_limit, e.rate_limit_duration, e.advanced_signatures,
+	e.rate_limit, e.rate_limit_duration, e.advanced_signatures, e.body_format, e.pinned_signature_version, e.http_method, e.max_concurrent_deliveries, e.min_delivery_interval_ms, e.retry_after_floor_seconds, e.retry_after_ceiling_seconds, e.content_type, e.form_encoding,
 	e.slack_webhook_url, e.support_email, e.app_id,
 	e.project_id,
     CASE
@@ -194,7 +233,12 @@ const (
 	CASE
         WHEN e.is_encrypted THEN pgp_sym_decrypt(e.authentication_type_api_key_header_value_cipher::bytea, :encryption_key)::TEXT
         ELSE e.authentication_type_api_key_header_value
-    END AS "authentication.api_key.header_value"
+    END AS "authentication.api_key.header_value",
+	e.authentication_type_digest_username AS "authentication.digest_auth.username",
+	CASE
+        WHEN e.is_encrypted THEN COALESCE(pgp_sym_decrypt(e.authentication_type_digest_password_cipher::bytea, :encryption_key)::TEXT, '')
+        ELSE e.authentication_type_digest_password
+    END AS "authentication.digest_auth.password"
 	FROM convoy.endpoints AS e
 	WHERE e.deleted_at IS NULL
 	AND e.project_id = :project_id
@@ -233,6 +277,34 @@ const (
 	GROUP BY s.id
 	ORDER BY s.id DESC
 	LIMIT 1`
+
+	fetchEndpointRecoveries = `
+	SELECT r.endpoint_id, r.project_id, r.attempts, r.next_probe_at, r.created_at, r.updated_at
+	FROM convoy.endpoint_recoveries r
+	JOIN convoy.endpoints e ON e.id = r.endpoint_id
+	WHERE r.project_id = $1
+	AND e.status = 'inactive'
+	AND e.deleted_at IS NULL;
+	`
+
+	createEndpointRecovery = `
+	INSERT INTO convoy.endpoint_recoveries (endpoint_id, project_id, attempts, next_probe_at)
+	VALUES ($1, $2, 0, NOW())
+	ON CONFLICT (endpoint_id) DO NOTHING;
+	`
+
+	upsertEndpointRecovery = `
+	INSERT INTO convoy.endpoint_recoveries (endpoint_id, project_id, attempts, next_probe_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (endpoint_id) DO UPDATE SET
+	attempts = EXCLUDED.attempts,
+	next_probe_at = EXCLUDED.next_probe_at,
+	updated_at = NOW();
+	`
+
+	deleteEndpointRecovery = `
+	DELETE FROM convoy.endpoint_recoveries WHERE endpoint_id = $1 AND project_id = $2;
+	`
 )
 
 type endpointRepo struct {
@@ -282,6 +354,10 @@ func (e *endpointRepo) CreateEndpoint(ctx context.Context, endpoint *datastore.E
 		endpoint.Description, endpoint.HttpTimeout, endpoint.RateLimit, endpoint.RateLimitDuration,
 		endpoint.AdvancedSignatures, endpoint.SlackWebhookURL, endpoint.SupportEmail, endpoint.AppID,
 		projectID, ac.Type, ac.ApiKey.HeaderName, ac.ApiKey.HeaderValue, isEncrypted, key,
+		endpoint.GetBodyFormat(), endpoint.PinnedSignatureVersion, endpoint.GetHttpMethod(), endpoint.MaxConcurrentDeliveries, endpoint.MinDeliveryIntervalMs,
+		endpoint.RetryAfterFloorSeconds, endpoint.RetryAfterCeilingSeconds,
+		ac.DigestAuth.Username, ac.DigestAuth.Password,
+		endpoint.GetContentType(), endpoint.GetFormEncoding(),
 	}
 
 	result, err := e.db.GetDB().ExecContext(ctx, createEndpoint, args...)
@@ -328,6 +404,7 @@ func (e *endpointRepo) FindEndpointByID(ctx context.Context, id, projectID strin
 		return nil, err
 	}
 
+	dropUnusedDigestAuth(endpoint)
 	return endpoint, nil
 }
 
@@ -400,6 +477,10 @@ func (e *endpointRepo) UpdateEndpoint(ctx context.Context, endpoint *datastore.E
 		endpoint.Description, endpoint.HttpTimeout, endpoint.RateLimit, endpoint.RateLimitDuration,
 		endpoint.AdvancedSignatures, endpoint.SlackWebhookURL, endpoint.SupportEmail,
 		ac.Type, ac.ApiKey.HeaderName, ac.ApiKey.HeaderValue, endpoint.Secrets, key,
+		endpoint.GetBodyFormat(), endpoint.PinnedSignatureVersion, endpoint.GetHttpMethod(), endpoint.MaxConcurrentDeliveries, endpoint.MinDeliveryIntervalMs,
+		endpoint.RetryAfterFloorSeconds, endpoint.RetryAfterCeilingSeconds,
+		ac.DigestAuth.Username, ac.DigestAuth.Password,
+		endpoint.GetContentType(), endpoint.GetFormEncoding(),
 	)
 	if err != nil {
 		isEncErr, err2 := e.isEncryptionError(err)
@@ -437,9 +518,44 @@ func (e *endpointRepo) UpdateEndpointStatus(ctx context.Context, projectID strin
 		return err
 	}
 
+	// an endpoint re-enabled, by hand or by a recovery probe, gets a fresh
+	// set of probes if it's disabled again
+	if status == datastore.ActiveEndpointStatus {
+		_, err = e.db.GetDB().ExecContext(ctx, deleteEndpointRecovery, endpointID, projectID)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// LoadEndpointRecoveries returns the recovery probes of the project's
+// endpoints the circuit breaker disabled and that are still disabled.
+func (e *endpointRepo) LoadEndpointRecoveries(ctx context.Context, projectID string) ([]datastore.EndpointRecovery, error) {
+	recoveries := make([]datastore.EndpointRecovery, 0)
+	err := e.db.GetReadDB().SelectContext(ctx, &recoveries, fetchEndpointRecoveries, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveries, nil
+}
+
+// CreateEndpointRecovery records that the circuit breaker disabled an
+// endpoint, which makes it due a recovery probe right away. An endpoint
+// that's already being probed keeps its probes.
+func (e *endpointRepo) CreateEndpointRecovery(ctx context.Context, projectID, endpointID string) error {
+	_, err := e.db.GetDB().ExecContext(ctx, createEndpointRecovery, endpointID, projectID)
+	return err
+}
+
+// UpsertEndpointRecovery records the probes sent to a disabled endpoint so far.
+func (e *endpointRepo) UpsertEndpointRecovery(ctx context.Context, recovery *datastore.EndpointRecovery) error {
+	_, err := e.db.GetDB().ExecContext(ctx, upsertEndpointRecovery, recovery.EndpointID, recovery.ProjectID, recovery.Attempts, recovery.NextProbeAt)
+	return err
+}
+
 func (e *endpointRepo) DeleteEndpoint(ctx context.Context, endpoint *datastore.Endpoint, projectID string) error {
 	tx, err := e.db.GetDB().BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -500,6 +616,7 @@ func (e *endpointRepo) FindEndpointByTargetURL(ctx context.Context, projectID st
 		return nil, err
 	}
 
+	dropUnusedDigestAuth(endpoint)
 	return endpoint, nil
 }
 
@@ -665,12 +782,21 @@ func (e *endpointRepo) scanEndpoints(rows *sqlx.Rows) ([]datastore.Endpoint, err
 			return nil, err
 		}
 
+		dropUnusedDigestAuth(&endpoint)
 		endpoints = append(endpoints, endpoint)
 	}
 
 	return endpoints, nil
 }
 
+// dropUnusedDigestAuth clears the empty digest credentials scanned for
+// endpoints that authenticate some other way
+func dropUnusedDigestAuth(endpoint *datastore.Endpoint) {
+	if endpoint.Authentication != nil && endpoint.Authentication.Type != datastore.DigestAuthentication {
+		endpoint.Authentication.DigestAuth = nil
+	}
+}
+
 type EndpointPaginated struct {
 	EndpointSecret
 }
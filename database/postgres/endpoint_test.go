@@ -98,6 +98,42 @@ func Test_UpdateEndpoint_Encrypted(t *testing.T) {
 	runUpdateEndpointTest(t, db)
 }
 
+func Test_CreateEndpoint_DigestAuth(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	runDigestAuthEndpointTest(t, db)
+
+	assertAndInitEncryption(t, db)
+
+	runDigestAuthEndpointTest(t, db)
+}
+
+func runDigestAuthEndpointTest(t *testing.T, db database.Database) {
+	endpointRepo := NewEndpointRepo(db)
+
+	project := seedProject(t, db)
+	endpoint := generateEndpoint(project)
+	endpoint.Authentication = &datastore.EndpointAuthentication{
+		Type:       datastore.DigestAuthentication,
+		DigestAuth: &datastore.DigestAuth{Username: "convoy", Password: "s3cret"},
+	}
+
+	require.NoError(t, endpointRepo.CreateEndpoint(context.Background(), endpoint, project.UID))
+
+	dbEndpoint, err := endpointRepo.FindEndpointByID(context.Background(), endpoint.UID, project.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.DigestAuthentication, dbEndpoint.Authentication.Type)
+	require.Equal(t, endpoint.Authentication.DigestAuth, dbEndpoint.Authentication.DigestAuth)
+
+	dbEndpoint.Authentication.DigestAuth.Password = "n3w-s3cret"
+	require.NoError(t, endpointRepo.UpdateEndpoint(context.Background(), dbEndpoint, project.UID))
+
+	dbEndpoint, err = endpointRepo.FindEndpointByID(context.Background(), endpoint.UID, project.UID)
+	require.NoError(t, err)
+	require.Equal(t, "n3w-s3cret", dbEndpoint.GetDigestAuth().Password)
+}
+
 func assertAndInitEncryption(t *testing.T, db database.Database) {
 	isEncrypted, err := checkEncryptionStatus(db)
 	require.NoError(t, err)
@@ -151,6 +187,62 @@ func runUpdateEndpointStatusTest(t *testing.T, db database.Database) {
 	require.Equal(t, status, dbEndpoint.Status)
 }
 
+func Test_EndpointRecoveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	endpointRepo := NewEndpointRepo(db)
+	endpoint := seedEndpoint(t, db)
+	ctx := context.Background()
+
+	// an endpoint disabled by hand isn't probed
+	require.NoError(t, endpointRepo.UpdateEndpointStatus(ctx, endpoint.ProjectID, endpoint.UID, datastore.InactiveEndpointStatus))
+
+	recoveries, err := endpointRepo.LoadEndpointRecoveries(ctx, endpoint.ProjectID)
+	require.NoError(t, err)
+	require.Empty(t, recoveries)
+
+	// one the breaker disabled is due a probe right away
+	require.NoError(t, endpointRepo.CreateEndpointRecovery(ctx, endpoint.ProjectID, endpoint.UID))
+
+	recoveries, err = endpointRepo.LoadEndpointRecoveries(ctx, endpoint.ProjectID)
+	require.NoError(t, err)
+	require.Len(t, recoveries, 1)
+	require.Equal(t, uint64(0), recoveries[0].Attempts)
+	require.False(t, recoveries[0].NextProbeAt.After(time.Now()))
+
+	nextProbeAt := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	recovery := &datastore.EndpointRecovery{EndpointID: endpoint.UID, ProjectID: endpoint.ProjectID, Attempts: 1, NextProbeAt: nextProbeAt}
+	require.NoError(t, endpointRepo.UpsertEndpointRecovery(ctx, recovery))
+
+	recovery.Attempts, recovery.NextProbeAt = 2, nextProbeAt.Add(time.Minute)
+	require.NoError(t, endpointRepo.UpsertEndpointRecovery(ctx, recovery))
+
+	// the breaker tripping again doesn't reset the probes
+	require.NoError(t, endpointRepo.CreateEndpointRecovery(ctx, endpoint.ProjectID, endpoint.UID))
+
+	recoveries, err = endpointRepo.LoadEndpointRecoveries(ctx, endpoint.ProjectID)
+	require.NoError(t, err)
+	require.Len(t, recoveries, 1)
+	require.Equal(t, uint64(2), recoveries[0].Attempts)
+	require.True(t, recoveries[0].NextProbeAt.Equal(nextProbeAt.Add(time.Minute)))
+
+	// an endpoint paused in the meantime isn't probed either
+	require.NoError(t, endpointRepo.UpdateEndpointStatus(ctx, endpoint.ProjectID, endpoint.UID, datastore.PausedEndpointStatus))
+
+	recoveries, err = endpointRepo.LoadEndpointRecoveries(ctx, endpoint.ProjectID)
+	require.NoError(t, err)
+	require.Empty(t, recoveries)
+
+	// re-enabling the endpoint clears its probes
+	require.NoError(t, endpointRepo.UpdateEndpointStatus(ctx, endpoint.ProjectID, endpoint.UID, datastore.ActiveEndpointStatus))
+	require.NoError(t, endpointRepo.UpdateEndpointStatus(ctx, endpoint.ProjectID, endpoint.UID, datastore.InactiveEndpointStatus))
+
+	recoveries, err = endpointRepo.LoadEndpointRecoveries(ctx, endpoint.ProjectID)
+	require.NoError(t, err)
+	require.Empty(t, recoveries)
+}
+
 func Test_DeleteEndpoint(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
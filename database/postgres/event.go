@@ -660,8 +660,8 @@ func (e *eventRepo) CopyRows(ctx context.Context, projectID string, interval int
 	return tx.Commit()
 }
 
-func (e *eventRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
-	return exportRecords(ctx, e.db.GetReadDB(), "convoy.events", projectID, createdAt, w)
+func (e *eventRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
+	return exportRecords(ctx, e.db.GetReadDB(), "convoy.events", projectID, createdAt, w, opts...)
 }
 
 func getCreatedDateFilter(startDate, endDate int64) (time.Time, time.Time) {
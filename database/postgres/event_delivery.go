@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,8 +17,10 @@ import (
 	"github.com/frain-dev/convoy/database/hooks"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/util"
 	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -33,14 +36,128 @@ var (
 	ErrEventDeliveriesNotDeleted       = errors.New("event deliveries could not be deleted")
 )
 
+const defaultLatencyBackfillBatchSize = 1000
+
+// purgeEventDeliveriesBatchSize is how many soft deleted deliveries are hard
+// deleted per statement, so a purge doesn't hold locks on the whole backlog
+const purgeEventDeliveriesBatchSize = 1000
+
+// defaultDescCursor is the id cursor a descending page starts from when none is given
+const defaultDescCursor = "FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF"
+
+// maxCursorCreatedAt bounds the first page of a (created_at, id) cursor
+var maxCursorCreatedAt = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
 const (
+	// deliveries created without a delivery mode take their subscription's.
+	// Their deduplication keys are claimed before they're inserted. The
+	// conflict target is the partitioned table's primary key, which the
+	// unpartitioned table has a unique index on.
 	createEventDelivery = `
-    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at,delivery_mode)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16);
+    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at,delivery_mode,deduplication_key,created_at)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,
+        COALESCE(CAST(NULLIF($16, '') AS convoy.delivery_mode), (SELECT delivery_mode FROM convoy.subscriptions WHERE id = $6), 'at_least_once'),
+        $17, COALESCE($18, CURRENT_TIMESTAMP))
+    ON CONFLICT (id, created_at, project_id) DO NOTHING;
     `
 	createEventDeliveries = `
-    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at,delivery_mode)
-    VALUES (:id, :project_id, :event_id, :endpoint_id, :device_id, :subscription_id, :headers, :status, :metadata, :cli_metadata, :description, :url_query_params, :idempotency_key, :event_type, :acknowledged_at, :delivery_mode);
+    INSERT INTO convoy.event_deliveries (id,project_id,event_id,endpoint_id,device_id,subscription_id,headers,status,metadata,cli_metadata,description,url_query_params,idempotency_key,event_type,acknowledged_at,delivery_mode,deduplication_key,created_at)
+    VALUES (:id, :project_id, :event_id, :endpoint_id, :device_id, :subscription_id, :headers, :status, :metadata, :cli_metadata, :description, :url_query_params, :idempotency_key, :event_type, :acknowledged_at,
+        COALESCE(CAST(NULLIF(:delivery_mode, '') AS convoy.delivery_mode), (SELECT delivery_mode FROM convoy.subscriptions WHERE id = :subscription_id), 'at_least_once'),
+        :deduplication_key, COALESCE(:created_at, CURRENT_TIMESTAMP));
+    `
+
+	// the side table isn't partitioned, so a key is taken at most once per
+	// project however far apart its deliveries are created. A delivery that
+	// holds its key already claims it again, so inserting it twice is a no-op.
+	claimEventDeliveryDeduplicationKey = `
+    INSERT INTO convoy.event_delivery_deduplication_keys AS k (project_id, deduplication_key, event_id, event_delivery_id, created_at)
+    VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_TIMESTAMP))
+    ON CONFLICT (project_id, deduplication_key) DO UPDATE SET event_delivery_id = EXCLUDED.event_delivery_id
+    WHERE k.event_delivery_id = EXCLUDED.event_delivery_id;
+    `
+	claimEventDeliveryDeduplicationKeys = `
+    INSERT INTO convoy.event_delivery_deduplication_keys (project_id, deduplication_key, event_id, event_delivery_id, created_at)
+    VALUES (:project_id, :deduplication_key, :event_id, :id, COALESCE(:created_at, CURRENT_TIMESTAMP))
+    ON CONFLICT (project_id, deduplication_key) DO NOTHING
+    RETURNING event_delivery_id;
+    `
+
+	// the first successful attempt is when the delivery completed
+	fetchEventDeliveriesMissingLatency = `
+    SELECT
+        ed.id,
+        COALESCE(ed.latency, '') AS latency,
+        EXTRACT(EPOCH FROM (
+            SELECT MIN(da.created_at) FROM convoy.delivery_attempts da
+            WHERE da.event_delivery_id = ed.id AND da.project_id = ed.project_id AND da.status = true
+        ) - COALESCE(ed.acknowledged_at, ed.created_at)) AS attempt_latency_seconds
+    FROM convoy.event_deliveries ed
+    WHERE ed.latency_seconds IS NULL AND ed.id > $1 AND ed.deleted_at IS NULL
+    ORDER BY ed.id
+    LIMIT $2;
+    `
+
+	backfillEventDeliveriesLatency = `
+    UPDATE convoy.event_deliveries ed SET latency_seconds = v.latency_seconds
+    FROM (SELECT UNNEST($1::TEXT[]) AS id, UNNEST($2::NUMERIC[]) AS latency_seconds) v
+    WHERE ed.id = v.id AND ed.latency_seconds IS NULL;
+    `
+
+	fetchEventDeliveriesWithLegacyAttempts = `
+    SELECT id, project_id, endpoint_id, attempts, created_at
+    FROM convoy.event_deliveries
+    WHERE attempts IS NOT NULL AND id > $1
+    ORDER BY id
+    LIMIT $2;
+    `
+
+	// legacy attempts moved before are skipped, so a batch can safely be moved again
+	insertLegacyDeliveryAttempts = `
+    INSERT INTO convoy.delivery_attempts (id, url, method, api_version, endpoint_id, event_delivery_id, project_id, ip_address, request_http_header, response_http_header, http_status, response_data, error, status, created_at, updated_at)
+    VALUES (:id, :url, :method, :api_version, :endpoint_id, :event_delivery_id, :project_id, :ip_address, :request_http_header, :response_http_header, :http_status, :response_data, :error, :status, :created_at, :updated_at)
+    ON CONFLICT DO NOTHING;
+    `
+
+	clearLegacyDeliveryAttempts = `
+    UPDATE convoy.event_deliveries SET attempts = NULL WHERE id = ANY($1);
+    `
+
+	// the batch is locked so its attempts and the deliveries themselves are
+	// deleted together
+	fetchSoftDeletedEventDeliveryIDs = `
+    SELECT id FROM convoy.event_deliveries
+    WHERE deleted_at IS NOT NULL AND deleted_at < $1
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED;
+    `
+
+	// delivery_attempts references event_deliveries without ON DELETE, so a
+	// delivery's attempts go first
+	purgeEventDeliveryAttempts = `
+    DELETE FROM convoy.delivery_attempts WHERE event_delivery_id = ANY($1);
+    `
+
+	purgeSoftDeletedEventDeliveries = `
+    DELETE FROM convoy.event_deliveries WHERE id = ANY($1);
+    `
+
+	releaseEventDeliveryDeduplicationKeys = `
+    DELETE FROM convoy.event_delivery_deduplication_keys WHERE project_id = $1 AND event_id = $2;
+    `
+
+	purgeEventDeliveryDeduplicationKeys = `
+    DELETE FROM convoy.event_delivery_deduplication_keys WHERE created_at < $1;
+    `
+
+	// deliveries are ordered by when they were created, ties are broken by id
+	hasPendingEventDeliveriesBefore = `
+    SELECT EXISTS (
+        SELECT 1 FROM convoy.event_deliveries
+        WHERE project_id = $1 AND endpoint_id = $2
+        AND status IN ('Scheduled', 'Retry', 'Processing')
+        AND (created_at, id) < ($3, $4) AND deleted_at IS NULL
+    );
     `
 
 	baseFetchEventDelivery = `
@@ -49,7 +166,7 @@ const (
         ed.headers,ed.attempts,ed.status,ed.metadata,ed.cli_metadata,
         COALESCE(ed.url_query_params, '') AS url_query_params,
         COALESCE(ed.idempotency_key, '') AS idempotency_key,
-        ed.description,ed.created_at,ed.updated_at,ed.acknowledged_at,
+        ed.description,ed.created_at,ed.updated_at,ed.acknowledged_at,ed.device_acknowledged_at,
         COALESCE(ed.event_type,'') AS "event_type",
         COALESCE(ed.device_id,'') AS "device_id",
         COALESCE(ed.endpoint_id,'') AS "endpoint_id",
@@ -104,8 +221,34 @@ const (
 	SELECT * FROM event_deliveries ORDER BY id %s
 	`
 
+	baseEventDeliveryPagedForwardByCreatedAt = `
+	WITH event_deliveries AS (
+	    %s
+	    %s
+	    AND (ed.created_at, ed.id) <= (:cursor_created_at, :cursor)
+	    ORDER BY ed.created_at %[3]s, ed.id %[3]s
+	    LIMIT :limit
+	)
+
+	SELECT * FROM event_deliveries ORDER BY created_at %[4]s, id %[4]s
+	`
+
+	baseEventDeliveryPagedBackwardByCreatedAt = `
+	WITH event_deliveries AS (
+		%s
+		%s
+		AND (ed.created_at, ed.id) >= (:cursor_created_at, :cursor)
+		ORDER BY ed.created_at %[3]s, ed.id %[3]s
+		LIMIT :limit
+	)
+
+	SELECT * FROM event_deliveries ORDER BY created_at %[4]s, id %[4]s
+	`
+
 	fetchEventDeliveryByID = baseFetchEventDelivery + ` AND ed.id = $1 AND ed.project_id = $2`
 
+	fetchEventDeliveriesByIdempotencyKey = baseFetchEventDelivery + ` AND ed.project_id = $1 AND ed.idempotency_key = $2 ORDER BY ed.created_at, ed.id`
+
 	fetchEventDeliverySlim = `
     SELECT
         id,project_id,event_id,subscription_id,
@@ -129,6 +272,14 @@ const (
 	AND ed.created_at <= :end_date
 	AND ed.deleted_at IS NULL`
 
+	// eventDeliveryResponseStatusFilter matches the status code of the delivery's
+	// latest attempt, http_status holds the full status line e.g. "429 Too Many Requests"
+	eventDeliveryResponseStatusFilter = ` AND (
+		SELECT split_part(da.http_status, ' ', 1) FROM convoy.delivery_attempts da
+		WHERE da.event_delivery_id = ed.id AND da.deleted_at IS NULL
+		ORDER BY da.created_at DESC LIMIT 1
+	) IN (:response_status_codes)`
+
 	countPrevEventDeliveries = `
 	select exists(
 		SELECT 1
@@ -141,12 +292,25 @@ const (
 	);
 	`
 
+	countPrevEventDeliveriesByCreatedAt = `
+	select exists(
+		SELECT 1
+		FROM convoy.event_deliveries ed
+		LEFT JOIN convoy.events ev ON ed.event_id = ev.id
+		WHERE ed.deleted_at IS NULL
+		%s
+		AND (ed.created_at, ed.id) > (:cursor_created_at, :cursor)
+		ORDER BY ed.created_at %s
+	);
+	`
+
 	loadEventDeliveriesIntervals = `
     SELECT
-        DATE_TRUNC('%s', created_at) AS "data.group_only",
-        TO_CHAR(DATE_TRUNC('%s', created_at), '%s') AS "data.total_time",
-        EXTRACT('%s' FROM created_at) AS "data.index",
+        DATE_TRUNC('%[1]s', created_at + %[2]s) - %[2]s AS "data.group_only",
+        TO_CHAR(DATE_TRUNC('%[1]s', created_at + %[2]s) - %[2]s, '%[3]s') AS "data.total_time",
+        EXTRACT('%[4]s' FROM created_at + %[2]s) AS "data.index",
         COUNT(*) AS count
+        %[5]s
         FROM
             convoy.event_deliveries
         WHERE
@@ -154,11 +318,40 @@ const (
         deleted_at IS NULL AND
         created_at >= $2 AND
         created_at <= $3
-        %s
+        %[6]s
     GROUP BY
         "data.group_only", "data.index";
     `
 
+	eventDeliveriesIntervalLatencyPercentiles = `,
+        COALESCE(PERCENTILE_CONT(0.50) WITHIN GROUP (ORDER BY latency_seconds), 0) AS p50,
+        COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY latency_seconds), 0) AS p95,
+        COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY latency_seconds), 0) AS p99
+    `
+
+	// one row per bucket with a count per mode, so every mode gets exactly
+	// the same buckets
+	loadEventDeliveriesIntervalsByDeliveryMode = `
+    SELECT
+        DATE_TRUNC('%[1]s', created_at + %[2]s) - %[2]s AS "data.group_only",
+        TO_CHAR(DATE_TRUNC('%[1]s', created_at + %[2]s) - %[2]s, '%[3]s') AS "data.total_time",
+        EXTRACT('%[4]s' FROM created_at + %[2]s) AS "data.index",
+        COUNT(*) FILTER (WHERE COALESCE(delivery_mode, 'at_least_once') = 'at_least_once') AS at_least_once,
+        COUNT(*) FILTER (WHERE delivery_mode = 'at_most_once') AS at_most_once,
+        COUNT(*) FILTER (WHERE delivery_mode = 'ordered') AS ordered
+        FROM
+            convoy.event_deliveries
+        WHERE
+        project_id = $1 AND
+        deleted_at IS NULL AND
+        created_at >= $2 AND
+        created_at <= $3
+    GROUP BY
+        "data.group_only", "data.index"
+    ORDER BY
+        "data.group_only";
+    `
+
 	fetchEventDeliveries = `
     SELECT
         id,project_id,event_id,subscription_id,
@@ -172,6 +365,26 @@ const (
         COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode AS "delivery_mode",
         acknowledged_at
     FROM convoy.event_deliveries ed
+    `
+
+	fetchLatestEventDeliveryByEndpoint = `
+    SELECT
+        id,project_id,event_id,subscription_id,
+        headers,attempts,status,metadata,cli_metadata,
+        COALESCE(idempotency_key, '') AS idempotency_key,
+        COALESCE(url_query_params, '') AS url_query_params,
+        description,created_at,updated_at,
+        COALESCE(event_type,'') AS "event_type",
+        COALESCE(device_id,'') AS "device_id",
+        COALESCE(endpoint_id,'') AS "endpoint_id",
+        COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode AS "delivery_mode",
+        acknowledged_at
+    FROM (
+        SELECT *, ROW_NUMBER() OVER (PARTITION BY endpoint_id ORDER BY created_at DESC, id DESC) AS row_number
+        FROM convoy.event_deliveries
+        WHERE project_id = $1 AND endpoint_id = ANY($2) AND deleted_at IS NULL
+    ) ed
+    WHERE row_number = 1;
     `
 
 	fetchDiscardedEventDeliveries = `
@@ -199,18 +412,270 @@ const (
       AND deleted_at IS NULL
     FOR UPDATE SKIP LOCKED
     LIMIT 1000;
+    `
+
+	// rows locked by a concurrent claim are skipped rather than waited on,
+	// so workers claiming at the same time never get the same deliveries
+	claimScheduledDeliveries = `
+    WITH claimed AS (
+        SELECT id FROM convoy.event_deliveries
+        WHERE status = $1 AND project_id = $2 AND deleted_at IS NULL
+        ORDER BY created_at
+        LIMIT $4
+        FOR UPDATE SKIP LOCKED
+    )
+    UPDATE convoy.event_deliveries ed SET status = $3, updated_at = NOW()
+    FROM claimed WHERE ed.id = claimed.id
+    RETURNING
+        ed.id,ed.project_id,ed.event_id,ed.subscription_id,
+        ed.headers,ed.attempts,ed.status,ed.metadata,ed.cli_metadata,
+        COALESCE(ed.url_query_params, '') AS url_query_params,
+        COALESCE(ed.idempotency_key, '') AS idempotency_key,ed.created_at,ed.updated_at,
+        COALESCE(ed.event_type,'') AS "event_type",
+        COALESCE(ed.device_id,'') AS "device_id",
+        COALESCE(ed.endpoint_id,'') AS "endpoint_id",
+        COALESCE(ed.delivery_mode, 'at_least_once')::convoy.delivery_mode AS "delivery_mode",
+        ed.acknowledged_at;
+    `
+
+	// updated_at is when a delivery last changed status, so it tells how
+	// long the delivery has been sitting in its current one
+	fetchEventDeliveriesStuckInStatus = `
+    SELECT id, project_id, endpoint_id, metadata, created_at, updated_at
+    FROM convoy.event_deliveries
+    WHERE status = $1
+      AND (project_id = $2 OR $2 = '')
+      AND updated_at <= now() - make_interval(secs => $3)
+      AND deleted_at IS NULL
+    ORDER BY updated_at
+    LIMIT $4;
+    `
+
+	// an endpoint or subscription that was soft deleted is as gone as one
+	// that doesn't exist, so both are joined on deleted_at IS NULL. Device
+	// deliveries have no endpoint and aren't orphaned by not having one.
+	fetchOrphanedEventDeliveries = `
+    SELECT ed.id, ed.project_id, ed.event_id,
+    COALESCE(ed.endpoint_id, '') AS endpoint_id,
+    COALESCE(ed.device_id, '') AS device_id,
+    ed.subscription_id, ed.status, ed.created_at, ed.updated_at
+    FROM convoy.event_deliveries ed
+    LEFT JOIN convoy.endpoints e ON e.id = ed.endpoint_id AND e.deleted_at IS NULL
+    LEFT JOIN convoy.subscriptions s ON s.id = ed.subscription_id AND s.deleted_at IS NULL
+    WHERE (ed.project_id = $1 OR $1 = '')
+      AND ed.id > $2
+      AND ed.deleted_at IS NULL
+      AND ((ed.endpoint_id IS NOT NULL AND e.id IS NULL) OR s.id IS NULL)
+    ORDER BY ed.id
+    LIMIT $3;
+    `
+
+	// a delivery's updated_at is when it was delivered once it's successful,
+	// acknowledging it doesn't change its status. Only devices acknowledge
+	// what they receive.
+	fetchUnacknowledgedEventDeliveries = `
+    SELECT id, project_id, event_id, endpoint_id, device_id, subscription_id,
+    status, metadata, created_at, updated_at
+    FROM convoy.event_deliveries
+    WHERE status = 'Success'
+      AND device_id IS NOT NULL
+      AND device_acknowledged_at IS NULL
+      AND (project_id = $1 OR $1 = '')
+      AND updated_at <= now() - make_interval(secs => $2)
+      AND deleted_at IS NULL
+    ORDER BY updated_at
+    LIMIT $3;
+    `
+
+	// deliveries the device already acknowledged are left alone, so the rows
+	// affected are the deliveries this call acknowledged
+	acknowledgeEventDeliveries = `
+    UPDATE convoy.event_deliveries SET device_acknowledged_at = ?
+    WHERE project_id = ? AND id IN (?)
+      AND device_acknowledged_at IS NULL
+      AND deleted_at IS NULL;
+    `
+
+	// the equality conditions are exactly the columns of
+	// idx_event_deliveries_project_id_endpoint_id_status, so the planner
+	// resolves them from the index and only sorts the matching rows
+	fetchEventDeliveriesByEndpointAndStatus = `
+    SELECT id, project_id, event_id, subscription_id, headers, status, metadata,
+    COALESCE(url_query_params, '') AS url_query_params,
+    COALESCE(idempotency_key, '') AS idempotency_key,
+    COALESCE(event_type, '') AS event_type,
+    COALESCE(device_id, '') AS device_id,
+    COALESCE(endpoint_id, '') AS endpoint_id,
+    COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode AS delivery_mode,
+    COALESCE(latency_seconds, 0) AS latency_seconds,
+    description, created_at, updated_at, acknowledged_at
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND endpoint_id = $2 AND status = $3
+      AND created_at >= $4 AND created_at <= $5
+      AND id < $6
+      AND deleted_at IS NULL
+    ORDER BY id DESC
+    LIMIT $7;
+    `
+
+	// pages by id alone so a page deep into the table costs the same as the
+	// first, ids are ulids so deliveries created while iterating come last
+	fetchEventDeliveriesAfterID = `
+    SELECT id, project_id, event_id, subscription_id, headers, status, metadata,
+    COALESCE(url_query_params, '') AS url_query_params,
+    COALESCE(idempotency_key, '') AS idempotency_key,
+    COALESCE(event_type, '') AS event_type,
+    COALESCE(device_id, '') AS device_id,
+    COALESCE(endpoint_id, '') AS endpoint_id,
+    COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode AS delivery_mode,
+    COALESCE(latency_seconds, 0) AS latency_seconds,
+    description, created_at, updated_at, acknowledged_at
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3
+      AND id > $4
+      AND deleted_at IS NULL
+      AND ($5::text = '' OR endpoint_id = $5)
+      AND (COALESCE(cardinality($6::text[]), 0) = 0 OR status = ANY($6::text[]))
+      AND (COALESCE(cardinality($7::text[]), 0) = 0 OR event_type = ANY($7::text[]))
+      AND (COALESCE(cardinality($8::text[]), 0) = 0 OR event_type IS NULL OR NOT (event_type = ANY($8::text[])))
+    ORDER BY id
+    LIMIT $9;
+    `
+
+	fetchDeadLetteredEventDeliveries = fetchEventDeliveries + `
+    WHERE ed.status = $1 AND ed.project_id = $2 AND ed.updated_at <= $3 AND ed.deleted_at IS NULL
+    ORDER BY ed.id
+    LIMIT 1000;
     `
 
 	countEventDeliveriesByStatus = `
     SELECT COUNT(id) FROM convoy.event_deliveries WHERE status = $1 AND (project_id = $2 OR $2 = '') AND created_at >= $3 AND created_at <= $4 AND deleted_at IS NULL;
+    `
+
+	// event_type is matched on its own so the planner can use the event_deliveries_event_type index
+	countEventDeliveriesByEventType = `
+    SELECT COUNT(id) FROM convoy.event_deliveries WHERE event_type = $1 AND status = $2 AND (project_id = $3 OR $3 = '') AND created_at >= $4 AND created_at <= $5 AND deleted_at IS NULL;
+    `
+
+	// a delivery counts towards the SLA once it has succeeded, given up,
+	// or had its whole time-to-success budget elapse. Successes without a
+	// recorded latency can't be measured against the budget, so they're
+	// left out rather than counted as meeting it.
+	fetchDeliverySLACompliance = `
+    SELECT
+        COUNT(id) FILTER (WHERE status = 'Success' AND latency_seconds <= $4) AS within_budget,
+        COUNT(id) AS total
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3 AND deleted_at IS NULL
+    AND NOT (status = 'Success' AND latency_seconds IS NULL)
+    AND (status IN ('Success', 'Failure', 'Discarded') OR created_at <= NOW() - make_interval(secs => $4));
+    `
+
+	fetchAttemptCountDistribution = `
+    SELECT (metadata->>'num_trials')::BIGINT AS attempts, COUNT(id) AS count
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND status = 'Success' AND created_at >= $2 AND created_at <= $3 AND deleted_at IS NULL
+    GROUP BY attempts
+    ORDER BY attempts;
+    `
+
+	// hours are taken in UTC so the buckets don't depend on the session's time zone
+	fetchDeliveryCountsByHour = `
+    SELECT EXTRACT(HOUR FROM created_at AT TIME ZONE 'UTC')::INT AS hour, COUNT(id) AS count
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3 AND deleted_at IS NULL
+    GROUP BY hour;
+    `
+
+	// width_bucket puts latencies below the first bound in bucket 0 and
+	// those at or above the last in bucket cardinality($5)
+	fetchEventDeliveryLatencyHistogram = `
+    SELECT width_bucket(latency_seconds::FLOAT8, $5::FLOAT8[]) AS bucket, COUNT(id) AS count
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND endpoint_id = $2 AND created_at >= $3 AND created_at <= $4
+    AND latency_seconds IS NOT NULL AND deleted_at IS NULL
+    GROUP BY bucket;
+    `
+
+	// only finished deliveries are counted, those still being retried
+	// haven't succeeded or failed yet
+	fetchEndpointDeliveryStats = `
+    SELECT
+        endpoint_id,
+        COUNT(id) AS total,
+        COUNT(id) FILTER (WHERE status = 'Success') AS successful,
+        COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY latency_seconds) FILTER (WHERE status = 'Success'), 0) AS p95_latency_seconds
+    FROM convoy.event_deliveries
+    WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3
+    AND status IN ('Success', 'Failure', 'Discarded') AND deleted_at IS NULL
+    GROUP BY endpoint_id
+    ORDER BY endpoint_id;
+    `
+
+	// the consecutive failures are the failed attempts after the last
+	// successful one, or all of them when none succeeded within the window
+	fetchEndpointHealth = `
+    SELECT
+        COUNT(ed.id) AS total,
+        COUNT(ed.id) FILTER (WHERE ed.status = 'Success') AS successful,
+        COALESCE(AVG(ed.latency_seconds) FILTER (WHERE ed.status = 'Success'), 0) AS avg_latency_seconds,
+        (
+            SELECT COUNT(da.id) FROM convoy.delivery_attempts da
+            WHERE da.project_id = $1 AND da.endpoint_id = $2 AND da.created_at >= $3
+            AND da.status = false AND da.deleted_at IS NULL
+            AND da.created_at > COALESCE((
+                SELECT MAX(s.created_at) FROM convoy.delivery_attempts s
+                WHERE s.project_id = $1 AND s.endpoint_id = $2 AND s.created_at >= $3
+                AND s.status = true AND s.deleted_at IS NULL
+            ), '-infinity'::TIMESTAMPTZ)
+        ) AS consecutive_failures
+    FROM convoy.event_deliveries ed
+    WHERE ed.project_id = $1 AND ed.endpoint_id = $2 AND ed.created_at >= $3
+    AND ed.status IN ('Success', 'Failure', 'Discarded') AND ed.deleted_at IS NULL;
+    `
+
+	// the snapshot an event replaced is kept with it, so when the same
+	// event is written again it gets the same previous payload back. An
+	// event older than the snapshot's doesn't replace it and returns no row
+	swapEntitySnapshot = `
+    INSERT INTO convoy.delivery_entity_snapshots AS s (subscription_id, entity_key, project_id, event_id, event_created_at, payload)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (subscription_id, entity_key) DO UPDATE SET
+        previous_payload = CASE WHEN s.event_id = EXCLUDED.event_id THEN s.previous_payload ELSE s.payload END,
+        event_id = EXCLUDED.event_id,
+        event_created_at = EXCLUDED.event_created_at,
+        payload = EXCLUDED.payload,
+        updated_at = NOW()
+    WHERE s.event_id = EXCLUDED.event_id
+       OR s.event_created_at IS NULL
+       OR s.event_created_at <= EXCLUDED.event_created_at
+    RETURNING previous_payload;
     `
 
 	countEventDeliveries = `
-    SELECT COUNT(id) FROM convoy.event_deliveries WHERE (project_id = ? OR ? = '') AND (event_id = ? OR ? = '') AND created_at >= ? AND created_at <= ? AND deleted_at IS NULL
+    SELECT COUNT(ed.id) FROM convoy.event_deliveries ed%s WHERE (ed.project_id = ? OR ? = '') AND (ed.event_id = ? OR ? = '') AND ed.created_at >= ? AND ed.created_at <= ? AND ed.deleted_at IS NULL
     `
 
+	countEventDeliveriesSourceJoin = ` JOIN convoy.events ev ON ev.id = ed.event_id`
+
 	updateEventDeliveriesStatus = `
     UPDATE convoy.event_deliveries SET status = ?, description = ?, updated_at = NOW() WHERE (project_id = ? OR ? = '')AND id IN (?) AND deleted_at IS NULL;
+    `
+
+	// requeueRecentEventDeliveries schedules the deliveries created since the
+	// cutoff and returns the ids of the ones that are older, those are left as is.
+	// A data-modifying CTE runs to completion even though the outer query
+	// doesn't read it.
+	requeueRecentEventDeliveries = `
+    WITH candidates AS (
+        SELECT id, created_at >= ? AS recent FROM convoy.event_deliveries
+        WHERE (project_id = ? OR ? = '') AND id IN (?) AND deleted_at IS NULL
+    ), requeued AS (
+        UPDATE convoy.event_deliveries SET status = ?, description = '', updated_at = NOW()
+        WHERE id IN (SELECT id FROM candidates WHERE recent) AND deleted_at IS NULL
+        RETURNING id
+    )
+    SELECT id FROM candidates WHERE NOT recent;
     `
 
 	updateEventDeliveryMetadata = `
@@ -218,11 +683,39 @@ const (
     `
 
 	softDeleteProjectEventDeliveries = `
-    UPDATE convoy.event_deliveries SET deleted_at = NOW() WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3 AND deleted_at IS NULL;
+    UPDATE convoy.event_deliveries SET deleted_at = NOW() WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3 AND deleted_at IS NULL
+    AND (COALESCE(cardinality($4::text[]), 0) = 0 OR event_type = ANY($4::text[]))
+    AND (COALESCE(cardinality($5::text[]), 0) = 0 OR event_type IS NULL OR NOT (event_type = ANY($5::text[])));
+    `
+
+	// deliveries being dispatched are left alone so they aren't sent twice
+	requeueEventDeliveriesByFilter = `
+    UPDATE convoy.event_deliveries SET status = $8, updated_at = NOW()
+    WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3 AND deleted_at IS NULL
+    AND status <> $9
+    AND ($4::text = '' OR endpoint_id = $4)
+    AND (COALESCE(cardinality($5::text[]), 0) = 0 OR status = ANY($5::text[]))
+    AND (COALESCE(cardinality($6::text[]), 0) = 0 OR event_type = ANY($6::text[]))
+    AND (COALESCE(cardinality($7::text[]), 0) = 0 OR event_type IS NULL OR NOT (event_type = ANY($7::text[])))
+    RETURNING id;
+    `
+
+	// only successful deliveries lose their payloads, failed and discarded
+	// ones can still be retried or replayed. Rows that were already stripped
+	// are skipped so they aren't rewritten on every run
+	stripProjectEventDeliveryPayloads = `
+    UPDATE convoy.event_deliveries SET metadata = metadata || '{"data": null, "raw": "", "full_document": null}'::jsonb
+    WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3 AND deleted_at IS NULL
+    AND status = $6
+    AND (COALESCE(metadata->>'raw', '') <> '' OR COALESCE(metadata->'data', 'null'::jsonb) <> 'null'::jsonb)
+    AND (COALESCE(cardinality($4::text[]), 0) = 0 OR event_type = ANY($4::text[]))
+    AND (COALESCE(cardinality($5::text[]), 0) = 0 OR event_type IS NULL OR NOT (event_type = ANY($5::text[])));
     `
 
 	hardDeleteProjectEventDeliveries = `
-    DELETE FROM convoy.event_deliveries WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3;
+    DELETE FROM convoy.event_deliveries WHERE project_id = $1 AND created_at >= $2 AND created_at <= $3
+    AND (COALESCE(cardinality($4::text[]), 0) = 0 OR event_type = ANY($4::text[]))
+    AND (COALESCE(cardinality($5::text[]), 0) = 0 OR event_type IS NULL OR NOT (event_type = ANY($5::text[])));
     `
 )
 
@@ -242,10 +735,6 @@ func (e *eventDeliveryRepo) CreateEventDelivery(ctx context.Context, delivery *d
 		deviceID = &delivery.DeviceID
 	}
 
-	if delivery.DeliveryMode == "" {
-		delivery.DeliveryMode = datastore.AtLeastOnceDeliveryMode
-	}
-
 	tx, isWrapped, err := GetTx(ctx, e.db.GetDB())
 	if err != nil {
 		return err
@@ -255,26 +744,34 @@ func (e *eventDeliveryRepo) CreateEventDelivery(ctx context.Context, delivery *d
 		defer rollbackTx(tx)
 	}
 
-	result, err := tx.ExecContext(
+	if key := deduplicationKey(delivery); key != nil {
+		result, err := tx.ExecContext(ctx, claimEventDeliveryDeduplicationKey, delivery.ProjectID, *key, delivery.EventID, delivery.UID, deliveryCreatedAt(delivery))
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected < 1 {
+			return datastore.ErrDuplicateEventDelivery
+		}
+	}
+
+	// nothing is inserted when a retried fan-out created this delivery already
+	_, err = tx.ExecContext(
 		ctx, createEventDelivery, delivery.UID, delivery.ProjectID,
 		delivery.EventID, endpointID, deviceID,
 		delivery.SubscriptionID, delivery.Headers, delivery.Status,
 		delivery.Metadata, delivery.CLIMetadata, delivery.Description, delivery.URLQueryParams, delivery.IdempotencyKey, delivery.EventType,
-		delivery.AcknowledgedAt, delivery.DeliveryMode,
+		delivery.AcknowledgedAt, delivery.DeliveryMode, deduplicationKey(delivery), deliveryCreatedAt(delivery),
 	)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected < 1 {
-		return ErrEventDeliveryNotCreated
-	}
-
 	if isWrapped {
 		return nil
 	}
@@ -282,8 +779,9 @@ func (e *eventDeliveryRepo) CreateEventDelivery(ctx context.Context, delivery *d
 	return tx.Commit()
 }
 
-// CreateEventDeliveries creates event deliveries in bulk
-func (e *eventDeliveryRepo) CreateEventDeliveries(ctx context.Context, deliveries []*datastore.EventDelivery) error {
+// CreateEventDeliveries creates event deliveries in bulk and returns the ones
+// created. Deliveries whose deduplication key is already taken are skipped.
+func (e *eventDeliveryRepo) CreateEventDeliveries(ctx context.Context, deliveries []*datastore.EventDelivery) ([]*datastore.EventDelivery, error) {
 	values := make([]map[string]interface{}, 0, len(deliveries))
 
 	for _, delivery := range deliveries {
@@ -298,111 +796,697 @@ func (e *eventDeliveryRepo) CreateEventDeliveries(ctx context.Context, deliverie
 			deviceID = &delivery.DeviceID
 		}
 
-		if delivery.DeliveryMode == "" {
-			delivery.DeliveryMode = datastore.AtLeastOnceDeliveryMode
-		}
-
 		values = append(values, map[string]interface{}{
-			"id":               delivery.UID,
-			"project_id":       delivery.ProjectID,
-			"event_id":         delivery.EventID,
-			"endpoint_id":      endpointID,
-			"device_id":        deviceID,
-			"subscription_id":  delivery.SubscriptionID,
-			"headers":          delivery.Headers,
-			"status":           delivery.Status,
-			"metadata":         delivery.Metadata,
-			"cli_metadata":     delivery.CLIMetadata,
-			"description":      delivery.Description,
-			"url_query_params": delivery.URLQueryParams,
-			"idempotency_key":  delivery.IdempotencyKey,
-			"event_type":       delivery.EventType,
-			"acknowledged_at":  delivery.AcknowledgedAt,
-			"delivery_mode":    delivery.DeliveryMode,
+			"id":                delivery.UID,
+			"project_id":        delivery.ProjectID,
+			"event_id":          delivery.EventID,
+			"endpoint_id":       endpointID,
+			"device_id":         deviceID,
+			"subscription_id":   delivery.SubscriptionID,
+			"headers":           delivery.Headers,
+			"status":            delivery.Status,
+			"metadata":          delivery.Metadata,
+			"cli_metadata":      delivery.CLIMetadata,
+			"description":       delivery.Description,
+			"url_query_params":  delivery.URLQueryParams,
+			"idempotency_key":   delivery.IdempotencyKey,
+			"event_type":        delivery.EventType,
+			"acknowledged_at":   delivery.AcknowledgedAt,
+			"delivery_mode":     delivery.DeliveryMode,
+			"deduplication_key": deduplicationKey(delivery),
+			"created_at":        deliveryCreatedAt(delivery),
 		})
 	}
 
+	// a transaction passed down through ctx already holds its connection
+	limiter := fanOutTxLimiter.Load()
+	if _, ok := ctx.Value(TransactionCtx).(*sqlx.Tx); ok {
+		limiter = nil
+	}
+
+	err := limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer limiter.release()
+
 	tx, isWrapped, err := GetTx(ctx, e.db.GetDB())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !isWrapped {
 		defer rollbackTx(tx)
 	}
 
+	claimed, err := e.claimDeduplicationKeys(ctx, tx, deliveries, values)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*datastore.EventDelivery, 0, len(deliveries))
+	inserts := make([]interface{}, 0, len(values))
+	for i, delivery := range deliveries {
+		if deduplicationKey(delivery) != nil {
+			if _, ok := claimed[delivery.UID]; !ok {
+				continue
+			}
+		}
+
+		created = append(created, delivery)
+		inserts = append(inserts, values[i])
+	}
+
 	var j int
-	for i := 0; i < len(values); i += PartitionSize {
+	for i := 0; i < len(inserts); i += PartitionSize {
 		j += PartitionSize
-		if j > len(values) {
-			j = len(values)
+		if j > len(inserts) {
+			j = len(inserts)
+		}
+
+		_, err = sqlx.NamedExecContext(ctx, tx, createEventDeliveries, inserts[i:j])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if isWrapped {
+		return created, nil
+	}
+
+	return created, tx.Commit()
+}
+
+// claimDeduplicationKeys claims the deduplication keys of deliveries and
+// returns the ids of the ones whose key it claimed. A key shared by several
+// deliveries goes to the first of them.
+func (e *eventDeliveryRepo) claimDeduplicationKeys(ctx context.Context, tx *sqlx.Tx, deliveries []*datastore.EventDelivery, values []map[string]interface{}) (map[string]struct{}, error) {
+	seen := make(map[string]struct{})
+	var vs []interface{}
+	for i, delivery := range deliveries {
+		key := deduplicationKey(delivery)
+		if key == nil {
+			continue
+		}
+
+		projectKey := delivery.ProjectID + "/" + *key
+		if _, ok := seen[projectKey]; ok {
+			continue
 		}
 
-		var vs []interface{}
-		for _, v := range values[i:j] {
-			vs = append(vs, v)
+		seen[projectKey] = struct{}{}
+		vs = append(vs, values[i])
+	}
+
+	claimed := make(map[string]struct{}, len(vs))
+
+	var j int
+	for i := 0; i < len(vs); i += PartitionSize {
+		j += PartitionSize
+		if j > len(vs) {
+			j = len(vs)
 		}
 
-		result, err := tx.NamedExecContext(ctx, createEventDeliveries, vs)
+		ids, err := e.insertDeduplicationKeys(ctx, tx, vs[i:j])
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		for _, id := range ids {
+			claimed[id] = struct{}{}
+		}
+	}
+
+	return claimed, nil
+}
+
+func (e *eventDeliveryRepo) insertDeduplicationKeys(ctx context.Context, tx *sqlx.Tx, vs []interface{}) ([]string, error) {
+	rows, err := sqlx.NamedQueryContext(ctx, tx, claimEventDeliveryDeduplicationKeys, vs)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	ids := make([]string, 0, len(vs))
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
 		}
 
-		rowsAffected, err := result.RowsAffected()
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ReleaseEventDeliveryDeduplicationKeys frees the (endpoint, event) pairs
+// held by an event's deliveries, so replaying the event can deliver it again.
+func (e *eventDeliveryRepo) ReleaseEventDeliveryDeduplicationKeys(ctx context.Context, projectID string, eventID string) error {
+	_, err := e.db.GetDB().ExecContext(ctx, releaseEventDeliveryDeduplicationKeys, projectID, eventID)
+	return err
+}
+
+// PurgeEventDeliveryDeduplicationKeys deletes the deduplication keys claimed
+// before the given time and returns how many it deleted. The events they
+// guard against fanning out twice are gone by then.
+func (e *eventDeliveryRepo) PurgeEventDeliveryDeduplicationKeys(ctx context.Context, before time.Time) (int64, error) {
+	result, err := e.db.GetDB().ExecContext(ctx, purgeEventDeliveryDeduplicationKeys, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// HasPendingEventDeliveriesBefore reports whether a delivery to the same
+// endpoint created before delivery is still scheduled, retrying or in flight.
+func (e *eventDeliveryRepo) HasPendingEventDeliveriesBefore(ctx context.Context, delivery *datastore.EventDelivery) (bool, error) {
+	var pending bool
+	err := e.db.GetDB().QueryRowxContext(ctx, hasPendingEventDeliveriesBefore, delivery.ProjectID, delivery.EndpointID, delivery.CreatedAt, delivery.UID).Scan(&pending)
+	if err != nil {
+		return false, err
+	}
+
+	return pending, nil
+}
+
+// deduplicationKey returns the delivery's deduplication key, or nil
+// when it doesn't have one so it is stored as NULL.
+func deduplicationKey(delivery *datastore.EventDelivery) *string {
+	if util.IsStringEmpty(delivery.DeduplicationKey) {
+		return nil
+	}
+
+	return &delivery.DeduplicationKey
+}
+
+// deliveryCreatedAt returns when the delivery was created, or nil when it
+// isn't set so the database's clock is used.
+func deliveryCreatedAt(delivery *datastore.EventDelivery) *time.Time {
+	if delivery.CreatedAt.IsZero() {
+		return nil
+	}
+
+	return &delivery.CreatedAt
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveryByID(ctx context.Context, projectID string, id string) (*datastore.EventDelivery, error) {
+	eventDelivery := &datastore.EventDelivery{}
+	err := readDB(ctx, e.db, datastore.PreferPrimary).QueryRowxContext(ctx, fetchEventDeliveryByID, id, projectID).StructScan(eventDelivery)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrEventDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return eventDelivery, nil
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveryByIDSlim(ctx context.Context, projectID string, id string) (*datastore.EventDelivery, error) {
+	eventDelivery := &datastore.EventDelivery{}
+	err := readDB(ctx, e.db, datastore.PreferPrimary).QueryRowxContext(ctx, fetchEventDeliverySlim, projectID, id).StructScan(eventDelivery)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrEventDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return eventDelivery, nil
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveriesByIDs(ctx context.Context, projectID string, ids []string) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+	query := fetchEventDeliveries + " WHERE id IN (?) AND project_id = ? AND deleted_at IS NULL"
+
+	query, args, err := sqlx.In(query, ids, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = e.db.GetDB().Rebind(query)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		eventDeliveries = append(eventDeliveries, ed)
+	}
+
+	return eventDeliveries, nil
+}
+
+// FindEventDeliveriesByIdempotencyKey returns every delivery of the project sent
+// with idempotencyKey, oldest first, with their endpoint, event and source metadata.
+func (e *eventDeliveryRepo) FindEventDeliveriesByIdempotencyKey(ctx context.Context, projectID string, idempotencyKey string) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchEventDeliveriesByIdempotencyKey, projectID, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		eventDeliveries = append(eventDeliveries, ed)
+	}
+
+	return eventDeliveries, rows.Err()
+}
+
+// LatestDeliveryByEndpoint returns the newest delivery of each of the given
+// endpoints keyed by endpoint id. Endpoints without deliveries are omitted.
+func (e *eventDeliveryRepo) LatestDeliveryByEndpoint(ctx context.Context, projectID string, endpointIDs []string) (map[string]datastore.EventDelivery, error) {
+	deliveries := make(map[string]datastore.EventDelivery, len(endpointIDs))
+	if len(endpointIDs) == 0 {
+		return deliveries, nil
+	}
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchLatestEventDeliveryByEndpoint, projectID, pq.Array(endpointIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries[ed.EndpointID] = ed
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveriesByEventID(ctx context.Context, projectID string, eventID string) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+
+	q := fetchEventDeliveries + " WHERE event_id = $1 AND project_id = $2 AND deleted_at IS NULL"
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, q, eventID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		eventDeliveries = append(eventDeliveries, ed)
+	}
+
+	return eventDeliveries, nil
+}
+
+// FindEventDeliveriesByEventIDs returns the deliveries of all the events in
+// one query, ordered by event then creation time, so a page of events can be
+// hydrated without a query per event.
+func (e *eventDeliveryRepo) FindEventDeliveriesByEventIDs(ctx context.Context, projectID string, eventIDs []string) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+	if len(eventIDs) == 0 {
+		return eventDeliveries, nil
+	}
+
+	q := fetchEventDeliveries + " WHERE event_id IN (?) AND project_id = ? AND deleted_at IS NULL ORDER BY event_id, created_at"
+
+	query, args, err := sqlx.In(q, eventIDs, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := readDB(ctx, e.db, datastore.PreferReplica)
+	rows, err := db.QueryxContext(ctx, db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		eventDeliveries = append(eventDeliveries, ed)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return eventDeliveries, nil
+}
+
+func (e *eventDeliveryRepo) CountDeliveriesByStatus(ctx context.Context, projectID string, status datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
+	deliveriesCount := struct{ Count int64 }{}
+
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+	err := readDB(ctx, e.db, datastore.PreferReplica).QueryRowxContext(ctx, countEventDeliveriesByStatus, status, projectID, start, end).StructScan(&deliveriesCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return deliveriesCount.Count, nil
+}
+
+// CountDeliveriesByEventType counts the deliveries of an event type with the given
+// status created within params, an empty projectID counts across all projects
+func (e *eventDeliveryRepo) CountDeliveriesByEventType(ctx context.Context, projectID, eventType string, status datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
+	deliveriesCount := struct{ Count int64 }{}
+
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+	err := readDB(ctx, e.db, datastore.PreferReplica).QueryRowxContext(ctx, countEventDeliveriesByEventType, eventType, status, projectID, start, end).StructScan(&deliveriesCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return deliveriesCount.Count, nil
+}
+
+// GetDeliverySLACompliance returns the percentage of deliveries created within params
+// that succeeded within budget. It returns 0 when there are no deliveries to measure.
+func (e *eventDeliveryRepo) GetDeliverySLACompliance(ctx context.Context, projectID string, budget time.Duration, params datastore.SearchParams) (float64, error) {
+	counts := struct {
+		WithinBudget int64 `db:"within_budget"`
+		Total        int64 `db:"total"`
+	}{}
+
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+	err := readDB(ctx, e.db, datastore.PreferReplica).QueryRowxContext(ctx, fetchDeliverySLACompliance, projectID, start, end, budget.Seconds()).StructScan(&counts)
+	if err != nil {
+		return 0, err
+	}
+
+	if counts.Total == 0 {
+		return 0, nil
+	}
+
+	return float64(counts.WithinBudget) / float64(counts.Total) * 100, nil
+}
+
+// LoadAttemptCountDistribution returns how many deliveries created within params
+// succeeded on each attempt, ordered by attempt.
+func (e *eventDeliveryRepo) LoadAttemptCountDistribution(ctx context.Context, projectID string, params datastore.SearchParams) ([]datastore.AttemptCount, error) {
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchAttemptCountDistribution, projectID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	distribution := make([]datastore.AttemptCount, 0)
+	for rows.Next() {
+		var ac datastore.AttemptCount
+		err = rows.StructScan(&ac)
+		if err != nil {
+			return nil, err
+		}
+
+		distribution = append(distribution, ac)
+	}
+
+	return distribution, rows.Err()
+}
+
+// LoadEndpointDeliveryStats returns, for each endpoint of the project with
+// finished deliveries created within params, how many there were, how many
+// succeeded and the 95th percentile latency of those that succeeded.
+func (e *eventDeliveryRepo) LoadEndpointDeliveryStats(ctx context.Context, projectID string, params datastore.SearchParams) ([]datastore.EndpointDeliveryStats, error) {
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchEndpointDeliveryStats, projectID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	stats := make([]datastore.EndpointDeliveryStats, 0)
+	for rows.Next() {
+		var s datastore.EndpointDeliveryStats
+		err = rows.StructScan(&s)
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+func (e *eventDeliveryRepo) ComputeEndpointHealth(ctx context.Context, projectID, endpointID string, window time.Duration) (*datastore.EndpointHealth, error) {
+	health := &datastore.EndpointHealth{EndpointID: endpointID}
+
+	err := readDB(ctx, e.db, datastore.PreferReplica).QueryRowxContext(ctx, fetchEndpointHealth, projectID, endpointID, time.Now().Add(-window)).StructScan(health)
+	if err != nil {
+		return nil, err
+	}
+
+	if health.Total > 0 {
+		health.SuccessRate = float64(health.Successful) / float64(health.Total) * 100
+	}
+
+	return health, nil
+}
+
+// SwapEntitySnapshot saves payload as the latest document of the entity
+// with entityKey for the subscription, and returns the one event eventID
+// replaced, nil when it is the entity's first. It returns
+// datastore.ErrStaleEntitySnapshot when the snapshot is of an event created
+// after eventCreatedAt, which it is left as.
+func (e *eventDeliveryRepo) SwapEntitySnapshot(ctx context.Context, projectID, subscriptionID, entityKey, eventID string, eventCreatedAt time.Time, payload []byte) ([]byte, error) {
+	var previous []byte
+	err := e.db.GetDB().QueryRowxContext(ctx, swapEntitySnapshot, subscriptionID, entityKey, projectID, eventID, eventCreatedAt, payload).Scan(&previous)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrStaleEntitySnapshot
+		}
+		return nil, err
+	}
+
+	return previous, nil
+}
+
+// GetEventDeliveryLatencyHistogram counts the endpoint's deliveries created
+// within params by latency, into the buckets split by the ascending bounds
+// in buckets. There's a bucket for latencies below the first bound and one
+// for those at or above the last, so len(buckets)+1 are always returned,
+// empty ones included. Deliveries without a latency are left out.
+func (e *eventDeliveryRepo) GetEventDeliveryLatencyHistogram(ctx context.Context, projectID, endpointID string, params datastore.SearchParams, buckets []float64) ([]datastore.LatencyBucket, error) {
+	if len(buckets) == 0 {
+		return nil, errors.New("at least one bucket bound is required")
+	}
+
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return nil, fmt.Errorf("bucket bounds must be ascending, %v is not above %v", buckets[i], buckets[i-1])
+		}
+	}
+
+	histogram := make([]datastore.LatencyBucket, len(buckets)+1)
+	for i := range histogram {
+		if i > 0 {
+			histogram[i].Lower = null.FloatFrom(buckets[i-1])
+		}
+
+		if i < len(buckets) {
+			histogram[i].Upper = null.FloatFrom(buckets[i])
+		}
+	}
+
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchEventDeliveryLatencyHistogram, projectID, endpointID, start, end, pq.Array(buckets))
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var bucket int
+		var count uint64
+		err = rows.Scan(&bucket, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		histogram[bucket].Count = count
+	}
+
+	return histogram, rows.Err()
+}
+
+// LoadDeliveryCountsByHour counts the project's deliveries created within
+// params by their hour of the day in UTC, summed across the days in the
+// window. All 24 hours are returned in order, hours without deliveries as 0.
+func (e *eventDeliveryRepo) LoadDeliveryCountsByHour(ctx context.Context, projectID string, params datastore.SearchParams) ([]datastore.HourlyDeliveryCount, error) {
+	counts := make([]datastore.HourlyDeliveryCount, 24)
+	for hour := range counts {
+		counts[hour].Hour = hour
+	}
+
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchDeliveryCountsByHour, projectID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var hc datastore.HourlyDeliveryCount
+		err = rows.StructScan(&hc)
+		if err != nil {
+			return nil, err
+		}
+
+		counts[hc.Hour].Count = hc.Count
+	}
+
+	return counts, rows.Err()
+}
+
+// FindDeadLetteredEventDeliveries returns up to 1000 failed deliveries in the
+// project that have not been updated since failedBefore.
+func (e *eventDeliveryRepo) FindDeadLetteredEventDeliveries(ctx context.Context, projectID string, failedBefore time.Time) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchDeadLetteredEventDeliveries, datastore.FailureEventStatus, projectID, failedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		eventDeliveries = append(eventDeliveries, ed)
+	}
+
+	return eventDeliveries, nil
+}
+
+func (e *eventDeliveryRepo) FindStuckEventDeliveriesByStatus(ctx context.Context, status datastore.EventDeliveryStatus) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchStuckEventDeliveries, status)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
 		if err != nil {
-			return err
-		}
-
-		if len(vs) > 0 && rowsAffected < 1 {
-			return ErrEventDeliveryNotCreated
+			return nil, err
 		}
-	}
 
-	if isWrapped {
-		return nil
+		eventDeliveries = append(eventDeliveries, ed)
 	}
 
-	return tx.Commit()
+	return eventDeliveries, nil
 }
 
-func (e *eventDeliveryRepo) FindEventDeliveryByID(ctx context.Context, projectID string, id string) (*datastore.EventDelivery, error) {
-	eventDelivery := &datastore.EventDelivery{}
-	err := e.db.GetDB().QueryRowxContext(ctx, fetchEventDeliveryByID, id, projectID).StructScan(eventDelivery)
+// ClaimScheduledDeliveries moves at most limit of the project's scheduled
+// deliveries to processing, oldest first, and returns them for dispatch.
+// Deliveries another claim is holding are skipped, so concurrent claims never
+// return the same delivery.
+func (e *eventDeliveryRepo) ClaimScheduledDeliveries(ctx context.Context, projectID string, limit int) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+
+	rows, err := e.db.GetDB().QueryxContext(ctx, claimScheduledDeliveries,
+		datastore.ScheduledEventStatus, projectID, datastore.ProcessingEventStatus, limit)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, datastore.ErrEventDeliveryNotFound
-		}
 		return nil, err
 	}
+	defer closeWithError(rows)
 
-	return eventDelivery, nil
-}
-
-func (e *eventDeliveryRepo) FindEventDeliveryByIDSlim(ctx context.Context, projectID string, id string) (*datastore.EventDelivery, error) {
-	eventDelivery := &datastore.EventDelivery{}
-	err := e.db.GetDB().QueryRowxContext(ctx, fetchEventDeliverySlim, projectID, id).StructScan(eventDelivery)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, datastore.ErrEventDeliveryNotFound
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
 		}
-		return nil, err
+
+		eventDeliveries = append(eventDeliveries, ed)
 	}
 
-	return eventDelivery, nil
+	return eventDeliveries, rows.Err()
 }
 
-func (e *eventDeliveryRepo) FindEventDeliveriesByIDs(ctx context.Context, projectID string, ids []string) ([]datastore.EventDelivery, error) {
+// FindEventDeliveriesStuckInStatus returns at most limit deliveries that have
+// been in status for longer than olderThan, oldest first. An empty projectID
+// searches every project.
+func (e *eventDeliveryRepo) FindEventDeliveriesStuckInStatus(ctx context.Context, projectID string, status datastore.EventDeliveryStatus, olderThan time.Duration, limit int) ([]datastore.EventDelivery, error) {
 	eventDeliveries := make([]datastore.EventDelivery, 0)
-	query := fetchEventDeliveries + " WHERE id IN (?) AND project_id = ? AND deleted_at IS NULL"
 
-	query, args, err := sqlx.In(query, ids, projectID)
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchEventDeliveriesStuckInStatus, status, projectID, olderThan.Seconds(), limit)
 	if err != nil {
 		return nil, err
 	}
+	defer closeWithError(rows)
 
-	query = e.db.GetDB().Rebind(query)
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		eventDeliveries = append(eventDeliveries, ed)
+	}
+
+	return eventDeliveries, rows.Err()
+}
 
-	rows, err := e.db.GetReadDB().QueryxContext(ctx, query, args...)
+// FindOrphanedEventDeliveries returns at most limit deliveries after afterID,
+// in id order, whose endpoint or subscription no longer exists or was
+// deleted. An empty projectID searches every project.
+func (e *eventDeliveryRepo) FindOrphanedEventDeliveries(ctx context.Context, projectID, afterID string, limit int) ([]datastore.EventDelivery, error) {
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchOrphanedEventDeliveries, projectID, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -418,14 +1502,16 @@ func (e *eventDeliveryRepo) FindEventDeliveriesByIDs(ctx context.Context, projec
 		eventDeliveries = append(eventDeliveries, ed)
 	}
 
-	return eventDeliveries, nil
+	return eventDeliveries, rows.Err()
 }
 
-func (e *eventDeliveryRepo) FindEventDeliveriesByEventID(ctx context.Context, projectID string, eventID string) ([]datastore.EventDelivery, error) {
+// FindUnacknowledgedEventDeliveries returns at most limit deliveries that were
+// delivered to a device more than olderThan ago and the device hasn't
+// acknowledged yet, oldest first. An empty projectID searches every project.
+func (e *eventDeliveryRepo) FindUnacknowledgedEventDeliveries(ctx context.Context, projectID string, olderThan time.Duration, limit int) ([]datastore.EventDelivery, error) {
 	eventDeliveries := make([]datastore.EventDelivery, 0)
 
-	q := fetchEventDeliveries + " WHERE event_id = $1 AND project_id = $2 AND deleted_at IS NULL"
-	rows, err := e.db.GetReadDB().QueryxContext(ctx, q, eventID, projectID)
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchUnacknowledgedEventDeliveries, projectID, olderThan.Seconds(), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -441,28 +1527,56 @@ func (e *eventDeliveryRepo) FindEventDeliveriesByEventID(ctx context.Context, pr
 		eventDeliveries = append(eventDeliveries, ed)
 	}
 
-	return eventDeliveries, nil
+	return eventDeliveries, rows.Err()
 }
 
-func (e *eventDeliveryRepo) CountDeliveriesByStatus(ctx context.Context, projectID string, status datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
-	deliveriesCount := struct{ Count int64 }{}
+// AcknowledgeEventDeliveries marks the project's deliveries with ids as
+// acknowledged by their device at at in a single update, it returns how many
+// deliveries the device hadn't acknowledged before.
+func (e *eventDeliveryRepo) AcknowledgeEventDeliveries(ctx context.Context, projectID string, ids []string, at time.Time) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
 
-	start := time.Unix(params.CreatedAtStart, 0)
-	end := time.Unix(params.CreatedAtEnd, 0)
-	err := e.db.GetReadDB().QueryRowxContext(ctx, countEventDeliveriesByStatus, status, projectID, start, end).StructScan(&deliveriesCount)
+	query, args, err := sqlx.In(acknowledgeEventDeliveries, at, projectID, ids)
 	if err != nil {
 		return 0, err
 	}
 
-	return deliveriesCount.Count, nil
+	query = e.db.GetDB().Rebind(query)
+
+	result, err := e.db.GetDB().ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
 }
 
-func (e *eventDeliveryRepo) FindStuckEventDeliveriesByStatus(ctx context.Context, status datastore.EventDeliveryStatus) ([]datastore.EventDelivery, error) {
-	eventDeliveries := make([]datastore.EventDelivery, 0)
+// FindEventDeliveriesByEndpointAndStatus returns a page of at most limit of
+// the endpoint's deliveries in status created within params, newest first.
+// It pages by id alone, pass the returned cursor back to get the next page,
+// an empty cursor means there are no more. Dashboards filtering on a single
+// endpoint and status should prefer it to LoadEventDeliveriesPaged, which
+// builds a generic filter and counts the rows before the page.
+func (e *eventDeliveryRepo) FindEventDeliveriesByEndpointAndStatus(ctx context.Context, projectID, endpointID string, status datastore.EventDeliveryStatus, params datastore.SearchParams, cursor string, limit int) ([]datastore.EventDelivery, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive, got %d", limit)
+	}
+
+	if cursor == "" {
+		cursor = defaultDescCursor
+	}
 
-	rows, err := e.db.GetReadDB().QueryxContext(ctx, fetchStuckEventDeliveries, status)
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+
+	eventDeliveries := make([]datastore.EventDelivery, 0, limit+1)
+
+	// one more than the page is fetched to tell whether there's a next page
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchEventDeliveriesByEndpointAndStatus, projectID, endpointID, status, start, end, cursor, limit+1)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer closeWithError(rows)
 
@@ -470,13 +1584,22 @@ func (e *eventDeliveryRepo) FindStuckEventDeliveriesByStatus(ctx context.Context
 		var ed datastore.EventDelivery
 		err = rows.StructScan(&ed)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		eventDeliveries = append(eventDeliveries, ed)
 	}
 
-	return eventDeliveries, nil
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(eventDeliveries) <= limit {
+		return eventDeliveries, "", nil
+	}
+
+	eventDeliveries = eventDeliveries[:limit]
+	return eventDeliveries, eventDeliveries[limit-1].UID, nil
 }
 
 func (e *eventDeliveryRepo) UpdateStatusOfEventDelivery(ctx context.Context, projectID string, delivery datastore.EventDelivery, status datastore.EventDeliveryStatus) error {
@@ -529,13 +1652,35 @@ func (e *eventDeliveryRepo) UpdateStatusOfEventDeliveries(ctx context.Context, p
 	return nil
 }
 
+func (e *eventDeliveryRepo) RequeueRecentEventDeliveries(ctx context.Context, projectID string, ids []string, maxAge time.Duration) ([]string, error) {
+	skipped := make([]string, 0)
+	if len(ids) == 0 {
+		return skipped, nil
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	query, args, err := sqlx.In(requeueRecentEventDeliveries, cutoff, projectID, projectID, ids, datastore.ScheduledEventStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	query = e.db.GetDB().Rebind(query)
+
+	err = e.db.GetDB().SelectContext(ctx, &skipped, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return skipped, nil
+}
+
 func (e *eventDeliveryRepo) FindDiscardedEventDeliveries(ctx context.Context, projectID, deviceId string, searchParams datastore.SearchParams) ([]datastore.EventDelivery, error) {
 	eventDeliveries := make([]datastore.EventDelivery, 0)
 
 	start := time.Unix(searchParams.CreatedAtStart, 0)
 	end := time.Unix(searchParams.CreatedAtEnd, 0)
 
-	rows, err := e.db.GetReadDB().QueryxContext(ctx, fetchDiscardedEventDeliveries, datastore.DiscardedEventStatus, projectID, deviceId, start, end)
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchDiscardedEventDeliveries, datastore.DiscardedEventStatus, projectID, deviceId, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -574,7 +1719,9 @@ func (e *eventDeliveryRepo) UpdateEventDeliveryMetadata(ctx context.Context, pro
 	return nil
 }
 
-func (e *eventDeliveryRepo) CountEventDeliveries(ctx context.Context, projectID string, endpointIDs []string, eventID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
+// CountEventDeliveries counts the project's deliveries matching the filters,
+// deliveries are only filtered by their event's source when sourceIDs isn't empty
+func (e *eventDeliveryRepo) CountEventDeliveries(ctx context.Context, projectID string, endpointIDs, sourceIDs []string, eventID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
 	count := struct {
 		Count int64
 	}{}
@@ -588,15 +1735,23 @@ func (e *eventDeliveryRepo) CountEventDeliveries(ctx context.Context, projectID
 		start, end,
 	}
 
-	q := countEventDeliveries
+	q := fmt.Sprintf(countEventDeliveries, "")
+	if len(sourceIDs) > 0 {
+		q = fmt.Sprintf(countEventDeliveries, countEventDeliveriesSourceJoin)
+	}
 
 	if len(endpointIDs) > 0 {
-		q += ` AND endpoint_id IN (?)`
+		q += ` AND ed.endpoint_id IN (?)`
 		args = append(args, endpointIDs)
 	}
 
+	if len(sourceIDs) > 0 {
+		q += ` AND ev.source_id IN (?)`
+		args = append(args, sourceIDs)
+	}
+
 	if len(status) > 0 {
-		q += ` AND status IN (?)`
+		q += ` AND ed.status IN (?)`
 		args = append(args, status)
 	}
 
@@ -607,7 +1762,7 @@ func (e *eventDeliveryRepo) CountEventDeliveries(ctx context.Context, projectID
 
 	query = e.db.GetReadDB().Rebind(query)
 
-	err = e.db.GetReadDB().QueryRowxContext(ctx, query, args...).StructScan(&count)
+	err = readDB(ctx, e.db, datastore.PreferReplica).QueryRowxContext(ctx, query, args...).StructScan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -622,10 +1777,13 @@ func (e *eventDeliveryRepo) DeleteProjectEventDeliveries(ctx context.Context, pr
 	start := time.Unix(filter.CreatedAtStart, 0)
 	end := time.Unix(filter.CreatedAtEnd, 0)
 
+	eventTypes := pq.Array(filter.EventTypes)
+	excludeEventTypes := pq.Array(filter.ExcludeEventTypes)
+
 	if hardDelete {
-		result, err = e.db.GetDB().ExecContext(ctx, hardDeleteProjectEventDeliveries, projectID, start, end)
+		result, err = e.db.GetDB().ExecContext(ctx, hardDeleteProjectEventDeliveries, projectID, start, end, eventTypes, excludeEventTypes)
 	} else {
-		result, err = e.db.GetDB().ExecContext(ctx, softDeleteProjectEventDeliveries, projectID, start, end)
+		result, err = e.db.GetDB().ExecContext(ctx, softDeleteProjectEventDeliveries, projectID, start, end, eventTypes, excludeEventTypes)
 	}
 
 	if err != nil {
@@ -644,9 +1802,97 @@ func (e *eventDeliveryRepo) DeleteProjectEventDeliveries(ctx context.Context, pr
 	return nil
 }
 
-func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projectID string, endpointIDs []string, eventID, subscriptionID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams, pageable datastore.Pageable, idempotencyKey, eventType string) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+// StripProjectEventDeliveryPayloads clears the payload (Metadata.Data and
+// Metadata.Raw) of the project's successful deliveries matching the filter,
+// and returns how many were stripped. The rows themselves are left for
+// reporting.
+func (e *eventDeliveryRepo) StripProjectEventDeliveryPayloads(ctx context.Context, projectID string, filter *datastore.EventDeliveryFilter) (int64, error) {
+	start := time.Unix(filter.CreatedAtStart, 0)
+	end := time.Unix(filter.CreatedAtEnd, 0)
+
+	result, err := e.db.GetDB().ExecContext(ctx, stripProjectEventDeliveryPayloads, projectID, start, end,
+		pq.Array(filter.EventTypes), pq.Array(filter.ExcludeEventTypes), datastore.SuccessEventStatus)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// RequeueEventDeliveriesByFilter schedules every delivery matching the filter
+// for dispatch in a single statement, and returns how many were requeued.
+// FindEventDeliveriesAfterID returns at most limit of the project's
+// deliveries matching filter whose ids come after afterID, in id order.
+// Pass the last returned id back to get the next page, an empty afterID
+// starts from the first delivery.
+func (e *eventDeliveryRepo) FindEventDeliveriesAfterID(ctx context.Context, projectID string, filter *datastore.EventDeliveryFilter, afterID string, limit int) ([]datastore.EventDelivery, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+
+	start := time.Unix(filter.CreatedAtStart, 0)
+	end := time.Unix(filter.CreatedAtEnd, 0)
+
+	status := make([]string, len(filter.Status))
+	for i := range filter.Status {
+		status[i] = string(filter.Status[i])
+	}
+
+	eventDeliveries := make([]datastore.EventDelivery, 0, limit)
+
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, fetchEventDeliveriesAfterID,
+		projectID, start, end, afterID, filter.EndpointID, pq.Array(status),
+		pq.Array(filter.EventTypes), pq.Array(filter.ExcludeEventTypes), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var ed datastore.EventDelivery
+		err = rows.StructScan(&ed)
+		if err != nil {
+			return nil, err
+		}
+
+		eventDeliveries = append(eventDeliveries, ed)
+	}
+
+	return eventDeliveries, rows.Err()
+}
+
+func (e *eventDeliveryRepo) RequeueEventDeliveriesByFilter(ctx context.Context, projectID string, filter *datastore.EventDeliveryFilter) (int64, error) {
+	start := time.Unix(filter.CreatedAtStart, 0)
+	end := time.Unix(filter.CreatedAtEnd, 0)
+
+	status := make([]string, len(filter.Status))
+	for i := range filter.Status {
+		status[i] = string(filter.Status[i])
+	}
+
+	rows, err := e.db.GetDB().QueryxContext(ctx, requeueEventDeliveriesByFilter,
+		projectID, start, end, filter.EndpointID, pq.Array(status),
+		pq.Array(filter.EventTypes), pq.Array(filter.ExcludeEventTypes),
+		datastore.ScheduledEventStatus, datastore.ProcessingEventStatus)
+	if err != nil {
+		return 0, err
+	}
+	defer closeWithError(rows)
+
+	var count int64
+	for rows.Next() {
+		count++
+	}
+
+	return count, rows.Err()
+}
+
+func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projectID string, endpointIDs []string, eventID, subscriptionID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams, pageable datastore.Pageable, idempotencyKey, eventType string, responseStatusCodes []int, readPreference datastore.ReadPreference) ([]datastore.EventDelivery, datastore.PaginationData, error) {
 	eventDeliveriesP := make([]EventDeliveryPaginated, 0)
 
+	// the page and its previous row count are read from the same database
+	db := readDB(ctx, e.db, readPreference)
+
 	start := time.Unix(params.CreatedAtStart, 0)
 	end := time.Unix(params.CreatedAtEnd, 0)
 
@@ -664,11 +1910,29 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 		"idempotency_key": idempotencyKey,
 	}
 
+	if len(responseStatusCodes) > 0 {
+		codes := make([]string, len(responseStatusCodes))
+		for i, code := range responseStatusCodes {
+			codes[i] = strconv.Itoa(code)
+		}
+		arg["response_status_codes"] = codes
+	}
+
+	if pageable.SortByCreatedAt() {
+		cursorCreatedAt, cursorID, err := createdAtCursorBounds(pageable)
+		if err != nil {
+			return nil, datastore.PaginationData{}, err
+		}
+
+		arg["cursor"] = cursorID
+		arg["cursor_created_at"] = cursorCreatedAt
+	}
+
 	var query, filterQuery string
 	if pageable.Direction == datastore.Next {
-		query = getFwdDeliveryPageQuery(pageable.SortOrder())
+		query = getFwdDeliveryPageQuery(pageable.SortOrder(), pageable.SortByCreatedAt())
 	} else {
-		query = getBackwardDeliveryPageQuery(pageable.SortOrder())
+		query = getBackwardDeliveryPageQuery(pageable.SortOrder(), pageable.SortByCreatedAt())
 	}
 
 	filterQuery = baseEventDeliveryFilter
@@ -684,6 +1948,10 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 		filterQuery += ` AND ed.subscription_id = :subscription_id`
 	}
 
+	if len(responseStatusCodes) > 0 {
+		filterQuery += eventDeliveryResponseStatusFilter
+	}
+
 	preOrder := pageable.SortOrder()
 	if pageable.Direction == datastore.Prev {
 		preOrder = reverseOrder(preOrder)
@@ -701,12 +1969,12 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 		return nil, datastore.PaginationData{}, err
 	}
 
-	query = e.db.GetReadDB().Rebind(query)
+	query = db.Rebind(query)
 	// Replace single colons with double colons to handle PostgreSQL type casting
 	// This is needed because sqlx.Named() generates :param but PostgreSQL expects ::param for type casting
 	query = strings.ReplaceAll(query, ":", "::")
 
-	rows, err := e.db.GetReadDB().QueryxContext(ctx, query, args...)
+	rows, err := db.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, datastore.PaginationData{}, err
 	}
@@ -784,8 +2052,9 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 		first := eventDeliveries[0]
 		qarg := arg
 		qarg["cursor"] = first.UID
+		qarg["cursor_created_at"] = first.CreatedAt
 
-		tmp := getCountEventPrevRowQuery(pageable.SortOrder())
+		tmp := getCountEventPrevRowQuery(pageable.SortOrder(), pageable.SortByCreatedAt())
 
 		cq := fmt.Sprintf(tmp, filterQuery, pageable.SortOrder())
 		countQuery, qargs, err = sqlx.Named(cq, qarg)
@@ -798,10 +2067,10 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 			return nil, datastore.PaginationData{}, err
 		}
 
-		countQuery = e.db.GetReadDB().Rebind(countQuery)
+		countQuery = db.Rebind(countQuery)
 
 		// count the row number before the first row
-		rows, err = e.db.GetReadDB().QueryxContext(ctx, countQuery, qargs...)
+		rows, err = db.QueryxContext(ctx, countQuery, qargs...)
 		if err != nil {
 			return nil, datastore.PaginationData{}, err
 		}
@@ -817,6 +2086,10 @@ func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, projec
 
 	ids := make([]string, len(eventDeliveries))
 	for i := range eventDeliveries {
+		if pageable.SortByCreatedAt() {
+			ids[i] = datastore.EncodeCreatedAtCursor(eventDeliveries[i].CreatedAt, eventDeliveries[i].UID)
+			continue
+		}
 		ids[i] = eventDeliveries[i].UID
 	}
 
@@ -837,86 +2110,179 @@ const (
 	yearlyIntervalFormat  = "yyyy"              // 1 month
 )
 
-func (e *eventDeliveryRepo) LoadEventDeliveriesIntervals(ctx context.Context, projectID string, params datastore.SearchParams, period datastore.Period, endpointIds []string) ([]datastore.EventInterval, error) {
+// LoadEventDeliveriesIntervals counts the project's deliveries per period
+// bucket. When withLatency is set the p50, p95 and p99 delivery latency of
+// every bucket is computed as well. Weekly buckets start on weekStart.
+func (e *eventDeliveryRepo) LoadEventDeliveriesIntervals(ctx context.Context, projectID string, params datastore.SearchParams, period datastore.Period, endpointIds []string, withLatency bool, weekStart time.Weekday) ([]datastore.EventInterval, error) {
 	intervals := make([]datastore.EventInterval, 0)
 
 	start := time.Unix(params.CreatedAtStart, 0)
 	end := time.Unix(params.CreatedAtEnd, 0)
 
-	var timeComponent string
-	var format string
-	var extract string
+	timeComponent, format, extract, err := intervalBucketing(period)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := ""
+	var args = []interface{}{projectID, start, end}
+	if len(endpointIds) > 0 {
+		filter = "AND endpoint_id = ANY($4)"
+		args = append(args, pq.Array(endpointIds))
+	}
+
+	percentiles := ""
+	if withLatency {
+		percentiles = eventDeliveriesIntervalLatencyPercentiles
+	}
+
+	shift := weekStartShift(period, weekStart)
+	q := fmt.Sprintf(loadEventDeliveriesIntervals, timeComponent, shift, format, extract, percentiles, filter)
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	for rows.Next() {
+		var interval datastore.EventInterval
+		err = rows.StructScan(&interval)
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, interval)
+	}
+
+	if len(intervals) < minLen {
+		intervals, err = padIntervals(intervals, periodDuration(period), period, weekStart)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return intervals, nil
+}
+
+// LoadEventDeliveriesIntervalsByDeliveryMode counts the project's deliveries per
+// period bucket, separately for each delivery mode. Every mode is present in the
+// result with the same buckets, a mode without deliveries in a bucket counts 0,
+// padded the same way LoadEventDeliveriesIntervals pads its buckets.
+func (e *eventDeliveryRepo) LoadEventDeliveriesIntervalsByDeliveryMode(ctx context.Context, projectID string, params datastore.SearchParams, period datastore.Period) (map[datastore.DeliveryMode][]datastore.EventInterval, error) {
+	start := time.Unix(params.CreatedAtStart, 0)
+	end := time.Unix(params.CreatedAtEnd, 0)
+
+	timeComponent, format, extract, err := intervalBucketing(period)
+	if err != nil {
+		return nil, err
+	}
+
+	q := fmt.Sprintf(loadEventDeliveriesIntervalsByDeliveryMode, timeComponent, weekStartShift(period, time.Monday), format, extract)
+	rows, err := readDB(ctx, e.db, datastore.PreferReplica).QueryxContext(ctx, q, projectID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	intervals := map[datastore.DeliveryMode][]datastore.EventInterval{
+		datastore.AtLeastOnceDeliveryMode: {},
+		datastore.AtMostOnceDeliveryMode:  {},
+		datastore.OrderedDeliveryMode:     {},
+	}
+
+	for rows.Next() {
+		var bucket struct {
+			Data        datastore.EventIntervalData `db:"data"`
+			AtLeastOnce uint64                      `db:"at_least_once"`
+			AtMostOnce  uint64                      `db:"at_most_once"`
+			Ordered     uint64                      `db:"ordered"`
+		}
+
+		err = rows.StructScan(&bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		intervals[datastore.AtLeastOnceDeliveryMode] = append(intervals[datastore.AtLeastOnceDeliveryMode], datastore.EventInterval{Data: bucket.Data, Count: bucket.AtLeastOnce})
+		intervals[datastore.AtMostOnceDeliveryMode] = append(intervals[datastore.AtMostOnceDeliveryMode], datastore.EventInterval{Data: bucket.Data, Count: bucket.AtMostOnce})
+		intervals[datastore.OrderedDeliveryMode] = append(intervals[datastore.OrderedDeliveryMode], datastore.EventInterval{Data: bucket.Data, Count: bucket.Ordered})
+	}
+
+	for mode, modeIntervals := range intervals {
+		if len(modeIntervals) < minLen {
+			intervals[mode], err = padIntervals(modeIntervals, periodDuration(period), period, time.Monday)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return intervals, nil
+}
+
+// intervalBucketing returns the date_trunc field, the display format and the
+// extract field used to bucket deliveries by period.
+func intervalBucketing(period datastore.Period) (timeComponent, format, extract string, err error) {
+	switch period {
+	case datastore.Daily:
+		return "day", dailyIntervalFormat, "doy", nil
+	case datastore.Weekly:
+		return "week", weeklyIntervalFormat, "week", nil
+	case datastore.Monthly:
+		return "month", monthlyIntervalFormat, "month", nil
+	case datastore.Yearly:
+		return "year", yearlyIntervalFormat, "year", nil
+	default:
+		return "", "", "", errors.New("specified data cannot be generated for period")
+	}
+}
+
+// weekStartShift returns the interval created_at is moved by before it is
+// truncated, and the bucket moved back by after. DATE_TRUNC always starts
+// weeks on Monday, so shifting by the days between weekStart and the next
+// Monday makes the buckets start on weekStart instead.
+func weekStartShift(period datastore.Period, weekStart time.Weekday) string {
+	days := 0
+	if period == datastore.Weekly {
+		days = weekStartOffset(weekStart)
+	}
+
+	return fmt.Sprintf("make_interval(days => %d)", days)
+}
+
+// weekStartOffset is the number of days from weekStart to the next Monday
+func weekStartOffset(weekStart time.Weekday) int {
+	return (int(time.Monday) - int(weekStart) + 7) % 7
+}
+
+// startOfWeek returns the midnight that starts t's week, when weeks start on weekStart
+func startOfWeek(t time.Time, weekStart time.Weekday) time.Time {
+	days := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, t.Location())
+}
+
+func periodDuration(period datastore.Period) time.Duration {
 	switch period {
 	case datastore.Daily:
-		timeComponent = "day"
-		format = dailyIntervalFormat
-		extract = "doy"
+		return time.Hour * 24
 	case datastore.Weekly:
-		timeComponent = "week"
-		format = weeklyIntervalFormat
-		extract = timeComponent
+		return time.Hour * 24 * 7
 	case datastore.Monthly:
-		timeComponent = "month"
-		format = monthlyIntervalFormat
-		extract = timeComponent
+		return time.Hour * 24 * 30
 	case datastore.Yearly:
-		timeComponent = "year"
-		format = yearlyIntervalFormat
-		extract = timeComponent
+		return time.Hour * 24 * 365
 	default:
-		return nil, errors.New("specified data cannot be generated for period")
-	}
-
-	filter := ""
-	var args = []interface{}{projectID, start, end}
-	if len(endpointIds) > 0 {
-		filter = "AND endpoint_id = ANY($4)"
-		args = append(args, pq.Array(endpointIds))
-	}
-	q := fmt.Sprintf(loadEventDeliveriesIntervals, timeComponent, timeComponent, format, extract, filter)
-	rows, err := e.db.GetReadDB().QueryxContext(ctx, q, args...)
-	if err != nil {
-		return nil, err
-	}
-
-	for rows.Next() {
-		var interval datastore.EventInterval
-		err = rows.StructScan(&interval)
-		if err != nil {
-			return nil, err
-		}
-
-		intervals = append(intervals, interval)
-	}
-
-	if len(intervals) < minLen {
-		var d time.Duration
-		switch period {
-		case datastore.Daily:
-			d = time.Hour * 24
-		case datastore.Weekly:
-			d = time.Hour * 24 * 7
-		case datastore.Monthly:
-			d = time.Hour * 24 * 30
-		case datastore.Yearly:
-			d = time.Hour * 24 * 365
-		}
-		intervals, err = padIntervals(intervals, d, period)
-		if err != nil {
-			return nil, err
-		}
+		return 0
 	}
-
-	return intervals, nil
 }
 
-func (e *eventDeliveryRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
-	return exportRecords(ctx, e.db.GetReadDB(), "convoy.event_deliveries", projectID, createdAt, w)
+func (e *eventDeliveryRepo) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
+	return e.ExportRecordsFormat(ctx, projectID, createdAt, datastore.JSONExportFormat, w, opts...)
 }
 
 const minLen = 30
 
-func padIntervals(intervals []datastore.EventInterval, duration time.Duration, period datastore.Period) ([]datastore.EventInterval, error) {
+func padIntervals(intervals []datastore.EventInterval, duration time.Duration, period datastore.Period, weekStart time.Weekday) ([]datastore.EventInterval, error) {
 	var err error
 
 	var format string
@@ -935,6 +2301,11 @@ func padIntervals(intervals []datastore.EventInterval, duration time.Duration, p
 	}
 
 	start := time.Now()
+	if period == datastore.Weekly {
+		// line the synthetic buckets up with the ones the query returns
+		start = startOfWeek(start, weekStart)
+	}
+
 	if len(intervals) > 0 {
 		start, err = time.Parse(format, intervals[0].Data.Time)
 		if err != nil {
@@ -952,6 +2323,9 @@ func padIntervals(intervals []datastore.EventInterval, duration time.Duration, p
 				Time:     start.Format(format),
 			},
 			Count: 0,
+			P50:   0,
+			P95:   0,
+			P99:   0,
 		}
 		start = start.Add(-duration)
 	}
@@ -1045,28 +2419,59 @@ func (m *CLIMetadata) Scan(value interface{}) error {
 	return nil
 }
 
-func getFwdDeliveryPageQuery(sortOrder string) string {
+func getFwdDeliveryPageQuery(sortOrder string, byCreatedAt bool) string {
+	query := baseEventDeliveryPagedForward
+	if byCreatedAt {
+		query = baseEventDeliveryPagedForwardByCreatedAt
+	}
+
 	if sortOrder == "ASC" {
-		return strings.Replace(baseEventDeliveryPagedForward, "<=", ">=", 1)
+		return strings.Replace(query, "<=", ">=", 1)
 	}
 
-	return baseEventDeliveryPagedForward
+	return query
 }
 
-func getBackwardDeliveryPageQuery(sortOrder string) string {
+func getBackwardDeliveryPageQuery(sortOrder string, byCreatedAt bool) string {
+	query := baseEventDeliveryPagedBackward
+	if byCreatedAt {
+		query = baseEventDeliveryPagedBackwardByCreatedAt
+	}
+
 	if sortOrder == "ASC" {
-		return strings.Replace(baseEventDeliveryPagedBackward, ">=", "<=", 1)
+		return strings.Replace(query, ">=", "<=", 1)
 	}
 
-	return baseEventDeliveryPagedBackward
+	return query
 }
 
-func getCountEventPrevRowQuery(sortOrder string) string {
+func getCountEventPrevRowQuery(sortOrder string, byCreatedAt bool) string {
+	query := countPrevEventDeliveries
+	if byCreatedAt {
+		query = countPrevEventDeliveriesByCreatedAt
+	}
+
 	if sortOrder == "ASC" {
-		return strings.Replace(countPrevEventDeliveries, ">", "<", 1)
+		return strings.Replace(query, ">", "<", 1)
+	}
+
+	return query
+}
+
+// createdAtCursorBounds decodes the (created_at, id) cursor of a page.
+// An unset cursor, or the default id cursor, starts from the matching end
+// of the table depending on which way the page comparison goes.
+func createdAtCursorBounds(pageable datastore.Pageable) (time.Time, string, error) {
+	cursor := pageable.Cursor()
+	if util.IsStringEmpty(cursor) || cursor == defaultDescCursor || cursor == datastore.DefaultCursor {
+		upperBound := (pageable.Direction == datastore.Next) == (pageable.SortOrder() == "DESC")
+		if upperBound {
+			return maxCursorCreatedAt, defaultDescCursor, nil
+		}
+		return time.Time{}, "", nil
 	}
 
-	return countPrevEventDeliveries
+	return datastore.DecodeCreatedAtCursor(cursor)
 }
 
 func reverseOrder(sortOrder string) string {
@@ -1078,15 +2483,363 @@ func reverseOrder(sortOrder string) string {
 	}
 }
 
-func (e *eventDeliveryRepo) PartitionEventDeliveriesTable(ctx context.Context) error {
+// BackfillLatencySeconds populates latency_seconds for deliveries written
+// before it existed, batchSize rows at a time. The latency is computed from
+// the delivery's first successful attempt, falling back to the deprecated
+// latency string. Rows that already have latency_seconds are left intact.
+func (e *eventDeliveryRepo) BackfillLatencySeconds(ctx context.Context, batchSize int) (int64, error) {
+	if batchSize < 1 {
+		batchSize = defaultLatencyBackfillBatchSize
+	}
+
+	var total int64
+	var cursor string
+
+	for {
+		batch, err := e.fetchEventDeliveriesMissingLatency(ctx, cursor, batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		ids := make([]string, 0, len(batch))
+		latencies := make([]float64, 0, len(batch))
+		for _, row := range batch {
+			seconds, ok := latencySeconds(row.AttemptLatencySeconds, row.Latency)
+			if !ok {
+				continue
+			}
+
+			ids = append(ids, row.ID)
+			latencies = append(latencies, seconds)
+		}
+
+		if len(ids) > 0 {
+			result, err := e.db.GetDB().ExecContext(ctx, backfillEventDeliveriesLatency, pq.StringArray(ids), pq.Float64Array(latencies))
+			if err != nil {
+				return total, err
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return total, err
+			}
+			total += rowsAffected
+		}
+
+		if len(batch) < batchSize {
+			return total, nil
+		}
+		cursor = batch[len(batch)-1].ID
+	}
+}
+
+// PurgeSoftDeletedEventDeliveries hard deletes the deliveries soft deleted
+// before olderThan and their attempts, in batches, and returns how many
+// deliveries were removed.
+func (e *eventDeliveryRepo) PurgeSoftDeletedEventDeliveries(ctx context.Context, olderThan time.Time) (int64, error) {
+	var total int64
+
+	for {
+		batch, purged, err := e.purgeSoftDeletedEventDeliveryBatch(ctx, olderThan)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+
+		if batch < purgeEventDeliveriesBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// purgeSoftDeletedEventDeliveryBatch hard deletes a batch of deliveries soft
+// deleted before olderThan along with their attempts, in one transaction. It
+// returns how many deliveries were in the batch and how many were deleted.
+func (e *eventDeliveryRepo) purgeSoftDeletedEventDeliveryBatch(ctx context.Context, olderThan time.Time) (int64, int64, error) {
+	tx, err := e.db.GetDB().BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rollbackTx(tx)
+
+	var ids []string
+	err = tx.SelectContext(ctx, &ids, fetchSoftDeletedEventDeliveryIDs, olderThan, purgeEventDeliveriesBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, purgeEventDeliveryAttempts, pq.StringArray(ids))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, purgeSoftDeletedEventDeliveries, pq.StringArray(ids))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int64(len(ids)), rowsAffected, nil
+}
+
+// CompactDeliveryAttempts moves the attempts of deliveries written before
+// convoy.delivery_attempts existed, still encoded in their attempts column,
+// into convoy.delivery_attempts, batchSize deliveries at a time. Each
+// delivery's column is cleared in the same transaction its attempts are
+// inserted in, so the command can be stopped and run again. Deliveries whose
+// attempts can't be decoded are left as is. It returns how many attempts
+// were inserted.
+func (e *eventDeliveryRepo) CompactDeliveryAttempts(ctx context.Context, batchSize int) (int64, error) {
+	if batchSize < 1 {
+		batchSize = defaultLatencyBackfillBatchSize
+	}
+
+	var total int64
+	var cursor string
+
+	for {
+		batch, err := e.fetchEventDeliveriesWithLegacyAttempts(ctx, cursor, batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		ids := make([]string, 0, len(batch))
+		values := make([]map[string]interface{}, 0, len(batch))
+		for _, row := range batch {
+			var attempts datastore.DeliveryAttempts
+			err = attempts.Scan(row.Attempts)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Errorf("failed to decode the attempts of event delivery %s", row.ID)
+				continue
+			}
+
+			ids = append(ids, row.ID)
+			for _, attempt := range attempts {
+				values = append(values, legacyAttemptValues(row, attempt))
+			}
+		}
+
+		inserted, err := e.moveLegacyDeliveryAttempts(ctx, ids, values)
+		total += inserted
+		if err != nil {
+			return total, err
+		}
+
+		if len(batch) < batchSize {
+			return total, nil
+		}
+		cursor = batch[len(batch)-1].ID
+	}
+}
+
+func (e *eventDeliveryRepo) moveLegacyDeliveryAttempts(ctx context.Context, ids []string, values []map[string]interface{}) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	tx, err := e.db.GetDB().BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rollbackTx(tx)
+
+	var inserted int64
+
+	// stay well under postgres' limit of 65535 bind parameters per statement
+	const chunkSize = 1000
+	for i := 0; i < len(values); i += chunkSize {
+		j := min(i+chunkSize, len(values))
+
+		result, err := tx.NamedExecContext(ctx, insertLegacyDeliveryAttempts, values[i:j])
+		if err != nil {
+			return 0, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += rowsAffected
+	}
+
+	_, err = tx.ExecContext(ctx, clearLegacyDeliveryAttempts, pq.StringArray(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return inserted, nil
+}
+
+// legacyAttemptValues fills in what legacy attempts were written without
+// from their delivery: an id, the delivery's project and endpoint, and its
+// creation time.
+func legacyAttemptValues(row legacyAttemptsRow, attempt datastore.DeliveryAttempt) map[string]interface{} {
+	id := attempt.UID
+	if util.IsStringEmpty(id) {
+		id = ulid.Make().String()
+	}
+
+	endpointID := attempt.EndpointID
+	if util.IsStringEmpty(endpointID) {
+		endpointID = row.EndpointID
+	}
+
+	createdAt := attempt.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = row.CreatedAt
+	}
+
+	updatedAt := attempt.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+
+	// the response body was encoded as a string, it isn't in ResponseData
+	responseData := attempt.ResponseData
+	if len(responseData) == 0 {
+		responseData = []byte(attempt.ResponseDataString)
+	}
+
+	return map[string]interface{}{
+		"id":                   id,
+		"url":                  attempt.URL,
+		"method":               attempt.Method,
+		"api_version":          attempt.APIVersion,
+		"endpoint_id":          endpointID,
+		"event_delivery_id":    row.ID,
+		"project_id":           row.ProjectID,
+		"ip_address":           attempt.IPAddress,
+		"request_http_header":  attempt.RequestHeader,
+		"response_http_header": attempt.ResponseHeader,
+		"http_status":          attempt.HttpResponseCode,
+		"response_data":        responseData,
+		"error":                attempt.Error,
+		"status":               attempt.Status,
+		"created_at":           createdAt,
+		"updated_at":           updatedAt,
+	}
+}
+
+type legacyAttemptsRow struct {
+	ID         string    `db:"id"`
+	ProjectID  string    `db:"project_id"`
+	EndpointID string    `db:"endpoint_id"`
+	Attempts   []byte    `db:"attempts"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (e *eventDeliveryRepo) fetchEventDeliveriesWithLegacyAttempts(ctx context.Context, cursor string, limit int) ([]legacyAttemptsRow, error) {
+	rows, err := e.db.GetDB().QueryxContext(ctx, fetchEventDeliveriesWithLegacyAttempts, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	batch := make([]legacyAttemptsRow, 0, limit)
+	for rows.Next() {
+		var row legacyAttemptsRow
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		batch = append(batch, row)
+	}
+
+	return batch, rows.Err()
+}
+
+type missingLatencyRow struct {
+	ID                    string          `db:"id"`
+	Latency               string          `db:"latency"`
+	AttemptLatencySeconds sql.NullFloat64 `db:"attempt_latency_seconds"`
+}
+
+func (e *eventDeliveryRepo) fetchEventDeliveriesMissingLatency(ctx context.Context, cursor string, limit int) ([]missingLatencyRow, error) {
+	rows, err := e.db.GetDB().QueryxContext(ctx, fetchEventDeliveriesMissingLatency, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	batch := make([]missingLatencyRow, 0, limit)
+	for rows.Next() {
+		var row missingLatencyRow
+		err = rows.StructScan(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		batch = append(batch, row)
+	}
+
+	return batch, rows.Err()
+}
+
+// latencySeconds prefers the latency measured from the delivery's attempts,
+// and falls back to parsing the deprecated latency string, e.g. "1.5s".
+func latencySeconds(fromAttempts sql.NullFloat64, legacy string) (float64, bool) {
+	if fromAttempts.Valid && fromAttempts.Float64 >= 0 {
+		return fromAttempts.Float64, true
+	}
+
+	d, err := time.ParseDuration(legacy)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+
+	return d.Seconds(), true
+}
+
+// PartitionEventDeliveriesTable converts event_deliveries into a table range
+// partitioned by project and created_at, with one partition per project per
+// day or per month depending on granularity.
+//
+// Daily partitions keep retention precise, a day's deliveries are dropped as
+// soon as they age out, but an instance with many projects and a long
+// retention ends up with tens of thousands of partitions, which slows down
+// query planning. Monthly partitions are about thirty times fewer at the cost
+// of deliveries being kept until their whole month ages out. The retention
+// policy's partitioner only works on days, so the retention policy drops and
+// creates monthly partitions itself.
+func (e *eventDeliveryRepo) PartitionEventDeliveriesTable(ctx context.Context, granularity datastore.PartitionGranularity) error {
+	if !granularity.IsValid() {
+		return fmt.Errorf("unsupported partition granularity %q", granularity)
+	}
+
 	_, err := e.db.GetDB().ExecContext(ctx, partitionEventDeliveriesTable)
 	if err != nil {
 		return err
 	}
 
+	_, err = e.db.GetDB().ExecContext(ctx, "SELECT partition_event_deliveries_table($1);", string(granularity))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// UnPartitionEventDeliveriesTable collapses the partitions of event_deliveries
+// back into a single table, whichever granularity they were created with.
 func (e *eventDeliveryRepo) UnPartitionEventDeliveriesTable(ctx context.Context) error {
 	_, err := e.db.GetDB().ExecContext(ctx, unPartitionEventDeliveriesTable)
 	if err != nil {
@@ -1096,6 +2849,104 @@ func (e *eventDeliveryRepo) UnPartitionEventDeliveriesTable(ctx context.Context)
 	return nil
 }
 
+// EnsureEventDeliveryPartitions creates the partitions of event_deliveries a
+// project is missing between from and to, inclusive, and returns how many it
+// created. Partitions span a day or a month, whichever the table was
+// partitioned with, and periods already covered by one of the project's
+// partitions are skipped, so it's safe to run again over the same range.
+func (e *eventDeliveryRepo) EnsureEventDeliveryPartitions(ctx context.Context, projectID string, from, to time.Time) (int, error) {
+	if to.Before(from) {
+		return 0, fmt.Errorf("the end of the range %s is before its start %s", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+
+	_, err := e.db.GetDB().ExecContext(ctx, ensureEventDeliveryPartitions)
+	if err != nil {
+		return 0, err
+	}
+
+	var created int
+	err = e.db.GetDB().QueryRowxContext(ctx, "SELECT convoy.ensure_event_delivery_partitions($1, $2, $3);",
+		projectID, from.Format(time.DateOnly), to.Format(time.DateOnly)).Scan(&created)
+	if err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+// EventDeliveryPartitionGranularity returns the span of the partitions of
+// event_deliveries, or an empty granularity when the table isn't
+// partitioned or has no partitions yet.
+func (e *eventDeliveryRepo) EventDeliveryPartitionGranularity(ctx context.Context) (datastore.PartitionGranularity, error) {
+	_, err := e.db.GetDB().ExecContext(ctx, ensureEventDeliveryPartitions)
+	if err != nil {
+		return "", err
+	}
+
+	var monthly bool
+	err = e.db.GetDB().QueryRowxContext(ctx, fetchEventDeliveryPartitionGranularity).Scan(&monthly)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if monthly {
+		return datastore.MonthlyPartitionGranularity, nil
+	}
+
+	return datastore.DailyPartitionGranularity, nil
+}
+
+// DropEventDeliveryPartitionsBefore drops the partitions of event_deliveries
+// that end at or before the given time and returns how many it dropped. It's
+// how monthly partitions age out, since the retention policy's partitioner
+// only maintains daily ones.
+func (e *eventDeliveryRepo) DropEventDeliveryPartitionsBefore(ctx context.Context, before time.Time) (int, error) {
+	_, err := e.db.GetDB().ExecContext(ctx, ensureEventDeliveryPartitions)
+	if err != nil {
+		return 0, err
+	}
+
+	var partitions []string
+	err = e.db.GetDB().SelectContext(ctx, &partitions, fetchExpiredEventDeliveryPartitions, before)
+	if err != nil {
+		return 0, err
+	}
+
+	dropped := 0
+	for _, partition := range partitions {
+		_, err = e.db.GetDB().ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS convoy.%s;", pq.QuoteIdentifier(partition)))
+		if err != nil {
+			return dropped, err
+		}
+		dropped++
+	}
+
+	return dropped, nil
+}
+
+const fetchEventDeliveryPartitionGranularity = `
+SELECT b.ends_at - b.starts_at > INTERVAL '2 days'
+FROM convoy.event_delivery_partition_bounds() b
+LIMIT 1;
+`
+
+const fetchExpiredEventDeliveryPartitions = `
+SELECT b.relname FROM (
+    SELECT c.relname, REGEXP_MATCH(
+        PG_GET_EXPR(c.relpartbound, c.oid),
+        $re$FROM \('([^']*)', '([^']*)'\) TO \('([^']*)', '([^']*)'\)$re$
+    ) AS m
+    FROM pg_inherits i
+    JOIN pg_class c ON c.oid = i.inhrelid
+    WHERE i.inhparent = 'convoy.event_deliveries'::REGCLASS
+) b
+WHERE b.m IS NOT NULL AND b.m[1] = b.m[3] AND b.m[4]::TIMESTAMPTZ <= $1
+ORDER BY b.relname;
+`
+
 var partitionEventDeliveriesTable = `
 CREATE OR REPLACE FUNCTION enforce_event_delivery_fk()
     RETURNS TRIGGER AS $$
@@ -1111,12 +2962,30 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
-CREATE OR REPLACE FUNCTION partition_event_deliveries_table()
+DROP FUNCTION IF EXISTS partition_event_deliveries_table();
+
+CREATE OR REPLACE FUNCTION partition_event_deliveries_table(granularity TEXT)
     RETURNS VOID AS $$
 DECLARE
     r RECORD;
+    unit TEXT;
+    step INTERVAL;
+    suffix_format TEXT;
 BEGIN
-    RAISE NOTICE 'Creating partitioned event deliveries table...';
+    CASE granularity
+        WHEN 'daily' THEN
+            unit := 'day';
+            step := INTERVAL '1 day';
+            suffix_format := 'YYYYMMDD';
+        WHEN 'monthly' THEN
+            unit := 'month';
+            step := INTERVAL '1 month';
+            suffix_format := 'YYYYMM';
+        ELSE
+            RAISE EXCEPTION 'unsupported partition granularity %', granularity;
+    END CASE;
+
+    RAISE NOTICE 'Creating % partitioned event deliveries table...', granularity;
 
     -- Drop old partitioned table
     DROP TABLE IF EXISTS convoy.event_deliveries_new;
@@ -1146,21 +3015,23 @@ BEGIN
         acknowledged_at  TIMESTAMP WITH TIME ZONE,
         latency_seconds  NUMERIC,
         delivery_mode    convoy.delivery_mode NOT NULL DEFAULT 'at_least_once',
+        deduplication_key TEXT,
+        device_acknowledged_at TIMESTAMP WITH TIME ZONE,
         PRIMARY KEY (id, created_at, project_id)
     ) PARTITION BY RANGE (project_id, created_at);
 
     RAISE NOTICE 'Creating partitions...';
     FOR r IN
         WITH dates AS (
-            SELECT project_id, created_at::DATE
+            SELECT project_id, DATE_TRUNC(unit, created_at)::DATE AS start_date
             FROM convoy.event_deliveries
-            GROUP BY created_at::DATE, project_id
-            order by created_at::DATE
+            GROUP BY DATE_TRUNC(unit, created_at)::DATE, project_id
+            order by DATE_TRUNC(unit, created_at)::DATE
         )
         SELECT project_id,
-               created_at::TEXT AS start_date,
-               (created_at + 1)::TEXT AS stop_date,
-               'event_deliveries_' || pg_catalog.REPLACE(project_id::TEXT, '-', '') || '_' || pg_catalog.REPLACE(created_at::TEXT, '-', '') AS partition_table_name
+               start_date::TEXT AS start_date,
+               (start_date + step)::DATE::TEXT AS stop_date,
+               'event_deliveries_' || pg_catalog.REPLACE(project_id::TEXT, '-', '') || '_' || TO_CHAR(start_date, suffix_format) AS partition_table_name
         FROM dates
     LOOP
         EXECUTE FORMAT(
@@ -1173,11 +3044,11 @@ BEGIN
     INSERT INTO convoy.event_deliveries_new (
         id, status, description, project_id, created_at, updated_at, endpoint_id, event_id, device_id, subscription_id, metadata, headers,
         attempts, cli_metadata, deleted_at, url_query_params, idempotency_key, latency, event_type, acknowledged_at,
-        latency_seconds, delivery_mode
+        latency_seconds, delivery_mode, deduplication_key, device_acknowledged_at
     )
     SELECT id, status, description, project_id, created_at, updated_at, endpoint_id, event_id, device_id, subscription_id, metadata, headers,
            attempts, cli_metadata, deleted_at, url_query_params, idempotency_key, latency, event_type, acknowledged_at,
-           latency_seconds, COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode
+           latency_seconds, COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode, deduplication_key, device_acknowledged_at
     FROM convoy.event_deliveries;
 
     -- Manage table renaming
@@ -1208,7 +3079,6 @@ BEGIN
     RAISE NOTICE 'Migration complete!';
 END;
 $$ LANGUAGE plpgsql;
-select partition_event_deliveries_table();
 `
 
 var unPartitionEventDeliveriesTable = `
@@ -1243,18 +3113,20 @@ begin
         event_type       TEXT,
         acknowledged_at  TIMESTAMP WITH TIME ZONE,
         latency_seconds  NUMERIC,
-        delivery_mode    convoy.delivery_mode NOT NULL DEFAULT 'at_least_once'
+        delivery_mode    convoy.delivery_mode NOT NULL DEFAULT 'at_least_once',
+        deduplication_key TEXT,
+        device_acknowledged_at TIMESTAMP WITH TIME ZONE
     );
 
     RAISE NOTICE 'Migrating data...';
     INSERT INTO convoy.event_deliveries_new (
         id, status, description, project_id, created_at, updated_at, endpoint_id, event_id, device_id, subscription_id, metadata, headers,
         attempts, cli_metadata, deleted_at, url_query_params, idempotency_key, latency, event_type, acknowledged_at,
-        latency_seconds, delivery_mode
+        latency_seconds, delivery_mode, deduplication_key, device_acknowledged_at
     )
     SELECT id, status, description, project_id, created_at, updated_at, endpoint_id, event_id, device_id, subscription_id, metadata, headers,
            attempts, cli_metadata, deleted_at, url_query_params, idempotency_key, latency, event_type, acknowledged_at,
-           latency_seconds, COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode
+           latency_seconds, COALESCE(delivery_mode, 'at_least_once')::convoy.delivery_mode, deduplication_key, device_acknowledged_at
     FROM convoy.event_deliveries;
 
     ALTER TABLE convoy.delivery_attempts DROP CONSTRAINT if exists delivery_attempts_event_delivery_id_fkey;
@@ -1279,8 +3151,81 @@ begin
     create index idx_event_deliveries_project_id_key on convoy.event_deliveries (project_id);
     create index idx_event_deliveries_status on convoy.event_deliveries (status);
     create index idx_event_deliveries_status_key on convoy.event_deliveries (status);
+    create unique index idx_event_deliveries_id_created_at_project_id on convoy.event_deliveries (id, created_at, project_id);
 
 	RAISE NOTICE 'Successfully un-partitioned events table...';
 end $$ language plpgsql;
 select convoy.un_partition_event_deliveries_table()
 `
+
+var ensureEventDeliveryPartitions = `
+CREATE OR REPLACE FUNCTION convoy.event_delivery_partition_bounds()
+    RETURNS TABLE (project_id TEXT, starts_at TIMESTAMPTZ, ends_at TIMESTAMPTZ) AS $$
+    SELECT b.m[1], b.m[2]::TIMESTAMPTZ, b.m[4]::TIMESTAMPTZ
+    FROM (
+        SELECT REGEXP_MATCH(
+            PG_GET_EXPR(c.relpartbound, c.oid),
+            $re$FROM \('([^']*)', '([^']*)'\) TO \('([^']*)', '([^']*)'\)$re$
+        ) AS m
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'convoy.event_deliveries'::REGCLASS
+    ) b
+    -- partitions spanning more than one project aren't created by convoy
+    WHERE b.m IS NOT NULL AND b.m[1] = b.m[3];
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION convoy.ensure_event_delivery_partitions(project TEXT, from_date DATE, to_date DATE)
+    RETURNS INT AS $$
+DECLARE
+    unit TEXT := 'day';
+    step INTERVAL := INTERVAL '1 day';
+    suffix_format TEXT := 'YYYYMMDD';
+    span INTERVAL;
+    start_date DATE;
+    stop_date DATE;
+    created INT := 0;
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = 'convoy' AND c.relname = 'event_deliveries' AND c.relkind = 'p'
+    ) THEN
+        RAISE EXCEPTION 'convoy.event_deliveries is not partitioned';
+    END IF;
+
+    -- the granularity is taken from the project's partitions, or any other
+    -- project's when it has none yet
+    SELECT b.ends_at - b.starts_at INTO span
+    FROM convoy.event_delivery_partition_bounds() b
+    ORDER BY b.project_id = project DESC
+    LIMIT 1;
+
+    IF span > INTERVAL '2 days' THEN
+        unit := 'month';
+        step := INTERVAL '1 month';
+        suffix_format := 'YYYYMM';
+    END IF;
+
+    start_date := DATE_TRUNC(unit, from_date)::DATE;
+    WHILE start_date <= to_date LOOP
+        stop_date := (start_date + step)::DATE;
+
+        IF NOT EXISTS (
+            SELECT 1 FROM convoy.event_delivery_partition_bounds() b
+            WHERE b.project_id = project AND b.starts_at < stop_date AND b.ends_at > start_date
+        ) THEN
+            EXECUTE FORMAT(
+                'CREATE TABLE IF NOT EXISTS convoy.%s PARTITION OF convoy.event_deliveries FOR VALUES FROM (%L, %L) TO (%L, %L)',
+                'event_deliveries_' || pg_catalog.REPLACE(project, '-', '') || '_' || TO_CHAR(start_date, suffix_format),
+                project, start_date, project, stop_date
+            );
+            created := created + 1;
+        END IF;
+
+        start_date := stop_date;
+    END LOOP;
+
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+`
@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+const (
+	exportEventDeliveriesFormatted = `
+    SELECT JSON_BUILD_OBJECT(%s) AS record
+    FROM convoy.event_deliveries ed
+    LEFT JOIN convoy.endpoints ep ON ed.endpoint_id = ep.id
+    LEFT JOIN convoy.events ev ON ed.event_id = ev.id
+    LEFT JOIN convoy.sources s ON s.id = ev.source_id
+    WHERE ed.deleted_at IS NULL AND ed.project_id = $1 AND ed.created_at < $2 %s
+    ORDER BY ed.id;
+    `
+
+	// exportEventDeliveriesSampleWhere is sampleWhere for the joined query
+	exportEventDeliveriesSampleWhere = ` AND (hashtext(ed.id || $3) & 2147483647) %% %d < $4`
+)
+
+type exportColumn struct {
+	name string
+	expr string
+}
+
+// eventDeliveryExportColumns are the fields ExportRecordsFormat can emit, in
+// the order they're emitted when no columns are selected.
+var eventDeliveryExportColumns = []exportColumn{
+	{name: "uid", expr: "ed.id"},
+	{name: "project_id", expr: "ed.project_id"},
+	{name: "event_id", expr: "ed.event_id"},
+	{name: "endpoint_id", expr: "ed.endpoint_id"},
+	{name: "subscription_id", expr: "ed.subscription_id"},
+	{name: "device_id", expr: "ed.device_id"},
+	{name: "event_type", expr: "ed.event_type"},
+	{name: "status", expr: "ed.status"},
+	{name: "description", expr: "ed.description"},
+	{name: "delivery_mode", expr: "COALESCE(ed.delivery_mode, 'at_least_once')"},
+	{name: "idempotency_key", expr: "ed.idempotency_key"},
+	{name: "url_query_params", expr: "ed.url_query_params"},
+	{name: "latency_seconds", expr: "ed.latency_seconds"},
+	{name: "headers", expr: "ed.headers"},
+	{name: "metadata", expr: "ed.metadata"},
+	{name: "cli_metadata", expr: "ed.cli_metadata"},
+	{name: "created_at", expr: "ed.created_at"},
+	{name: "updated_at", expr: "ed.updated_at"},
+	{name: "acknowledged_at", expr: "ed.acknowledged_at"},
+	{
+		name: "endpoint_metadata",
+		expr: `CASE WHEN ep.id IS NULL THEN NULL ELSE JSON_BUILD_OBJECT(
+            'id', ep.id, 'name', ep.name, 'url', ep.url,
+            'owner_id', ep.owner_id, 'support_email', ep.support_email) END`,
+	},
+	{
+		name: "source_metadata",
+		expr: `CASE WHEN s.id IS NULL THEN NULL ELSE JSON_BUILD_OBJECT('id', s.id, 'name', s.name) END`,
+	},
+}
+
+// ExportRecordsFormat streams the project's deliveries created before createdAt
+// to w in the given format, and returns how many were written. Rows are read
+// through a single cursor on the read database, so memory use doesn't grow
+// with the number of rows. JSON exports are the full rows, as ExportRecords
+// has always written them, NDJSON and CSV exports include the endpoint and
+// source metadata and honour datastore.WithColumns.
+func (e *eventDeliveryRepo) ExportRecordsFormat(ctx context.Context, projectID string, createdAt time.Time, format datastore.ExportFormat, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
+	switch format {
+	case datastore.JSONExportFormat:
+		return exportRecords(ctx, e.db.GetReadDB(), "convoy.event_deliveries", projectID, createdAt, w, opts...)
+	case datastore.NDJSONExportFormat, datastore.CSVExportFormat:
+	default:
+		return 0, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	o := &datastore.ExportOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.SampleRate < 0 || o.SampleRate > 1 {
+		return 0, fmt.Errorf("sample rate must be between 0 and 1, got %v", o.SampleRate)
+	}
+
+	columns, err := selectExportColumns(o.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	fields := make([]string, 0, len(columns))
+	for _, c := range columns {
+		fields = append(fields, fmt.Sprintf("'%s', %s", c.name, c.expr))
+	}
+
+	filter := ""
+	args := []interface{}{projectID, createdAt}
+	if o.SampleRate > 0 && o.SampleRate < 1 {
+		filter = fmt.Sprintf(exportEventDeliveriesSampleWhere, sampleBuckets)
+		args = append(args, o.SampleSeed, int(math.Round(o.SampleRate*sampleBuckets)))
+	}
+
+	q := fmt.Sprintf(exportEventDeliveriesFormatted, strings.Join(fields, ", "), filter)
+	rows, err := e.db.GetReadDB().QueryxContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer closeWithError(rows)
+
+	bw := bufio.NewWriter(w)
+
+	var write func(record json.RawMessage) error
+	if format == datastore.NDJSONExportFormat {
+		write = func(record json.RawMessage) error {
+			_, err := bw.Write(append(record, '\n'))
+			return err
+		}
+	} else {
+		cw := csv.NewWriter(bw)
+
+		header := make([]string, 0, len(columns))
+		for _, c := range columns {
+			header = append(header, c.name)
+		}
+
+		err = cw.Write(header)
+		if err != nil {
+			return 0, err
+		}
+
+		cw.Flush()
+		if err = cw.Error(); err != nil {
+			return 0, err
+		}
+
+		write = func(record json.RawMessage) error {
+			line, err := csvRecord(record, header)
+			if err != nil {
+				return err
+			}
+
+			err = cw.Write(line)
+			if err != nil {
+				return err
+			}
+
+			// csv.Writer only reports write errors through Error
+			cw.Flush()
+			return cw.Error()
+		}
+	}
+
+	var numDocs int64
+	var record json.RawMessage
+	for rows.Next() {
+		err = rows.Scan(&record)
+		if err != nil {
+			return numDocs, err
+		}
+
+		err = write(record)
+		if err != nil {
+			return numDocs, err
+		}
+		numDocs++
+	}
+
+	if err = rows.Err(); err != nil {
+		return numDocs, err
+	}
+
+	return numDocs, bw.Flush()
+}
+
+func selectExportColumns(names []string) ([]exportColumn, error) {
+	if len(names) == 0 {
+		return eventDeliveryExportColumns, nil
+	}
+
+	columns := make([]exportColumn, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, c := range eventDeliveryExportColumns {
+			if c.name == name {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown export column %q", name)
+		}
+	}
+
+	return columns, nil
+}
+
+// csvRecord flattens a JSON record into CSV fields in the order of columns.
+// Strings are written as they are, nulls as empty fields and everything else,
+// nested objects included, as JSON.
+func csvRecord(record json.RawMessage, columns []string) ([]string, error) {
+	var values map[string]json.RawMessage
+	err := json.Unmarshal(record, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	line := make([]string, 0, len(columns))
+	for _, c := range columns {
+		v := bytes.TrimSpace(values[c])
+
+		switch {
+		case len(v) == 0 || bytes.Equal(v, []byte("null")):
+			line = append(line, "")
+		case v[0] == '"':
+			var s string
+			err = json.Unmarshal(v, &s)
+			if err != nil {
+				return nil, err
+			}
+			line = append(line, s)
+		default:
+			line = append(line, string(v))
+		}
+	}
+
+	return line, nil
+}
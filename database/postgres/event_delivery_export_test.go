@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_csvRecord(t *testing.T) {
+	record := json.RawMessage(`{"uid": "ed-1", "latency_seconds": 1.5, "acknowledged_at": null, "description": "line one\nline \"two\"", "endpoint_metadata": {"id": "ep-1", "name": "billing"}}`)
+
+	line, err := csvRecord(record, []string{"endpoint_metadata", "uid", "acknowledged_at", "latency_seconds", "description", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"id": "ep-1", "name": "billing"}`, "ed-1", "", "1.5", "line one\nline \"two\"", ""}, line)
+}
+
+func Test_selectExportColumns(t *testing.T) {
+	columns, err := selectExportColumns(nil)
+	require.NoError(t, err)
+	require.Equal(t, eventDeliveryExportColumns, columns)
+
+	columns, err = selectExportColumns([]string{"status", "uid"})
+	require.NoError(t, err)
+	require.Equal(t, []exportColumn{{name: "status", expr: "ed.status"}, {name: "uid", expr: "ed.id"}}, columns)
+
+	_, err = selectExportColumns([]string{"uid", "id; DROP TABLE convoy.event_deliveries"})
+	require.Error(t, err)
+}
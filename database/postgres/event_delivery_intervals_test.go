@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func Test_startOfWeek(t *testing.T) {
+	// a wednesday
+	now := time.Date(2024, time.March, 6, 15, 30, 0, 0, time.UTC)
+
+	require.Equal(t, time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC), startOfWeek(now, time.Monday))
+	require.Equal(t, time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC), startOfWeek(now, time.Sunday))
+	require.Equal(t, time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC), startOfWeek(now, time.Wednesday))
+	require.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), startOfWeek(now, time.Thursday))
+}
+
+func Test_weekStartShift(t *testing.T) {
+	require.Equal(t, "make_interval(days => 0)", weekStartShift(datastore.Weekly, time.Monday))
+	require.Equal(t, "make_interval(days => 1)", weekStartShift(datastore.Weekly, time.Sunday))
+	require.Equal(t, "make_interval(days => 2)", weekStartShift(datastore.Weekly, time.Saturday))
+	require.Equal(t, "make_interval(days => 6)", weekStartShift(datastore.Weekly, time.Tuesday))
+
+	// only weeks are anchored
+	require.Equal(t, "make_interval(days => 0)", weekStartShift(datastore.Daily, time.Sunday))
+}
+
+func Test_padIntervalsWeekStart(t *testing.T) {
+	for _, weekStart := range []time.Weekday{time.Monday, time.Sunday, time.Friday} {
+		intervals, err := padIntervals(nil, periodDuration(datastore.Weekly), datastore.Weekly, weekStart)
+		require.NoError(t, err)
+		require.Len(t, intervals, minLen)
+
+		for _, interval := range intervals {
+			bucket, err := time.Parse("2006-01-02", interval.Data.Time)
+			require.NoError(t, err)
+			require.Equal(t, weekStart, bucket.Weekday())
+		}
+	}
+
+	// padding keeps to the anchor of the buckets the query returned
+	intervals, err := padIntervals([]datastore.EventInterval{
+		{Data: datastore.EventIntervalData{Time: "2024-03-03"}, Count: 4},
+	}, periodDuration(datastore.Weekly), datastore.Weekly, time.Sunday)
+	require.NoError(t, err)
+	require.Len(t, intervals, minLen)
+	require.Equal(t, "2024-02-25", intervals[minLen-2].Data.Time)
+	require.Equal(t, uint64(4), intervals[minLen-1].Count)
+}
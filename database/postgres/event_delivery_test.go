@@ -5,7 +5,12 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"gopkg.in/guregu/null.v4"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -55,6 +60,172 @@ func Test_eventDeliveryRepo_CreateEventDelivery(t *testing.T) {
 	require.Equal(t, ed, dbEventDelivery)
 }
 
+func Test_eventDeliveryRepo_DeduplicateEventDeliveries(t *testing.T) {
+	for _, partitioned := range []bool{false, true} {
+		t.Run(fmt.Sprintf("partitioned=%v", partitioned), func(t *testing.T) {
+			db, closeFn := getDB(t)
+			defer closeFn()
+
+			source := seedSource(t, db)
+			project := seedProject(t, db)
+			device := seedDevice(t, db)
+			endpoint := seedEndpoint(t, db)
+			event := seedEvent(t, db, project)
+			sub := seedSubscription(t, db, project, source, endpoint, device)
+
+			edRepo := NewEventDeliveryRepo(db)
+			ctx := context.Background()
+
+			createdAt := time.Now().UTC().Truncate(time.Microsecond)
+
+			if partitioned {
+				require.NoError(t, edRepo.PartitionEventDeliveriesTable(ctx, datastore.DailyPartitionGranularity))
+				defer func() {
+					require.NoError(t, edRepo.UnPartitionEventDeliveriesTable(ctx))
+				}()
+
+				_, err := edRepo.EnsureEventDeliveryPartitions(ctx, project.UID, createdAt, createdAt.AddDate(0, 0, 1))
+				require.NoError(t, err)
+			}
+
+			newDelivery := func(createdAt time.Time) *datastore.EventDelivery {
+				ed := generateEventDelivery(project, endpoint, event, device, sub)
+				ed.DeduplicationKey = datastore.EventDeliveryDeduplicationKey(endpoint.UID, event.UID)
+				ed.CreatedAt = createdAt
+				return ed
+			}
+
+			first := newDelivery(createdAt)
+			created, err := edRepo.CreateEventDeliveries(ctx, []*datastore.EventDelivery{first})
+			require.NoError(t, err)
+			require.Equal(t, []*datastore.EventDelivery{first}, created)
+
+			// the same event fanned out to the same endpoint again is skipped,
+			// however long after the first delivery it's created
+			for _, later := range []time.Time{createdAt, createdAt.Add(time.Second), createdAt.Add(time.Hour)} {
+				created, err = edRepo.CreateEventDeliveries(ctx, []*datastore.EventDelivery{newDelivery(later)})
+				require.NoError(t, err)
+				require.Empty(t, created)
+
+				err = edRepo.CreateEventDelivery(ctx, newDelivery(later))
+				require.ErrorIs(t, err, datastore.ErrDuplicateEventDelivery)
+			}
+
+			// a key repeated within a batch goes to the first delivery
+			otherEndpoint := seedEndpoint(t, db)
+			batch := []*datastore.EventDelivery{newDelivery(createdAt), newDelivery(createdAt)}
+			for _, ed := range batch {
+				ed.DeduplicationKey = datastore.EventDeliveryDeduplicationKey(otherEndpoint.UID, event.UID)
+			}
+			created, err = edRepo.CreateEventDeliveries(ctx, batch)
+			require.NoError(t, err)
+			require.Equal(t, batch[:1], created)
+
+			// other conflicts aren't swallowed along with duplicates
+			reused := generateEventDelivery(project, endpoint, event, device, sub)
+			reused.UID = first.UID
+			reused.CreatedAt = createdAt
+			_, err = edRepo.CreateEventDeliveries(ctx, []*datastore.EventDelivery{reused})
+			require.Error(t, err)
+
+			// deliveries without a key are never deduplicated
+			unkeyed := []*datastore.EventDelivery{
+				generateEventDelivery(project, endpoint, event, device, sub),
+				generateEventDelivery(project, endpoint, event, device, sub),
+			}
+			for _, ed := range unkeyed {
+				ed.CreatedAt = createdAt
+			}
+			created, err = edRepo.CreateEventDeliveries(ctx, unkeyed)
+			require.NoError(t, err)
+			require.Len(t, created, 2)
+
+			// a replay releases the keys so the event can be delivered again
+			err = edRepo.ReleaseEventDeliveryDeduplicationKeys(ctx, project.UID, event.UID)
+			require.NoError(t, err)
+
+			replayed := newDelivery(createdAt.Add(time.Hour))
+			created, err = edRepo.CreateEventDeliveries(ctx, []*datastore.EventDelivery{replayed})
+			require.NoError(t, err)
+			require.Equal(t, []*datastore.EventDelivery{replayed}, created)
+
+			deliveries, err := edRepo.FindEventDeliveriesByEventID(ctx, project.UID, event.UID)
+			require.NoError(t, err)
+			require.Len(t, deliveries, 5)
+
+			// keys are purged with the events they were claimed for
+			purged, err := edRepo.PurgeEventDeliveryDeduplicationKeys(ctx, createdAt)
+			require.NoError(t, err)
+			require.Zero(t, purged)
+
+			purged, err = edRepo.PurgeEventDeliveryDeduplicationKeys(ctx, createdAt.Add(2*time.Hour))
+			require.NoError(t, err)
+			require.Equal(t, int64(1), purged)
+		})
+	}
+}
+
+func Test_eventDeliveryRepo_CreateEventDeliveryIsIdempotent(t *testing.T) {
+	for _, partitioned := range []bool{false, true} {
+		t.Run(fmt.Sprintf("partitioned=%v", partitioned), func(t *testing.T) {
+			db, closeFn := getDB(t)
+			defer closeFn()
+
+			source := seedSource(t, db)
+			project := seedProject(t, db)
+			device := seedDevice(t, db)
+			endpoint := seedEndpoint(t, db)
+			event := seedEvent(t, db, project)
+			sub := seedSubscription(t, db, project, source, endpoint, device)
+
+			edRepo := NewEventDeliveryRepo(db)
+			ctx := context.Background()
+
+			createdAt := time.Now().UTC().Truncate(time.Microsecond)
+
+			if partitioned {
+				require.NoError(t, edRepo.PartitionEventDeliveriesTable(ctx, datastore.DailyPartitionGranularity))
+				defer func() {
+					require.NoError(t, edRepo.UnPartitionEventDeliveriesTable(ctx))
+				}()
+
+				_, err := edRepo.EnsureEventDeliveryPartitions(ctx, project.UID, createdAt, createdAt)
+				require.NoError(t, err)
+			}
+
+			unkeyed := generateEventDelivery(project, endpoint, event, device, sub)
+			unkeyed.CreatedAt = createdAt
+
+			keyed := generateEventDelivery(project, endpoint, event, device, sub)
+			keyed.DeduplicationKey = datastore.EventDeliveryDeduplicationKey(endpoint.UID, event.UID)
+			keyed.CreatedAt = createdAt
+
+			for _, ed := range []*datastore.EventDelivery{unkeyed, keyed} {
+				require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+				// inserting the same delivery again, e.g. from a retried
+				// fan-out, is a no-op
+				require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+				var count int
+				err := db.GetDB().QueryRowxContext(ctx, `SELECT COUNT(*) FROM convoy.event_deliveries WHERE id = $1`, ed.UID).Scan(&count)
+				require.NoError(t, err)
+				require.Equal(t, 1, count)
+
+				dbEd, err := edRepo.FindEventDeliveryByID(ctx, project.UID, ed.UID)
+				require.NoError(t, err)
+				require.True(t, ed.CreatedAt.Equal(dbEd.CreatedAt))
+			}
+
+			// the key stays with the delivery that claimed it
+			other := generateEventDelivery(project, endpoint, event, device, sub)
+			other.DeduplicationKey = keyed.DeduplicationKey
+			other.CreatedAt = createdAt
+			require.ErrorIs(t, edRepo.CreateEventDelivery(ctx, other), datastore.ErrDuplicateEventDelivery)
+		})
+	}
+}
+
 func generateEventDelivery(project *datastore.Project, endpoint *datastore.Endpoint, event *datastore.Event, device *datastore.Device, sub *datastore.Subscription) *datastore.EventDelivery {
 	e := &datastore.EventDelivery{
 		UID:            ulid.Make().String(),
@@ -194,6 +365,51 @@ func Test_eventDeliveryRepo_FindEventDeliveriesByEventID(t *testing.T) {
 	}
 }
 
+func Test_eventDeliveryRepo_FindEventDeliveriesByEventIDs(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	first, second, other := seedEvent(t, db, project), seedEvent(t, db, project), seedEvent(t, db, project)
+
+	created := map[string][]string{}
+	for i, event := range []*datastore.Event{second, first, other, first, second, first} {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.CreatedAt = time.Now().Add(time.Duration(i) * time.Second)
+
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		created[event.UID] = append(created[event.UID], ed.UID)
+	}
+
+	deliveries, err := edRepo.FindEventDeliveriesByEventIDs(context.Background(), project.UID, []string{first.UID, second.UID})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 5)
+
+	// deliveries come grouped by event, oldest first within each event
+	got := map[string][]string{}
+	for i := range deliveries {
+		if i > 0 && deliveries[i].EventID == deliveries[i-1].EventID {
+			require.False(t, deliveries[i].CreatedAt.Before(deliveries[i-1].CreatedAt))
+		}
+
+		got[deliveries[i].EventID] = append(got[deliveries[i].EventID], deliveries[i].UID)
+	}
+
+	require.Equal(t, map[string][]string{first.UID: created[first.UID], second.UID: created[second.UID]}, got)
+	require.True(t, sort.SliceIsSorted(deliveries, func(i, j int) bool { return deliveries[i].EventID < deliveries[j].EventID }))
+
+	deliveries, err = edRepo.FindEventDeliveriesByEventIDs(context.Background(), project.UID, []string{})
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
+
 func Test_eventDeliveryRepo_CountDeliveriesByStatus(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -228,7 +444,7 @@ func Test_eventDeliveryRepo_CountDeliveriesByStatus(t *testing.T) {
 	require.Equal(t, int64(3), count)
 }
 
-func Test_eventDeliveryRepo_UpdateStatusOfEventDelivery(t *testing.T) {
+func Test_eventDeliveryRepo_CountDeliveriesByEventType(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
 
@@ -239,22 +455,42 @@ func Test_eventDeliveryRepo_UpdateStatusOfEventDelivery(t *testing.T) {
 	event := seedEvent(t, db, project)
 	sub := seedSubscription(t, db, project, source, endpoint, device)
 
-	ed := generateEventDelivery(project, endpoint, event, device, sub)
-
 	edRepo := NewEventDeliveryRepo(db)
-	err := edRepo.CreateEventDelivery(context.Background(), ed)
-	require.NoError(t, err)
 
-	err = edRepo.UpdateStatusOfEventDelivery(context.Background(), project.UID, *ed, datastore.RetryEventStatus)
+	for i := 0; i < 8; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = datastore.FailureEventStatus
+		ed.EventType = "invoice.paid"
+		if i%2 == 0 {
+			ed.EventType = "invoice.created"
+		}
+		if i == 6 {
+			ed.Status = datastore.SuccessEventStatus
+		}
+
+		err := edRepo.CreateEventDelivery(context.Background(), ed)
+		require.NoError(t, err)
+	}
+
+	params := datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}
+
+	count, err := edRepo.CountDeliveriesByEventType(context.Background(), project.UID, "invoice.created", datastore.FailureEventStatus, params)
 	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
 
-	dbEventDelivery, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, ed.UID)
+	count, err = edRepo.CountDeliveriesByEventType(context.Background(), project.UID, "invoice.paid", datastore.FailureEventStatus, params)
 	require.NoError(t, err)
+	require.Equal(t, int64(4), count)
 
-	require.Equal(t, datastore.RetryEventStatus, dbEventDelivery.Status)
+	count, err = edRepo.CountDeliveriesByEventType(context.Background(), project.UID, "invoice.voided", datastore.FailureEventStatus, params)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), count)
 }
 
-func Test_eventDeliveryRepo_UpdateStatusOfEventDeliveries(t *testing.T) {
+func Test_eventDeliveryRepo_GetDeliverySLACompliance(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
 
@@ -265,28 +501,60 @@ func Test_eventDeliveryRepo_UpdateStatusOfEventDeliveries(t *testing.T) {
 	event := seedEvent(t, db, project)
 	sub := seedSubscription(t, db, project, source, endpoint, device)
 
-	ed1 := generateEventDelivery(project, endpoint, event, device, sub)
-	ed2 := generateEventDelivery(project, endpoint, event, device, sub)
-
 	edRepo := NewEventDeliveryRepo(db)
-	err := edRepo.CreateEventDelivery(context.Background(), ed1)
-	require.NoError(t, err)
 
-	err = edRepo.CreateEventDelivery(context.Background(), ed2)
+	deliveries := []struct {
+		status  datastore.EventDeliveryStatus
+		latency float64
+	}{
+		{status: datastore.SuccessEventStatus, latency: 1},
+		{status: datastore.SuccessEventStatus, latency: 5},
+		{status: datastore.SuccessEventStatus, latency: 10},
+		{status: datastore.SuccessEventStatus, latency: 45},
+		{status: datastore.FailureEventStatus, latency: 0},
+	}
+
+	for _, d := range deliveries {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		err := edRepo.CreateEventDelivery(context.Background(), ed)
+		require.NoError(t, err)
+
+		ed.Status = d.status
+		ed.LatencySeconds = d.latency
+		err = edRepo.UpdateEventDeliveryMetadata(context.Background(), project.UID, ed)
+		require.NoError(t, err)
+	}
+
+	// still in flight and within budget, so it isn't measured yet
+	ed := generateEventDelivery(project, endpoint, event, device, sub)
+	ed.Status = datastore.RetryEventStatus
+	err := edRepo.CreateEventDelivery(context.Background(), ed)
 	require.NoError(t, err)
 
-	err = edRepo.UpdateStatusOfEventDeliveries(context.Background(), project.UID, []string{ed1.UID, ed2.UID}, datastore.RetryEventStatus)
+	// succeeded without a recorded latency, so it can't be measured
+	ed = generateEventDelivery(project, endpoint, event, device, sub)
+	ed.Status = datastore.SuccessEventStatus
+	err = edRepo.CreateEventDelivery(context.Background(), ed)
 	require.NoError(t, err)
 
-	dbEventDeliveries, err := edRepo.FindEventDeliveriesByIDs(context.Background(), project.UID, []string{ed1.UID, ed2.UID})
+	_, err = db.GetDB().ExecContext(context.Background(), "UPDATE convoy.event_deliveries SET latency_seconds = NULL WHERE id = $1", ed.UID)
 	require.NoError(t, err)
 
-	for _, d := range dbEventDeliveries {
-		require.Equal(t, datastore.RetryEventStatus, d.Status)
+	params := datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
 	}
+
+	percentage, err := edRepo.GetDeliverySLACompliance(context.Background(), project.UID, 10*time.Second, params)
+	require.NoError(t, err)
+	require.InDelta(t, 60.0, percentage, 0.001)
+
+	percentage, err = edRepo.GetDeliverySLACompliance(context.Background(), ulid.Make().String(), 10*time.Second, params)
+	require.NoError(t, err)
+	require.Equal(t, 0.0, percentage)
 }
 
-func Test_eventDeliveryRepo_FindDiscardedEventDeliveries(t *testing.T) {
+func Test_eventDeliveryRepo_LoadAttemptCountDistribution(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
 
@@ -299,30 +567,53 @@ func Test_eventDeliveryRepo_FindDiscardedEventDeliveries(t *testing.T) {
 
 	edRepo := NewEventDeliveryRepo(db)
 
-	status := datastore.DiscardedEventStatus
-	for i := 0; i < 8; i++ {
+	deliveries := []struct {
+		status    datastore.EventDeliveryStatus
+		numTrials uint64
+	}{
+		{status: datastore.SuccessEventStatus, numTrials: 1},
+		{status: datastore.SuccessEventStatus, numTrials: 1},
+		{status: datastore.SuccessEventStatus, numTrials: 1},
+		{status: datastore.SuccessEventStatus, numTrials: 2},
+		{status: datastore.SuccessEventStatus, numTrials: 2},
+		{status: datastore.SuccessEventStatus, numTrials: 4},
+		// only successful deliveries are counted
+		{status: datastore.FailureEventStatus, numTrials: 3},
+		{status: datastore.RetryEventStatus, numTrials: 2},
+	}
 
+	for _, d := range deliveries {
 		ed := generateEventDelivery(project, endpoint, event, device, sub)
-		if i == 1 || i == 4 || i == 5 {
-			ed.Status = status
-		}
-
 		err := edRepo.CreateEventDelivery(context.Background(), ed)
 		require.NoError(t, err)
+
+		ed.Status = d.status
+		ed.Metadata.NumTrials = d.numTrials
+		err = edRepo.UpdateEventDeliveryMetadata(context.Background(), project.UID, ed)
+		require.NoError(t, err)
 	}
 
-	dbEventDeliveries, err := edRepo.FindDiscardedEventDeliveries(context.Background(), project.UID, device.UID, datastore.SearchParams{
+	params := datastore.SearchParams{
 		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
 		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
-	})
-	require.NoError(t, err)
-
-	for _, d := range dbEventDeliveries {
-		require.Equal(t, datastore.DiscardedEventStatus, d.Status)
 	}
+
+	distribution, err := edRepo.LoadAttemptCountDistribution(context.Background(), project.UID, params)
+	require.NoError(t, err)
+	require.Equal(t, []datastore.AttemptCount{
+		{Attempts: 1, Count: 3},
+		{Attempts: 2, Count: 2},
+		{Attempts: 4, Count: 1},
+	}, distribution)
+
+	// outside the window
+	params.CreatedAtEnd = time.Now().Add(-time.Minute).Unix()
+	distribution, err = edRepo.LoadAttemptCountDistribution(context.Background(), project.UID, params)
+	require.NoError(t, err)
+	require.Empty(t, distribution)
 }
 
-func Test_eventDeliveryRepo_UpdateEventDeliveryWithAttempt(t *testing.T) {
+func Test_eventDeliveryRepo_FindDeadLetteredEventDeliveries(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
 
@@ -333,29 +624,28 @@ func Test_eventDeliveryRepo_UpdateEventDeliveryWithAttempt(t *testing.T) {
 	event := seedEvent(t, db, project)
 	sub := seedSubscription(t, db, project, source, endpoint, device)
 
-	ed := generateEventDelivery(project, endpoint, event, device, sub)
-
 	edRepo := NewEventDeliveryRepo(db)
-	err := edRepo.CreateEventDelivery(context.Background(), ed)
-	require.NoError(t, err)
 
-	latency := "1h2m"
-	latencySeconds := 3720.0
+	failed := generateEventDelivery(project, endpoint, event, device, sub)
+	failed.Status = datastore.FailureEventStatus
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), failed))
 
-	ed.Latency = latency
-	ed.LatencySeconds = latencySeconds
+	retrying := generateEventDelivery(project, endpoint, event, device, sub)
+	retrying.Status = datastore.RetryEventStatus
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), retrying))
 
-	err = edRepo.UpdateEventDeliveryMetadata(context.Background(), project.UID, ed)
+	deliveries, err := edRepo.FindDeadLetteredEventDeliveries(context.Background(), project.UID, time.Now().Add(time.Minute))
 	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, failed.UID, deliveries[0].UID)
 
-	dbEventDelivery, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, ed.UID)
+	// deliveries that failed after the cutoff are not yet eligible
+	deliveries, err = edRepo.FindDeadLetteredEventDeliveries(context.Background(), project.UID, time.Now().Add(-time.Hour))
 	require.NoError(t, err)
-
-	require.Equal(t, "", dbEventDelivery.Latency)
-	require.Equal(t, latencySeconds, dbEventDelivery.LatencySeconds)
+	require.Empty(t, deliveries)
 }
 
-func Test_eventDeliveryRepo_CountEventDeliveries(t *testing.T) {
+func Test_eventDeliveryRepo_LoadEventDeliveriesIntervalsByDeliveryMode(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
 
@@ -366,26 +656,77 @@ func Test_eventDeliveryRepo_CountEventDeliveries(t *testing.T) {
 	event := seedEvent(t, db, project)
 	sub := seedSubscription(t, db, project, source, endpoint, device)
 
-	ed1 := generateEventDelivery(project, endpoint, event, device, sub)
-	ed2 := generateEventDelivery(project, endpoint, event, device, sub)
-
 	edRepo := NewEventDeliveryRepo(db)
-	err := edRepo.CreateEventDelivery(context.Background(), ed1)
-	require.NoError(t, err)
 
-	err = edRepo.CreateEventDelivery(context.Background(), ed2)
+	fixtures := map[datastore.DeliveryMode]int{
+		datastore.AtLeastOnceDeliveryMode: 4,
+		datastore.AtMostOnceDeliveryMode:  2,
+		datastore.OrderedDeliveryMode:     1,
+	}
+
+	for mode, n := range fixtures {
+		for i := 0; i < n; i++ {
+			ed := generateEventDelivery(project, endpoint, event, device, sub)
+			ed.DeliveryMode = mode
+			require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		}
+	}
+
+	// only at least once deliveries were made yesterday
+	yesterday := time.Now().AddDate(0, 0, -1)
+	ed := generateEventDelivery(project, endpoint, event, device, sub)
+	ed.DeliveryMode = datastore.AtLeastOnceDeliveryMode
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+	_, err := db.GetDB().ExecContext(context.Background(), "UPDATE convoy.event_deliveries SET created_at = $1 WHERE id = $2", yesterday, ed.UID)
 	require.NoError(t, err)
 
-	c, err := edRepo.CountEventDeliveries(context.Background(), project.UID, []string{ed1.EndpointID, ed2.EndpointID}, event.UID, []datastore.EventDeliveryStatus{datastore.SuccessEventStatus}, datastore.SearchParams{
-		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+	intervals, err := edRepo.LoadEventDeliveriesIntervalsByDeliveryMode(context.Background(), project.UID, datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-48 * time.Hour).Unix(),
 		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
-	})
+	}, datastore.Daily)
 	require.NoError(t, err)
+	require.Len(t, intervals, 3)
 
-	require.Equal(t, int64(2), c)
+	// every mode has each bucket once, a mode without deliveries in it counts 0
+	atLeastOnce, atMostOnce := intervals[datastore.AtLeastOnceDeliveryMode], intervals[datastore.AtMostOnceDeliveryMode]
+	require.Len(t, atMostOnce, len(atLeastOnce))
+	for i := range atLeastOnce {
+		require.Equal(t, atLeastOnce[i].Data.Time, atMostOnce[i].Data.Time)
+	}
+
+	yesterdayBuckets := func(modeIntervals []datastore.EventInterval) []datastore.EventInterval {
+		var b []datastore.EventInterval
+		for _, interval := range modeIntervals {
+			if interval.Data.Time == yesterday.Format("2006-01-02") {
+				b = append(b, interval)
+			}
+		}
+		return b
+	}
+	require.Len(t, yesterdayBuckets(atLeastOnce), 1)
+	require.Equal(t, uint64(1), yesterdayBuckets(atLeastOnce)[0].Count)
+	require.Len(t, yesterdayBuckets(atMostOnce), 1)
+	require.Equal(t, uint64(0), yesterdayBuckets(atMostOnce)[0].Count)
+
+	today := time.Now().Format("2006-01-02")
+	for mode, n := range fixtures {
+		modeIntervals := intervals[mode]
+		require.Len(t, modeIntervals, minLen)
+
+		// padding is prepended, so today's bucket is the last one
+		last := modeIntervals[len(modeIntervals)-1]
+		require.Equal(t, today, last.Data.Time)
+		require.Equal(t, uint64(n), last.Count)
+
+		for _, interval := range modeIntervals[:len(modeIntervals)-1] {
+			if interval.Data.Time != yesterday.Format("2006-01-02") {
+				require.Equal(t, uint64(0), interval.Count)
+			}
+		}
+	}
 }
 
-func Test_eventDeliveryRepo_DeleteProjectEventDeliveries(t *testing.T) {
+func Test_eventDeliveryRepo_LoadEventDeliveriesIntervalsWithLatency(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
 
@@ -396,44 +737,48 @@ func Test_eventDeliveryRepo_DeleteProjectEventDeliveries(t *testing.T) {
 	event := seedEvent(t, db, project)
 	sub := seedSubscription(t, db, project, source, endpoint, device)
 
-	// soft delete
-	ed1 := generateEventDelivery(project, endpoint, event, device, sub)
-	ed2 := generateEventDelivery(project, endpoint, event, device, sub)
-
 	edRepo := NewEventDeliveryRepo(db)
-	err := edRepo.CreateEventDelivery(context.Background(), ed1)
-	require.NoError(t, err)
 
-	err = edRepo.CreateEventDelivery(context.Background(), ed2)
-	require.NoError(t, err)
+	for i := 1; i <= 100; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
 
-	err = edRepo.DeleteProjectEventDeliveries(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		ed.Status = datastore.SuccessEventStatus
+		ed.LatencySeconds = float64(i)
+		require.NoError(t, edRepo.UpdateEventDeliveryMetadata(context.Background(), project.UID, ed))
+	}
+
+	params := datastore.SearchParams{
 		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
 		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
-	}, false)
-
-	require.NoError(t, err)
-
-	// hard delete
-
-	ed1 = generateEventDelivery(project, endpoint, event, device, sub)
-	ed2 = generateEventDelivery(project, endpoint, event, device, sub)
+	}
 
-	err = edRepo.CreateEventDelivery(context.Background(), ed1)
+	intervals, err := edRepo.LoadEventDeliveriesIntervals(context.Background(), project.UID, params, datastore.Daily, nil, true, time.Monday)
 	require.NoError(t, err)
+	require.Len(t, intervals, minLen)
+
+	last := intervals[len(intervals)-1]
+	require.Equal(t, uint64(100), last.Count)
+	require.InDelta(t, 50.5, last.P50, 0.001)
+	require.InDelta(t, 95.05, last.P95, 0.001)
+	require.InDelta(t, 99.01, last.P99, 0.001)
+
+	for _, interval := range intervals[:len(intervals)-1] {
+		require.Equal(t, uint64(0), interval.Count)
+		require.Zero(t, interval.P50)
+		require.Zero(t, interval.P95)
+		require.Zero(t, interval.P99)
+	}
 
-	err = edRepo.CreateEventDelivery(context.Background(), ed2)
+	intervals, err = edRepo.LoadEventDeliveriesIntervals(context.Background(), project.UID, params, datastore.Daily, nil, false, time.Monday)
 	require.NoError(t, err)
 
-	err = edRepo.DeleteProjectEventDeliveries(context.Background(), project.UID, &datastore.EventDeliveryFilter{
-		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
-		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
-	}, true)
-
-	require.NoError(t, err)
+	last = intervals[len(intervals)-1]
+	require.Equal(t, uint64(100), last.Count)
+	require.Zero(t, last.P50)
 }
 
-func Test_eventDeliveryRepo_LoadEventDeliveriesPaged(t *testing.T) {
+func Test_eventDeliveryRepo_LoadEventDeliveriesIntervalsWeekStart(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
 
@@ -445,80 +790,2181 @@ func Test_eventDeliveryRepo_LoadEventDeliveriesPaged(t *testing.T) {
 	sub := seedSubscription(t, db, project, source, endpoint, device)
 
 	edRepo := NewEventDeliveryRepo(db)
-	edMap := map[string]*datastore.EventDelivery{}
-	for i := 0; i < 8; i++ {
+
+	// a saturday, a sunday and the monday after
+	saturday := time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)
+	for _, createdAt := range []time.Time{saturday, saturday.AddDate(0, 0, 1), saturday.AddDate(0, 0, 2)} {
 		ed := generateEventDelivery(project, endpoint, event, device, sub)
-		edMap[ed.UID] = ed
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
 
-		err := edRepo.CreateEventDelivery(context.Background(), ed)
+		_, err := db.GetDB().ExecContext(context.Background(), "UPDATE convoy.event_deliveries SET created_at = $1 WHERE id = $2", createdAt, ed.UID)
 		require.NoError(t, err)
 	}
 
-	dbEventDeliveries, _, err := edRepo.LoadEventDeliveriesPaged(
-		context.Background(), project.UID, []string{endpoint.UID}, event.UID, sub.UID,
-		[]datastore.EventDeliveryStatus{datastore.SuccessEventStatus},
-		datastore.SearchParams{
-			CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
-			CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
-		},
-		datastore.Pageable{
-			PerPage: 10,
-		},
-		"", "",
-	)
+	params := datastore.SearchParams{
+		CreatedAtStart: saturday.AddDate(0, 0, -7).Unix(),
+		CreatedAtEnd:   saturday.AddDate(0, 0, 7).Unix(),
+	}
 
-	require.NoError(t, err)
-	require.Equal(t, 8, len(dbEventDeliveries))
+	counts := func(weekStart time.Weekday) map[string]uint64 {
+		intervals, err := edRepo.LoadEventDeliveriesIntervals(context.Background(), project.UID, params, datastore.Weekly, nil, false, weekStart)
+		require.NoError(t, err)
+		require.Len(t, intervals, minLen)
 
-	for i := range dbEventDeliveries {
+		c := map[string]uint64{}
+		for _, interval := range intervals {
+			if interval.Count > 0 {
+				c[interval.Data.Time] = interval.Count
+			}
+		}
+		return c
+	}
 
-		dbEventDelivery := &dbEventDeliveries[i]
-		ed := edMap[dbEventDelivery.UID]
+	require.Equal(t, map[string]uint64{"2024-02-26": 2, "2024-03-04": 1}, counts(time.Monday))
+	require.Equal(t, map[string]uint64{"2024-02-25": 1, "2024-03-03": 2}, counts(time.Sunday))
+	require.Equal(t, map[string]uint64{"2024-03-02": 3}, counts(time.Saturday))
+}
 
-		require.NotEmpty(t, dbEventDelivery.CreatedAt)
-		require.NotEmpty(t, dbEventDelivery.UpdatedAt)
+func Test_eventDeliveryRepo_LatestDeliveryByEndpoint(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
 
-		dbEventDelivery.CreatedAt, dbEventDelivery.UpdatedAt, dbEventDelivery.AcknowledgedAt = time.Time{}, time.Time{}, null.Time{}
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	event := seedEvent(t, db, project)
 
-		require.Equal(t, event.EventType, dbEventDelivery.Event.EventType)
-		require.Equal(t, endpoint.UID, dbEventDelivery.Endpoint.UID)
-		dbEventDelivery.Event, dbEventDelivery.Endpoint, dbEventDelivery.Source, dbEventDelivery.Device = nil, nil, nil, nil
+	edRepo := NewEventDeliveryRepo(db)
 
-		require.Equal(t, ed.Metadata.NextSendTime.UTC(), dbEventDelivery.Metadata.NextSendTime.UTC())
-		ed.Metadata.NextSendTime = time.Time{}
-		dbEventDelivery.Metadata.NextSendTime = time.Time{}
+	busy := seedEndpoint(t, db)
+	quiet := seedEndpoint(t, db)
+	idle := seedEndpoint(t, db)
+
+	latest := map[string]string{}
+	for endpoint, n := range map[*datastore.Endpoint]int{busy: 3, quiet: 1} {
+		sub := seedSubscription(t, db, project, source, endpoint, device)
+		for i := 0; i < n; i++ {
+			ed := generateEventDelivery(project, endpoint, event, device, sub)
+			require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+			latest[endpoint.UID] = ed.UID
+		}
+	}
 
-		ed.CreatedAt, ed.UpdatedAt, ed.AcknowledgedAt = time.Time{}, time.Time{}, null.Time{}
-		ed.Latency = ""
-		dbEventDelivery.Latency = ""
+	deliveries, err := edRepo.LatestDeliveryByEndpoint(context.Background(), project.UID, []string{busy.UID, quiet.UID, idle.UID})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
 
-		ed.DeliveryMode = datastore.AtLeastOnceDeliveryMode
+	for endpointID, deliveryID := range latest {
+		require.Contains(t, deliveries, endpointID)
+		require.Equal(t, deliveryID, deliveries[endpointID].UID)
+		require.Equal(t, endpointID, deliveries[endpointID].EndpointID)
+	}
 
-		require.Equal(t, ed, dbEventDelivery)
+	require.NotContains(t, deliveries, idle.UID)
+}
+
+func Test_eventDeliveryRepo_HasPendingEventDeliveriesBefore(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	otherEndpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+	ctx := context.Background()
+
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	newDelivery := func(endpoint *datastore.Endpoint, createdAt time.Time, status datastore.EventDeliveryStatus) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.DeliveryMode = datastore.OrderedDeliveryMode
+		ed.Status = status
+		ed.CreatedAt = createdAt
+		require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+		return ed
 	}
 
-	evType := "file"
-	event = seedEventWithEventType(t, db, project, evType)
+	first := newDelivery(endpoint, createdAt, datastore.ProcessingEventStatus)
+	second := newDelivery(endpoint, createdAt.Add(time.Second), datastore.ScheduledEventStatus)
+	other := newDelivery(otherEndpoint, createdAt.Add(time.Second), datastore.ScheduledEventStatus)
 
-	ed := generateEventDelivery(project, endpoint, event, device, sub)
+	// the first delivery to the endpoint is in flight
+	pending, err := edRepo.HasPendingEventDeliveriesBefore(ctx, second)
+	require.NoError(t, err)
+	require.True(t, pending)
 
-	err = edRepo.CreateEventDeliveries(context.Background(), []*datastore.EventDelivery{ed})
+	// nothing is ahead of the first one, or of deliveries to another endpoint
+	pending, err = edRepo.HasPendingEventDeliveriesBefore(ctx, first)
 	require.NoError(t, err)
+	require.False(t, pending)
 
-	filteredDeliveries, _, err := edRepo.LoadEventDeliveriesPaged(
-		context.Background(), project.UID, []string{endpoint.UID}, event.UID, sub.UID,
-		[]datastore.EventDeliveryStatus{datastore.SuccessEventStatus},
-		datastore.SearchParams{
-			CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
-			CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
-		},
-		datastore.Pageable{
-			PerPage: 10,
-		},
-		"", evType,
-	)
+	pending, err = edRepo.HasPendingEventDeliveriesBefore(ctx, other)
+	require.NoError(t, err)
+	require.False(t, pending)
 
+	// once it's done, the second one is next
+	require.NoError(t, edRepo.UpdateStatusOfEventDelivery(ctx, project.UID, *first, datastore.SuccessEventStatus))
+
+	pending, err = edRepo.HasPendingEventDeliveriesBefore(ctx, second)
 	require.NoError(t, err)
-	require.Equal(t, 1, len(filteredDeliveries))
-	require.Equal(t, ed.UID, filteredDeliveries[0].UID)
+	require.False(t, pending)
+}
+
+func Test_eventDeliveryRepo_CreateEventDeliveryInheritsSubscriptionDeliveryMode(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+
+	sub := generateSubscription(project, source, endpoint, device)
+	sub.DeliveryMode = datastore.AtMostOnceDeliveryMode
+	require.NoError(t, NewSubscriptionRepo(db).CreateSubscription(context.Background(), project.UID, sub))
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	single := generateEventDelivery(project, endpoint, event, device, sub)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), single))
+
+	bulk := generateEventDelivery(project, endpoint, event, device, sub)
+	bulk.DeduplicationKey = ""
+	_, err := edRepo.CreateEventDeliveries(context.Background(), []*datastore.EventDelivery{bulk})
+	require.NoError(t, err)
+
+	// an explicit mode is kept
+	explicit := generateEventDelivery(project, endpoint, event, device, sub)
+	explicit.DeliveryMode = datastore.AtLeastOnceDeliveryMode
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), explicit))
+
+	for id, want := range map[string]datastore.DeliveryMode{
+		single.UID:   datastore.AtMostOnceDeliveryMode,
+		bulk.UID:     datastore.AtMostOnceDeliveryMode,
+		explicit.UID: datastore.AtLeastOnceDeliveryMode,
+	} {
+		ed, err := edRepo.FindEventDeliveryByIDSlim(context.Background(), project.UID, id)
+		require.NoError(t, err)
+		require.Equal(t, want, ed.DeliveryMode)
+	}
+}
+
+func Test_eventDeliveryRepo_ClaimScheduledDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	scheduled := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = datastore.ScheduledEventStatus
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		scheduled[ed.UID] = true
+	}
+
+	done := generateEventDelivery(project, endpoint, event, device, sub)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), done))
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		errs    []error
+		claimed = map[string]int{}
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			batch, err := edRepo.ClaimScheduledDeliveries(context.Background(), project.UID, 7)
+
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+			for _, ed := range batch {
+				claimed[ed.UID]++
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	// every scheduled delivery is claimed exactly once
+	require.Len(t, claimed, len(scheduled))
+	for id, n := range claimed {
+		require.True(t, scheduled[id])
+		require.Equal(t, 1, n, "delivery %s was claimed more than once", id)
+
+		ed, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, id)
+		require.NoError(t, err)
+		require.Equal(t, datastore.ProcessingEventStatus, ed.Status)
+	}
+
+	ed, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, done.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+
+	batch, err := edRepo.ClaimScheduledDeliveries(context.Background(), project.UID, 7)
+	require.NoError(t, err)
+	require.Empty(t, batch)
+}
+
+func Test_eventDeliveryRepo_FindEventDeliveriesStuckInStatus(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	for i := 0; i < 3; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = datastore.ProcessingEventStatus
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+	}
+
+	done := generateEventDelivery(project, endpoint, event, device, sub)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), done))
+
+	stuck, err := edRepo.FindEventDeliveriesStuckInStatus(context.Background(), project.UID, datastore.ProcessingEventStatus, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, stuck, 3)
+
+	for _, ed := range stuck {
+		require.Equal(t, project.UID, ed.ProjectID)
+		require.Equal(t, endpoint.UID, ed.EndpointID)
+		require.Equal(t, uint64(1), ed.Metadata.NumTrials)
+	}
+
+	stuck, err = edRepo.FindEventDeliveriesStuckInStatus(context.Background(), project.UID, datastore.ProcessingEventStatus, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, stuck, 2)
+
+	stuck, err = edRepo.FindEventDeliveriesStuckInStatus(context.Background(), project.UID, datastore.ProcessingEventStatus, time.Hour, 10)
+	require.NoError(t, err)
+	require.Empty(t, stuck)
+}
+
+func Test_eventDeliveryRepo_FindOrphanedEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	ctx := context.Background()
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	event := seedEvent(t, db, project)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	newDelivery := func(endpoint *datastore.Endpoint, sub *datastore.Subscription) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+		return ed
+	}
+
+	endpoint := seedEndpoint(t, db)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+	healthy := newDelivery(endpoint, sub)
+
+	deletedEndpoint := seedEndpoint(t, db)
+	deletedEndpointSub := seedSubscription(t, db, project, source, deletedEndpoint, device)
+	endpointOrphan := newDelivery(deletedEndpoint, deletedEndpointSub)
+	require.NoError(t, NewEndpointRepo(db).DeleteEndpoint(ctx, deletedEndpoint, deletedEndpoint.ProjectID))
+
+	deletedSub := seedSubscription(t, db, project, source, endpoint, device)
+	subOrphan := newDelivery(endpoint, deletedSub)
+	require.NoError(t, NewSubscriptionRepo(db).DeleteSubscription(ctx, project.UID, deletedSub))
+
+	orphans, err := edRepo.FindOrphanedEventDeliveries(ctx, project.UID, "", 10)
+	require.NoError(t, err)
+	require.Len(t, orphans, 2)
+
+	ids := []string{orphans[0].UID, orphans[1].UID}
+	require.ElementsMatch(t, []string{endpointOrphan.UID, subOrphan.UID}, ids)
+	require.NotContains(t, ids, healthy.UID)
+
+	// orphans are paged through in id order
+	first, err := edRepo.FindOrphanedEventDeliveries(ctx, project.UID, "", 1)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	require.Equal(t, orphans[0].UID, first[0].UID)
+
+	rest, err := edRepo.FindOrphanedEventDeliveries(ctx, project.UID, first[0].UID, 10)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	require.Equal(t, orphans[1].UID, rest[0].UID)
+}
+
+func Test_eventDeliveryRepo_FindUnacknowledgedEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	create := func(status datastore.EventDeliveryStatus, acked bool, deliveredAgo time.Duration) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = status
+		// like fan-out does, this isn't the device's acknowledgement
+		ed.AcknowledgedAt = null.TimeFrom(time.Now())
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		_, err := db.GetDB().ExecContext(context.Background(),
+			`UPDATE convoy.event_deliveries SET updated_at = now() - make_interval(secs => $2) WHERE id = $1`,
+			ed.UID, deliveredAgo.Seconds())
+		require.NoError(t, err)
+
+		if acked {
+			_, err = db.GetDB().ExecContext(context.Background(),
+				`UPDATE convoy.event_deliveries SET device_acknowledged_at = now() WHERE id = $1`, ed.UID)
+			require.NoError(t, err)
+		}
+
+		return ed
+	}
+
+	lagging := create(datastore.SuccessEventStatus, false, 2*time.Hour)
+	laggingLess := create(datastore.SuccessEventStatus, false, 90*time.Minute)
+	create(datastore.SuccessEventStatus, false, time.Minute)
+	create(datastore.SuccessEventStatus, true, 2*time.Hour)
+	create(datastore.FailureEventStatus, false, 2*time.Hour)
+
+	// deliveries to endpoints aren't acknowledged by anyone
+	toEndpoint := create(datastore.SuccessEventStatus, false, 2*time.Hour)
+	_, err := db.GetDB().ExecContext(context.Background(), `UPDATE convoy.event_deliveries SET device_id = NULL WHERE id = $1`, toEndpoint.UID)
+	require.NoError(t, err)
+
+	unacked, err := edRepo.FindUnacknowledgedEventDeliveries(context.Background(), project.UID, time.Hour, 10)
+	require.NoError(t, err)
+	require.Len(t, unacked, 2)
+
+	require.Equal(t, lagging.UID, unacked[0].UID)
+	require.Equal(t, laggingLess.UID, unacked[1].UID)
+	for _, ed := range unacked {
+		require.Equal(t, project.UID, ed.ProjectID)
+		require.Equal(t, sub.UID, ed.SubscriptionID)
+		require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+	}
+
+	unacked, err = edRepo.FindUnacknowledgedEventDeliveries(context.Background(), "", time.Hour, 1)
+	require.NoError(t, err)
+	require.Len(t, unacked, 1)
+	require.Equal(t, lagging.UID, unacked[0].UID)
+
+	unacked, err = edRepo.FindUnacknowledgedEventDeliveries(context.Background(), project.UID, 3*time.Hour, 10)
+	require.NoError(t, err)
+	require.Empty(t, unacked)
+}
+
+func Test_eventDeliveryRepo_AcknowledgeEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	acked := generateEventDelivery(project, endpoint, event, device, sub)
+	ackedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), acked))
+
+	_, err := db.GetDB().ExecContext(context.Background(),
+		`UPDATE convoy.event_deliveries SET device_acknowledged_at = $2 WHERE id = $1`, acked.UID, ackedAt)
+	require.NoError(t, err)
+
+	ids := []string{acked.UID}
+	for i := 0; i < 3; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		// set at fan-out, it doesn't stop the device acknowledging it
+		ed.AcknowledgedAt = null.TimeFrom(time.Now())
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		ids = append(ids, ed.UID)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	n, err := edRepo.AcknowledgeEventDeliveries(context.Background(), project.UID, append(ids, "missing"), now)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+
+	for _, id := range ids {
+		ed, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, id)
+		require.NoError(t, err)
+		require.True(t, ed.DeviceAcknowledgedAt.Valid)
+
+		want := now
+		if id == acked.UID {
+			want = ackedAt
+		}
+		require.True(t, want.Equal(ed.DeviceAcknowledgedAt.Time))
+	}
+
+	// acknowledging them again doesn't count them twice
+	n, err = edRepo.AcknowledgeEventDeliveries(context.Background(), project.UID, ids, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = edRepo.AcknowledgeEventDeliveries(context.Background(), "other-project", ids, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func Test_eventDeliveryRepo_FindEventDeliveriesAfterID(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = datastore.FailureEventStatus
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		ids = append(ids, ed.UID)
+	}
+
+	succeeded := generateEventDelivery(project, endpoint, event, device, sub)
+	succeeded.Status = datastore.SuccessEventStatus
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), succeeded))
+
+	sort.Strings(ids)
+
+	filter := &datastore.EventDeliveryFilter{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+		Status:         []datastore.EventDeliveryStatus{datastore.FailureEventStatus},
+	}
+
+	var got []string
+	afterID := ""
+	for {
+		page, err := edRepo.FindEventDeliveriesAfterID(context.Background(), project.UID, filter, afterID, 2)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+
+		require.LessOrEqual(t, len(page), 2)
+		for _, ed := range page {
+			got = append(got, ed.UID)
+		}
+		afterID = page[len(page)-1].UID
+	}
+
+	require.Equal(t, ids, got)
+
+	_, err := edRepo.FindEventDeliveriesAfterID(context.Background(), project.UID, filter, "", 0)
+	require.Error(t, err)
+}
+
+func Test_eventDeliveryRepo_GetEventDeliveryLatencyHistogram(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	otherEndpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	create := func(endpoint *datastore.Endpoint, latency *float64) {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		_, err := db.GetDB().ExecContext(context.Background(),
+			`UPDATE convoy.event_deliveries SET latency_seconds = $2 WHERE id = $1`, ed.UID, latency)
+		require.NoError(t, err)
+	}
+
+	for _, latency := range []float64{0.05, 0.2, 0.3, 0.5, 1.5, 2, 30} {
+		create(endpoint, &latency)
+	}
+	// deliveries without a latency and other endpoints' aren't counted
+	create(endpoint, nil)
+	create(otherEndpoint, &[]float64{0.2}[0])
+
+	params := datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}
+
+	histogram, err := edRepo.GetEventDeliveryLatencyHistogram(context.Background(), project.UID, endpoint.UID, params, []float64{0.1, 0.5, 2})
+	require.NoError(t, err)
+	require.Equal(t, []datastore.LatencyBucket{
+		{Upper: null.FloatFrom(0.1), Count: 1},
+		{Lower: null.FloatFrom(0.1), Upper: null.FloatFrom(0.5), Count: 2},
+		{Lower: null.FloatFrom(0.5), Upper: null.FloatFrom(2), Count: 2},
+		{Lower: null.FloatFrom(2), Count: 2},
+	}, histogram)
+
+	// outside the window every bucket is empty
+	params.CreatedAtEnd = time.Now().Add(-time.Minute).Unix()
+	histogram, err = edRepo.GetEventDeliveryLatencyHistogram(context.Background(), project.UID, endpoint.UID, params, []float64{0.1, 0.5, 2})
+	require.NoError(t, err)
+	require.Len(t, histogram, 4)
+	for _, b := range histogram {
+		require.Zero(t, b.Count)
+	}
+
+	_, err = edRepo.GetEventDeliveryLatencyHistogram(context.Background(), project.UID, endpoint.UID, params, nil)
+	require.Error(t, err)
+
+	_, err = edRepo.GetEventDeliveryLatencyHistogram(context.Background(), project.UID, endpoint.UID, params, []float64{1, 0.5})
+	require.Error(t, err)
+}
+
+func Test_eventDeliveryRepo_LoadEndpointDeliveryStats(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	otherEndpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	create := func(endpoint *datastore.Endpoint, status datastore.EventDeliveryStatus, latency float64) {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = status
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		_, err := db.GetDB().ExecContext(context.Background(),
+			`UPDATE convoy.event_deliveries SET latency_seconds = $2 WHERE id = $1`, ed.UID, latency)
+		require.NoError(t, err)
+	}
+
+	for _, latency := range []float64{1, 1, 1} {
+		create(endpoint, datastore.SuccessEventStatus, latency)
+	}
+	create(endpoint, datastore.FailureEventStatus, 30)
+	// deliveries still being retried aren't counted
+	create(endpoint, datastore.RetryEventStatus, 1)
+
+	create(otherEndpoint, datastore.SuccessEventStatus, 2)
+	create(otherEndpoint, datastore.DiscardedEventStatus, 0)
+
+	params := datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}
+
+	stats, err := edRepo.LoadEndpointDeliveryStats(context.Background(), project.UID, params)
+	require.NoError(t, err)
+
+	want := []datastore.EndpointDeliveryStats{
+		{EndpointID: endpoint.UID, Total: 4, Successful: 3, P95LatencySeconds: 1},
+		{EndpointID: otherEndpoint.UID, Total: 2, Successful: 1, P95LatencySeconds: 2},
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i].EndpointID < want[j].EndpointID })
+	require.Equal(t, want, stats)
+
+	// outside the window there are no stats
+	params.CreatedAtEnd = time.Now().Add(-time.Minute).Unix()
+	stats, err = edRepo.LoadEndpointDeliveryStats(context.Background(), project.UID, params)
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
+
+func Test_eventDeliveryRepo_SwapEntitySnapshot(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+	ctx := context.Background()
+
+	// the entity's first event has nothing to diff against
+	now := time.Now()
+	previous, err := edRepo.SwapEntitySnapshot(ctx, project.UID, sub.UID, "cus_1", "event-1", now, []byte(`{"v":1}`))
+	require.NoError(t, err)
+	require.Nil(t, previous)
+
+	previous, err = edRepo.SwapEntitySnapshot(ctx, project.UID, sub.UID, "cus_1", "event-2", now.Add(time.Second), []byte(`{"v":2}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"v":1}`, string(previous))
+
+	// writing the same event again diffs against the same document
+	previous, err = edRepo.SwapEntitySnapshot(ctx, project.UID, sub.UID, "cus_1", "event-2", now.Add(time.Second), []byte(`{"v":2}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"v":1}`, string(previous))
+
+	// an event older than the snapshot's doesn't replace it
+	_, err = edRepo.SwapEntitySnapshot(ctx, project.UID, sub.UID, "cus_1", "event-0", now.Add(-time.Second), []byte(`{"v":0}`))
+	require.ErrorIs(t, err, datastore.ErrStaleEntitySnapshot)
+
+	previous, err = edRepo.SwapEntitySnapshot(ctx, project.UID, sub.UID, "cus_1", "event-4", now.Add(2*time.Second), []byte(`{"v":4}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"v":2}`, string(previous))
+
+	// other entities are kept apart
+	previous, err = edRepo.SwapEntitySnapshot(ctx, project.UID, sub.UID, "cus_2", "event-3", now, []byte(`{"v":3}`))
+	require.NoError(t, err)
+	require.Nil(t, previous)
+}
+
+func Test_eventDeliveryRepo_LoadDeliveryCountsByHour(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	create := func(createdAt time.Time) {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		_, err := db.GetDB().ExecContext(context.Background(),
+			`UPDATE convoy.event_deliveries SET created_at = $2 WHERE id = $1`, ed.UID, createdAt)
+		require.NoError(t, err)
+	}
+
+	// the same hour on different days lands in the same bucket
+	day := time.Now().UTC().Truncate(24 * time.Hour).Add(-72 * time.Hour)
+	fixture := map[int]int{0: 2, 9: 3, 13: 1, 23: 4}
+	for hour, count := range fixture {
+		for i := 0; i < count; i++ {
+			create(day.Add(time.Duration(i%2)*24*time.Hour + time.Duration(hour)*time.Hour + 30*time.Minute))
+		}
+	}
+
+	// deliveries outside the window aren't counted
+	create(day.Add(-48*time.Hour + 9*time.Hour))
+
+	params := datastore.SearchParams{
+		CreatedAtStart: day.Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}
+
+	counts, err := edRepo.LoadDeliveryCountsByHour(context.Background(), project.UID, params)
+	require.NoError(t, err)
+	require.Len(t, counts, 24)
+
+	for hour, hc := range counts {
+		require.Equal(t, hour, hc.Hour)
+		require.Equal(t, uint64(fixture[hour]), hc.Count, "hour %d", hour)
+	}
+}
+
+func Test_eventDeliveryRepo_FindEventDeliveriesByEndpointAndStatus(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	otherEndpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	create := func(endpoint *datastore.Endpoint, status datastore.EventDeliveryStatus, createdAgo time.Duration) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = status
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		_, err := db.GetDB().ExecContext(context.Background(),
+			`UPDATE convoy.event_deliveries SET created_at = now() - make_interval(secs => $2) WHERE id = $1`,
+			ed.UID, createdAgo.Seconds())
+		require.NoError(t, err)
+
+		return ed
+	}
+
+	var failed []*datastore.EventDelivery
+	for i := 0; i < 5; i++ {
+		failed = append(failed, create(endpoint, datastore.FailureEventStatus, time.Minute))
+	}
+	create(endpoint, datastore.SuccessEventStatus, time.Minute)
+	create(otherEndpoint, datastore.FailureEventStatus, time.Minute)
+	create(endpoint, datastore.FailureEventStatus, 48*time.Hour)
+
+	params := datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}
+
+	var found []string
+	cursor := ""
+	pages := 0
+	for {
+		deliveries, next, err := edRepo.FindEventDeliveriesByEndpointAndStatus(context.Background(), project.UID, endpoint.UID, datastore.FailureEventStatus, params, cursor, 2)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(deliveries), 2)
+		pages++
+
+		for _, ed := range deliveries {
+			require.Equal(t, endpoint.UID, ed.EndpointID)
+			require.Equal(t, datastore.FailureEventStatus, ed.Status)
+			found = append(found, ed.UID)
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	// ulids are ordered by creation, so newest first is the reverse order
+	want := make([]string, 0, len(failed))
+	for i := len(failed) - 1; i >= 0; i-- {
+		want = append(want, failed[i].UID)
+	}
+	require.Equal(t, want, found)
+	require.Equal(t, 3, pages)
+
+	deliveries, next, err := edRepo.FindEventDeliveriesByEndpointAndStatus(context.Background(), project.UID, endpoint.UID, datastore.FailureEventStatus, params, "", 5)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 5)
+	require.Empty(t, next)
+
+	_, _, err = edRepo.FindEventDeliveriesByEndpointAndStatus(context.Background(), project.UID, endpoint.UID, datastore.FailureEventStatus, params, "", 0)
+	require.Error(t, err)
+}
+
+func Test_eventDeliveryRepo_FindEventDeliveriesByEndpointAndStatus_UsesIndex(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+	for i := 0; i < 20; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+	}
+
+	ctx := context.Background()
+	tx, err := db.GetDB().BeginTxx(ctx, nil)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, tx.Rollback()) }()
+
+	_, err = tx.ExecContext(ctx, `ANALYZE convoy.event_deliveries`)
+	require.NoError(t, err)
+
+	// the table is far too small for an index to beat a sequential scan, so
+	// the planner has to be told not to consider one
+	_, err = tx.ExecContext(ctx, `SET LOCAL enable_seqscan = off`)
+	require.NoError(t, err)
+
+	rows, err := tx.QueryxContext(ctx, "EXPLAIN "+fetchEventDeliveriesByEndpointAndStatus,
+		project.UID, endpoint.UID, datastore.SuccessEventStatus,
+		time.Now().Add(-time.Hour), time.Now().Add(time.Hour), defaultDescCursor, 10)
+	require.NoError(t, err)
+	defer closeWithError(rows)
+
+	var plan []string
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan = append(plan, line)
+	}
+	require.NoError(t, rows.Err())
+
+	require.Contains(t, strings.Join(plan, "\n"), "idx_event_deliveries_project_id_endpoint_id_status")
+}
+
+func Test_eventDeliveryRepo_FindEventDeliveriesByIdempotencyKey(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	event := seedEvent(t, db, project)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	var want []string
+	for i := 0; i < 3; i++ {
+		endpoint := seedEndpoint(t, db)
+		sub := seedSubscription(t, db, project, source, endpoint, device)
+
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.IdempotencyKey = "key-1"
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		want = append(want, ed.UID)
+	}
+
+	endpoint := seedEndpoint(t, db)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	other := generateEventDelivery(project, endpoint, event, device, sub)
+	other.IdempotencyKey = "key-2"
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), other))
+
+	deliveries, err := edRepo.FindEventDeliveriesByIdempotencyKey(context.Background(), project.UID, "key-1")
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+
+	for i, ed := range deliveries {
+		require.Equal(t, want[i], ed.UID)
+		require.Equal(t, "key-1", ed.IdempotencyKey)
+		require.Equal(t, ed.EndpointID, ed.Endpoint.UID)
+		require.NotEmpty(t, ed.Endpoint.Url)
+		require.Equal(t, event.UID, ed.Event.UID)
+	}
+
+	deliveries, err = edRepo.FindEventDeliveriesByIdempotencyKey(context.Background(), seedProject(t, db).UID, "key-1")
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
+
+func Test_eventDeliveryRepo_UpdateStatusOfEventDelivery(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ed := generateEventDelivery(project, endpoint, event, device, sub)
+
+	edRepo := NewEventDeliveryRepo(db)
+	err := edRepo.CreateEventDelivery(context.Background(), ed)
+	require.NoError(t, err)
+
+	err = edRepo.UpdateStatusOfEventDelivery(context.Background(), project.UID, *ed, datastore.RetryEventStatus)
+	require.NoError(t, err)
+
+	dbEventDelivery, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, ed.UID)
+	require.NoError(t, err)
+
+	require.Equal(t, datastore.RetryEventStatus, dbEventDelivery.Status)
+}
+
+func Test_eventDeliveryRepo_UpdateStatusOfEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ed1 := generateEventDelivery(project, endpoint, event, device, sub)
+	ed2 := generateEventDelivery(project, endpoint, event, device, sub)
+
+	edRepo := NewEventDeliveryRepo(db)
+	err := edRepo.CreateEventDelivery(context.Background(), ed1)
+	require.NoError(t, err)
+
+	err = edRepo.CreateEventDelivery(context.Background(), ed2)
+	require.NoError(t, err)
+
+	err = edRepo.UpdateStatusOfEventDeliveries(context.Background(), project.UID, []string{ed1.UID, ed2.UID}, datastore.RetryEventStatus)
+	require.NoError(t, err)
+
+	dbEventDeliveries, err := edRepo.FindEventDeliveriesByIDs(context.Background(), project.UID, []string{ed1.UID, ed2.UID})
+	require.NoError(t, err)
+
+	for _, d := range dbEventDeliveries {
+		require.Equal(t, datastore.RetryEventStatus, d.Status)
+	}
+}
+
+func Test_eventDeliveryRepo_RequeueRecentEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	recent := generateEventDelivery(project, endpoint, event, device, sub)
+	recent.Status = datastore.FailureEventStatus
+	stale := generateEventDelivery(project, endpoint, event, device, sub)
+	stale.Status = datastore.FailureEventStatus
+
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), recent))
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), stale))
+
+	_, err := db.GetDB().ExecContext(context.Background(), `UPDATE convoy.event_deliveries SET created_at = $1 WHERE id = $2`, time.Now().Add(-30*24*time.Hour), stale.UID)
+	require.NoError(t, err)
+
+	skipped, err := edRepo.RequeueRecentEventDeliveries(context.Background(), project.UID, []string{recent.UID, stale.UID}, 72*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, []string{stale.UID}, skipped)
+
+	dbRecent, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, recent.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.ScheduledEventStatus, dbRecent.Status)
+
+	dbStale, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, stale.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.FailureEventStatus, dbStale.Status)
+
+	// nothing is skipped once the window covers every delivery
+	skipped, err = edRepo.RequeueRecentEventDeliveries(context.Background(), project.UID, []string{recent.UID, stale.UID}, 60*24*time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+
+	dbStale, err = edRepo.FindEventDeliveryByID(context.Background(), project.UID, stale.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.ScheduledEventStatus, dbStale.Status)
+}
+
+func Test_eventDeliveryRepo_FindDiscardedEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	status := datastore.DiscardedEventStatus
+	for i := 0; i < 8; i++ {
+
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		if i == 1 || i == 4 || i == 5 {
+			ed.Status = status
+		}
+
+		err := edRepo.CreateEventDelivery(context.Background(), ed)
+		require.NoError(t, err)
+	}
+
+	dbEventDeliveries, err := edRepo.FindDiscardedEventDeliveries(context.Background(), project.UID, device.UID, datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	for _, d := range dbEventDeliveries {
+		require.Equal(t, datastore.DiscardedEventStatus, d.Status)
+	}
+}
+
+func Test_eventDeliveryRepo_UpdateEventDeliveryWithAttempt(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ed := generateEventDelivery(project, endpoint, event, device, sub)
+
+	edRepo := NewEventDeliveryRepo(db)
+	err := edRepo.CreateEventDelivery(context.Background(), ed)
+	require.NoError(t, err)
+
+	latency := "1h2m"
+	latencySeconds := 3720.0
+
+	ed.Latency = latency
+	ed.LatencySeconds = latencySeconds
+
+	err = edRepo.UpdateEventDeliveryMetadata(context.Background(), project.UID, ed)
+	require.NoError(t, err)
+
+	dbEventDelivery, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, ed.UID)
+	require.NoError(t, err)
+
+	require.Equal(t, "", dbEventDelivery.Latency)
+	require.Equal(t, latencySeconds, dbEventDelivery.LatencySeconds)
+}
+
+func Test_eventDeliveryRepo_CountEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ed1 := generateEventDelivery(project, endpoint, event, device, sub)
+	ed2 := generateEventDelivery(project, endpoint, event, device, sub)
+
+	edRepo := NewEventDeliveryRepo(db)
+	err := edRepo.CreateEventDelivery(context.Background(), ed1)
+	require.NoError(t, err)
+
+	err = edRepo.CreateEventDelivery(context.Background(), ed2)
+	require.NoError(t, err)
+
+	c, err := edRepo.CountEventDeliveries(context.Background(), project.UID, []string{ed1.EndpointID, ed2.EndpointID}, nil, event.UID, []datastore.EventDeliveryStatus{datastore.SuccessEventStatus}, datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(2), c)
+}
+
+func Test_eventDeliveryRepo_CountEventDeliveriesBySource(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	sourced := generateEvent(t, db)
+	sourced.ProjectID = project.UID
+	sourced.SourceID = source.UID
+	require.NoError(t, NewEventRepo(db).CreateEvent(context.Background(), sourced))
+
+	unsourced := seedEvent(t, db, project)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), generateEventDelivery(project, endpoint, sourced, device, sub)))
+	}
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), generateEventDelivery(project, endpoint, unsourced, device, sub)))
+
+	params := datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}
+
+	c, err := edRepo.CountEventDeliveries(context.Background(), project.UID, nil, []string{source.UID}, "", nil, params)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), c)
+
+	c, err = edRepo.CountEventDeliveries(context.Background(), project.UID, []string{endpoint.UID}, []string{source.UID}, "", []datastore.EventDeliveryStatus{datastore.SuccessEventStatus}, params)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), c)
+
+	c, err = edRepo.CountEventDeliveries(context.Background(), project.UID, nil, []string{"unknown-source"}, "", nil, params)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), c)
+
+	c, err = edRepo.CountEventDeliveries(context.Background(), project.UID, nil, nil, "", nil, params)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), c)
+}
+
+func Test_eventDeliveryRepo_DeleteProjectEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	// soft delete
+	ed1 := generateEventDelivery(project, endpoint, event, device, sub)
+	ed2 := generateEventDelivery(project, endpoint, event, device, sub)
+
+	edRepo := NewEventDeliveryRepo(db)
+	err := edRepo.CreateEventDelivery(context.Background(), ed1)
+	require.NoError(t, err)
+
+	err = edRepo.CreateEventDelivery(context.Background(), ed2)
+	require.NoError(t, err)
+
+	err = edRepo.DeleteProjectEventDeliveries(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}, false)
+
+	require.NoError(t, err)
+
+	// hard delete
+
+	ed1 = generateEventDelivery(project, endpoint, event, device, sub)
+	ed2 = generateEventDelivery(project, endpoint, event, device, sub)
+
+	err = edRepo.CreateEventDelivery(context.Background(), ed1)
+	require.NoError(t, err)
+
+	err = edRepo.CreateEventDelivery(context.Background(), ed2)
+	require.NoError(t, err)
+
+	err = edRepo.DeleteProjectEventDeliveries(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	}, true)
+
+	require.NoError(t, err)
+}
+
+func Test_eventDeliveryRepo_DeleteProjectEventDeliveries_ByEventType(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	auditEvent := seedEventWithEventType(t, db, project, "audit.log")
+	heartbeatEvent := seedEventWithEventType(t, db, project, "heartbeat")
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	audit := generateEventDelivery(project, endpoint, auditEvent, device, sub)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), audit))
+
+	heartbeat := generateEventDelivery(project, endpoint, heartbeatEvent, device, sub)
+	require.NoError(t, edRepo.CreateEventDelivery(context.Background(), heartbeat))
+
+	// both deliveries are the same age, only the heartbeat falls under the default policy
+	err := edRepo.DeleteProjectEventDeliveries(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		CreatedAtStart:    time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:      time.Now().Add(time.Hour).Unix(),
+		ExcludeEventTypes: []string{"audit.log"},
+	}, true)
+	require.NoError(t, err)
+
+	_, err = edRepo.FindEventDeliveryByID(context.Background(), project.UID, heartbeat.UID)
+	require.ErrorIs(t, err, datastore.ErrEventDeliveryNotFound)
+
+	dbAudit, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, audit.UID)
+	require.NoError(t, err)
+	require.Equal(t, audit.UID, dbAudit.UID)
+
+	// the audit override's cutoff hasn't been reached
+	err = edRepo.DeleteProjectEventDeliveries(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(-30 * time.Minute).Unix(),
+		EventTypes:     []string{"audit.log"},
+	}, true)
+	require.ErrorIs(t, err, ErrEventDeliveriesNotDeleted)
+
+	_, err = edRepo.FindEventDeliveryByID(context.Background(), project.UID, audit.UID)
+	require.NoError(t, err)
+}
+
+func Test_eventDeliveryRepo_StripProjectEventDeliveryPayloads(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	create := func(status datastore.EventDeliveryStatus, age time.Duration) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = status
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		_, err := db.GetDB().ExecContext(context.Background(),
+			`UPDATE convoy.event_deliveries SET created_at = now() - make_interval(secs => $2) WHERE id = $1`,
+			ed.UID, age.Seconds())
+		require.NoError(t, err)
+
+		return ed
+	}
+
+	recent := create(datastore.SuccessEventStatus, time.Hour)
+	old := create(datastore.SuccessEventStatus, 48*time.Hour)
+	oldRetrying := create(datastore.RetryEventStatus, 48*time.Hour)
+	// failed and discarded deliveries can still be retried
+	oldFailed := create(datastore.FailureEventStatus, 48*time.Hour)
+	oldDiscarded := create(datastore.DiscardedEventStatus, 48*time.Hour)
+	expired := create(datastore.SuccessEventStatus, 800*time.Hour)
+
+	// payloads are kept for a day, deliveries for 30 days
+	payloadCutoff := &datastore.EventDeliveryFilter{CreatedAtEnd: time.Now().Add(-24 * time.Hour).Unix()}
+
+	stripped, err := edRepo.StripProjectEventDeliveryPayloads(context.Background(), project.UID, payloadCutoff)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stripped)
+
+	// stripped deliveries aren't rewritten
+	stripped, err = edRepo.StripProjectEventDeliveryPayloads(context.Background(), project.UID, payloadCutoff)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), stripped)
+
+	err = edRepo.DeleteProjectEventDeliveries(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		CreatedAtEnd: time.Now().Add(-720 * time.Hour).Unix(),
+	}, true)
+	require.NoError(t, err)
+
+	_, err = edRepo.FindEventDeliveryByID(context.Background(), project.UID, expired.UID)
+	require.ErrorIs(t, err, datastore.ErrEventDeliveryNotFound)
+
+	dbOld, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, old.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.SuccessEventStatus, dbOld.Status)
+	require.Equal(t, "", dbOld.Metadata.Raw)
+	require.Equal(t, "null", string(dbOld.Metadata.Data))
+	require.Equal(t, old.Metadata.NumTrials, dbOld.Metadata.NumTrials)
+
+	for _, ed := range []*datastore.EventDelivery{recent, oldRetrying, oldFailed, oldDiscarded} {
+		dbEd, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, ed.UID)
+		require.NoError(t, err)
+		require.Equal(t, ed.Metadata.Raw, dbEd.Metadata.Raw)
+		require.JSONEq(t, string(ed.Metadata.Data), string(dbEd.Metadata.Data))
+	}
+}
+
+func Test_eventDeliveryRepo_RequeueEventDeliveriesByFilter(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	otherEndpoint := seedEndpoint(t, db)
+	invoiceEvent := seedEventWithEventType(t, db, project, "invoice.paid")
+	heartbeatEvent := seedEventWithEventType(t, db, project, "heartbeat")
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	seed := func(ep *datastore.Endpoint, event *datastore.Event, status datastore.EventDeliveryStatus) string {
+		ed := generateEventDelivery(project, ep, event, device, sub)
+		ed.Status = status
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		return ed.UID
+	}
+
+	failed := seed(endpoint, invoiceEvent, datastore.FailureEventStatus)
+	discarded := seed(endpoint, invoiceEvent, datastore.DiscardedEventStatus)
+	processing := seed(endpoint, invoiceEvent, datastore.ProcessingEventStatus)
+	heartbeat := seed(endpoint, heartbeatEvent, datastore.FailureEventStatus)
+	otherEndpointFailed := seed(otherEndpoint, invoiceEvent, datastore.FailureEventStatus)
+
+	filter := &datastore.EventDeliveryFilter{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+		EndpointID:     endpoint.UID,
+		Status:         []datastore.EventDeliveryStatus{datastore.FailureEventStatus, datastore.ProcessingEventStatus},
+		EventTypes:     []string{"invoice.paid"},
+	}
+
+	count, err := edRepo.RequeueEventDeliveriesByFilter(context.Background(), project.UID, filter)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	wantStatus := map[string]datastore.EventDeliveryStatus{
+		failed:              datastore.ScheduledEventStatus,
+		discarded:           datastore.DiscardedEventStatus,
+		processing:          datastore.ProcessingEventStatus,
+		heartbeat:           datastore.FailureEventStatus,
+		otherEndpointFailed: datastore.FailureEventStatus,
+	}
+	for id, status := range wantStatus {
+		ed, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, id)
+		require.NoError(t, err)
+		require.Equal(t, status, ed.Status, id)
+	}
+
+	// without the narrowing filters every delivery in the window except the processing one is requeued
+	count, err = edRepo.RequeueEventDeliveriesByFilter(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), count)
+
+	ed, err := edRepo.FindEventDeliveryByID(context.Background(), project.UID, processing)
+	require.NoError(t, err)
+	require.Equal(t, datastore.ProcessingEventStatus, ed.Status)
+
+	// nothing falls in a window in the past
+	count, err = edRepo.RequeueEventDeliveriesByFilter(context.Background(), project.UID, &datastore.EventDeliveryFilter{
+		CreatedAtStart: time.Now().Add(-2 * time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(-time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func Test_eventDeliveryRepo_LoadEventDeliveriesPaged(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+	edMap := map[string]*datastore.EventDelivery{}
+	for i := 0; i < 8; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		edMap[ed.UID] = ed
+
+		err := edRepo.CreateEventDelivery(context.Background(), ed)
+		require.NoError(t, err)
+	}
+
+	dbEventDeliveries, _, err := edRepo.LoadEventDeliveriesPaged(
+		context.Background(), project.UID, []string{endpoint.UID}, event.UID, sub.UID,
+		[]datastore.EventDeliveryStatus{datastore.SuccessEventStatus},
+		datastore.SearchParams{
+			CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+			CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+		},
+		datastore.Pageable{
+			PerPage: 10,
+		},
+		"", "", nil,
+		datastore.PreferReplica,
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, 8, len(dbEventDeliveries))
+
+	for i := range dbEventDeliveries {
+
+		dbEventDelivery := &dbEventDeliveries[i]
+		ed := edMap[dbEventDelivery.UID]
+
+		require.NotEmpty(t, dbEventDelivery.CreatedAt)
+		require.NotEmpty(t, dbEventDelivery.UpdatedAt)
+
+		dbEventDelivery.CreatedAt, dbEventDelivery.UpdatedAt, dbEventDelivery.AcknowledgedAt = time.Time{}, time.Time{}, null.Time{}
+
+		require.Equal(t, event.EventType, dbEventDelivery.Event.EventType)
+		require.Equal(t, endpoint.UID, dbEventDelivery.Endpoint.UID)
+		dbEventDelivery.Event, dbEventDelivery.Endpoint, dbEventDelivery.Source, dbEventDelivery.Device = nil, nil, nil, nil
+
+		require.Equal(t, ed.Metadata.NextSendTime.UTC(), dbEventDelivery.Metadata.NextSendTime.UTC())
+		ed.Metadata.NextSendTime = time.Time{}
+		dbEventDelivery.Metadata.NextSendTime = time.Time{}
+
+		ed.CreatedAt, ed.UpdatedAt, ed.AcknowledgedAt = time.Time{}, time.Time{}, null.Time{}
+		ed.Latency = ""
+		dbEventDelivery.Latency = ""
+
+		ed.DeliveryMode = datastore.AtLeastOnceDeliveryMode
+
+		require.Equal(t, ed, dbEventDelivery)
+	}
+
+	evType := "file"
+	event = seedEventWithEventType(t, db, project, evType)
+
+	ed := generateEventDelivery(project, endpoint, event, device, sub)
+
+	_, err = edRepo.CreateEventDeliveries(context.Background(), []*datastore.EventDelivery{ed})
+	require.NoError(t, err)
+
+	filteredDeliveries, _, err := edRepo.LoadEventDeliveriesPaged(
+		context.Background(), project.UID, []string{endpoint.UID}, event.UID, sub.UID,
+		[]datastore.EventDeliveryStatus{datastore.SuccessEventStatus},
+		datastore.SearchParams{
+			CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+			CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+		},
+		datastore.Pageable{
+			PerPage: 10,
+		},
+		"", evType, nil,
+		datastore.PreferReplica,
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, len(filteredDeliveries))
+	require.Equal(t, ed.UID, filteredDeliveries[0].UID)
+}
+
+func Test_eventDeliveryRepo_LoadEventDeliveriesPagedByResponseStatusCode(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+	attemptsRepo := NewDeliveryAttemptRepo(db)
+
+	// the status lines of each delivery's attempts, oldest first
+	fixtures := map[string][]string{
+		"rate_limited":    {"500 Internal Server Error", "429 Too Many Requests"},
+		"server_error":    {"429 Too Many Requests", "503 Service Unavailable"},
+		"recovered":       {"500 Internal Server Error", "200 OK"},
+		"never_attempted": nil,
+	}
+
+	deliveries := map[string]string{}
+	for name, statuses := range fixtures {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		deliveries[name] = ed.UID
+
+		for _, status := range statuses {
+			err := attemptsRepo.CreateDeliveryAttempt(context.Background(), &datastore.DeliveryAttempt{
+				UID:              ulid.Make().String(),
+				EventDeliveryId:  ed.UID,
+				URL:              "https://example.com",
+				Method:           "POST",
+				ProjectId:        project.UID,
+				EndpointID:       endpoint.UID,
+				APIVersion:       "2024-01-01",
+				HttpResponseCode: status,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	load := func(codes []int, pageable datastore.Pageable) ([]string, datastore.PaginationData) {
+		eds, pagination, err := edRepo.LoadEventDeliveriesPaged(
+			context.Background(), project.UID, []string{endpoint.UID}, "", sub.UID, nil,
+			datastore.SearchParams{
+				CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+				CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+			},
+			pageable, "", "", codes,
+			datastore.PreferReplica,
+		)
+		require.NoError(t, err)
+
+		ids := make([]string, len(eds))
+		for i := range eds {
+			ids[i] = eds[i].UID
+		}
+		return ids, pagination
+	}
+
+	firstPage := datastore.Pageable{PerPage: 10, Direction: datastore.Next, NextCursor: datastore.DefaultCursor}
+
+	// only the latest attempt is considered
+	ids, _ := load([]int{429}, firstPage)
+	require.Equal(t, []string{deliveries["rate_limited"]}, ids)
+
+	ids, _ = load([]int{500, 502, 503}, firstPage)
+	require.Equal(t, []string{deliveries["server_error"]}, ids)
+
+	// deliveries without attempts are only returned when there's no filter
+	ids, _ = load(nil, firstPage)
+	require.Len(t, ids, 4)
+	require.Contains(t, ids, deliveries["never_attempted"])
+
+	// it pages like the other filters
+	ids, pagination := load([]int{429, 503}, datastore.Pageable{PerPage: 1, Direction: datastore.Next, NextCursor: datastore.DefaultCursor})
+	require.Len(t, ids, 1)
+	require.True(t, pagination.HasNextPage)
+	require.False(t, pagination.HasPreviousPage)
+
+	next, pagination := load([]int{429, 503}, datastore.Pageable{PerPage: 1, Direction: datastore.Next, NextCursor: pagination.NextPageCursor})
+	require.Len(t, next, 1)
+	require.NotEqual(t, ids[0], next[0])
+	require.False(t, pagination.HasNextPage)
+	require.True(t, pagination.HasPreviousPage)
+}
+
+func Test_eventDeliveryRepo_LoadEventDeliveriesPagedByCreatedAt(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	// deliveries imported out of order, the id order is the reverse of the chronological one
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	chronological := make([]string, 3)
+	for i := range chronological {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		createdAt := now.Add(-time.Duration(i) * time.Minute)
+		_, err := db.GetDB().ExecContext(context.Background(), `UPDATE convoy.event_deliveries SET created_at = $1 WHERE id = $2`, createdAt, ed.UID)
+		require.NoError(t, err)
+
+		chronological[len(chronological)-1-i] = ed.UID
+	}
+
+	load := func(pageable datastore.Pageable) ([]string, datastore.PaginationData) {
+		eds, pagination, err := edRepo.LoadEventDeliveriesPaged(
+			context.Background(), project.UID, []string{endpoint.UID}, "", sub.UID, nil,
+			datastore.SearchParams{
+				CreatedAtStart: now.Add(-time.Hour).Unix(),
+				CreatedAtEnd:   now.Add(time.Hour).Unix(),
+			},
+			pageable, "", "", nil,
+			datastore.PreferReplica,
+		)
+		require.NoError(t, err)
+
+		ids := make([]string, len(eds))
+		for i := range eds {
+			ids[i] = eds[i].UID
+		}
+		return ids, pagination
+	}
+
+	// the id-only path stays the default
+	ids, _ := load(datastore.Pageable{PerPage: 10, Direction: datastore.Next, NextCursor: datastore.DefaultCursor})
+	require.Equal(t, []string{chronological[0], chronological[1], chronological[2]}, ids)
+
+	ids, _ = load(datastore.Pageable{PerPage: 10, Direction: datastore.Next, SortField: datastore.CreatedAtSortField})
+	require.Equal(t, []string{chronological[2], chronological[1], chronological[0]}, ids)
+
+	ids, _ = load(datastore.Pageable{PerPage: 10, Direction: datastore.Next, Sort: "ASC", SortField: datastore.CreatedAtSortField})
+	require.Equal(t, chronological, ids)
+
+	// page through the compound cursor and back
+	pageable := datastore.Pageable{PerPage: 1, Direction: datastore.Next, SortField: datastore.CreatedAtSortField}
+	pageable.SetCursors()
+
+	first, pagination := load(pageable)
+	require.Equal(t, []string{chronological[2]}, first)
+	require.True(t, pagination.HasNextPage)
+	require.False(t, pagination.HasPreviousPage)
+
+	pageable.NextCursor = pagination.NextPageCursor
+	second, pagination := load(pageable)
+	require.Equal(t, []string{chronological[1]}, second)
+	require.True(t, pagination.HasNextPage)
+	require.True(t, pagination.HasPreviousPage)
+
+	pageable.Direction = datastore.Prev
+	pageable.PrevCursor = pagination.PrevPageCursor
+	back, _ := load(pageable)
+	require.Contains(t, back, chronological[2])
+
+	_, _, err := edRepo.LoadEventDeliveriesPaged(
+		context.Background(), project.UID, nil, "", "", nil, datastore.SearchParams{},
+		datastore.Pageable{PerPage: 1, Direction: datastore.Next, SortField: datastore.CreatedAtSortField, NextCursor: "not-a-cursor"},
+		"", "", nil,
+		datastore.PreferReplica,
+	)
+	require.ErrorIs(t, err, datastore.ErrInvalidCursor)
+}
+
+func Test_eventDeliveryRepo_BackfillLatencySeconds(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ctx := context.Background()
+	edRepo := NewEventDeliveryRepo(db)
+	attemptsRepo := NewDeliveryAttemptRepo(db)
+
+	acknowledgedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	newDelivery := func() *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.AcknowledgedAt = null.TimeFrom(acknowledgedAt)
+		require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+		return ed
+	}
+
+	// delivered on its second attempt, three seconds after it was acknowledged
+	fromAttempts := newDelivery()
+	for i, status := range []bool{false, true} {
+		attempt := &datastore.DeliveryAttempt{
+			UID:              ulid.Make().String(),
+			EventDeliveryId:  fromAttempts.UID,
+			URL:              "https://example.com",
+			Method:           "POST",
+			ProjectId:        project.UID,
+			EndpointID:       endpoint.UID,
+			APIVersion:       "2024-01-01",
+			HttpResponseCode: "200",
+			Status:           status,
+		}
+		require.NoError(t, attemptsRepo.CreateDeliveryAttempt(ctx, attempt))
+
+		_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.delivery_attempts SET created_at = $1 WHERE id = $2`,
+			acknowledgedAt.Add(time.Duration(i+2)*time.Second), attempt.UID)
+		require.NoError(t, err)
+	}
+
+	fromLegacy := newDelivery()
+	_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.event_deliveries SET latency = '1.5s' WHERE id = $1`, fromLegacy.UID)
+	require.NoError(t, err)
+
+	existing := newDelivery()
+	_, err = db.GetDB().ExecContext(ctx, `UPDATE convoy.event_deliveries SET latency = '9s', latency_seconds = 7 WHERE id = $1`, existing.UID)
+	require.NoError(t, err)
+
+	unknown := newDelivery()
+
+	// a batch size of one exercises paging through the rows
+	_, err = edRepo.BackfillLatencySeconds(ctx, 1)
+	require.NoError(t, err)
+
+	latencyOf := func(id string) sql.NullFloat64 {
+		var latency sql.NullFloat64
+		err := db.GetDB().QueryRowxContext(ctx, `SELECT latency_seconds FROM convoy.event_deliveries WHERE id = $1`, id).Scan(&latency)
+		require.NoError(t, err)
+		return latency
+	}
+
+	require.Equal(t, sql.NullFloat64{Float64: 3, Valid: true}, latencyOf(fromAttempts.UID))
+	require.Equal(t, sql.NullFloat64{Float64: 1.5, Valid: true}, latencyOf(fromLegacy.UID))
+	require.Equal(t, sql.NullFloat64{Float64: 7, Valid: true}, latencyOf(existing.UID))
+	require.False(t, latencyOf(unknown.UID).Valid)
+
+	// nothing is left to backfill for these deliveries
+	_, err = edRepo.BackfillLatencySeconds(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, sql.NullFloat64{Float64: 3, Valid: true}, latencyOf(fromAttempts.UID))
+}
+
+func Test_eventDeliveryRepo_CompactDeliveryAttempts(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ctx := context.Background()
+	edRepo := NewEventDeliveryRepo(db)
+	attemptsRepo := NewDeliveryAttemptRepo(db)
+
+	newDelivery := func(attempts string) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+		_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.event_deliveries SET attempts = $1::bytea WHERE id = $2`, []byte(attempts), ed.UID)
+		require.NoError(t, err)
+		return ed
+	}
+
+	attemptedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	legacyID := ulid.Make().String()
+
+	// the second attempt was written without an id or endpoint
+	legacy := newDelivery(fmt.Sprintf(`[
+		{"uid": %q, "url": "https://example.com", "method": "POST", "endpoint_id": %q, "api_version": "2023-01-01", "http_status": "500 Internal Server Error", "response_data": "oops", "status": false, "created_at": %q},
+		{"url": "https://example.com", "method": "POST", "api_version": "2023-01-01", "http_status": "200 OK", "status": true}
+	]`, legacyID, endpoint.UID, attemptedAt.Format(time.RFC3339)))
+	empty := newDelivery(`null`)
+	corrupt := newDelivery(`{"uid": `)
+
+	// a batch size of one exercises paging through the rows
+	inserted, err := edRepo.CompactDeliveryAttempts(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), inserted)
+
+	attempts, err := attemptsRepo.FindDeliveryAttempts(ctx, legacy.UID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+
+	byStatus := map[bool]datastore.DeliveryAttempt{attempts[0].Status: attempts[0], attempts[1].Status: attempts[1]}
+
+	failed := byStatus[false]
+	require.Equal(t, legacyID, failed.UID)
+	require.Equal(t, "500 Internal Server Error", failed.HttpResponseCode)
+	require.Equal(t, "oops", string(failed.ResponseData))
+	require.Equal(t, project.UID, failed.ProjectId)
+	require.True(t, attemptedAt.Equal(failed.CreatedAt))
+
+	succeeded := byStatus[true]
+	require.NotEmpty(t, succeeded.UID)
+	require.Equal(t, endpoint.UID, succeeded.EndpointID)
+	require.Equal(t, legacy.UID, succeeded.EventDeliveryId)
+
+	attemptsOf := func(id string) []byte {
+		var attempts []byte
+		err := db.GetDB().QueryRowxContext(ctx, `SELECT attempts FROM convoy.event_deliveries WHERE id = $1`, id).Scan(&attempts)
+		require.NoError(t, err)
+		return attempts
+	}
+
+	require.Nil(t, attemptsOf(legacy.UID))
+	require.Nil(t, attemptsOf(empty.UID))
+	require.Equal(t, `{"uid": `, string(attemptsOf(corrupt.UID)))
+
+	// nothing is moved twice
+	inserted, err = edRepo.CompactDeliveryAttempts(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), inserted)
+
+	attempts, err = attemptsRepo.FindDeliveryAttempts(ctx, legacy.UID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+}
+
+func Test_eventDeliveryRepo_ComputeEndpointHealth(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ctx := context.Background()
+	edRepo := NewEventDeliveryRepo(db)
+	attemptsRepo := NewDeliveryAttemptRepo(db)
+
+	health, err := edRepo.ComputeEndpointHealth(ctx, project.UID, endpoint.UID, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, &datastore.EndpointHealth{EndpointID: endpoint.UID}, health)
+
+	newDelivery := func(status datastore.EventDeliveryStatus, latency float64, age time.Duration) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = status
+		require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+		_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.event_deliveries SET latency_seconds = $1, created_at = $2 WHERE id = $3`, latency, time.Now().Add(-age), ed.UID)
+		require.NoError(t, err)
+		return ed
+	}
+
+	newAttempt := func(ed *datastore.EventDelivery, status bool, age time.Duration) {
+		attempt := &datastore.DeliveryAttempt{
+			UID:             ulid.Make().String(),
+			URL:             endpoint.Url,
+			Method:          "POST",
+			EndpointID:      endpoint.UID,
+			ProjectId:       project.UID,
+			EventDeliveryId: ed.UID,
+			Status:          status,
+		}
+		require.NoError(t, attemptsRepo.CreateDeliveryAttempt(ctx, attempt))
+
+		_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.delivery_attempts SET created_at = $1 WHERE id = $2`, time.Now().Add(-age), attempt.UID)
+		require.NoError(t, err)
+	}
+
+	succeeded := newDelivery(datastore.SuccessEventStatus, 1, 40*time.Minute)
+	newDelivery(datastore.SuccessEventStatus, 3, 30*time.Minute)
+	failed := newDelivery(datastore.FailureEventStatus, 10, 20*time.Minute)
+	// neither the unfinished delivery nor the one before the window count
+	newDelivery(datastore.ScheduledEventStatus, 0, 10*time.Minute)
+	old := newDelivery(datastore.FailureEventStatus, 0, 2*time.Hour)
+
+	newAttempt(old, false, 2*time.Hour)
+	newAttempt(succeeded, true, 40*time.Minute)
+	newAttempt(failed, false, 20*time.Minute)
+	newAttempt(failed, false, 10*time.Minute)
+
+	health, err = edRepo.ComputeEndpointHealth(ctx, project.UID, endpoint.UID, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, endpoint.UID, health.EndpointID)
+	require.Equal(t, uint64(3), health.Total)
+	require.Equal(t, uint64(2), health.Successful)
+	require.InDelta(t, 66.67, health.SuccessRate, 0.01)
+	require.InDelta(t, 2, health.AvgLatencySeconds, 0.001)
+	require.Equal(t, uint64(2), health.ConsecutiveFailures)
+
+	// a success ends the run of failures
+	newAttempt(failed, true, time.Minute)
+
+	health, err = edRepo.ComputeEndpointHealth(ctx, project.UID, endpoint.UID, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), health.ConsecutiveFailures)
+}
+
+func Test_eventDeliveryRepo_PurgeSoftDeletedEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	ctx := context.Background()
+	edRepo := NewEventDeliveryRepo(db)
+
+	newDelivery := func(deletedAt *time.Time) *datastore.EventDelivery {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+		if deletedAt != nil {
+			_, err := db.GetDB().ExecContext(ctx, `UPDATE convoy.event_deliveries SET deleted_at = $1 WHERE id = $2`, *deletedAt, ed.UID)
+			require.NoError(t, err)
+		}
+		return ed
+	}
+
+	longAgo := time.Now().Add(-48 * time.Hour)
+	recently := time.Now().Add(-time.Hour)
+
+	old := []*datastore.EventDelivery{newDelivery(&longAgo), newDelivery(&longAgo)}
+	recent := newDelivery(&recently)
+	live := newDelivery(nil)
+
+	// attempts reference their delivery, they're purged along with it
+	attempt := &datastore.DeliveryAttempt{
+		UID:              ulid.Make().String(),
+		EventDeliveryId:  old[0].UID,
+		URL:              "https://example.com",
+		Method:           "POST",
+		ProjectId:        project.UID,
+		EndpointID:       endpoint.UID,
+		HttpResponseCode: "500",
+		ResponseData:     []byte(`{"status":"error"}`),
+	}
+	require.NoError(t, NewDeliveryAttemptRepo(db).CreateDeliveryAttempt(ctx, attempt))
+
+	purged, err := edRepo.PurgeSoftDeletedEventDeliveries(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(len(old)))
+
+	exists := func(id string) bool {
+		var count int
+		err := db.GetDB().QueryRowxContext(ctx, `SELECT COUNT(*) FROM convoy.event_deliveries WHERE id = $1`, id).Scan(&count)
+		require.NoError(t, err)
+		return count == 1
+	}
+
+	for _, ed := range old {
+		require.False(t, exists(ed.UID))
+	}
+	require.True(t, exists(recent.UID))
+
+	var attempts int
+	err = db.GetDB().QueryRowxContext(ctx, `SELECT COUNT(*) FROM convoy.delivery_attempts WHERE event_delivery_id = $1`, old[0].UID).Scan(&attempts)
+	require.NoError(t, err)
+	require.Zero(t, attempts)
+
+	ed, err := edRepo.FindEventDeliveryByID(ctx, project.UID, live.UID)
+	require.NoError(t, err)
+	require.Equal(t, live.UID, ed.UID)
+}
+
+func Test_eventDeliveryRepo_PartitionEventDeliveriesTable(t *testing.T) {
+	tests := []struct {
+		name           string
+		granularity    datastore.PartitionGranularity
+		wantPartitions []string
+	}{
+		{
+			name:           "daily",
+			granularity:    datastore.DailyPartitionGranularity,
+			wantPartitions: []string{"20240115", "20240120", "20240203"},
+		},
+		{
+			name:           "monthly",
+			granularity:    datastore.MonthlyPartitionGranularity,
+			wantPartitions: []string{"202401", "202402"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, closeFn := getDB(t)
+			defer closeFn()
+
+			source := seedSource(t, db)
+			project := seedProject(t, db)
+			device := seedDevice(t, db)
+			endpoint := seedEndpoint(t, db)
+			event := seedEvent(t, db, project)
+			sub := seedSubscription(t, db, project, source, endpoint, device)
+
+			ctx := context.Background()
+			edRepo := NewEventDeliveryRepo(db)
+
+			// midday, so the day is the same in the database's time zone
+			createdAt := []time.Time{
+				time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 3, 12, 0, 0, 0, time.UTC),
+			}
+
+			ids := make([]string, 0, len(createdAt))
+			for _, c := range createdAt {
+				ed := generateEventDelivery(project, endpoint, event, device, sub)
+				require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+				_, err := db.GetDB().ExecContext(ctx, "UPDATE convoy.event_deliveries SET created_at = $1 WHERE id = $2", c, ed.UID)
+				require.NoError(t, err)
+				ids = append(ids, ed.UID)
+			}
+
+			require.NoError(t, edRepo.PartitionEventDeliveriesTable(ctx, tc.granularity))
+			defer func() {
+				// whichever layout it was partitioned with, it's collapsed back
+				// into a single table with the deliveries intact
+				require.NoError(t, edRepo.UnPartitionEventDeliveriesTable(ctx))
+
+				var relkind string
+				err := db.GetDB().QueryRowxContext(ctx, "SELECT relkind FROM pg_class WHERE oid = 'convoy.event_deliveries'::regclass").Scan(&relkind)
+				require.NoError(t, err)
+				require.Equal(t, "r", relkind)
+
+				deliveries, err := edRepo.FindEventDeliveriesByIDs(ctx, project.UID, ids)
+				require.NoError(t, err)
+				require.Len(t, deliveries, len(ids))
+			}()
+
+			var partitions []string
+			err := db.GetDB().SelectContext(ctx, &partitions, `
+				SELECT c.relname FROM pg_inherits i
+				JOIN pg_class c ON c.oid = i.inhrelid
+				WHERE i.inhparent = 'convoy.event_deliveries'::regclass
+				ORDER BY c.relname`)
+			require.NoError(t, err)
+
+			prefix := "event_deliveries_" + strings.ReplaceAll(project.UID, "-", "") + "_"
+			want := make([]string, 0, len(tc.wantPartitions))
+			for _, suffix := range tc.wantPartitions {
+				want = append(want, strings.ToLower(prefix+suffix))
+			}
+			require.Equal(t, want, partitions)
+
+			deliveries, err := edRepo.FindEventDeliveriesByIDs(ctx, project.UID, ids)
+			require.NoError(t, err)
+			require.Len(t, deliveries, len(ids))
+		})
+	}
+
+	t.Run("unknown granularity", func(t *testing.T) {
+		db, closeFn := getDB(t)
+		defer closeFn()
+
+		err := NewEventDeliveryRepo(db).PartitionEventDeliveriesTable(context.Background(), "weekly")
+		require.Error(t, err)
+	})
+}
+
+func Test_eventDeliveryRepo_EnsureEventDeliveryPartitions(t *testing.T) {
+	tests := []struct {
+		name           string
+		granularity    datastore.PartitionGranularity
+		from, to       time.Time
+		wantCreated    int
+		wantPartitions []string
+	}{
+		{
+			name:           "daily",
+			granularity:    datastore.DailyPartitionGranularity,
+			from:           time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC),
+			to:             time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+			wantCreated:    2,
+			wantPartitions: []string{"20240114", "20240115", "20240116"},
+		},
+		{
+			name:           "monthly",
+			granularity:    datastore.MonthlyPartitionGranularity,
+			from:           time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+			to:             time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+			wantCreated:    2,
+			wantPartitions: []string{"202401", "202402", "202403"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, closeFn := getDB(t)
+			defer closeFn()
+
+			source := seedSource(t, db)
+			project := seedProject(t, db)
+			device := seedDevice(t, db)
+			endpoint := seedEndpoint(t, db)
+			event := seedEvent(t, db, project)
+			sub := seedSubscription(t, db, project, source, endpoint, device)
+
+			ctx := context.Background()
+			edRepo := NewEventDeliveryRepo(db)
+
+			// the table isn't partitioned yet
+			_, err := edRepo.EnsureEventDeliveryPartitions(ctx, project.UID, tc.from, tc.to)
+			require.Error(t, err)
+
+			ed := generateEventDelivery(project, endpoint, event, device, sub)
+			require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+
+			_, err = db.GetDB().ExecContext(ctx, "UPDATE convoy.event_deliveries SET created_at = $1 WHERE id = $2",
+				time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), ed.UID)
+			require.NoError(t, err)
+
+			require.NoError(t, edRepo.PartitionEventDeliveriesTable(ctx, tc.granularity))
+			defer func() {
+				require.NoError(t, edRepo.UnPartitionEventDeliveriesTable(ctx))
+			}()
+
+			created, err := edRepo.EnsureEventDeliveryPartitions(ctx, project.UID, tc.from, tc.to)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantCreated, created)
+
+			// periods that already have a partition are skipped
+			created, err = edRepo.EnsureEventDeliveryPartitions(ctx, project.UID, tc.from, tc.to)
+			require.NoError(t, err)
+			require.Equal(t, 0, created)
+
+			var partitions []string
+			err = db.GetDB().SelectContext(ctx, &partitions, `
+				SELECT c.relname FROM pg_inherits i
+				JOIN pg_class c ON c.oid = i.inhrelid
+				WHERE i.inhparent = 'convoy.event_deliveries'::regclass
+				ORDER BY c.relname`)
+			require.NoError(t, err)
+
+			prefix := "event_deliveries_" + strings.ReplaceAll(project.UID, "-", "") + "_"
+			want := make([]string, 0, len(tc.wantPartitions))
+			for _, suffix := range tc.wantPartitions {
+				want = append(want, strings.ToLower(prefix+suffix))
+			}
+			require.Equal(t, want, partitions)
+
+			// deliveries in the repaired range can be inserted
+			ed = generateEventDelivery(project, endpoint, event, device, sub)
+			ed.CreatedAt = tc.to.Add(12 * time.Hour)
+			require.NoError(t, edRepo.CreateEventDelivery(ctx, ed))
+		})
+	}
+
+	t.Run("end before start", func(t *testing.T) {
+		db, closeFn := getDB(t)
+		defer closeFn()
+
+		from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		_, err := NewEventDeliveryRepo(db).EnsureEventDeliveryPartitions(context.Background(), "project-1", from, from.AddDate(0, 0, -1))
+		require.Error(t, err)
+	})
+}
+
+func Test_eventDeliveryRepo_DropEventDeliveryPartitionsBefore(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	project := seedProject(t, db)
+
+	ctx := context.Background()
+	edRepo := NewEventDeliveryRepo(db)
+
+	// the table isn't partitioned yet
+	granularity, err := edRepo.EventDeliveryPartitionGranularity(ctx)
+	require.NoError(t, err)
+	require.Empty(t, granularity)
+
+	require.NoError(t, edRepo.PartitionEventDeliveriesTable(ctx, datastore.MonthlyPartitionGranularity))
+	defer func() {
+		require.NoError(t, edRepo.UnPartitionEventDeliveriesTable(ctx))
+	}()
+
+	_, err = edRepo.EnsureEventDeliveryPartitions(ctx, project.UID,
+		time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	granularity, err = edRepo.EventDeliveryPartitionGranularity(ctx)
+	require.NoError(t, err)
+	require.Equal(t, datastore.MonthlyPartitionGranularity, granularity)
+
+	// a month is only dropped once all of it is before the cutoff
+	dropped, err := edRepo.DropEventDeliveryPartitionsBefore(ctx, time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+
+	dropped, err = edRepo.DropEventDeliveryPartitionsBefore(ctx, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+
+	var partitions []string
+	err = db.GetDB().SelectContext(ctx, &partitions, `
+		SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'convoy.event_deliveries'::regclass
+		ORDER BY c.relname`)
+	require.NoError(t, err)
+	require.Equal(t, []string{strings.ToLower("event_deliveries_" + strings.ReplaceAll(project.UID, "-", "") + "_202403")}, partitions)
 }
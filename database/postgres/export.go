@@ -10,6 +10,7 @@ import (
 
 	"github.com/tidwall/gjson"
 
+	"github.com/frain-dev/convoy/datastore"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -26,17 +27,41 @@ const (
 	`
 
 	where = ` WHERE deleted_at IS NULL AND project_id = $1 AND created_at < $2 AND (id > $3 OR $3 = '')`
+
+	// sampleWhere keeps a row when the hash of its id and the seed falls in
+	// the first rate fraction of sampleBuckets, so a given seed always
+	// selects the same rows regardless of batch boundaries.
+	sampleWhere = ` AND (hashtext(id || $%d) & 2147483647) %% %d < $%d`
 )
 
+const sampleBuckets = 10000
+
 // ExportRecords exports the records from the given table and writes them in json format to the passed writer.
 // It's the caller's responsibility to close the writer.
-func exportRecords(ctx context.Context, db *sqlx.DB, tableName, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
+func exportRecords(ctx context.Context, db *sqlx.DB, tableName, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
+	o := &datastore.ExportOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.SampleRate < 0 || o.SampleRate > 1 {
+		return 0, fmt.Errorf("sample rate must be between 0 and 1, got %v", o.SampleRate)
+	}
+
+	countWhere, exportWhere := where, where
+	var sampleArgs []interface{}
+	if o.SampleRate > 0 && o.SampleRate < 1 {
+		countWhere += fmt.Sprintf(sampleWhere, 4, sampleBuckets, 5)
+		exportWhere += fmt.Sprintf(sampleWhere, 5, sampleBuckets, 6)
+		sampleArgs = []interface{}{o.SampleSeed, int(math.Round(o.SampleRate * sampleBuckets))}
+	}
+
 	c := &struct {
 		Count int64 `db:"count"`
 	}{}
 
-	countQuery := fmt.Sprintf(count, tableName, where)
-	err := db.QueryRowxContext(ctx, countQuery, projectID, createdAt, "").StructScan(c)
+	countQuery := fmt.Sprintf(count, tableName, countWhere)
+	err := db.QueryRowxContext(ctx, countQuery, append([]interface{}{projectID, createdAt, ""}, sampleArgs...)...).StructScan(c)
 	if err != nil {
 		return 0, err
 	}
@@ -56,14 +81,14 @@ func exportRecords(ctx context.Context, db *sqlx.DB, tableName, projectID string
 		return 0, err
 	}
 
-	q := fmt.Sprintf(exportRepoQ, tableName, where)
+	q := fmt.Sprintf(exportRepoQ, tableName, exportWhere)
 	var (
 		n      int64
 		lastID string
 	)
 
 	for i := 0; i < numBatches; i++ {
-		n, lastID, err = querybatch(ctx, db, q, projectID, lastID, createdAt, batchSize, w)
+		n, lastID, err = querybatch(ctx, db, q, projectID, lastID, createdAt, batchSize, w, sampleArgs...)
 		if err != nil {
 			return 0, fmt.Errorf("failed to query batch %d: %v", i, err)
 		}
@@ -80,12 +105,12 @@ func exportRecords(ctx context.Context, db *sqlx.DB, tableName, projectID string
 
 var commaJSON = []byte(`,`)
 
-func querybatch(ctx context.Context, db *sqlx.DB, q, projectID, lastID string, createdAt time.Time, batchSize int, w io.Writer) (int64, string, error) {
+func querybatch(ctx context.Context, db *sqlx.DB, q, projectID, lastID string, createdAt time.Time, batchSize int, w io.Writer, sampleArgs ...interface{}) (int64, string, error) {
 	var numDocs int64
 
 	// Calling rows.Close() manually in places before we return is important here to prevent
 	//  a memory leak, we cannot use defer in a loop because this can fill up the function stack quickly
-	rows, err := db.QueryxContext(ctx, q, projectID, createdAt, lastID, batchSize)
+	rows, err := db.QueryxContext(ctx, q, append([]interface{}{projectID, createdAt, lastID, batchSize}, sampleArgs...)...)
 	if err != nil {
 		return 0, "", err
 	}
@@ -0,0 +1,167 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExportRecords_Sampled(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	project := seedProject(t, db)
+	eventRepo := NewEventRepo(db)
+
+	total := 1000
+	for i := 0; i < total; i++ {
+		seedEvent(t, db, project)
+	}
+
+	exportUIDs := func(opts ...datastore.ExportOption) []string {
+		var buf bytes.Buffer
+		n, err := eventRepo.ExportRecords(context.Background(), project.UID, time.Now().Add(time.Hour), &buf, opts...)
+		require.NoError(t, err)
+
+		var records []struct {
+			UID string `json:"uid"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+		require.Equal(t, n, int64(len(records)))
+
+		uids := make([]string, 0, len(records))
+		for _, r := range records {
+			uids = append(uids, r.UID)
+		}
+
+		return uids
+	}
+
+	require.Len(t, exportUIDs(), total)
+
+	sample := exportUIDs(datastore.WithSampleRate(0.2, "seed-a"))
+	require.InDelta(t, 200, len(sample), 60)
+
+	// the same seed selects the same rows
+	require.Equal(t, sample, exportUIDs(datastore.WithSampleRate(0.2, "seed-a")))
+
+	// a different seed selects a different sample of about the same size
+	other := exportUIDs(datastore.WithSampleRate(0.2, "seed-b"))
+	require.InDelta(t, 200, len(other), 60)
+	require.NotEqual(t, sample, other)
+
+	_, err := eventRepo.ExportRecords(context.Background(), project.UID, time.Now(), &bytes.Buffer{}, datastore.WithSampleRate(1.5, "seed-a"))
+	require.Error(t, err)
+}
+
+func Test_eventDeliveryRepo_ExportRecordsFormat(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	edRepo := NewEventDeliveryRepo(db)
+
+	ids := make(map[string]struct{})
+	for i := 0; i < 5; i++ {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+		ids[ed.UID] = struct{}{}
+	}
+
+	createdAt := time.Now().Add(time.Hour)
+
+	t.Run("ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := edRepo.ExportRecordsFormat(context.Background(), project.UID, createdAt, datastore.NDJSONExportFormat, &buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), n)
+
+		lines := 0
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			lines++
+
+			var record struct {
+				UID              string `json:"uid"`
+				Status           string `json:"status"`
+				EndpointMetadata struct {
+					ID  string `json:"id"`
+					URL string `json:"url"`
+				} `json:"endpoint_metadata"`
+			}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+
+			require.Contains(t, ids, record.UID)
+			require.Equal(t, string(datastore.SuccessEventStatus), record.Status)
+			require.Equal(t, endpoint.UID, record.EndpointMetadata.ID)
+			require.Equal(t, endpoint.Url, record.EndpointMetadata.URL)
+		}
+		require.Equal(t, 5, lines)
+	})
+
+	t.Run("ndjson_with_columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := edRepo.ExportRecordsFormat(context.Background(), project.UID, createdAt, datastore.NDJSONExportFormat, &buf, datastore.WithColumns("uid", "status"))
+		require.NoError(t, err)
+
+		line, err := bufio.NewReader(&buf).ReadBytes('\n')
+		require.NoError(t, err)
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &record))
+		require.Len(t, record, 2)
+		require.Contains(t, record, "uid")
+		require.Contains(t, record, "status")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := edRepo.ExportRecordsFormat(context.Background(), project.UID, createdAt, datastore.CSVExportFormat, &buf, datastore.WithColumns("uid", "endpoint_id", "endpoint_metadata"))
+		require.NoError(t, err)
+		require.Equal(t, int64(5), n)
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 6)
+		require.Equal(t, []string{"uid", "endpoint_id", "endpoint_metadata"}, rows[0])
+
+		for _, row := range rows[1:] {
+			require.Contains(t, ids, row[0])
+			require.Equal(t, endpoint.UID, row[1])
+			require.Contains(t, row[2], endpoint.UID)
+		}
+	})
+
+	t.Run("json_is_unchanged", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := edRepo.ExportRecords(context.Background(), project.UID, createdAt, &buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), n)
+
+		var records []map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+		require.Len(t, records, 5)
+	})
+
+	_, err := edRepo.ExportRecordsFormat(context.Background(), project.UID, createdAt, datastore.NDJSONExportFormat, &bytes.Buffer{}, datastore.WithColumns("password"))
+	require.Error(t, err)
+
+	_, err = edRepo.ExportRecordsFormat(context.Background(), project.UID, createdAt, "xml", &bytes.Buffer{})
+	require.Error(t, err)
+}
@@ -36,6 +36,9 @@ type Postgres struct {
 	pool     *pgxpool.Pool
 	replicas []*Postgres
 	randGen  *rand.Rand
+
+	// lag is this replica's last measured replay lag
+	lag *replicaLag
 }
 
 func NewDB(cfg config.Configuration) (*Postgres, error) {
@@ -64,6 +67,9 @@ func NewDB(cfg config.Configuration) (*Postgres, error) {
 	primary.replicas = replicas
 	primary.randGen = rand.New(rand.NewSource(time.Now().UnixNano()))
 
+	SetMaxFanOutTransactions(dbConfig.MaxFanOutTransactions)
+	SetMaxReplicaLag(dbConfig.MaxReplicaLagBytes)
+
 	if err_ := ping(primary); err_ != nil {
 		return nil, err_
 	}
@@ -92,7 +98,7 @@ func parseDBConfig(dbConfig config.DatabaseConfiguration, src ...string) (*Postg
 	sqlDB := stdlib.OpenDBFromPool(pool)
 	db := sqlx.NewDb(sqlDB, "pgx")
 
-	return &Postgres{dbx: db, pool: pool}, nil
+	return &Postgres{dbx: db, pool: pool, lag: &replicaLag{}}, nil
 }
 
 func (p *Postgres) GetDB() *sqlx.DB {
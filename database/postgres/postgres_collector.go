@@ -18,6 +18,9 @@ var cachedMetrics *Metrics // needed to feed the UI with data when sampling time
 
 var metricsConfig *config.MetricsConfiguration
 
+// deliverySummaryWindow returns the period the delivery summary counts cover
+var deliverySummaryWindow = lastCompleteMinute
+
 type EventQueueMetrics struct {
 	ProjectID string `json:"project_id" db:"project_id"`
 	SourceId  string `json:"source_id" db:"source_id"`
@@ -59,6 +62,12 @@ type EventQueueEndpointAttemptMetrics struct {
 	Total      uint64 `json:"total" db:"total"`
 }
 
+type EventDeliverySummaryMetrics struct {
+	ProjectID string `json:"project_id" db:"project_id"`
+	Status    string `json:"status" db:"status"`
+	Total     uint64 `json:"total" db:"total"`
+}
+
 type Metrics struct {
 	EventQueueMetrics []EventQueueMetrics
 
@@ -67,6 +76,7 @@ type Metrics struct {
 	EventDeliveryQueueMetrics        []EventDeliveryQueueMetrics
 	EventQueueEndpointBacklogMetrics []EventQueueEndpointBacklogMetrics
 	EventQueueEndpointAttemptMetrics []EventQueueEndpointAttemptMetrics
+	EventDeliverySummaryMetrics      []EventDeliverySummaryMetrics
 }
 
 var (
@@ -99,6 +109,12 @@ var (
 		"Total number of attempts per endpoint",
 		[]string{"project", "endpoint", "status", "http_status_code"}, nil,
 	)
+
+	eventDeliverySummaryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "event_delivery_summary"),
+		"Number of event deliveries created in the last complete minute per project and status",
+		[]string{"project", "status"}, nil,
+	)
 )
 
 func (p *Postgres) Describe(ch chan<- *prometheus.Desc) {
@@ -212,6 +228,21 @@ func (p *Postgres) Collect(ch chan<- prometheus.Metric) {
 		)
 		metricsMap[key] = struct{}{}
 	}
+
+	for _, metric := range metrics.EventDeliverySummaryMetrics {
+		key := fmt.Sprintf("edsm_%s_%s", metric.ProjectID, metric.Status)
+		if _, ok := metricsMap[key]; ok {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			eventDeliverySummaryDesc,
+			prometheus.GaugeValue,
+			float64(metric.Total),
+			metric.ProjectID,
+			strings.ToLower(metric.Status),
+		)
+		metricsMap[key] = struct{}{}
+	}
 	clear(metricsMap)
 
 	lastRun = now
@@ -331,5 +362,43 @@ func (p *Postgres) collectMetrics() (*Metrics, error) {
 	}
 	metrics.EventQueueEndpointBacklogMetrics = eventQueueEndpointBacklogMetrics
 
+	if metricsConfig.Prometheus.DeliverySummary {
+		start, end := deliverySummaryWindow()
+		metrics.EventDeliverySummaryMetrics, err = p.collectDeliverySummary(start, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return metrics, nil
 }
+
+// collectDeliverySummary counts the deliveries created in [start, end) per project and status
+func (p *Postgres) collectDeliverySummary(start, end time.Time) ([]EventDeliverySummaryMetrics, error) {
+	query := `SELECT project_id, status, COUNT(*) AS total
+    FROM convoy.event_deliveries
+    WHERE created_at >= $1 AND created_at < $2 AND deleted_at IS NULL
+    GROUP BY project_id, status`
+	rows, err := p.GetDB().Queryx(query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithError(rows)
+
+	summary := make([]EventDeliverySummaryMetrics, 0)
+	for rows.Next() {
+		var m EventDeliverySummaryMetrics
+		err = rows.StructScan(&m)
+		if err != nil {
+			return nil, err
+		}
+		summary = append(summary, m)
+	}
+
+	return summary, rows.Err()
+}
+
+func lastCompleteMinute() (time.Time, time.Time) {
+	end := time.Now().Truncate(time.Minute)
+	return end.Add(-time.Minute), end
+}
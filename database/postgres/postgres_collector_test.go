@@ -0,0 +1,77 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func TestPostgres_CollectDeliverySummary(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	source := seedSource(t, db)
+	project := seedProject(t, db)
+	device := seedDevice(t, db)
+	endpoint := seedEndpoint(t, db)
+	event := seedEvent(t, db, project)
+	sub := seedSubscription(t, db, project, source, endpoint, device)
+
+	start := time.Date(2024, time.March, 1, 10, 4, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+
+	fixtures := []struct {
+		status    datastore.EventDeliveryStatus
+		createdAt time.Time
+	}{
+		{status: datastore.SuccessEventStatus, createdAt: start},
+		{status: datastore.SuccessEventStatus, createdAt: start.Add(30 * time.Second)},
+		{status: datastore.FailureEventStatus, createdAt: start.Add(59 * time.Second)},
+		// outside the window
+		{status: datastore.SuccessEventStatus, createdAt: end},
+		{status: datastore.FailureEventStatus, createdAt: start.Add(-time.Second)},
+	}
+
+	edRepo := NewEventDeliveryRepo(db)
+	for _, f := range fixtures {
+		ed := generateEventDelivery(project, endpoint, event, device, sub)
+		ed.Status = f.status
+		require.NoError(t, edRepo.CreateEventDelivery(context.Background(), ed))
+
+		_, err := db.GetDB().ExecContext(context.Background(), "UPDATE convoy.event_deliveries SET created_at = $1 WHERE id = $2", f.createdAt, ed.UID)
+		require.NoError(t, err)
+	}
+
+	metricsConfig = &config.MetricsConfiguration{
+		IsEnabled:  true,
+		Prometheus: config.PrometheusMetricsConfiguration{SampleTime: 1, DeliverySummary: true},
+	}
+	cachedMetrics = nil
+	deliverySummaryWindow = func() (time.Time, time.Time) { return start, end }
+	defer func() {
+		metricsConfig = nil
+		cachedMetrics = nil
+		deliverySummaryWindow = lastCompleteMinute
+	}()
+
+	expected := fmt.Sprintf(`
+# HELP convoy_event_delivery_summary Number of event deliveries created in the last complete minute per project and status
+# TYPE convoy_event_delivery_summary gauge
+convoy_event_delivery_summary{project="%[1]s",status="failure"} 1
+convoy_event_delivery_summary{project="%[1]s",status="success"} 2
+`, project.UID)
+
+	err := testutil.CollectAndCompare(db.(*Postgres), strings.NewReader(expected), "convoy_event_delivery_summary")
+	require.NoError(t, err)
+}
@@ -38,12 +38,25 @@ const (
 		strategy_retry_count, signature_header, signature_versions,
 		disable_endpoint, meta_events_enabled, meta_events_type,
 		meta_events_event_type, meta_events_url, meta_events_secret,
-		meta_events_pub_sub, ssl_enforce_secure_endpoints
+		meta_events_pub_sub, ssl_enforce_secure_endpoints,
+		dead_letter_replay_policy, dead_letter_replay_after_hours,
+		signature_unsigned_event_types, signature_proxy_url,
+		content_idempotency_keys, max_event_age_seconds,
+		signature_sign_request_target, max_retry_seconds,
+		signature_sign_query_params, metadata_headers,
+		delivery_mode, signature_secret_epoch_header,
+		signature_sign_secret_epoch, signature_missing_secret_policy,
+		ingest_rate_limit, strategy_backoff_base, strategy_max_interval,
+		strategy_jitter_percent, max_concurrent_deliveries,
+		test_mode, inspection_token
 	  )
 	  VALUES
 		(
 		  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
-		  $14, $15, $16, $17, $18, $19
+		  $14, $15, $16, $17, $18, $19, $20, $21, COALESCE($22::TEXT[], '{}'), $23,
+		  $24, $25, $26, $27, $28, COALESCE($29::TEXT[], '{}'),
+		  COALESCE(NULLIF($30, ''), 'at_least_once'), $31, $32, $33,
+		  $34, $35, $36, $37, $38, $39, $40
 		);
 	`
 
@@ -67,6 +80,27 @@ const (
 		meta_events_pub_sub = $17,
 		search_policy = $18,
 		ssl_enforce_secure_endpoints = $19,
+		dead_letter_replay_policy = $20,
+		dead_letter_replay_after_hours = $21,
+		signature_unsigned_event_types = COALESCE($22::TEXT[], '{}'),
+		signature_proxy_url = $23,
+		content_idempotency_keys = $24,
+		max_event_age_seconds = $25,
+		signature_sign_request_target = $26,
+		max_retry_seconds = $27,
+		signature_sign_query_params = $28,
+		metadata_headers = COALESCE($29::TEXT[], '{}'),
+		delivery_mode = COALESCE(NULLIF($30, ''), 'at_least_once'),
+		signature_secret_epoch_header = $31,
+		signature_sign_secret_epoch = $32,
+		signature_missing_secret_policy = $33,
+		ingest_rate_limit = $34,
+		strategy_backoff_base = $35,
+		strategy_max_interval = $36,
+		strategy_jitter_percent = $37,
+		max_concurrent_deliveries = $38,
+		test_mode = $39,
+		inspection_token = $40,
 		updated_at = NOW()
 	WHERE id = $1 AND deleted_at IS NULL;
 	`
@@ -88,8 +122,27 @@ const (
 		c.strategy_type AS "config.strategy.type",
 		c.strategy_duration AS "config.strategy.duration",
 		c.strategy_retry_count AS "config.strategy.retry_count",
+		c.strategy_backoff_base AS "config.strategy.backoff_base",
+		c.strategy_max_interval AS "config.strategy.max_interval",
+		c.strategy_jitter_percent AS "config.strategy.jitter_percent",
 		c.signature_header AS "config.signature.header",
 		c.signature_versions AS "config.signature.versions",
+		c.signature_unsigned_event_types AS "config.signature.unsigned_event_types",
+		c.signature_proxy_url AS "config.signature.proxy_url",
+		c.signature_sign_request_target AS "config.signature.sign_request_target",
+		c.signature_sign_query_params AS "config.signature.sign_query_params",
+		c.signature_secret_epoch_header AS "config.signature.secret_epoch_header",
+		c.signature_sign_secret_epoch AS "config.signature.sign_secret_epoch",
+		c.signature_missing_secret_policy AS "config.signature.missing_secret_policy",
+		c.content_idempotency_keys AS "config.content_idempotency_keys",
+		c.max_event_age_seconds AS "config.max_event_age_seconds",
+		c.max_retry_seconds AS "config.max_retry_seconds",
+		c.ingest_rate_limit AS "config.ingest_rate_limit",
+		c.max_concurrent_deliveries AS "config.max_concurrent_deliveries",
+		c.test_mode AS "config.test_mode",
+		c.inspection_token AS "config.inspection_token",
+		c.metadata_headers AS "config.metadata_headers",
+		c.delivery_mode AS "config.delivery_mode",
 		c.disable_endpoint AS "config.disable_endpoint",
 		c.ssl_enforce_secure_endpoints as "config.ssl.enforce_secure_endpoints",
 		c.meta_events_enabled AS "config.meta_event.is_enabled",
@@ -98,6 +151,8 @@ const (
 		COALESCE(c.meta_events_url, '') AS "config.meta_event.url",
 		COALESCE(c.meta_events_secret, '') AS "config.meta_event.secret",
 		c.meta_events_pub_sub AS "config.meta_event.pub_sub",
+		c.dead_letter_replay_policy AS "config.dead_letter_replay.policy",
+		c.dead_letter_replay_after_hours AS "config.dead_letter_replay.auto_replay_after_hours",
 		p.created_at,
 		p.updated_at,
 		p.deleted_at
@@ -125,14 +180,35 @@ const (
 	c.strategy_duration AS "config.strategy.duration",
 	c.ssl_enforce_secure_endpoints as "config.ssl.enforce_secure_endpoints",
 	c.strategy_retry_count AS "config.strategy.retry_count",
+	c.strategy_backoff_base AS "config.strategy.backoff_base",
+	c.strategy_max_interval AS "config.strategy.max_interval",
+	c.strategy_jitter_percent AS "config.strategy.jitter_percent",
 	c.signature_header AS "config.signature.header",
 	c.signature_versions AS "config.signature.versions",
+	c.signature_unsigned_event_types AS "config.signature.unsigned_event_types",
+	c.signature_proxy_url AS "config.signature.proxy_url",
+	c.signature_sign_request_target AS "config.signature.sign_request_target",
+	c.signature_sign_query_params AS "config.signature.sign_query_params",
+	c.signature_secret_epoch_header AS "config.signature.secret_epoch_header",
+	c.signature_sign_secret_epoch AS "config.signature.sign_secret_epoch",
+	c.signature_missing_secret_policy AS "config.signature.missing_secret_policy",
+	c.content_idempotency_keys AS "config.content_idempotency_keys",
+	c.max_event_age_seconds AS "config.max_event_age_seconds",
+	c.max_retry_seconds AS "config.max_retry_seconds",
+	c.ingest_rate_limit AS "config.ingest_rate_limit",
+	c.max_concurrent_deliveries AS "config.max_concurrent_deliveries",
+	c.test_mode AS "config.test_mode",
+	c.inspection_token AS "config.inspection_token",
+	c.metadata_headers AS "config.metadata_headers",
+	c.delivery_mode AS "config.delivery_mode",
 	c.meta_events_enabled AS "config.meta_event.is_enabled",
 	COALESCE(c.meta_events_type, '') AS "config.meta_event.type",
 	c.meta_events_event_type AS "config.meta_event.event_type",
 	COALESCE(c.meta_events_url, '') AS "config.meta_event.url",
 	COALESCE(c.meta_events_secret, '') AS "config.meta_event.secret",
 	c.meta_events_pub_sub AS "config.meta_event.pub_sub",
+	c.dead_letter_replay_policy AS "config.dead_letter_replay.policy",
+	c.dead_letter_replay_after_hours AS "config.dead_letter_replay.auto_replay_after_hours",
 	p.created_at,
 	p.updated_at,
 	p.deleted_at
@@ -142,6 +218,13 @@ const (
   WHERE (p.organisation_id = $1 OR $1 = '') AND p.deleted_at IS NULL ORDER BY p.id;
  `
 
+	fetchProjectIDByInspectionToken = `
+	SELECT p.id
+	FROM convoy.projects p
+	JOIN convoy.project_configurations c ON p.project_configuration_id = c.id
+	WHERE c.inspection_token = $1 AND c.test_mode AND p.deleted_at IS NULL;
+	`
+
 	updateProjectById = `
 	UPDATE convoy.projects SET
 	name = $2,
@@ -187,11 +270,13 @@ const (
 	WHERE project_id = ? AND status IN (?) AND deleted_at IS NULL RETURNING
 	id, name, status, owner_id, url,
     description, http_timeout, rate_limit, rate_limit_duration,
-    advanced_signatures, slack_webhook_url, support_email,
+    advanced_signatures, body_format, pinned_signature_version, http_method, max_concurrent_deliveries, min_delivery_interval_ms, retry_after_floor_seconds, retry_after_ceiling_seconds, content_type, form_encoding, slack_webhook_url, support_email,
     app_id, project_id, secrets, created_at, updated_at,
     authentication_type AS "authentication.type",
     authentication_type_api_key_header_name AS "authentication.api_key.header_name",
-    authentication_type_api_key_header_value AS "authentication.api_key.header_value";
+    authentication_type_api_key_header_value AS "authentication.api_key.header_value",
+    authentication_type_digest_username AS "authentication.digest_auth.username",
+    authentication_type_digest_password AS "authentication.digest_auth.password";
 	`
 
 	getProjectsWithEventsInTheInterval = `
@@ -240,6 +325,7 @@ func (p *projectRepo) CreateProject(ctx context.Context, project *datastore.Proj
 	sc := project.Config.GetStrategyConfig()
 	sgc := project.Config.GetSignatureConfig()
 	me := project.Config.GetMetaEventConfig()
+	dlr := project.Config.GetDeadLetterReplayConfig()
 
 	configID := ulid.Make().String()
 	result, err := tx.ExecContext(ctx, createProjectConfiguration,
@@ -262,6 +348,27 @@ func (p *projectRepo) CreateProject(ctx context.Context, project *datastore.Proj
 		me.Secret,
 		me.PubSub,
 		project.Config.SSL.EnforceSecureEndpoints,
+		dlr.Policy,
+		dlr.AutoReplayAfterHours,
+		sgc.UnsignedEventTypes,
+		sgc.ProxyURL,
+		project.Config.ContentIdempotencyKeys,
+		project.Config.MaxEventAgeSeconds,
+		sgc.SignRequestTarget,
+		project.Config.MaxRetrySeconds,
+		sgc.SignQueryParams,
+		project.Config.MetadataHeaders,
+		project.Config.DeliveryMode,
+		sgc.SecretEpochHeader,
+		sgc.SignSecretEpoch,
+		sgc.MissingSecretPolicy,
+		project.Config.IngestRateLimit,
+		sc.BackoffBase,
+		sc.MaxInterval,
+		sc.JitterPercent,
+		project.Config.MaxConcurrentDeliveries,
+		project.Config.TestMode,
+		project.Config.InspectionToken,
 	)
 	if err != nil {
 		return err
@@ -360,6 +467,7 @@ func (p *projectRepo) UpdateProject(ctx context.Context, project *datastore.Proj
 	sgc := project.Config.GetSignatureConfig()
 	ssl := project.Config.GetSSLConfig()
 	me := project.Config.GetMetaEventConfig()
+	dlr := project.Config.GetDeadLetterReplayConfig()
 
 	cRes, err := tx.ExecContext(ctx, updateProjectConfiguration,
 		project.ProjectConfigID,
@@ -381,6 +489,27 @@ func (p *projectRepo) UpdateProject(ctx context.Context, project *datastore.Proj
 		me.PubSub,
 		project.Config.SearchPolicy,
 		ssl.EnforceSecureEndpoints,
+		dlr.Policy,
+		dlr.AutoReplayAfterHours,
+		sgc.UnsignedEventTypes,
+		sgc.ProxyURL,
+		project.Config.ContentIdempotencyKeys,
+		project.Config.MaxEventAgeSeconds,
+		sgc.SignRequestTarget,
+		project.Config.MaxRetrySeconds,
+		sgc.SignQueryParams,
+		project.Config.MetadataHeaders,
+		project.Config.DeliveryMode,
+		sgc.SecretEpochHeader,
+		sgc.SignSecretEpoch,
+		sgc.MissingSecretPolicy,
+		project.Config.IngestRateLimit,
+		sc.BackoffBase,
+		sc.MaxInterval,
+		sc.JitterPercent,
+		project.Config.MaxConcurrentDeliveries,
+		project.Config.TestMode,
+		project.Config.InspectionToken,
 	)
 	if err != nil {
 		return fmt.Errorf("update project config err: %v", err)
@@ -441,6 +570,21 @@ func (p *projectRepo) FetchProjectByID(ctx context.Context, id string) (*datasto
 	return &project, nil
 }
 
+// FetchProjectByInspectionToken returns the project in test mode whose
+// inspection URL has token in it.
+func (p *projectRepo) FetchProjectByInspectionToken(ctx context.Context, token string) (*datastore.Project, error) {
+	var id string
+	err := p.db.GetDB().GetContext(ctx, &id, fetchProjectIDByInspectionToken, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	return p.FetchProjectByID(ctx, id)
+}
+
 func (p *projectRepo) FillProjectsStatistics(ctx context.Context, project *datastore.Project) error {
 	var stats datastore.ProjectStatistics
 	err := p.db.GetReadDB().GetContext(ctx, &stats, projectStatistics, project.UID)
@@ -61,6 +61,43 @@ func Test_FetchProjectByID(t *testing.T) {
 	require.Equal(t, newProject, dbProject)
 }
 
+func Test_FetchProjectByInspectionToken(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	ctx := context.Background()
+	projectRepo := NewProjectRepo(db)
+
+	config := datastore.DefaultProjectConfig
+	config.TestMode = true
+	config.InspectionToken = ulid.Make().String()
+
+	project := &datastore.Project{
+		UID:            ulid.Make().String(),
+		Name:           "A project in test mode",
+		OrganisationID: seedOrg(t, db).UID,
+		Type:           datastore.OutgoingProject,
+		Config:         &config,
+	}
+	require.NoError(t, projectRepo.CreateProject(ctx, project))
+
+	dbProject, err := projectRepo.FetchProjectByInspectionToken(ctx, config.InspectionToken)
+	require.NoError(t, err)
+	require.Equal(t, project.UID, dbProject.UID)
+	require.True(t, dbProject.Config.TestMode)
+	require.Equal(t, config.InspectionToken, dbProject.Config.InspectionToken)
+
+	_, err = projectRepo.FetchProjectByInspectionToken(ctx, "unknown")
+	require.ErrorIs(t, err, datastore.ErrProjectNotFound)
+
+	// the token stops working once test mode is off
+	project.Config.TestMode = false
+	require.NoError(t, projectRepo.UpdateProject(ctx, project))
+
+	_, err = projectRepo.FetchProjectByInspectionToken(ctx, config.InspectionToken)
+	require.ErrorIs(t, err, datastore.ErrProjectNotFound)
+}
+
 func TestCountProjects(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -175,9 +212,12 @@ func Test_UpdateProject(t *testing.T) {
 			},
 			SSL: &datastore.SSLConfiguration{EnforceSecureEndpoints: false},
 			Strategy: &datastore.StrategyConfiguration{
-				Type:       datastore.ExponentialStrategyProvider,
-				Duration:   2434,
-				RetryCount: 5737,
+				Type:          datastore.ExponentialStrategyProvider,
+				Duration:      2434,
+				RetryCount:    5737,
+				BackoffBase:   1.5,
+				MaxInterval:   86400,
+				JitterPercent: 20,
 			},
 			Signature: &datastore.SignatureConfiguration{
 				Header: "f888fbfb",
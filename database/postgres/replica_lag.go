@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/frain-dev/convoy/database"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxReplicaLagBytes is how far, in bytes of WAL, a replica may replay
+// behind the primary before reads that prefer replicas are sent to the
+// primary instead. Zero or less turns the lag check off.
+var maxReplicaLagBytes atomic.Int64
+
+// SetMaxReplicaLag sets how many bytes of WAL a replica may lag behind the
+// primary and still serve reads.
+func SetMaxReplicaLag(bytes int64) {
+	maxReplicaLagBytes.Store(bytes)
+}
+
+// replicaLagCheckInterval is how long a replica's measured lag is reused
+// before it is measured again, so reads don't each pay for the check
+const replicaLagCheckInterval = time.Second
+
+const (
+	fetchPrimaryWALPosition = `SELECT pg_current_wal_lsn()::TEXT;`
+
+	// pg_last_wal_replay_lsn is NULL on a server that isn't replaying WAL
+	fetchReplicaLag = `SELECT COALESCE(pg_wal_lsn_diff($1::pg_lsn, pg_last_wal_replay_lsn()), 0)::BIGINT;`
+)
+
+// replicaLag caches the last measured lag of a replica
+type replicaLag struct {
+	mu        sync.Mutex
+	bytes     int64
+	checkedAt time.Time
+}
+
+// readPreferenceDB is a database that can pick a connection by read preference
+type readPreferenceDB interface {
+	GetReadDBWithPreference(ctx context.Context, pref datastore.ReadPreference) *sqlx.DB
+}
+
+// GetReadDBWithPreference returns the connection a read with pref should
+// use. PreferPrimary reads always go to the primary. Other reads go to a
+// replica like with GetReadDB, unless the replica's replay lag is above the
+// max replica lag, then they go to the primary.
+func (p *Postgres) GetReadDBWithPreference(ctx context.Context, pref datastore.ReadPreference) *sqlx.DB {
+	if pref == datastore.PreferPrimary || len(p.replicas) == 0 {
+		return p.dbx
+	}
+
+	r, err := p.getRandomReplica()
+	if err != nil || r == nil {
+		log.WithError(err).Error("failed to get random replica")
+		return p.dbx
+	}
+
+	maxLag := maxReplicaLagBytes.Load()
+	if maxLag <= 0 {
+		return r.dbx
+	}
+
+	lag, err := p.ReplicaLag(ctx, r)
+	if err != nil {
+		log.WithError(err).Errorf("failed to check the lag of replica %d, reading from the primary", r.id)
+		return p.dbx
+	}
+
+	if lag > maxLag {
+		log.Debugf("replica %d is %d bytes behind the primary, reading from the primary", r.id, lag)
+		return p.dbx
+	}
+
+	return r.dbx
+}
+
+// ReplicaLag returns how many bytes of WAL the replica has yet to replay
+// from the primary, measured at most once every replicaLagCheckInterval.
+func (p *Postgres) ReplicaLag(ctx context.Context, replica *Postgres) (int64, error) {
+	replica.lag.mu.Lock()
+	defer replica.lag.mu.Unlock()
+
+	if time.Since(replica.lag.checkedAt) < replicaLagCheckInterval {
+		return replica.lag.bytes, nil
+	}
+
+	var lsn string
+	err := p.dbx.QueryRowxContext(ctx, fetchPrimaryWALPosition).Scan(&lsn)
+	if err != nil {
+		return 0, err
+	}
+
+	var lag int64
+	err = replica.dbx.QueryRowxContext(ctx, fetchReplicaLag, lsn).Scan(&lag)
+	if err != nil {
+		return 0, err
+	}
+
+	replica.lag.bytes = lag
+	replica.lag.checkedAt = time.Now()
+
+	return lag, nil
+}
+
+// readDB returns the connection a repository read with pref should use,
+// databases that don't support read preferences serve it from their read db.
+// A preference set on ctx with datastore.WithReadPreference overrides pref.
+func readDB(ctx context.Context, db database.Database, pref datastore.ReadPreference) *sqlx.DB {
+	pref = datastore.ReadPreferenceFromContext(ctx, pref)
+
+	if pdb, ok := db.(readPreferenceDB); ok {
+		return pdb.GetReadDBWithPreference(ctx, pref)
+	}
+
+	if pref == datastore.PreferPrimary {
+		return db.GetDB()
+	}
+
+	return db.GetReadDB()
+}
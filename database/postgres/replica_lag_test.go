@@ -0,0 +1,54 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func Test_GetReadDBWithPreference(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	primary := &Postgres{dbx: db.GetDB()}
+	ctx := context.Background()
+
+	// without replicas every read goes to the primary
+	require.Same(t, primary.dbx, primary.GetReadDBWithPreference(ctx, datastore.PreferReplica))
+
+	// the primary stands in for a replica here, it isn't replaying WAL so it has no lag
+	replica := &Postgres{id: 1, dbx: sqlx.NewDb(db.GetDB().DB, "pgx"), lag: &replicaLag{}}
+	primary.replicas = []*Postgres{replica}
+	primary.randGen = _db.randGen
+
+	defer SetMaxReplicaLag(0)
+
+	require.Same(t, primary.dbx, primary.GetReadDBWithPreference(ctx, datastore.PreferPrimary))
+	require.Same(t, replica.dbx, primary.GetReadDBWithPreference(ctx, datastore.PreferReplica))
+
+	SetMaxReplicaLag(1024)
+
+	lag, err := primary.ReplicaLag(ctx, replica)
+	require.NoError(t, err)
+	require.Zero(t, lag)
+	require.Same(t, replica.dbx, primary.GetReadDBWithPreference(ctx, datastore.PreferReplica))
+
+	// a replica lagging past the threshold is skipped until it catches up
+	replica.lag.bytes, replica.lag.checkedAt = 4096, time.Now()
+	require.Same(t, primary.dbx, primary.GetReadDBWithPreference(ctx, datastore.PreferReplica))
+
+	replica.lag.checkedAt = time.Time{}
+	require.Same(t, replica.dbx, primary.GetReadDBWithPreference(ctx, datastore.PreferReplica))
+
+	// a preference on the context overrides the one the read is made with
+	require.Same(t, replica.dbx, readDB(ctx, primary, datastore.PreferReplica))
+	require.Same(t, primary.dbx, readDB(datastore.WithPrimaryReads(ctx), primary, datastore.PreferReplica))
+}
@@ -18,8 +18,9 @@ import (
 const (
 	createSource = `
     INSERT INTO convoy.sources (id,source_verifier_id,name,type,mask_id,provider,is_disabled,forward_headers,project_id,
-                                pub_sub,custom_response_body,custom_response_content_type,idempotency_keys, body_function, header_function)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15);
+                                pub_sub,custom_response_body,custom_response_content_type,idempotency_keys, body_function, header_function,
+                                ingest_failure_threshold)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16);
     `
 
 	createSourceVerifier = `
@@ -46,10 +47,46 @@ const (
 	idempotency_keys = $12,
 	body_function = $13,
 	header_function = $14,
+	ingest_failure_threshold = $15,
+	paused = $16,
+	consecutive_ingest_failures = CASE WHEN $16 THEN consecutive_ingest_failures ELSE 0 END,
 	updated_at = NOW()
 	WHERE id = $1 AND deleted_at IS NULL ;
 	`
 
+	// unpausing a source gives it a fresh run of ingests before it's
+	// paused again
+	updateSourcePaused = `
+	UPDATE convoy.sources SET
+	paused = $3,
+	consecutive_ingest_failures = CASE WHEN $3 THEN consecutive_ingest_failures ELSE 0 END,
+	updated_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
+	`
+
+	// only pauses a running source, so of concurrent callers exactly one
+	// gets the row back
+	pauseSource = `
+	UPDATE convoy.sources SET
+	paused = true,
+	updated_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND paused = false AND deleted_at IS NULL
+	RETURNING id;
+	`
+
+	incrementSourceIngestFailures = `
+	UPDATE convoy.sources SET
+	consecutive_ingest_failures = consecutive_ingest_failures + 1
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL
+	RETURNING consecutive_ingest_failures;
+	`
+
+	resetSourceIngestFailures = `
+	UPDATE convoy.sources SET
+	consecutive_ingest_failures = 0
+	WHERE id = $1 AND project_id = $2 AND consecutive_ingest_failures > 0 AND deleted_at IS NULL;
+	`
+
 	updateSourceVerifierById = `
 	UPDATE convoy.source_verifiers SET
         type=$2,
@@ -79,6 +116,9 @@ const (
 		s.project_id,
 		s.body_function,
 		s.header_function,
+		s.ingest_failure_threshold,
+		s.consecutive_ingest_failures,
+		s.paused,
 		COALESCE(s.source_verifier_id, '') AS source_verifier_id,
 		COALESCE(s.custom_response_body, '') AS "custom_response.body",
 		COALESCE(s.custom_response_content_type, '') AS "custom_response.content_type",
@@ -111,6 +151,9 @@ const (
 		idempotency_keys,
 		body_function,
 		header_function,
+		ingest_failure_threshold,
+		consecutive_ingest_failures,
+		paused,
 		project_id,
 		created_at,
 		updated_at
@@ -250,6 +293,7 @@ func (s *sourceRepo) CreateSource(ctx context.Context, source *datastore.Source)
 		source.Provider, source.IsDisabled, pq.Array(source.ForwardHeaders), source.ProjectID,
 		source.PubSub, source.CustomResponse.Body, source.CustomResponse.ContentType,
 		source.IdempotencyKeys, source.BodyFunction, source.HeaderFunction,
+		source.IngestFailureThreshold,
 	)
 	if err != nil {
 		return err
@@ -284,6 +328,7 @@ func (s *sourceRepo) UpdateSource(ctx context.Context, projectID string, source
 		source.Provider, source.IsDisabled, source.ForwardHeaders, projectID,
 		source.PubSub, source.CustomResponse.Body, source.CustomResponse.ContentType,
 		source.IdempotencyKeys, source.BodyFunction, source.HeaderFunction,
+		source.IngestFailureThreshold, source.Paused,
 	)
 	if err != nil {
 		return err
@@ -378,6 +423,62 @@ func (s *sourceRepo) FindSourceByMaskID(ctx context.Context, maskID string) (*da
 	return source, nil
 }
 
+// UpdateSourcePaused pauses or unpauses the source's ingest, unpausing it
+// resets its consecutive ingest failures
+func (s *sourceRepo) UpdateSourcePaused(ctx context.Context, projectID, sourceID string, paused bool) error {
+	result, err := s.db.GetDB().ExecContext(ctx, updateSourcePaused, sourceID, projectID, paused)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return datastore.ErrSourceNotFound
+	}
+
+	return nil
+}
+
+// PauseSource pauses the source's ingest and reports whether this call
+// paused it, it's false when the source was already paused
+func (s *sourceRepo) PauseSource(ctx context.Context, projectID, sourceID string) (bool, error) {
+	var id string
+	err := s.db.GetDB().QueryRowxContext(ctx, pauseSource, sourceID, projectID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IncrementSourceIngestFailures counts another ingest failure in a row for
+// the source and returns how many there have been
+func (s *sourceRepo) IncrementSourceIngestFailures(ctx context.Context, projectID, sourceID string) (uint64, error) {
+	var failures uint64
+	err := s.db.GetDB().QueryRowxContext(ctx, incrementSourceIngestFailures, sourceID, projectID).Scan(&failures)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, datastore.ErrSourceNotFound
+		}
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+// ResetSourceIngestFailures ends the source's run of ingest failures
+func (s *sourceRepo) ResetSourceIngestFailures(ctx context.Context, projectID, sourceID string) error {
+	_, err := s.db.GetDB().ExecContext(ctx, resetSourceIngestFailures, sourceID, projectID)
+	return err
+}
+
 func (s *sourceRepo) DeleteSourceByID(ctx context.Context, projectId string, id, sourceVerifierId string) error {
 	tx, err := s.db.GetDB().BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -130,6 +130,49 @@ func Test_UpdateSource(t *testing.T) {
 	require.Equal(t, source, newSource)
 }
 
+func Test_SourceIngestFailures(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	sourceRepo := NewSourceRepo(db)
+	source := generateSource(t, db)
+	source.IngestFailureThreshold = 2
+
+	require.NoError(t, sourceRepo.CreateSource(context.Background(), source))
+
+	failures, err := sourceRepo.IncrementSourceIngestFailures(context.Background(), source.ProjectID, source.UID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), failures)
+
+	failures, err = sourceRepo.IncrementSourceIngestFailures(context.Background(), source.ProjectID, source.UID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), failures)
+
+	paused, err := sourceRepo.PauseSource(context.Background(), source.ProjectID, source.UID)
+	require.NoError(t, err)
+	require.True(t, paused)
+
+	// only the first pause reports pausing the source
+	paused, err = sourceRepo.PauseSource(context.Background(), source.ProjectID, source.UID)
+	require.NoError(t, err)
+	require.False(t, paused)
+
+	newSource, err := sourceRepo.FindSourceByID(context.Background(), source.ProjectID, source.UID)
+	require.NoError(t, err)
+	require.True(t, newSource.Paused)
+	require.Equal(t, uint64(2), newSource.ConsecutiveIngestFailures)
+	require.Equal(t, uint64(2), newSource.IngestFailureThreshold)
+
+	require.NoError(t, sourceRepo.ResetSourceIngestFailures(context.Background(), source.ProjectID, source.UID))
+
+	newSource, err = sourceRepo.FindSourceByID(context.Background(), source.ProjectID, source.UID)
+	require.NoError(t, err)
+	require.Zero(t, newSource.ConsecutiveIngestFailures)
+
+	err = sourceRepo.UpdateSourcePaused(context.Background(), source.ProjectID, ulid.Make().String(), true)
+	require.ErrorIs(t, err, datastore.ErrSourceNotFound)
+}
+
 func Test_DeleteSource(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
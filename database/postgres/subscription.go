@@ -31,13 +31,11 @@ const (
 	filter_config_filter_is_flattened,
 	rate_limit_config_count,rate_limit_config_duration,function,
 	filter_config_filter_raw_headers, filter_config_filter_raw_body,
-	delivery_mode
+	delivery_mode, header_allow_list, payload_transform, diff_key
 	)
     VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,
-        CASE 
-            WHEN $22 = '' OR $22 IS NULL THEN 'at_least_once'::convoy.delivery_mode 
-            ELSE $22::convoy.delivery_mode 
-        END
+        NULLIF($22, '')::convoy.delivery_mode,
+        $23, $24, $25
     );
     `
 
@@ -61,9 +59,12 @@ const (
 	filter_config_filter_raw_headers=$18,
 	filter_config_filter_raw_body=$19,
 	delivery_mode=CASE 
-        WHEN $20 = '' OR $20 IS NULL THEN COALESCE(delivery_mode, 'at_least_once'::convoy.delivery_mode)
+        WHEN $20 = '' OR $20 IS NULL THEN delivery_mode
         ELSE $20::convoy.delivery_mode 
     END,
+	header_allow_list=$21,
+	payload_transform=$22,
+	diff_key=$23,
     updated_at=now()
     WHERE id = $1 AND project_id = $2
 	AND deleted_at IS NULL;
@@ -75,7 +76,11 @@ const (
 	s.project_id,
 	s.created_at,
 	s.updated_at, s.function,
-	COALESCE(s.delivery_mode, 'at_least_once'::convoy.delivery_mode) AS "delivery_mode",
+	COALESCE(s.delivery_mode::TEXT, '') AS "delivery_mode",
+	COALESCE(s.header_allow_list, '{}') AS "header_allow_list",
+	s.paused,
+	s.payload_transform,
+	s.diff_key,
 
 	COALESCE(s.endpoint_id,'') AS "endpoint_id",
 	COALESCE(s.device_id,'') AS "device_id",
@@ -291,6 +296,12 @@ const (
 	WHERE id = $1 AND project_id = $2;
 	`
 
+	updateSubscriptionPaused = `
+	UPDATE convoy.subscriptions SET
+	paused = $3, updated_at = NOW()
+	WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
+	`
+
 	upsertSubscriptionEventTypes = `
 	INSERT INTO convoy.event_types (id, name, project_id, description, category) 
 	VALUES (:id, :name, :project_id, :description, :category) 
@@ -595,7 +606,8 @@ func (s *subscriptionRepo) CreateSubscription(ctx context.Context, projectID str
 		fc.EventTypes, fc.Filter.Headers, fc.Filter.Body, fc.Filter.IsFlattened,
 		rlc.Count, rlc.Duration, subscription.Function,
 		subscription.FilterConfig.Filter.RawHeaders, subscription.FilterConfig.Filter.RawBody,
-		subscription.DeliveryMode,
+		subscription.DeliveryMode, subscription.HeaderAllowList, subscription.PayloadTransform,
+		subscription.DiffKey,
 	)
 	if err != nil {
 		return err
@@ -697,7 +709,8 @@ func (s *subscriptionRepo) UpdateSubscription(ctx context.Context, projectID str
 		fc.EventTypes, fc.Filter.Headers, fc.Filter.Body, fc.Filter.IsFlattened,
 		rlc.Count, rlc.Duration, subscription.Function,
 		fc.Filter.RawHeaders, fc.Filter.RawBody,
-		subscription.DeliveryMode,
+		subscription.DeliveryMode, subscription.HeaderAllowList, subscription.PayloadTransform,
+		subscription.DiffKey,
 	)
 	if err != nil {
 		return err
@@ -890,6 +903,24 @@ func (s *subscriptionRepo) DeleteSubscription(ctx context.Context, projectID str
 	return nil
 }
 
+func (s *subscriptionRepo) UpdateSubscriptionPaused(ctx context.Context, projectID, subscriptionID string, paused bool) error {
+	result, err := s.db.GetDB().ExecContext(ctx, updateSubscriptionPaused, subscriptionID, projectID, paused)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return datastore.ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
 func (s *subscriptionRepo) FindSubscriptionByID(ctx context.Context, projectID string, subscriptionID string) (*datastore.Subscription, error) {
 	subscription := &datastore.Subscription{}
 	key, err := s.km.GetCurrentKeyFromCache()
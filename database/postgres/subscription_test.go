@@ -220,6 +220,37 @@ func Test_DeleteSubscription(t *testing.T) {
 	require.Equal(t, err, datastore.ErrSubscriptionNotFound)
 }
 
+func Test_UpdateSubscriptionPaused(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	subRepo := NewSubscriptionRepo(db)
+
+	project := seedProject(t, db)
+	source := seedSource(t, db)
+	endpoint := seedEndpoint(t, db)
+
+	newSub := generateSubscription(project, source, endpoint, &datastore.Device{})
+	require.NoError(t, subRepo.CreateSubscription(context.Background(), project.UID, newSub))
+
+	err := subRepo.UpdateSubscriptionPaused(context.Background(), project.UID, newSub.UID, true)
+	require.NoError(t, err)
+
+	dbSub, err := subRepo.FindSubscriptionByID(context.Background(), project.UID, newSub.UID)
+	require.NoError(t, err)
+	require.True(t, dbSub.Paused)
+
+	err = subRepo.UpdateSubscriptionPaused(context.Background(), project.UID, newSub.UID, false)
+	require.NoError(t, err)
+
+	dbSub, err = subRepo.FindSubscriptionByID(context.Background(), project.UID, newSub.UID)
+	require.NoError(t, err)
+	require.False(t, dbSub.Paused)
+
+	err = subRepo.UpdateSubscriptionPaused(context.Background(), project.UID, ulid.Make().String(), true)
+	require.Equal(t, datastore.ErrSubscriptionNotFound, err)
+}
+
 func Test_CreateSubscription(t *testing.T) {
 	db, closeFn := getDB(t)
 	defer closeFn()
@@ -575,7 +606,7 @@ func Test_DeliveryModes(t *testing.T) {
 		{
 			name:                "Empty String",
 			deliveryMode:        "",
-			expectedInitial:     datastore.AtLeastOnceDeliveryMode, // default value
+			expectedInitial:     "", // inherits the project's delivery mode
 			updateTo:            datastore.AtMostOnceDeliveryMode,
 			expectedAfterUpdate: datastore.AtMostOnceDeliveryMode,
 		},
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// fanOutTxLimiter bounds the number of CreateEventDeliveries transactions
+// open at once across the instance, so wide fan-outs arriving together
+// can't exhaust the connection pool.
+var fanOutTxLimiter atomic.Pointer[txLimiter]
+
+// SetMaxFanOutTransactions caps the number of concurrent fan-out
+// transactions. A limit of zero or less removes the cap.
+func SetMaxFanOutTransactions(limit int) {
+	if limit <= 0 {
+		fanOutTxLimiter.Store(nil)
+		return
+	}
+
+	fanOutTxLimiter.Store(newTxLimiter(limit))
+}
+
+type txLimiter struct {
+	slots chan struct{}
+}
+
+func newTxLimiter(limit int) *txLimiter {
+	return &txLimiter{slots: make(chan struct{}, limit)}
+}
+
+// acquire blocks until a slot is free or ctx is done.
+func (l *txLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *txLimiter) release() {
+	if l == nil {
+		return
+	}
+
+	<-l.slots
+}
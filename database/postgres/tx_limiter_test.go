@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxLimiter_BoundsConcurrentTransactions(t *testing.T) {
+	const limit = 3
+	l := newTxLimiter(limit)
+
+	var inFlight, maxInFlight atomic.Int64
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			require.NoError(t, l.acquire(context.Background()))
+			defer l.release()
+
+			n := inFlight.Add(1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+
+	wg.Wait()
+
+	require.LessOrEqual(t, maxInFlight.Load(), int64(limit))
+	require.Zero(t, inFlight.Load())
+}
+
+func TestTxLimiter_AcquireRespectsContext(t *testing.T) {
+	l := newTxLimiter(1)
+	require.NoError(t, l.acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, l.acquire(ctx), context.DeadlineExceeded)
+
+	l.release()
+	require.NoError(t, l.acquire(context.Background()))
+}
+
+func TestSetMaxFanOutTransactions(t *testing.T) {
+	t.Cleanup(func() { SetMaxFanOutTransactions(0) })
+
+	SetMaxFanOutTransactions(5)
+	require.Equal(t, 5, cap(fanOutTxLimiter.Load().slots))
+
+	SetMaxFanOutTransactions(0)
+	require.Nil(t, fanOutTxLimiter.Load())
+
+	// a nil limiter never blocks
+	var l *txLimiter
+	require.NoError(t, l.acquire(context.Background()))
+	l.release()
+}
@@ -22,6 +22,9 @@ type Filter struct {
 	IdempotencyKey string
 	Status         []EventDeliveryStatus
 	SearchParams   SearchParams
+
+	// ResponseStatusCodes matches deliveries by their latest attempt's status code
+	ResponseStatusCodes []int
 }
 
 func (f *Filter) Scan(v interface{}) error {
@@ -0,0 +1,57 @@
+package datastore
+
+import "context"
+
+// EventDeliveryIterator walks a project's deliveries matching a filter in id
+// order, a page at a time. Each page starts after the last id of the page
+// before it, so pages deep into the table are as fast as the first and
+// deliveries created while iterating don't shift the pages.
+type EventDeliveryIterator struct {
+	repo      EventDeliveryRepository
+	projectID string
+	filter    *EventDeliveryFilter
+	cursor    string
+	pageSize  int
+	done      bool
+}
+
+// NewEventDeliveryIterator returns an iterator starting after the delivery
+// with id afterID, an empty afterID starts from the first delivery.
+func NewEventDeliveryIterator(repo EventDeliveryRepository, projectID string, filter *EventDeliveryFilter, afterID string, pageSize int) *EventDeliveryIterator {
+	return &EventDeliveryIterator{
+		repo:      repo,
+		projectID: projectID,
+		filter:    filter,
+		cursor:    afterID,
+		pageSize:  pageSize,
+	}
+}
+
+// Next returns the next page of deliveries, an empty page means the
+// iterator is done.
+func (it *EventDeliveryIterator) Next(ctx context.Context) ([]EventDelivery, error) {
+	if it.done {
+		return nil, nil
+	}
+
+	deliveries, err := it.repo.FindEventDeliveriesAfterID(ctx, it.projectID, it.filter, it.cursor, it.pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// a short page is the last one, there's no need to ask for another
+	if len(deliveries) < it.pageSize {
+		it.done = true
+	}
+
+	if len(deliveries) > 0 {
+		it.cursor = deliveries[len(deliveries)-1].UID
+	}
+
+	return deliveries, nil
+}
+
+// Cursor returns the id of the last delivery the iterator returned
+func (it *EventDeliveryIterator) Cursor() string {
+	return it.cursor
+}
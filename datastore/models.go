@@ -5,6 +5,7 @@ import (
 
 	"database/sql/driver"
 
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -40,8 +41,18 @@ type Pageable struct {
 	Sort       string        `json:"sort"`
 	PrevCursor string        `json:"prev_page_cursor"`
 	NextCursor string        `json:"next_page_cursor"`
+
+	// SortField selects the column(s) the cursors paginate on, defaults to IDSortField
+	SortField string `json:"sort_field"`
 }
 
+const (
+	IDSortField        = "id"
+	CreatedAtSortField = "created_at"
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
 type PageDirection string
 
 const (
@@ -69,6 +80,39 @@ func (p Pageable) Limit() int {
 	return p.PerPage + 1
 }
 
+// SortByCreatedAt reports whether the page is keyed on the compound
+// (created_at, id) cursor rather than on the id alone.
+func (p Pageable) SortByCreatedAt() bool {
+	return p.SortField == CreatedAtSortField
+}
+
+// EncodeCreatedAtCursor builds the opaque cursor token for the
+// (created_at, id) pagination path.
+func EncodeCreatedAtCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCreatedAtCursor is the inverse of EncodeCreatedAtCursor.
+func DecodeCreatedAtCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	return t, id, nil
+}
+
 func (p *Pageable) SetCursors() {
 	switch p.Sort {
 	case "ASC":
@@ -97,6 +141,9 @@ type PrevRowCount struct {
 	Exists bool
 }
 
+// Build sets the page cursors from items, the cursor of every fetched row in
+// page order. These are the row ids, or EncodeCreatedAtCursor tokens when the
+// page is sorted by created_at, and are handed back to the caller unchanged.
 func (p *PaginationData) Build(pageable Pageable, items []string) *PaginationData {
 	p.PerPage = int64(pageable.PerPage)
 
@@ -153,6 +200,56 @@ type SearchParams struct {
 	CreatedAtEnd   int64 `json:"created_at_end" bson:"created_at_end"`
 }
 
+// ReadPreference picks which database a read is served from when read
+// replicas are set up.
+type ReadPreference string
+
+const (
+	// PreferReplica reads from a replica, unless it has fallen too far
+	// behind the primary.
+	PreferReplica ReadPreference = "replica"
+
+	// PreferPrimary reads from the primary, for reads that must see
+	// writes made moments before.
+	PreferPrimary ReadPreference = "primary"
+)
+
+// ReadPreferenceAfterWrite returns PreferPrimary for reads made within
+// window of lastWrite, when a replica may not have caught up with the write
+// yet, and PreferReplica otherwise. A window of 0 always prefers replicas.
+func ReadPreferenceAfterWrite(lastWrite time.Time, window time.Duration) ReadPreference {
+	if window > 0 && time.Since(lastWrite) < window {
+		return PreferPrimary
+	}
+
+	return PreferReplica
+}
+
+type readPreferenceCtxKey struct{}
+
+// WithReadPreference returns a copy of ctx whose repository reads are served
+// with pref, over the preference each read would otherwise be made with.
+func WithReadPreference(ctx context.Context, pref ReadPreference) context.Context {
+	return context.WithValue(ctx, readPreferenceCtxKey{}, pref)
+}
+
+// WithPrimaryReads returns a copy of ctx whose repository reads all go to
+// the primary. Handlers use it after a write so the reads that follow in the
+// same flow see the write, even those that would otherwise use a replica.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return WithReadPreference(ctx, PreferPrimary)
+}
+
+// ReadPreferenceFromContext returns the read preference set on ctx with
+// WithReadPreference, or def when none was set.
+func ReadPreferenceFromContext(ctx context.Context, def ReadPreference) ReadPreference {
+	if pref, ok := ctx.Value(readPreferenceCtxKey{}).(ReadPreference); ok && pref != "" {
+		return pref
+	}
+
+	return def
+}
+
 type (
 	StrategyProvider string
 	ProjectType      string
@@ -201,6 +298,7 @@ const (
 
 const (
 	APIKeyAuthentication EndpointAuthenticationType = "api_key"
+	DigestAuthentication EndpointAuthenticationType = "digest"
 )
 
 const (
@@ -302,6 +400,8 @@ var (
 
 	DefaultSSLConfig = SSLConfiguration{EnforceSecureEndpoints: false}
 
+	DefaultDeadLetterReplayConfig = DeadLetterReplayConfiguration{Policy: ManualDeadLetterReplayPolicy}
+
 	DefaultStrategyConfig = StrategyConfiguration{
 		Type:       LinearStrategyProvider,
 		Duration:   100,
@@ -366,6 +466,37 @@ const (
 	PausedEndpointStatus   EndpointStatus = "paused"
 )
 
+type EndpointBodyFormat string
+
+const (
+	// RawEndpointBodyFormat sends the event payload as the request body.
+	RawEndpointBodyFormat EndpointBodyFormat = "raw"
+	// EnvelopeEndpointBodyFormat wraps the event payload in an envelope
+	// alongside the delivery metadata, i.e. {"metadata": {...}, "data": {...}}.
+	EnvelopeEndpointBodyFormat EndpointBodyFormat = "envelope"
+)
+
+const (
+	// JSONContentType is the content type payloads are sent with by default
+	JSONContentType = "application/json"
+	// FormContentType sends payloads form encoded, see EndpointFormEncoding
+	FormContentType = "application/x-www-form-urlencoded"
+)
+
+// EndpointFormEncoding is how payloads are form encoded for endpoints with
+// the form content type
+type EndpointFormEncoding string
+
+const (
+	// FieldsEndpointFormEncoding sends each top-level field of the payload as
+	// a form field, strings as is and other values as JSON. Payloads that
+	// aren't JSON objects are sent as with PayloadEndpointFormEncoding.
+	FieldsEndpointFormEncoding EndpointFormEncoding = "fields"
+	// PayloadEndpointFormEncoding sends the whole payload as JSON in a single
+	// form field named payload.
+	PayloadEndpointFormEncoding EndpointFormEncoding = "payload"
+)
+
 type (
 	EndpointStatus string
 	Secrets        []Secret
@@ -415,17 +546,29 @@ func (s Secrets) Value() (driver.Value, error) {
 }
 
 type Endpoint struct {
-	UID                string  `json:"uid" db:"id"`
-	ProjectID          string  `json:"project_id" db:"project_id"`
-	OwnerID            string  `json:"owner_id,omitempty" db:"owner_id"`
-	Url                string  `json:"url" db:"url"`
-	Name               string  `json:"name" db:"name"`
-	Secrets            Secrets `json:"secrets" db:"secrets"`
-	AdvancedSignatures bool    `json:"advanced_signatures" db:"advanced_signatures"`
-	Description        string  `json:"description" db:"description"`
-	SlackWebhookURL    string  `json:"slack_webhook_url,omitempty" db:"slack_webhook_url"`
-	SupportEmail       string  `json:"support_email,omitempty" db:"support_email"`
-	AppID              string  `json:"-" db:"app_id"` // Deprecated but necessary for backward compatibility
+	UID                string             `json:"uid" db:"id"`
+	ProjectID          string             `json:"project_id" db:"project_id"`
+	OwnerID            string             `json:"owner_id,omitempty" db:"owner_id"`
+	Url                string             `json:"url" db:"url"`
+	Name               string             `json:"name" db:"name"`
+	Secrets            Secrets            `json:"secrets" db:"secrets"`
+	AdvancedSignatures bool               `json:"advanced_signatures" db:"advanced_signatures"`
+	BodyFormat         EndpointBodyFormat `json:"body_format" db:"body_format"`
+	Description        string             `json:"description" db:"description"`
+	SlackWebhookURL    string             `json:"slack_webhook_url,omitempty" db:"slack_webhook_url"`
+	SupportEmail       string             `json:"support_email,omitempty" db:"support_email"`
+	AppID              string             `json:"-" db:"app_id"` // Deprecated but necessary for backward compatibility
+
+	// PinnedSignatureVersion is the only project signature version (1-indexed)
+	// sent to the endpoint, when it is set
+	PinnedSignatureVersion int    `json:"pinned_signature_version" db:"pinned_signature_version"`
+	HttpMethod             string `json:"http_method" db:"http_method"`
+
+	// ContentType is the content type payloads are sent with, JSON by
+	// default. Payloads are form encoded as FormEncoding says for the form
+	// content type and sent as JSON for any other.
+	ContentType  string               `json:"content_type" db:"content_type"`
+	FormEncoding EndpointFormEncoding `json:"form_encoding" db:"form_encoding"`
 
 	Status         EndpointStatus          `json:"status" db:"status"`
 	HttpTimeout    uint64                  `json:"http_timeout" db:"http_timeout"`
@@ -436,11 +579,61 @@ type Endpoint struct {
 	RateLimitDuration uint64  `json:"rate_limit_duration" db:"rate_limit_duration"`
 	FailureRate       float64 `json:"failure_rate" db:"-"`
 
+	// MaxConcurrentDeliveries caps how many deliveries can be in flight to
+	// the endpoint at once, 0 leaves it uncapped
+	MaxConcurrentDeliveries int `json:"max_concurrent_deliveries" db:"max_concurrent_deliveries"`
+
+	// MinDeliveryIntervalMs is the least time in milliseconds between the
+	// start of two deliveries to the endpoint, 0 leaves them unspaced
+	MinDeliveryIntervalMs uint64 `json:"min_delivery_interval_ms" db:"min_delivery_interval_ms"`
+
+	// RetryAfterFloorSeconds and RetryAfterCeilingSeconds clamp the retry
+	// delay a Retry-After header from the endpoint asks for, 0 leaves
+	// that side unclamped
+	RetryAfterFloorSeconds   uint64 `json:"retry_after_floor_seconds" db:"retry_after_floor_seconds"`
+	RetryAfterCeilingSeconds uint64 `json:"retry_after_ceiling_seconds" db:"retry_after_ceiling_seconds"`
+
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
 	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
 }
 
+// GetBodyFormat returns how payloads are sent to the endpoint, defaulting to raw.
+func (e *Endpoint) GetBodyFormat() EndpointBodyFormat {
+	if e.BodyFormat == "" {
+		return RawEndpointBodyFormat
+	}
+
+	return e.BodyFormat
+}
+
+// GetHttpMethod returns the method webhooks are sent with, defaulting to POST.
+func (e *Endpoint) GetHttpMethod() string {
+	if e.HttpMethod == "" {
+		return http.MethodPost
+	}
+
+	return e.HttpMethod
+}
+
+// GetContentType returns the content type payloads are sent with, defaulting to JSON.
+func (e *Endpoint) GetContentType() string {
+	if e.ContentType == "" {
+		return JSONContentType
+	}
+
+	return e.ContentType
+}
+
+// GetFormEncoding returns how payloads are form encoded, defaulting to fields.
+func (e *Endpoint) GetFormEncoding() EndpointFormEncoding {
+	if e.FormEncoding == "" {
+		return FieldsEndpointFormEncoding
+	}
+
+	return e.FormEncoding
+}
+
 func (e *Endpoint) FindSecret(secretID string) *Secret {
 	for i := range e.Secrets {
 		secret := &e.Secrets[i]
@@ -459,13 +652,36 @@ type EndpointConfig struct {
 }
 
 func (e *Endpoint) GetAuthConfig() EndpointAuthentication {
-	if e.Authentication != nil {
-		if e.Authentication.ApiKey != nil {
-			return *e.Authentication
-		}
+	ac := EndpointAuthentication{ApiKey: &ApiKey{}, DigestAuth: &DigestAuth{}}
+	if e.Authentication == nil {
+		return ac
+	}
+
+	if e.Authentication.ApiKey != nil {
+		ac.Type = e.Authentication.Type
+		ac.ApiKey = e.Authentication.ApiKey
+	}
+
+	if e.Authentication.Type == DigestAuthentication && e.Authentication.DigestAuth != nil {
+		ac.Type = e.Authentication.Type
+		ac.DigestAuth = e.Authentication.DigestAuth
+	}
+
+	return ac
+}
+
+// GetDigestAuth returns the endpoint's digest credentials, it is nil when
+// the endpoint doesn't use digest authentication
+func (e *Endpoint) GetDigestAuth() *DigestAuth {
+	if e.Authentication == nil || e.Authentication.Type != DigestAuthentication {
+		return nil
 	}
 
-	return EndpointAuthentication{ApiKey: &ApiKey{}}
+	if e.Authentication.DigestAuth == nil || e.Authentication.DigestAuth.Username == "" {
+		return nil
+	}
+
+	return e.Authentication.DigestAuth
 }
 
 func (e *Endpoint) GetActiveSecretIndex() (int, error) {
@@ -477,10 +693,47 @@ func (e *Endpoint) GetActiveSecretIndex() (int, error) {
 	return 0, ErrNoActiveSecret
 }
 
+// HasSigningSecret reports whether the endpoint has a secret deliveries can
+// be signed with, deleted secrets aren't used for signing.
+func (e *Endpoint) HasSigningSecret() bool {
+	for _, secret := range e.Secrets {
+		if secret.DeletedAt.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretEpoch returns the rotation epoch of the endpoint's active secret.
+func (e *Endpoint) SecretEpoch() int64 {
+	idx, err := e.GetActiveSecretIndex()
+	if err != nil {
+		return 0
+	}
+
+	return e.Secrets[idx].Epoch
+}
+
+// NextSecretEpoch returns the rotation epoch of the endpoint's next secret.
+func (e *Endpoint) NextSecretEpoch() int64 {
+	var next int64
+	for _, secret := range e.Secrets {
+		if secret.Epoch >= next {
+			next = secret.Epoch + 1
+		}
+	}
+
+	return next
+}
+
 type Secret struct {
 	UID   string `json:"uid" db:"id"`
 	Value string `json:"value" db:"value"`
 
+	// Epoch counts the rotations that led to this secret, the endpoint's
+	// first secret is epoch 0 and each rotation's new secret is one more
+	Epoch int64 `json:"epoch" db:"epoch"`
+
 	ExpiresAt null.Time `json:"expires_at,omitempty" db:"expires_at,omitempty" swaggertype:"string"`
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
@@ -488,8 +741,9 @@ type Secret struct {
 }
 
 type EndpointAuthentication struct {
-	Type   EndpointAuthenticationType `json:"type,omitempty" db:"type" valid:"optional,in(api_key)~unsupported authentication type"`
-	ApiKey *ApiKey                    `json:"api_key" db:"api_key"`
+	Type       EndpointAuthenticationType `json:"type,omitempty" db:"type" valid:"optional,in(api_key|digest)~unsupported authentication type"`
+	ApiKey     *ApiKey                    `json:"api_key" db:"api_key"`
+	DigestAuth *DigestAuth                `json:"digest_auth,omitempty" db:"digest_auth"`
 }
 
 var (
@@ -550,17 +804,54 @@ func (s SignatureVersions) Value() (driver.Value, error) {
 }
 
 type ProjectConfig struct {
-	MaxIngestSize                 uint64                  `json:"max_payload_read_size" db:"max_payload_read_size"`
-	ReplayAttacks                 bool                    `json:"replay_attacks_prevention_enabled" db:"replay_attacks_prevention_enabled"`
-	AddEventIDTraceHeaders        bool                    `json:"add_event_id_trace_headers"`
-	DisableEndpoint               bool                    `json:"disable_endpoint" db:"disable_endpoint"`
-	MultipleEndpointSubscriptions bool                    `json:"multiple_endpoint_subscriptions" db:"multiple_endpoint_subscriptions"`
-	SearchPolicy                  string                  `json:"search_policy" db:"search_policy"`
-	SSL                           *SSLConfiguration       `json:"ssl" db:"ssl"`
-	RateLimit                     *RateLimitConfiguration `json:"ratelimit" db:"ratelimit"`
-	Strategy                      *StrategyConfiguration  `json:"strategy" db:"strategy"`
-	Signature                     *SignatureConfiguration `json:"signature" db:"signature"`
-	MetaEvent                     *MetaEventConfiguration `json:"meta_event" db:"meta_event"`
+	MaxIngestSize                 uint64                         `json:"max_payload_read_size" db:"max_payload_read_size"`
+	ReplayAttacks                 bool                           `json:"replay_attacks_prevention_enabled" db:"replay_attacks_prevention_enabled"`
+	AddEventIDTraceHeaders        bool                           `json:"add_event_id_trace_headers"`
+	DisableEndpoint               bool                           `json:"disable_endpoint" db:"disable_endpoint"`
+	MultipleEndpointSubscriptions bool                           `json:"multiple_endpoint_subscriptions" db:"multiple_endpoint_subscriptions"`
+	SearchPolicy                  string                         `json:"search_policy" db:"search_policy"`
+	SSL                           *SSLConfiguration              `json:"ssl" db:"ssl"`
+	RateLimit                     *RateLimitConfiguration        `json:"ratelimit" db:"ratelimit"`
+	Strategy                      *StrategyConfiguration         `json:"strategy" db:"strategy"`
+	Signature                     *SignatureConfiguration        `json:"signature" db:"signature"`
+	MetaEvent                     *MetaEventConfiguration        `json:"meta_event" db:"meta_event"`
+	DeadLetterReplay              *DeadLetterReplayConfiguration `json:"dead_letter_replay" db:"dead_letter_replay"`
+
+	// ContentIdempotencyKeys derives the idempotency key sent with deliveries
+	// from the event's content, see dedup.ContentChecksum
+	ContentIdempotencyKeys bool `json:"content_idempotency_keys" db:"content_idempotency_keys"`
+
+	// MaxEventAgeSeconds rejects ingested events whose declared timestamp is
+	// older than it, 0 falls back to the instance setting
+	MaxEventAgeSeconds uint64 `json:"max_event_age_seconds" db:"max_event_age_seconds"`
+
+	// MaxRetrySeconds caps the delay between retries of the project's
+	// deliveries, 0 falls back to the instance setting
+	MaxRetrySeconds uint64 `json:"max_retry_seconds" db:"max_retry_seconds"`
+
+	// MetadataHeaders lists the event metadata headers sent with the
+	// project's deliveries, see DeliveryMetadataHeader
+	MetadataHeaders pq.StringArray `json:"metadata_headers" db:"metadata_headers"`
+
+	// DeliveryMode is the delivery mode of subscriptions that don't set
+	// their own, empty means at least once
+	DeliveryMode DeliveryMode `json:"delivery_mode" db:"delivery_mode"`
+
+	// IngestRateLimit caps how many events a second the project can
+	// ingest, apart from the instance's ingest rate, 0 is unlimited
+	IngestRateLimit uint64 `json:"ingest_rate_limit" db:"ingest_rate_limit"`
+
+	// MaxConcurrentDeliveries caps how many of the project's deliveries can
+	// be in flight at once, across all its endpoints, 0 is unlimited
+	MaxConcurrentDeliveries uint64 `json:"max_concurrent_deliveries" db:"max_concurrent_deliveries"`
+
+	// TestMode turns on the project's inspection URL, /inspect/{InspectionToken},
+	// which captures what is delivered to it
+	TestMode bool `json:"test_mode" db:"test_mode"`
+
+	// InspectionToken is the secret in the project's inspection URL, it is
+	// set when test mode is turned on and cleared when it is turned off
+	InspectionToken string `json:"inspection_token" db:"inspection_token"`
 }
 
 func (p *ProjectConfig) GetRateLimitConfig() RateLimitConfiguration {
@@ -591,6 +882,34 @@ func (p *ProjectConfig) GetSSLConfig() SSLConfiguration {
 	return SSLConfiguration{}
 }
 
+// GetMaxRetrySeconds returns the ceiling on the delay between retries, the
+// smaller of the project's and the instance's, where 0 means either is unset.
+func (p *ProjectConfig) GetMaxRetrySeconds(instanceMax uint64) uint64 {
+	if p.MaxRetrySeconds == 0 || (instanceMax != 0 && instanceMax < p.MaxRetrySeconds) {
+		return instanceMax
+	}
+
+	return p.MaxRetrySeconds
+}
+
+func (p *ProjectConfig) GetDeadLetterReplayConfig() DeadLetterReplayConfiguration {
+	if p.DeadLetterReplay != nil {
+		return *p.DeadLetterReplay
+	}
+
+	return DefaultDeadLetterReplayConfig
+}
+
+// GetDeliveryMode returns the delivery mode of the project's subscriptions
+// that don't set their own, at least once unless the project says otherwise.
+func (p *ProjectConfig) GetDeliveryMode() DeliveryMode {
+	if p.DeliveryMode != "" {
+		return p.DeliveryMode
+	}
+
+	return AtLeastOnceDeliveryMode
+}
+
 func (p *ProjectConfig) GetMetaEventConfig() MetaEventConfiguration {
 	if p.MetaEvent != nil {
 		return *p.MetaEvent
@@ -608,12 +927,87 @@ type StrategyConfiguration struct {
 	Type       StrategyProvider `json:"type" db:"type" valid:"optional~please provide a valid strategy type, in(linear|exponential)~unsupported strategy type"`
 	Duration   uint64           `json:"duration" db:"duration" valid:"optional~please provide a valid duration in seconds,int"`
 	RetryCount uint64           `json:"retry_count" db:"retry_count" valid:"optional~please provide a valid retry count,int"`
+
+	// BackoffBase is what the exponential strategy multiplies the interval
+	// by after every attempt, 0 falls back to 2
+	BackoffBase float64 `json:"backoff_base" db:"backoff_base"`
+
+	// MaxInterval caps the seconds the exponential strategy waits between
+	// two attempts, 0 falls back to the max retry seconds
+	MaxInterval uint64 `json:"max_interval" db:"max_interval"`
+
+	// JitterPercent randomly moves every retry's delay by up to this
+	// percent of it either way, so deliveries that failed together don't
+	// all retry together. 0 retries on the computed delay exactly.
+	JitterPercent uint64 `json:"jitter_percent" db:"jitter_percent"`
 }
 
 type SignatureConfiguration struct {
 	Hash     string                         `json:"-" db:"hash"` // Deprecated
 	Header   config.SignatureHeaderProvider `json:"header,omitempty" valid:"required~please provide a valid signature header"`
 	Versions SignatureVersions              `json:"versions" db:"versions"`
+
+	// UnsignedEventTypes lists event types whose deliveries are sent
+	// without a signature header. Every other event type is signed.
+	UnsignedEventTypes pq.StringArray `json:"unsigned_event_types" db:"unsigned_event_types"`
+
+	// ProxyURL, when set, delegates signing to an external signing service
+	// so the signing keys never leave it, e.g. an HSM-backed proxy
+	ProxyURL string `json:"proxy_url,omitempty" db:"proxy_url"`
+
+	// SignRequestTarget binds signatures to the request's method, host and
+	// path as well as its body, see signature.RequestTarget
+	SignRequestTarget bool `json:"sign_request_target" db:"sign_request_target"`
+
+	// SignQueryParams adds the query params deliveries are sent with to the
+	// signed string, in the order signature.CanonicalQuery puts them
+	SignQueryParams bool `json:"sign_query_params" db:"sign_query_params"`
+
+	// SecretEpochHeader sends the rotation epoch of the endpoint secret
+	// deliveries are signed with in the X-Convoy-Secret-Epoch header
+	SecretEpochHeader bool `json:"secret_epoch_header" db:"secret_epoch_header"`
+
+	// SignSecretEpoch adds the secret's rotation epoch to the signed string
+	// too, the header is sent so receivers can verify it
+	SignSecretEpoch bool `json:"sign_secret_epoch" db:"sign_secret_epoch"`
+
+	// MissingSecretPolicy is what happens to deliveries that should be
+	// signed when their endpoint has no secret to sign them with
+	MissingSecretPolicy MissingSecretPolicy `json:"missing_secret_policy" db:"missing_secret_policy"`
+}
+
+type MissingSecretPolicy string
+
+const (
+	// FailMissingSecretPolicy fails the delivery without sending it.
+	FailMissingSecretPolicy MissingSecretPolicy = "fail"
+	// UnsignedMissingSecretPolicy sends the delivery without a signature.
+	UnsignedMissingSecretPolicy MissingSecretPolicy = "unsigned"
+)
+
+// GetMissingSecretPolicy returns the project's missing secret policy,
+// deliveries fail by default.
+func (s SignatureConfiguration) GetMissingSecretPolicy() MissingSecretPolicy {
+	if s.MissingSecretPolicy == "" {
+		return FailMissingSecretPolicy
+	}
+	return s.MissingSecretPolicy
+}
+
+// SendsSecretEpoch reports whether deliveries carry the secret epoch header.
+func (s SignatureConfiguration) SendsSecretEpoch() bool {
+	return s.SecretEpochHeader || s.SignSecretEpoch
+}
+
+// ShouldSign reports whether deliveries of the event type carry a signature.
+func (s SignatureConfiguration) ShouldSign(eventType string) bool {
+	for _, t := range s.UnsignedEventTypes {
+		if t == eventType {
+			return false
+		}
+	}
+
+	return true
 }
 
 type SignatureVersion struct {
@@ -621,6 +1015,26 @@ type SignatureVersion struct {
 	Hash      string       `json:"hash,omitempty" db:"hash" valid:"required~please provide a valid hash,supported_hash~unsupported hash type"`
 	Encoding  EncodingType `json:"encoding" db:"encoding" valid:"required~please provide a valid signature header"`
 	CreatedAt time.Time    `json:"created_at,omitempty" db:"created_at" swaggertype:"string"`
+
+	// ActiveFrom and ActiveUntil bound when deliveries are signed with this
+	// version, either may be left unset. Overlapping windows on an old and a
+	// new version send both signatures while consumers move to the new one.
+	ActiveFrom  null.Time `json:"active_from,omitempty" db:"active_from" swaggertype:"string"`
+	ActiveUntil null.Time `json:"active_until,omitempty" db:"active_until" swaggertype:"string"`
+}
+
+// IsActiveAt reports whether t falls within the version's window. The window
+// includes ActiveFrom and excludes ActiveUntil.
+func (s SignatureVersion) IsActiveAt(t time.Time) bool {
+	if s.ActiveFrom.Valid && t.Before(s.ActiveFrom.Time) {
+		return false
+	}
+
+	if s.ActiveUntil.Valid && !t.Before(s.ActiveUntil.Time) {
+		return false
+	}
+
+	return true
 }
 
 type MetaEventConfiguration struct {
@@ -636,6 +1050,26 @@ type SSLConfiguration struct {
 	EnforceSecureEndpoints bool `json:"enforce_secure_endpoints" db:"enforce_secure_endpoints"`
 }
 
+type DeadLetterReplayPolicy string
+
+const (
+	// ManualDeadLetterReplayPolicy only replays dead-lettered deliveries on request.
+	ManualDeadLetterReplayPolicy DeadLetterReplayPolicy = "manual"
+	// AutoDeadLetterReplayPolicy replays dead-lettered deliveries once they have
+	// been dead for AutoReplayAfterHours, and still allows manual replays. It
+	// replays nothing while AutoReplayAfterHours is 0.
+	AutoDeadLetterReplayPolicy DeadLetterReplayPolicy = "auto"
+	// NeverDeadLetterReplayPolicy never replays dead-lettered deliveries.
+	NeverDeadLetterReplayPolicy DeadLetterReplayPolicy = "never"
+)
+
+// DeadLetterReplayConfiguration controls how deliveries that exhausted their
+// retries (failed deliveries) can be replayed.
+type DeadLetterReplayConfiguration struct {
+	Policy               DeadLetterReplayPolicy `json:"policy" db:"policy" valid:"optional,in(manual|auto|never)~unsupported dead letter replay policy"`
+	AutoReplayAfterHours uint64                 `json:"auto_replay_after_hours" db:"auto_replay_after_hours"`
+}
+
 type RetentionPolicyConfiguration struct {
 	Policy                   string `json:"policy" db:"policy"`
 	IsRetentionPolicyEnabled bool   `json:"retention_policy_enabled" db:"enabled"`
@@ -662,6 +1096,17 @@ type EventDeliveryFilter struct {
 	ProjectID      string `json:"project_id" bson:"project_id"`
 	CreatedAtStart int64  `json:"created_at_start" bson:"created_at_start"`
 	CreatedAtEnd   int64  `json:"created_at_end" bson:"created_at_end"`
+
+	// EventTypes restricts the filter to these event types when set
+	EventTypes []string `json:"event_types" bson:"event_types"`
+
+	// ExcludeEventTypes leaves out these event types when set
+	ExcludeEventTypes []string `json:"exclude_event_types" bson:"exclude_event_types"`
+
+	// EndpointID and Status narrow the filter to one endpoint and a set
+	// of statuses when set, they are only used for requeueing
+	EndpointID string                `json:"endpoint_id" bson:"endpoint_id"`
+	Status     []EventDeliveryStatus `json:"status" bson:"status"`
 }
 
 type DeliveryAttemptsFilter struct {
@@ -684,6 +1129,7 @@ var (
 	ErrEndpointNotFound              = errors.New("endpoint not found")
 	ErrSubscriptionNotFound          = errors.New("subscription not found")
 	ErrEventDeliveryNotFound         = errors.New("event delivery not found")
+	ErrStaleEntitySnapshot           = errors.New("entity snapshot is of a newer event")
 	ErrDeliveryAttemptNotFound       = errors.New("event delivery attempt not found")
 	ErrDeliveryAttemptsNotDeleted    = errors.New("event delivery attempts not deleted")
 	ErrPortalLinkNotFound            = errors.New("portal link not found")
@@ -694,6 +1140,7 @@ var (
 	ErrNoActiveSecret                = errors.New("no active secret found")
 	ErrSecretNotFound                = errors.New("secret not found")
 	ErrMetaEventNotFound             = errors.New("meta event not found")
+	ErrDuplicateEventDelivery        = errors.New("an event delivery for this endpoint and event already exists")
 )
 
 type AppMetadata struct {
@@ -793,8 +1240,72 @@ type (
 const (
 	AtLeastOnceDeliveryMode DeliveryMode = "at_least_once"
 	AtMostOnceDeliveryMode  DeliveryMode = "at_most_once"
+
+	// OrderedDeliveryMode delivers at least once, holding each delivery
+	// while an earlier one to the same endpoint is still pending
+	OrderedDeliveryMode DeliveryMode = "ordered"
+)
+
+// IsValid reports whether d is a known delivery mode.
+func (d DeliveryMode) IsValid() bool {
+	return d == AtLeastOnceDeliveryMode || d == AtMostOnceDeliveryMode || d == OrderedDeliveryMode
+}
+
+// PartitionGranularity is the time span each partition of a partitioned
+// table covers.
+type PartitionGranularity string
+
+const (
+	DailyPartitionGranularity   PartitionGranularity = "daily"
+	MonthlyPartitionGranularity PartitionGranularity = "monthly"
 )
 
+// IsValid reports whether g is a known partition granularity.
+func (g PartitionGranularity) IsValid() bool {
+	return g == DailyPartitionGranularity || g == MonthlyPartitionGranularity
+}
+
+// DeliveryMetadataHeader is an event metadata header a project can send with
+// its deliveries, so consumers can correlate them without parsing the body.
+type DeliveryMetadataHeader string
+
+const (
+	EventIDMetadataHeader        DeliveryMetadataHeader = "event_id"
+	EventTypeMetadataHeader      DeliveryMetadataHeader = "event_type"
+	DeliveryIDMetadataHeader     DeliveryMetadataHeader = "delivery_id"
+	SubscriptionIDMetadataHeader DeliveryMetadataHeader = "subscription_id"
+)
+
+var deliveryMetadataHeaderNames = map[DeliveryMetadataHeader]string{
+	EventIDMetadataHeader:        "X-Convoy-Event-Id",
+	EventTypeMetadataHeader:      "X-Convoy-Event-Type",
+	DeliveryIDMetadataHeader:     "X-Convoy-Delivery-Id",
+	SubscriptionIDMetadataHeader: "X-Convoy-Subscription-Id",
+}
+
+// HeaderName returns the name of the header h is sent as, e.g.
+// X-Convoy-Event-Id, and false when h isn't a known metadata header.
+func (h DeliveryMetadataHeader) HeaderName() (string, bool) {
+	name, ok := deliveryMetadataHeaderNames[h]
+	return name, ok
+}
+
+// Value returns the value of h for the delivery.
+func (h DeliveryMetadataHeader) Value(e *EventDelivery) string {
+	switch h {
+	case EventIDMetadataHeader:
+		return e.EventID
+	case EventTypeMetadataHeader:
+		return string(e.EventType)
+	case DeliveryIDMetadataHeader:
+		return e.UID
+	case SubscriptionIDMetadataHeader:
+		return e.SubscriptionID
+	default:
+		return ""
+	}
+}
+
 func (h *HttpHeader) Scan(value interface{}) error {
 	bytes, ok := value.([]byte)
 	if !ok {
@@ -853,6 +1364,10 @@ type Metadata struct {
 	Raw      string           `json:"raw" bson:"raw"`
 	Strategy StrategyProvider `json:"strategy" bson:"strategy"`
 
+	// FullDocument is the whole document when Data only carries the fields
+	// that changed since the entity's previous event, see Subscription.DiffKey
+	FullDocument json.RawMessage `json:"full_document,omitempty" bson:"full_document"`
+
 	NextSendTime time.Time `json:"next_send_time" bson:"next_send_time"`
 
 	// NumTrials: number of times we have tried to deliver this Event to
@@ -864,6 +1379,14 @@ type Metadata struct {
 	RetryLimit uint64 `json:"retry_limit" bson:"retry_limit"`
 
 	MaxRetrySeconds uint64 `json:"max_retry_seconds" bson:"max_retry_seconds"`
+
+	BackoffBase float64 `json:"backoff_base,omitempty" bson:"backoff_base"`
+
+	MaxIntervalSeconds uint64 `json:"max_interval_seconds,omitempty" bson:"max_interval_seconds"`
+
+	// OrderingPreserved is set on ordered deliveries when they're sent, it's
+	// false when an earlier delivery to the same endpoint was still pending
+	OrderingPreserved *bool `json:"ordering_preserved,omitempty" bson:"ordering_preserved"`
 }
 
 func (m *Metadata) Scan(value interface{}) error {
@@ -905,6 +1428,67 @@ type EventIntervalData struct {
 type EventInterval struct {
 	Data  EventIntervalData `json:"data" db:"data"`
 	Count uint64            `json:"count" db:"count"`
+
+	// P50, P95 and P99 are the delivery latency percentiles of the
+	// interval in seconds, they are only loaded when asked for.
+	P50 float64 `json:"p50_latency_seconds" db:"p50"`
+	P95 float64 `json:"p95_latency_seconds" db:"p95"`
+	P99 float64 `json:"p99_latency_seconds" db:"p99"`
+}
+
+// AttemptCount is how many deliveries succeeded on a given attempt.
+type AttemptCount struct {
+	Attempts uint64 `json:"attempts" db:"attempts"`
+	Count    uint64 `json:"count" db:"count"`
+}
+
+// LatencyBucket is how many deliveries took from Lower seconds up to, but
+// not including, Upper seconds. The first bucket of a histogram has no
+// Lower and the last has no Upper.
+type LatencyBucket struct {
+	Lower null.Float `json:"lower_seconds"`
+	Upper null.Float `json:"upper_seconds"`
+	Count uint64     `json:"count"`
+}
+
+// EndpointDeliveryStats sums up an endpoint's finished deliveries within a
+// window, latencies are in seconds.
+type EndpointDeliveryStats struct {
+	EndpointID        string  `json:"endpoint_id" db:"endpoint_id"`
+	Total             uint64  `json:"total" db:"total"`
+	Successful        uint64  `json:"successful" db:"successful"`
+	P95LatencySeconds float64 `json:"p95_latency_seconds" db:"p95_latency_seconds"`
+}
+
+// EndpointHealth sums up how an endpoint's recent deliveries went: the
+// success rate (0-100) and average latency in seconds of its finished
+// deliveries, and how many attempts in a row failed since its last
+// successful one.
+type EndpointHealth struct {
+	EndpointID          string  `json:"endpoint_id" db:"endpoint_id"`
+	Total               uint64  `json:"total" db:"total"`
+	Successful          uint64  `json:"successful" db:"successful"`
+	SuccessRate         float64 `json:"success_rate" db:"-"`
+	AvgLatencySeconds   float64 `json:"avg_latency_seconds" db:"avg_latency_seconds"`
+	ConsecutiveFailures uint64  `json:"consecutive_failures" db:"consecutive_failures"`
+}
+
+// EndpointRecovery tracks the health probes sent to a disabled endpoint:
+// how many failed so far and when the next one is due.
+type EndpointRecovery struct {
+	EndpointID  string    `json:"endpoint_id" db:"endpoint_id"`
+	ProjectID   string    `json:"project_id" db:"project_id"`
+	Attempts    uint64    `json:"attempts" db:"attempts"`
+	NextProbeAt time.Time `json:"next_probe_at" db:"next_probe_at"`
+	CreatedAt   time.Time `json:"created_at,omitempty" db:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty"`
+}
+
+// HourlyDeliveryCount is how many deliveries were created in an hour of the
+// day (0-23, UTC), summed across every day of a window.
+type HourlyDeliveryCount struct {
+	Hour  int    `json:"hour" db:"hour"`
+	Count uint64 `json:"count" db:"count"`
 }
 
 type DeliveryAttempt struct {
@@ -985,6 +1569,12 @@ type EventDelivery struct {
 	EventType      EventType    `json:"event_type,omitempty" db:"event_type"`
 	DeliveryMode   DeliveryMode `json:"delivery_mode" db:"delivery_mode"`
 
+	// DeduplicationKey guards against creating a second delivery of the
+	// same event to the same endpoint, see EventDeliveryDeduplicationKey.
+	// Deliveries only collide when they share CreatedAt too, since the
+	// unique index has to include the partition key.
+	DeduplicationKey string `json:"-" db:"deduplication_key"`
+
 	Endpoint *Endpoint `json:"endpoint_metadata,omitempty" db:"endpoint_metadata"`
 	Event    *Event    `json:"event_metadata,omitempty" db:"event_metadata"`
 	Source   *Source   `json:"source_metadata,omitempty" db:"source_metadata"`
@@ -996,9 +1586,20 @@ type EventDelivery struct {
 	CLIMetadata      *CLIMetadata        `json:"cli_metadata" db:"cli_metadata"`
 	Description      string              `json:"description,omitempty" db:"description"`
 	AcknowledgedAt   null.Time           `json:"acknowledged_at,omitempty" db:"acknowledged_at,omitempty" swaggertype:"string"`
-	CreatedAt        time.Time           `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
-	UpdatedAt        time.Time           `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
-	DeletedAt        null.Time           `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
+
+	// DeviceAcknowledgedAt is when the device the delivery was streamed to
+	// acknowledged receiving it
+	DeviceAcknowledgedAt null.Time `json:"device_acknowledged_at,omitempty" db:"device_acknowledged_at" swaggertype:"string"`
+
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at,omitempty" swaggertype:"string"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty" swaggertype:"string"`
+	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
+}
+
+// EventDeliveryDeduplicationKey returns the key that allows only one
+// delivery per (endpoint, event) pair.
+func EventDeliveryDeduplicationKey(endpointID, eventID string) string {
+	return endpointID + ":" + eventID
 }
 
 func (d *EventDelivery) GetLatencyStartTime() time.Time {
@@ -1082,8 +1683,29 @@ type Subscription struct {
 	FilterConfig    *FilterConfiguration    `json:"filter_config,omitempty" db:"filter_config"`
 	RateLimitConfig *RateLimitConfiguration `json:"rate_limit_config,omitempty" db:"rate_limit_config"`
 
+	// DeliveryMode overrides the project's delivery mode for this
+	// subscription's event deliveries when it is set.
 	DeliveryMode DeliveryMode `json:"delivery_mode,omitempty" db:"delivery_mode"`
 
+	// HeaderAllowList restricts the source request headers forwarded onto
+	// this subscription's event deliveries. Every header is forwarded when empty.
+	HeaderAllowList pq.StringArray `json:"header_allow_list,omitempty" db:"header_allow_list"`
+
+	// Paused holds this subscription's event deliveries without
+	// failing them while sibling subscriptions keep delivering.
+	Paused bool `json:"paused" db:"paused"`
+
+	// PayloadTransform is a gjson path the worker reshapes this subscription's
+	// delivery payloads with before they are signed, e.g. "data" to unwrap a
+	// top-level data envelope. Payloads are sent as is when it is empty.
+	PayloadTransform string `json:"payload_transform,omitempty" db:"payload_transform"`
+
+	// DiffKey is a dot separated path to the entity an event is about,
+	// e.g. "data.id". When it is set, an event's deliveries carry only the
+	// entity key and the fields that changed since the previous event for
+	// the same entity, the first event for an entity is delivered whole.
+	DiffKey string `json:"diff_key,omitempty" db:"diff_key"`
+
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at" swaggertype:"string"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at" swaggertype:"string"`
 	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
@@ -1125,6 +1747,20 @@ func (s *Subscription) GetRateLimitConfig() RateLimitConfiguration {
 	return RateLimitConfiguration{}
 }
 
+// GetDeliveryMode returns the subscription's delivery mode, falling back to
+// the project's when the subscription doesn't override it.
+func (s *Subscription) GetDeliveryMode(project *Project) DeliveryMode {
+	if s.DeliveryMode != "" {
+		return s.DeliveryMode
+	}
+
+	if project != nil && project.Config != nil {
+		return project.Config.GetDeliveryMode()
+	}
+
+	return AtLeastOnceDeliveryMode
+}
+
 type CustomResponse struct {
 	Body        string `json:"body" db:"body"`
 	ContentType string `json:"content_type" db:"content_type"`
@@ -1149,6 +1785,15 @@ type Source struct {
 	BodyFunction    *string         `json:"body_function" db:"body_function"`
 	HeaderFunction  *string         `json:"header_function" db:"header_function"`
 
+	// IngestFailureThreshold pauses the source after this many ingested
+	// requests in a row fail verification or can't be read, 0 never does
+	IngestFailureThreshold    uint64 `json:"ingest_failure_threshold" db:"ingest_failure_threshold"`
+	ConsecutiveIngestFailures uint64 `json:"consecutive_ingest_failures" db:"consecutive_ingest_failures"`
+
+	// Paused rejects everything ingested through the source until it's
+	// unpaused
+	Paused bool `json:"paused" db:"paused"`
+
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at" swaggertype:"string"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at" swaggertype:"string"`
 	DeletedAt null.Time `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string"`
@@ -1368,6 +2013,13 @@ type ApiKey struct {
 	HeaderName  string `json:"header_name" db:"header_name" valid:"required"`
 }
 
+// DigestAuth holds the credentials used to answer an endpoint's HTTP Digest
+// authentication challenge
+type DigestAuth struct {
+	Username string `json:"username" db:"username" valid:"required"`
+	Password string `json:"password" db:"password" valid:"required"`
+}
+
 type Organisation struct {
 	UID            string      `json:"uid" db:"id"`
 	OwnerID        string      `json:"" db:"owner_id"`
@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -71,3 +72,109 @@ func TestProject_IsOwner(t *testing.T) {
 		})
 	}
 }
+
+func TestPaginationData_BuildCreatedAtCursor(t *testing.T) {
+	createdAt := time.Date(2025, time.March, 4, 10, 30, 15, 123456000, time.UTC)
+	items := []string{
+		EncodeCreatedAtCursor(createdAt.Add(time.Minute), "01HZ0000000000000000000002"),
+		EncodeCreatedAtCursor(createdAt, "01HZ0000000000000000000001"),
+	}
+
+	p := &PaginationData{}
+	p.Build(Pageable{PerPage: 1, SortField: CreatedAtSortField}, items)
+	require.True(t, p.HasNextPage)
+
+	gotCreatedAt, gotID, err := DecodeCreatedAtCursor(p.NextPageCursor)
+	require.NoError(t, err)
+	require.True(t, createdAt.Equal(gotCreatedAt))
+	require.Equal(t, "01HZ0000000000000000000001", gotID)
+
+	gotCreatedAt, gotID, err = DecodeCreatedAtCursor(p.PrevPageCursor)
+	require.NoError(t, err)
+	require.True(t, createdAt.Add(time.Minute).Equal(gotCreatedAt))
+	require.Equal(t, "01HZ0000000000000000000002", gotID)
+}
+
+func TestDecodeCreatedAtCursor_Invalid(t *testing.T) {
+	for _, cursor := range []string{"01HZ0000000000000000000001", "%%%", EncodeCreatedAtCursor(time.Now(), "")[:4]} {
+		_, _, err := DecodeCreatedAtCursor(cursor)
+		require.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
+
+func TestSubscription_GetDeliveryMode(t *testing.T) {
+	project := &Project{Config: &ProjectConfig{}}
+
+	sub := &Subscription{DeliveryMode: AtMostOnceDeliveryMode}
+	require.Equal(t, AtMostOnceDeliveryMode, sub.GetDeliveryMode(project))
+
+	sub = &Subscription{}
+	require.Equal(t, AtLeastOnceDeliveryMode, sub.GetDeliveryMode(project))
+	require.Equal(t, AtLeastOnceDeliveryMode, sub.GetDeliveryMode(nil))
+
+	// subscriptions inherit the project's default, unless they set their own
+	project.Config.DeliveryMode = AtMostOnceDeliveryMode
+	require.Equal(t, AtMostOnceDeliveryMode, sub.GetDeliveryMode(project))
+
+	sub = &Subscription{DeliveryMode: AtLeastOnceDeliveryMode}
+	require.Equal(t, AtLeastOnceDeliveryMode, sub.GetDeliveryMode(project))
+}
+
+func TestProjectConfig_GetMaxRetrySeconds(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectMax  uint64
+		instanceMax uint64
+		want        uint64
+	}{
+		{name: "project ceiling is lower", projectMax: 60, instanceMax: 7200, want: 60},
+		{name: "instance ceiling is lower", projectMax: 7200, instanceMax: 60, want: 60},
+		{name: "project ceiling is unset", projectMax: 0, instanceMax: 7200, want: 7200},
+		{name: "instance ceiling is unset", projectMax: 60, instanceMax: 0, want: 60},
+		{name: "both are unset", projectMax: 0, instanceMax: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ProjectConfig{MaxRetrySeconds: tt.projectMax}
+			require.Equal(t, tt.want, c.GetMaxRetrySeconds(tt.instanceMax))
+		})
+	}
+}
+
+func TestSignatureVersion_IsActiveAt(t *testing.T) {
+	now := time.Now()
+	v := SignatureVersion{
+		ActiveFrom:  null.TimeFrom(now),
+		ActiveUntil: null.TimeFrom(now.Add(time.Hour)),
+	}
+
+	require.False(t, v.IsActiveAt(now.Add(-time.Second)))
+	require.True(t, v.IsActiveAt(now))
+	require.True(t, v.IsActiveAt(now.Add(time.Hour-time.Second)))
+	require.False(t, v.IsActiveAt(now.Add(time.Hour)))
+
+	require.True(t, SignatureVersion{}.IsActiveAt(now))
+}
+
+func TestReadPreferenceAfterWrite(t *testing.T) {
+	require.Equal(t, PreferPrimary, ReadPreferenceAfterWrite(time.Now().Add(-2*time.Second), 5*time.Second))
+	require.Equal(t, PreferReplica, ReadPreferenceAfterWrite(time.Now().Add(-10*time.Second), 5*time.Second))
+
+	// a zero window never prefers the primary
+	require.Equal(t, PreferReplica, ReadPreferenceAfterWrite(time.Now(), 0))
+}
+
+func TestReadPreferenceFromContext(t *testing.T) {
+	ctx := context.Background()
+
+	// reads keep their own preference unless the context sets one
+	require.Equal(t, PreferReplica, ReadPreferenceFromContext(ctx, PreferReplica))
+	require.Equal(t, PreferPrimary, ReadPreferenceFromContext(ctx, PreferPrimary))
+
+	primary := WithPrimaryReads(ctx)
+	require.Equal(t, PreferPrimary, ReadPreferenceFromContext(primary, PreferReplica))
+
+	replica := WithReadPreference(ctx, PreferReplica)
+	require.Equal(t, PreferReplica, ReadPreferenceFromContext(replica, PreferPrimary))
+}
@@ -26,23 +26,62 @@ type APIKeyRepository interface {
 type EventDeliveryRepository interface {
 	ExportRepository
 	CreateEventDelivery(context.Context, *EventDelivery) error
-	CreateEventDeliveries(context.Context, []*EventDelivery) error
+	CreateEventDeliveries(context.Context, []*EventDelivery) ([]*EventDelivery, error)
+	ReleaseEventDeliveryDeduplicationKeys(ctx context.Context, projectID string, eventID string) error
+	PurgeEventDeliveryDeduplicationKeys(ctx context.Context, before time.Time) (int64, error)
+	HasPendingEventDeliveriesBefore(ctx context.Context, delivery *EventDelivery) (bool, error)
 	FindEventDeliveryByID(ctx context.Context, projectID string, id string) (*EventDelivery, error)
 	FindEventDeliveryByIDSlim(ctx context.Context, projectID string, id string) (*EventDelivery, error)
 	FindEventDeliveriesByIDs(ctx context.Context, projectID string, ids []string) ([]EventDelivery, error)
+	FindEventDeliveriesByIdempotencyKey(ctx context.Context, projectID string, idempotencyKey string) ([]EventDelivery, error)
 	FindEventDeliveriesByEventID(ctx context.Context, projectID string, id string) ([]EventDelivery, error)
+	FindEventDeliveriesByEventIDs(ctx context.Context, projectID string, eventIDs []string) ([]EventDelivery, error)
+	LatestDeliveryByEndpoint(ctx context.Context, projectID string, endpointIDs []string) (map[string]EventDelivery, error)
 	CountDeliveriesByStatus(ctx context.Context, projectID string, status EventDeliveryStatus, params SearchParams) (int64, error)
+	CountDeliveriesByEventType(ctx context.Context, projectID, eventType string, status EventDeliveryStatus, params SearchParams) (int64, error)
+	GetDeliverySLACompliance(ctx context.Context, projectID string, budget time.Duration, params SearchParams) (float64, error)
+	LoadAttemptCountDistribution(ctx context.Context, projectID string, params SearchParams) ([]AttemptCount, error)
+	GetEventDeliveryLatencyHistogram(ctx context.Context, projectID, endpointID string, params SearchParams, buckets []float64) ([]LatencyBucket, error)
+	LoadEndpointDeliveryStats(ctx context.Context, projectID string, params SearchParams) ([]EndpointDeliveryStats, error)
+	// ComputeEndpointHealth sums up the endpoint's deliveries and attempts
+	// created within window, from the read replica
+	ComputeEndpointHealth(ctx context.Context, projectID, endpointID string, window time.Duration) (*EndpointHealth, error)
+	SwapEntitySnapshot(ctx context.Context, projectID, subscriptionID, entityKey, eventID string, eventCreatedAt time.Time, payload []byte) ([]byte, error)
+	LoadDeliveryCountsByHour(ctx context.Context, projectID string, params SearchParams) ([]HourlyDeliveryCount, error)
+	FindDeadLetteredEventDeliveries(ctx context.Context, projectID string, failedBefore time.Time) ([]EventDelivery, error)
 	UpdateStatusOfEventDelivery(ctx context.Context, projectID string, eventDelivery EventDelivery, status EventDeliveryStatus) error
 	UpdateStatusOfEventDeliveries(ctx context.Context, projectID string, ids []string, status EventDeliveryStatus) error
+	// RequeueRecentEventDeliveries schedules the deliveries among ids created
+	// within maxAge and returns the ids of the ones it skipped for being older
+	RequeueRecentEventDeliveries(ctx context.Context, projectID string, ids []string, maxAge time.Duration) ([]string, error)
+	RequeueEventDeliveriesByFilter(ctx context.Context, projectID string, filter *EventDeliveryFilter) (int64, error)
+	FindEventDeliveriesAfterID(ctx context.Context, projectID string, filter *EventDeliveryFilter, afterID string, limit int) ([]EventDelivery, error)
 	FindDiscardedEventDeliveries(ctx context.Context, projectID, deviceId string, params SearchParams) ([]EventDelivery, error)
 	FindStuckEventDeliveriesByStatus(ctx context.Context, status EventDeliveryStatus) ([]EventDelivery, error)
+	FindEventDeliveriesStuckInStatus(ctx context.Context, projectID string, status EventDeliveryStatus, olderThan time.Duration, limit int) ([]EventDelivery, error)
+	// FindOrphanedEventDeliveries returns deliveries after afterID whose
+	// endpoint or subscription no longer exists
+	FindOrphanedEventDeliveries(ctx context.Context, projectID, afterID string, limit int) ([]EventDelivery, error)
+	ClaimScheduledDeliveries(ctx context.Context, projectID string, limit int) ([]EventDelivery, error)
+	FindUnacknowledgedEventDeliveries(ctx context.Context, projectID string, olderThan time.Duration, limit int) ([]EventDelivery, error)
+	AcknowledgeEventDeliveries(ctx context.Context, projectID string, ids []string, at time.Time) (int64, error)
+	FindEventDeliveriesByEndpointAndStatus(ctx context.Context, projectID, endpointID string, status EventDeliveryStatus, params SearchParams, cursor string, limit int) ([]EventDelivery, string, error)
 	UpdateEventDeliveryMetadata(ctx context.Context, projectID string, eventDelivery *EventDelivery) error
-	CountEventDeliveries(ctx context.Context, projectID string, endpointIDs []string, eventID string, status []EventDeliveryStatus, params SearchParams) (int64, error)
+	CountEventDeliveries(ctx context.Context, projectID string, endpointIDs, sourceIDs []string, eventID string, status []EventDeliveryStatus, params SearchParams) (int64, error)
 	DeleteProjectEventDeliveries(ctx context.Context, projectID string, filter *EventDeliveryFilter, hardDelete bool) error
-	LoadEventDeliveriesPaged(ctx context.Context, projectID string, endpointIDs []string, eventID, subscriptionID string, status []EventDeliveryStatus, params SearchParams, pageable Pageable, idempotencyKey, eventType string) ([]EventDelivery, PaginationData, error)
-	LoadEventDeliveriesIntervals(ctx context.Context, projectID string, params SearchParams, period Period, ids []string) ([]EventInterval, error)
-	PartitionEventDeliveriesTable(ctx context.Context) error
+	StripProjectEventDeliveryPayloads(ctx context.Context, projectID string, filter *EventDeliveryFilter) (int64, error)
+	LoadEventDeliveriesPaged(ctx context.Context, projectID string, endpointIDs []string, eventID, subscriptionID string, status []EventDeliveryStatus, params SearchParams, pageable Pageable, idempotencyKey, eventType string, responseStatusCodes []int, readPreference ReadPreference) ([]EventDelivery, PaginationData, error)
+	LoadEventDeliveriesIntervals(ctx context.Context, projectID string, params SearchParams, period Period, ids []string, withLatency bool, weekStart time.Weekday) ([]EventInterval, error)
+	ExportRecordsFormat(ctx context.Context, projectID string, createdAt time.Time, format ExportFormat, w io.Writer, opts ...ExportOption) (int64, error)
+	LoadEventDeliveriesIntervalsByDeliveryMode(ctx context.Context, projectID string, params SearchParams, period Period) (map[DeliveryMode][]EventInterval, error)
+	BackfillLatencySeconds(ctx context.Context, batchSize int) (int64, error)
+	CompactDeliveryAttempts(ctx context.Context, batchSize int) (int64, error)
+	PurgeSoftDeletedEventDeliveries(ctx context.Context, olderThan time.Time) (int64, error)
+	PartitionEventDeliveriesTable(ctx context.Context, granularity PartitionGranularity) error
 	UnPartitionEventDeliveriesTable(ctx context.Context) error
+	EnsureEventDeliveryPartitions(ctx context.Context, projectID string, from, to time.Time) (int, error)
+	EventDeliveryPartitionGranularity(ctx context.Context) (PartitionGranularity, error)
+	DropEventDeliveryPartitionsBefore(ctx context.Context, before time.Time) (int, error)
 }
 
 type EventRepository interface {
@@ -73,6 +112,7 @@ type ProjectRepository interface {
 	UpdateProject(context.Context, *Project) error
 	DeleteProject(ctx context.Context, uid string) error
 	FetchProjectByID(context.Context, string) (*Project, error)
+	FetchProjectByInspectionToken(ctx context.Context, token string) (*Project, error)
 	GetProjectsWithEventsInTheInterval(ctx context.Context, interval int) ([]ProjectEvents, error)
 	FillProjectsStatistics(ctx context.Context, project *Project) error
 }
@@ -122,6 +162,9 @@ type EndpointRepository interface {
 	LoadEndpointsPaged(ctx context.Context, projectID string, filter *Filter, pageable Pageable) ([]Endpoint, PaginationData, error)
 	UpdateSecrets(ctx context.Context, endpointID string, projectID string, secrets Secrets) error
 	DeleteSecret(ctx context.Context, endpoint *Endpoint, secretID string, projectID string) error
+	LoadEndpointRecoveries(ctx context.Context, projectID string) ([]EndpointRecovery, error)
+	CreateEndpointRecovery(ctx context.Context, projectID, endpointID string) error
+	UpsertEndpointRecovery(ctx context.Context, recovery *EndpointRecovery) error
 }
 
 type SubscriptionRepository interface {
@@ -129,6 +172,7 @@ type SubscriptionRepository interface {
 	UpdateSubscription(ctx context.Context, projectID string, subscription *Subscription) error
 	LoadSubscriptionsPaged(ctx context.Context, projectID string, filter *FilterBy, pageable Pageable) ([]Subscription, PaginationData, error)
 	DeleteSubscription(ctx context.Context, projectID string, subscription *Subscription) error
+	UpdateSubscriptionPaused(ctx context.Context, projectID, subscriptionID string, paused bool) error
 	FindSubscriptionByID(ctx context.Context, projectID, id string) (*Subscription, error)
 	FindSubscriptionsBySourceID(ctx context.Context, projectID, sourceID string) ([]Subscription, error)
 	FindSubscriptionsByEndpointID(ctx context.Context, projectId string, endpointID string) ([]Subscription, error)
@@ -163,6 +207,10 @@ type SourceRepository interface {
 	DeleteSourceByID(ctx context.Context, projectId string, id string, sourceVerifierId string) error
 	LoadSourcesPaged(ctx context.Context, projectId string, filter *SourceFilter, pageable Pageable) ([]Source, PaginationData, error)
 	LoadPubSubSourcesByProjectIDs(ctx context.Context, projectIds []string, pageable Pageable) ([]Source, PaginationData, error)
+	UpdateSourcePaused(ctx context.Context, projectID, sourceID string, paused bool) error
+	PauseSource(ctx context.Context, projectID, sourceID string) (bool, error)
+	IncrementSourceIngestFailures(ctx context.Context, projectID, sourceID string) (uint64, error)
+	ResetSourceIngestFailures(ctx context.Context, projectID, sourceID string) error
 }
 
 type DeviceRepository interface {
@@ -224,12 +272,61 @@ type MetaEventRepository interface {
 }
 
 type ExportRepository interface {
-	ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error)
+	ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...ExportOption) (int64, error)
+}
+
+// ExportOptions tweaks which rows ExportRecords emits.
+type ExportOptions struct {
+	// SampleRate is the fraction of rows to export, in (0, 1].
+	// Zero exports everything.
+	SampleRate float64
+
+	// SampleSeed selects which rows make up the sample. Exports that
+	// use the same rate and seed emit the same rows.
+	SampleSeed string
+
+	// Columns limits the fields of every exported record, in that order.
+	// Empty exports every field. Only used by ExportRecordsFormat.
+	Columns []string
+}
+
+// ExportFormat is how ExportRecordsFormat encodes records.
+type ExportFormat string
+
+const (
+	// JSONExportFormat writes the full rows as one JSON array, like ExportRecords
+	JSONExportFormat ExportFormat = "json"
+
+	// NDJSONExportFormat writes one JSON object per line
+	NDJSONExportFormat ExportFormat = "ndjson"
+
+	// CSVExportFormat writes a header row followed by one row per record,
+	// nested objects are written as JSON
+	CSVExportFormat ExportFormat = "csv"
+)
+
+type ExportOption func(o *ExportOptions)
+
+// WithSampleRate makes ExportRecords emit approximately rate of the
+// matching rows, picked deterministically from seed.
+func WithSampleRate(rate float64, seed string) ExportOption {
+	return func(o *ExportOptions) {
+		o.SampleRate = rate
+		o.SampleSeed = seed
+	}
+}
+
+// WithColumns makes ExportRecordsFormat emit only the given fields.
+func WithColumns(columns ...string) ExportOption {
+	return func(o *ExportOptions) {
+		o.Columns = columns
+	}
 }
 
 type DeliveryAttemptsRepository interface {
 	ExportRepository
 	CreateDeliveryAttempt(context.Context, *DeliveryAttempt) error
+	CreateDeliveryAttempts(context.Context, []*DeliveryAttempt) error
 	FindDeliveryAttemptById(context.Context, string, string) (*DeliveryAttempt, error)
 	FindDeliveryAttempts(context.Context, string) ([]DeliveryAttempt, error)
 	DeleteProjectDeliveriesAttempts(ctx context.Context, projectID string, filter *DeliveryAttemptsFilter, hardDelete bool) error
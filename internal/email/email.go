@@ -26,6 +26,7 @@ const (
 	TemplateOrganisationInvite TemplateName = "organisation.invite"
 	TemplateResetPassword      TemplateName = "reset.password"
 	TemplateTwitterSource      TemplateName = "twitter.source"
+	TemplateSourcePaused       TemplateName = "source.paused"
 )
 
 func (t TemplateName) String() string {
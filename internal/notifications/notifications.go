@@ -112,3 +112,64 @@ func SendEndpointNotification(
 
 	return nil
 }
+
+// SendSourcePausedNotification tells the support contacts of the endpoints
+// subscribed to source that it was paused after failures ingests in a row
+// failed, the last one for reason.
+func SendSourcePausedNotification(
+	_ context.Context,
+	source *datastore.Source,
+	project *datastore.Project,
+	endpoints []*datastore.Endpoint,
+	q queue.Queuer,
+	failures uint64,
+	reason string,
+) error {
+	var ns []*Notification
+
+	for _, endpoint := range endpoints {
+		if !util.IsStringEmpty(endpoint.SupportEmail) {
+			ns = append(ns, &Notification{
+				NotificationType: EmailNotificationType,
+				Payload: email.Message{
+					Email:        endpoint.SupportEmail,
+					Subject:      "Source Paused",
+					TemplateName: email.TemplateSourcePaused,
+					Params: map[string]string{
+						"source_name": source.Name,
+						"logo_url":    project.LogoURL,
+						"failures":    strconv.FormatUint(failures, 10),
+						"failure_msg": reason,
+					},
+				},
+			})
+		}
+
+		if !util.IsStringEmpty(endpoint.SlackWebhookURL) {
+			ns = append(ns, &Notification{
+				NotificationType: SlackNotificationType,
+				Payload: SlackNotification{
+					WebhookURL: endpoint.SlackWebhookURL,
+					Text:       fmt.Sprintf("source (%s) was paused after %d ingested requests in a row failed, reason for the last failure is \"%s\", events sent to it are rejected until it is unpaused", source.Name, failures, reason),
+				},
+			})
+		}
+	}
+
+	for _, v := range ns {
+		buf, err := msgpack.EncodeMsgPack(v)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to marshal %v notification payload", v.NotificationType)
+			continue
+		}
+
+		job := &queue.Job{Payload: buf}
+
+		err = q.Write(convoy.NotificationProcessor, convoy.DefaultQueue, job)
+		if err != nil {
+			log.WithError(err).Error("Failed to write new notification to the queue")
+		}
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/tidwall/gjson"
@@ -284,3 +285,25 @@ func GenerateChecksum(s string) string {
 
 	return hashString
 }
+
+// ContentChecksum derives an idempotency key from an event's content alone, so
+// the same content gets the same key across retries and re-ingestion. The key is
+// the hex encoded SHA256 of the project id, the event type and the payload,
+// joined with newlines. The payload is canonicalised first: object keys are
+// sorted and insignificant whitespace is dropped, so formatting differences
+// don't change the key.
+func ContentChecksum(projectID, eventType string, payload []byte) (string, error) {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return "", err
+	}
+
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return GenerateChecksum(projectID + "\n" + eventType + "\n" + string(canonical)), nil
+}
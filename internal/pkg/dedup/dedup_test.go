@@ -0,0 +1,51 @@
+package dedup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentChecksum(t *testing.T) {
+	key, err := ContentChecksum("project-1", "invoice.paid", []byte(`{"id": 1, "amount": 100}`))
+	require.NoError(t, err)
+
+	// the same content formatted differently
+	same, err := ContentChecksum("project-1", "invoice.paid", []byte("{\n  \"amount\": 100,\n  \"id\": 1\n}"))
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	tests := []struct {
+		name      string
+		projectID string
+		eventType string
+		payload   string
+	}{
+		{name: "different payload", projectID: "project-1", eventType: "invoice.paid", payload: `{"id": 1, "amount": 101}`},
+		{name: "different event type", projectID: "project-1", eventType: "invoice.failed", payload: `{"id": 1, "amount": 100}`},
+		{name: "different project", projectID: "project-2", eventType: "invoice.paid", payload: `{"id": 1, "amount": 100}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other, err := ContentChecksum(tt.projectID, tt.eventType, []byte(tt.payload))
+			require.NoError(t, err)
+			require.NotEqual(t, key, other)
+		})
+	}
+}
+
+func TestContentChecksum_LargeNumbers(t *testing.T) {
+	a, err := ContentChecksum("project-1", "invoice.paid", []byte(`{"id": 9007199254740993}`))
+	require.NoError(t, err)
+
+	b, err := ContentChecksum("project-1", "invoice.paid", []byte(`{"id": 9007199254740992}`))
+	require.NoError(t, err)
+
+	require.NotEqual(t, a, b)
+}
+
+func TestContentChecksum_InvalidPayload(t *testing.T) {
+	_, err := ContentChecksum("project-1", "invoice.paid", []byte(`{"id":`))
+	require.Error(t, err)
+}
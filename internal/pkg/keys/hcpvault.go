@@ -13,6 +13,8 @@ import (
 	"github.com/frain-dev/convoy/pkg/log"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -44,6 +46,19 @@ type HCPVaultKeyManager struct {
 	cache    cache.Cache
 
 	isSet bool
+
+	// mu serializes calls to the HCP Vault API, the background refresh
+	// runs alongside the requests
+	mu sync.Mutex
+
+	// cacheDuration is how long the secret is cached, it's refreshed in
+	// the background every half of it. lastKey is the last secret fetched
+	// or set, it's served while a refresh fails.
+	cacheDuration time.Duration
+	lastKey       atomic.Pointer[string]
+	stopRefresh   chan struct{}
+	refreshDone   chan struct{}
+	stopOnce      sync.Once
 }
 
 type SecretResponse struct {
@@ -132,6 +147,7 @@ func (k *HCPVaultKeyManager) GetCurrentKeyFromCache() (string, error) {
 	}
 
 	if currentKey != nil && *currentKey != "" {
+		k.lastKey.Store(currentKey)
 		return *currentKey, nil
 	}
 
@@ -148,6 +164,9 @@ func (k *HCPVaultKeyManager) GetCurrentKey() (string, error) {
 
 // GetHCPSecretKey retrieves the current key from HCP Vault API.
 func (k *HCPVaultKeyManager) GetHCPSecretKey() (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
 	retryCount := 1
 	for {
 		if err := k.ensureValidToken(); err != nil {
@@ -166,7 +185,8 @@ func (k *HCPVaultKeyManager) GetHCPSecretKey() (string, error) {
 			}
 			return "", err
 		}
-		return currentKey, k.cache.Set(context.Background(), RedisCacheKey, &currentKey, oneYear)
+		k.lastKey.Store(&currentKey)
+		return currentKey, k.cache.Set(context.Background(), RedisCacheKey, &currentKey, k.cacheTTL())
 	}
 }
 
@@ -211,6 +231,10 @@ func (k *HCPVaultKeyManager) SetKey(newKey string) error {
 	if !k.licenser.CredentialEncryption() {
 		return ErrCredentialEncryptionFeatureUnavailable
 	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
 	// Retry configuration
 	maxRetries := 1
 	retryCount := 0
@@ -280,7 +304,8 @@ func (k *HCPVaultKeyManager) createOrUpdateSecret(newKey string) error {
 		return parseErrorResponse(resp)
 	}
 
-	return k.cache.Set(context.Background(), RedisCacheKey, &newKey, oneYear)
+	k.lastKey.Store(&newKey)
+	return k.cache.Set(context.Background(), RedisCacheKey, &newKey, k.cacheTTL())
 }
 
 // deleteSecret deletes the existing secret to reset the versioning.
@@ -311,6 +336,75 @@ func (k *HCPVaultKeyManager) Unset() {
 	k.isSet = false
 }
 
+// cacheTTL is how long the secret stays in the cache, secrets without a
+// cache duration are kept until they are set again.
+func (k *HCPVaultKeyManager) cacheTTL() time.Duration {
+	if k.cacheDuration <= 0 {
+		return oneYear
+	}
+	return k.cacheDuration
+}
+
+// StartRefresh fetches the secret every half cache duration in the
+// background, so it's replaced in the cache before it expires and reads
+// never wait on HCP Vault. It does nothing without a cache duration.
+func (k *HCPVaultKeyManager) StartRefresh() {
+	if !k.isSet || k.cacheDuration <= 0 || k.stopRefresh != nil {
+		return
+	}
+
+	k.stopRefresh = make(chan struct{})
+	k.refreshDone = make(chan struct{})
+	go k.refreshLoop(k.cacheDuration / 2)
+}
+
+func (k *HCPVaultKeyManager) refreshLoop(interval time.Duration) {
+	defer close(k.refreshDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			k.refresh()
+		case <-k.stopRefresh:
+			return
+		}
+	}
+}
+
+// refresh fetches the secret into the cache. When that fails the last good
+// secret is cached again, so deliveries keep working until HCP Vault is back.
+func (k *HCPVaultKeyManager) refresh() {
+	_, err := k.GetCurrentKey()
+	if err == nil {
+		return
+	}
+
+	log.WithError(err).Warn("failed to refresh the HCP Vault secret, serving the last good value")
+
+	lastKey := k.lastKey.Load()
+	if lastKey == nil {
+		return
+	}
+
+	err = k.cache.Set(context.Background(), RedisCacheKey, lastKey, k.cacheTTL())
+	if err != nil {
+		log.WithError(err).Warn("failed to cache the last good HCP Vault secret")
+	}
+}
+
+// StopRefresh stops the background refresh and waits for it to exit
+func (k *HCPVaultKeyManager) StopRefresh() {
+	if k.stopRefresh == nil {
+		return
+	}
+
+	k.stopOnce.Do(func() { close(k.stopRefresh) })
+	<-k.refreshDone
+}
+
 // isUnauthorizedError checks if the error is due to an expired or invalid token.
 func isUnauthorizedError(err error) bool {
 	var apiErr *APIError
@@ -371,16 +465,17 @@ func NewHCPVaultKeyManagerFromConfig(cfg config.HCPVaultConfig, licenser license
 	}
 
 	return &HCPVaultKeyManager{
-		ClientID:     cfg.ClientID,
-		ClientSecret: cfg.ClientSecret,
-		OrgID:        cfg.OrgID,
-		ProjectID:    cfg.ProjectID,
-		AppName:      cfg.AppName,
-		SecretName:   cfg.SecretName,
-		APIBaseURL:   HCPAPIBaseURL,
-		httpClient:   http.DefaultClient,
-		cache:        cache,
-		licenser:     licenser,
-		isSet:        true,
+		ClientID:      cfg.ClientID,
+		ClientSecret:  cfg.ClientSecret,
+		OrgID:         cfg.OrgID,
+		ProjectID:     cfg.ProjectID,
+		AppName:       cfg.AppName,
+		SecretName:    cfg.SecretName,
+		APIBaseURL:    HCPAPIBaseURL,
+		httpClient:    http.DefaultClient,
+		cache:         cache,
+		licenser:      licenser,
+		isSet:         true,
+		cacheDuration: cfg.CacheDuration,
 	}
 }
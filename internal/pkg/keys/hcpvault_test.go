@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -198,3 +199,106 @@ func TestHCPVaultKeyManagerEdgeCases(t *testing.T) {
 		assert.Contains(t, err.Error(), "unknown error")
 	})
 }
+
+func TestHCPVaultKeyManagerRefresh(t *testing.T) {
+	var fail atomic.Bool
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			http.Error(w, `{"code":14,"message":"unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+
+		n := fetches.Add(1)
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"secret":{"static_version":{"value":"mock-key-%d"}}}`, n)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ll := mocks.NewMockLicenser(ctrl)
+	ll.EXPECT().CredentialEncryption().Return(true).AnyTimes()
+
+	h := &HCPVaultKeyManager{
+		APIBaseURL:    server.URL,
+		OrgID:         "mock-org",
+		ProjectID:     "mock-proj",
+		AppName:       "mock-app",
+		SecretName:    "mock-secret",
+		token:         "dummy-token",
+		expiryTime:    time.Now().Add(time.Hour),
+		cache:         mcache.NewMemoryCache(),
+		httpClient:    http.DefaultClient,
+		licenser:      ll,
+		isSet:         true,
+		cacheDuration: time.Minute,
+	}
+
+	key, err := h.GetCurrentKeyFromCache()
+	assert.Nil(t, err)
+	assert.Equal(t, "mock-key-1", key)
+
+	// the refresh replaces the cached secret, reads are served from the cache
+	h.refresh()
+	for i := 0; i < 3; i++ {
+		key, err = h.GetCurrentKeyFromCache()
+		assert.Nil(t, err)
+		assert.Equal(t, "mock-key-2", key)
+	}
+	assert.Equal(t, int32(2), fetches.Load())
+
+	// a failed refresh keeps serving the last good secret, even once the
+	// cached one expired
+	fail.Store(true)
+	err = h.cache.Delete(context.Background(), RedisCacheKey)
+	assert.Nil(t, err)
+
+	h.refresh()
+	key, err = h.GetCurrentKeyFromCache()
+	assert.Nil(t, err)
+	assert.Equal(t, "mock-key-2", key)
+}
+
+func TestHCPVaultKeyManagerStartRefresh(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := fetches.Add(1)
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"secret":{"static_version":{"value":"mock-key-%d"}}}`, n)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ll := mocks.NewMockLicenser(ctrl)
+	ll.EXPECT().CredentialEncryption().Return(true).AnyTimes()
+
+	h := &HCPVaultKeyManager{
+		APIBaseURL:    server.URL,
+		OrgID:         "mock-org",
+		ProjectID:     "mock-proj",
+		AppName:       "mock-app",
+		SecretName:    "mock-secret",
+		token:         "dummy-token",
+		expiryTime:    time.Now().Add(time.Hour),
+		cache:         mcache.NewMemoryCache(),
+		httpClient:    http.DefaultClient,
+		licenser:      ll,
+		isSet:         true,
+		cacheDuration: 20 * time.Millisecond,
+	}
+
+	h.StartRefresh()
+	assert.Eventually(t, func() bool { return fetches.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	h.StopRefresh()
+	fetched := fetches.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, fetched, fetches.Load())
+
+	// stopping again doesn't block
+	h.StopRefresh()
+}
@@ -11,6 +11,7 @@ var (
 		"endpoints": {
 			"secrets": "secrets_cipher",
 			"authentication_type_api_key_header_value": "authentication_type_api_key_header_value_cipher",
+			"authentication_type_digest_password":      "authentication_type_digest_password_cipher",
 		},
 	}
 )
@@ -22,6 +23,11 @@ type KeyManager interface {
 	SetKey(newKey string) error
 }
 
+// Refresher is a KeyManager that refreshes its key in the background
+type Refresher interface {
+	StopRefresh()
+}
+
 var kmSingleton atomic.Value
 
 func Set(km KeyManager) error {
@@ -38,3 +44,16 @@ func Get() (KeyManager, error) {
 
 	return *km, nil
 }
+
+// StopRefresh stops the background refresh of the KeyManager passed to Set,
+// if it refreshes its key
+func StopRefresh() {
+	km, err := Get()
+	if err != nil {
+		return
+	}
+
+	if r, ok := km.(Refresher); ok {
+		r.StopRefresh()
+	}
+}
@@ -2,6 +2,8 @@ package limiter
 
 import (
 	"context"
+	"time"
+
 	"github.com/frain-dev/convoy/config"
 	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
 )
@@ -10,6 +12,16 @@ type RateLimiter interface {
 	// Allow rate limits outgoing events to endpoints based on a rate in a specified time duration by the endpoint id
 	Allow(ctx context.Context, key string, rate int) error
 	AllowWithDuration(ctx context.Context, key string, rate int, duration int) error
+
+	// Acquire takes one of the limit in-flight slots of key and returns the token it is held with,
+	// slots that aren't released are given back after ttl
+	Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (string, error)
+	// Release gives back the slot of key held with token
+	Release(ctx context.Context, key string, token string) error
+
+	// AllowOncePer lets one call for key through per interval, the others fail with
+	// the time left until the next one is let through
+	AllowOncePer(ctx context.Context, key string, interval time.Duration) error
 }
 
 func NewLimiter(cfg config.Configuration) (RateLimiter, error) {
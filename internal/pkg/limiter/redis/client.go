@@ -7,12 +7,46 @@ import (
 
 	"github.com/frain-dev/convoy/internal/pkg/rdb"
 	"github.com/go-redis/redis_rate/v10"
+	"github.com/oklog/ulid/v2"
+	"github.com/redis/go-redis/v9"
 )
 
-var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+var (
+	ErrRateLimitExceeded        = errors.New("rate limit exceeded")
+	ErrConcurrencyLimitExceeded = errors.New("concurrency limit exceeded")
+	ErrIntervalNotElapsed       = errors.New("minimum interval has not elapsed")
+)
+
+// acquireScript holds the slots of a key in a sorted set scored by when they
+// expire, expired slots are dropped before the free ones are counted.
+var acquireScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZCARD", KEYS[1]) >= limit then
+    return 0
+end
+
+redis.call("ZADD", KEYS[1], now + ttl, ARGV[4])
+redis.call("PEXPIRE", KEYS[1], ttl)
+return 1
+`)
+
+// allowOncePerScript holds a key for the interval after the call it let
+// through, and returns how long is left of it when the key is already held.
+var allowOncePerScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], 1, "NX", "PX", ARGV[1]) then
+    return 0
+end
+
+return redis.call("PTTL", KEYS[1])
+`)
 
 type RedisLimiter struct {
 	limiter *redis_rate.Limiter
+	client  redis.UniversalClient
 }
 
 func NewRedisLimiter(addresses []string) (*RedisLimiter, error) {
@@ -22,7 +56,7 @@ func NewRedisLimiter(addresses []string) (*RedisLimiter, error) {
 	}
 
 	c := redis_rate.NewLimiter(client.Client())
-	r := &RedisLimiter{limiter: c}
+	r := &RedisLimiter{limiter: c, client: client.Client()}
 
 	return r, nil
 }
@@ -75,11 +109,61 @@ func (r *RedisLimiter) AllowWithDuration(ctx context.Context, key string, limit
 	return nil
 }
 
+// Acquire takes one of the limit in-flight slots of key. A slot that is never
+// released, e.g. because the worker holding it died, expires after ttl.
+func (r *RedisLimiter) Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (string, error) {
+	token := ulid.Make().String()
+
+	acquired, err := acquireScript.Run(ctx, r.client, []string{key}, time.Now().UnixMilli(), ttl.Milliseconds(), limit, token).Int()
+	if err != nil {
+		return "", err
+	}
+
+	if acquired == 0 {
+		return "", ErrConcurrencyLimitExceeded
+	}
+
+	return token, nil
+}
+
+// Release gives back the slot of key held with token.
+func (r *RedisLimiter) Release(ctx context.Context, key string, token string) error {
+	return r.client.ZRem(ctx, key, token).Err()
+}
+
+// AllowOncePer lets one call for key through per interval. The others fail with
+// a RedisLimiterError holding the time left until the next call is let through.
+func (r *RedisLimiter) AllowOncePer(ctx context.Context, key string, interval time.Duration) error {
+	if interval.Milliseconds() <= 0 {
+		return nil
+	}
+
+	remaining, err := allowOncePerScript.Run(ctx, r.client, []string{key}, interval.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+
+	if remaining > 0 {
+		return &RedisLimiterError{
+			delay: time.Duration(remaining) * time.Millisecond,
+			err:   ErrIntervalNotElapsed,
+		}
+	}
+
+	return nil
+}
+
 type RedisLimiterError struct {
 	delay time.Duration
 	err   error
 }
 
+// NewRedisLimiterError returns err as a limiter error that is let through
+// again after delay
+func NewRedisLimiterError(delay time.Duration, err error) *RedisLimiterError {
+	return &RedisLimiterError{delay: delay, err: err}
+}
+
 func (e *RedisLimiterError) Error() string {
 	return e.err.Error()
 }
@@ -67,3 +67,60 @@ func Test_RateLimitAllow(t *testing.T) {
 		})
 	}
 }
+
+func Test_ConcurrencyLimitAcquire(t *testing.T) {
+	limiter, err := NewRedisLimiter(getDSN())
+	require.NoError(t, err)
+
+	key := ulid.Make().String()
+
+	first, err := limiter.Acquire(context.Background(), key, 2, time.Minute)
+	require.NoError(t, err)
+
+	_, err = limiter.Acquire(context.Background(), key, 2, time.Minute)
+	require.NoError(t, err)
+
+	_, err = limiter.Acquire(context.Background(), key, 2, time.Minute)
+	require.ErrorIs(t, err, ErrConcurrencyLimitExceeded)
+
+	require.NoError(t, limiter.Release(context.Background(), key, first))
+
+	_, err = limiter.Acquire(context.Background(), key, 2, time.Minute)
+	require.NoError(t, err)
+}
+
+func Test_ConcurrencyLimitExpiredSlot(t *testing.T) {
+	limiter, err := NewRedisLimiter(getDSN())
+	require.NoError(t, err)
+
+	key := ulid.Make().String()
+
+	_, err = limiter.Acquire(context.Background(), key, 1, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = limiter.Acquire(context.Background(), key, 1, 100*time.Millisecond)
+	require.ErrorIs(t, err, ErrConcurrencyLimitExceeded)
+
+	time.Sleep(200 * time.Millisecond)
+
+	_, err = limiter.Acquire(context.Background(), key, 1, 100*time.Millisecond)
+	require.NoError(t, err)
+}
+
+func Test_AllowOncePer(t *testing.T) {
+	limiter, err := NewRedisLimiter(getDSN())
+	require.NoError(t, err)
+
+	key := ulid.Make().String()
+
+	require.NoError(t, limiter.AllowOncePer(context.Background(), key, 200*time.Millisecond))
+
+	err = limiter.AllowOncePer(context.Background(), key, 200*time.Millisecond)
+	require.ErrorIs(t, GetRawError(err), ErrIntervalNotElapsed)
+	require.Greater(t, GetRetryAfter(err), time.Duration(0))
+	require.LessOrEqual(t, GetRetryAfter(err), 200*time.Millisecond)
+
+	time.Sleep(GetRetryAfter(err) + 50*time.Millisecond)
+
+	require.NoError(t, limiter.AllowOncePer(context.Background(), key, 200*time.Millisecond))
+}
@@ -1,12 +1,14 @@
 package metrics
 
 import (
+	"context"
 	"sync"
 
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/pkg/license"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -18,6 +20,8 @@ const (
 	projectLabel  = "project"
 	sourceLabel   = "source"
 	endpointLabel = "endpoint"
+
+	traceIDExemplarLabel = "trace_id"
 )
 
 // Metrics for the data plane
@@ -28,6 +32,10 @@ type Metrics struct {
 	IngestErrorsTotal    *prometheus.CounterVec
 	IngestLatency        *prometheus.HistogramVec
 	EventDeliveryLatency *prometheus.HistogramVec
+
+	// exemplarsEnabled is set when traces are exported with otel, so
+	// latency samples can link to the trace that produced them.
+	exemplarsEnabled bool
 }
 
 func GetDPInstance(licenser license.Licenser) *Metrics {
@@ -65,7 +73,8 @@ func InitMetrics(licenser license.Licenser) *Metrics {
 	}
 
 	m := &Metrics{
-		IsEnabled: true,
+		IsEnabled:        true,
+		exemplarsEnabled: cfg.Metrics.Backend == config.PrometheusMetricsProvider && cfg.Tracer.Type == config.OTelTracerProvider,
 
 		IngestTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -108,11 +117,31 @@ func InitMetrics(licenser license.Licenser) *Metrics {
 	return m
 }
 
-func (m *Metrics) RecordEndToEndLatency(ev *datastore.EventDelivery) {
+func (m *Metrics) RecordEndToEndLatency(ctx context.Context, ev *datastore.EventDelivery) {
 	if !m.IsEnabled {
 		return
 	}
-	m.EventDeliveryLatency.With(prometheus.Labels{projectLabel: ev.ProjectID, endpointLabel: ev.EndpointID}).Observe(ev.LatencySeconds)
+
+	observer := m.EventDeliveryLatency.With(prometheus.Labels{projectLabel: ev.ProjectID, endpointLabel: ev.EndpointID})
+	if m.exemplarsEnabled {
+		if exemplar := traceExemplar(ctx); exemplar != nil {
+			observer.(prometheus.ExemplarObserver).ObserveWithExemplar(ev.LatencySeconds, exemplar)
+			return
+		}
+	}
+
+	observer.Observe(ev.LatencySeconds)
+}
+
+// traceExemplar returns the exemplar labels for the sampled span in ctx,
+// or nil when there is no span worth linking to.
+func traceExemplar(ctx context.Context) prometheus.Labels {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil
+	}
+
+	return prometheus.Labels{traceIDExemplarLabel: sc.TraceID().String()}
 }
 
 func (m *Metrics) RecordIngestLatency(projectId string, latency float64) {
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecordEndToEndLatency_Exemplars(t *testing.T) {
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name            string
+		tracer          config.TracerProvider
+		ctx             context.Context
+		wantExemplarIDs []string
+	}{
+		{
+			name:            "otel tracer with active span",
+			tracer:          config.OTelTracerProvider,
+			ctx:             spanCtx,
+			wantExemplarIDs: []string{traceID.String()},
+		},
+		{
+			name:   "otel tracer without span",
+			tracer: config.OTelTracerProvider,
+			ctx:    context.Background(),
+		},
+		{
+			name:   "non otel tracer with active span",
+			tracer: config.SentryTracerProvider,
+			ctx:    spanCtx,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			err := config.LoadConfig("")
+			require.NoError(t, err)
+
+			err = config.Override(&config.Configuration{
+				Metrics: config.MetricsConfiguration{
+					IsEnabled: true,
+					Backend:   config.PrometheusMetricsProvider,
+				},
+				Tracer: config.TracerConfiguration{Type: tc.tracer},
+			})
+			require.NoError(t, err)
+
+			licenser := mocks.NewMockLicenser(ctrl)
+			licenser.EXPECT().CanExportPrometheusMetrics().Return(true)
+
+			m := InitMetrics(licenser)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(m.EventDeliveryLatency)
+
+			m.RecordEndToEndLatency(tc.ctx, &datastore.EventDelivery{ProjectID: "project-1", EndpointID: "endpoint-1", LatencySeconds: 0.3})
+
+			families, err := reg.Gather()
+			require.NoError(t, err)
+			require.Len(t, families, 1)
+
+			histogram := families[0].GetMetric()[0].GetHistogram()
+			require.Equal(t, uint64(1), histogram.GetSampleCount())
+
+			var exemplarIDs []string
+			for _, bucket := range histogram.GetBucket() {
+				if bucket.GetExemplar() != nil {
+					exemplarIDs = append(exemplarIDs, traceIDFromExemplar(bucket.GetExemplar()))
+				}
+			}
+			require.Equal(t, tc.wantExemplarIDs, exemplarIDs)
+		})
+	}
+}
+
+func traceIDFromExemplar(e *dto.Exemplar) string {
+	for _, l := range e.GetLabel() {
+		if l.GetName() == traceIDExemplarLabel {
+			return l.GetValue()
+		}
+	}
+
+	return ""
+}
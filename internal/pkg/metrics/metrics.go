@@ -33,6 +33,9 @@ func Reset() {
 func RegisterQueueMetrics(q queue.Queuer, db database.Database, cbm *cb.CircuitBreakerManager) {
 	configuration, err := config.Get()
 	if err == nil && configuration.Metrics.IsEnabled {
+		pending := NewPendingDeliveriesCollector(postgres.NewProjectRepo(db), postgres.NewEventDeliveryRepo(db), configuration.Metrics)
+		Reg().MustRegister(pending)
+
 		if cbm == nil { // cbm can be nil if the feature flag is not enabled
 			Reg().MustRegister(q.(*redisqueue.RedisQueue), db.(*postgres.Postgres))
 		} else {
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var pendingEventDeliveriesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName("convoy", "", "pending_event_deliveries"),
+	"Number of event deliveries waiting to be delivered per project and status",
+	[]string{projectLabel, "status"}, nil,
+)
+
+// pendingStatuses are the delivery statuses that make up the backlog
+var pendingStatuses = []datastore.EventDeliveryStatus{
+	datastore.ScheduledEventStatus,
+	datastore.ProcessingEventStatus,
+}
+
+type pendingDeliveries struct {
+	ProjectID string
+	Status    datastore.EventDeliveryStatus
+	Total     int64
+}
+
+// PendingDeliveriesCollector exposes how many deliveries are Scheduled or
+// Processing in each project. The counts are sampled at most once every
+// sample time seconds, scrapes in between are served from the last sample.
+type PendingDeliveriesCollector struct {
+	projectRepo       datastore.ProjectRepository
+	eventDeliveryRepo datastore.EventDeliveryRepository
+	sampleTime        time.Duration
+
+	mu      sync.Mutex
+	lastRun time.Time
+	cached  []pendingDeliveries
+}
+
+func NewPendingDeliveriesCollector(projectRepo datastore.ProjectRepository, eventDeliveryRepo datastore.EventDeliveryRepository, cfg config.MetricsConfiguration) *PendingDeliveriesCollector {
+	return &PendingDeliveriesCollector{
+		projectRepo:       projectRepo,
+		eventDeliveryRepo: eventDeliveryRepo,
+		sampleTime:        time.Duration(cfg.Prometheus.SampleTime) * time.Second,
+	}
+}
+
+func (p *PendingDeliveriesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pendingEventDeliveriesDesc
+}
+
+func (p *PendingDeliveriesCollector) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.cached == nil || !p.lastRun.Add(p.sampleTime).After(now) {
+		pending, err := p.collectPendingDeliveries(context.Background(), now)
+		if err != nil {
+			log.Errorf("Failed to collect pending event deliveries: %v", err)
+			return
+		}
+
+		p.cached = pending
+		p.lastRun = now
+	}
+
+	for _, metric := range p.cached {
+		ch <- prometheus.MustNewConstMetric(
+			pendingEventDeliveriesDesc,
+			prometheus.GaugeValue,
+			float64(metric.Total),
+			metric.ProjectID,
+			strings.ToLower(string(metric.Status)),
+		)
+	}
+}
+
+// collectPendingDeliveries counts each project's pending deliveries, projects
+// without any are left out so they don't add series
+func (p *PendingDeliveriesCollector) collectPendingDeliveries(ctx context.Context, now time.Time) ([]pendingDeliveries, error) {
+	projects, err := p.projectRepo.LoadProjects(ctx, &datastore.ProjectFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	params := datastore.SearchParams{CreatedAtStart: 0, CreatedAtEnd: now.Unix()}
+
+	pending := make([]pendingDeliveries, 0)
+	for _, project := range projects {
+		for _, status := range pendingStatuses {
+			total, err := p.eventDeliveryRepo.CountDeliveriesByStatus(ctx, project.UID, status, params)
+			if err != nil {
+				return nil, err
+			}
+
+			if total == 0 {
+				continue
+			}
+
+			pending = append(pending, pendingDeliveries{ProjectID: project.UID, Status: status, Total: total})
+		}
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPendingDeliveriesCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	eventDeliveryRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+
+	projectRepo.EXPECT().LoadProjects(gomock.Any(), gomock.Any()).Times(1).
+		Return([]*datastore.Project{{UID: "project-1"}, {UID: "project-2"}, {UID: "project-3"}}, nil)
+
+	counts := map[string]map[datastore.EventDeliveryStatus]int64{
+		"project-1": {datastore.ScheduledEventStatus: 4, datastore.ProcessingEventStatus: 2},
+		"project-2": {datastore.ScheduledEventStatus: 0, datastore.ProcessingEventStatus: 7},
+		"project-3": {datastore.ScheduledEventStatus: 0, datastore.ProcessingEventStatus: 0},
+	}
+	for projectID, byStatus := range counts {
+		for status, total := range byStatus {
+			eventDeliveryRepo.EXPECT().CountDeliveriesByStatus(gomock.Any(), projectID, status, gomock.Any()).Times(1).Return(total, nil)
+		}
+	}
+
+	collector := NewPendingDeliveriesCollector(projectRepo, eventDeliveryRepo, config.MetricsConfiguration{
+		Prometheus: config.PrometheusMetricsConfiguration{SampleTime: 60},
+	})
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(collector)
+
+	want := `
+# HELP convoy_pending_event_deliveries Number of event deliveries waiting to be delivered per project and status
+# TYPE convoy_pending_event_deliveries gauge
+convoy_pending_event_deliveries{project="project-1",status="processing"} 2
+convoy_pending_event_deliveries{project="project-1",status="scheduled"} 4
+convoy_pending_event_deliveries{project="project-2",status="processing"} 7
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(want), "convoy_pending_event_deliveries"))
+
+	// scrapes within the sample time are served from the last sample
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(want), "convoy_pending_event_deliveries"))
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -31,6 +32,7 @@ import (
 	"github.com/frain-dev/convoy/auth/realm_chain"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/queue"
 	"github.com/frain-dev/convoy/util"
 
 	"github.com/go-chi/chi/v5"
@@ -117,6 +119,22 @@ func RateLimiterHandler(rateLimiter limiter.RateLimiter, httpApiRateLimit int) f
 	}
 }
 
+// QueueBackpressure rejects new events with a 429 while the event queue is
+// saturated, so producers back off instead of growing the queue unboundedly.
+func QueueBackpressure(bp *queue.Backpressure) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if bp == nil || bp.Check() == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(bp.RetryAfter().Seconds()))))
+			_ = render.Render(w, r, util.NewErrorResponse(queue.ErrQueueSaturated.Error(), http.StatusTooManyRequests))
+		})
+	}
+}
+
 func InstrumentPath(l license.Licenser) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -318,6 +336,7 @@ func Pagination(next http.Handler) http.Handler {
 		rawDirection := r.URL.Query().Get("direction")
 		rawNextCursor := r.URL.Query().Get("next_page_cursor")
 		rawPrevCursor := r.URL.Query().Get("prev_page_cursor")
+		sortField := r.URL.Query().Get("sortField")
 
 		if len(rawPerPage) == 0 {
 			rawPerPage = "20"
@@ -338,6 +357,7 @@ func Pagination(next http.Handler) http.Handler {
 			Direction:  datastore.PageDirection(rawDirection),
 			NextCursor: rawNextCursor,
 			PrevCursor: rawPrevCursor,
+			SortField:  sortField,
 		}
 		pageable.SetCursors()
 
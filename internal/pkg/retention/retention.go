@@ -3,12 +3,16 @@ package retention
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/database"
 	"github.com/frain-dev/convoy/database/postgres"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/pkg/log"
 	partman "github.com/jirevwe/go_partman"
 	"os"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -85,6 +89,7 @@ func (r *PartitionRetentionPolicy) Start(ctx context.Context, sampleRate time.Du
 		}
 
 		projectRepo := postgres.NewProjectRepo(r.db)
+		eventDeliveryRepo := postgres.NewEventDeliveryRepo(r.db)
 
 		for {
 			select {
@@ -96,6 +101,13 @@ func (r *PartitionRetentionPolicy) Start(ctx context.Context, sampleRate time.Du
 					r.logger.WithError(pErr).Error("failed to load projects")
 				}
 
+				// monthly event_deliveries partitions are maintained by Perform,
+				// the partitioner would add daily ones overlapping them
+				granularity, gErr := eventDeliveryRepo.EventDeliveryPartitionGranularity(context.Background())
+				if gErr != nil {
+					r.logger.WithError(gErr).Error("failed to fetch the event deliveries partition granularity")
+				}
+
 				for _, project := range projects {
 					err = r.partitioner.AddManagedTable(partman.Table{
 						Name:              "events",
@@ -127,19 +139,21 @@ func (r *PartitionRetentionPolicy) Start(ctx context.Context, sampleRate time.Du
 						r.logger.WithError(err).Error("failed to add convoy.events to managed tables")
 					}
 
-					err = r.partitioner.AddManagedTable(partman.Table{
-						Name:              "event_deliveries",
-						Schema:            "convoy",
-						TenantId:          project.UID,
-						TenantIdColumn:    "project_id",
-						PartitionBy:       "created_at",
-						PartitionType:     partman.TypeRange,
-						RetentionPeriod:   r.retentionPeriod,
-						PartitionInterval: time.Hour * 24,
-						PartitionCount:    10,
-					})
-					if err != nil {
-						r.logger.WithError(err).Error("failed to add convoy.event_deliveries to managed tables")
+					if gErr == nil && granularity != datastore.MonthlyPartitionGranularity {
+						err = r.partitioner.AddManagedTable(partman.Table{
+							Name:              "event_deliveries",
+							Schema:            "convoy",
+							TenantId:          project.UID,
+							TenantIdColumn:    "project_id",
+							PartitionBy:       "created_at",
+							PartitionType:     partman.TypeRange,
+							RetentionPeriod:   r.retentionPeriod,
+							PartitionInterval: time.Hour * 24,
+							PartitionCount:    10,
+						})
+						if err != nil {
+							r.logger.WithError(err).Error("failed to add convoy.event_deliveries to managed tables")
+						}
 					}
 
 					err = r.partitioner.AddManagedTable(partman.Table{
@@ -163,7 +177,126 @@ func (r *PartitionRetentionPolicy) Start(ctx context.Context, sampleRate time.Du
 }
 
 func (r *PartitionRetentionPolicy) Perform(ctx context.Context) error {
-	return r.partitioner.Maintain(ctx)
+	err := r.partitioner.Maintain(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = r.maintainMonthlyEventDeliveryPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = purgeDeduplicationKeys(ctx, r.db, r.logger, time.Now().Add(-r.retentionPeriod))
+	if err != nil {
+		return err
+	}
+
+	return r.purgeEventTypeOverrides(ctx)
+}
+
+// purgeDeduplicationKeys deletes the event delivery deduplication keys
+// claimed before the events they were claimed for aged out.
+func purgeDeduplicationKeys(ctx context.Context, db database.Database, logger log.StdLogger, before time.Time) error {
+	purged, err := postgres.NewEventDeliveryRepo(db).PurgeEventDeliveryDeduplicationKeys(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		logger.Infof("purged %d event delivery deduplication keys", purged)
+	}
+
+	return nil
+}
+
+// maintainMonthlyEventDeliveryPartitions does for monthly event_deliveries
+// partitions what the partitioner does for daily ones. A month's partition
+// is dropped once its last day ages out of the retention policy, and every
+// project gets the partitions of the current and next month ahead of time.
+func (r *PartitionRetentionPolicy) maintainMonthlyEventDeliveryPartitions(ctx context.Context) error {
+	eventDeliveryRepo := postgres.NewEventDeliveryRepo(r.db)
+
+	granularity, err := eventDeliveryRepo.EventDeliveryPartitionGranularity(ctx)
+	if err != nil {
+		return err
+	}
+
+	if granularity != datastore.MonthlyPartitionGranularity {
+		return nil
+	}
+
+	now := time.Now()
+	dropped, err := eventDeliveryRepo.DropEventDeliveryPartitionsBefore(ctx, now.Add(-r.retentionPeriod))
+	if err != nil {
+		return err
+	}
+
+	if dropped > 0 {
+		r.logger.Infof("dropped %d monthly event deliveries partitions", dropped)
+	}
+
+	projects, err := postgres.NewProjectRepo(r.db).LoadProjects(ctx, &datastore.ProjectFilter{})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range projects {
+		_, err = eventDeliveryRepo.EnsureEventDeliveryPartitions(ctx, p.UID, now, now.AddDate(0, 1, 0))
+		if err != nil {
+			r.logger.WithError(err).Error("failed to create monthly event deliveries partitions")
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// purgeEventTypeOverrides applies the event type retention overrides to the
+// partitioned event_deliveries table. Partitions are dropped whole once they
+// age out of the retention policy, so overrides shorter than it are applied
+// by deleting the matching deliveries from the partitions still kept, and
+// overrides longer than it can't keep deliveries past their partition.
+func (r *PartitionRetentionPolicy) purgeEventTypeOverrides(ctx context.Context) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	eventTypePolicies, err := cfg.RetentionPolicy.EventTypeRetentionPeriods()
+	if err != nil {
+		return err
+	}
+
+	filters, capped := partitionedEventDeliveryRetentionFilters(time.Now(), r.retentionPeriod, eventTypePolicies)
+	if len(capped) > 0 {
+		r.logger.Warnf("event deliveries of %v are dropped with their partitions after the retention policy %s, their longer overrides don't apply to partitioned tables", capped, r.retentionPeriod)
+	}
+
+	if len(filters) == 0 {
+		return nil
+	}
+
+	projects, err := postgres.NewProjectRepo(r.db).LoadProjects(ctx, &datastore.ProjectFilter{})
+	if err != nil {
+		return err
+	}
+
+	eventDeliveryRepo := postgres.NewEventDeliveryRepo(r.db)
+
+	var errs []error
+	for _, p := range projects {
+		for _, filter := range filters {
+			err = eventDeliveryRepo.DeleteProjectEventDeliveries(ctx, p.UID, filter, true)
+			if err != nil {
+				r.logger.WithError(err).Error("failed to delete project event deliveries")
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 type DeleteRetentionPolicy struct {
@@ -191,6 +324,26 @@ func (d *DeleteRetentionPolicy) Perform(ctx context.Context) error {
 		return err
 	}
 
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	eventTypePolicies, err := cfg.RetentionPolicy.EventTypeRetentionPeriods()
+	if err != nil {
+		return err
+	}
+
+	payloadPolicy, err := cfg.RetentionPolicy.PayloadRetentionPeriod()
+	if err != nil {
+		return err
+	}
+
+	if payloadPolicy >= policy {
+		d.logger.Warnf("payload retention policy %s isn't shorter than the retention policy %s, payloads are kept with their deliveries", payloadPolicy, policy)
+		payloadPolicy = 0
+	}
+
 	filter := &datastore.ProjectFilter{}
 	projects, err := projectRepo.LoadProjects(context.Background(), filter)
 	if err != nil {
@@ -202,25 +355,36 @@ func (d *DeleteRetentionPolicy) Perform(ctx context.Context) error {
 		return nil
 	}
 
-	for _, p := range projects {
+	purge := func(ctx context.Context, p *datastore.Project) error {
+		var errs []error
+
 		deliveryFilter := &datastore.DeliveryAttemptsFilter{
 			CreatedAtStart: 0,
 			CreatedAtEnd:   time.Now().Add(-policy).Unix(),
 		}
 
-		err = deliveryAttemptsRepo.DeleteProjectDeliveriesAttempts(ctx, p.UID, deliveryFilter, true)
+		err := deliveryAttemptsRepo.DeleteProjectDeliveriesAttempts(ctx, p.UID, deliveryFilter, true)
 		if err != nil {
 			d.logger.WithError(err).Error("failed to delete project delivery attempts")
+			errs = append(errs, err)
 		}
 
-		eventDeliveryFilter := &datastore.EventDeliveryFilter{
-			CreatedAtStart: 0,
-			CreatedAtEnd:   time.Now().Add(-policy).Unix(),
+		for _, eventDeliveryFilter := range eventDeliveryRetentionFilters(time.Now(), policy, eventTypePolicies) {
+			err = eventDeliveryRepo.DeleteProjectEventDeliveries(ctx, p.UID, eventDeliveryFilter, true)
+			if err != nil {
+				d.logger.WithError(err).Error("failed to delete project event deliveries")
+				errs = append(errs, err)
+			}
 		}
 
-		err = eventDeliveryRepo.DeleteProjectEventDeliveries(ctx, p.UID, eventDeliveryFilter, true)
-		if err != nil {
-			d.logger.WithError(err).Error("failed to delete project event deliveries")
+		if payloadPolicy > 0 {
+			stripped, err := eventDeliveryRepo.StripProjectEventDeliveryPayloads(ctx, p.UID, payloadRetentionFilter(time.Now(), payloadPolicy))
+			if err != nil {
+				d.logger.WithError(err).Error("failed to strip project event delivery payloads")
+				errs = append(errs, err)
+			} else if stripped > 0 {
+				d.logger.Infof("stripped the payloads of %d event deliveries in project %s", stripped, p.UID)
+			}
 		}
 
 		eventFilter := &datastore.EventFilter{
@@ -230,17 +394,163 @@ func (d *DeleteRetentionPolicy) Perform(ctx context.Context) error {
 		err = eventRepo.DeleteProjectEvents(ctx, p.UID, eventFilter, true)
 		if err != nil {
 			d.logger.WithError(err).Error("failed to delete project events")
+			errs = append(errs, err)
 		}
 
 		err = eventRepo.DeleteProjectTokenizedEvents(ctx, p.UID, eventFilter)
 		if err != nil {
 			d.logger.WithError(err).Error("failed to delete tokenized project events")
+			errs = append(errs, err)
 		}
+
+		return errors.Join(errs...)
+	}
+
+	result := purgeProjects(ctx, projects, cfg.RetentionPolicy.GetConcurrency(), purge)
+	if len(result.Failed) > 0 {
+		d.logger.WithError(result.Err()).Errorf("retention policy failed to purge %d of %d projects", len(result.Failed), len(projects))
+	}
+
+	err = purgeDeduplicationKeys(ctx, d.db, d.logger, time.Now().Add(-policy))
+	if err != nil {
+		d.logger.WithError(err).Error("failed to purge event delivery deduplication keys")
 	}
 
 	return nil
 }
 
+// PurgeResult aggregates the outcome of purging a set of projects.
+type PurgeResult struct {
+	Purged []string
+	Failed map[string]error
+}
+
+// Err joins the errors of every project that failed to purge.
+func (p *PurgeResult) Err() error {
+	ids := make([]string, 0, len(p.Failed))
+	for id := range p.Failed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	errs := make([]error, 0, len(ids))
+	for _, id := range ids {
+		errs = append(errs, fmt.Errorf("project %s: %w", id, p.Failed[id]))
+	}
+
+	return errors.Join(errs...)
+}
+
+// purgeProjects runs purge for every project, with at most concurrency
+// purges in flight at once to protect the database.
+func purgeProjects(ctx context.Context, projects []*datastore.Project, concurrency int, purge func(context.Context, *datastore.Project) error) *PurgeResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	result := &PurgeResult{Failed: map[string]error{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, p := range projects {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(p *datastore.Project) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := purge(ctx, p)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				result.Failed[p.UID] = err
+				return
+			}
+			result.Purged = append(result.Purged, p.UID)
+		}(p)
+	}
+
+	wg.Wait()
+	sort.Strings(result.Purged)
+
+	return result
+}
+
+// eventDeliveryRetentionFilters returns one delete filter per retention
+// period. Event types with an override get their own cutoff and are left
+// out of the default policy's filter.
+func eventDeliveryRetentionFilters(now time.Time, policy time.Duration, eventTypePolicies map[string]time.Duration) []*datastore.EventDeliveryFilter {
+	overridden := make([]string, 0, len(eventTypePolicies))
+	for eventType := range eventTypePolicies {
+		overridden = append(overridden, eventType)
+	}
+	sort.Strings(overridden)
+
+	filters := []*datastore.EventDeliveryFilter{
+		{
+			CreatedAtStart:    0,
+			CreatedAtEnd:      now.Add(-policy).Unix(),
+			ExcludeEventTypes: overridden,
+		},
+	}
+
+	for _, eventType := range overridden {
+		filters = append(filters, &datastore.EventDeliveryFilter{
+			CreatedAtStart: 0,
+			CreatedAtEnd:   now.Add(-eventTypePolicies[eventType]).Unix(),
+			EventTypes:     []string{eventType},
+		})
+	}
+
+	return filters
+}
+
+// partitionedEventDeliveryRetentionFilters returns one delete filter per
+// event type whose override is shorter than the partitions' retention
+// period, and the event types whose overrides are longer, which can't be
+// honoured once their partition is dropped.
+func partitionedEventDeliveryRetentionFilters(now time.Time, policy time.Duration, eventTypePolicies map[string]time.Duration) ([]*datastore.EventDeliveryFilter, []string) {
+	eventTypes := make([]string, 0, len(eventTypePolicies))
+	for eventType := range eventTypePolicies {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+
+	var filters []*datastore.EventDeliveryFilter
+	var capped []string
+	for _, eventType := range eventTypes {
+		period := eventTypePolicies[eventType]
+		switch {
+		case period < policy:
+			filters = append(filters, &datastore.EventDeliveryFilter{
+				CreatedAtStart: 0,
+				CreatedAtEnd:   now.Add(-period).Unix(),
+				EventTypes:     []string{eventType},
+			})
+		case period > policy:
+			capped = append(capped, eventType)
+		}
+	}
+
+	return filters, capped
+}
+
+// payloadRetentionFilter matches the deliveries whose payloads have been
+// kept for longer than the payload retention period.
+func payloadRetentionFilter(now time.Time, payloadPolicy time.Duration) *datastore.EventDeliveryFilter {
+	return &datastore.EventDeliveryFilter{
+		CreatedAtStart: 0,
+		CreatedAtEnd:   now.Add(-payloadPolicy).Unix(),
+	}
+}
+
 func (d *DeleteRetentionPolicy) Start(_ context.Context, _ time.Duration) {}
 
 func NewDeleteRetentionPolicy(db database.Database, logger log.StdLogger) *DeleteRetentionPolicy {
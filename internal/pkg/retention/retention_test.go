@@ -0,0 +1,225 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_eventDeliveryRetentionFilters(t *testing.T) {
+	now := time.Now()
+	policy := 720 * time.Hour
+
+	tests := []struct {
+		name              string
+		eventTypePolicies map[string]time.Duration
+		want              []*datastore.EventDeliveryFilter
+	}{
+		{
+			name: "should_use_default_policy_without_overrides",
+			want: []*datastore.EventDeliveryFilter{
+				{CreatedAtEnd: now.Add(-policy).Unix(), ExcludeEventTypes: []string{}},
+			},
+		},
+		{
+			name: "should_use_per_event_type_cutoffs",
+			eventTypePolicies: map[string]time.Duration{
+				"heartbeat": 24 * time.Hour,
+				"audit.log": 8760 * time.Hour,
+			},
+			want: []*datastore.EventDeliveryFilter{
+				{CreatedAtEnd: now.Add(-policy).Unix(), ExcludeEventTypes: []string{"audit.log", "heartbeat"}},
+				{CreatedAtEnd: now.Add(-8760 * time.Hour).Unix(), EventTypes: []string{"audit.log"}},
+				{CreatedAtEnd: now.Add(-24 * time.Hour).Unix(), EventTypes: []string{"heartbeat"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, eventDeliveryRetentionFilters(now, policy, tt.eventTypePolicies))
+		})
+	}
+}
+
+func Test_partitionedEventDeliveryRetentionFilters(t *testing.T) {
+	now := time.Now()
+	policy := 720 * time.Hour
+
+	filters, capped := partitionedEventDeliveryRetentionFilters(now, policy, map[string]time.Duration{
+		"heartbeat":  24 * time.Hour,
+		"ping":       time.Hour,
+		"audit.log":  8760 * time.Hour,
+		"invoice.ok": policy,
+	})
+
+	// shorter overrides are deleted from the partitions still kept
+	require.Equal(t, []*datastore.EventDeliveryFilter{
+		{CreatedAtEnd: now.Add(-24 * time.Hour).Unix(), EventTypes: []string{"heartbeat"}},
+		{CreatedAtEnd: now.Add(-time.Hour).Unix(), EventTypes: []string{"ping"}},
+	}, filters)
+
+	// longer ones can't outlive their partition
+	require.Equal(t, []string{"audit.log"}, capped)
+
+	filters, capped = partitionedEventDeliveryRetentionFilters(now, policy, nil)
+	require.Empty(t, filters)
+	require.Empty(t, capped)
+}
+
+func Test_eventDeliveryRetentionFilters_AuditSurvivesHeartbeatPurge(t *testing.T) {
+	now := time.Now()
+	createdAt := now.Add(-48 * time.Hour).Unix()
+
+	filters := eventDeliveryRetentionFilters(now, 24*time.Hour, map[string]time.Duration{
+		"audit.log": 8760 * time.Hour,
+	})
+
+	matches := func(eventType string) bool {
+		for _, f := range filters {
+			if createdAt < f.CreatedAtStart || createdAt > f.CreatedAtEnd {
+				continue
+			}
+
+			if len(f.EventTypes) > 0 && !contains(f.EventTypes, eventType) {
+				continue
+			}
+
+			if contains(f.ExcludeEventTypes, eventType) {
+				continue
+			}
+
+			return true
+		}
+
+		return false
+	}
+
+	require.True(t, matches("heartbeat"))
+	require.False(t, matches("audit.log"))
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func Test_purgeProjects(t *testing.T) {
+	projects := make([]*datastore.Project, 6)
+	for i := range projects {
+		projects[i] = &datastore.Project{UID: string(rune('a' + i))}
+	}
+
+	t.Run("should_purge_up_to_the_concurrency_cap_at_once", func(t *testing.T) {
+		const concurrency = 3
+
+		var inFlight, maxInFlight int32
+		release := make(chan struct{})
+		started := make(chan struct{}, len(projects))
+
+		purge := func(_ context.Context, _ *datastore.Project) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+
+			started <- struct{}{}
+			<-release
+			atomic.AddInt32(&inFlight, -1)
+			return nil
+		}
+
+		done := make(chan *PurgeResult)
+		go func() { done <- purgeProjects(context.Background(), projects, concurrency, purge) }()
+
+		// the first batch starts together and nothing else until one finishes
+		for i := 0; i < concurrency; i++ {
+			<-started
+		}
+		select {
+		case <-started:
+			t.Fatal("purge started beyond the concurrency cap")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		result := <-done
+
+		require.Equal(t, int32(concurrency), atomic.LoadInt32(&maxInFlight))
+		require.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, result.Purged)
+		require.Empty(t, result.Failed)
+		require.NoError(t, result.Err())
+	})
+
+	t.Run("should_aggregate_failed_projects", func(t *testing.T) {
+		errDelete := errors.New("delete failed")
+
+		var mu sync.Mutex
+		var seen []string
+		purge := func(_ context.Context, p *datastore.Project) error {
+			mu.Lock()
+			seen = append(seen, p.UID)
+			mu.Unlock()
+
+			if p.UID == "b" || p.UID == "e" {
+				return errDelete
+			}
+			return nil
+		}
+
+		result := purgeProjects(context.Background(), projects, 4, purge)
+
+		require.Len(t, seen, len(projects))
+		require.Equal(t, []string{"a", "c", "d", "f"}, result.Purged)
+		require.Equal(t, map[string]error{"b": errDelete, "e": errDelete}, result.Failed)
+		require.ErrorIs(t, result.Err(), errDelete)
+		require.Equal(t, "project b: delete failed\nproject e: delete failed", result.Err().Error())
+	})
+
+	t.Run("should_purge_serially_without_a_cap", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		purge := func(_ context.Context, _ *datastore.Project) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			if n > atomic.LoadInt32(&maxInFlight) {
+				atomic.StoreInt32(&maxInFlight, n)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return nil
+		}
+
+		result := purgeProjects(context.Background(), projects, 0, purge)
+		require.Equal(t, int32(1), atomic.LoadInt32(&maxInFlight))
+		require.Len(t, result.Purged, len(projects))
+	})
+}
+
+func Test_payloadRetentionFilter(t *testing.T) {
+	now := time.Now()
+	payloadPolicy := 72 * time.Hour
+	policy := 720 * time.Hour
+
+	payloadFilter := payloadRetentionFilter(now, payloadPolicy)
+	require.Equal(t, &datastore.EventDeliveryFilter{CreatedAtEnd: now.Add(-payloadPolicy).Unix()}, payloadFilter)
+
+	// a delivery a week old loses its payload but isn't deleted yet
+	createdAt := now.Add(-7 * 24 * time.Hour).Unix()
+	require.LessOrEqual(t, createdAt, payloadFilter.CreatedAtEnd)
+
+	for _, f := range eventDeliveryRetentionFilters(now, policy, nil) {
+		require.Greater(t, createdAt, f.CreatedAtEnd)
+	}
+}
@@ -14,10 +14,20 @@ import (
 )
 
 const (
-	// Maximum message size allowed from peer.
-	maxMessageSize = 512
+	// Maximum message size allowed from peer, a larger message closes the
+	// connection. It fits an acknowledgement of up to maxAcksPerMessage ids.
+	maxMessageSize = 8192
 
 	maxDeviceLastSeenDuration = 10 * time.Second
+
+	// acknowledgements from a device are written once ackBatchSize of them
+	// are pending, or ackFlushInterval after the last write
+	ackBatchSize     = 100
+	ackFlushInterval = time.Second
+
+	// maxAcksPerMessage is the most event deliveries a device can acknowledge
+	// in one message, devices acknowledging more split them across messages
+	maxAcksPerMessage = ackBatchSize
 )
 
 var (
@@ -37,6 +47,9 @@ type Client struct {
 	conn WebSocketConnection
 
 	lock sync.RWMutex // protect Device from data race
+
+	ackLock     sync.Mutex // protect pendingAcks from data race
+	pendingAcks []string
 }
 
 func NewClient(ctx context.Context, conn WebSocketConnection, device *datastore.Device, sourceID string, deviceRepo datastore.DeviceRepository, eventDeliveryRepo datastore.EventDeliveryRepository) {
@@ -61,6 +74,11 @@ func NewClient(ctx context.Context, conn WebSocketConnection, device *datastore.
 func (c *Client) readPump(ctx context.Context, unregister chan *Client) {
 	defer c.Close(unregister)
 
+	stop := make(chan struct{})
+	go c.ackPump(ctx, stop)
+	defer close(stop)
+	defer c.flushAcks(ctx)
+
 	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetPingHandler(c.pingHandler(ctx))
 
@@ -114,7 +132,60 @@ func (c *Client) processMessage(ctx context.Context, messageType int, message []
 			log.WithError(err).Error("failed to unmarshal text message")
 			return
 		}
-		go c.UpdateEventDeliveryStatus(ctx, ed.UID, c.Device.ProjectID)
+
+		if len(ed.UID) > 0 {
+			c.queueAcks(ctx, ed.UID)
+		}
+		c.queueAcks(ctx, ed.UIDs...)
+	}
+}
+
+// queueAcks adds the event deliveries a device acknowledged to the pending
+// batch, the batch is written right away once it is full
+func (c *Client) queueAcks(ctx context.Context, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	c.ackLock.Lock()
+	c.pendingAcks = append(c.pendingAcks, ids...)
+
+	var batch []string
+	if len(c.pendingAcks) >= ackBatchSize {
+		batch, c.pendingAcks = c.pendingAcks, nil
+	}
+	c.ackLock.Unlock()
+
+	if len(batch) > 0 {
+		go c.AcknowledgeEventDeliveries(ctx, batch)
+	}
+}
+
+// flushAcks writes the pending acknowledgements
+func (c *Client) flushAcks(ctx context.Context) {
+	c.ackLock.Lock()
+	batch := c.pendingAcks
+	c.pendingAcks = nil
+	c.ackLock.Unlock()
+
+	if len(batch) > 0 {
+		c.AcknowledgeEventDeliveries(ctx, batch)
+	}
+}
+
+// ackPump writes the pending acknowledgements every ackFlushInterval until
+// stop is closed
+func (c *Client) ackPump(ctx context.Context, stop chan struct{}) {
+	ticker := time.NewTicker(ackFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.flushAcks(ctx)
+		case <-stop:
+			return
+		}
 	}
 }
 
@@ -212,6 +283,25 @@ func (c *Client) UpdateEventDeliveryStatus(ctx context.Context, id, projectId st
 	}
 }
 
+// AcknowledgeEventDeliveries marks the event deliveries the device received
+// as successful and acknowledged in bulk, deliveries acknowledged before,
+// e.g. re-sent to a device that reconnected, aren't counted again.
+func (c *Client) AcknowledgeEventDeliveries(ctx context.Context, ids []string) {
+	err := c.eventDeliveryRepo.UpdateStatusOfEventDeliveries(ctx, c.Device.ProjectID, ids, datastore.SuccessEventStatus)
+	if err != nil {
+		log.WithError(err).WithField("device_id", c.deviceID).Error("failed to update event deliveries status")
+		return
+	}
+
+	acked, err := c.eventDeliveryRepo.AcknowledgeEventDeliveries(ctx, c.Device.ProjectID, ids, time.Now())
+	if err != nil {
+		log.WithError(err).WithField("device_id", c.deviceID).Error("failed to acknowledge event deliveries")
+		return
+	}
+
+	log.WithFields(log.Fields{"device_id": c.deviceID}).Debugf("acknowledged %d of %d event deliveries", acked, len(ids))
+}
+
 func (c *Client) ResendEventDeliveries(ctx context.Context, since time.Time, events chan *CLIEvent) {
 	eds, err := c.eventDeliveryRepo.FindDiscardedEventDeliveries(ctx, c.Device.ProjectID, c.Device.UID,
 		datastore.SearchParams{CreatedAtStart: since.Unix(), CreatedAtEnd: time.Now().Unix()})
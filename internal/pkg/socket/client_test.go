@@ -2,13 +2,16 @@ package socket
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/mocks"
 	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -82,6 +85,76 @@ func TestUpdateEventDeliveryStatus(t *testing.T) {
 	c.UpdateEventDeliveryStatus(context.Background(), c.deviceID, c.Device.ProjectID)
 }
 
+func TestAcknowledgeEventDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := mocks.NewMockWebSocketConnection(ctrl)
+	r := provideRepo(ctrl)
+
+	c := provideClient(r, conn)
+
+	evd := r.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+	gomock.InOrder(
+		evd.EXPECT().UpdateStatusOfEventDeliveries(gomock.Any(), gomock.Any(), []string{"ed-1", "ed-2", "ed-3"}, datastore.SuccessEventStatus).
+			Return(nil),
+		evd.EXPECT().AcknowledgeEventDeliveries(gomock.Any(), gomock.Any(), []string{"ed-1", "ed-2", "ed-3"}, gomock.Any()).
+			Return(int64(3), nil),
+	)
+
+	c.processMessage(context.Background(), websocket.TextMessage, []byte(`{"uid":"ed-1"}`), nil)
+	c.processMessage(context.Background(), websocket.TextMessage, []byte(`{"uids":["ed-2","ed-3"]}`), nil)
+
+	// acknowledgements are held until the batch is flushed
+	c.flushAcks(context.Background())
+	c.flushAcks(context.Background())
+}
+
+func TestAcknowledgeEventDeliveries_FullBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := mocks.NewMockWebSocketConnection(ctrl)
+	r := provideRepo(ctrl)
+
+	c := provideClient(r, conn)
+
+	ids := make([]string, ackBatchSize)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("ed-%d", i)
+	}
+
+	done := make(chan struct{})
+	evd := r.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+	evd.EXPECT().UpdateStatusOfEventDeliveries(gomock.Any(), gomock.Any(), ids, datastore.SuccessEventStatus).
+		Return(nil)
+	evd.EXPECT().AcknowledgeEventDeliveries(gomock.Any(), gomock.Any(), ids, gomock.Any()).
+		DoAndReturn(func(context.Context, string, []string, time.Time) (int64, error) {
+			close(done)
+			return int64(len(ids)), nil
+		})
+
+	c.queueAcks(context.Background(), ids...)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("full batch of acknowledgements wasn't written")
+	}
+	require.Empty(t, c.pendingAcks)
+}
+
+func TestAcknowledgeEventDeliveries_MaxMessageFitsReadLimit(t *testing.T) {
+	ids := make([]string, maxAcksPerMessage)
+	for i := range ids {
+		ids[i] = uuid.NewString()
+	}
+
+	message, err := json.Marshal(AckEventDelivery{UIDs: ids})
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(message), maxMessageSize)
+}
+
 func TestResendEventDeliveries(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
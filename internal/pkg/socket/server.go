@@ -38,8 +38,11 @@ type Hub struct {
 	ticker *time.Ticker
 }
 
+// AckEventDelivery acknowledges one delivery by uid, or a batch of them by
+// uids, e.g. the deliveries a device received while it was reconnecting
 type AckEventDelivery struct {
-	UID string `json:"uid"`
+	UID  string   `json:"uid"`
+	UIDs []string `json:"uids,omitempty"`
 }
 
 type CLIEvent struct {
@@ -0,0 +1,138 @@
+// Package sse streams event delivery status changes to connected
+// clients using Server-Sent Events.
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	channelPrefix     = "convoy:eventdelivery.updated:"
+	eventName         = "eventdelivery.updated"
+	heartbeatInterval = 15 * time.Second
+)
+
+// DeliveryStatusUpdate is the payload pushed to clients whenever an
+// event delivery's status changes.
+type DeliveryStatusUpdate struct {
+	UID         string                        `json:"uid"`
+	ProjectID   string                        `json:"project_id"`
+	EventID     string                        `json:"event_id"`
+	EndpointID  string                        `json:"endpoint_id"`
+	Status      datastore.EventDeliveryStatus `json:"status"`
+	Description string                        `json:"description,omitempty"`
+	NumTrials   uint64                        `json:"num_trials"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+}
+
+func NewDeliveryStatusUpdate(delivery *datastore.EventDelivery) *DeliveryStatusUpdate {
+	update := &DeliveryStatusUpdate{
+		UID:         delivery.UID,
+		ProjectID:   delivery.ProjectID,
+		EventID:     delivery.EventID,
+		EndpointID:  delivery.EndpointID,
+		Status:      delivery.Status,
+		Description: delivery.Description,
+		UpdatedAt:   delivery.UpdatedAt,
+	}
+
+	if delivery.Metadata != nil {
+		update.NumTrials = delivery.Metadata.NumTrials
+	}
+
+	return update
+}
+
+// Channel returns the redis pub/sub channel a project's updates are published on.
+func Channel(projectID string) string {
+	return channelPrefix + projectID
+}
+
+// Publish broadcasts an update to every client subscribed to the project.
+func Publish(ctx context.Context, client redis.UniversalClient, update *DeliveryStatusUpdate) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	return client.Publish(ctx, Channel(update.ProjectID), data).Err()
+}
+
+// Stream buffers updates for a single client. It only ever holds the
+// latest undelivered update, so a slow client skips intermediate
+// updates instead of blocking the publisher.
+type Stream struct {
+	updates chan []byte
+}
+
+func NewStream() *Stream {
+	return &Stream{updates: make(chan []byte, 1)}
+}
+
+// Push queues data for the client, replacing any update it has not read yet.
+func (s *Stream) Push(data []byte) {
+	for {
+		select {
+		case s.updates <- data:
+			return
+		default:
+		}
+
+		// the buffer is full, drop the stale update
+		select {
+		case <-s.updates:
+		default:
+		}
+	}
+}
+
+// Serve writes queued updates to w as server-sent events until the
+// request's context is done. The server's write timeout would cut the
+// stream off, so it's lifted for the stream's request.
+func (s *Stream) Serve(w http.ResponseWriter, r *http.Request) error {
+	rc := http.NewResponseController(w)
+
+	err := rc.SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+		case data := <-s.updates:
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventName, data); err != nil {
+				return err
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return err
+		}
+	}
+}
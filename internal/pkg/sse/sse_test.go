@@ -0,0 +1,129 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var event, data string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if data != "" {
+				return event, data
+			}
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStream_PushesStatusChangesToConnectedClient(t *testing.T) {
+	stream := NewStream()
+	connected := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(connected)
+		_ = stream.Serve(w, r)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	<-connected
+
+	reader := bufio.NewReader(resp.Body)
+	for _, status := range []datastore.EventDeliveryStatus{datastore.ProcessingEventStatus, datastore.SuccessEventStatus} {
+		data, err := json.Marshal(NewDeliveryStatusUpdate(&datastore.EventDelivery{
+			UID:       "delivery-1",
+			ProjectID: "project-1",
+			Status:    status,
+			Metadata:  &datastore.Metadata{NumTrials: 1},
+		}))
+		require.NoError(t, err)
+
+		stream.Push(data)
+
+		event, payload := readEvent(t, reader)
+		require.Equal(t, eventName, event)
+
+		var update DeliveryStatusUpdate
+		require.NoError(t, json.Unmarshal([]byte(payload), &update))
+		require.Equal(t, "delivery-1", update.UID)
+		require.Equal(t, "project-1", update.ProjectID)
+		require.Equal(t, status, update.Status)
+		require.Equal(t, uint64(1), update.NumTrials)
+	}
+}
+
+func TestStream_OutlivesServerWriteTimeout(t *testing.T) {
+	stream := NewStream()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = stream.Serve(w, r)
+	}))
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// well past the server's write timeout, the stream is still open
+	time.Sleep(300 * time.Millisecond)
+	stream.Push([]byte(`{"status":"Success"}`))
+
+	event, payload := readEvent(t, bufio.NewReader(resp.Body))
+	require.Equal(t, eventName, event)
+	require.Equal(t, `{"status":"Success"}`, payload)
+}
+
+func TestStream_SlowClientReceivesLatest(t *testing.T) {
+	stream := NewStream()
+
+	stream.Push([]byte(`{"status":"Scheduled"}`))
+	stream.Push([]byte(`{"status":"Processing"}`))
+	stream.Push([]byte(`{"status":"Success"}`))
+
+	require.Len(t, stream.updates, 1)
+	require.Equal(t, `{"status":"Success"}`, string(<-stream.updates))
+}
+
+func TestChannel(t *testing.T) {
+	require.Equal(t, "convoy:eventdelivery.updated:project-1", Channel("project-1"))
+}
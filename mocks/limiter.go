@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,21 @@ func (m *MockRateLimiter) EXPECT() *MockRateLimiterMockRecorder {
 	return m.recorder
 }
 
+// Acquire mocks base method.
+func (m *MockRateLimiter) Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acquire", ctx, key, limit, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockRateLimiterMockRecorder) Acquire(ctx, key, limit, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockRateLimiter)(nil).Acquire), ctx, key, limit, ttl)
+}
+
 // Allow mocks base method.
 func (m *MockRateLimiter) Allow(ctx context.Context, key string, rate int) error {
 	m.ctrl.T.Helper()
@@ -54,6 +70,20 @@ func (mr *MockRateLimiterMockRecorder) Allow(ctx, key, rate any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimiter)(nil).Allow), ctx, key, rate)
 }
 
+// AllowOncePer mocks base method.
+func (m *MockRateLimiter) AllowOncePer(ctx context.Context, key string, interval time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowOncePer", ctx, key, interval)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AllowOncePer indicates an expected call of AllowOncePer.
+func (mr *MockRateLimiterMockRecorder) AllowOncePer(ctx, key, interval any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowOncePer", reflect.TypeOf((*MockRateLimiter)(nil).AllowOncePer), ctx, key, interval)
+}
+
 // AllowWithDuration mocks base method.
 func (m *MockRateLimiter) AllowWithDuration(ctx context.Context, key string, rate, duration int) error {
 	m.ctrl.T.Helper()
@@ -67,3 +97,17 @@ func (mr *MockRateLimiterMockRecorder) AllowWithDuration(ctx, key, rate, duratio
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowWithDuration", reflect.TypeOf((*MockRateLimiter)(nil).AllowWithDuration), ctx, key, rate, duration)
 }
+
+// Release mocks base method.
+func (m *MockRateLimiter) Release(ctx context.Context, key, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, key, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockRateLimiterMockRecorder) Release(ctx, key, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockRateLimiter)(nil).Release), ctx, key, token)
+}
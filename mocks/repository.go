@@ -187,6 +187,96 @@ func (m *MockEventDeliveryRepository) EXPECT() *MockEventDeliveryRepositoryMockR
 	return m.recorder
 }
 
+// AcknowledgeEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) AcknowledgeEventDeliveries(ctx context.Context, projectID string, ids []string, at time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcknowledgeEventDeliveries", ctx, projectID, ids, at)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcknowledgeEventDeliveries indicates an expected call of AcknowledgeEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) AcknowledgeEventDeliveries(ctx, projectID, ids, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgeEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).AcknowledgeEventDeliveries), ctx, projectID, ids, at)
+}
+
+// BackfillLatencySeconds mocks base method.
+func (m *MockEventDeliveryRepository) BackfillLatencySeconds(ctx context.Context, batchSize int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillLatencySeconds", ctx, batchSize)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillLatencySeconds indicates an expected call of BackfillLatencySeconds.
+func (mr *MockEventDeliveryRepositoryMockRecorder) BackfillLatencySeconds(ctx, batchSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillLatencySeconds", reflect.TypeOf((*MockEventDeliveryRepository)(nil).BackfillLatencySeconds), ctx, batchSize)
+}
+
+// ClaimScheduledDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) ClaimScheduledDeliveries(ctx context.Context, projectID string, limit int) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimScheduledDeliveries", ctx, projectID, limit)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimScheduledDeliveries indicates an expected call of ClaimScheduledDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) ClaimScheduledDeliveries(ctx, projectID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimScheduledDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ClaimScheduledDeliveries), ctx, projectID, limit)
+}
+
+// CompactDeliveryAttempts mocks base method.
+func (m *MockEventDeliveryRepository) CompactDeliveryAttempts(ctx context.Context, batchSize int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompactDeliveryAttempts", ctx, batchSize)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompactDeliveryAttempts indicates an expected call of CompactDeliveryAttempts.
+func (mr *MockEventDeliveryRepositoryMockRecorder) CompactDeliveryAttempts(ctx, batchSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompactDeliveryAttempts", reflect.TypeOf((*MockEventDeliveryRepository)(nil).CompactDeliveryAttempts), ctx, batchSize)
+}
+
+// ComputeEndpointHealth mocks base method.
+func (m *MockEventDeliveryRepository) ComputeEndpointHealth(ctx context.Context, projectID, endpointID string, window time.Duration) (*datastore.EndpointHealth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ComputeEndpointHealth", ctx, projectID, endpointID, window)
+	ret0, _ := ret[0].(*datastore.EndpointHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ComputeEndpointHealth indicates an expected call of ComputeEndpointHealth.
+func (mr *MockEventDeliveryRepositoryMockRecorder) ComputeEndpointHealth(ctx, projectID, endpointID, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ComputeEndpointHealth", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ComputeEndpointHealth), ctx, projectID, endpointID, window)
+}
+
+// CountDeliveriesByEventType mocks base method.
+func (m *MockEventDeliveryRepository) CountDeliveriesByEventType(ctx context.Context, projectID, eventType string, status datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeliveriesByEventType", ctx, projectID, eventType, status, params)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeliveriesByEventType indicates an expected call of CountDeliveriesByEventType.
+func (mr *MockEventDeliveryRepositoryMockRecorder) CountDeliveriesByEventType(ctx, projectID, eventType, status, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeliveriesByEventType", reflect.TypeOf((*MockEventDeliveryRepository)(nil).CountDeliveriesByEventType), ctx, projectID, eventType, status, params)
+}
+
 // CountDeliveriesByStatus mocks base method.
 func (m *MockEventDeliveryRepository) CountDeliveriesByStatus(ctx context.Context, projectID string, status datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -203,26 +293,27 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) CountDeliveriesByStatus(ctx,
 }
 
 // CountEventDeliveries mocks base method.
-func (m *MockEventDeliveryRepository) CountEventDeliveries(ctx context.Context, projectID string, endpointIDs []string, eventID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
+func (m *MockEventDeliveryRepository) CountEventDeliveries(ctx context.Context, projectID string, endpointIDs, sourceIDs []string, eventID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CountEventDeliveries", ctx, projectID, endpointIDs, eventID, status, params)
+	ret := m.ctrl.Call(m, "CountEventDeliveries", ctx, projectID, endpointIDs, sourceIDs, eventID, status, params)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CountEventDeliveries indicates an expected call of CountEventDeliveries.
-func (mr *MockEventDeliveryRepositoryMockRecorder) CountEventDeliveries(ctx, projectID, endpointIDs, eventID, status, params any) *gomock.Call {
+func (mr *MockEventDeliveryRepositoryMockRecorder) CountEventDeliveries(ctx, projectID, endpointIDs, sourceIDs, eventID, status, params any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).CountEventDeliveries), ctx, projectID, endpointIDs, eventID, status, params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).CountEventDeliveries), ctx, projectID, endpointIDs, sourceIDs, eventID, status, params)
 }
 
 // CreateEventDeliveries mocks base method.
-func (m *MockEventDeliveryRepository) CreateEventDeliveries(arg0 context.Context, arg1 []*datastore.EventDelivery) error {
+func (m *MockEventDeliveryRepository) CreateEventDeliveries(arg0 context.Context, arg1 []*datastore.EventDelivery) ([]*datastore.EventDelivery, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateEventDeliveries", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]*datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateEventDeliveries indicates an expected call of CreateEventDeliveries.
@@ -259,19 +350,104 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) DeleteProjectEventDeliveries(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProjectEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).DeleteProjectEventDeliveries), ctx, projectID, filter, hardDelete)
 }
 
+// DropEventDeliveryPartitionsBefore mocks base method.
+func (m *MockEventDeliveryRepository) DropEventDeliveryPartitionsBefore(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropEventDeliveryPartitionsBefore", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DropEventDeliveryPartitionsBefore indicates an expected call of DropEventDeliveryPartitionsBefore.
+func (mr *MockEventDeliveryRepositoryMockRecorder) DropEventDeliveryPartitionsBefore(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropEventDeliveryPartitionsBefore", reflect.TypeOf((*MockEventDeliveryRepository)(nil).DropEventDeliveryPartitionsBefore), ctx, before)
+}
+
+// EnsureEventDeliveryPartitions mocks base method.
+func (m *MockEventDeliveryRepository) EnsureEventDeliveryPartitions(ctx context.Context, projectID string, from, to time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEventDeliveryPartitions", ctx, projectID, from, to)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureEventDeliveryPartitions indicates an expected call of EnsureEventDeliveryPartitions.
+func (mr *MockEventDeliveryRepositoryMockRecorder) EnsureEventDeliveryPartitions(ctx, projectID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEventDeliveryPartitions", reflect.TypeOf((*MockEventDeliveryRepository)(nil).EnsureEventDeliveryPartitions), ctx, projectID, from, to)
+}
+
+// EventDeliveryPartitionGranularity mocks base method.
+func (m *MockEventDeliveryRepository) EventDeliveryPartitionGranularity(ctx context.Context) (datastore.PartitionGranularity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EventDeliveryPartitionGranularity", ctx)
+	ret0, _ := ret[0].(datastore.PartitionGranularity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EventDeliveryPartitionGranularity indicates an expected call of EventDeliveryPartitionGranularity.
+func (mr *MockEventDeliveryRepositoryMockRecorder) EventDeliveryPartitionGranularity(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EventDeliveryPartitionGranularity", reflect.TypeOf((*MockEventDeliveryRepository)(nil).EventDeliveryPartitionGranularity), ctx)
+}
+
 // ExportRecords mocks base method.
-func (m *MockEventDeliveryRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
+func (m *MockEventDeliveryRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExportRecords", ctx, projectID, createdAt, w)
+	varargs := []any{ctx, projectID, createdAt, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportRecords", varargs...)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExportRecords indicates an expected call of ExportRecords.
-func (mr *MockEventDeliveryRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any) *gomock.Call {
+func (mr *MockEventDeliveryRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ExportRecords), ctx, projectID, createdAt, w)
+	varargs := append([]any{ctx, projectID, createdAt, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ExportRecords), varargs...)
+}
+
+// ExportRecordsFormat mocks base method.
+func (m *MockEventDeliveryRepository) ExportRecordsFormat(ctx context.Context, projectID string, createdAt time.Time, format datastore.ExportFormat, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, projectID, createdAt, format, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportRecordsFormat", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportRecordsFormat indicates an expected call of ExportRecordsFormat.
+func (mr *MockEventDeliveryRepositoryMockRecorder) ExportRecordsFormat(ctx, projectID, createdAt, format, w any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, projectID, createdAt, format, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecordsFormat", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ExportRecordsFormat), varargs...)
+}
+
+// FindDeadLetteredEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) FindDeadLetteredEventDeliveries(ctx context.Context, projectID string, failedBefore time.Time) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeadLetteredEventDeliveries", ctx, projectID, failedBefore)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeadLetteredEventDeliveries indicates an expected call of FindDeadLetteredEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindDeadLetteredEventDeliveries(ctx, projectID, failedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeadLetteredEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindDeadLetteredEventDeliveries), ctx, projectID, failedBefore)
 }
 
 // FindDiscardedEventDeliveries mocks base method.
@@ -289,6 +465,37 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) FindDiscardedEventDeliveries(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDiscardedEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindDiscardedEventDeliveries), ctx, projectID, deviceId, params)
 }
 
+// FindEventDeliveriesAfterID mocks base method.
+func (m *MockEventDeliveryRepository) FindEventDeliveriesAfterID(ctx context.Context, projectID string, filter *datastore.EventDeliveryFilter, afterID string, limit int) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventDeliveriesAfterID", ctx, projectID, filter, afterID, limit)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEventDeliveriesAfterID indicates an expected call of FindEventDeliveriesAfterID.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveriesAfterID(ctx, projectID, filter, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveriesAfterID", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveriesAfterID), ctx, projectID, filter, afterID, limit)
+}
+
+// FindEventDeliveriesByEndpointAndStatus mocks base method.
+func (m *MockEventDeliveryRepository) FindEventDeliveriesByEndpointAndStatus(ctx context.Context, projectID, endpointID string, status datastore.EventDeliveryStatus, params datastore.SearchParams, cursor string, limit int) ([]datastore.EventDelivery, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventDeliveriesByEndpointAndStatus", ctx, projectID, endpointID, status, params, cursor, limit)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindEventDeliveriesByEndpointAndStatus indicates an expected call of FindEventDeliveriesByEndpointAndStatus.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveriesByEndpointAndStatus(ctx, projectID, endpointID, status, params, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveriesByEndpointAndStatus", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveriesByEndpointAndStatus), ctx, projectID, endpointID, status, params, cursor, limit)
+}
+
 // FindEventDeliveriesByEventID mocks base method.
 func (m *MockEventDeliveryRepository) FindEventDeliveriesByEventID(ctx context.Context, projectID, id string) ([]datastore.EventDelivery, error) {
 	m.ctrl.T.Helper()
@@ -304,6 +511,21 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveriesByEventID(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveriesByEventID", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveriesByEventID), ctx, projectID, id)
 }
 
+// FindEventDeliveriesByEventIDs mocks base method.
+func (m *MockEventDeliveryRepository) FindEventDeliveriesByEventIDs(ctx context.Context, projectID string, eventIDs []string) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventDeliveriesByEventIDs", ctx, projectID, eventIDs)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEventDeliveriesByEventIDs indicates an expected call of FindEventDeliveriesByEventIDs.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveriesByEventIDs(ctx, projectID, eventIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveriesByEventIDs", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveriesByEventIDs), ctx, projectID, eventIDs)
+}
+
 // FindEventDeliveriesByIDs mocks base method.
 func (m *MockEventDeliveryRepository) FindEventDeliveriesByIDs(ctx context.Context, projectID string, ids []string) ([]datastore.EventDelivery, error) {
 	m.ctrl.T.Helper()
@@ -319,6 +541,36 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveriesByIDs(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveriesByIDs", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveriesByIDs), ctx, projectID, ids)
 }
 
+// FindEventDeliveriesByIdempotencyKey mocks base method.
+func (m *MockEventDeliveryRepository) FindEventDeliveriesByIdempotencyKey(ctx context.Context, projectID, idempotencyKey string) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventDeliveriesByIdempotencyKey", ctx, projectID, idempotencyKey)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEventDeliveriesByIdempotencyKey indicates an expected call of FindEventDeliveriesByIdempotencyKey.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveriesByIdempotencyKey(ctx, projectID, idempotencyKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveriesByIdempotencyKey", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveriesByIdempotencyKey), ctx, projectID, idempotencyKey)
+}
+
+// FindEventDeliveriesStuckInStatus mocks base method.
+func (m *MockEventDeliveryRepository) FindEventDeliveriesStuckInStatus(ctx context.Context, projectID string, status datastore.EventDeliveryStatus, olderThan time.Duration, limit int) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventDeliveriesStuckInStatus", ctx, projectID, status, olderThan, limit)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEventDeliveriesStuckInStatus indicates an expected call of FindEventDeliveriesStuckInStatus.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveriesStuckInStatus(ctx, projectID, status, olderThan, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveriesStuckInStatus", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveriesStuckInStatus), ctx, projectID, status, olderThan, limit)
+}
+
 // FindEventDeliveryByID mocks base method.
 func (m *MockEventDeliveryRepository) FindEventDeliveryByID(ctx context.Context, projectID, id string) (*datastore.EventDelivery, error) {
 	m.ctrl.T.Helper()
@@ -349,6 +601,21 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) FindEventDeliveryByIDSlim(ctx
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventDeliveryByIDSlim", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindEventDeliveryByIDSlim), ctx, projectID, id)
 }
 
+// FindOrphanedEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) FindOrphanedEventDeliveries(ctx context.Context, projectID, afterID string, limit int) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrphanedEventDeliveries", ctx, projectID, afterID, limit)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrphanedEventDeliveries indicates an expected call of FindOrphanedEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindOrphanedEventDeliveries(ctx, projectID, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrphanedEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindOrphanedEventDeliveries), ctx, projectID, afterID, limit)
+}
+
 // FindStuckEventDeliveriesByStatus mocks base method.
 func (m *MockEventDeliveryRepository) FindStuckEventDeliveriesByStatus(ctx context.Context, status datastore.EventDeliveryStatus) ([]datastore.EventDelivery, error) {
 	m.ctrl.T.Helper()
@@ -364,25 +631,160 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) FindStuckEventDeliveriesBySta
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStuckEventDeliveriesByStatus", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindStuckEventDeliveriesByStatus), ctx, status)
 }
 
+// FindUnacknowledgedEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) FindUnacknowledgedEventDeliveries(ctx context.Context, projectID string, olderThan time.Duration, limit int) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUnacknowledgedEventDeliveries", ctx, projectID, olderThan, limit)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUnacknowledgedEventDeliveries indicates an expected call of FindUnacknowledgedEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindUnacknowledgedEventDeliveries(ctx, projectID, olderThan, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUnacknowledgedEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindUnacknowledgedEventDeliveries), ctx, projectID, olderThan, limit)
+}
+
+// GetDeliverySLACompliance mocks base method.
+func (m *MockEventDeliveryRepository) GetDeliverySLACompliance(ctx context.Context, projectID string, budget time.Duration, params datastore.SearchParams) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliverySLACompliance", ctx, projectID, budget, params)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliverySLACompliance indicates an expected call of GetDeliverySLACompliance.
+func (mr *MockEventDeliveryRepositoryMockRecorder) GetDeliverySLACompliance(ctx, projectID, budget, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliverySLACompliance", reflect.TypeOf((*MockEventDeliveryRepository)(nil).GetDeliverySLACompliance), ctx, projectID, budget, params)
+}
+
+// GetEventDeliveryLatencyHistogram mocks base method.
+func (m *MockEventDeliveryRepository) GetEventDeliveryLatencyHistogram(ctx context.Context, projectID, endpointID string, params datastore.SearchParams, buckets []float64) ([]datastore.LatencyBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventDeliveryLatencyHistogram", ctx, projectID, endpointID, params, buckets)
+	ret0, _ := ret[0].([]datastore.LatencyBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventDeliveryLatencyHistogram indicates an expected call of GetEventDeliveryLatencyHistogram.
+func (mr *MockEventDeliveryRepositoryMockRecorder) GetEventDeliveryLatencyHistogram(ctx, projectID, endpointID, params, buckets any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventDeliveryLatencyHistogram", reflect.TypeOf((*MockEventDeliveryRepository)(nil).GetEventDeliveryLatencyHistogram), ctx, projectID, endpointID, params, buckets)
+}
+
+// LatestDeliveryByEndpoint mocks base method.
+func (m *MockEventDeliveryRepository) LatestDeliveryByEndpoint(ctx context.Context, projectID string, endpointIDs []string) (map[string]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestDeliveryByEndpoint", ctx, projectID, endpointIDs)
+	ret0, _ := ret[0].(map[string]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestDeliveryByEndpoint indicates an expected call of LatestDeliveryByEndpoint.
+func (mr *MockEventDeliveryRepositoryMockRecorder) LatestDeliveryByEndpoint(ctx, projectID, endpointIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestDeliveryByEndpoint", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LatestDeliveryByEndpoint), ctx, projectID, endpointIDs)
+}
+
+// LoadAttemptCountDistribution mocks base method.
+func (m *MockEventDeliveryRepository) LoadAttemptCountDistribution(ctx context.Context, projectID string, params datastore.SearchParams) ([]datastore.AttemptCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAttemptCountDistribution", ctx, projectID, params)
+	ret0, _ := ret[0].([]datastore.AttemptCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAttemptCountDistribution indicates an expected call of LoadAttemptCountDistribution.
+func (mr *MockEventDeliveryRepositoryMockRecorder) LoadAttemptCountDistribution(ctx, projectID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAttemptCountDistribution", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadAttemptCountDistribution), ctx, projectID, params)
+}
+
+// LoadDeliveryCountsByHour mocks base method.
+func (m *MockEventDeliveryRepository) LoadDeliveryCountsByHour(ctx context.Context, projectID string, params datastore.SearchParams) ([]datastore.HourlyDeliveryCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadDeliveryCountsByHour", ctx, projectID, params)
+	ret0, _ := ret[0].([]datastore.HourlyDeliveryCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadDeliveryCountsByHour indicates an expected call of LoadDeliveryCountsByHour.
+func (mr *MockEventDeliveryRepositoryMockRecorder) LoadDeliveryCountsByHour(ctx, projectID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadDeliveryCountsByHour", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadDeliveryCountsByHour), ctx, projectID, params)
+}
+
+// LoadEndpointDeliveryStats mocks base method.
+func (m *MockEventDeliveryRepository) LoadEndpointDeliveryStats(ctx context.Context, projectID string, params datastore.SearchParams) ([]datastore.EndpointDeliveryStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadEndpointDeliveryStats", ctx, projectID, params)
+	ret0, _ := ret[0].([]datastore.EndpointDeliveryStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadEndpointDeliveryStats indicates an expected call of LoadEndpointDeliveryStats.
+func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEndpointDeliveryStats(ctx, projectID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEndpointDeliveryStats", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEndpointDeliveryStats), ctx, projectID, params)
+}
+
 // LoadEventDeliveriesIntervals mocks base method.
-func (m *MockEventDeliveryRepository) LoadEventDeliveriesIntervals(ctx context.Context, projectID string, params datastore.SearchParams, period datastore.Period, ids []string) ([]datastore.EventInterval, error) {
+func (m *MockEventDeliveryRepository) LoadEventDeliveriesIntervals(ctx context.Context, projectID string, params datastore.SearchParams, period datastore.Period, ids []string, withLatency bool, weekStart time.Weekday) ([]datastore.EventInterval, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadEventDeliveriesIntervals", ctx, projectID, params, period, ids)
+	ret := m.ctrl.Call(m, "LoadEventDeliveriesIntervals", ctx, projectID, params, period, ids, withLatency, weekStart)
 	ret0, _ := ret[0].([]datastore.EventInterval)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LoadEventDeliveriesIntervals indicates an expected call of LoadEventDeliveriesIntervals.
-func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEventDeliveriesIntervals(ctx, projectID, params, period, ids any) *gomock.Call {
+func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEventDeliveriesIntervals(ctx, projectID, params, period, ids, withLatency, weekStart any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventDeliveriesIntervals", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEventDeliveriesIntervals), ctx, projectID, params, period, ids, withLatency, weekStart)
+}
+
+// HasPendingEventDeliveriesBefore mocks base method.
+func (m *MockEventDeliveryRepository) HasPendingEventDeliveriesBefore(ctx context.Context, delivery *datastore.EventDelivery) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPendingEventDeliveriesBefore", ctx, delivery)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPendingEventDeliveriesBefore indicates an expected call of HasPendingEventDeliveriesBefore.
+func (mr *MockEventDeliveryRepositoryMockRecorder) HasPendingEventDeliveriesBefore(ctx, delivery any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventDeliveriesIntervals", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEventDeliveriesIntervals), ctx, projectID, params, period, ids)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPendingEventDeliveriesBefore", reflect.TypeOf((*MockEventDeliveryRepository)(nil).HasPendingEventDeliveriesBefore), ctx, delivery)
+}
+
+// LoadEventDeliveriesIntervalsByDeliveryMode mocks base method.
+func (m *MockEventDeliveryRepository) LoadEventDeliveriesIntervalsByDeliveryMode(ctx context.Context, projectID string, params datastore.SearchParams, period datastore.Period) (map[datastore.DeliveryMode][]datastore.EventInterval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadEventDeliveriesIntervalsByDeliveryMode", ctx, projectID, params, period)
+	ret0, _ := ret[0].(map[datastore.DeliveryMode][]datastore.EventInterval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadEventDeliveriesIntervalsByDeliveryMode indicates an expected call of LoadEventDeliveriesIntervalsByDeliveryMode.
+func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEventDeliveriesIntervalsByDeliveryMode(ctx, projectID, params, period any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventDeliveriesIntervalsByDeliveryMode", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEventDeliveriesIntervalsByDeliveryMode), ctx, projectID, params, period)
 }
 
 // LoadEventDeliveriesPaged mocks base method.
-func (m *MockEventDeliveryRepository) LoadEventDeliveriesPaged(ctx context.Context, projectID string, endpointIDs []string, eventID, subscriptionID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams, pageable datastore.Pageable, idempotencyKey, eventType string) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+func (m *MockEventDeliveryRepository) LoadEventDeliveriesPaged(ctx context.Context, projectID string, endpointIDs []string, eventID, subscriptionID string, status []datastore.EventDeliveryStatus, params datastore.SearchParams, pageable datastore.Pageable, idempotencyKey, eventType string, responseStatusCodes []int, readPreference datastore.ReadPreference) ([]datastore.EventDelivery, datastore.PaginationData, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadEventDeliveriesPaged", ctx, projectID, endpointIDs, eventID, subscriptionID, status, params, pageable, idempotencyKey, eventType)
+	ret := m.ctrl.Call(m, "LoadEventDeliveriesPaged", ctx, projectID, endpointIDs, eventID, subscriptionID, status, params, pageable, idempotencyKey, eventType, responseStatusCodes, readPreference)
 	ret0, _ := ret[0].([]datastore.EventDelivery)
 	ret1, _ := ret[1].(datastore.PaginationData)
 	ret2, _ := ret[2].(error)
@@ -390,23 +792,127 @@ func (m *MockEventDeliveryRepository) LoadEventDeliveriesPaged(ctx context.Conte
 }
 
 // LoadEventDeliveriesPaged indicates an expected call of LoadEventDeliveriesPaged.
-func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEventDeliveriesPaged(ctx, projectID, endpointIDs, eventID, subscriptionID, status, params, pageable, idempotencyKey, eventType any) *gomock.Call {
+func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEventDeliveriesPaged(ctx, projectID, endpointIDs, eventID, subscriptionID, status, params, pageable, idempotencyKey, eventType, responseStatusCodes, readPreference any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventDeliveriesPaged", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEventDeliveriesPaged), ctx, projectID, endpointIDs, eventID, subscriptionID, status, params, pageable, idempotencyKey, eventType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventDeliveriesPaged", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEventDeliveriesPaged), ctx, projectID, endpointIDs, eventID, subscriptionID, status, params, pageable, idempotencyKey, eventType, responseStatusCodes, readPreference)
 }
 
 // PartitionEventDeliveriesTable mocks base method.
-func (m *MockEventDeliveryRepository) PartitionEventDeliveriesTable(ctx context.Context) error {
+func (m *MockEventDeliveryRepository) PartitionEventDeliveriesTable(ctx context.Context, granularity datastore.PartitionGranularity) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PartitionEventDeliveriesTable", ctx)
+	ret := m.ctrl.Call(m, "PartitionEventDeliveriesTable", ctx, granularity)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PartitionEventDeliveriesTable indicates an expected call of PartitionEventDeliveriesTable.
-func (mr *MockEventDeliveryRepositoryMockRecorder) PartitionEventDeliveriesTable(ctx any) *gomock.Call {
+func (mr *MockEventDeliveryRepositoryMockRecorder) PartitionEventDeliveriesTable(ctx, granularity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PartitionEventDeliveriesTable", reflect.TypeOf((*MockEventDeliveryRepository)(nil).PartitionEventDeliveriesTable), ctx, granularity)
+}
+
+// PurgeEventDeliveryDeduplicationKeys mocks base method.
+func (m *MockEventDeliveryRepository) PurgeEventDeliveryDeduplicationKeys(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeEventDeliveryDeduplicationKeys", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeEventDeliveryDeduplicationKeys indicates an expected call of PurgeEventDeliveryDeduplicationKeys.
+func (mr *MockEventDeliveryRepositoryMockRecorder) PurgeEventDeliveryDeduplicationKeys(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeEventDeliveryDeduplicationKeys", reflect.TypeOf((*MockEventDeliveryRepository)(nil).PurgeEventDeliveryDeduplicationKeys), ctx, before)
+}
+
+// PurgeSoftDeletedEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) PurgeSoftDeletedEventDeliveries(ctx context.Context, olderThan time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSoftDeletedEventDeliveries", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeSoftDeletedEventDeliveries indicates an expected call of PurgeSoftDeletedEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) PurgeSoftDeletedEventDeliveries(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSoftDeletedEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).PurgeSoftDeletedEventDeliveries), ctx, olderThan)
+}
+
+// ReleaseEventDeliveryDeduplicationKeys mocks base method.
+func (m *MockEventDeliveryRepository) ReleaseEventDeliveryDeduplicationKeys(ctx context.Context, projectID, eventID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseEventDeliveryDeduplicationKeys", ctx, projectID, eventID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseEventDeliveryDeduplicationKeys indicates an expected call of ReleaseEventDeliveryDeduplicationKeys.
+func (mr *MockEventDeliveryRepositoryMockRecorder) ReleaseEventDeliveryDeduplicationKeys(ctx, projectID, eventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseEventDeliveryDeduplicationKeys", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ReleaseEventDeliveryDeduplicationKeys), ctx, projectID, eventID)
+}
+
+// RequeueEventDeliveriesByFilter mocks base method.
+func (m *MockEventDeliveryRepository) RequeueEventDeliveriesByFilter(ctx context.Context, projectID string, filter *datastore.EventDeliveryFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueEventDeliveriesByFilter", ctx, projectID, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueEventDeliveriesByFilter indicates an expected call of RequeueEventDeliveriesByFilter.
+func (mr *MockEventDeliveryRepositoryMockRecorder) RequeueEventDeliveriesByFilter(ctx, projectID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueEventDeliveriesByFilter", reflect.TypeOf((*MockEventDeliveryRepository)(nil).RequeueEventDeliveriesByFilter), ctx, projectID, filter)
+}
+
+// RequeueRecentEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) RequeueRecentEventDeliveries(ctx context.Context, projectID string, ids []string, maxAge time.Duration) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueRecentEventDeliveries", ctx, projectID, ids, maxAge)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueRecentEventDeliveries indicates an expected call of RequeueRecentEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) RequeueRecentEventDeliveries(ctx, projectID, ids, maxAge any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueRecentEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).RequeueRecentEventDeliveries), ctx, projectID, ids, maxAge)
+}
+
+// StripProjectEventDeliveryPayloads mocks base method.
+func (m *MockEventDeliveryRepository) StripProjectEventDeliveryPayloads(ctx context.Context, projectID string, filter *datastore.EventDeliveryFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StripProjectEventDeliveryPayloads", ctx, projectID, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StripProjectEventDeliveryPayloads indicates an expected call of StripProjectEventDeliveryPayloads.
+func (mr *MockEventDeliveryRepositoryMockRecorder) StripProjectEventDeliveryPayloads(ctx, projectID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StripProjectEventDeliveryPayloads", reflect.TypeOf((*MockEventDeliveryRepository)(nil).StripProjectEventDeliveryPayloads), ctx, projectID, filter)
+}
+
+// SwapEntitySnapshot mocks base method.
+func (m *MockEventDeliveryRepository) SwapEntitySnapshot(ctx context.Context, projectID, subscriptionID, entityKey, eventID string, eventCreatedAt time.Time, payload []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwapEntitySnapshot", ctx, projectID, subscriptionID, entityKey, eventID, eventCreatedAt, payload)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SwapEntitySnapshot indicates an expected call of SwapEntitySnapshot.
+func (mr *MockEventDeliveryRepositoryMockRecorder) SwapEntitySnapshot(ctx, projectID, subscriptionID, entityKey, eventID, eventCreatedAt, payload any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PartitionEventDeliveriesTable", reflect.TypeOf((*MockEventDeliveryRepository)(nil).PartitionEventDeliveriesTable), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwapEntitySnapshot", reflect.TypeOf((*MockEventDeliveryRepository)(nil).SwapEntitySnapshot), ctx, projectID, subscriptionID, entityKey, eventID, eventCreatedAt, payload)
 }
 
 // UnPartitionEventDeliveriesTable mocks base method.
@@ -576,18 +1082,23 @@ func (mr *MockEventRepositoryMockRecorder) DeleteProjectTokenizedEvents(ctx, pro
 }
 
 // ExportRecords mocks base method.
-func (m *MockEventRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
+func (m *MockEventRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExportRecords", ctx, projectID, createdAt, w)
+	varargs := []any{ctx, projectID, createdAt, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportRecords", varargs...)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExportRecords indicates an expected call of ExportRecords.
-func (mr *MockEventRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any) *gomock.Call {
+func (mr *MockEventRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockEventRepository)(nil).ExportRecords), ctx, projectID, createdAt, w)
+	varargs := append([]any{ctx, projectID, createdAt, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockEventRepository)(nil).ExportRecords), varargs...)
 }
 
 // FindEventByID mocks base method.
@@ -832,6 +1343,21 @@ func (mr *MockProjectRepositoryMockRecorder) FetchProjectByID(arg0, arg1 any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchProjectByID", reflect.TypeOf((*MockProjectRepository)(nil).FetchProjectByID), arg0, arg1)
 }
 
+// FetchProjectByInspectionToken mocks base method.
+func (m *MockProjectRepository) FetchProjectByInspectionToken(ctx context.Context, token string) (*datastore.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchProjectByInspectionToken", ctx, token)
+	ret0, _ := ret[0].(*datastore.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchProjectByInspectionToken indicates an expected call of FetchProjectByInspectionToken.
+func (mr *MockProjectRepositoryMockRecorder) FetchProjectByInspectionToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchProjectByInspectionToken", reflect.TypeOf((*MockProjectRepository)(nil).FetchProjectByInspectionToken), ctx, token)
+}
+
 // FillProjectsStatistics mocks base method.
 func (m *MockProjectRepository) FillProjectsStatistics(ctx context.Context, project *datastore.Project) error {
 	m.ctrl.T.Helper()
@@ -1340,6 +1866,20 @@ func (mr *MockEndpointRepositoryMockRecorder) CreateEndpoint(ctx, endpoint, proj
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEndpoint", reflect.TypeOf((*MockEndpointRepository)(nil).CreateEndpoint), ctx, endpoint, projectID)
 }
 
+// CreateEndpointRecovery mocks base method.
+func (m *MockEndpointRepository) CreateEndpointRecovery(ctx context.Context, projectID, endpointID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEndpointRecovery", ctx, projectID, endpointID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEndpointRecovery indicates an expected call of CreateEndpointRecovery.
+func (mr *MockEndpointRepositoryMockRecorder) CreateEndpointRecovery(ctx, projectID, endpointID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEndpointRecovery", reflect.TypeOf((*MockEndpointRepository)(nil).CreateEndpointRecovery), ctx, projectID, endpointID)
+}
+
 // DeleteEndpoint mocks base method.
 func (m *MockEndpointRepository) DeleteEndpoint(ctx context.Context, endpoint *datastore.Endpoint, projectID string) error {
 	m.ctrl.T.Helper()
@@ -1443,6 +1983,21 @@ func (mr *MockEndpointRepositoryMockRecorder) FindEndpointsByOwnerID(ctx, projec
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEndpointsByOwnerID", reflect.TypeOf((*MockEndpointRepository)(nil).FindEndpointsByOwnerID), ctx, projectID, ownerID)
 }
 
+// LoadEndpointRecoveries mocks base method.
+func (m *MockEndpointRepository) LoadEndpointRecoveries(ctx context.Context, projectID string) ([]datastore.EndpointRecovery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadEndpointRecoveries", ctx, projectID)
+	ret0, _ := ret[0].([]datastore.EndpointRecovery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadEndpointRecoveries indicates an expected call of LoadEndpointRecoveries.
+func (mr *MockEndpointRepositoryMockRecorder) LoadEndpointRecoveries(ctx, projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEndpointRecoveries", reflect.TypeOf((*MockEndpointRepository)(nil).LoadEndpointRecoveries), ctx, projectID)
+}
+
 // LoadEndpointsPaged mocks base method.
 func (m *MockEndpointRepository) LoadEndpointsPaged(ctx context.Context, projectID string, filter *datastore.Filter, pageable datastore.Pageable) ([]datastore.Endpoint, datastore.PaginationData, error) {
 	m.ctrl.T.Helper()
@@ -1501,6 +2056,20 @@ func (mr *MockEndpointRepositoryMockRecorder) UpdateSecrets(ctx, endpointID, pro
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecrets", reflect.TypeOf((*MockEndpointRepository)(nil).UpdateSecrets), ctx, endpointID, projectID, secrets)
 }
 
+// UpsertEndpointRecovery mocks base method.
+func (m *MockEndpointRepository) UpsertEndpointRecovery(ctx context.Context, recovery *datastore.EndpointRecovery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEndpointRecovery", ctx, recovery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertEndpointRecovery indicates an expected call of UpsertEndpointRecovery.
+func (mr *MockEndpointRepositoryMockRecorder) UpsertEndpointRecovery(ctx, recovery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEndpointRecovery", reflect.TypeOf((*MockEndpointRepository)(nil).UpsertEndpointRecovery), ctx, recovery)
+}
+
 // MockSubscriptionRepository is a mock of SubscriptionRepository interface.
 type MockSubscriptionRepository struct {
 	ctrl     *gomock.Controller
@@ -1748,6 +2317,20 @@ func (mr *MockSubscriptionRepositoryMockRecorder) UpdateSubscription(ctx, projec
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).UpdateSubscription), ctx, projectID, subscription)
 }
 
+// UpdateSubscriptionPaused mocks base method.
+func (m *MockSubscriptionRepository) UpdateSubscriptionPaused(ctx context.Context, projectID, subscriptionID string, paused bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscriptionPaused", ctx, projectID, subscriptionID, paused)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscriptionPaused indicates an expected call of UpdateSubscriptionPaused.
+func (mr *MockSubscriptionRepositoryMockRecorder) UpdateSubscriptionPaused(ctx, projectID, subscriptionID, paused any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriptionPaused", reflect.TypeOf((*MockSubscriptionRepository)(nil).UpdateSubscriptionPaused), ctx, projectID, subscriptionID, paused)
+}
+
 // MockFilterRepository is a mock of FilterRepository interface.
 type MockFilterRepository struct {
 	ctrl     *gomock.Controller
@@ -1999,6 +2582,21 @@ func (mr *MockSourceRepositoryMockRecorder) FindSourceByName(ctx, projectId, nam
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSourceByName", reflect.TypeOf((*MockSourceRepository)(nil).FindSourceByName), ctx, projectId, name)
 }
 
+// IncrementSourceIngestFailures mocks base method.
+func (m *MockSourceRepository) IncrementSourceIngestFailures(ctx context.Context, projectID, sourceID string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementSourceIngestFailures", ctx, projectID, sourceID)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementSourceIngestFailures indicates an expected call of IncrementSourceIngestFailures.
+func (mr *MockSourceRepositoryMockRecorder) IncrementSourceIngestFailures(ctx, projectID, sourceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementSourceIngestFailures", reflect.TypeOf((*MockSourceRepository)(nil).IncrementSourceIngestFailures), ctx, projectID, sourceID)
+}
+
 // LoadPubSubSourcesByProjectIDs mocks base method.
 func (m *MockSourceRepository) LoadPubSubSourcesByProjectIDs(ctx context.Context, projectIds []string, pageable datastore.Pageable) ([]datastore.Source, datastore.PaginationData, error) {
 	m.ctrl.T.Helper()
@@ -2031,6 +2629,20 @@ func (mr *MockSourceRepositoryMockRecorder) LoadSourcesPaged(ctx, projectId, fil
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSourcesPaged", reflect.TypeOf((*MockSourceRepository)(nil).LoadSourcesPaged), ctx, projectId, filter, pageable)
 }
 
+// ResetSourceIngestFailures mocks base method.
+func (m *MockSourceRepository) ResetSourceIngestFailures(ctx context.Context, projectID, sourceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetSourceIngestFailures", ctx, projectID, sourceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetSourceIngestFailures indicates an expected call of ResetSourceIngestFailures.
+func (mr *MockSourceRepositoryMockRecorder) ResetSourceIngestFailures(ctx, projectID, sourceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSourceIngestFailures", reflect.TypeOf((*MockSourceRepository)(nil).ResetSourceIngestFailures), ctx, projectID, sourceID)
+}
+
 // UpdateSource mocks base method.
 func (m *MockSourceRepository) UpdateSource(ctx context.Context, projectId string, source *datastore.Source) error {
 	m.ctrl.T.Helper()
@@ -2045,6 +2657,35 @@ func (mr *MockSourceRepositoryMockRecorder) UpdateSource(ctx, projectId, source
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSource", reflect.TypeOf((*MockSourceRepository)(nil).UpdateSource), ctx, projectId, source)
 }
 
+// PauseSource mocks base method.
+func (m *MockSourceRepository) PauseSource(ctx context.Context, projectID, sourceID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseSource", ctx, projectID, sourceID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseSource indicates an expected call of PauseSource.
+func (mr *MockSourceRepositoryMockRecorder) PauseSource(ctx, projectID, sourceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSource", reflect.TypeOf((*MockSourceRepository)(nil).PauseSource), ctx, projectID, sourceID)
+}
+
+// UpdateSourcePaused mocks base method.
+func (m *MockSourceRepository) UpdateSourcePaused(ctx context.Context, projectID, sourceID string, paused bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSourcePaused", ctx, projectID, sourceID, paused)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSourcePaused indicates an expected call of UpdateSourcePaused.
+func (mr *MockSourceRepositoryMockRecorder) UpdateSourcePaused(ctx, projectID, sourceID, paused any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSourcePaused", reflect.TypeOf((*MockSourceRepository)(nil).UpdateSourcePaused), ctx, projectID, sourceID, paused)
+}
+
 // MockDeviceRepository is a mock of DeviceRepository interface.
 type MockDeviceRepository struct {
 	ctrl     *gomock.Controller
@@ -2800,18 +3441,23 @@ func (m *MockExportRepository) EXPECT() *MockExportRepositoryMockRecorder {
 }
 
 // ExportRecords mocks base method.
-func (m *MockExportRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
+func (m *MockExportRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExportRecords", ctx, projectID, createdAt, w)
+	varargs := []any{ctx, projectID, createdAt, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportRecords", varargs...)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExportRecords indicates an expected call of ExportRecords.
-func (mr *MockExportRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any) *gomock.Call {
+func (mr *MockExportRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockExportRepository)(nil).ExportRecords), ctx, projectID, createdAt, w)
+	varargs := append([]any{ctx, projectID, createdAt, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockExportRepository)(nil).ExportRecords), varargs...)
 }
 
 // MockDeliveryAttemptsRepository is a mock of DeliveryAttemptsRepository interface.
@@ -2852,6 +3498,20 @@ func (mr *MockDeliveryAttemptsRepositoryMockRecorder) CreateDeliveryAttempt(arg0
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveryAttempt", reflect.TypeOf((*MockDeliveryAttemptsRepository)(nil).CreateDeliveryAttempt), arg0, arg1)
 }
 
+// CreateDeliveryAttempts mocks base method.
+func (m *MockDeliveryAttemptsRepository) CreateDeliveryAttempts(arg0 context.Context, arg1 []*datastore.DeliveryAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveryAttempts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveryAttempts indicates an expected call of CreateDeliveryAttempts.
+func (mr *MockDeliveryAttemptsRepositoryMockRecorder) CreateDeliveryAttempts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveryAttempts", reflect.TypeOf((*MockDeliveryAttemptsRepository)(nil).CreateDeliveryAttempts), arg0, arg1)
+}
+
 // DeleteProjectDeliveriesAttempts mocks base method.
 func (m *MockDeliveryAttemptsRepository) DeleteProjectDeliveriesAttempts(ctx context.Context, projectID string, filter *datastore.DeliveryAttemptsFilter, hardDelete bool) error {
 	m.ctrl.T.Helper()
@@ -2867,18 +3527,23 @@ func (mr *MockDeliveryAttemptsRepositoryMockRecorder) DeleteProjectDeliveriesAtt
 }
 
 // ExportRecords mocks base method.
-func (m *MockDeliveryAttemptsRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer) (int64, error) {
+func (m *MockDeliveryAttemptsRepository) ExportRecords(ctx context.Context, projectID string, createdAt time.Time, w io.Writer, opts ...datastore.ExportOption) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExportRecords", ctx, projectID, createdAt, w)
+	varargs := []any{ctx, projectID, createdAt, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportRecords", varargs...)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExportRecords indicates an expected call of ExportRecords.
-func (mr *MockDeliveryAttemptsRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any) *gomock.Call {
+func (mr *MockDeliveryAttemptsRepositoryMockRecorder) ExportRecords(ctx, projectID, createdAt, w any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockDeliveryAttemptsRepository)(nil).ExportRecords), ctx, projectID, createdAt, w)
+	varargs := append([]any{ctx, projectID, createdAt, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecords", reflect.TypeOf((*MockDeliveryAttemptsRepository)(nil).ExportRecords), varargs...)
 }
 
 // FindDeliveryAttemptById mocks base method.
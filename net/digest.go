@@ -0,0 +1,337 @@
+package net
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotDigestChallenge       = errors.New("response is not a digest authentication challenge")
+	ErrUnsupportedDigestAlgo    = errors.New("unsupported digest authentication algorithm")
+	ErrUnsupportedDigestQop     = errors.New("unsupported digest authentication qop")
+	ErrMissingDigestNonce       = errors.New("digest authentication challenge has no nonce")
+	ErrMissingDigestCredentials = errors.New("digest authentication credentials are required")
+)
+
+// DigestCredentials are used to answer an endpoint's HTTP Digest
+// authentication (RFC 7616) challenge
+type DigestCredentials struct {
+	Username string
+	Password string
+}
+
+type digestCredentialsKey struct{}
+
+// ContextWithDigestCredentials makes the dispatcher answer digest
+// authentication challenges for requests sent with ctx using creds
+func ContextWithDigestCredentials(ctx context.Context, creds *DigestCredentials) context.Context {
+	return context.WithValue(ctx, digestCredentialsKey{}, creds)
+}
+
+func digestCredentialsFromContext(ctx context.Context) *DigestCredentials {
+	creds, _ := ctx.Value(digestCredentialsKey{}).(*DigestCredentials)
+	return creds
+}
+
+// digestChallenge is a parsed WWW-Authenticate Digest challenge. nc counts
+// how many requests were authorized with its nonce.
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	stale     bool
+	nc        uint32
+}
+
+// parseDigestChallenge picks the Digest challenge out of a response's
+// WWW-Authenticate headers, servers may offer other schemes alongside it
+func parseDigestChallenge(headers []string) (*digestChallenge, error) {
+	for _, header := range headers {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(header), " ")
+		if !strings.EqualFold(scheme, "digest") {
+			continue
+		}
+
+		c := &digestChallenge{algorithm: "MD5"}
+		for key, value := range parseAuthParams(params) {
+			switch key {
+			case "realm":
+				c.realm = value
+			case "nonce":
+				c.nonce = value
+			case "opaque":
+				c.opaque = value
+			case "algorithm":
+				c.algorithm = strings.ToUpper(value)
+			case "stale":
+				c.stale = strings.EqualFold(value, "true")
+			case "qop":
+				for _, qop := range strings.Split(value, ",") {
+					if strings.TrimSpace(qop) == "auth" {
+						c.qop = "auth"
+					}
+				}
+
+				if c.qop == "" {
+					return nil, ErrUnsupportedDigestQop
+				}
+			}
+		}
+
+		if c.nonce == "" {
+			return nil, ErrMissingDigestNonce
+		}
+
+		if _, err := c.hasher(); err != nil {
+			return nil, err
+		}
+
+		return c, nil
+	}
+
+	return nil, ErrNotDigestChallenge
+}
+
+// parseAuthParams splits a challenge's comma separated key=value pairs,
+// quoted values may contain commas and escaped quotes
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+		key, rest, found := strings.Cut(s, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(rest, `"`) {
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end == -1 {
+				end = len(rest)
+			}
+			value.WriteString(strings.TrimSpace(rest[:end]))
+			s = rest[end:]
+		}
+
+		params[key] = value.String()
+	}
+
+	return params
+}
+
+func (c *digestChallenge) hasher() (func() hash.Hash, error) {
+	switch strings.TrimSuffix(c.algorithm, "-SESS") {
+	case "MD5":
+		return md5.New, nil
+	case "SHA-256":
+		return sha256.New, nil
+	default:
+		return nil, ErrUnsupportedDigestAlgo
+	}
+}
+
+// cacheable reports whether the challenge's nonce may be reused for later
+// requests, which needs the server to track the nonce count sent with qop
+func (c *digestChallenge) cacheable() bool {
+	return c.qop == "auth"
+}
+
+// authorization computes the Authorization header answering the challenge
+// for a request, each call uses the nonce once more
+func (c *digestChallenge) authorization(creds *DigestCredentials, method, uri string) (string, error) {
+	newHash, err := c.hasher()
+	if err != nil {
+		return "", err
+	}
+
+	h := func(parts ...string) string {
+		sum := newHash()
+		sum.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(sum.Sum(nil))
+	}
+
+	cnonce, err := newCnonce()
+	if err != nil {
+		return "", err
+	}
+
+	ha1 := h(creds.Username, c.realm, creds.Password)
+	if strings.HasSuffix(c.algorithm, "-SESS") {
+		ha1 = h(ha1, c.nonce, cnonce)
+	}
+	ha2 := h(method, uri)
+
+	params := []string{
+		fmt.Sprintf(`username="%s"`, quoteAuthParam(creds.Username)),
+		fmt.Sprintf(`realm="%s"`, quoteAuthParam(c.realm)),
+		fmt.Sprintf(`nonce="%s"`, quoteAuthParam(c.nonce)),
+		fmt.Sprintf(`uri="%s"`, quoteAuthParam(uri)),
+		fmt.Sprintf("algorithm=%s", c.algorithm),
+	}
+
+	if c.qop != "" {
+		c.nc++
+		nc := fmt.Sprintf("%08x", c.nc)
+		params = append(params,
+			fmt.Sprintf(`response="%s"`, h(ha1, c.nonce, nc, cnonce, c.qop, ha2)),
+			fmt.Sprintf("qop=%s", c.qop),
+			fmt.Sprintf("nc=%s", nc),
+			fmt.Sprintf(`cnonce="%s"`, cnonce),
+		)
+	} else {
+		params = append(params, fmt.Sprintf(`response="%s"`, h(ha1, c.nonce, ha2)))
+	}
+
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf(`opaque="%s"`, quoteAuthParam(c.opaque)))
+	}
+
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+func quoteAuthParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// digestNonceCache keeps the last challenge each endpoint host sent, so
+// later requests can be authorized up front instead of being challenged first
+type digestNonceCache struct {
+	mu         sync.Mutex
+	challenges map[string]*digestChallenge
+}
+
+func newDigestNonceCache() *digestNonceCache {
+	return &digestNonceCache{challenges: map[string]*digestChallenge{}}
+}
+
+func digestCacheKey(req *http.Request, creds *DigestCredentials) string {
+	return req.URL.Scheme + "://" + req.URL.Host + "|" + creds.Username
+}
+
+// authorize sets the request's Authorization header from the cached
+// challenge, it reports false when there isn't one
+func (n *digestNonceCache) authorize(req *http.Request, creds *DigestCredentials) (bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	c, ok := n.challenges[digestCacheKey(req, creds)]
+	if !ok {
+		return false, nil
+	}
+
+	auth, err := c.authorization(creds, req.Method, req.URL.RequestURI())
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Authorization", auth)
+	return true, nil
+}
+
+// answer sets the request's Authorization header from a fresh challenge and
+// caches the challenge when its nonce may be reused
+func (n *digestNonceCache) answer(req *http.Request, creds *DigestCredentials, c *digestChallenge) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	auth, err := c.authorization(creds, req.Method, req.URL.RequestURI())
+	if err != nil {
+		return err
+	}
+
+	key := digestCacheKey(req, creds)
+	if c.cacheable() {
+		n.challenges[key] = c
+	} else {
+		delete(n.challenges, key)
+	}
+
+	req.Header.Set("Authorization", auth)
+	return nil
+}
+
+func (n *digestNonceCache) forget(req *http.Request, creds *DigestCredentials) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.challenges, digestCacheKey(req, creds))
+}
+
+// doDigest sends req answering the endpoint's digest challenge with creds.
+// A cached nonce is tried first, when the endpoint rejects it or there is
+// none the request is sent again with the challenge the endpoint responded
+// with. A 401 to the answered challenge is returned like any other response.
+func (d *Dispatcher) doDigest(ctx context.Context, req *http.Request, res *Response, maxResponseSize int64, creds *DigestCredentials) error {
+	if creds.Username == "" {
+		return ErrMissingDigestCredentials
+	}
+
+	if _, err := d.digestNonces.authorize(req, creds); err != nil {
+		return err
+	}
+
+	err := d.do(ctx, req, res, maxResponseSize)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return err
+	}
+
+	challenge, err := parseDigestChallenge(res.ResponseHeader.Values("WWW-Authenticate"))
+	if err != nil {
+		d.digestNonces.forget(req, creds)
+		if errors.Is(err, ErrNotDigestChallenge) {
+			return nil
+		}
+		return err
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		req.Body = body
+	}
+
+	if err = d.digestNonces.answer(req, creds, challenge); err != nil {
+		return err
+	}
+
+	err = d.do(ctx, req, res, maxResponseSize)
+	if err != nil || res.StatusCode == http.StatusUnauthorized {
+		d.digestNonces.forget(req, creds)
+	}
+
+	return err
+}
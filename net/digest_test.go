@@ -0,0 +1,174 @@
+package net
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/internal/pkg/fflag"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+const (
+	testDigestRealm = "webhooks@example.com"
+	testDigestNonce = "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+)
+
+func md5Hex(parts ...string) string {
+	sum := md5.Sum([]byte(strings.Join(parts, ":")))
+	return hex.EncodeToString(sum[:])
+}
+
+// newDigestServer accepts requests digest authenticated as username with
+// password, it counts every request and the challenges it sends
+func newDigestServer(t *testing.T, username, password string, requests, challenges *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		challenge := func() {
+			challenges.Add(1)
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", qop="auth,auth-int", nonce="%s", opaque="5ccc069c403ebaf9f0171e9517f40e41"`, testDigestRealm, testDigestNonce))
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		scheme, raw, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if scheme != "Digest" {
+			challenge()
+			return
+		}
+
+		params := parseAuthParams(raw)
+		ha1 := md5Hex(username, testDigestRealm, password)
+		ha2 := md5Hex(r.Method, params["uri"])
+		want := md5Hex(ha1, testDigestNonce, params["nc"], params["cnonce"], params["qop"], ha2)
+
+		if params["username"] != username || params["nonce"] != testDigestNonce || params["response"] != want {
+			challenge()
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"name":"convoy"}`, string(body))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func newDigestDispatcher(t *testing.T) *Dispatcher {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	dispatcher, err := NewDispatcher(mocks.NewMockLicenser(ctrl), fflag.NewFFlag([]string{}), LoggerOption(log.NewLogger(os.Stdout)))
+	require.NoError(t, err)
+
+	return dispatcher
+}
+
+func TestDispatcherDigestAuth(t *testing.T) {
+	var requests, challenges atomic.Int32
+	server := newDigestServer(t, "convoy", "s3cret", &requests, &challenges)
+	defer server.Close()
+
+	dispatcher := newDigestDispatcher(t)
+	ctx := ContextWithDigestCredentials(context.Background(), &DigestCredentials{Username: "convoy", Password: "s3cret"})
+
+	resp, err := dispatcher.SendWebhook(ctx, http.MethodPost, server.URL+"/hooks?id=1", json.RawMessage(`{"name":"convoy"}`), "X-Signature", "test-hmac", 1024, nil, "", 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(2), requests.Load())
+	require.Equal(t, int32(1), challenges.Load())
+
+	// the nonce is cached, so the next delivery is authorized up front
+	resp, err = dispatcher.SendWebhook(ctx, http.MethodPost, server.URL+"/hooks?id=1", json.RawMessage(`{"name":"convoy"}`), "X-Signature", "test-hmac", 1024, nil, "", 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(3), requests.Load())
+	require.Equal(t, int32(1), challenges.Load())
+	require.Contains(t, resp.RequestHeader.Get("Authorization"), "nc=00000002")
+}
+
+func TestDispatcherDigestAuthWrongCredentials(t *testing.T) {
+	var requests, challenges atomic.Int32
+	server := newDigestServer(t, "convoy", "s3cret", &requests, &challenges)
+	defer server.Close()
+
+	dispatcher := newDigestDispatcher(t)
+	ctx := ContextWithDigestCredentials(context.Background(), &DigestCredentials{Username: "convoy", Password: "wrong"})
+
+	resp, err := dispatcher.SendUnsignedWebhook(ctx, http.MethodPost, server.URL, json.RawMessage(`{"name":"convoy"}`), 1024, nil, "", 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// the challenge is answered once, then the rejection is returned
+	require.Equal(t, int32(2), requests.Load())
+	require.Equal(t, int32(2), challenges.Load())
+	require.Empty(t, dispatcher.digestNonces.challenges)
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    *digestChallenge
+		wantErr error
+	}{
+		{
+			name:    "should_pick_digest_among_other_schemes",
+			headers: []string{`Basic realm="api"`, `Digest realm="api, v2", nonce="abc", algorithm=SHA-256, qop="auth", stale=TRUE`},
+			want:    &digestChallenge{realm: "api, v2", nonce: "abc", algorithm: "SHA-256", qop: "auth", stale: true},
+		},
+		{
+			name:    "should_default_to_md5_without_qop",
+			headers: []string{`Digest realm="api", nonce="abc"`},
+			want:    &digestChallenge{realm: "api", nonce: "abc", algorithm: "MD5"},
+		},
+		{
+			name:    "should_reject_non_digest_challenges",
+			headers: []string{`Basic realm="api"`},
+			wantErr: ErrNotDigestChallenge,
+		},
+		{
+			name:    "should_reject_unsupported_algorithms",
+			headers: []string{`Digest realm="api", nonce="abc", algorithm=SHA-512-256`},
+			wantErr: ErrUnsupportedDigestAlgo,
+		},
+		{
+			name:    "should_reject_challenges_only_offering_auth_int",
+			headers: []string{`Digest realm="api", nonce="abc", qop="auth-int"`},
+			wantErr: ErrUnsupportedDigestQop,
+		},
+		{
+			name:    "should_reject_challenges_without_a_nonce",
+			headers: []string{`Digest realm="api"`},
+			wantErr: ErrMissingDigestNonce,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDigestChallenge(tt.headers)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -17,10 +17,12 @@ import (
 	"net/http/httptrace"
 	"net/netip"
 	"net/url"
+	"syscall"
 	"time"
 
 	"github.com/stealthrocket/netjail"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/net/http2"
 
 	"github.com/frain-dev/convoy/internal/pkg/license"
 
@@ -38,6 +40,10 @@ var (
 	ErrInvalidIPPrefix     = errors.New("invalid IP prefix")
 	ErrTracerIsRequired    = errors.New("tracer cannot be nil")
 	ErrNon2xxResponse      = errors.New("endpoint returned a non-2xx response")
+
+	ErrInvalidMaxIdleConnsPerHost = errors.New("max idle connections per host cannot be negative")
+	ErrInvalidIdleConnTimeout     = errors.New("idle connection timeout cannot be negative")
+	ErrInvalidTransientRetries    = errors.New("transient retries cannot be negative")
 )
 
 type DispatcherOption func(d *Dispatcher) error
@@ -103,16 +109,27 @@ type Dispatcher struct {
 	rules         *netjail.Rules
 	tracer        tracer.Backend
 	detailedTrace DetailedTraceConfig
+
+	// forceHTTP2 negotiates HTTP/2 with endpoints that support it
+	forceHTTP2 bool
+
+	// transientRetries is how many times a request that failed on a
+	// transient network error is resent before the attempt fails
+	transientRetries int
+
+	// digestNonces caches endpoints' digest authentication challenges
+	digestNonces *digestNonceCache
 }
 
 func NewDispatcher(l license.Licenser, ff *fflag.FFlag, options ...DispatcherOption) (*Dispatcher, error) {
 	d := &Dispatcher{
-		ff:     ff,
-		l:      l,
-		logger: log.NewLogger(os.Stdout),
-		tracer: tracer.NoOpBackend{},
-		client: &http.Client{},
-		rules:  &netjail.Rules{},
+		ff:           ff,
+		l:            l,
+		logger:       log.NewLogger(os.Stdout),
+		tracer:       tracer.NoOpBackend{},
+		client:       &http.Client{},
+		rules:        &netjail.Rules{},
+		digestNonces: newDigestNonceCache(),
 		transport: &http.Transport{
 			MaxIdleConns:          1000,
 			IdleConnTimeout:       30 * time.Second,
@@ -135,19 +152,37 @@ func NewDispatcher(l license.Licenser, ff *fflag.FFlag, options ...DispatcherOpt
 
 	netJailTransport := &netjail.Transport{
 		New: func() *http.Transport {
-			return d.transport.Clone()
+			t := d.transport.Clone()
+			if err := d.configureHTTP2(t); err != nil {
+				d.logger.WithError(err).Error("failed to configure http2, falling back to http/1.1")
+			}
+			return t
 		},
 	}
 
 	if ff.CanAccessFeature(fflag.IpRules) && l.IpRules() {
 		d.client.Transport = NewNetJailTransport(netJailTransport)
 	} else {
+		if err := d.configureHTTP2(d.transport); err != nil {
+			return nil, err
+		}
 		d.client.Transport = NewVanillaTransport(d.transport)
 	}
 
 	return d, nil
 }
 
+// configureHTTP2 lets t negotiate HTTP/2 over TLS when forceHTTP2 is set.
+// Endpoints that only speak HTTP/1.1 don't offer h2 and are sent HTTP/1.1
+// requests like before, plain http endpoints are always sent HTTP/1.1.
+func (d *Dispatcher) configureHTTP2(t *http.Transport) error {
+	if !d.forceHTTP2 {
+		return nil
+	}
+
+	return http2.ConfigureTransport(t)
+}
+
 // ProxyOption defines an HTTP proxy which the client will use. It fails-open the string isn't a valid HTTP URL
 func ProxyOption(httpProxy string) DispatcherOption {
 	return func(d *Dispatcher) error {
@@ -266,6 +301,55 @@ func TracerOption(tracer tracer.Backend) DispatcherOption {
 	}
 }
 
+// ForceHTTP2Option negotiates HTTP/2 with endpoints that support it, which
+// endpoints behind gRPC-web gateways need. Endpoints that don't support it are
+// still sent HTTP/1.1 requests.
+func ForceHTTP2Option(enabled bool) DispatcherOption {
+	return func(d *Dispatcher) error {
+		d.forceHTTP2 = enabled
+		return nil
+	}
+}
+
+// ConnectionPoolOption tunes how many idle connections are kept open to each
+// endpoint host and for how long, zero values keep the defaults.
+func ConnectionPoolOption(maxIdleConnsPerHost int, idleConnTimeout time.Duration) DispatcherOption {
+	return func(d *Dispatcher) error {
+		if maxIdleConnsPerHost < 0 {
+			return ErrInvalidMaxIdleConnsPerHost
+		}
+
+		if idleConnTimeout < 0 {
+			return ErrInvalidIdleConnTimeout
+		}
+
+		if maxIdleConnsPerHost > 0 {
+			d.transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+			d.transport.MaxIdleConns = max(d.transport.MaxIdleConns, maxIdleConnsPerHost)
+		}
+
+		if idleConnTimeout > 0 {
+			d.transport.IdleConnTimeout = idleConnTimeout
+		}
+
+		return nil
+	}
+}
+
+// TransientRetryOption resends a request up to retries times when it fails
+// on a transient network error like a connection reset, before the attempt
+// is reported as failed. There are no retries when it is 0.
+func TransientRetryOption(retries int) DispatcherOption {
+	return func(d *Dispatcher) error {
+		if retries < 0 {
+			return ErrInvalidTransientRetries
+		}
+
+		d.transientRetries = retries
+		return nil
+	}
+}
+
 func DetailedTraceOption(enabled bool) DispatcherOption {
 	return func(d *Dispatcher) error {
 		d.detailedTrace.Enabled = enabled
@@ -290,32 +374,47 @@ func (d *Dispatcher) validateProxy(proxyURL string) (*url.URL, bool, error) {
 	return nil, false, nil
 }
 
-func (d *Dispatcher) SendWebhook(ctx context.Context, endpoint string, jsonData json.RawMessage, signatureHeader string, hmac string, maxResponseSize int64, headers httpheader.HTTPHeader, idempotencyKey string, timeout time.Duration) (*Response, error) {
+func (d *Dispatcher) SendWebhook(ctx context.Context, method, endpoint string, jsonData json.RawMessage, signatureHeader string, hmac string, maxResponseSize int64, headers httpheader.HTTPHeader, idempotencyKey string, timeout time.Duration) (*Response, error) {
+	if util.IsStringEmpty(signatureHeader) || util.IsStringEmpty(hmac) {
+		err := errors.New("signature header and hmac are required")
+		d.logger.WithError(err).Error("Dispatcher invalid arguments")
+		return &Response{Error: err.Error()}, err
+	}
+
+	return d.sendWebhook(ctx, method, endpoint, jsonData, signatureHeader, hmac, maxResponseSize, headers, idempotencyKey, timeout)
+}
+
+// SendUnsignedWebhook sends the webhook without a signature header, for
+// event types the project has opted out of signing.
+func (d *Dispatcher) SendUnsignedWebhook(ctx context.Context, method, endpoint string, jsonData json.RawMessage, maxResponseSize int64, headers httpheader.HTTPHeader, idempotencyKey string, timeout time.Duration) (*Response, error) {
+	return d.sendWebhook(ctx, method, endpoint, jsonData, "", "", maxResponseSize, headers, idempotencyKey, timeout)
+}
+
+func (d *Dispatcher) sendWebhook(ctx context.Context, method, endpoint string, jsonData json.RawMessage, signatureHeader string, hmac string, maxResponseSize int64, headers httpheader.HTTPHeader, idempotencyKey string, timeout time.Duration) (*Response, error) {
 	d.logger.Debugf("rules: %+v", d.rules)
 
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	r := &Response{}
-	if util.IsStringEmpty(signatureHeader) || util.IsStringEmpty(hmac) {
-		err := errors.New("signature header and hmac are required")
-		d.logger.WithError(err).Error("Dispatcher invalid arguments")
-		r.Error = err.Error()
-		return r, err
-	}
-
 	if d.ff.CanAccessFeature(fflag.IpRules) && d.l.IpRules() {
 		ctx = netjail.ContextWithRules(ctx, d.rules)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if util.IsStringEmpty(method) {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		d.logger.WithError(err).Error("error occurred while creating request")
 		return r, err
 	}
 
-	req.Header.Set(signatureHeader, hmac)
-	req.Header.Add("Content-Type", "application/json")
+	if !util.IsStringEmpty(hmac) {
+		req.Header.Set(signatureHeader, hmac)
+	}
+	req.Header.Add("Content-Type", contentTypeFromContext(ctx))
 	req.Header.Set("Accept-Encoding", "gzip")
 	req.Header.Add("User-Agent", defaultUserAgent())
 	if len(idempotencyKey) > 0 {
@@ -331,7 +430,11 @@ func (d *Dispatcher) SendWebhook(ctx context.Context, endpoint string, jsonData
 	r.URL = req.URL
 	r.Method = req.Method
 
-	err = d.do(ctx, req, r, maxResponseSize)
+	if creds := digestCredentialsFromContext(ctx); creds != nil {
+		err = d.doDigest(ctx, req, r, maxResponseSize, creds)
+	} else {
+		err = d.do(ctx, req, r, maxResponseSize)
+	}
 	if err != nil {
 		return r, err
 	}
@@ -357,6 +460,40 @@ func updateDispatchHeaders(r *Response, res *http.Response) {
 	r.ResponseHeader = res.Header
 }
 
+type contentTypeKey struct{}
+
+// ContextWithContentType makes the dispatcher send requests sent with ctx
+// with contentType instead of application/json
+func ContextWithContentType(ctx context.Context, contentType string) context.Context {
+	return context.WithValue(ctx, contentTypeKey{}, contentType)
+}
+
+func contentTypeFromContext(ctx context.Context) string {
+	contentType, _ := ctx.Value(contentTypeKey{}).(string)
+	if util.IsStringEmpty(contentType) {
+		return "application/json"
+	}
+
+	return contentType
+}
+
+type noTransientRetriesKey struct{}
+
+// ContextWithoutTransientRetries makes the dispatcher send requests sent with
+// ctx once, even when they fail on a transient network error. The endpoint
+// may have received a request whose connection was reset afterwards.
+func ContextWithoutTransientRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTransientRetriesKey{}, true)
+}
+
+func transientRetriesFromContext(ctx context.Context, retries int) int {
+	if skip, _ := ctx.Value(noTransientRetriesKey{}).(bool); skip {
+		return 0
+	}
+
+	return retries
+}
+
 func defaultUserAgent() string {
 	return "Convoy/" + convoy.GetVersion()
 }
@@ -423,7 +560,21 @@ func (d *Dispatcher) do(ctx context.Context, req *http.Request, res *Response, m
 		req = req.WithContext(ctx)
 	}
 
+	retries := transientRetriesFromContext(ctx, d.transientRetries)
 	response, err := d.client.Do(req)
+	for retry := 0; err != nil && retry < retries && isTransientNetworkError(err) && ctx.Err() == nil; retry++ {
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				break
+			}
+			req.Body = body
+		}
+
+		d.logger.WithError(err).Warn("transient error sending request to API endpoint, retrying")
+		response, err = d.client.Do(req)
+	}
+
 	if err != nil {
 		d.logger.WithError(err).Error("error sending request to API endpoint")
 		res.Error = err.Error()
@@ -464,9 +615,35 @@ func (d *Dispatcher) do(ctx context.Context, req *http.Request, res *Response, m
 	return nil
 }
 
+// isTransientNetworkError reports whether err is a network blip, like the
+// connection being reset or closed under the request, that's worth retrying
+func isTransientNetworkError(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Do sends req with the client webhooks are sent with, so it goes through
+// the same proxy, connection pool and ip rules.
+func (d *Dispatcher) Do(req *http.Request) (*http.Response, error) {
+	if d.ff.CanAccessFeature(fflag.IpRules) && d.l.IpRules() {
+		req = req.WithContext(netjail.ContextWithRules(req.Context(), d.rules))
+	}
+
+	return d.client.Do(req)
+}
+
 // Ping sends a GET request to the specified endpoint and verifies it returns a 2xx response.
 // It returns an error if the endpoint is unreachable or returns a non-2xx status code.
 func (d *Dispatcher) Ping(ctx context.Context, endpoint string, timeout time.Duration) error {
+	_, err := d.PingStatus(ctx, endpoint, timeout)
+	return err
+}
+
+// PingStatus is Ping that also returns the status code the endpoint responded
+// with, it is 0 when the endpoint couldn't be reached.
+func (d *Dispatcher) PingStatus(ctx context.Context, endpoint string, timeout time.Duration) (int, error) {
 	d.logger.Debugf("rules: %+v", d.rules)
 
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -479,7 +656,7 @@ func (d *Dispatcher) Ping(ctx context.Context, endpoint string, timeout time.Dur
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		d.logger.WithError(err).Error("error creating ping request")
-		return err
+		return 0, err
 	}
 
 	trace := &httptrace.ClientTrace{
@@ -496,15 +673,15 @@ func (d *Dispatcher) Ping(ctx context.Context, endpoint string, timeout time.Dur
 	response, err := d.client.Do(req)
 	if err != nil {
 		d.logger.WithError(err).Error("error sending ping request")
-		return err
+		return 0, err
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		err = fmt.Errorf("%w: got status code %d", ErrNon2xxResponse, response.StatusCode)
 		d.logger.WithError(err).Error("ping request failed")
-		return err
+		return response.StatusCode, err
 	}
 
-	return nil
+	return response.StatusCode, nil
 }
@@ -5,9 +5,12 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"io"
+	stdnet "net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -295,7 +298,7 @@ func TestDispatcher_SendRequest(t *testing.T) {
 				defer deferFn()
 			}
 
-			got, err := d.SendWebhook(context.Background(), tt.args.endpoint, tt.args.jsonData, tt.args.project.Config.Signature.Header.String(), tt.args.hmac, config.MaxResponseSize, tt.args.headers, "", time.Minute)
+			got, err := d.SendWebhook(context.Background(), http.MethodPost, tt.args.endpoint, tt.args.jsonData, tt.args.project.Config.Signature.Header.String(), tt.args.hmac, config.MaxResponseSize, tt.args.headers, "", time.Minute)
 			if tt.wantErr {
 				require.NotNil(t, err)
 				require.Contains(t, err.Error(), tt.want.Error)
@@ -439,6 +442,7 @@ func TestDispatcherSendRequest(t *testing.T) {
 	// Send request
 	resp, err := dispatcher.SendWebhook(
 		context.Background(),
+		http.MethodPost,
 		server.URL,
 		jsonData,
 		"X-Signature",
@@ -456,6 +460,56 @@ func TestDispatcherSendRequest(t *testing.T) {
 	require.Equal(t, "custom-value", resp.RequestHeader.Get("X-Custom-Header"))
 }
 
+// TestDispatcherSendRequestMethod tests that SendWebhook uses the given method
+func TestDispatcherSendRequestMethod(t *testing.T) {
+	tests := []struct {
+		method     string
+		wantMethod string
+	}{
+		{method: "", wantMethod: http.MethodPost},
+		{method: http.MethodPut, wantMethod: http.MethodPut},
+		{method: http.MethodPatch, wantMethod: http.MethodPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantMethod, func(t *testing.T) {
+			var gotMethod, gotSignature, gotQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+				gotSignature = r.Header.Get("X-Signature")
+				gotQuery = r.URL.RawQuery
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			licenser := mocks.NewMockLicenser(ctrl)
+			licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+			licenser.EXPECT().IpRules().Times(4).Return(true)
+
+			dispatcher, err := NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				LoggerOption(log.NewLogger(os.Stdout)),
+				ProxyOption("nil"),
+				AllowListOption([]string{"0.0.0.0/0"}),
+				BlockListOption([]string{"10.0.0.0/8"}),
+			)
+			require.NoError(t, err)
+
+			_, err = dispatcher.SendWebhook(context.Background(), tt.method, server.URL+"/hooks?source=convoy", json.RawMessage(`{"key": "value"}`), "X-Signature", "test-hmac", 1024, httpheader.HTTPHeader{}, "", 5*time.Second)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantMethod, gotMethod)
+
+			// the signature and query params are sent whatever the method
+			require.Equal(t, "test-hmac", gotSignature)
+			require.Equal(t, "source=convoy", gotQuery)
+		})
+	}
+}
+
 // TestDispatcherWithTimeout tests the timeout functionality
 func TestDispatcherWithTimeout(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -484,6 +538,7 @@ func TestDispatcherWithTimeout(t *testing.T) {
 	// Send request with a short timeout
 	_, err = dispatcher.SendWebhook(
 		context.Background(),
+		http.MethodPost,
 		server.URL,
 		nil,
 		"X-Signature",
@@ -529,6 +584,7 @@ func TestDispatcherWithBlockedIP(t *testing.T) {
 	// Attempt to send a request
 	_, err = dispatcher.SendWebhook(
 		context.Background(),
+		http.MethodPost,
 		server.URL,
 		nil,
 		"X-Signature",
@@ -544,3 +600,171 @@ func TestDispatcherWithBlockedIP(t *testing.T) {
 	require.ErrorIs(t, err, netjail.ErrDenied)
 	require.Contains(t, err.Error(), "127.0.0.1: address not allowed")
 }
+
+func TestDispatcherDoWithBlockedIP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	licenser := mocks.NewMockLicenser(ctrl)
+	licenser.EXPECT().IpRules().AnyTimes().Return(true)
+
+	dispatcher, err := NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{string(fflag.IpRules)}),
+		LoggerOption(log.NewLogger(os.Stdout)),
+		AllowListOption([]string{"0.0.0.0/0"}),
+		BlockListOption([]string{"127.0.0.0/8"}),
+	)
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	// other requests sent through the dispatcher are held to the same rules
+	_, err = dispatcher.Do(req)
+	require.ErrorIs(t, err, netjail.ErrDenied)
+}
+
+func TestDispatcherForceHTTP2(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverHTTP2  bool
+		forceHTTP2   bool
+		wantProtocol int
+	}{
+		{name: "should_send_http2_when_forced", serverHTTP2: true, forceHTTP2: true, wantProtocol: 2},
+		{name: "should_fall_back_to_http1_when_unsupported", serverHTTP2: false, forceHTTP2: true, wantProtocol: 1},
+		{name: "should_send_http1_when_not_forced", serverHTTP2: true, forceHTTP2: false, wantProtocol: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var protocol int
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				protocol = r.ProtoMajor
+				w.WriteHeader(http.StatusOK)
+			}))
+			server.EnableHTTP2 = tt.serverHTTP2
+			server.StartTLS()
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			licenser := mocks.NewMockLicenser(ctrl)
+
+			dispatcher, err := NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{}),
+				LoggerOption(log.NewLogger(os.Stdout)),
+				TLSConfigOption(true, licenser, nil),
+				ForceHTTP2Option(tt.forceHTTP2),
+			)
+			require.NoError(t, err)
+
+			resp, err := dispatcher.SendUnsignedWebhook(context.Background(), http.MethodPost, server.URL, json.RawMessage(`{}`), 1024, nil, "", 5*time.Second)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tt.wantProtocol, protocol)
+		})
+	}
+}
+
+func TestConnectionPoolOption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	licenser := mocks.NewMockLicenser(ctrl)
+
+	d, err := NewDispatcher(licenser, fflag.NewFFlag([]string{}), ConnectionPoolOption(2000, time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2000, d.transport.MaxIdleConnsPerHost)
+	require.Equal(t, 2000, d.transport.MaxIdleConns)
+	require.Equal(t, time.Minute, d.transport.IdleConnTimeout)
+
+	// zero values keep the defaults
+	d, err = NewDispatcher(licenser, fflag.NewFFlag([]string{}), ConnectionPoolOption(0, 0))
+	require.NoError(t, err)
+	require.Equal(t, 100, d.transport.MaxIdleConnsPerHost)
+	require.Equal(t, 30*time.Second, d.transport.IdleConnTimeout)
+
+	_, err = NewDispatcher(licenser, fflag.NewFFlag([]string{}), ConnectionPoolOption(-1, 0))
+	require.ErrorIs(t, err, ErrInvalidMaxIdleConnsPerHost)
+
+	_, err = NewDispatcher(licenser, fflag.NewFFlag([]string{}), ConnectionPoolOption(0, -time.Second))
+	require.ErrorIs(t, err, ErrInvalidIdleConnTimeout)
+}
+
+func TestDispatcherTransientRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		transientRetries int
+		noRetries        bool
+		wantErr          bool
+		wantRequests     int32
+	}{
+		{name: "should_fail_on_connection_reset_by_default", transientRetries: 0, wantErr: true, wantRequests: 1},
+		{name: "should_succeed_after_a_retry_on_connection_reset", transientRetries: 1, wantErr: false, wantRequests: 2},
+		{name: "should_not_retry_when_the_context_says_not_to", transientRetries: 1, noRetries: true, wantErr: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, `{"name":"convoy"}`, string(body))
+
+				// the first request has its connection reset
+				if requests.Add(1) == 1 {
+					conn, _, err := w.(http.Hijacker).Hijack()
+					require.NoError(t, err)
+					require.NoError(t, conn.(*stdnet.TCPConn).SetLinger(0))
+					require.NoError(t, conn.Close())
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			licenser := mocks.NewMockLicenser(ctrl)
+
+			dispatcher, err := NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{}),
+				LoggerOption(log.NewLogger(os.Stdout)),
+				TransientRetryOption(tt.transientRetries),
+			)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if tt.noRetries {
+				ctx = ContextWithoutTransientRetries(ctx)
+			}
+
+			resp, err := dispatcher.SendUnsignedWebhook(ctx, http.MethodPost, server.URL, json.RawMessage(`{"name":"convoy"}`), 1024, nil, "", 5*time.Second)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.NotEmpty(t, resp.Error)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.Empty(t, resp.Error)
+			}
+			require.Equal(t, tt.wantRequests, requests.Load())
+		})
+	}
+
+	_, err := NewDispatcher(mocks.NewMockLicenser(gomock.NewController(t)), fflag.NewFFlag([]string{}), TransientRetryOption(-1))
+	require.ErrorIs(t, err, ErrInvalidTransientRetries)
+}
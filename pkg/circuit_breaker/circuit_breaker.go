@@ -30,6 +30,8 @@ type CircuitBreaker struct {
 	ConsecutiveFailures uint64 `json:"consecutive_failures"`
 	// Number of notifications (maximum of 3) sent in the observability window
 	NotificationsSent uint64 `json:"notifications_sent"`
+	// Time the circuit breaker last went from half-open to closed
+	RecoveredAt time.Time `json:"recovered_at"`
 
 	logger *log.Logger
 }
@@ -81,6 +83,7 @@ func (b *CircuitBreaker) asKeyValue() map[string]interface{} {
 	kv["total_successes"] = b.TotalSuccesses
 	kv["consecutive_failures"] = b.ConsecutiveFailures
 	kv["notifications_sent"] = b.NotificationsSent
+	kv["recovered_at"] = b.RecoveredAt
 	return kv
 }
 
@@ -88,6 +91,7 @@ func (b *CircuitBreaker) trip(resetTime time.Time) {
 	b.State = StateOpen
 	b.WillResetAt = resetTime
 	b.ConsecutiveFailures++
+	b.RecoveredAt = time.Time{}
 	if b.logger != nil {
 		b.logger.Infof("[circuit breaker] circuit breaker transitioned to open.")
 		b.logger.Debugf("[circuit breaker] circuit breaker state: %+v", b.asKeyValue())
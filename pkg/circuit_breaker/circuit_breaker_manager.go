@@ -8,10 +8,13 @@ import (
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/pkg/msgpack"
 	"strings"
+	"sync"
 	"time"
 )
 
 const prefix = "breaker:"
+const probePrefix = "breaker_probes:"
+const rampPrefix = "breaker_ramp:"
 const mutexKey = "convoy:circuit_breaker:mutex"
 
 type PollFunc func(ctx context.Context, lookBackDuration uint64, resetTimes map[string]time.Time) (map[string]PollResult, error)
@@ -24,6 +27,14 @@ var (
 	// ErrOpenState is returned when the circuit breaker state is open
 	ErrOpenState = errors.New("[circuit breaker] circuit breaker is open")
 
+	// ErrProbeNotAllowed is returned when the circuit breaker state is half open and
+	// all the probes it allows are in flight or have failed
+	ErrProbeNotAllowed = errors.New("[circuit breaker] half-open probe limit reached")
+
+	// ErrRecoveryRampLimited is returned when the circuit breaker has just closed
+	// and the requests it lets through each second while ramping up are used up
+	ErrRecoveryRampLimited = errors.New("[circuit breaker] recovery ramp limit reached")
+
 	// ErrCircuitBreakerNotFound is returned when the circuit breaker is not found
 	ErrCircuitBreakerNotFound = errors.New("[circuit breaker] circuit breaker not found")
 
@@ -236,6 +247,7 @@ func (cb *CircuitBreakerManager) sampleStore(ctx context.Context, pollResults ma
 
 		if breaker.State == StateHalfOpen && breaker.SuccessRate >= float64(cb.config.SuccessThreshold) {
 			breaker.Reset(cb.clock.Now().Add(time.Duration(cb.config.BreakerTimeout) * time.Second))
+			breaker.RecoveredAt = cb.clock.Now()
 		} else if (breaker.State == StateClosed || breaker.State == StateHalfOpen) && breaker.Requests >= cb.config.MinimumRequestCount {
 			if breaker.FailureRate >= float64(cb.config.FailureThreshold) {
 				breaker.trip(cb.clock.Now().Add(time.Duration(cb.config.BreakerTimeout) * time.Second))
@@ -354,6 +366,109 @@ func (cb *CircuitBreakerManager) CanExecute(ctx context.Context, key string) err
 	return nil
 }
 
+// IsStateError reports whether err was returned by CanExecute because of the
+// breaker's state, any other error means the state couldn't be read.
+func IsStateError(err error) bool {
+	return errors.Is(err, ErrOpenState) || errors.Is(err, ErrTooManyRequests) || errors.Is(err, ErrProbeNotAllowed) ||
+		errors.Is(err, ErrRecoveryRampLimited)
+}
+
+// ProbeDone reports the outcome of a request let through by Allow, it is safe
+// to call more than once and only the first call counts.
+type ProbeDone func(failed bool)
+
+func noopProbeDone(bool) {}
+
+// Allow is CanExecute with the number of probes a half-open circuit breaker lets
+// through capped at HalfOpenMaxProbes. A probe holds its slot until it's done,
+// probes that fail keep holding it until the breaker leaves the half-open state,
+// so once every slot is held the rest are turned away with ErrProbeNotAllowed
+// until a probe succeeds. A breaker that closed less than RecoveryRampDuration
+// ago lets RecoveryRampRate requests through each second and turns the rest away
+// with ErrRecoveryRampLimited. The returned ProbeDone must be called with the outcome.
+func (cb *CircuitBreakerManager) Allow(ctx context.Context, key string) (ProbeDone, error) {
+	b, err := cb.GetCircuitBreaker(ctx, key)
+	if err != nil {
+		return noopProbeDone, err
+	}
+
+	if b == nil {
+		return noopProbeDone, nil
+	}
+
+	if b.State == StateClosed {
+		return noopProbeDone, cb.rampUp(ctx, key, *b)
+	}
+
+	err = cb.getCircuitBreakerError(*b)
+	if err != nil {
+		return noopProbeDone, err
+	}
+
+	if b.State != StateHalfOpen || cb.config.HalfOpenMaxProbes == 0 {
+		return noopProbeDone, nil
+	}
+
+	// breakers are tripped with a new reset time, so probes
+	// are counted afresh every time a breaker goes half-open
+	probeKey := fmt.Sprintf("%s%s:%d", probePrefix, key, b.WillResetAt.Unix())
+	ttl := time.Duration(cb.config.ObservabilityWindow) * time.Minute
+
+	probes, err := cb.store.Incr(ctx, probeKey, 1, ttl)
+	if err != nil {
+		return noopProbeDone, err
+	}
+
+	if probes > int64(cb.config.HalfOpenMaxProbes) {
+		_, err = cb.store.Incr(ctx, probeKey, -1, ttl)
+		if err != nil {
+			cb.logger.WithError(err).Errorf("[circuit breaker] failed to release rejected probe of %s", key)
+		}
+		return noopProbeDone, ErrProbeNotAllowed
+	}
+
+	var once sync.Once
+	return func(failed bool) {
+		once.Do(func() {
+			if failed {
+				return
+			}
+
+			_, innerErr := cb.store.Incr(context.WithoutCancel(ctx), probeKey, -1, ttl)
+			if innerErr != nil {
+				cb.logger.WithError(innerErr).Errorf("[circuit breaker] failed to release probe of %s", key)
+			}
+		})
+	}, nil
+}
+
+// rampUp caps the requests let through each second by a recently closed breaker
+func (cb *CircuitBreakerManager) rampUp(ctx context.Context, key string, b CircuitBreaker) error {
+	if cb.config.RecoveryRampDuration == 0 || b.RecoveredAt.IsZero() {
+		return nil
+	}
+
+	now := cb.clock.Now()
+	if now.Sub(b.RecoveredAt) >= time.Duration(cb.config.RecoveryRampDuration)*time.Second {
+		return nil
+	}
+
+	// requests are counted in one second windows, the counters
+	// only need to outlive the second they were counted in
+	rampKey := fmt.Sprintf("%s%s:%d", rampPrefix, key, now.Unix())
+
+	requests, err := cb.store.Incr(ctx, rampKey, 1, 2*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if requests > int64(cb.config.RecoveryRampRate) {
+		return ErrRecoveryRampLimited
+	}
+
+	return nil
+}
+
 // GetCircuitBreaker is used to get fetch the circuit breaker state,
 // it fails open if the circuit breaker for that key is not found
 func (cb *CircuitBreakerManager) GetCircuitBreaker(ctx context.Context, key string) (c *CircuitBreaker, err error) {
@@ -906,3 +906,202 @@ func TestCircuitBreakerManager_Start(t *testing.T) {
 	// Ensure the poll function was called multiple times
 	require.True(t, pollCount > 1)
 }
+
+// encodingTestStore returns breakers encoded like the redis store does,
+// so sampleStore carries their state over between samples
+type encodingTestStore struct {
+	*TestStore
+}
+
+func (s *encodingTestStore) GetMany(ctx context.Context, keys ...string) ([]interface{}, error) {
+	vals := make([]interface{}, len(keys))
+	for i, key := range keys {
+		v, err := s.GetOne(ctx, key)
+		if err != nil {
+			if errors.Is(err, ErrCircuitBreakerNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		vals[i] = v
+	}
+
+	return vals, nil
+}
+
+func TestCircuitBreakerManager_HalfOpenProbes(t *testing.T) {
+	ctx := context.Background()
+	testClock := clock.NewSimulatedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	c := &CircuitBreakerConfig{
+		SampleRate:                  2,
+		BreakerTimeout:              30,
+		FailureThreshold:            50,
+		SuccessThreshold:            10,
+		MinimumRequestCount:         10,
+		ObservabilityWindow:         5,
+		ConsecutiveFailureThreshold: 10,
+		HalfOpenMaxProbes:           2,
+	}
+	b, err := NewCircuitBreakerManager(
+		ClockOption(testClock),
+		StoreOption(&encodingTestStore{NewTestStore()}),
+		ConfigOption(c),
+		LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	endpointId := "endpoint-1"
+
+	// open
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 13, 1)))
+	_, err = b.Allow(ctx, endpointId)
+	require.ErrorIs(t, err, ErrOpenState)
+
+	// half-open once the breaker timeout has passed
+	testClock.AdvanceTime(time.Duration(c.BreakerTimeout+1) * time.Second)
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 10, 1)))
+
+	breaker, err := b.GetCircuitBreakerWithError(ctx, endpointId)
+	require.NoError(t, err)
+	require.Equal(t, StateHalfOpen, breaker.State)
+
+	first, err := b.Allow(ctx, endpointId)
+	require.NoError(t, err)
+
+	second, err := b.Allow(ctx, endpointId)
+	require.NoError(t, err)
+
+	_, err = b.Allow(ctx, endpointId)
+	require.ErrorIs(t, err, ErrProbeNotAllowed)
+	require.True(t, IsStateError(err))
+
+	// a failed probe keeps its slot
+	first(true)
+	_, err = b.Allow(ctx, endpointId)
+	require.ErrorIs(t, err, ErrProbeNotAllowed)
+
+	// a successful one hands it back, reporting it twice doesn't free another
+	second(false)
+	second(false)
+
+	third, err := b.Allow(ctx, endpointId)
+	require.NoError(t, err)
+	third(false)
+
+	// closed once enough probes succeed, there's no limit on a closed breaker
+	testClock.AdvanceTime(5 * time.Second)
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 0, 2)))
+
+	breaker, err = b.GetCircuitBreakerWithError(ctx, endpointId)
+	require.NoError(t, err)
+	require.Equal(t, StateClosed, breaker.State)
+
+	for i := 0; i < 5; i++ {
+		done, err := b.Allow(ctx, endpointId)
+		require.NoError(t, err)
+		done(true)
+	}
+}
+
+func TestCircuitBreakerManager_HalfOpenProbesUnlimited(t *testing.T) {
+	ctx := context.Background()
+	store := NewTestStore()
+
+	b, err := NewCircuitBreakerManager(
+		ClockOption(clock.NewSimulatedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))),
+		StoreOption(store),
+		ConfigOption(&CircuitBreakerConfig{
+			SampleRate:                  2,
+			BreakerTimeout:              30,
+			FailureThreshold:            50,
+			SuccessThreshold:            10,
+			MinimumRequestCount:         10,
+			ObservabilityWindow:         5,
+			ConsecutiveFailureThreshold: 10,
+		}),
+		LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	err = store.SetOne(ctx, "breaker:endpoint-1", CircuitBreaker{Key: "breaker:endpoint-1", State: StateHalfOpen}, time.Minute)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		done, err := b.Allow(ctx, "endpoint-1")
+		require.NoError(t, err)
+		done(true)
+	}
+}
+
+func TestCircuitBreakerManager_RecoveryRamp(t *testing.T) {
+	ctx := context.Background()
+	testClock := clock.NewSimulatedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	c := &CircuitBreakerConfig{
+		SampleRate:                  2,
+		BreakerTimeout:              30,
+		FailureThreshold:            50,
+		SuccessThreshold:            10,
+		MinimumRequestCount:         10,
+		ObservabilityWindow:         5,
+		ConsecutiveFailureThreshold: 10,
+		RecoveryRampDuration:        10,
+		RecoveryRampRate:            2,
+	}
+	b, err := NewCircuitBreakerManager(
+		ClockOption(testClock),
+		StoreOption(&encodingTestStore{NewTestStore()}),
+		ConfigOption(c),
+		LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	endpointId := "endpoint-1"
+
+	// a breaker that has never tripped doesn't ramp
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 1, 2)))
+	for i := 0; i < 5; i++ {
+		_, err = b.Allow(ctx, endpointId)
+		require.NoError(t, err)
+	}
+
+	// open, then half-open, then closed
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 13, 1)))
+	testClock.AdvanceTime(time.Duration(c.BreakerTimeout+1) * time.Second)
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 10, 1)))
+	testClock.AdvanceTime(5 * time.Second)
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 0, 2)))
+
+	breaker, err := b.GetCircuitBreakerWithError(ctx, endpointId)
+	require.NoError(t, err)
+	require.Equal(t, StateClosed, breaker.State)
+	require.True(t, testClock.Now().Equal(breaker.RecoveredAt))
+
+	// only RecoveryRampRate requests go out each second right after closing
+	for second := 0; second < 3; second++ {
+		for i := 0; i < int(c.RecoveryRampRate); i++ {
+			_, err = b.Allow(ctx, endpointId)
+			require.NoError(t, err)
+		}
+
+		_, err = b.Allow(ctx, endpointId)
+		require.ErrorIs(t, err, ErrRecoveryRampLimited)
+		require.True(t, IsStateError(err))
+
+		testClock.AdvanceTime(time.Second)
+	}
+
+	// back to normal once the ramp is over
+	testClock.AdvanceTime(time.Duration(c.RecoveryRampDuration) * time.Second)
+	for i := 0; i < 10; i++ {
+		_, err = b.Allow(ctx, endpointId)
+		require.NoError(t, err)
+	}
+
+	// tripping again ends a ramp
+	require.NoError(t, b.sampleStore(ctx, pollResult(t, endpointId, 13, 1)))
+	breaker, err = b.GetCircuitBreakerWithError(ctx, endpointId)
+	require.NoError(t, err)
+	require.True(t, breaker.RecoveredAt.IsZero())
+}
@@ -34,6 +34,21 @@ type CircuitBreakerConfig struct {
 	// ConsecutiveFailureThreshold determines when we ultimately disable the endpoint.
 	// E.g., after 10 consecutive transitions from half-open → open we should disable it.
 	ConsecutiveFailureThreshold uint64 `json:"consecutive_failure_threshold"`
+
+	// HalfOpenMaxProbes is the number of requests a circuit breaker in the half-open
+	// state lets through at once, the rest are rejected until a probe succeeds.
+	// There's no limit when it is 0
+	HalfOpenMaxProbes uint64 `json:"half_open_max_probes"`
+
+	// RecoveryRampDuration is the time (in seconds) after a circuit breaker closes
+	// during which requests are capped at RecoveryRampRate, so the retries queued
+	// while it was open don't all hit the recovered resource at once.
+	// There's no ramp when it is 0
+	RecoveryRampDuration uint64 `json:"recovery_ramp_duration"`
+
+	// RecoveryRampRate is the number of requests per second a circuit breaker
+	// lets through while it's ramping up after closing
+	RecoveryRampRate uint64 `json:"recovery_ramp_rate"`
 }
 
 func (c *CircuitBreakerConfig) Validate() error {
@@ -80,6 +95,11 @@ func (c *CircuitBreakerConfig) Validate() error {
 		errs.WriteString("; ")
 	}
 
+	if c.RecoveryRampDuration > 0 && c.RecoveryRampRate == 0 {
+		errs.WriteString("RecoveryRampRate must be greater than 0 when RecoveryRampDuration is set")
+		errs.WriteString("; ")
+	}
+
 	if errs.Len() > 0 {
 		return fmt.Errorf("config validation failed with errors: %s", errs.String())
 	}
@@ -114,6 +114,21 @@ func TestCircuitBreakerConfig_Validate(t *testing.T) {
 			wantErr: true,
 			err:     "MinimumRequestCount must be greater than 10",
 		},
+		{
+			name: "RecoveryRampDuration without a RecoveryRampRate",
+			config: CircuitBreakerConfig{
+				SampleRate:                  1,
+				BreakerTimeout:              30,
+				FailureThreshold:            30,
+				SuccessThreshold:            2,
+				ObservabilityWindow:         5,
+				MinimumRequestCount:         10,
+				ConsecutiveFailureThreshold: 1,
+				RecoveryRampDuration:        60,
+			},
+			wantErr: true,
+			err:     "RecoveryRampRate must be greater than 0 when RecoveryRampDuration is set",
+		},
 	}
 
 	for _, tt := range tests {
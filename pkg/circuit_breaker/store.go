@@ -21,6 +21,7 @@ type CircuitBreakerStore interface {
 	GetMany(context.Context, ...string) ([]interface{}, error)
 	SetOne(context.Context, string, interface{}, time.Duration) error
 	SetMany(context.Context, map[string]CircuitBreaker, time.Duration) error
+	Incr(context.Context, string, int64, time.Duration) (int64, error)
 }
 
 type RedisStore struct {
@@ -116,17 +117,34 @@ func (s *RedisStore) SetMany(ctx context.Context, breakers map[string]CircuitBre
 	return nil
 }
 
+// Incr adds delta to the counter at key and returns its new value, the
+// counter expires after ttl
+func (s *RedisStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	pipe := s.redis.TxPipeline()
+	incr := pipe.IncrBy(ctx, key, delta)
+	pipe.Expire(ctx, key, ttl)
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
 type TestStore struct {
-	store map[string]CircuitBreaker
-	mu    *sync.RWMutex
-	clock clock.Clock
+	store    map[string]CircuitBreaker
+	counters map[string]int64
+	mu       *sync.RWMutex
+	clock    clock.Clock
 }
 
 func NewTestStore() *TestStore {
 	return &TestStore{
-		store: make(map[string]CircuitBreaker),
-		mu:    &sync.RWMutex{},
-		clock: clock.NewSimulatedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		store:    make(map[string]CircuitBreaker),
+		counters: make(map[string]int64),
+		mu:       &sync.RWMutex{},
+		clock:    clock.NewSimulatedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
 	}
 }
 
@@ -196,3 +214,10 @@ func (t *TestStore) SetMany(ctx context.Context, m map[string]CircuitBreaker, du
 	}
 	return nil
 }
+
+func (t *TestStore) Incr(_ context.Context, key string, delta int64, _ time.Duration) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counters[key] += delta
+	return t.counters[key], nil
+}
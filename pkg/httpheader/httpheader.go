@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"net/http"
 )
 
 // HTTPHeader is our custom  header type that can merge fields.
@@ -22,6 +23,24 @@ func (h HTTPHeader) MergeHeaders(nh HTTPHeader) {
 	}
 }
 
+// Filter returns the subset of h whose keys appear in allowList.
+// Keys are compared in their canonical form.
+func (h HTTPHeader) Filter(allowList []string) HTTPHeader {
+	allowed := make(map[string]struct{}, len(allowList))
+	for _, key := range allowList {
+		allowed[http.CanonicalHeaderKey(key)] = struct{}{}
+	}
+
+	filtered := HTTPHeader{}
+	for k, v := range h {
+		if _, ok := allowed[http.CanonicalHeaderKey(k)]; ok {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
 func (h *HTTPHeader) Scan(value interface{}) error {
 	if value == nil {
 		*h = nil
@@ -55,3 +55,19 @@ func Test_MergeHeaders(t *testing.T) {
 		})
 	}
 }
+
+func Test_Filter(t *testing.T) {
+	header := HTTPHeader{
+		"Stripe-Signature": []string{"t=1492774577,v1=5257a869e7"},
+		"X-GitHub-Event":   []string{"issue_comment"},
+		"User-Agent":       []string{"GitHub-Hookshot/9398d35"},
+	}
+
+	filtered := header.Filter([]string{"stripe-signature", "X-GitHub-Event", "X-Missing"})
+
+	require.Equal(t, HTTPHeader{
+		"Stripe-Signature": []string{"t=1492774577,v1=5257a869e7"},
+		"X-GitHub-Event":   []string{"issue_comment"},
+	}, filtered)
+	require.NotContains(t, filtered, "User-Agent")
+}
@@ -0,0 +1,94 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSignatureHeader is returned when a signature header cannot be parsed.
+var ErrInvalidSignatureHeader = errors.New("invalid signature header")
+
+// CanonicalBatch returns the bytes a batch signature is computed over.
+//
+// Each payload is compacted the same way single event payloads are,
+// then written as a netstring: its length in bytes, a colon, the
+// payload and a trailing comma. The length prefix keeps the encoding
+// unambiguous, so no two different batches share canonical bytes.
+// Payload order is significant.
+func CanonicalBatch(payloads []json.RawMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, payload := range payloads {
+		p, err := encodeJSON(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString(strconv.Itoa(len(p)))
+		buf.WriteByte(':')
+		buf.Write(p)
+		buf.WriteByte(',')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// VerifyBatch reports whether header is a valid signature over payloads
+// for the given secret. It accepts both simple and advanced signature
+// headers, and consumers can use it to verify a batch they received.
+func VerifyBatch(payloads []json.RawMessage, header string, scheme Scheme) (bool, error) {
+	if len(scheme.Secret) == 0 {
+		return false, errors.New("signature secret cannot be empty")
+	}
+
+	buf, err := CanonicalBatch(payloads)
+	if err != nil {
+		return false, err
+	}
+
+	s := &Signature{}
+	if !strings.HasPrefix(header, "t=") {
+		for _, sec := range scheme.Secret {
+			sig, err := s.generateSignature(scheme, sec, buf)
+			if err != nil {
+				return false, err
+			}
+
+			if hmac.Equal([]byte(sig), []byte(header)) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	parts := strings.Split(header, ",")
+	ts := strings.TrimPrefix(parts[0], "t=")
+	if len(parts) < 2 || ts == "" {
+		return false, ErrInvalidSignatureHeader
+	}
+
+	signedPayload := append([]byte(ts+","), buf...)
+	for _, sec := range scheme.Secret {
+		sig, err := s.generateSignature(scheme, sec, signedPayload)
+		if err != nil {
+			return false, err
+		}
+
+		for _, part := range parts[1:] {
+			_, v, ok := strings.Cut(part, "=")
+			if !ok {
+				return false, ErrInvalidSignatureHeader
+			}
+
+			if hmac.Equal([]byte(sig), []byte(v)) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,113 @@
+package signature
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CanonicalBatch(t *testing.T) {
+	payloads := []json.RawMessage{
+		json.RawMessage(`{"b": {}, "e": "123", "a": 1}`),
+		json.RawMessage(`{"event": "invoice.paid"}`),
+	}
+
+	buf, err := CanonicalBatch(payloads)
+	require.NoError(t, err)
+	require.Equal(t, `24:{"b":{},"e":"123","a":1},24:{"event":"invoice.paid"},`, string(buf))
+
+	// the length prefix keeps the encoding unambiguous
+	split, err := CanonicalBatch([]json.RawMessage{json.RawMessage(`1`), json.RawMessage(`2`)})
+	require.NoError(t, err)
+	merged, err := CanonicalBatch([]json.RawMessage{json.RawMessage(`12`)})
+	require.NoError(t, err)
+	require.NotEqual(t, split, merged)
+}
+
+func Test_Batch_Signatures(t *testing.T) {
+	scheme := Scheme{
+		Secret:   []string{"secret"},
+		Hash:     "SHA256",
+		Encoding: "hex",
+	}
+
+	payloads := []json.RawMessage{
+		json.RawMessage(`{"id": 1, "event": "invoice.created"}`),
+		json.RawMessage(`{"id": 2, "event": "invoice.paid"}`),
+		json.RawMessage(`{"id": 3, "event": "invoice.refunded"}`),
+	}
+
+	tests := map[string]struct {
+		advanced bool
+	}{
+		"simple_signature":   {advanced: false},
+		"advanced_signature": {advanced: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sig := &Signature{
+				Batch:    payloads,
+				Schemes:  []Scheme{scheme},
+				Advanced: tc.advanced,
+				generateTimestampFn: func() string {
+					return "1257894000"
+				},
+			}
+
+			header, err := sig.ComputeHeaderValue()
+			require.NoError(t, err)
+
+			// the signature validates over the reconstructed canonical bytes
+			canonical, err := CanonicalBatch(payloads)
+			require.NoError(t, err)
+
+			single := &Signature{Schemes: []Scheme{scheme}}
+			signedPayload := canonical
+			if tc.advanced {
+				signedPayload = append([]byte("1257894000,"), canonical...)
+			}
+			want, err := single.generateSignature(scheme, "secret", signedPayload)
+			require.NoError(t, err)
+			require.Contains(t, header, want)
+
+			ok, err := VerifyBatch(payloads, header, scheme)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			// changing any event in the batch changes the signature
+			for i := range payloads {
+				tampered := make([]json.RawMessage, len(payloads))
+				copy(tampered, payloads)
+				tampered[i] = json.RawMessage(`{"id": 0, "event": "tampered"}`)
+
+				tamperedHeader, err := (&Signature{
+					Batch:               tampered,
+					Schemes:             []Scheme{scheme},
+					Advanced:            tc.advanced,
+					generateTimestampFn: sig.generateTimestampFn,
+				}).ComputeHeaderValue()
+				require.NoError(t, err)
+				require.NotEqual(t, header, tamperedHeader)
+
+				ok, err = VerifyBatch(tampered, header, scheme)
+				require.NoError(t, err)
+				require.False(t, ok)
+			}
+
+			// reordering the batch changes the signature
+			reordered := []json.RawMessage{payloads[1], payloads[0], payloads[2]}
+			ok, err = VerifyBatch(reordered, header, scheme)
+			require.NoError(t, err)
+			require.False(t, ok)
+		})
+	}
+}
+
+func Test_VerifyBatch_InvalidHeader(t *testing.T) {
+	scheme := Scheme{Secret: []string{"secret"}, Hash: "SHA256", Encoding: "hex"}
+
+	_, err := VerifyBatch([]json.RawMessage{json.RawMessage(`{}`)}, "t=1257894000", scheme)
+	require.ErrorIs(t, err, ErrInvalidSignatureHeader)
+}
@@ -0,0 +1,113 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultProxyTimeout = 10 * time.Second
+
+	// maxProxyResponseSize caps how much of the signing service's response we read
+	maxProxyResponseSize = 64 * 1024
+)
+
+// ErrSigningProxyFailed is the error returned when the signing service
+// doesn't return a signature.
+var ErrSigningProxyFailed = errors.New("signing proxy failed to sign the payload")
+
+// ProxySigner signs payloads through an external signing service, so the
+// signing keys stay with the service (e.g. in an HSM) and never reach us.
+// Nothing it receives from the service is cached.
+type ProxySigner struct {
+	URL    string
+	Client Doer
+}
+
+// Doer sends requests to the signing service. The signing service's url is
+// set per project, so it must be sent through the same egress rules as
+// webhooks, see net.Dispatcher.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ProxySignRequest is the body sent to the signing service.
+type ProxySignRequest struct {
+	ProjectID       string          `json:"project_id"`
+	EndpointID      string          `json:"endpoint_id"`
+	EventDeliveryID string          `json:"event_delivery_id"`
+	Payload         json.RawMessage `json:"payload"`
+
+	// Target is set when the signature must also cover the request
+	// line and host, see RequestTarget.Canonical
+	Target *RequestTarget `json:"target,omitempty"`
+
+	// Query is set when the signature must also cover the request's
+	// query string, it is already in the order CanonicalQuery puts it
+	Query *string `json:"query,omitempty"`
+
+	// Epoch is set when the signature must also cover the rotation
+	// epoch of the endpoint's secret, see Signature.Epoch
+	Epoch *int64 `json:"epoch,omitempty"`
+}
+
+type proxySignResponse struct {
+	// Signature is used verbatim as the signature header value
+	Signature string `json:"signature"`
+}
+
+func NewProxySigner(url string, client Doer) *ProxySigner {
+	return &ProxySigner{
+		URL:    url,
+		Client: client,
+	}
+}
+
+// Sign asks the signing service for the signature header value of the request's payload.
+func (p *ProxySigner) Sign(ctx context.Context, r ProxySignRequest) (string, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFailedToEncodePayload, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultProxyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSigningProxyFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSigningProxyFailed, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxProxyResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSigningProxyFailed, err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return "", fmt.Errorf("%w: signing service responded with status %d", ErrSigningProxyFailed, resp.StatusCode)
+	}
+
+	var res proxySignResponse
+	if err = json.Unmarshal(data, &res); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSigningProxyFailed, err)
+	}
+
+	if res.Signature == "" {
+		return "", fmt.Errorf("%w: signing service returned an empty signature", ErrSigningProxyFailed)
+	}
+
+	return res.Signature, nil
+}
@@ -0,0 +1,91 @@
+package signature
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxySigner_Sign(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantSignature string
+		wantErr       error
+	}{
+		{
+			name:          "should_return_the_service_signature",
+			status:        http.StatusOK,
+			body:          `{"signature": "t=1,v1=abc"}`,
+			wantSignature: "t=1,v1=abc",
+		},
+		{
+			name:    "should_fail_when_the_service_errors",
+			status:  http.StatusInternalServerError,
+			body:    `{"error": "hsm unavailable"}`,
+			wantErr: ErrSigningProxyFailed,
+		},
+		{
+			name:    "should_fail_on_an_empty_signature",
+			status:  http.StatusOK,
+			body:    `{"signature": ""}`,
+			wantErr: ErrSigningProxyFailed,
+		},
+		{
+			name:    "should_fail_on_an_invalid_response",
+			status:  http.StatusOK,
+			body:    `signature`,
+			wantErr: ErrSigningProxyFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ProxySignRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			req := ProxySignRequest{
+				ProjectID:       "project-1",
+				EndpointID:      "endpoint-1",
+				EventDeliveryID: "delivery-1",
+				Payload:         json.RawMessage(`{"name":"convoy"}`),
+			}
+
+			sig, err := NewProxySigner(server.URL, server.Client()).Sign(context.Background(), req)
+			require.Equal(t, req, got)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSignature, sig)
+		})
+	}
+}
+
+func TestProxySigner_SignUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := NewProxySigner(server.URL, server.Client()).Sign(context.Background(), ProxySignRequest{Payload: json.RawMessage(`{}`)})
+	require.ErrorIs(t, err, ErrSigningProxyFailed)
+}
+
+func TestProxySigner_SignMalformedURL(t *testing.T) {
+	_, err := NewProxySigner("http://signer\x7f", http.DefaultClient).Sign(context.Background(), ProxySignRequest{Payload: json.RawMessage(`{}`)})
+	require.ErrorIs(t, err, ErrSigningProxyFailed)
+}
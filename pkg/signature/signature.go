@@ -11,6 +11,9 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +28,10 @@ var (
 
 	// ErrInvalidHash is the error returned when a unsupported hash is supplied.
 	ErrInvalidHash = errors.New("Hash not supported")
+
+	// ErrEmptySignatureScheme is the error returned when no scheme can sign,
+	// e.g. every version's active window has lapsed.
+	ErrEmptySignatureScheme = errors.New("signature scheme cannot be empty")
 )
 
 type Scheme struct {
@@ -35,16 +42,46 @@ type Scheme struct {
 
 	Hash     string
 	Encoding string
+
+	// Inactive leaves the scheme out of the header while keeping
+	// its version number, e.g. outside a rotation window.
+	Inactive bool
 }
 
 type Signature struct {
 	Payload json.RawMessage
 
+	// Encoded, when set, signs Payload byte for byte instead of as JSON,
+	// for payloads sent in another encoding, e.g. form encoded.
+	Encoded bool
+
+	// Batch, when set, makes the signature cover every payload in it
+	// instead of Payload. See CanonicalBatch for how the batch is encoded.
+	Batch []json.RawMessage
+
+	// Target, when set, is signed along with the payload so receivers
+	// behind proxies can verify the request was meant for them. See
+	// RequestTarget.Canonical for the exact string that is signed.
+	Target *RequestTarget
+
+	// Query, when not nil, is signed along with the payload, even when it
+	// is empty. See CanonicalQuery for how it is ordered.
+	Query url.Values
+
+	// Epoch, when set, is the rotation epoch of the signing secrets and is
+	// signed along with the payload, so a signature can't be passed off as
+	// coming from another rotation.
+	Epoch *int64
+
 	// The order of these Schemes is a core part of this API.
 	// We use the index as the version number. That is:
 	// Index 0 = v0, Index 1 = v1
 	Schemes []Scheme
 
+	// Version, when set, pins the header to the scheme with that
+	// version number, the other schemes are left out.
+	Version int
+
 	// This flag allows for backward-compatible implementation
 	// of this type. You're either generating a simplistic header
 	// or a complex header.
@@ -62,12 +99,24 @@ func (s *Signature) ComputeHeaderValue() (string, error) {
 		return "", err
 	}
 
+	if s.Epoch != nil {
+		tBuf = withEpoch(*s.Epoch, tBuf)
+	}
+
+	if s.Query != nil {
+		tBuf = withQuery(CanonicalQuery(s.Query), tBuf)
+	}
+
+	if s.Target != nil {
+		tBuf = s.Target.Canonical(tBuf)
+	}
+
 	// Generate Simple Signatures
 	if !s.Advanced {
-		if len(s.Schemes) == 0 {
-			return "", errors.New("signature scheme cannot be empty")
+		sch, ok := s.simpleScheme()
+		if !ok {
+			return "", ErrEmptySignatureScheme
 		}
-		sch := s.Schemes[len(s.Schemes)-1]
 		if len(sch.Secret) == 0 {
 			return "", errors.New("signature secret cannot be empty")
 		}
@@ -102,7 +151,17 @@ func (s *Signature) ComputeHeaderValue() (string, error) {
 	tPrefix := fmt.Sprintf("t=%s", ts)
 	hStr.WriteString(tPrefix)
 
+	var signed bool
 	for k, sch := range s.Schemes {
+		if s.pinned() && k+1 != s.Version {
+			continue
+		}
+
+		if sch.Inactive {
+			continue
+		}
+		signed = true
+
 		v := fmt.Sprintf(",v%d=", k+1)
 
 		var hSig string
@@ -117,9 +176,135 @@ func (s *Signature) ComputeHeaderValue() (string, error) {
 		}
 	}
 
+	if !signed {
+		return "", ErrEmptySignatureScheme
+	}
+
 	return hStr.String(), nil
 }
 
+// simpleScheme returns the scheme a simple signature is generated with, the
+// pinned one or else the latest active one.
+func (s *Signature) simpleScheme() (Scheme, bool) {
+	if s.pinned() {
+		sch := s.Schemes[s.Version-1]
+		return sch, !sch.Inactive
+	}
+
+	for i := len(s.Schemes) - 1; i >= 0; i-- {
+		if !s.Schemes[i].Inactive {
+			return s.Schemes[i], true
+		}
+	}
+
+	return Scheme{}, false
+}
+
+// RequestTarget is the request line and host a signature is bound to.
+type RequestTarget struct {
+	Method string `json:"method"`
+	Host   string `json:"host"`
+	Path   string `json:"path"`
+}
+
+// NewRequestTarget builds the target of a request with the given method to rawURL.
+// The method is upper-cased, the host (with its port, if any) is lower-cased and
+// an empty path becomes "/". The query string is not part of the target, it is
+// signed through Signature.Query.
+func NewRequestTarget(method, rawURL string) (*RequestTarget, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("url %q has no host", rawURL)
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	return &RequestTarget{
+		Method: strings.ToUpper(method),
+		Host:   strings.ToLower(u.Host),
+		Path:   path,
+	}, nil
+}
+
+// Canonical joins the target and body with newlines, i.e.
+// METHOD\nHOST\nPATH\nBODY, e.g. "POST\nexample.com:8080\n/hooks\n{...}".
+// For advanced signatures this string takes the body's place after
+// the timestamp: "<timestamp>,METHOD\nHOST\nPATH\nBODY". When the query
+// is signed as well it goes between the path and the body.
+func (t *RequestTarget) Canonical(body []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(t.Method) + len(t.Host) + len(t.Path) + len(body) + 3)
+
+	buf.WriteString(t.Method)
+	buf.WriteByte('\n')
+	buf.WriteString(t.Host)
+	buf.WriteByte('\n')
+	buf.WriteString(t.Path)
+	buf.WriteByte('\n')
+	buf.Write(body)
+
+	return buf.Bytes()
+}
+
+// CanonicalQuery encodes query sorted by key, and the values of a key
+// sorted too, so the same params always encode the same way whatever
+// order they're sent in, e.g. "a=1&a=2&b=x+y".
+func CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+
+		for _, v := range values {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(url.QueryEscape(k))
+			buf.WriteByte('=')
+			buf.WriteString(url.QueryEscape(v))
+		}
+	}
+
+	return buf.String()
+}
+
+// withQuery puts the canonical query on the line before the body, i.e.
+// QUERY\nBODY.
+func withQuery(query string, body []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(query) + len(body) + 1)
+
+	buf.WriteString(query)
+	buf.WriteByte('\n')
+	buf.Write(body)
+
+	return buf.Bytes()
+}
+
+// withEpoch puts the secret's rotation epoch on the line before the body,
+// i.e. EPOCH\nBODY. When the query is signed as well it goes before the
+// epoch: QUERY\nEPOCH\nBODY.
+func withEpoch(epoch int64, body []byte) []byte {
+	return withQuery(strconv.FormatInt(epoch, 10), body)
+}
+
+func (s *Signature) pinned() bool {
+	return s.Version > 0 && s.Version <= len(s.Schemes)
+}
+
 func (s *Signature) generateSignature(sch Scheme, sec string, buf []byte) (string, error) {
 	var sig string
 	var err error
@@ -181,13 +366,25 @@ func (s *Signature) getHashFunction(algo string) (func() hash.Hash, error) {
 }
 
 func (s *Signature) encodePayload() ([]byte, error) {
+	if len(s.Batch) > 0 {
+		return CanonicalBatch(s.Batch)
+	}
+
+	if s.Encoded {
+		return s.Payload, nil
+	}
+
+	return encodeJSON(s.Payload)
+}
+
+func encodeJSON(payload json.RawMessage) ([]byte, error) {
 	buf := bytes.NewBuffer([]byte{})
 	encoder := json.NewEncoder(buf)
 	encoder.SetEscapeHTML(false)
 
-	err := encoder.Encode(s.Payload)
+	err := encoder.Encode(payload)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrFailedToEncodePayload, err)
 	}
 
 	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
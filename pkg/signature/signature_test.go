@@ -1,7 +1,11 @@
 package signature
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -84,6 +88,57 @@ func Test_Advanced_Signatures(t *testing.T) {
 				"v2=fKtYNaWP+THgLxwIifnkzGLBeCf8iWdGmFtKr0DM93+KCU1vksP8DmT8EbTwlF5Q0F2FmjOXxSxcUBkyuMlmVQ==",
 			}),
 		},
+		"should_only_generate_the_pinned_version_signature": {
+			signature: &Signature{
+				Payload: json.RawMessage(`{"b": {}, "e": "123", "a": 1}`),
+				Schemes: []Scheme{
+					{
+						Secret:   []string{"secret"},
+						Hash:     "SHA256",
+						Encoding: "hex",
+					},
+					{
+						Secret:   []string{"secret"},
+						Hash:     "SHA512",
+						Encoding: "base64",
+					},
+				},
+				Version: 2,
+				generateTimestampFn: func() string {
+					return "1257894000"
+				},
+				Advanced: true,
+			},
+			assertion: func(t require.TestingT, s interface{}, _ ...interface{}) {
+				require.Equal(t, "t=1257894000,v2=fKtYNaWP+THgLxwIifnkzGLBeCf8iWdGmFtKr0DM93+KCU1vksP8DmT8EbTwlF5Q0F2FmjOXxSxcUBkyuMlmVQ==", s)
+			},
+		},
+		"should_generate_all_signatures_for_an_unknown_pinned_version": {
+			signature: &Signature{
+				Payload: json.RawMessage(`{"b": {}, "e": "123", "a": 1}`),
+				Schemes: []Scheme{
+					{
+						Secret:   []string{"secret"},
+						Hash:     "SHA256",
+						Encoding: "hex",
+					},
+					{
+						Secret:   []string{"secret"},
+						Hash:     "SHA512",
+						Encoding: "base64",
+					},
+				},
+				Version: 3,
+				generateTimestampFn: func() string {
+					return "1257894000"
+				},
+				Advanced: true,
+			},
+			assertion: assertSignatureVersionMapping([]string{
+				"v1=c97c302e4a991a7d4a72b60c3f9a0c3adb3611cd0acc632994a72ace04d6509c",
+				"v2=fKtYNaWP+THgLxwIifnkzGLBeCf8iWdGmFtKr0DM93+KCU1vksP8DmT8EbTwlF5Q0F2FmjOXxSxcUBkyuMlmVQ==",
+			}),
+		},
 		"should_include_timestamp_in_computed_value": {
 			signature: &Signature{
 				Payload: json.RawMessage(`{"b": {}, "e": "123", "a": 1}`),
@@ -171,6 +226,28 @@ func Test_Simple_Signatures(t *testing.T) {
 			assertion: require.Equal,
 			expected:  "xdz+2j9aMVQUUjSy0KUz/CsjD4jaD6wHJGGf1c3eZzrWxHTf1cAjZ3aL07O9NZXMhg5gajfi+TYuBU1aoU18xA==",
 		},
+		"should_generate_simple_signature_with_the_pinned_scheme": {
+			signature: &Signature{
+				Payload: json.RawMessage(`{"b": {}, "e": "123", "a": 1}`),
+				Schemes: []Scheme{
+					{
+						Secret:   []string{"secret"},
+						Hash:     "SHA512",
+						Encoding: "hex",
+					},
+					{
+						Secret:   []string{"secret"},
+						Hash:     "SHA512",
+						Encoding: "base64",
+					},
+				},
+				Version:  1,
+				Advanced: false,
+			},
+			assertion: require.Equal,
+			expected: "c5dcfeda3f5a3154145234b2d0a533fc2b230f88da0fac0724619fd5" +
+				"cdde673ad6c474dfd5c02367768bd3b3bd3595cc860e606a37e2f9362e054d5aa14d7cc4",
+		},
 	}
 
 	for name, tc := range tests {
@@ -188,6 +265,227 @@ func Test_Simple_Signatures(t *testing.T) {
 }
 
 func Test_ComputeHeaderValue_Errors(t *testing.T) {
+	sig := &Signature{
+		Payload: json.RawMessage(`{"event": "invoice.completed"`),
+		Schemes: []Scheme{
+			{
+				Secret:   []string{"Paged-Rotten-Dyslexia-Wiring"},
+				Hash:     "SHA256",
+				Encoding: "hex",
+			},
+		},
+	}
+
+	_, err := sig.ComputeHeaderValue()
+	require.ErrorIs(t, err, ErrFailedToEncodePayload)
+}
+
+func TestNewRequestTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		want    *RequestTarget
+		wantErr bool
+	}{
+		{
+			name:   "should_normalise_method_and_host",
+			method: "post",
+			url:    "https://Example.COM:8443/hooks/convoy?ref=1",
+			want:   &RequestTarget{Method: "POST", Host: "example.com:8443", Path: "/hooks/convoy"},
+		},
+		{
+			name:   "should_default_an_empty_path",
+			method: "PUT",
+			url:    "https://example.com",
+			want:   &RequestTarget{Method: "PUT", Host: "example.com", Path: "/"},
+		},
+		{
+			name:   "should_keep_the_path_escaped",
+			method: "POST",
+			url:    "https://example.com/a%20b/c",
+			want:   &RequestTarget{Method: "POST", Host: "example.com", Path: "/a%20b/c"},
+		},
+		{
+			name:    "should_fail_without_a_host",
+			method:  "POST",
+			url:     "/hooks",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRequestTarget(tt.method, tt.url)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_RequestTarget_Signatures(t *testing.T) {
+	payload := json.RawMessage(`{"e":"123"}`)
+	target := &RequestTarget{Method: "POST", Host: "example.com", Path: "/hooks"}
+
+	require.Equal(t, "POST\nexample.com\n/hooks\n{\"e\":\"123\"}", string(target.Canonical(payload)))
+
+	sign := func(msg string) string {
+		h := hmac.New(sha256.New, []byte("secret"))
+		h.Write([]byte(msg))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	newSig := func(target *RequestTarget, advanced bool) *Signature {
+		return &Signature{
+			Payload:             payload,
+			Target:              target,
+			Schemes:             []Scheme{{Secret: []string{"secret"}, Hash: "SHA256", Encoding: "hex"}},
+			Advanced:            advanced,
+			generateTimestampFn: func() string { return "1257894000" },
+		}
+	}
+
+	simple, err := newSig(target, false).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign("POST\nexample.com\n/hooks\n{\"e\":\"123\"}"), simple)
+
+	advanced, err := newSig(target, true).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, "t=1257894000,v1="+sign("1257894000,POST\nexample.com\n/hooks\n{\"e\":\"123\"}"), advanced)
+
+	// the signature no longer verifies once the request is sent to another host
+	moved := *target
+	moved.Host = "attacker.example.com"
+	other, err := newSig(&moved, false).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.NotEqual(t, simple, other)
+
+	// and without a target only the body is signed
+	bodyOnly, err := newSig(nil, false).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign(`{"e":"123"}`), bodyOnly)
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+		want  string
+	}{
+		{name: "empty", query: url.Values{}, want: ""},
+		{name: "sorted by key", query: url.Values{"b": {"2"}, "a": {"1"}}, want: "a=1&b=2"},
+		{name: "values of a key are sorted", query: url.Values{"a": {"z", "y"}}, want: "a=y&a=z"},
+		{name: "keys and values are escaped", query: url.Values{"a b": {"c&d"}}, want: "a+b=c%26d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CanonicalQuery(tt.query))
+		})
+	}
+}
+
+func Test_Query_Signatures(t *testing.T) {
+	payload := json.RawMessage(`{"e":"123"}`)
+	query := url.Values{"tenant": {"acme"}, "ref": {"2", "1"}}
+
+	sign := func(msg string) string {
+		h := hmac.New(sha256.New, []byte("secret"))
+		h.Write([]byte(msg))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	newSig := func(target *RequestTarget, query url.Values) *Signature {
+		return &Signature{
+			Payload: payload,
+			Target:  target,
+			Query:   query,
+			Schemes: []Scheme{{Secret: []string{"secret"}, Hash: "SHA256", Encoding: "hex"}},
+		}
+	}
+
+	signed, err := newSig(nil, query).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign("ref=1&ref=2&tenant=acme\n{\"e\":\"123\"}"), signed)
+
+	// the query goes between the target and the body
+	target := &RequestTarget{Method: "POST", Host: "example.com", Path: "/hooks"}
+	withTarget, err := newSig(target, query).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign("POST\nexample.com\n/hooks\nref=1&ref=2&tenant=acme\n{\"e\":\"123\"}"), withTarget)
+
+	// changing a param invalidates the signature
+	changed, err := newSig(nil, url.Values{"tenant": {"other"}, "ref": {"2", "1"}}).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.NotEqual(t, signed, changed)
+
+	// an empty query is signed as an empty line
+	empty, err := newSig(nil, url.Values{}).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign("\n{\"e\":\"123\"}"), empty)
+}
+
+func Test_Epoch_Signatures(t *testing.T) {
+	payload := json.RawMessage(`{"e":"123"}`)
+
+	sign := func(msg string) string {
+		h := hmac.New(sha256.New, []byte("secret"))
+		h.Write([]byte(msg))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	newSig := func(epoch *int64, query url.Values) *Signature {
+		return &Signature{
+			Payload: payload,
+			Epoch:   epoch,
+			Query:   query,
+			Schemes: []Scheme{{Secret: []string{"secret"}, Hash: "SHA256", Encoding: "hex"}},
+		}
+	}
+
+	epoch := int64(3)
+	signed, err := newSig(&epoch, nil).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign("3\n{\"e\":\"123\"}"), signed)
+
+	// the query goes before the epoch
+	withQuery, err := newSig(&epoch, url.Values{"ref": {"1"}}).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign("ref=1\n3\n{\"e\":\"123\"}"), withQuery)
+
+	// a signature from another rotation doesn't verify
+	next := epoch + 1
+	rotated, err := newSig(&next, nil).ComputeHeaderValue()
+	require.NoError(t, err)
+	require.NotEqual(t, signed, rotated)
+}
+
+func Test_Encoded_Signatures(t *testing.T) {
+	sign := func(msg string) string {
+		h := hmac.New(sha256.New, []byte("secret"))
+		h.Write([]byte(msg))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	s := &Signature{
+		Payload: json.RawMessage("event=invoice.completed&amount=100"),
+		Encoded: true,
+		Schemes: []Scheme{{Secret: []string{"secret"}, Hash: "SHA256", Encoding: "hex"}},
+	}
+
+	// a form encoded body is signed as sent, it isn't valid json
+	signed, err := s.ComputeHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, sign("event=invoice.completed&amount=100"), signed)
+
+	s.Encoded = false
+	_, err = s.ComputeHeaderValue()
+	require.ErrorIs(t, err, ErrFailedToEncodePayload)
 }
 
 func assertSignatureIncludesTimestamp(t require.TestingT, v interface{}, args ...interface{}) {
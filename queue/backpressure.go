@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+var ErrQueueSaturated = errors.New("the event queue is saturated, retry later")
+
+// DepthFunc returns the number of tasks waiting in a queue.
+type DepthFunc func() (int, error)
+
+// Backpressure rejects new work while a queue is above its high-water mark,
+// and lets it through again once the queue drains below its low-water mark.
+type Backpressure struct {
+	depth         DepthFunc
+	highWaterMark int
+	lowWaterMark  int
+	interval      time.Duration
+
+	mu        sync.Mutex
+	saturated bool
+	checkedAt time.Time
+}
+
+// NewBackpressure samples the queue depth at most once per interval.
+func NewBackpressure(depth DepthFunc, highWaterMark, lowWaterMark int, interval time.Duration) *Backpressure {
+	return &Backpressure{
+		depth:         depth,
+		highWaterMark: highWaterMark,
+		lowWaterMark:  lowWaterMark,
+		interval:      interval,
+	}
+}
+
+// Check returns ErrQueueSaturated while the queue is saturated. The caller
+// that samples the depth does so without holding the lock, the others go on
+// with the last known state in the meantime.
+func (b *Backpressure) Check() error {
+	b.mu.Lock()
+	due := time.Since(b.checkedAt) >= b.interval
+	if due {
+		b.checkedAt = time.Now()
+	}
+	b.mu.Unlock()
+
+	if due {
+		b.sample()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.saturated {
+		return ErrQueueSaturated
+	}
+
+	return nil
+}
+
+// RetryAfter is how long producers should wait before trying again.
+func (b *Backpressure) RetryAfter() time.Duration {
+	return b.interval
+}
+
+func (b *Backpressure) sample() {
+	depth, err := b.depth()
+	if err != nil {
+		// don't block ingestion because the queue couldn't be inspected
+		log.WithError(err).Error("backpressure: failed to fetch queue depth")
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case depth > b.highWaterMark:
+		b.saturated = true
+	case depth < b.lowWaterMark:
+		b.saturated = false
+	}
+}
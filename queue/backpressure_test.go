@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackpressure_Check(t *testing.T) {
+	depth := 0
+	bp := NewBackpressure(func() (int, error) { return depth, nil }, 100, 50, 0)
+
+	// below the high-water mark
+	depth = 100
+	require.NoError(t, bp.Check())
+
+	// above the high-water mark
+	depth = 101
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+
+	// still throttled until the queue drains below the low-water mark
+	depth = 75
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+
+	depth = 50
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+
+	depth = 49
+	require.NoError(t, bp.Check())
+}
+
+func TestBackpressure_CheckCachesDepth(t *testing.T) {
+	calls := 0
+	bp := NewBackpressure(func() (int, error) {
+		calls++
+		return 200, nil
+	}, 100, 100, time.Hour)
+
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+	require.Equal(t, 1, calls)
+}
+
+func TestBackpressure_CheckDepthError(t *testing.T) {
+	var err error
+	depth := 200
+	bp := NewBackpressure(func() (int, error) { return depth, err }, 100, 100, 0)
+
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+
+	// keeps the last known state when the queue can't be inspected
+	err = errors.New("connection refused")
+	depth = 0
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+
+	err = nil
+	require.NoError(t, bp.Check())
+}
+
+func TestBackpressure_CheckDoesNotWaitOnSample(t *testing.T) {
+	sampling, release := make(chan struct{}), make(chan struct{})
+	bp := NewBackpressure(func() (int, error) {
+		close(sampling)
+		<-release
+		return 200, nil
+	}, 100, 100, time.Hour)
+
+	done := make(chan error)
+	go func() { done <- bp.Check() }()
+	<-sampling
+
+	// other callers go on with the last known state while the depth is read
+	require.NoError(t, bp.Check())
+
+	close(release)
+	require.ErrorIs(t, <-done, ErrQueueSaturated)
+	require.ErrorIs(t, bp.Check(), ErrQueueSaturated)
+}
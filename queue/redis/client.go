@@ -93,6 +93,27 @@ func (q *RedisQueue) Inspector() *asynq.Inspector {
 	return q.inspector
 }
 
+// Depth returns the number of tasks in the queue that haven't been
+// completed or archived yet.
+func (q *RedisQueue) Depth(queueName convoy.QueueName) (int, error) {
+	info, err := q.inspector.GetQueueInfo(string(queueName))
+	if err != nil {
+		if ErrQueueNotFound.Error() == err.Error() {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return queueDepth(info), nil
+}
+
+// queueDepth is the queue's size less its archived tasks, asynq's size
+// already leaves out completed tasks.
+func queueDepth(info *asynq.QueueInfo) int {
+	return info.Size - info.Archived
+}
+
 func (q *RedisQueue) DeleteEventDeliveriesFromQueue(queueName convoy.QueueName, ids []string) error {
 	for _, id := range ids {
 		taskInfo, err := q.inspector.GetTaskInfo(string(queueName), id)
@@ -0,0 +1,23 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueDepth(t *testing.T) {
+	info := &asynq.QueueInfo{
+		Pending:   3,
+		Active:    2,
+		Scheduled: 1,
+		Retry:     1,
+		Archived:  4,
+		Completed: 20,
+	}
+	info.Size = info.Pending + info.Active + info.Scheduled + info.Retry + info.Aggregating + info.Archived
+
+	// completed tasks aren't part of the size, so they aren't taken off it
+	require.Equal(t, 7, queueDepth(info))
+}
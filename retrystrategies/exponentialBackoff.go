@@ -6,20 +6,18 @@ import (
 	"time"
 )
 
+const defaultBackoffBase = 2
+
 type JitterFn func(uint) int
 
 type ExponentialBackoffRetryStrategy struct {
 	intervalSeconds uint64
+	base            float64
 	maxRetrySeconds uint64
 }
 
 func (r *ExponentialBackoffRetryStrategy) NextDuration(attempts uint64) time.Duration {
-	retrySeconds := float64(r.intervalSeconds) * math.Pow(2, float64(attempts))
-	if uint64(retrySeconds) > r.maxRetrySeconds {
-		retrySeconds = float64(r.maxRetrySeconds)
-	}
-
-	d := time.Duration(retrySeconds) * time.Second
+	d := r.interval(attempts)
 
 	jitter := time.Duration(rand.Uint64() % 10e9)
 
@@ -28,13 +26,34 @@ func (r *ExponentialBackoffRetryStrategy) NextDuration(attempts uint64) time.Dur
 	return d
 }
 
+// interval is intervalSeconds * base^attempts, clamped to maxRetrySeconds
+func (r *ExponentialBackoffRetryStrategy) interval(attempts uint64) time.Duration {
+	retrySeconds := float64(r.intervalSeconds) * math.Pow(r.base, float64(attempts))
+	if retrySeconds > float64(r.maxRetrySeconds) {
+		retrySeconds = float64(r.maxRetrySeconds)
+	}
+
+	return time.Duration(retrySeconds) * time.Second
+}
+
 func NewExponential(intervalSeconds uint64, maxRetrySeconds uint64) *ExponentialBackoffRetryStrategy {
+	return NewExponentialWithBase(intervalSeconds, defaultBackoffBase, maxRetrySeconds)
+}
+
+// NewExponentialWithBase is NewExponential with the interval multiplied by
+// base instead of 2 after every attempt. A base of 1 or less falls back to 2.
+func NewExponentialWithBase(intervalSeconds uint64, base float64, maxRetrySeconds uint64) *ExponentialBackoffRetryStrategy {
 	if maxRetrySeconds == 0 {
 		maxRetrySeconds = 7200
 	}
 
+	if base <= 1 {
+		base = defaultBackoffBase
+	}
+
 	return &ExponentialBackoffRetryStrategy{
 		intervalSeconds: intervalSeconds,
+		base:            base,
 		maxRetrySeconds: maxRetrySeconds,
 	}
 }
@@ -45,3 +45,60 @@ func TestExponentialBackoffRetryStrategy_NextDuration_MaxFourHours(t *testing.T)
 		assert.True(t, d < 5*time.Hour)
 	}
 }
+
+func TestExponentialBackoffRetryStrategy_Interval(t *testing.T) {
+	tests := []struct {
+		name            string
+		intervalSeconds uint64
+		base            float64
+		maxRetrySeconds uint64
+		want            []time.Duration
+	}{
+		{
+			name:            "base-defaults-to-two",
+			intervalSeconds: 5,
+			maxRetrySeconds: 7200,
+			want:            []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second},
+		},
+		{
+			name:            "custom-base",
+			intervalSeconds: 2,
+			base:            3,
+			maxRetrySeconds: 7200,
+			want:            []time.Duration{2 * time.Second, 6 * time.Second, 18 * time.Second, 54 * time.Second, 162 * time.Second},
+		},
+		{
+			name:            "fractional-base",
+			intervalSeconds: 100,
+			base:            1.5,
+			maxRetrySeconds: 7200,
+			want:            []time.Duration{100 * time.Second, 150 * time.Second, 225 * time.Second},
+		},
+		{
+			name:            "clamped-to-max-interval",
+			intervalSeconds: 10,
+			base:            4,
+			maxRetrySeconds: 300,
+			want:            []time.Duration{10 * time.Second, 40 * time.Second, 160 * time.Second, 300 * time.Second, 300 * time.Second},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewExponentialWithBase(tc.intervalSeconds, tc.base, tc.maxRetrySeconds)
+
+			for attempts, want := range tc.want {
+				assert.Equal(t, want, r.interval(uint64(attempts)))
+
+				// NextDuration only adds up to 5s of jitter on top
+				d := r.NextDuration(uint64(attempts))
+				assert.GreaterOrEqual(t, d, want)
+				assert.Less(t, d, want+5*time.Second)
+			}
+		})
+	}
+
+	// the max interval holds however many attempts there were
+	r := NewExponentialWithBase(10, 10, 3600)
+	assert.Equal(t, time.Hour, r.interval(1000))
+}
@@ -13,8 +13,18 @@ type RetryStrategy interface {
 
 func NewRetryStrategyFromMetadata(m datastore.Metadata) RetryStrategy {
 	if string(m.Strategy) == string(datastore.ExponentialStrategyProvider) {
-		return NewExponential(m.IntervalSeconds, m.MaxRetrySeconds)
+		return NewExponentialWithBase(m.IntervalSeconds, m.BackoffBase, maxIntervalSeconds(m))
 	}
 
 	return NewDefault(m.IntervalSeconds)
 }
+
+// maxIntervalSeconds is the smaller of the metadata's max interval and max
+// retry seconds, where 0 means either is unset
+func maxIntervalSeconds(m datastore.Metadata) uint64 {
+	if m.MaxIntervalSeconds == 0 || (m.MaxRetrySeconds != 0 && m.MaxRetrySeconds < m.MaxIntervalSeconds) {
+		return m.MaxRetrySeconds
+	}
+
+	return m.MaxIntervalSeconds
+}
@@ -40,3 +40,38 @@ func TestRetry_FallsBackToDefault(t *testing.T) {
 	_, isDefault := r.(*DefaultRetryStrategy)
 	assert.True(t, isDefault)
 }
+
+func TestRetry_ExponentialUsesBackoffBaseAndMaxInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata datastore.Metadata
+		want     *ExponentialBackoffRetryStrategy
+	}{
+		{
+			name:     "defaults",
+			metadata: datastore.Metadata{Strategy: "exponential", IntervalSeconds: 5},
+			want:     &ExponentialBackoffRetryStrategy{intervalSeconds: 5, base: 2, maxRetrySeconds: 7200},
+		},
+		{
+			name:     "max interval",
+			metadata: datastore.Metadata{Strategy: "exponential", IntervalSeconds: 5, BackoffBase: 3, MaxIntervalSeconds: 600},
+			want:     &ExponentialBackoffRetryStrategy{intervalSeconds: 5, base: 3, maxRetrySeconds: 600},
+		},
+		{
+			name:     "max retry seconds below the max interval",
+			metadata: datastore.Metadata{Strategy: "exponential", IntervalSeconds: 5, MaxIntervalSeconds: 600, MaxRetrySeconds: 120},
+			want:     &ExponentialBackoffRetryStrategy{intervalSeconds: 5, base: 2, maxRetrySeconds: 120},
+		},
+		{
+			name:     "max interval below the max retry seconds",
+			metadata: datastore.Metadata{Strategy: "exponential", IntervalSeconds: 5, MaxIntervalSeconds: 60, MaxRetrySeconds: 120},
+			want:     &ExponentialBackoffRetryStrategy{intervalSeconds: 5, base: 2, maxRetrySeconds: 60},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, NewRetryStrategyFromMetadata(tc.metadata))
+		})
+	}
+}
@@ -9,9 +9,10 @@ import (
 )
 
 type BatchReplayEventService struct {
-	EndpointRepo datastore.EndpointRepository
-	Queue        queue.Queuer
-	EventRepo    datastore.EventRepository
+	EndpointRepo      datastore.EndpointRepository
+	EventDeliveryRepo datastore.EventDeliveryRepository
+	Queue             queue.Queuer
+	EventRepo         datastore.EventRepository
 
 	Filter *datastore.Filter
 }
@@ -24,8 +25,9 @@ func (e *BatchReplayEventService) Run(ctx context.Context) (int, int, error) {
 	}
 
 	rs := ReplayEventService{
-		EndpointRepo: e.EndpointRepo,
-		Queue:        e.Queue,
+		EndpointRepo:      e.EndpointRepo,
+		EventDeliveryRepo: e.EventDeliveryRepo,
+		Queue:             e.Queue,
 	}
 
 	failures := 0
@@ -17,10 +17,11 @@ import (
 
 func provideBatchReplayEventService(ctrl *gomock.Controller, f *datastore.Filter) *BatchReplayEventService {
 	return &BatchReplayEventService{
-		EndpointRepo: mocks.NewMockEndpointRepository(ctrl),
-		Queue:        mocks.NewMockQueuer(ctrl),
-		EventRepo:    mocks.NewMockEventRepository(ctrl),
-		Filter:       f,
+		EndpointRepo:      mocks.NewMockEndpointRepository(ctrl),
+		EventDeliveryRepo: mocks.NewMockEventDeliveryRepository(ctrl),
+		Queue:             mocks.NewMockQueuer(ctrl),
+		EventRepo:         mocks.NewMockEventRepository(ctrl),
+		Filter:            f,
 	}
 }
 
@@ -53,6 +54,9 @@ func TestBatchReplayEventService_Run(t *testing.T) {
 					nil,
 				)
 
+				ed, _ := br.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().ReleaseEventDeliveryDeduplicationKeys(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).Return(nil)
+
 				q, _ := br.Queue.(*mocks.MockQueuer)
 				q.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).Times(2).Return(nil)
 			},
@@ -81,6 +85,9 @@ func TestBatchReplayEventService_Run(t *testing.T) {
 					nil,
 				)
 
+				ed, _ := br.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().ReleaseEventDeliveryDeduplicationKeys(gomock.Any(), gomock.Any(), gomock.Any()).Times(3).Return(nil)
+
 				q, _ := br.Queue.(*mocks.MockQueuer)
 				q.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).Times(2).Return(nil)
 				q.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).Times(1).Return(errors.New("failed"))
@@ -19,9 +19,20 @@ type BatchRetryEventDeliveryService struct {
 	Queue             queue.Queuer
 	Filter            *datastore.Filter
 	ProjectID         string
+
+	// MaxAge, when set, narrows the filter to deliveries created within it,
+	// so older ones aren't retried
+	MaxAge time.Duration
 }
 
 func (e *BatchRetryEventDeliveryService) Run(ctx context.Context) error {
+	if e.MaxAge > 0 {
+		oldest := time.Now().Add(-e.MaxAge).Unix()
+		if e.Filter.SearchParams.CreatedAtStart < oldest {
+			e.Filter.SearchParams.CreatedAtStart = oldest
+		}
+	}
+
 	// Check if there's an active batch retry
 	activeRetry, err := e.BatchRetryRepo.FindActiveBatchRetry(ctx, e.ProjectID)
 	if err != nil && !errors.Is(err, datastore.ErrBatchRetryNotFound) {
@@ -34,7 +45,7 @@ func (e *BatchRetryEventDeliveryService) Run(ctx context.Context) error {
 	}
 
 	// Count total events
-	count, err := e.EventDeliveryRepo.CountEventDeliveries(ctx, e.ProjectID, e.Filter.EndpointIDs, e.Filter.EventID, e.Filter.Status, e.Filter.SearchParams)
+	count, err := e.EventDeliveryRepo.CountEventDeliveries(ctx, e.ProjectID, e.Filter.EndpointIDs, nil, e.Filter.EventID, e.Filter.Status, e.Filter.SearchParams)
 	if err != nil {
 		log.FromContext(ctx).WithError(err).Error("failed to count events")
 		return &ServiceError{ErrMsg: "failed to count events", Err: err}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/mocks"
@@ -69,7 +70,7 @@ func TestBatchRetryEventDeliveryService_Run(t *testing.T) {
 
 				br.EXPECT().CreateBatchRetry(gomock.Any(), gomock.Any())
 
-				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(int64(10), nil)
 
 				q, _ := es.Queue.(*mocks.MockQueuer)
@@ -104,7 +105,7 @@ func TestBatchRetryEventDeliveryService_Run(t *testing.T) {
 				br.EXPECT().FindActiveBatchRetry(gomock.Any(), "123").
 					Return(nil, datastore.ErrBatchRetryNotFound).Times(1)
 
-				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(int64(10), nil)
 
 				br.EXPECT().CreateBatchRetry(gomock.Any(), gomock.Any())
@@ -157,7 +158,7 @@ func TestBatchRetryEventDeliveryService_Run(t *testing.T) {
 				br.EXPECT().FindActiveBatchRetry(gomock.Any(), "123").
 					Return(nil, datastore.ErrBatchRetryNotFound).Times(1)
 
-				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(int64(0), errors.New("failed to count events"))
 			},
 		},
@@ -180,7 +181,7 @@ func TestBatchRetryEventDeliveryService_Run(t *testing.T) {
 				br.EXPECT().FindActiveBatchRetry(gomock.Any(), "123").
 					Return(nil, datastore.ErrBatchRetryNotFound).Times(1)
 
-				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(int64(10), nil)
 
 				br.EXPECT().CreateBatchRetry(gomock.Any(), gomock.Any()).
@@ -207,7 +208,7 @@ func TestBatchRetryEventDeliveryService_Run(t *testing.T) {
 				br.EXPECT().FindActiveBatchRetry(gomock.Any(), "123").
 					Return(nil, datastore.ErrBatchRetryNotFound).Times(1)
 
-				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(int64(10), nil)
 
 				br.EXPECT().CreateBatchRetry(gomock.Any(), gomock.Any())
@@ -252,7 +253,7 @@ func TestBatchRetryEventDeliveryService_Run(t *testing.T) {
 				br.EXPECT().FindActiveBatchRetry(gomock.Any(), "123").
 					Return(nil, datastore.ErrBatchRetryNotFound).Times(1)
 
-				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				ed.EXPECT().CountEventDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(int64(0), nil)
 
 				br.EXPECT().CreateBatchRetry(gomock.Any(), gomock.Any())
@@ -286,3 +287,41 @@ func TestBatchRetryEventDeliveryService_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchRetryEventDeliveryService_RunWithMaxAge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stale := time.Now().Add(-30 * 24 * time.Hour).Unix()
+	recent := time.Now().Add(-time.Hour).Unix()
+
+	es := provideBatchRetryEventDeliveryService(ctrl, &datastore.Filter{
+		EndpointIDs:  []string{"abc"},
+		SearchParams: datastore.SearchParams{CreatedAtStart: stale, CreatedAtEnd: recent},
+	})
+	es.MaxAge = 72 * time.Hour
+
+	ed, _ := es.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+	br, _ := es.BatchRetryRepo.(*mocks.MockBatchRetryRepository)
+	q, _ := es.Queue.(*mocks.MockQueuer)
+
+	br.EXPECT().FindActiveBatchRetry(gomock.Any(), "123").Return(nil, datastore.ErrBatchRetryNotFound)
+
+	// deliveries older than the max age are left out of the count and the batch
+	ed.EXPECT().CountEventDeliveries(gomock.Any(), "123", []string{"abc"}, gomock.Any(), "", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, _ []string, _ string, _ []datastore.EventDeliveryStatus, params datastore.SearchParams) (int64, error) {
+			require.GreaterOrEqual(t, params.CreatedAtStart, time.Now().Add(-72*time.Hour).Unix())
+			require.Equal(t, recent, params.CreatedAtEnd)
+			return 3, nil
+		})
+
+	br.EXPECT().CreateBatchRetry(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, batchRetry *datastore.BatchRetry) error {
+			require.GreaterOrEqual(t, batchRetry.Filter.SearchParams.CreatedAtStart, time.Now().Add(-72*time.Hour).Unix())
+			return nil
+		})
+
+	q.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+
+	require.NoError(t, es.Run(context.Background()))
+}
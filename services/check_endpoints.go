@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+const checkEndpointsPageSize = 100
+
+// EndpointReachability is the outcome of pinging one endpoint.
+type EndpointReachability struct {
+	EndpointID string        `json:"endpoint_id"`
+	Name       string        `json:"name"`
+	URL        string        `json:"url"`
+	Reachable  bool          `json:"reachable"`
+	StatusCode int           `json:"status_code"`
+	Latency    time.Duration `json:"latency"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// CheckEndpointsService pings every active endpoint in a project through the
+// dispatcher, so its allow and block lists apply, with at most Concurrency
+// pings in flight, and reports which endpoints are reachable.
+type CheckEndpointsService struct {
+	EndpointRepo datastore.EndpointRepository
+	Dispatcher   *net.Dispatcher
+	ProjectID    string
+	Concurrency  int
+	Timeout      time.Duration
+}
+
+func (c *CheckEndpointsService) Run(ctx context.Context) ([]EndpointReachability, error) {
+	if c.Concurrency <= 0 {
+		return nil, &ServiceError{ErrMsg: "concurrency must be greater than zero"}
+	}
+
+	endpoints, err := c.activeEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]EndpointReachability, len(endpoints))
+	sem := make(chan struct{}, c.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			report[i] = c.check(ctx, &endpoints[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return report, nil
+}
+
+func (c *CheckEndpointsService) activeEndpoints(ctx context.Context) ([]datastore.Endpoint, error) {
+	var endpoints []datastore.Endpoint
+
+	pageable := datastore.Pageable{PerPage: checkEndpointsPageSize, Direction: datastore.Next}
+	pageable.SetCursors()
+
+	for {
+		page, paginationData, err := c.EndpointRepo.LoadEndpointsPaged(ctx, c.ProjectID, &datastore.Filter{}, pageable)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("failed to load endpoints")
+			return nil, &ServiceError{ErrMsg: "failed to load endpoints", Err: err}
+		}
+
+		for i := range page {
+			if page[i].Status == datastore.ActiveEndpointStatus {
+				endpoints = append(endpoints, page[i])
+			}
+		}
+
+		if !paginationData.HasNextPage {
+			return endpoints, nil
+		}
+		pageable.NextCursor = paginationData.NextPageCursor
+	}
+}
+
+func (c *CheckEndpointsService) check(ctx context.Context, endpoint *datastore.Endpoint) EndpointReachability {
+	start := time.Now()
+	statusCode, err := c.Dispatcher.PingStatus(ctx, endpoint.Url, c.Timeout)
+
+	r := EndpointReachability{
+		EndpointID: endpoint.UID,
+		Name:       endpoint.Name,
+		URL:        endpoint.Url,
+		Reachable:  err == nil,
+		StatusCode: statusCode,
+		Latency:    time.Since(start),
+	}
+
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	return r
+}
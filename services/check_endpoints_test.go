@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/internal/pkg/fflag"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func TestCheckEndpointsService_Run(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	handler := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(status)
+		}
+	}
+
+	healthy := httptest.NewServer(handler(http.StatusOK))
+	defer healthy.Close()
+
+	failing := httptest.NewServer(handler(http.StatusInternalServerError))
+	defer failing.Close()
+
+	down := httptest.NewServer(handler(http.StatusOK))
+	down.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+
+	firstPage := []datastore.Endpoint{
+		{UID: "endpoint-1", Name: "healthy", Url: healthy.URL, Status: datastore.ActiveEndpointStatus},
+		{UID: "endpoint-2", Name: "failing", Url: failing.URL, Status: datastore.ActiveEndpointStatus},
+		{UID: "endpoint-3", Name: "disabled", Url: healthy.URL, Status: datastore.InactiveEndpointStatus},
+	}
+	secondPage := []datastore.Endpoint{
+		{UID: "endpoint-4", Name: "down", Url: down.URL, Status: datastore.ActiveEndpointStatus},
+		{UID: "endpoint-5", Name: "healthy-2", Url: healthy.URL, Status: datastore.ActiveEndpointStatus},
+	}
+
+	gomock.InOrder(
+		endpointRepo.EXPECT().LoadEndpointsPaged(gomock.Any(), "project-1", gomock.Any(), gomock.Any()).
+			Return(firstPage, datastore.PaginationData{HasNextPage: true, NextPageCursor: "endpoint-3"}, nil),
+		endpointRepo.EXPECT().LoadEndpointsPaged(gomock.Any(), "project-1", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ *datastore.Filter, pageable datastore.Pageable) ([]datastore.Endpoint, datastore.PaginationData, error) {
+				require.Equal(t, "endpoint-3", pageable.NextCursor)
+				return secondPage, datastore.PaginationData{}, nil
+			}),
+	)
+
+	dispatcher, err := net.NewDispatcher(licenser, fflag.NewFFlag([]string{}), net.LoggerOption(log.NewLogger(os.Stdout)))
+	require.NoError(t, err)
+
+	ce := CheckEndpointsService{
+		EndpointRepo: endpointRepo,
+		Dispatcher:   dispatcher,
+		ProjectID:    "project-1",
+		Concurrency:  2,
+		Timeout:      5 * time.Second,
+	}
+
+	report, err := ce.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report, 4)
+
+	want := []struct {
+		id         string
+		reachable  bool
+		statusCode int
+	}{
+		{id: "endpoint-1", reachable: true, statusCode: http.StatusOK},
+		{id: "endpoint-2", reachable: false, statusCode: http.StatusInternalServerError},
+		{id: "endpoint-4", reachable: false, statusCode: 0},
+		{id: "endpoint-5", reachable: true, statusCode: http.StatusOK},
+	}
+
+	for i, w := range want {
+		require.Equal(t, w.id, report[i].EndpointID)
+		require.Equal(t, w.reachable, report[i].Reachable, w.id)
+		require.Equal(t, w.statusCode, report[i].StatusCode, w.id)
+		require.Equal(t, w.reachable, report[i].Error == "", w.id)
+		require.Positive(t, report[i].Latency, w.id)
+	}
+
+	require.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestCheckEndpointsService_InvalidConcurrency(t *testing.T) {
+	ce := CheckEndpointsService{ProjectID: "project-1"}
+
+	_, err := ce.Run(context.Background())
+	require.Error(t, err)
+}
@@ -47,6 +47,10 @@ func (a *CreateEndpointService) Run(ctx context.Context) (*datastore.Endpoint, e
 
 	a.E.URL = endpointUrl
 
+	if err = validatePinnedSignatureVersion(project, a.E.PinnedSignatureVersion); err != nil {
+		return nil, &ServiceError{ErrMsg: err.Error()}
+	}
+
 	truthValue := true
 	switch project.Type {
 	case datastore.IncomingProject:
@@ -60,22 +64,31 @@ func (a *CreateEndpointService) Run(ctx context.Context) (*datastore.Endpoint, e
 	}
 
 	endpoint := &datastore.Endpoint{
-		UID:                ulid.Make().String(),
-		ProjectID:          a.ProjectID,
-		OwnerID:            a.E.OwnerID,
-		Name:               a.E.Name,
-		SupportEmail:       a.E.SupportEmail,
-		SlackWebhookURL:    a.E.SlackWebhookURL,
-		Url:                a.E.URL,
-		Description:        a.E.Description,
-		RateLimit:          a.E.RateLimit,
-		HttpTimeout:        a.E.HttpTimeout,
-		AdvancedSignatures: *a.E.AdvancedSignatures,
-		AppID:              a.E.AppID,
-		RateLimitDuration:  a.E.RateLimitDuration,
-		Status:             datastore.ActiveEndpointStatus,
-		CreatedAt:          time.Now(),
-		UpdatedAt:          time.Now(),
+		UID:                      ulid.Make().String(),
+		ProjectID:                a.ProjectID,
+		OwnerID:                  a.E.OwnerID,
+		Name:                     a.E.Name,
+		SupportEmail:             a.E.SupportEmail,
+		SlackWebhookURL:          a.E.SlackWebhookURL,
+		Url:                      a.E.URL,
+		Description:              a.E.Description,
+		RateLimit:                a.E.RateLimit,
+		HttpTimeout:              a.E.HttpTimeout,
+		AdvancedSignatures:       *a.E.AdvancedSignatures,
+		BodyFormat:               a.E.BodyFormat,
+		PinnedSignatureVersion:   a.E.PinnedSignatureVersion,
+		HttpMethod:               a.E.HttpMethod,
+		ContentType:              a.E.ContentType,
+		FormEncoding:             a.E.FormEncoding,
+		MaxConcurrentDeliveries:  a.E.MaxConcurrentDeliveries,
+		MinDeliveryIntervalMs:    a.E.MinDeliveryIntervalMs,
+		RetryAfterFloorSeconds:   a.E.RetryAfterFloorSeconds,
+		RetryAfterCeilingSeconds: a.E.RetryAfterCeilingSeconds,
+		AppID:                    a.E.AppID,
+		RateLimitDuration:        a.E.RateLimitDuration,
+		Status:                   datastore.ActiveEndpointStatus,
+		CreatedAt:                time.Now(),
+		UpdatedAt:                time.Now(),
 	}
 
 	if !a.Licenser.AdvancedEndpointMgmt() {
@@ -165,6 +178,9 @@ func (a *CreateEndpointService) ValidateEndpoint(ctx context.Context, enforceSec
 			net.AllowListOption(cfg.Dispatcher.AllowList),
 			net.BlockListOption(cfg.Dispatcher.BlockList),
 			net.TLSConfigOption(cfg.Dispatcher.InsecureSkipVerify, a.Licenser, caCertTLSCfg),
+			net.ForceHTTP2Option(cfg.Dispatcher.ForceHTTP2),
+			net.ConnectionPoolOption(cfg.Dispatcher.MaxIdleConnsPerHost, cfg.Dispatcher.GetIdleConnTimeout()),
+			net.TransientRetryOption(cfg.Dispatcher.TransientRetries),
 		)
 		if innerErr != nil {
 			return "", innerErr
@@ -191,8 +207,31 @@ func ValidateEndpointAuthentication(auth *datastore.EndpointAuthentication) (*da
 			return nil, util.NewServiceError(http.StatusBadRequest, errors.New("api key field is required"))
 		}
 
+		if auth.Type == datastore.DigestAuthentication && auth.DigestAuth == nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, errors.New("digest auth field is required"))
+		}
+
 		return auth, nil
 	}
 
 	return nil, nil
 }
+
+// validatePinnedSignatureVersion checks the version is one of the project's
+// signature versions and is active, 0 means the endpoint isn't pinned.
+func validatePinnedSignatureVersion(project *datastore.Project, version int) error {
+	if version == 0 {
+		return nil
+	}
+
+	versions := project.Config.GetSignatureConfig().Versions
+	if version < 0 || version > len(versions) {
+		return errors.New("pinned signature version does not exist")
+	}
+
+	if !versions[version-1].IsActiveAt(time.Now()) {
+		return errors.New("pinned signature version is not active")
+	}
+
+	return nil
+}
@@ -60,13 +60,15 @@ func (m *MetaEvent) Run(ctx context.Context, eventType string, projectID string,
 	}
 
 	metaData := &datastore.Metadata{
-		NumTrials:       0,
-		RetryLimit:      project.Config.Strategy.RetryCount,
-		Data:            mpByte,
-		Raw:             string(mpByte),
-		IntervalSeconds: project.Config.Strategy.Duration,
-		Strategy:        project.Config.Strategy.Type,
-		NextSendTime:    time.Now(),
+		NumTrials:          0,
+		RetryLimit:         project.Config.Strategy.RetryCount,
+		Data:               mpByte,
+		Raw:                string(mpByte),
+		IntervalSeconds:    project.Config.Strategy.Duration,
+		Strategy:           project.Config.Strategy.Type,
+		BackoffBase:        project.Config.Strategy.BackoffBase,
+		MaxIntervalSeconds: project.Config.Strategy.MaxInterval,
+		NextSendTime:       time.Now(),
 	}
 
 	metaEvent := &datastore.MetaEvent{
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/license"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/util"
+	"github.com/frain-dev/convoy/worker/task"
+)
+
+// CreatePlannedDeliveriesService materializes precomputed delivery plans,
+// each event is delivered to exactly the endpoints its plan lists.
+type CreatePlannedDeliveriesService struct {
+	EventRepo         datastore.EventRepository
+	EndpointRepo      datastore.EndpointRepository
+	SubRepo           datastore.SubscriptionRepository
+	EventDeliveryRepo datastore.EventDeliveryRepository
+	DeviceRepo        datastore.DeviceRepository
+	Queue             queue.Queuer
+	Licenser          license.Licenser
+
+	Plans   []models.DeliveryPlan
+	Project *datastore.Project
+}
+
+// Run checks every event and endpoint the plans reference before creating
+// any delivery, and returns the number of deliveries planned.
+func (s *CreatePlannedDeliveriesService) Run(ctx context.Context) (int, error) {
+	var eventIDs, endpointIDs []string
+	seenEvents, seenEndpoints := map[string]bool{}, map[string]bool{}
+	for _, plan := range s.Plans {
+		if !seenEvents[plan.EventID] {
+			seenEvents[plan.EventID] = true
+			eventIDs = append(eventIDs, plan.EventID)
+		}
+
+		for _, id := range plan.EndpointIDs {
+			if !seenEndpoints[id] {
+				seenEndpoints[id] = true
+				endpointIDs = append(endpointIDs, id)
+			}
+		}
+	}
+
+	events, err := s.EventRepo.FindEventsByIDs(ctx, s.Project.UID, eventIDs)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to fetch events by ids")
+		return 0, &ServiceError{ErrMsg: "failed to fetch events", Err: err}
+	}
+
+	eventsByID := make(map[string]*datastore.Event, len(events))
+	for i := range events {
+		eventsByID[events[i].UID] = &events[i]
+	}
+
+	for _, id := range eventIDs {
+		if _, ok := eventsByID[id]; !ok {
+			return 0, &ServiceError{ErrMsg: fmt.Sprintf("event %s does not exist", id)}
+		}
+	}
+
+	endpoints, err := s.EndpointRepo.FindEndpointsByID(ctx, endpointIDs, s.Project.UID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to fetch endpoints by ids")
+		return 0, &ServiceError{ErrMsg: "failed to fetch endpoints", Err: err}
+	}
+
+	found := make(map[string]bool, len(endpoints))
+	for i := range endpoints {
+		found[endpoints[i].UID] = true
+	}
+
+	// deliveries must reference a subscription, an endpoint's own api
+	// subscription is used instead of matching the event against it
+	subscriptions := make(map[string]datastore.Subscription, len(endpointIDs))
+	for _, id := range endpointIDs {
+		if !found[id] {
+			return 0, &ServiceError{ErrMsg: fmt.Sprintf("endpoint %s does not exist", id)}
+		}
+
+		subs, err := s.SubRepo.FindSubscriptionsByEndpointID(ctx, s.Project.UID, id)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("failed to fetch endpoint subscriptions")
+			return 0, &ServiceError{ErrMsg: "failed to fetch endpoint subscriptions", Err: err}
+		}
+
+		for _, sub := range subs {
+			if sub.Type == datastore.SubscriptionTypeAPI {
+				subscriptions[id] = sub
+				break
+			}
+		}
+
+		if _, ok := subscriptions[id]; !ok {
+			return 0, &ServiceError{ErrMsg: fmt.Sprintf("endpoint %s has no subscription", id)}
+		}
+	}
+
+	planned := 0
+	for _, plan := range s.Plans {
+		event := eventsByID[plan.EventID]
+
+		subs := make([]datastore.Subscription, 0, len(plan.EndpointIDs))
+		newEndpoints := make([]string, 0, len(plan.EndpointIDs))
+		for _, id := range plan.EndpointIDs {
+			if !util.StringSliceContains(newEndpoints, id) {
+				newEndpoints = append(newEndpoints, id)
+				subs = append(subs, subscriptions[id])
+			}
+		}
+
+		for _, id := range newEndpoints {
+			if !util.StringSliceContains(event.Endpoints, id) {
+				event.Endpoints = append(event.Endpoints, id)
+			}
+		}
+
+		err = s.EventRepo.UpdateEventEndpoints(ctx, event, newEndpoints)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("failed to update event endpoints")
+			return planned, &ServiceError{ErrMsg: "failed to update event endpoints", Err: err}
+		}
+
+		err = task.CreatePlannedEventDeliveries(ctx, subs, event, s.Project, s.EventDeliveryRepo, s.Queue, s.DeviceRepo, s.EndpointRepo, s.Licenser)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("failed to create event deliveries for event %s", event.UID)
+			return planned, &ServiceError{ErrMsg: "failed to create event deliveries", Err: err}
+		}
+
+		planned += len(subs)
+	}
+
+	return planned, nil
+}
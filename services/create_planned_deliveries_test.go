@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func provideCreatePlannedDeliveriesService(ctrl *gomock.Controller, plans []models.DeliveryPlan) *CreatePlannedDeliveriesService {
+	return &CreatePlannedDeliveriesService{
+		EventRepo:         mocks.NewMockEventRepository(ctrl),
+		EndpointRepo:      mocks.NewMockEndpointRepository(ctrl),
+		SubRepo:           mocks.NewMockSubscriptionRepository(ctrl),
+		EventDeliveryRepo: mocks.NewMockEventDeliveryRepository(ctrl),
+		DeviceRepo:        mocks.NewMockDeviceRepository(ctrl),
+		Queue:             mocks.NewMockQueuer(ctrl),
+		Licenser:          mocks.NewMockLicenser(ctrl),
+		Plans:             plans,
+		Project: &datastore.Project{
+			UID:    "project-1",
+			Config: &datastore.ProjectConfig{Strategy: &datastore.DefaultStrategyConfig},
+		},
+	}
+}
+
+func TestCreatePlannedDeliveriesService_Run(t *testing.T) {
+	plans := []models.DeliveryPlan{
+		{EventID: "event-1", EndpointIDs: []string{"endpoint-1", "endpoint-2"}},
+		{EventID: "event-2", EndpointIDs: []string{"endpoint-2", "endpoint-2"}},
+	}
+
+	tests := []struct {
+		name        string
+		dbFn        func(s *CreatePlannedDeliveriesService, created *[][2]string)
+		wantPlanned int
+		wantPairs   [][2]string
+		wantErrMsg  string
+	}{
+		{
+			name: "should_create_exactly_the_planned_deliveries",
+			dbFn: func(s *CreatePlannedDeliveriesService, created *[][2]string) {
+				ev, _ := s.EventRepo.(*mocks.MockEventRepository)
+				ev.EXPECT().FindEventsByIDs(gomock.Any(), "project-1", []string{"event-1", "event-2"}).
+					Return([]datastore.Event{{UID: "event-1", ProjectID: "project-1"}, {UID: "event-2", ProjectID: "project-1"}}, nil)
+				ev.EXPECT().UpdateEventEndpoints(gomock.Any(), gomock.Any(), []string{"endpoint-1", "endpoint-2"}).Return(nil)
+				ev.EXPECT().UpdateEventEndpoints(gomock.Any(), gomock.Any(), []string{"endpoint-2"}).Return(nil)
+
+				e, _ := s.EndpointRepo.(*mocks.MockEndpointRepository)
+				e.EXPECT().FindEndpointsByID(gomock.Any(), []string{"endpoint-1", "endpoint-2"}, "project-1").
+					Return([]datastore.Endpoint{{UID: "endpoint-1"}, {UID: "endpoint-2"}}, nil)
+				e.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), "project-1").
+					DoAndReturn(func(_ context.Context, id, _ string) (*datastore.Endpoint, error) {
+						return &datastore.Endpoint{UID: id, Status: datastore.ActiveEndpointStatus}, nil
+					}).Times(3)
+
+				sr, _ := s.SubRepo.(*mocks.MockSubscriptionRepository)
+				sr.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-1", "endpoint-1").
+					Return([]datastore.Subscription{{UID: "sub-1", Type: datastore.SubscriptionTypeAPI, EndpointID: "endpoint-1"}}, nil)
+				sr.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-1", "endpoint-2").
+					Return([]datastore.Subscription{{UID: "sub-2", Type: datastore.SubscriptionTypeAPI, EndpointID: "endpoint-2"}}, nil)
+
+				ed, _ := s.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, deliveries []*datastore.EventDelivery) ([]*datastore.EventDelivery, error) {
+						for _, d := range deliveries {
+							*created = append(*created, [2]string{d.EventID, d.EndpointID})
+						}
+						return deliveries, nil
+					}).Times(2)
+
+				q, _ := s.Queue.(*mocks.MockQueuer)
+				q.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+			},
+			wantPlanned: 3,
+			wantPairs: [][2]string{
+				{"event-1", "endpoint-1"},
+				{"event-1", "endpoint-2"},
+				{"event-2", "endpoint-2"},
+			},
+		},
+		{
+			name: "should_reject_unknown_event",
+			dbFn: func(s *CreatePlannedDeliveriesService, _ *[][2]string) {
+				ev, _ := s.EventRepo.(*mocks.MockEventRepository)
+				ev.EXPECT().FindEventsByIDs(gomock.Any(), "project-1", []string{"event-1", "event-2"}).
+					Return([]datastore.Event{{UID: "event-1", ProjectID: "project-1"}}, nil)
+			},
+			wantErrMsg: "event event-2 does not exist",
+		},
+		{
+			name: "should_reject_unknown_endpoint",
+			dbFn: func(s *CreatePlannedDeliveriesService, _ *[][2]string) {
+				ev, _ := s.EventRepo.(*mocks.MockEventRepository)
+				ev.EXPECT().FindEventsByIDs(gomock.Any(), "project-1", []string{"event-1", "event-2"}).
+					Return([]datastore.Event{{UID: "event-1", ProjectID: "project-1"}, {UID: "event-2", ProjectID: "project-1"}}, nil)
+
+				e, _ := s.EndpointRepo.(*mocks.MockEndpointRepository)
+				e.EXPECT().FindEndpointsByID(gomock.Any(), []string{"endpoint-1", "endpoint-2"}, "project-1").
+					Return([]datastore.Endpoint{{UID: "endpoint-1"}}, nil)
+
+				sr, _ := s.SubRepo.(*mocks.MockSubscriptionRepository)
+				sr.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-1", "endpoint-1").
+					Return([]datastore.Subscription{{UID: "sub-1", Type: datastore.SubscriptionTypeAPI, EndpointID: "endpoint-1"}}, nil)
+			},
+			wantErrMsg: "endpoint endpoint-2 does not exist",
+		},
+		{
+			name: "should_reject_endpoint_without_subscription",
+			dbFn: func(s *CreatePlannedDeliveriesService, _ *[][2]string) {
+				ev, _ := s.EventRepo.(*mocks.MockEventRepository)
+				ev.EXPECT().FindEventsByIDs(gomock.Any(), "project-1", []string{"event-1", "event-2"}).
+					Return([]datastore.Event{{UID: "event-1", ProjectID: "project-1"}, {UID: "event-2", ProjectID: "project-1"}}, nil)
+
+				e, _ := s.EndpointRepo.(*mocks.MockEndpointRepository)
+				e.EXPECT().FindEndpointsByID(gomock.Any(), []string{"endpoint-1", "endpoint-2"}, "project-1").
+					Return([]datastore.Endpoint{{UID: "endpoint-1"}, {UID: "endpoint-2"}}, nil)
+
+				sr, _ := s.SubRepo.(*mocks.MockSubscriptionRepository)
+				sr.EXPECT().FindSubscriptionsByEndpointID(gomock.Any(), "project-1", "endpoint-1").
+					Return(nil, nil)
+			},
+			wantErrMsg: "endpoint endpoint-1 has no subscription",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := provideCreatePlannedDeliveriesService(ctrl, plans)
+
+			var created [][2]string
+			tt.dbFn(s, &created)
+
+			planned, err := s.Run(context.Background())
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.wantErrMsg, err.(*ServiceError).Error())
+				require.Empty(t, created)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPlanned, planned)
+			require.Equal(t, tt.wantPairs, created)
+		})
+	}
+}
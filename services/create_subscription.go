@@ -71,6 +71,10 @@ func (s *CreateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		EndpointID:   s.NewSubscription.EndpointID,
 		DeliveryMode: s.NewSubscription.DeliveryMode,
 
+		HeaderAllowList:  s.NewSubscription.HeaderAllowList,
+		PayloadTransform: s.NewSubscription.PayloadTransform,
+		DiffKey:          s.NewSubscription.DiffKey,
+
 		AlertConfig:     s.NewSubscription.AlertConfig.Transform(),
 		RateLimitConfig: s.NewSubscription.RateLimitConfig.Transform(),
 
@@ -78,11 +82,9 @@ func (s *CreateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 		UpdatedAt: time.Now(),
 	}
 
-	// Set default delivery mode if empty
-	if subscription.DeliveryMode == "" {
-		subscription.DeliveryMode = datastore.AtLeastOnceDeliveryMode
-	} else if subscription.DeliveryMode != datastore.AtLeastOnceDeliveryMode && subscription.DeliveryMode != datastore.AtMostOnceDeliveryMode {
-		return nil, &ServiceError{ErrMsg: "invalid delivery mode value, must be either 'at_least_once' or 'at_most_once'"}
+	// subscriptions without a delivery mode inherit the project's
+	if subscription.DeliveryMode != "" && !subscription.DeliveryMode.IsValid() {
+		return nil, &ServiceError{ErrMsg: "invalid delivery mode value, must be one of 'at_least_once', 'at_most_once' or 'ordered'"}
 	}
 
 	if s.Licenser.AdvancedSubscriptions() {
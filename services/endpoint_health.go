@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+const endpointHealthPageSize = 100
+
+// EndpointHealthSortKey is what an endpoint health report is sorted by.
+type EndpointHealthSortKey string
+
+const (
+	SortEndpointHealthByScore               EndpointHealthSortKey = "score"
+	SortEndpointHealthBySuccessRate         EndpointHealthSortKey = "success_rate"
+	SortEndpointHealthByLatency             EndpointHealthSortKey = "latency"
+	SortEndpointHealthByConsecutiveFailures EndpointHealthSortKey = "failures"
+)
+
+var ErrInvalidEndpointHealthSortKey = errors.New("sort must be one of score, success_rate, latency or failures")
+
+// EndpointHealthReport is the health of one endpoint.
+type EndpointHealthReport struct {
+	datastore.EndpointHealth
+	Name  string  `json:"name"`
+	URL   string  `json:"url"`
+	Score float64 `json:"score"`
+}
+
+// EndpointHealthService computes the health of every endpoint in a project
+// from its deliveries and attempts within Window, and sorts them by SortBy,
+// least healthy first unless Descending is set.
+type EndpointHealthService struct {
+	EndpointRepo      datastore.EndpointRepository
+	EventDeliveryRepo datastore.EventDeliveryRepository
+
+	ProjectID  string
+	Window     time.Duration
+	SortBy     EndpointHealthSortKey
+	Descending bool
+}
+
+func (e *EndpointHealthService) Run(ctx context.Context) ([]EndpointHealthReport, error) {
+	if e.Window <= 0 {
+		return nil, &ServiceError{ErrMsg: "window must be greater than zero"}
+	}
+
+	less, err := endpointHealthLess(e.SortBy)
+	if err != nil {
+		return nil, &ServiceError{ErrMsg: err.Error()}
+	}
+
+	endpoints, err := e.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]EndpointHealthReport, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		health, err := e.EventDeliveryRepo.ComputeEndpointHealth(ctx, e.ProjectID, endpoint.UID, e.Window)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("failed to compute the health of endpoint %s", endpoint.UID)
+			return nil, &ServiceError{ErrMsg: "failed to compute endpoint health", Err: err}
+		}
+
+		report = append(report, EndpointHealthReport{
+			EndpointHealth: *health,
+			Name:           endpoint.Name,
+			URL:            endpoint.Url,
+			Score:          endpointHealthScore(health),
+		})
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		if e.Descending {
+			return less(report[j], report[i])
+		}
+		return less(report[i], report[j])
+	})
+
+	return report, nil
+}
+
+// endpointHealthScore scores an endpoint's health like its reliability,
+// with its average latency standing in for the p95 and its consecutive
+// failures for breaker trips. An endpoint without deliveries or failures is
+// healthy.
+func endpointHealthScore(h *datastore.EndpointHealth) float64 {
+	if h.Total == 0 && h.ConsecutiveFailures == 0 {
+		return 100
+	}
+
+	stats := datastore.EndpointDeliveryStats{
+		EndpointID:        h.EndpointID,
+		Total:             h.Total,
+		Successful:        h.Successful,
+		P95LatencySeconds: h.AvgLatencySeconds,
+	}
+
+	return ScoreEndpointReliability(stats, h.ConsecutiveFailures, DefaultReliabilityWeights, defaultReliabilityLatencyTarget).Score
+}
+
+func (e *EndpointHealthService) endpoints(ctx context.Context) ([]datastore.Endpoint, error) {
+	var endpoints []datastore.Endpoint
+
+	pageable := datastore.Pageable{PerPage: endpointHealthPageSize, Direction: datastore.Next}
+	pageable.SetCursors()
+
+	for {
+		page, paginationData, err := e.EndpointRepo.LoadEndpointsPaged(ctx, e.ProjectID, &datastore.Filter{}, pageable)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("failed to load endpoints")
+			return nil, &ServiceError{ErrMsg: "failed to load endpoints", Err: err}
+		}
+
+		endpoints = append(endpoints, page...)
+
+		if !paginationData.HasNextPage {
+			return endpoints, nil
+		}
+		pageable.NextCursor = paginationData.NextPageCursor
+	}
+}
+
+// endpointHealthLess orders reports from the least to the most healthy by
+// key, so slower endpoints and ones with more failures come first
+func endpointHealthLess(key EndpointHealthSortKey) (func(a, b EndpointHealthReport) bool, error) {
+	switch key {
+	case "", SortEndpointHealthByScore:
+		return func(a, b EndpointHealthReport) bool { return a.Score < b.Score }, nil
+	case SortEndpointHealthBySuccessRate:
+		return func(a, b EndpointHealthReport) bool { return a.SuccessRate < b.SuccessRate }, nil
+	case SortEndpointHealthByLatency:
+		return func(a, b EndpointHealthReport) bool { return a.AvgLatencySeconds > b.AvgLatencySeconds }, nil
+	case SortEndpointHealthByConsecutiveFailures:
+		return func(a, b EndpointHealthReport) bool { return a.ConsecutiveFailures > b.ConsecutiveFailures }, nil
+	default:
+		return nil, ErrInvalidEndpointHealthSortKey
+	}
+}
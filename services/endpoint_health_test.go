@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEndpointHealthService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+
+	endpointRepo.EXPECT().LoadEndpointsPaged(gomock.Any(), "project-1", gomock.Any(), gomock.Any()).
+		Return([]datastore.Endpoint{{UID: "healthy", Name: "healthy", Url: "https://healthy.example.com"}}, datastore.PaginationData{HasNextPage: true, NextPageCursor: "healthy"}, nil)
+	endpointRepo.EXPECT().LoadEndpointsPaged(gomock.Any(), "project-1", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ *datastore.Filter, pageable datastore.Pageable) ([]datastore.Endpoint, datastore.PaginationData, error) {
+			require.Equal(t, "healthy", pageable.NextCursor)
+			return []datastore.Endpoint{{UID: "failing"}, {UID: "idle"}}, datastore.PaginationData{}, nil
+		})
+
+	health := map[string]*datastore.EndpointHealth{
+		"healthy": {EndpointID: "healthy", Total: 10, Successful: 9, SuccessRate: 90, AvgLatencySeconds: 0.2},
+		"failing": {EndpointID: "failing", Total: 10, Successful: 5, SuccessRate: 50, AvgLatencySeconds: 1.5, ConsecutiveFailures: 4},
+		"idle":    {EndpointID: "idle"},
+	}
+	edRepo.EXPECT().ComputeEndpointHealth(gomock.Any(), "project-1", gomock.Any(), time.Hour).Times(3).
+		DoAndReturn(func(_ context.Context, _, endpointID string, _ time.Duration) (*datastore.EndpointHealth, error) {
+			return health[endpointID], nil
+		})
+
+	s := &EndpointHealthService{
+		EndpointRepo:      endpointRepo,
+		EventDeliveryRepo: edRepo,
+		ProjectID:         "project-1",
+		Window:            time.Hour,
+	}
+
+	report, err := s.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report, 3)
+
+	// least healthy first
+	require.Equal(t, "failing", report[0].EndpointID)
+	require.Equal(t, 49.67, report[0].Score)
+	require.Equal(t, "healthy", report[1].EndpointID)
+	require.Equal(t, "https://healthy.example.com", report[1].URL)
+	require.Equal(t, 94.0, report[1].Score)
+	require.Equal(t, "idle", report[2].EndpointID)
+	require.Equal(t, 100.0, report[2].Score)
+}
+
+func TestEndpointHealthService_RunSorted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+
+	endpointRepo.EXPECT().LoadEndpointsPaged(gomock.Any(), "project-1", gomock.Any(), gomock.Any()).AnyTimes().
+		Return([]datastore.Endpoint{{UID: "slow"}, {UID: "fast"}}, datastore.PaginationData{}, nil)
+
+	health := map[string]*datastore.EndpointHealth{
+		"slow": {EndpointID: "slow", Total: 2, Successful: 2, SuccessRate: 100, AvgLatencySeconds: 3},
+		"fast": {EndpointID: "fast", Total: 2, Successful: 1, SuccessRate: 50, AvgLatencySeconds: 0.1, ConsecutiveFailures: 1},
+	}
+	edRepo.EXPECT().ComputeEndpointHealth(gomock.Any(), "project-1", gomock.Any(), time.Hour).AnyTimes().
+		DoAndReturn(func(_ context.Context, _, endpointID string, _ time.Duration) (*datastore.EndpointHealth, error) {
+			return health[endpointID], nil
+		})
+
+	s := &EndpointHealthService{
+		EndpointRepo:      endpointRepo,
+		EventDeliveryRepo: edRepo,
+		ProjectID:         "project-1",
+		Window:            time.Hour,
+		SortBy:            SortEndpointHealthByLatency,
+	}
+
+	report, err := s.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "slow", report[0].EndpointID)
+	require.Equal(t, "fast", report[1].EndpointID)
+
+	s.Descending = true
+	report, err = s.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fast", report[0].EndpointID)
+	require.Equal(t, "slow", report[1].EndpointID)
+
+	s.Descending = false
+	s.SortBy = SortEndpointHealthBySuccessRate
+	report, err = s.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fast", report[0].EndpointID)
+}
+
+func TestEndpointHealthService_RunErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+
+	s := &EndpointHealthService{
+		EndpointRepo:      endpointRepo,
+		EventDeliveryRepo: edRepo,
+		ProjectID:         "project-1",
+		Window:            time.Hour,
+		SortBy:            "name",
+	}
+
+	_, err := s.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, ErrInvalidEndpointHealthSortKey.Error(), err.Error())
+
+	s.SortBy = SortEndpointHealthByScore
+	s.Window = 0
+	_, err = s.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, "window must be greater than zero", err.Error())
+
+	s.Window = time.Hour
+	endpointRepo.EXPECT().LoadEndpointsPaged(gomock.Any(), "project-1", gomock.Any(), gomock.Any()).
+		Return([]datastore.Endpoint{{UID: "endpoint-1"}}, datastore.PaginationData{}, nil)
+	edRepo.EXPECT().ComputeEndpointHealth(gomock.Any(), "project-1", "endpoint-1", time.Hour).
+		Return(nil, errors.New("replica unavailable"))
+
+	_, err = s.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, "failed to compute endpoint health", err.Error())
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/circuit_breaker"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+const defaultReliabilityLatencyTarget = time.Second
+
+var ErrInvalidReliabilityWeights = errors.New("reliability weights must not be negative and at least one must be positive")
+
+// ReliabilityWeights are how much each signal counts towards an endpoint's
+// reliability score, relative to the others.
+type ReliabilityWeights struct {
+	SuccessRate  float64 `json:"success_rate"`
+	Latency      float64 `json:"latency"`
+	BreakerTrips float64 `json:"breaker_trips"`
+}
+
+// DefaultReliabilityWeights weigh mostly on whether deliveries succeed
+var DefaultReliabilityWeights = ReliabilityWeights{SuccessRate: 0.6, Latency: 0.25, BreakerTrips: 0.15}
+
+func (w ReliabilityWeights) validate() error {
+	if w.SuccessRate < 0 || w.Latency < 0 || w.BreakerTrips < 0 {
+		return ErrInvalidReliabilityWeights
+	}
+
+	if w.SuccessRate+w.Latency+w.BreakerTrips <= 0 {
+		return ErrInvalidReliabilityWeights
+	}
+
+	return nil
+}
+
+// EndpointReliability is an endpoint's reliability score, from 0 to 100,
+// and the signals it was computed from.
+type EndpointReliability struct {
+	EndpointID        string  `json:"endpoint_id"`
+	Score             float64 `json:"score"`
+	SuccessRate       float64 `json:"success_rate"`
+	P95LatencySeconds float64 `json:"p95_latency_seconds"`
+	BreakerTrips      uint64  `json:"breaker_trips"`
+}
+
+// circuitBreakerReader reads an endpoint's circuit breaker, it is
+// satisfied by *circuit_breaker.CircuitBreakerManager
+type circuitBreakerReader interface {
+	GetCircuitBreaker(ctx context.Context, key string) (*circuit_breaker.CircuitBreaker, error)
+}
+
+// EndpointReliabilityService scores the reliability of every endpoint of a
+// project with finished deliveries within Params. An endpoint's score
+// combines its success rate, how its p95 latency compares to
+// LatencyTarget and how many times in a row its circuit breaker tripped,
+// weighted by Weights.
+type EndpointReliabilityService struct {
+	EventDeliveryRepo datastore.EventDeliveryRepository
+	Breakers          circuitBreakerReader
+
+	ProjectID     string
+	Params        datastore.SearchParams
+	Weights       ReliabilityWeights
+	LatencyTarget time.Duration
+}
+
+func (e *EndpointReliabilityService) Run(ctx context.Context) ([]EndpointReliability, error) {
+	err := e.Weights.validate()
+	if err != nil {
+		return nil, &ServiceError{ErrMsg: err.Error()}
+	}
+
+	target := e.LatencyTarget
+	if target <= 0 {
+		target = defaultReliabilityLatencyTarget
+	}
+
+	stats, err := e.EventDeliveryRepo.LoadEndpointDeliveryStats(ctx, e.ProjectID, e.Params)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to load endpoint delivery stats")
+		return nil, &ServiceError{ErrMsg: "failed to load endpoint delivery stats", Err: err}
+	}
+
+	scores := make([]EndpointReliability, 0, len(stats))
+	for _, s := range stats {
+		trips, err := e.breakerTrips(ctx, s.EndpointID)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("failed to load the circuit breaker of endpoint %s", s.EndpointID)
+			return nil, &ServiceError{ErrMsg: "failed to load circuit breaker", Err: err}
+		}
+
+		scores = append(scores, ScoreEndpointReliability(s, trips, e.Weights, target))
+	}
+
+	return scores, nil
+}
+
+// breakerTrips returns how many times in a row the endpoint's circuit
+// breaker tripped, endpoints without a breaker haven't tripped
+func (e *EndpointReliabilityService) breakerTrips(ctx context.Context, endpointID string) (uint64, error) {
+	if e.Breakers == nil {
+		return 0, nil
+	}
+
+	b, err := e.Breakers.GetCircuitBreaker(ctx, endpointID)
+	if err != nil {
+		return 0, err
+	}
+
+	if b == nil {
+		return 0, nil
+	}
+
+	return b.ConsecutiveFailures, nil
+}
+
+// ScoreEndpointReliability scores an endpoint from its delivery stats and
+// breaker trips. Each signal is scored from 0 to 1: the success rate as is,
+// latency as 1 up to target then falling off as target/p95, and trips as
+// 1/(1+trips). The score is their weighted average scaled to 0-100.
+func ScoreEndpointReliability(stats datastore.EndpointDeliveryStats, trips uint64, weights ReliabilityWeights, target time.Duration) EndpointReliability {
+	var successRate float64
+	if stats.Total > 0 {
+		successRate = float64(stats.Successful) / float64(stats.Total)
+	}
+
+	latencyScore := 1.0
+	if stats.P95LatencySeconds > target.Seconds() {
+		latencyScore = target.Seconds() / stats.P95LatencySeconds
+	}
+
+	// with nothing delivered, there's no latency to reward
+	if stats.Successful == 0 {
+		latencyScore = 0
+	}
+
+	tripScore := 1 / (1 + float64(trips))
+
+	total := weights.SuccessRate + weights.Latency + weights.BreakerTrips
+	score := (weights.SuccessRate*successRate + weights.Latency*latencyScore + weights.BreakerTrips*tripScore) / total
+
+	return EndpointReliability{
+		EndpointID:        stats.EndpointID,
+		Score:             math.Round(score*10000) / 100,
+		SuccessRate:       successRate * 100,
+		P95LatencySeconds: stats.P95LatencySeconds,
+		BreakerTrips:      trips,
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/circuit_breaker"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeBreakerReader map[string]*circuit_breaker.CircuitBreaker
+
+func (f fakeBreakerReader) GetCircuitBreaker(_ context.Context, key string) (*circuit_breaker.CircuitBreaker, error) {
+	if key == "broken" {
+		return nil, errors.New("store unavailable")
+	}
+
+	return f[key], nil
+}
+
+func TestScoreEndpointReliability(t *testing.T) {
+	score := func(successful uint64, p95 float64, trips uint64) float64 {
+		stats := datastore.EndpointDeliveryStats{EndpointID: "endpoint-1", Total: 100, Successful: successful, P95LatencySeconds: p95}
+		return ScoreEndpointReliability(stats, trips, DefaultReliabilityWeights, time.Second).Score
+	}
+
+	// every delivery succeeding fast without trips is a perfect score
+	require.Equal(t, 100.0, score(100, 0.5, 0))
+
+	// the score falls as the success rate does
+	require.Greater(t, score(100, 0.5, 0), score(90, 0.5, 0))
+	require.Greater(t, score(90, 0.5, 0), score(50, 0.5, 0))
+
+	// latencies within the target score the same, slower ones score lower
+	require.Equal(t, score(100, 0.2, 0), score(100, 1, 0))
+	require.Greater(t, score(100, 1, 0), score(100, 2, 0))
+	require.Greater(t, score(100, 2, 0), score(100, 10, 0))
+
+	// breaker trips lower the score
+	require.Greater(t, score(100, 0.5, 0), score(100, 0.5, 1))
+	require.Greater(t, score(100, 0.5, 1), score(100, 0.5, 5))
+
+	// nothing delivered only scores for not tripping
+	require.Equal(t, 15.0, score(0, 0, 0))
+
+	// weights decide which signal matters
+	slow := datastore.EndpointDeliveryStats{Total: 100, Successful: 100, P95LatencySeconds: 4}
+	flaky := datastore.EndpointDeliveryStats{Total: 100, Successful: 80, P95LatencySeconds: 0.5}
+	latencyOnly := ReliabilityWeights{Latency: 1}
+	successOnly := ReliabilityWeights{SuccessRate: 1}
+
+	require.Equal(t, 25.0, ScoreEndpointReliability(slow, 0, latencyOnly, time.Second).Score)
+	require.Equal(t, 100.0, ScoreEndpointReliability(flaky, 0, latencyOnly, time.Second).Score)
+	require.Equal(t, 100.0, ScoreEndpointReliability(slow, 0, successOnly, time.Second).Score)
+	require.Equal(t, 80.0, ScoreEndpointReliability(flaky, 0, successOnly, time.Second).Score)
+}
+
+func TestEndpointReliabilityService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	params := datastore.SearchParams{CreatedAtStart: 1, CreatedAtEnd: 2}
+
+	edRepo.EXPECT().LoadEndpointDeliveryStats(gomock.Any(), "project-1", params).Return([]datastore.EndpointDeliveryStats{
+		{EndpointID: "healthy", Total: 10, Successful: 10, P95LatencySeconds: 0.3},
+		{EndpointID: "tripping", Total: 10, Successful: 4, P95LatencySeconds: 3},
+	}, nil)
+
+	s := &EndpointReliabilityService{
+		EventDeliveryRepo: edRepo,
+		Breakers:          fakeBreakerReader{"tripping": {ConsecutiveFailures: 3}},
+		ProjectID:         "project-1",
+		Params:            params,
+		Weights:           DefaultReliabilityWeights,
+	}
+
+	scores, err := s.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+
+	require.Equal(t, EndpointReliability{EndpointID: "healthy", Score: 100, SuccessRate: 100, P95LatencySeconds: 0.3}, scores[0])
+
+	require.Equal(t, "tripping", scores[1].EndpointID)
+	require.Equal(t, uint64(3), scores[1].BreakerTrips)
+	require.Equal(t, 40.0, scores[1].SuccessRate)
+	// 0.6*0.4 + 0.25*(1/3) + 0.15*(1/4)
+	require.InDelta(t, 36.08, scores[1].Score, 0.01)
+}
+
+func TestEndpointReliabilityService_RunErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+
+	s := &EndpointReliabilityService{
+		EventDeliveryRepo: edRepo,
+		Breakers:          fakeBreakerReader{},
+		ProjectID:         "project-1",
+		Weights:           ReliabilityWeights{SuccessRate: 1, Latency: -1},
+	}
+
+	_, err := s.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, ErrInvalidReliabilityWeights.Error(), err.Error())
+
+	s.Weights = ReliabilityWeights{}
+	_, err = s.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, ErrInvalidReliabilityWeights.Error(), err.Error())
+
+	s.Weights = DefaultReliabilityWeights
+	edRepo.EXPECT().LoadEndpointDeliveryStats(gomock.Any(), "project-1", gomock.Any()).
+		Return([]datastore.EndpointDeliveryStats{{EndpointID: "broken", Total: 1, Successful: 1}}, nil)
+
+	_, err = s.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, "failed to load circuit breaker", err.Error())
+}
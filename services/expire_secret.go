@@ -83,6 +83,7 @@ func (a *ExpireSecretService) Run(ctx context.Context) (*datastore.Endpoint, err
 	sc := datastore.Secret{
 		UID:       ulid.Make().String(),
 		Value:     newSecret,
+		Epoch:     a.Endpoint.NextSecretEpoch(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -98,3 +98,32 @@ func TestExpireSecretService_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestExpireSecretService_SecretEpoch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpoint := &datastore.Endpoint{
+		UID:       "abc",
+		ProjectID: "1234",
+		Secrets:   []datastore.Secret{{UID: "1234", Value: "test_secret"}},
+	}
+	require.Equal(t, int64(0), endpoint.SecretEpoch())
+
+	es := provideExpireSecretService(ctrl, &models.ExpireSecret{Expiration: 10}, endpoint, &datastore.Project{UID: "1234567890"})
+
+	endpointRepo := es.EndpointRepo.(*mocks.MockEndpointRepository)
+	endpointRepo.EXPECT().UpdateSecrets(gomock.Any(), "abc", "1234567890", gomock.Any()).Times(2).Return(nil)
+
+	eq, _ := es.Queuer.(*mocks.MockQueuer)
+	eq.EXPECT().Write(convoy.ExpireSecretsProcessor, convoy.DefaultQueue, gomock.Any()).Times(2).Return(nil)
+
+	// every rotation's secret is one epoch after the one it replaces
+	for want := int64(1); want <= 2; want++ {
+		endpoint, err := es.Run(context.Background())
+		require.NoError(t, err)
+
+		require.Equal(t, want, endpoint.SecretEpoch())
+		require.Equal(t, want, endpoint.Secrets[len(endpoint.Secrets)-1].Epoch)
+	}
+}
@@ -3,12 +3,17 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/queue"
 )
 
+// DefaultRequeueMaxAge is how old a delivery can be for a bulk retry from
+// the dashboard to resend it, older ones need an explicit override
+const DefaultRequeueMaxAge = 72 * time.Hour
+
 type ForceResendEventDeliveriesService struct {
 	EventDeliveryRepo datastore.EventDeliveryRepository
 	EndpointRepo      datastore.EndpointRepository
@@ -16,6 +21,11 @@ type ForceResendEventDeliveriesService struct {
 
 	IDs     []string
 	Project *datastore.Project
+
+	// MaxAge, when set, leaves deliveries created longer ago than it alone,
+	// their ids are in Skipped after Run
+	MaxAge  time.Duration
+	Skipped []string
 }
 
 func (e *ForceResendEventDeliveriesService) Run(ctx context.Context) (int, int, error) {
@@ -31,6 +41,10 @@ func (e *ForceResendEventDeliveriesService) Run(ctx context.Context) (int, int,
 		return 0, 0, &ServiceError{ErrMsg: err.Error()}
 	}
 
+	if e.MaxAge > 0 {
+		return e.forceResendRecentEventDeliveries(ctx, deliveries)
+	}
+
 	failures := 0
 	for _, delivery := range deliveries {
 		err := e.forceResendEventDelivery(ctx, &delivery, e.Project)
@@ -44,7 +58,70 @@ func (e *ForceResendEventDeliveriesService) Run(ctx context.Context) (int, int,
 	return successes, failures, nil
 }
 
+// forceResendRecentEventDeliveries resends the deliveries created within
+// MaxAge, the status of the rest isn't touched
+func (e *ForceResendEventDeliveriesService) forceResendRecentEventDeliveries(ctx context.Context, deliveries []datastore.EventDelivery) (int, int, error) {
+	failures := 0
+	eligible := make([]datastore.EventDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		err := e.checkEndpoint(ctx, &delivery, e.Project)
+		if err != nil {
+			failures++
+			log.FromContext(ctx).WithError(err).Error("an item in the force resend batch failed")
+			continue
+		}
+
+		eligible = append(eligible, delivery)
+	}
+
+	ids := make([]string, 0, len(eligible))
+	for _, delivery := range eligible {
+		ids = append(ids, delivery.UID)
+	}
+
+	skipped, err := e.EventDeliveryRepo.RequeueRecentEventDeliveries(ctx, e.Project.UID, ids, e.MaxAge)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to requeue event deliveries")
+		return 0, 0, &ServiceError{ErrMsg: "failed to requeue event deliveries", Err: err}
+	}
+
+	e.Skipped = skipped
+
+	tooOld := make(map[string]bool, len(skipped))
+	for _, id := range skipped {
+		tooOld[id] = true
+	}
+
+	successes := 0
+	for _, delivery := range eligible {
+		if tooOld[delivery.UID] {
+			continue
+		}
+
+		delivery.Status = datastore.ScheduledEventStatus
+		err := enqueueEventDelivery(ctx, &delivery, e.Project, e.Queue, true)
+		if err != nil {
+			failures++
+			log.FromContext(ctx).WithError(err).Error("an item in the force resend batch failed")
+			continue
+		}
+
+		successes++
+	}
+
+	return successes, failures, nil
+}
+
 func (e *ForceResendEventDeliveriesService) forceResendEventDelivery(ctx context.Context, eventDelivery *datastore.EventDelivery, project *datastore.Project) error {
+	err := e.checkEndpoint(ctx, eventDelivery, project)
+	if err != nil {
+		return err
+	}
+
+	return requeueEventDelivery(ctx, eventDelivery, project, e.EventDeliveryRepo, e.Queue, true)
+}
+
+func (e *ForceResendEventDeliveriesService) checkEndpoint(ctx context.Context, eventDelivery *datastore.EventDelivery, project *datastore.Project) error {
 	endpoint, err := e.EndpointRepo.FindEndpointByID(ctx, eventDelivery.EndpointID, project.UID)
 	if err != nil {
 		return datastore.ErrEndpointNotFound
@@ -54,7 +131,7 @@ func (e *ForceResendEventDeliveriesService) forceResendEventDelivery(ctx context
 		return errors.New("force resend to an inactive or pending endpoint is not allowed")
 	}
 
-	return requeueEventDelivery(ctx, eventDelivery, project, e.EventDeliveryRepo, e.Queue)
+	return nil
 }
 
 func validateEventDeliveryStatus(deliveries []datastore.EventDelivery) error {
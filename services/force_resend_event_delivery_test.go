@@ -144,6 +144,48 @@ func TestForceResendEventDeliveriesService_Run(t *testing.T) {
 	}
 }
 
+func TestForceResendEventDeliveriesService_RunWithMaxAge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := config.LoadConfig("./testdata/basic-config.json")
+	require.NoError(t, err)
+
+	es := provideForceResendEventDeliveriesService(ctrl, []string{"recent", "stale", "paused"}, &datastore.Project{UID: "123"})
+	es.MaxAge = DefaultRequeueMaxAge
+
+	ed, _ := es.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+	ed.EXPECT().FindEventDeliveriesByIDs(gomock.Any(), "123", []string{"recent", "stale", "paused"}).
+		Return([]datastore.EventDelivery{
+			{UID: "recent", EndpointID: "active", Status: datastore.SuccessEventStatus},
+			{UID: "stale", EndpointID: "active", Status: datastore.SuccessEventStatus},
+			{UID: "paused", EndpointID: "paused", Status: datastore.SuccessEventStatus},
+		}, nil)
+
+	a, _ := es.EndpointRepo.(*mocks.MockEndpointRepository)
+	a.EXPECT().FindEndpointByID(gomock.Any(), "active", "123").
+		Times(2).Return(&datastore.Endpoint{Status: datastore.ActiveEndpointStatus}, nil)
+	a.EXPECT().FindEndpointByID(gomock.Any(), "paused", "123").
+		Return(&datastore.Endpoint{Status: datastore.PausedEndpointStatus}, nil)
+
+	// only deliveries to active endpoints are requeued, and the stale one is skipped
+	ed.EXPECT().RequeueRecentEventDeliveries(gomock.Any(), "123", []string{"recent", "stale"}, DefaultRequeueMaxAge).
+		Return([]string{"stale"}, nil)
+
+	q, _ := es.Queue.(*mocks.MockQueuer)
+	q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).
+		DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+			require.Equal(t, "recent", job.ID)
+			return nil
+		})
+
+	successes, failures, err := es.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, successes)
+	require.Equal(t, 1, failures)
+	require.Equal(t, []string{"stale"}, es.Skipped)
+}
+
 func TestEventService_forceResendEventDelivery(t *testing.T) {
 	ctx := context.Background()
 	type args struct {
@@ -332,7 +374,7 @@ func TestEventService_requeueEventDelivery(t *testing.T) {
 				tc.dbFn(&tc.args)
 			}
 
-			err = requeueEventDelivery(tc.args.ctx, tc.args.eventDelivery, tc.args.g, tc.args.eventDeliveryRepo, tc.args.queuer)
+			err = requeueEventDelivery(tc.args.ctx, tc.args.eventDelivery, tc.args.g, tc.args.eventDeliveryRepo, tc.args.queuer, true)
 			if tc.wantErr {
 				require.NotNil(t, err)
 				require.Equal(t, tc.wantErrMsg, err.(*ServiceError).Error())
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/config"
+	"github.com/oklog/ulid/v2"
+)
+
+// InspectedDelivery is a request captured by the inspection sink.
+type InspectedDelivery struct {
+	UID        string      `json:"uid"`
+	ProjectID  string      `json:"project_id"`
+	Method     string      `json:"method"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+	CapturedAt time.Time   `json:"captured_at"`
+}
+
+// InspectionSink keeps the last few requests delivered to a project's
+// inspection URL in the cache, so test deliveries can be looked at
+// without an external service. A capture is dropped once it is older
+// than TTL.
+//
+// Captures are read, changed and written back without a lock, so two
+// deliveries captured at the same time can lose one of them, that's
+// fine for a sink meant for trying out deliveries.
+type InspectionSink struct {
+	Cache       cache.Cache
+	TTL         time.Duration
+	MaxCaptures int
+
+	now func() time.Time
+}
+
+func NewInspectionSink(c cache.Cache, cfg config.InspectionSinkConfiguration) *InspectionSink {
+	return &InspectionSink{
+		Cache:       c,
+		TTL:         cfg.GetTTL(),
+		MaxCaptures: cfg.GetMaxCaptures(),
+		now:         time.Now,
+	}
+}
+
+// Capture keeps d as the latest capture of its project.
+func (s *InspectionSink) Capture(ctx context.Context, d *InspectedDelivery) error {
+	captures, err := s.load(ctx, d.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	d.UID = ulid.Make().String()
+	d.CapturedAt = s.now()
+
+	captures = append([]InspectedDelivery{*d}, captures...)
+	if len(captures) > s.MaxCaptures {
+		captures = captures[:s.MaxCaptures]
+	}
+
+	err = s.Cache.Set(ctx, inspectionSinkKey(d.ProjectID), captures, s.TTL)
+	if err != nil {
+		return &ServiceError{ErrMsg: "failed to save inspected delivery", Err: err}
+	}
+
+	return nil
+}
+
+// Recent returns up to limit of a project's captures, latest first.
+func (s *InspectionSink) Recent(ctx context.Context, projectID string, limit int) ([]InspectedDelivery, error) {
+	captures, err := s.load(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(captures) > limit {
+		captures = captures[:limit]
+	}
+
+	return captures, nil
+}
+
+// load returns a project's captures that haven't expired, the cache only
+// expires the whole list, ttl after the last capture.
+func (s *InspectionSink) load(ctx context.Context, projectID string) ([]InspectedDelivery, error) {
+	var captures []InspectedDelivery
+	err := s.Cache.Get(ctx, inspectionSinkKey(projectID), &captures)
+	if err != nil {
+		return nil, &ServiceError{ErrMsg: "failed to load inspected deliveries", Err: err}
+	}
+
+	cutoff := s.now().Add(-s.TTL)
+	live := make([]InspectedDelivery, 0, len(captures))
+	for _, c := range captures {
+		if c.CapturedAt.After(cutoff) {
+			live = append(live, c)
+		}
+	}
+
+	return live, nil
+}
+
+func inspectionSinkKey(projectID string) string {
+	return "inspection_sink:" + projectID
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	mcache "github.com/frain-dev/convoy/cache/memory"
+	"github.com/frain-dev/convoy/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectionSink(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewInspectionSink(mcache.NewMemoryCache(), config.InspectionSinkConfiguration{TTL: 60, MaxCaptures: 3})
+	s.now = func() time.Time { return now }
+
+	capture := func(projectID, body string) {
+		err := s.Capture(ctx, &InspectedDelivery{
+			ProjectID: projectID,
+			Method:    http.MethodPost,
+			Headers:   http.Header{"X-Convoy-Signature": []string{"sig"}},
+			Body:      body,
+		})
+		require.NoError(t, err)
+	}
+
+	bodies := func(captures []InspectedDelivery) []string {
+		b := make([]string, 0, len(captures))
+		for _, c := range captures {
+			b = append(b, c.Body)
+		}
+		return b
+	}
+
+	// nothing captured yet
+	captures, err := s.Recent(ctx, "project-1", 10)
+	require.NoError(t, err)
+	require.Empty(t, captures)
+
+	capture("project-1", `{"n":1}`)
+	now = now.Add(10 * time.Second)
+	capture("project-1", `{"n":2}`)
+	capture("project-2", `{"n":1}`)
+
+	// the latest capture comes first, with what was delivered
+	captures, err = s.Recent(ctx, "project-1", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"n":2}`, `{"n":1}`}, bodies(captures))
+	require.NotEmpty(t, captures[0].UID)
+	require.Equal(t, "project-1", captures[0].ProjectID)
+	require.Equal(t, http.MethodPost, captures[0].Method)
+	require.Equal(t, "sig", captures[0].Headers.Get("X-Convoy-Signature"))
+	require.True(t, now.Equal(captures[0].CapturedAt))
+
+	// only the last n are returned
+	captures, err = s.Recent(ctx, "project-1", 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"n":2}`}, bodies(captures))
+
+	// and only the last MaxCaptures are kept
+	capture("project-1", `{"n":3}`)
+	capture("project-1", `{"n":4}`)
+	captures, err = s.Recent(ctx, "project-1", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"n":4}`, `{"n":3}`, `{"n":2}`}, bodies(captures))
+
+	// captures expire after the ttl
+	now = now.Add(55 * time.Second)
+	captures, err = s.Recent(ctx, "project-1", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"n":4}`, `{"n":3}`, `{"n":2}`}, bodies(captures))
+
+	now = now.Add(10 * time.Second)
+	captures, err = s.Recent(ctx, "project-1", 10)
+	require.NoError(t, err)
+	require.Empty(t, captures)
+
+	captures, err = s.Recent(ctx, "project-2", 10)
+	require.NoError(t, err)
+	require.Empty(t, captures)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+// PauseSubscriptionService pauses or resumes a subscription, its event
+// deliveries are held while it is paused. Pausing a paused subscription,
+// or resuming an active one, leaves it as it is.
+type PauseSubscriptionService struct {
+	SubRepo        datastore.SubscriptionRepository
+	ProjectID      string
+	SubscriptionID string
+	Paused         bool
+}
+
+func (s *PauseSubscriptionService) Run(ctx context.Context) (*datastore.Subscription, error) {
+	subscription, err := s.SubRepo.FindSubscriptionByID(ctx, s.ProjectID, s.SubscriptionID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to find subscription")
+		return nil, &ServiceError{ErrMsg: "failed to find subscription", Err: err}
+	}
+
+	subscription.Paused = s.Paused
+
+	err = s.SubRepo.UpdateSubscriptionPaused(ctx, s.ProjectID, subscription.UID, subscription.Paused)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to update subscription")
+		return nil, &ServiceError{ErrMsg: "failed to update subscription", Err: err}
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func providePauseSubscriptionService(ctrl *gomock.Controller, subscriptionID, projectID string, paused bool) *PauseSubscriptionService {
+	return &PauseSubscriptionService{
+		SubRepo:        mocks.NewMockSubscriptionRepository(ctrl),
+		SubscriptionID: subscriptionID,
+		ProjectID:      projectID,
+		Paused:         paused,
+	}
+}
+
+func TestPauseSubscriptionService_Run(t *testing.T) {
+	tests := []struct {
+		name             string
+		paused           bool
+		dbFn             func(ss *PauseSubscriptionService)
+		wantSubscription *datastore.Subscription
+		wantErrMsg       string
+	}{
+		{
+			name:   "should_pause_active_subscription",
+			paused: true,
+			dbFn: func(ss *PauseSubscriptionService) {
+				s, _ := ss.SubRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "abc", "123").Times(1).Return(
+					&datastore.Subscription{UID: "123"}, nil,
+				)
+
+				s.EXPECT().UpdateSubscriptionPaused(gomock.Any(), "abc", "123", true).Times(1).Return(nil)
+			},
+			wantSubscription: &datastore.Subscription{UID: "123", Paused: true},
+		},
+		{
+			name: "should_resume_paused_subscription",
+			dbFn: func(ss *PauseSubscriptionService) {
+				s, _ := ss.SubRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "abc", "123").Times(1).Return(
+					&datastore.Subscription{UID: "123", Paused: true}, nil,
+				)
+
+				s.EXPECT().UpdateSubscriptionPaused(gomock.Any(), "abc", "123", false).Times(1).Return(nil)
+			},
+			wantSubscription: &datastore.Subscription{UID: "123"},
+		},
+		{
+			name:   "should_keep_paused_subscription_paused",
+			paused: true,
+			dbFn: func(ss *PauseSubscriptionService) {
+				s, _ := ss.SubRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "abc", "123").Times(1).Return(
+					&datastore.Subscription{UID: "123", Paused: true}, nil,
+				)
+
+				s.EXPECT().UpdateSubscriptionPaused(gomock.Any(), "abc", "123", true).Times(1).Return(nil)
+			},
+			wantSubscription: &datastore.Subscription{UID: "123", Paused: true},
+		},
+		{
+			name: "should_fail_to_find_subscription",
+			dbFn: func(ss *PauseSubscriptionService) {
+				s, _ := ss.SubRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "abc", "123").Times(1).Return(
+					nil, datastore.ErrSubscriptionNotFound,
+				)
+			},
+			wantErrMsg: "failed to find subscription",
+		},
+		{
+			name:   "should_fail_to_update_subscription",
+			paused: true,
+			dbFn: func(ss *PauseSubscriptionService) {
+				s, _ := ss.SubRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "abc", "123").Times(1).Return(
+					&datastore.Subscription{UID: "123"}, nil,
+				)
+
+				s.EXPECT().UpdateSubscriptionPaused(gomock.Any(), "abc", "123", true).Times(1).Return(errors.New("failed"))
+			},
+			wantErrMsg: "failed to update subscription",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := providePauseSubscriptionService(ctrl, "123", "abc", tt.paused)
+			tt.dbFn(s)
+
+			subscription, err := s.Run(context.Background())
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.wantErrMsg, err.(*ServiceError).Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSubscription, subscription)
+		})
+	}
+}
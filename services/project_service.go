@@ -9,10 +9,12 @@ import (
 
 	"github.com/frain-dev/convoy/internal/pkg/license"
 
+	"github.com/dchest/uniuri"
 	"github.com/frain-dev/convoy/auth"
 	"github.com/oklog/ulid/v2"
 
 	"github.com/frain-dev/convoy/api/models"
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/util"
@@ -79,12 +81,39 @@ func (ps *ProjectService) CreateProject(ctx context.Context, newProject *models.
 			return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
 		}
 
+		err = validateMaxRetrySeconds(projectConfig)
+		if err != nil {
+			return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateStrategy(projectConfig)
+		if err != nil {
+			return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateMetadataHeaders(projectConfig)
+		if err != nil {
+			return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateDeliveryMode(projectConfig)
+		if err != nil {
+			return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateSignatureVersions(projectConfig, time.Now())
+		if err != nil {
+			return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
 		if !util.IsStringEmpty(projectConfig.SearchPolicy) {
 			_, err = time.ParseDuration(projectConfig.SearchPolicy)
 			if err != nil {
 				return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
 			}
 		}
+
+		setInspectionToken(projectConfig, "")
 	}
 
 	if !ps.Licenser.AdvancedWebhookFiltering() {
@@ -173,12 +202,43 @@ func (ps *ProjectService) UpdateProject(ctx context.Context, project *datastore.
 			}
 		}
 
+		var inspectionToken string
+		if project.Config != nil {
+			inspectionToken = project.Config.InspectionToken
+		}
+
 		project.Config = update.Config.Transform()
+		setInspectionToken(project.Config, inspectionToken)
 		checkSignatureVersions(project.Config.Signature.Versions)
 		err := validateMetaEvent(project.Config, ps.Licenser)
 		if err != nil {
 			return nil, util.NewServiceError(http.StatusBadRequest, err)
 		}
+
+		err = validateMaxRetrySeconds(project.Config)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateStrategy(project.Config)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateMetadataHeaders(project.Config)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateDeliveryMode(project.Config)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateSignatureVersions(project.Config, time.Now())
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
 	}
 
 	if !util.IsStringEmpty(update.LogoURL) {
@@ -211,6 +271,107 @@ func checkSignatureVersions(versions []datastore.SignatureVersion) {
 	}
 }
 
+// setInspectionToken keeps the inspection token of a project that stays in
+// test mode, issues one when test mode is turned on and clears it when it is
+// turned off, so an old inspection URL stops working.
+func setInspectionToken(c *datastore.ProjectConfig, current string) {
+	switch {
+	case !c.TestMode:
+		c.InspectionToken = ""
+	case current != "":
+		c.InspectionToken = current
+	default:
+		c.InspectionToken = uniuri.NewLen(32)
+	}
+}
+
+// validateMaxRetrySeconds ensures the project's retry ceiling isn't above the
+// instance's, which the worker would cap it to anyway.
+func validateMaxRetrySeconds(c *datastore.ProjectConfig) error {
+	if c.MaxRetrySeconds == 0 {
+		return nil
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	if cfg.MaxRetrySeconds != 0 && c.MaxRetrySeconds > cfg.MaxRetrySeconds {
+		return fmt.Errorf("max retry seconds cannot be greater than the instance's max retry seconds of %d", cfg.MaxRetrySeconds)
+	}
+
+	return nil
+}
+
+// validateStrategy ensures the exponential strategy's backoff grows, its max
+// interval isn't shorter than the interval it starts from and retries aren't
+// jittered by more than their whole delay.
+func validateStrategy(c *datastore.ProjectConfig) error {
+	sc := c.GetStrategyConfig()
+
+	if sc.BackoffBase != 0 && sc.BackoffBase <= 1 {
+		return errors.New("strategy backoff base must be greater than 1")
+	}
+
+	if sc.MaxInterval != 0 && sc.MaxInterval < sc.Duration {
+		return fmt.Errorf("strategy max interval cannot be less than the strategy duration of %d", sc.Duration)
+	}
+
+	if sc.JitterPercent > 100 {
+		return errors.New("strategy jitter percent cannot be greater than 100")
+	}
+
+	return nil
+}
+
+// validateMetadataHeaders ensures the project only sends known metadata headers.
+func validateMetadataHeaders(c *datastore.ProjectConfig) error {
+	for _, h := range c.MetadataHeaders {
+		if _, ok := datastore.DeliveryMetadataHeader(h).HeaderName(); !ok {
+			return fmt.Errorf("unsupported metadata header %q", h)
+		}
+	}
+
+	return nil
+}
+
+// validateDeliveryMode ensures the project's default delivery mode is one
+// subscriptions support.
+func validateDeliveryMode(c *datastore.ProjectConfig) error {
+	if c.DeliveryMode != "" && !c.DeliveryMode.IsValid() {
+		return errors.New("invalid delivery mode value, must be one of 'at_least_once', 'at_most_once' or 'ordered'")
+	}
+
+	return nil
+}
+
+// validateSignatureVersions ensures every version's window ends after it
+// starts and that deliveries sent now can be signed with some version.
+func validateSignatureVersions(c *datastore.ProjectConfig, now time.Time) error {
+	versions := c.GetSignatureConfig().Versions
+	if len(versions) == 0 {
+		return nil
+	}
+
+	var active bool
+	for i, v := range versions {
+		if v.ActiveFrom.Valid && v.ActiveUntil.Valid && !v.ActiveFrom.Time.Before(v.ActiveUntil.Time) {
+			return fmt.Errorf("signature version %d must become active before it expires", i+1)
+		}
+
+		if v.IsActiveAt(now) {
+			active = true
+		}
+	}
+
+	if !active {
+		return errors.New("at least one signature version must be active")
+	}
+
+	return nil
+}
+
 func validateMetaEvent(c *datastore.ProjectConfig, licenser license.Licenser) error {
 	metaEvent := c.MetaEvent
 	if metaEvent == nil {
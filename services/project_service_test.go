@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/frain-dev/convoy/api/models"
 	"github.com/frain-dev/convoy/auth"
@@ -14,6 +15,7 @@ import (
 	"github.com/frain-dev/convoy/util"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"gopkg.in/guregu/null.v4"
 )
 
 func provideProjectService(ctrl *gomock.Controller) (*ProjectService, error) {
@@ -738,3 +740,84 @@ func TestProjectService_UpdateProject(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMaxRetrySeconds(t *testing.T) {
+	t.Setenv("CONVOY_MAX_RETRY_SECONDS", "3600")
+
+	err := config.LoadConfig("./testdata/basic-config.json")
+	require.NoError(t, err)
+
+	require.NoError(t, validateMaxRetrySeconds(&datastore.ProjectConfig{}))
+	require.NoError(t, validateMaxRetrySeconds(&datastore.ProjectConfig{MaxRetrySeconds: 3600}))
+
+	err = validateMaxRetrySeconds(&datastore.ProjectConfig{MaxRetrySeconds: 3601})
+	require.EqualError(t, err, "max retry seconds cannot be greater than the instance's max retry seconds of 3600")
+}
+
+func TestSetInspectionToken(t *testing.T) {
+	// turning test mode on issues a token
+	c := &datastore.ProjectConfig{TestMode: true}
+	setInspectionToken(c, "")
+	require.Len(t, c.InspectionToken, 32)
+
+	// staying in test mode keeps it
+	c = &datastore.ProjectConfig{TestMode: true}
+	setInspectionToken(c, "token")
+	require.Equal(t, "token", c.InspectionToken)
+
+	// and turning it off clears it
+	c = &datastore.ProjectConfig{InspectionToken: "token"}
+	setInspectionToken(c, "token")
+	require.Empty(t, c.InspectionToken)
+}
+
+func TestValidateStrategy(t *testing.T) {
+	require.NoError(t, validateStrategy(&datastore.ProjectConfig{}))
+	require.NoError(t, validateStrategy(&datastore.ProjectConfig{Strategy: &datastore.StrategyConfiguration{Duration: 10, BackoffBase: 1.5, MaxInterval: 10}}))
+
+	err := validateStrategy(&datastore.ProjectConfig{Strategy: &datastore.StrategyConfiguration{Duration: 10, BackoffBase: 1}})
+	require.EqualError(t, err, "strategy backoff base must be greater than 1")
+
+	err = validateStrategy(&datastore.ProjectConfig{Strategy: &datastore.StrategyConfiguration{Duration: 10, MaxInterval: 5}})
+	require.EqualError(t, err, "strategy max interval cannot be less than the strategy duration of 10")
+
+	require.NoError(t, validateStrategy(&datastore.ProjectConfig{Strategy: &datastore.StrategyConfiguration{JitterPercent: 100}}))
+
+	err = validateStrategy(&datastore.ProjectConfig{Strategy: &datastore.StrategyConfiguration{JitterPercent: 101}})
+	require.EqualError(t, err, "strategy jitter percent cannot be greater than 100")
+}
+
+func TestValidateDeliveryMode(t *testing.T) {
+	require.NoError(t, validateDeliveryMode(&datastore.ProjectConfig{}))
+	require.NoError(t, validateDeliveryMode(&datastore.ProjectConfig{DeliveryMode: datastore.AtMostOnceDeliveryMode}))
+
+	err := validateDeliveryMode(&datastore.ProjectConfig{DeliveryMode: "exactly_once"})
+	require.EqualError(t, err, "invalid delivery mode value, must be one of 'at_least_once', 'at_most_once' or 'ordered'")
+}
+
+func TestValidateSignatureVersions(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	withVersions := func(versions ...datastore.SignatureVersion) *datastore.ProjectConfig {
+		return &datastore.ProjectConfig{Signature: &datastore.SignatureConfiguration{Versions: versions}}
+	}
+
+	require.NoError(t, validateSignatureVersions(&datastore.ProjectConfig{}, now))
+	require.NoError(t, validateSignatureVersions(withVersions(datastore.SignatureVersion{}), now))
+
+	// an old version expiring while the new one takes over
+	require.NoError(t, validateSignatureVersions(withVersions(
+		datastore.SignatureVersion{ActiveUntil: null.TimeFrom(now.Add(time.Hour))},
+		datastore.SignatureVersion{ActiveFrom: null.TimeFrom(now.Add(-time.Hour))},
+	), now))
+
+	err := validateSignatureVersions(withVersions(
+		datastore.SignatureVersion{ActiveFrom: null.TimeFrom(now), ActiveUntil: null.TimeFrom(now)},
+	), now)
+	require.EqualError(t, err, "signature version 1 must become active before it expires")
+
+	err = validateSignatureVersions(withVersions(
+		datastore.SignatureVersion{ActiveUntil: null.TimeFrom(now.Add(-time.Hour))},
+		datastore.SignatureVersion{ActiveFrom: null.TimeFrom(now.Add(time.Hour))},
+	), now)
+	require.EqualError(t, err, "at least one signature version must be active")
+}
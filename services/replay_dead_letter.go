@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/worker/task"
+)
+
+// ReplayDeadLetterService manually replays a dead-lettered (failed) event
+// delivery, subject to the project's dead letter replay policy.
+type ReplayDeadLetterService struct {
+	EventDeliveryRepo datastore.EventDeliveryRepository
+	Queue             queue.Queuer
+
+	EventDelivery *datastore.EventDelivery
+	Project       *datastore.Project
+}
+
+func (r *ReplayDeadLetterService) Run(ctx context.Context) error {
+	if r.Project.Config != nil && r.Project.Config.GetDeadLetterReplayConfig().Policy == datastore.NeverDeadLetterReplayPolicy {
+		return &ServiceError{ErrMsg: "dead letter replay is disabled for this project"}
+	}
+
+	if r.EventDelivery.Status != datastore.FailureEventStatus {
+		return &ServiceError{ErrMsg: "only failed event deliveries can be replayed"}
+	}
+
+	err := task.ReplayDeadLetteredDelivery(ctx, r.EventDeliveryRepo, r.Queue, r.EventDelivery)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to replay dead-lettered event delivery")
+		return &ServiceError{ErrMsg: "an error occurred while trying to replay event delivery", Err: err}
+	}
+
+	return nil
+}
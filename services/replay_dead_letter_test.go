@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func provideReplayDeadLetterService(ctrl *gomock.Controller, eventDelivery *datastore.EventDelivery, project *datastore.Project) *ReplayDeadLetterService {
+	return &ReplayDeadLetterService{
+		EventDeliveryRepo: mocks.NewMockEventDeliveryRepository(ctrl),
+		Queue:             mocks.NewMockQueuer(ctrl),
+		EventDelivery:     eventDelivery,
+		Project:           project,
+	}
+}
+
+func projectWithDeadLetterReplayPolicy(policy datastore.DeadLetterReplayPolicy) *datastore.Project {
+	return &datastore.Project{
+		UID: "abc",
+		Config: &datastore.ProjectConfig{
+			DeadLetterReplay: &datastore.DeadLetterReplayConfiguration{Policy: policy},
+		},
+	}
+}
+
+func TestReplayDeadLetterService_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventDelivery *datastore.EventDelivery
+		project       *datastore.Project
+		dbFn          func(rs *ReplayDeadLetterService)
+		wantErr       bool
+		wantErrMsg    string
+	}{
+		{
+			name: "should_replay_on_explicit_call_with_manual_policy",
+			eventDelivery: &datastore.EventDelivery{
+				UID:       "123",
+				ProjectID: "abc",
+				Status:    datastore.FailureEventStatus,
+				Metadata:  &datastore.Metadata{NumTrials: 3, RetryLimit: 3},
+			},
+			project: projectWithDeadLetterReplayPolicy(datastore.ManualDeadLetterReplayPolicy),
+			dbFn: func(rs *ReplayDeadLetterService) {
+				ed, _ := rs.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().UpdateEventDeliveryMetadata(gomock.Any(), "abc", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, d *datastore.EventDelivery) error {
+						require.Equal(t, datastore.ScheduledEventStatus, d.Status)
+						require.Equal(t, uint64(0), d.Metadata.NumTrials)
+						return nil
+					})
+
+				q, _ := rs.Queue.(*mocks.MockQueuer)
+				q.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "should_replay_on_explicit_call_with_auto_policy",
+			eventDelivery: &datastore.EventDelivery{
+				UID:       "123",
+				ProjectID: "abc",
+				Status:    datastore.FailureEventStatus,
+				Metadata:  &datastore.Metadata{NumTrials: 3, RetryLimit: 3},
+			},
+			project: projectWithDeadLetterReplayPolicy(datastore.AutoDeadLetterReplayPolicy),
+			dbFn: func(rs *ReplayDeadLetterService) {
+				ed, _ := rs.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().UpdateEventDeliveryMetadata(gomock.Any(), "abc", gomock.Any()).Return(nil)
+
+				q, _ := rs.Queue.(*mocks.MockQueuer)
+				q.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "should_not_replay_with_never_policy",
+			eventDelivery: &datastore.EventDelivery{
+				UID:    "123",
+				Status: datastore.FailureEventStatus,
+			},
+			project:    projectWithDeadLetterReplayPolicy(datastore.NeverDeadLetterReplayPolicy),
+			wantErr:    true,
+			wantErrMsg: "dead letter replay is disabled for this project",
+		},
+		{
+			name: "should_not_replay_delivery_that_has_not_failed",
+			eventDelivery: &datastore.EventDelivery{
+				UID:    "123",
+				Status: datastore.RetryEventStatus,
+			},
+			project:    projectWithDeadLetterReplayPolicy(datastore.ManualDeadLetterReplayPolicy),
+			wantErr:    true,
+			wantErrMsg: "only failed event deliveries can be replayed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rs := provideReplayDeadLetterService(ctrl, tt.eventDelivery, tt.project)
+
+			if tt.dbFn != nil {
+				tt.dbFn(rs)
+			}
+
+			err := rs.Run(context.Background())
+			if tt.wantErr {
+				require.NotNil(t, err)
+				require.Equal(t, tt.wantErrMsg, err.(*ServiceError).Error())
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -15,8 +15,9 @@ import (
 )
 
 type ReplayEventService struct {
-	EndpointRepo datastore.EndpointRepository
-	Queue        queue.Queuer
+	EndpointRepo      datastore.EndpointRepository
+	EventDeliveryRepo datastore.EventDeliveryRepository
+	Queue             queue.Queuer
 
 	Event *datastore.Event
 }
@@ -35,6 +36,15 @@ func (e *ReplayEventService) Run(ctx context.Context) error {
 	if util.IsStringEmpty(e.Event.UID) || util.IsStringEmpty(e.Event.ProjectID) {
 		return &ServiceError{ErrMsg: "missing event or project id"}
 	}
+
+	// the event's earlier deliveries hold its per-endpoint deduplication
+	// keys, release them so the replay can fan the event out again
+	err = e.EventDeliveryRepo.ReleaseEventDeliveryDeduplicationKeys(ctx, e.Event.ProjectID, e.Event.UID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("replay_event: failed to release event delivery deduplication keys")
+		return &ServiceError{ErrMsg: "failed to release event delivery deduplication keys", Err: err}
+	}
+
 	jobId := fmt.Sprintf("replay:%s:%s", e.Event.ProjectID, e.Event.UID)
 
 	job := &queue.Job{
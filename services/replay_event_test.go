@@ -16,9 +16,10 @@ import (
 
 func provideReplayEventService(ctrl *gomock.Controller, event *datastore.Event) *ReplayEventService {
 	return &ReplayEventService{
-		EndpointRepo: mocks.NewMockEndpointRepository(ctrl),
-		Queue:        mocks.NewMockQueuer(ctrl),
-		Event:        event,
+		EndpointRepo:      mocks.NewMockEndpointRepository(ctrl),
+		EventDeliveryRepo: mocks.NewMockEventDeliveryRepository(ctrl),
+		Queue:             mocks.NewMockQueuer(ctrl),
+		Event:             event,
 	}
 }
 
@@ -44,6 +45,9 @@ func TestReplayEventService_Run(t *testing.T) {
 				g:     &datastore.Project{UID: "123", Name: "test_project"},
 			},
 			dbFn: func(es *ReplayEventService) {
+				ed, _ := es.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().ReleaseEventDeliveryDeduplicationKeys(gomock.Any(), "proj1", "123").Times(1).Return(nil)
+
 				eq, _ := es.Queue.(*mocks.MockQueuer)
 				eq.EXPECT().Write(convoy.CreateEventProcessor, gomock.Any(), gomock.Any()).
 					Times(1).Return(nil)
@@ -58,6 +62,9 @@ func TestReplayEventService_Run(t *testing.T) {
 				g:     &datastore.Project{UID: "123", Name: "test_project"},
 			},
 			dbFn: func(es *ReplayEventService) {
+				ed, _ := es.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().ReleaseEventDeliveryDeduplicationKeys(gomock.Any(), "proj1", "123").Times(1).Return(nil)
+
 				eq, _ := es.Queue.(*mocks.MockQueuer)
 				eq.EXPECT().Write(convoy.CreateEventProcessor, gomock.Any(), gomock.Any()).
 					Times(1).Return(errors.New("failed"))
@@ -65,6 +72,20 @@ func TestReplayEventService_Run(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "failed to write event to queue",
 		},
+		{
+			name: "should_fail_to_release_deduplication_keys",
+			args: args{
+				ctx:   ctx,
+				event: &datastore.Event{UID: "123", ProjectID: "proj1"},
+				g:     &datastore.Project{UID: "123", Name: "test_project"},
+			},
+			dbFn: func(es *ReplayEventService) {
+				ed, _ := es.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().ReleaseEventDeliveryDeduplicationKeys(gomock.Any(), "proj1", "123").Times(1).Return(errors.New("failed"))
+			},
+			wantErr:    true,
+			wantErrMsg: "failed to release event delivery deduplication keys",
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
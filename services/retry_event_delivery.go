@@ -51,10 +51,12 @@ func (e *RetryEventDeliveryService) Run(ctx context.Context) error {
 		}
 	}
 
-	return requeueEventDelivery(ctx, e.EventDelivery, e.Project, e.EventDeliveryRepo, e.Queue)
+	return requeueEventDelivery(ctx, e.EventDelivery, e.Project, e.EventDeliveryRepo, e.Queue, true)
 }
 
-func requeueEventDelivery(ctx context.Context, eventDelivery *datastore.EventDelivery, g *datastore.Project, ed datastore.EventDeliveryRepository, q queue.Queuer) error {
+// requeueEventDelivery schedules an event delivery and queues it again, as a
+// manual retry when manualRetry is set
+func requeueEventDelivery(ctx context.Context, eventDelivery *datastore.EventDelivery, g *datastore.Project, ed datastore.EventDeliveryRepository, q queue.Queuer, manualRetry bool) error {
 	eventDelivery.Status = datastore.ScheduledEventStatus
 	err := ed.UpdateStatusOfEventDelivery(ctx, g.UID, *eventDelivery, datastore.ScheduledEventStatus)
 	if err != nil {
@@ -62,10 +64,17 @@ func requeueEventDelivery(ctx context.Context, eventDelivery *datastore.EventDel
 		return &ServiceError{ErrMsg: "an error occurred while trying to resend event", Err: err}
 	}
 
+	return enqueueEventDelivery(ctx, eventDelivery, g, q, manualRetry)
+}
+
+// enqueueEventDelivery queues a retry of an event delivery that's already
+// scheduled, manual retries can be configured to skip the circuit breaker
+func enqueueEventDelivery(ctx context.Context, eventDelivery *datastore.EventDelivery, g *datastore.Project, q queue.Queuer, manualRetry bool) error {
 	taskName := convoy.EventProcessor
 	payload := task.EventDelivery{
 		EventDeliveryID: eventDelivery.UID,
 		ProjectID:       g.UID,
+		ManualRetry:     manualRetry,
 	}
 
 	bytes, err := msgpack.EncodeMsgPack(payload)
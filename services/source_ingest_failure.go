@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/notifications"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/queue"
+)
+
+// RecordSourceIngestFailureService counts an authenticated request ingested
+// through Source whose payload couldn't be read. The first failure at or
+// past the source's ingest failure threshold pauses it and notifies the
+// support contacts of the endpoints subscribed to it.
+type RecordSourceIngestFailureService struct {
+	SourceRepo       datastore.SourceRepository
+	SubscriptionRepo datastore.SubscriptionRepository
+	EndpointRepo     datastore.EndpointRepository
+	Queue            queue.Queuer
+
+	Project *datastore.Project
+	Source  *datastore.Source
+	Reason  error
+}
+
+func (r *RecordSourceIngestFailureService) Run(ctx context.Context) error {
+	if r.Source.IngestFailureThreshold == 0 || r.Source.Paused {
+		return nil
+	}
+
+	failures, err := r.SourceRepo.IncrementSourceIngestFailures(ctx, r.Project.UID, r.Source.UID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to record source ingest failure")
+		return &ServiceError{ErrMsg: "failed to record source ingest failure", Err: err}
+	}
+
+	if failures < r.Source.IngestFailureThreshold {
+		return nil
+	}
+
+	// failures ingested at the same time all try to pause the source, only
+	// the one that pauses it notifies
+	paused, err := r.SourceRepo.PauseSource(ctx, r.Project.UID, r.Source.UID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to pause source")
+		return &ServiceError{ErrMsg: "failed to pause source", Err: err}
+	}
+
+	if !paused {
+		return nil
+	}
+
+	log.FromContext(ctx).Infof("paused source %s after %d ingest failures in a row", r.Source.UID, failures)
+
+	endpoints, err := r.subscribedEndpoints(ctx)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to load the endpoints subscribed to the source")
+		return &ServiceError{ErrMsg: "failed to load the endpoints subscribed to the source", Err: err}
+	}
+
+	return notifications.SendSourcePausedNotification(ctx, r.Source, r.Project, endpoints, r.Queue, failures, r.Reason.Error())
+}
+
+func (r *RecordSourceIngestFailureService) subscribedEndpoints(ctx context.Context) ([]*datastore.Endpoint, error) {
+	subscriptions, err := r.SubscriptionRepo.FindSubscriptionsBySourceID(ctx, r.Project.UID, r.Source.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(subscriptions))
+	endpoints := make([]*datastore.Endpoint, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		if s.EndpointID == "" || seen[s.EndpointID] {
+			continue
+		}
+		seen[s.EndpointID] = true
+
+		endpoint, err := r.EndpointRepo.FindEndpointByID(ctx, s.EndpointID, r.Project.UID)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/notifications"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func provideRecordSourceIngestFailureService(ctrl *gomock.Controller, source *datastore.Source) *RecordSourceIngestFailureService {
+	return &RecordSourceIngestFailureService{
+		SourceRepo:       mocks.NewMockSourceRepository(ctrl),
+		SubscriptionRepo: mocks.NewMockSubscriptionRepository(ctrl),
+		EndpointRepo:     mocks.NewMockEndpointRepository(ctrl),
+		Queue:            mocks.NewMockQueuer(ctrl),
+		Project:          &datastore.Project{UID: "project-1"},
+		Source:           source,
+		Reason:           errors.New("invalid signature header"),
+	}
+}
+
+func TestRecordSourceIngestFailureService_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should_count_failures_below_the_threshold", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := provideRecordSourceIngestFailureService(ctrl, &datastore.Source{UID: "source-1", IngestFailureThreshold: 3})
+
+		s.SourceRepo.(*mocks.MockSourceRepository).EXPECT().
+			IncrementSourceIngestFailures(gomock.Any(), "project-1", "source-1").Return(uint64(2), nil)
+
+		require.NoError(t, s.Run(ctx))
+	})
+
+	t.Run("should_pause_the_source_and_notify_at_the_threshold", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := provideRecordSourceIngestFailureService(ctrl, &datastore.Source{UID: "source-1", Name: "github", IngestFailureThreshold: 3})
+
+		sr, _ := s.SourceRepo.(*mocks.MockSourceRepository)
+		sr.EXPECT().IncrementSourceIngestFailures(gomock.Any(), "project-1", "source-1").Return(uint64(3), nil)
+		sr.EXPECT().PauseSource(gomock.Any(), "project-1", "source-1").Return(true, nil)
+
+		// two subscriptions to the same endpoint notify it once
+		subRepo, _ := s.SubscriptionRepo.(*mocks.MockSubscriptionRepository)
+		subRepo.EXPECT().FindSubscriptionsBySourceID(gomock.Any(), "project-1", "source-1").Return([]datastore.Subscription{
+			{UID: "sub-1", EndpointID: "endpoint-1"},
+			{UID: "sub-2", EndpointID: "endpoint-1"},
+		}, nil)
+
+		endpointRepo, _ := s.EndpointRepo.(*mocks.MockEndpointRepository)
+		endpointRepo.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-1", "project-1").
+			Return(&datastore.Endpoint{UID: "endpoint-1", SupportEmail: "ops@example.com"}, nil)
+
+		q, _ := s.Queue.(*mocks.MockQueuer)
+		q.EXPECT().Write(convoy.NotificationProcessor, convoy.DefaultQueue, gomock.Any()).
+			DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+				var n notifications.Notification
+				require.NoError(t, msgpack.DecodeMsgPack(job.Payload, &n))
+				require.Equal(t, notifications.EmailNotificationType, n.NotificationType)
+				return nil
+			})
+
+		require.NoError(t, s.Run(ctx))
+	})
+
+	t.Run("should_not_notify_when_another_failure_paused_the_source", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := provideRecordSourceIngestFailureService(ctrl, &datastore.Source{UID: "source-1", IngestFailureThreshold: 3})
+
+		sr, _ := s.SourceRepo.(*mocks.MockSourceRepository)
+		sr.EXPECT().IncrementSourceIngestFailures(gomock.Any(), "project-1", "source-1").Return(uint64(4), nil)
+		sr.EXPECT().PauseSource(gomock.Any(), "project-1", "source-1").Return(false, nil)
+
+		require.NoError(t, s.Run(ctx))
+	})
+
+	t.Run("should_pause_the_source_past_the_threshold", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// the threshold was lowered below the failures already counted
+		s := provideRecordSourceIngestFailureService(ctrl, &datastore.Source{UID: "source-1", IngestFailureThreshold: 3})
+
+		sr, _ := s.SourceRepo.(*mocks.MockSourceRepository)
+		sr.EXPECT().IncrementSourceIngestFailures(gomock.Any(), "project-1", "source-1").Return(uint64(5), nil)
+		sr.EXPECT().PauseSource(gomock.Any(), "project-1", "source-1").Return(true, nil)
+
+		subRepo, _ := s.SubscriptionRepo.(*mocks.MockSubscriptionRepository)
+		subRepo.EXPECT().FindSubscriptionsBySourceID(gomock.Any(), "project-1", "source-1").Return([]datastore.Subscription{}, nil)
+
+		require.NoError(t, s.Run(ctx))
+	})
+
+	t.Run("should_do_nothing_without_a_threshold", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := provideRecordSourceIngestFailureService(ctrl, &datastore.Source{UID: "source-1"})
+		require.NoError(t, s.Run(ctx))
+	})
+
+	t.Run("should_fail_to_pause_the_source", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := provideRecordSourceIngestFailureService(ctrl, &datastore.Source{UID: "source-1", IngestFailureThreshold: 1})
+
+		sr, _ := s.SourceRepo.(*mocks.MockSourceRepository)
+		sr.EXPECT().IncrementSourceIngestFailures(gomock.Any(), "project-1", "source-1").Return(uint64(1), nil)
+		sr.EXPECT().PauseSource(gomock.Any(), "project-1", "source-1").Return(false, errors.New("failed"))
+
+		err := s.Run(ctx)
+		require.Error(t, err)
+		require.Equal(t, "failed to pause source", err.(*ServiceError).Error())
+	})
+}
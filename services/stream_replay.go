@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/queue"
+)
+
+const (
+	defaultReplayBatchSize = 1000
+	replayCheckpointTTL    = 7 * 24 * time.Hour
+)
+
+var ErrInvalidReplayRate = errors.New("replay rate must be positive")
+
+// ReplayCheckpoint is how far a streaming replay got. It is saved after
+// every page and when the replay is paused, a replay run again with the
+// same id resumes after LastID.
+type ReplayCheckpoint struct {
+	LastID   string `json:"last_id"`
+	Replayed int64  `json:"replayed"`
+	Failed   int64  `json:"failed"`
+	Done     bool   `json:"done"`
+}
+
+// ReplayCheckpointStore saves the checkpoints of streaming replays
+type ReplayCheckpointStore interface {
+	LoadCheckpoint(ctx context.Context, replayID string) (*ReplayCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, replayID string, checkpoint *ReplayCheckpoint) error
+}
+
+type cacheReplayCheckpointStore struct {
+	cache cache.Cache
+}
+
+// NewCacheReplayCheckpointStore keeps replay checkpoints in c for a week
+func NewCacheReplayCheckpointStore(c cache.Cache) ReplayCheckpointStore {
+	return &cacheReplayCheckpointStore{cache: c}
+}
+
+func replayCheckpointKey(replayID string) string {
+	return "replay_checkpoints:" + replayID
+}
+
+func (c *cacheReplayCheckpointStore) LoadCheckpoint(ctx context.Context, replayID string) (*ReplayCheckpoint, error) {
+	var checkpoint ReplayCheckpoint
+	err := c.cache.Get(ctx, replayCheckpointKey(replayID), &checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
+
+func (c *cacheReplayCheckpointStore) SaveCheckpoint(ctx context.Context, replayID string, checkpoint *ReplayCheckpoint) error {
+	return c.cache.Set(ctx, replayCheckpointKey(replayID), checkpoint, replayCheckpointTTL)
+}
+
+// StreamReplayService replays every delivery of a project matching Filter,
+// streaming them a page at a time and enqueueing at most RatePerSecond
+// deliveries a second, so replaying millions of deliveries doesn't flood
+// the queue. Cancelling the context pauses the replay, running it again
+// with the same ReplayID resumes where it stopped.
+type StreamReplayService struct {
+	EventDeliveryRepo datastore.EventDeliveryRepository
+	Queue             queue.Queuer
+	Checkpoints       ReplayCheckpointStore
+
+	ReplayID      string
+	Project       *datastore.Project
+	Filter        *datastore.EventDeliveryFilter
+	RatePerSecond float64
+	BatchSize     int
+}
+
+func (s *StreamReplayService) Run(ctx context.Context) (*ReplayCheckpoint, error) {
+	if s.RatePerSecond <= 0 {
+		return nil, &ServiceError{ErrMsg: ErrInvalidReplayRate.Error()}
+	}
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReplayBatchSize
+	}
+
+	checkpoint, err := s.Checkpoints.LoadCheckpoint(ctx, s.ReplayID)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to load replay checkpoint")
+		return nil, &ServiceError{ErrMsg: "failed to load replay checkpoint", Err: err}
+	}
+
+	if checkpoint.Done {
+		return checkpoint, nil
+	}
+
+	it := datastore.NewEventDeliveryIterator(s.EventDeliveryRepo, s.Project.UID, s.Filter, checkpoint.LastID, batchSize)
+
+	// the ticker drops ticks while an enqueue is slow, so a slow queue
+	// never leads to a burst above the rate once it recovers
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / s.RatePerSecond))
+	defer ticker.Stop()
+
+	for {
+		deliveries, err := it.Next(ctx)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("failed to load event deliveries to replay")
+			s.saveCheckpoint(ctx, checkpoint)
+			return checkpoint, &ServiceError{ErrMsg: "failed to load event deliveries", Err: err}
+		}
+
+		if len(deliveries) == 0 {
+			break
+		}
+
+		for i := range deliveries {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+			}
+
+			if ctx.Err() != nil {
+				s.saveCheckpoint(ctx, checkpoint)
+				return checkpoint, ctx.Err()
+			}
+
+			// replays are queued like scheduled retries, so a failing
+			// endpoint's circuit breaker still holds them back
+			err = requeueEventDelivery(ctx, &deliveries[i], s.Project, s.EventDeliveryRepo, s.Queue, false)
+			if err != nil {
+				checkpoint.Failed++
+				log.FromContext(ctx).WithError(err).Errorf("failed to replay event delivery %s", deliveries[i].UID)
+			} else {
+				checkpoint.Replayed++
+			}
+
+			checkpoint.LastID = deliveries[i].UID
+		}
+
+		s.saveCheckpoint(ctx, checkpoint)
+	}
+
+	checkpoint.Done = true
+	s.saveCheckpoint(ctx, checkpoint)
+
+	return checkpoint, nil
+}
+
+// saveCheckpoint saves the replay's progress, it still saves when ctx was
+// cancelled to pause the replay
+func (s *StreamReplayService) saveCheckpoint(ctx context.Context, checkpoint *ReplayCheckpoint) {
+	err := s.Checkpoints.SaveCheckpoint(context.WithoutCancel(ctx), s.ReplayID, checkpoint)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("failed to save checkpoint of replay %s", s.ReplayID)
+	}
+}
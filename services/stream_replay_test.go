@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/worker/task"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type memoryReplayCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]ReplayCheckpoint
+	saves       int
+}
+
+func (m *memoryReplayCheckpointStore) LoadCheckpoint(_ context.Context, replayID string) (*ReplayCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	checkpoint := m.checkpoints[replayID]
+	return &checkpoint, nil
+}
+
+func (m *memoryReplayCheckpointStore) SaveCheckpoint(_ context.Context, replayID string, checkpoint *ReplayCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checkpoints[replayID] = *checkpoint
+	m.saves++
+	return nil
+}
+
+// expectReplayPages serves deliveries ed-1 to ed-n from the keyset query,
+// in pages that start after the cursor they are given
+func expectReplayPages(t *testing.T, repo *mocks.MockEventDeliveryRepository, n int) {
+	repo.EXPECT().FindEventDeliveriesAfterID(gomock.Any(), "project-1", gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ *datastore.EventDeliveryFilter, afterID string, limit int) ([]datastore.EventDelivery, error) {
+			start := 0
+			if afterID != "" {
+				_, err := fmt.Sscanf(afterID, "ed-%d", &start)
+				require.NoError(t, err)
+			}
+
+			page := make([]datastore.EventDelivery, 0, limit)
+			for i := start + 1; i <= n && len(page) < limit; i++ {
+				page = append(page, datastore.EventDelivery{UID: fmt.Sprintf("ed-%d", i), ProjectID: "project-1"})
+			}
+			return page, nil
+		}).AnyTimes()
+
+	repo.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), "project-1", gomock.Any(), datastore.ScheduledEventStatus).
+		Return(nil).AnyTimes()
+}
+
+func provideStreamReplayService(ctrl *gomock.Controller, store *memoryReplayCheckpointStore) *StreamReplayService {
+	return &StreamReplayService{
+		EventDeliveryRepo: mocks.NewMockEventDeliveryRepository(ctrl),
+		Queue:             mocks.NewMockQueuer(ctrl),
+		Checkpoints:       store,
+		ReplayID:          "replay-1",
+		Project:           &datastore.Project{UID: "project-1"},
+		Filter:            &datastore.EventDeliveryFilter{ProjectID: "project-1"},
+		RatePerSecond:     100,
+		BatchSize:         2,
+	}
+}
+
+func TestStreamReplayService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := &memoryReplayCheckpointStore{checkpoints: map[string]ReplayCheckpoint{}}
+	s := provideStreamReplayService(ctrl, store)
+
+	expectReplayPages(t, s.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository), 5)
+
+	var enqueued []time.Time
+	s.Queue.(*mocks.MockQueuer).EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, _ interface{}, job *queue.Job) error {
+			enqueued = append(enqueued, time.Now())
+
+			// replays aren't manual retries
+			var payload task.EventDelivery
+			require.NoError(t, msgpack.DecodeMsgPack(job.Payload, &payload))
+			require.False(t, payload.ManualRetry)
+			return nil
+		}).Times(5)
+
+	start := time.Now()
+	checkpoint, err := s.Run(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, ReplayCheckpoint{LastID: "ed-5", Replayed: 5, Done: true}, *checkpoint)
+	require.Equal(t, *checkpoint, store.checkpoints["replay-1"])
+
+	// one save per page, the last page is short, then the completion
+	require.Equal(t, 4, store.saves)
+
+	// at 100 a second, the nth delivery isn't enqueued before n*10ms
+	for i := range enqueued {
+		require.GreaterOrEqual(t, enqueued[i].Sub(start), time.Duration(i+1)*10*time.Millisecond)
+	}
+}
+
+func TestStreamReplayService_RunPauseAndResume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := &memoryReplayCheckpointStore{checkpoints: map[string]ReplayCheckpoint{}}
+	s := provideStreamReplayService(ctrl, store)
+
+	expectReplayPages(t, s.EventDeliveryRepo.(*mocks.MockEventDeliveryRepository), 7)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var enqueued []string
+	s.Queue.(*mocks.MockQueuer).EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, _ interface{}, job *queue.Job) error {
+			enqueued = append(enqueued, job.ID)
+			// pause the replay in the middle of the second page
+			if len(enqueued) == 3 {
+				cancel()
+			}
+			return nil
+		}).Times(7)
+
+	checkpoint, err := s.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, ReplayCheckpoint{LastID: "ed-3", Replayed: 3}, *checkpoint)
+	require.Equal(t, *checkpoint, store.checkpoints["replay-1"])
+
+	checkpoint, err = s.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ReplayCheckpoint{LastID: "ed-7", Replayed: 7, Done: true}, *checkpoint)
+
+	require.Equal(t, []string{"ed-1", "ed-2", "ed-3", "ed-4", "ed-5", "ed-6", "ed-7"}, enqueued)
+
+	// a finished replay isn't replayed again
+	checkpoint, err = s.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, checkpoint.Done)
+	require.Len(t, enqueued, 7)
+}
+
+func TestStreamReplayService_RunInvalidRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := &memoryReplayCheckpointStore{checkpoints: map[string]ReplayCheckpoint{}}
+	s := provideStreamReplayService(ctrl, store)
+	s.RatePerSecond = 0
+
+	_, err := s.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, ErrInvalidReplayRate.Error(), err.Error())
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+// SubscriptionExportVersion is bumped whenever the export document changes
+// in a way older importers can't read.
+const SubscriptionExportVersion = 1
+
+const exportSubscriptionsPageSize = 100
+
+// SubscriptionExport is the document written by ExportSubscriptionsService
+// and read by ImportSubscriptionsService.
+type SubscriptionExport struct {
+	Version       int                    `json:"version"`
+	ProjectID     string                 `json:"project_id"`
+	ExportedAt    time.Time              `json:"exported_at"`
+	Subscriptions []ExportedSubscription `json:"subscriptions"`
+}
+
+// ExportedSubscription is a subscription with its endpoint and source
+// referenced by id, name and url, so they can be remapped on import.
+type ExportedSubscription struct {
+	UID      string                      `json:"uid"`
+	Name     string                      `json:"name"`
+	Type     datastore.SubscriptionType  `json:"type"`
+	Function string                      `json:"function,omitempty"`
+	Endpoint *ExportedEndpointRef        `json:"endpoint,omitempty"`
+	Source   *ExportedSourceRef          `json:"source,omitempty"`
+	Filter   *ExportedSubscriptionFilter `json:"filter,omitempty"`
+
+	AlertConfig     *datastore.AlertConfiguration     `json:"alert_config,omitempty"`
+	RetryConfig     *datastore.RetryConfiguration     `json:"retry_config,omitempty"`
+	RateLimitConfig *datastore.RateLimitConfiguration `json:"rate_limit_config,omitempty"`
+
+	DeliveryMode     datastore.DeliveryMode `json:"delivery_mode,omitempty"`
+	HeaderAllowList  []string               `json:"header_allow_list,omitempty"`
+	PayloadTransform string                 `json:"payload_transform,omitempty"`
+	DiffKey          string                 `json:"diff_key,omitempty"`
+	Paused           bool                   `json:"paused"`
+
+	// EventTypeFilters are the per event type filters, they are exported
+	// unflattened as they were written
+	EventTypeFilters []ExportedEventTypeFilter `json:"event_type_filters,omitempty"`
+}
+
+type ExportedEndpointRef struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type ExportedSourceRef struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+}
+
+type ExportedSubscriptionFilter struct {
+	EventTypes []string    `json:"event_types"`
+	Headers    datastore.M `json:"headers"`
+	Body       datastore.M `json:"body"`
+}
+
+type ExportedEventTypeFilter struct {
+	EventType string      `json:"event_type"`
+	Headers   datastore.M `json:"headers"`
+	Body      datastore.M `json:"body"`
+}
+
+// ExportSubscriptionsService serializes every api subscription in a project
+// along with its event type filters. CLI and device subscriptions are tied to
+// the instance they were created on, so they're left out.
+type ExportSubscriptionsService struct {
+	SubRepo    datastore.SubscriptionRepository
+	FilterRepo datastore.FilterRepository
+	ProjectID  string
+}
+
+func (e *ExportSubscriptionsService) Run(ctx context.Context) (*SubscriptionExport, error) {
+	export := &SubscriptionExport{
+		Version:       SubscriptionExportVersion,
+		ProjectID:     e.ProjectID,
+		ExportedAt:    time.Now(),
+		Subscriptions: []ExportedSubscription{},
+	}
+
+	pageable := datastore.Pageable{PerPage: exportSubscriptionsPageSize, Direction: datastore.Next}
+	pageable.SetCursors()
+
+	for {
+		subscriptions, paginationData, err := e.SubRepo.LoadSubscriptionsPaged(ctx, e.ProjectID, &datastore.FilterBy{}, pageable)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("failed to load subscriptions")
+			return nil, &ServiceError{ErrMsg: "failed to load subscriptions", Err: err}
+		}
+
+		for i := range subscriptions {
+			sub := &subscriptions[i]
+			if sub.Type != datastore.SubscriptionTypeAPI {
+				continue
+			}
+
+			filters, err := e.FilterRepo.FindFiltersBySubscriptionID(ctx, sub.UID)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Errorf("failed to find filters of subscription %s", sub.UID)
+				return nil, &ServiceError{ErrMsg: "failed to find subscription filters", Err: err}
+			}
+
+			export.Subscriptions = append(export.Subscriptions, exportSubscription(sub, filters))
+		}
+
+		if !paginationData.HasNextPage {
+			break
+		}
+		pageable.NextCursor = paginationData.NextPageCursor
+	}
+
+	return export, nil
+}
+
+func exportSubscription(sub *datastore.Subscription, filters []datastore.EventTypeFilter) ExportedSubscription {
+	s := ExportedSubscription{
+		UID:             sub.UID,
+		Name:            sub.Name,
+		Type:            sub.Type,
+		Function:        sub.Function.ValueOrZero(),
+		AlertConfig:     sub.AlertConfig,
+		RetryConfig:     sub.RetryConfig,
+		RateLimitConfig: sub.RateLimitConfig,
+		DeliveryMode:    sub.DeliveryMode,
+		HeaderAllowList: sub.HeaderAllowList,
+		Paused:          sub.Paused,
+
+		PayloadTransform: sub.PayloadTransform,
+		DiffKey:          sub.DiffKey,
+	}
+
+	if sub.Endpoint != nil {
+		s.Endpoint = &ExportedEndpointRef{UID: sub.Endpoint.UID, Name: sub.Endpoint.Name, URL: sub.Endpoint.Url}
+	} else if sub.EndpointID != "" {
+		s.Endpoint = &ExportedEndpointRef{UID: sub.EndpointID}
+	}
+
+	if sub.Source != nil {
+		s.Source = &ExportedSourceRef{UID: sub.Source.UID, Name: sub.Source.Name}
+	} else if sub.SourceID != "" {
+		s.Source = &ExportedSourceRef{UID: sub.SourceID}
+	}
+
+	if sub.FilterConfig != nil {
+		s.Filter = &ExportedSubscriptionFilter{
+			EventTypes: sub.FilterConfig.EventTypes,
+			Headers:    sub.FilterConfig.Filter.RawHeaders,
+			Body:       sub.FilterConfig.Filter.RawBody,
+		}
+	}
+
+	for _, f := range filters {
+		s.EventTypeFilters = append(s.EventTypeFilters, ExportedEventTypeFilter{
+			EventType: f.EventType,
+			Headers:   f.RawHeaders,
+			Body:      f.RawBody,
+		})
+	}
+
+	return s
+}
+
+// SubscriptionImportResult reports what an import created and what it had to
+// skip because an endpoint or source couldn't be found in the target project.
+type SubscriptionImportResult struct {
+	// Created maps the exported subscription ids to the ids they were
+	// created with
+	Created              map[string]string     `json:"created"`
+	UnmappedEndpoints    []ExportedEndpointRef `json:"unmapped_endpoints"`
+	UnmappedSources      []ExportedSourceRef   `json:"unmapped_sources"`
+	SkippedSubscriptions []string              `json:"skipped_subscriptions"`
+}
+
+// ImportSubscriptionsService recreates exported subscriptions in a project.
+// An exported endpoint is mapped through EndpointMap first and then by its url,
+// sources are mapped by name. Subscriptions whose endpoint or source can't be
+// mapped are skipped and reported, the rest are created with new ids.
+type ImportSubscriptionsService struct {
+	SubRepo      datastore.SubscriptionRepository
+	FilterRepo   datastore.FilterRepository
+	EndpointRepo datastore.EndpointRepository
+	SourceRepo   datastore.SourceRepository
+
+	ProjectID string
+	Export    *SubscriptionExport
+
+	// EndpointMap maps exported endpoint ids to endpoint ids in the project
+	EndpointMap map[string]string
+
+	// DryRun maps endpoints and sources without creating anything
+	DryRun bool
+}
+
+func (i *ImportSubscriptionsService) Run(ctx context.Context) (*SubscriptionImportResult, error) {
+	if i.Export == nil {
+		return nil, &ServiceError{ErrMsg: "subscription export is empty"}
+	}
+
+	if i.Export.Version > SubscriptionExportVersion {
+		return nil, &ServiceError{ErrMsg: fmt.Sprintf("unsupported subscription export version %d", i.Export.Version)}
+	}
+
+	result := &SubscriptionImportResult{Created: map[string]string{}}
+	endpoints := map[string]string{}
+	sources := map[string]string{}
+	unmappedEndpoints := map[string]bool{}
+	unmappedSources := map[string]bool{}
+
+	for n := range i.Export.Subscriptions {
+		exported := &i.Export.Subscriptions[n]
+
+		endpointID, err := i.mapEndpoint(ctx, exported.Endpoint, endpoints)
+		if err != nil {
+			return result, err
+		}
+
+		sourceID, err := i.mapSource(ctx, exported.Source, sources)
+		if err != nil {
+			return result, err
+		}
+
+		if exported.Endpoint != nil && endpointID == "" {
+			if !unmappedEndpoints[exported.Endpoint.UID] {
+				unmappedEndpoints[exported.Endpoint.UID] = true
+				result.UnmappedEndpoints = append(result.UnmappedEndpoints, *exported.Endpoint)
+			}
+		}
+
+		if exported.Source != nil && sourceID == "" {
+			if !unmappedSources[exported.Source.UID] {
+				unmappedSources[exported.Source.UID] = true
+				result.UnmappedSources = append(result.UnmappedSources, *exported.Source)
+			}
+		}
+
+		if (exported.Endpoint != nil && endpointID == "") || (exported.Source != nil && sourceID == "") {
+			result.SkippedSubscriptions = append(result.SkippedSubscriptions, exported.UID)
+			continue
+		}
+
+		sub := importedSubscription(exported, i.ProjectID, endpointID, sourceID)
+		if i.DryRun {
+			result.Created[exported.UID] = sub.UID
+			continue
+		}
+
+		err = i.SubRepo.CreateSubscription(ctx, i.ProjectID, sub)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("failed to create subscription %s", exported.Name)
+			return result, &ServiceError{ErrMsg: ErrCreateSubscriptionError.Error(), Err: err}
+		}
+
+		// the event type filters are created with the subscription's filter,
+		// the exported ones replace them
+		err = i.importFilters(ctx, sub.UID, exported.EventTypeFilters)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("failed to import the filters of subscription %s", exported.Name)
+			return result, &ServiceError{ErrMsg: "failed to import subscription filters", Err: err}
+		}
+
+		if exported.Paused {
+			err = i.SubRepo.UpdateSubscriptionPaused(ctx, i.ProjectID, sub.UID, true)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Errorf("failed to pause subscription %s", exported.Name)
+				return result, &ServiceError{ErrMsg: "failed to pause subscription", Err: err}
+			}
+			sub.Paused = true
+		}
+
+		result.Created[exported.UID] = sub.UID
+	}
+
+	return result, nil
+}
+
+// mapEndpoint returns the id of the project's endpoint for ref, or an empty
+// string when there isn't one.
+func (i *ImportSubscriptionsService) mapEndpoint(ctx context.Context, ref *ExportedEndpointRef, mapped map[string]string) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+
+	if id, ok := mapped[ref.UID]; ok {
+		return id, nil
+	}
+
+	var endpoint *datastore.Endpoint
+	var err error
+	if id, ok := i.EndpointMap[ref.UID]; ok {
+		endpoint, err = i.EndpointRepo.FindEndpointByID(ctx, id, i.ProjectID)
+	} else if ref.URL != "" {
+		endpoint, err = i.EndpointRepo.FindEndpointByTargetURL(ctx, i.ProjectID, ref.URL)
+	} else {
+		err = datastore.ErrEndpointNotFound
+	}
+
+	switch {
+	case err == nil:
+		mapped[ref.UID] = endpoint.UID
+	case errors.Is(err, datastore.ErrEndpointNotFound):
+		mapped[ref.UID] = ""
+	default:
+		log.FromContext(ctx).WithError(err).Error("failed to find endpoint")
+		return "", &ServiceError{ErrMsg: "failed to find endpoint", Err: err}
+	}
+
+	return mapped[ref.UID], nil
+}
+
+// mapSource returns the id of the project's source named like ref, or an
+// empty string when there isn't one.
+func (i *ImportSubscriptionsService) mapSource(ctx context.Context, ref *ExportedSourceRef, mapped map[string]string) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+
+	if id, ok := mapped[ref.UID]; ok {
+		return id, nil
+	}
+
+	source, err := i.SourceRepo.FindSourceByName(ctx, i.ProjectID, ref.Name)
+	switch {
+	case err == nil:
+		mapped[ref.UID] = source.UID
+	case errors.Is(err, datastore.ErrSourceNotFound):
+		mapped[ref.UID] = ""
+	default:
+		log.FromContext(ctx).WithError(err).Error("failed to find source")
+		return "", &ServiceError{ErrMsg: "failed to find source", Err: err}
+	}
+
+	return mapped[ref.UID], nil
+}
+
+func (i *ImportSubscriptionsService) importFilters(ctx context.Context, subscriptionID string, exported []ExportedEventTypeFilter) error {
+	if len(exported) == 0 {
+		return nil
+	}
+
+	existing, err := i.FilterRepo.FindFiltersBySubscriptionID(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	byEventType := make(map[string]*datastore.EventTypeFilter, len(existing))
+	for n := range existing {
+		byEventType[existing[n].EventType] = &existing[n]
+	}
+
+	var filters []datastore.EventTypeFilter
+	for _, f := range exported {
+		filter := datastore.EventTypeFilter{
+			UID:            ulid.Make().String(),
+			SubscriptionID: subscriptionID,
+			EventType:      f.EventType,
+			Headers:        copyM(f.Headers),
+			Body:           copyM(f.Body),
+			RawHeaders:     f.Headers,
+			RawBody:        f.Body,
+		}
+
+		if e, ok := byEventType[f.EventType]; ok {
+			filter.UID = e.UID
+			err = i.FilterRepo.UpdateFilter(ctx, &filter)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 0 {
+		return nil
+	}
+
+	return i.FilterRepo.CreateFilters(ctx, filters)
+}
+
+func importedSubscription(exported *ExportedSubscription, projectID, endpointID, sourceID string) *datastore.Subscription {
+	sub := &datastore.Subscription{
+		UID:             ulid.Make().String(),
+		Name:            exported.Name,
+		Type:            datastore.SubscriptionTypeAPI,
+		ProjectID:       projectID,
+		EndpointID:      endpointID,
+		SourceID:        sourceID,
+		AlertConfig:     exported.AlertConfig,
+		RetryConfig:     exported.RetryConfig,
+		RateLimitConfig: exported.RateLimitConfig,
+		DeliveryMode:    exported.DeliveryMode,
+		HeaderAllowList: exported.HeaderAllowList,
+
+		PayloadTransform: exported.PayloadTransform,
+		DiffKey:          exported.DiffKey,
+		FilterConfig: &datastore.FilterConfiguration{
+			EventTypes: []string{"*"},
+			Filter: datastore.FilterSchema{
+				Headers:    datastore.M{},
+				Body:       datastore.M{},
+				RawHeaders: datastore.M{},
+				RawBody:    datastore.M{},
+			},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if exported.Function != "" {
+		sub.Function = null.StringFrom(exported.Function)
+	}
+
+	if f := exported.Filter; f != nil {
+		if len(f.EventTypes) > 0 {
+			sub.FilterConfig.EventTypes = f.EventTypes
+		}
+
+		if len(f.Headers) > 0 || len(f.Body) > 0 {
+			sub.FilterConfig.Filter = datastore.FilterSchema{
+				Headers:    copyM(f.Headers),
+				Body:       copyM(f.Body),
+				RawHeaders: f.Headers,
+				RawBody:    f.Body,
+			}
+		}
+	}
+
+	return sub
+}
+
+// copyM returns a shallow copy of m, the repos flatten filters in place and
+// the raw filters must be kept as they are.
+func copyM(m datastore.M) datastore.M {
+	c := make(datastore.M, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
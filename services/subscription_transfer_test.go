@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func exportedProjectSubscriptions() []datastore.Subscription {
+	return []datastore.Subscription{
+		{
+			UID:        "sub-1",
+			Name:       "orders",
+			Type:       datastore.SubscriptionTypeAPI,
+			ProjectID:  "source-project",
+			EndpointID: "endpoint-1",
+			Endpoint:   &datastore.Endpoint{UID: "endpoint-1", Name: "orders", Url: "https://orders.example.com"},
+			Function:   null.StringFrom("function transform(payload) { return payload }"),
+			RetryConfig: &datastore.RetryConfiguration{
+				Type:       datastore.ExponentialStrategyProvider,
+				Duration:   10,
+				RetryCount: 5,
+			},
+			RateLimitConfig: &datastore.RateLimitConfiguration{Count: 100, Duration: 60},
+			FilterConfig: &datastore.FilterConfiguration{
+				EventTypes: []string{"order.created", "order.paid"},
+				Filter: datastore.FilterSchema{
+					Headers:    datastore.M{"x-tenant": "acme"},
+					Body:       datastore.M{"order.total": datastore.M{"$gte": 10}},
+					RawHeaders: datastore.M{"x-tenant": "acme"},
+					RawBody:    datastore.M{"order": datastore.M{"total": datastore.M{"$gte": 10}}},
+				},
+			},
+			DeliveryMode:    datastore.AtMostOnceDeliveryMode,
+			HeaderAllowList: []string{"x-request-id"},
+			Paused:          true,
+		},
+		{
+			UID:        "sub-2",
+			Name:       "invoices",
+			Type:       datastore.SubscriptionTypeAPI,
+			ProjectID:  "source-project",
+			EndpointID: "endpoint-2",
+			Endpoint:   &datastore.Endpoint{UID: "endpoint-2", Name: "invoices", Url: "https://invoices.example.com"},
+			FilterConfig: &datastore.FilterConfiguration{
+				EventTypes: []string{"*"},
+			},
+		},
+		{
+			UID:        "sub-3",
+			Name:       "cli",
+			Type:       datastore.SubscriptionTypeCLI,
+			ProjectID:  "source-project",
+			EndpointID: "endpoint-1",
+		},
+	}
+}
+
+func TestSubscriptionExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	filterRepo := mocks.NewMockFilterRepository(ctrl)
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	sourceRepo := mocks.NewMockSourceRepository(ctrl)
+
+	subRepo.EXPECT().LoadSubscriptionsPaged(gomock.Any(), "source-project", gomock.Any(), gomock.Any()).Times(1).
+		Return(exportedProjectSubscriptions()[:1], datastore.PaginationData{HasNextPage: true, NextPageCursor: "sub-1"}, nil)
+	subRepo.EXPECT().LoadSubscriptionsPaged(gomock.Any(), "source-project", gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ string, _ *datastore.FilterBy, pageable datastore.Pageable) ([]datastore.Subscription, datastore.PaginationData, error) {
+			require.Equal(t, "sub-1", pageable.NextCursor)
+			return exportedProjectSubscriptions()[1:], datastore.PaginationData{}, nil
+		})
+
+	paidFilter := datastore.EventTypeFilter{
+		UID:            "filter-2",
+		SubscriptionID: "sub-1",
+		EventType:      "order.paid",
+		Headers:        datastore.M{"x-tenant": "acme"},
+		Body:           datastore.M{"order.currency": "EUR"},
+		RawHeaders:     datastore.M{"x-tenant": "acme"},
+		RawBody:        datastore.M{"order": datastore.M{"currency": "EUR"}},
+	}
+	filterRepo.EXPECT().FindFiltersBySubscriptionID(gomock.Any(), "sub-1").Times(1).Return([]datastore.EventTypeFilter{paidFilter}, nil)
+	filterRepo.EXPECT().FindFiltersBySubscriptionID(gomock.Any(), "sub-2").Times(1).Return(nil, nil)
+
+	export, err := (&ExportSubscriptionsService{SubRepo: subRepo, FilterRepo: filterRepo, ProjectID: "source-project"}).Run(ctx)
+	require.NoError(t, err)
+	require.Len(t, export.Subscriptions, 2)
+
+	// the export goes through json on its way to the other instance
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+
+	var imported SubscriptionExport
+	require.NoError(t, json.Unmarshal(data, &imported))
+
+	// endpoint-1 is mapped explicitly, endpoint-2 has no endpoint with its url
+	endpointRepo.EXPECT().FindEndpointByID(gomock.Any(), "target-endpoint-1", "target-project").Times(1).
+		Return(&datastore.Endpoint{UID: "target-endpoint-1", ProjectID: "target-project"}, nil)
+	endpointRepo.EXPECT().FindEndpointByTargetURL(gomock.Any(), "target-project", "https://invoices.example.com").Times(1).
+		Return(nil, datastore.ErrEndpointNotFound)
+
+	var created *datastore.Subscription
+	subRepo.EXPECT().CreateSubscription(gomock.Any(), "target-project", gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ string, s *datastore.Subscription) error {
+			created = s
+			return nil
+		})
+	subRepo.EXPECT().UpdateSubscriptionPaused(gomock.Any(), "target-project", gomock.Any(), true).Times(1).Return(nil)
+
+	filterRepo.EXPECT().FindFiltersBySubscriptionID(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, id string) ([]datastore.EventTypeFilter, error) {
+			return []datastore.EventTypeFilter{
+				{UID: "default-created", SubscriptionID: id, EventType: "order.created"},
+				{UID: "default-paid", SubscriptionID: id, EventType: "order.paid"},
+			}, nil
+		})
+
+	var updated *datastore.EventTypeFilter
+	filterRepo.EXPECT().UpdateFilter(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, f *datastore.EventTypeFilter) error {
+			updated = f
+			return nil
+		})
+
+	result, err := (&ImportSubscriptionsService{
+		SubRepo:      subRepo,
+		FilterRepo:   filterRepo,
+		EndpointRepo: endpointRepo,
+		SourceRepo:   sourceRepo,
+		ProjectID:    "target-project",
+		Export:       &imported,
+		EndpointMap:  map[string]string{"endpoint-1": "target-endpoint-1"},
+	}).Run(ctx)
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	require.Equal(t, map[string]string{"sub-1": created.UID}, result.Created)
+	require.NotEqual(t, "sub-1", created.UID)
+
+	want := exportedProjectSubscriptions()[0]
+	require.Equal(t, "target-project", created.ProjectID)
+	require.Equal(t, "target-endpoint-1", created.EndpointID)
+	require.Equal(t, want.Name, created.Name)
+	require.Equal(t, want.Function, created.Function)
+	require.Equal(t, want.RetryConfig, created.RetryConfig)
+	require.Equal(t, want.RateLimitConfig, created.RateLimitConfig)
+	require.Equal(t, want.DeliveryMode, created.DeliveryMode)
+	require.Equal(t, []string(want.HeaderAllowList), []string(created.HeaderAllowList))
+	require.Equal(t, want.FilterConfig.EventTypes, created.FilterConfig.EventTypes)
+	requireJSONEqual(t, want.FilterConfig.Filter.RawHeaders, created.FilterConfig.Filter.RawHeaders)
+	requireJSONEqual(t, want.FilterConfig.Filter.RawBody, created.FilterConfig.Filter.RawBody)
+	require.True(t, created.Paused)
+
+	require.NotNil(t, updated)
+	require.Equal(t, "default-paid", updated.UID)
+	requireJSONEqual(t, paidFilter.RawBody, updated.RawBody)
+	requireJSONEqual(t, paidFilter.RawHeaders, updated.RawHeaders)
+
+	require.Equal(t, []ExportedEndpointRef{{UID: "endpoint-2", Name: "invoices", URL: "https://invoices.example.com"}}, result.UnmappedEndpoints)
+	require.Equal(t, []string{"sub-2"}, result.SkippedSubscriptions)
+}
+
+func TestImportSubscriptionsService_DryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	endpointRepo.EXPECT().FindEndpointByTargetURL(gomock.Any(), "target-project", "https://orders.example.com").Times(1).
+		Return(&datastore.Endpoint{UID: "target-endpoint-1"}, nil)
+
+	export := &SubscriptionExport{
+		Version: SubscriptionExportVersion,
+		Subscriptions: []ExportedSubscription{
+			{UID: "sub-1", Name: "orders", Endpoint: &ExportedEndpointRef{UID: "endpoint-1", URL: "https://orders.example.com"}},
+			{UID: "sub-2", Name: "orders-audit", Endpoint: &ExportedEndpointRef{UID: "endpoint-1", URL: "https://orders.example.com"}},
+			{UID: "sub-3", Name: "missing", Endpoint: &ExportedEndpointRef{UID: "endpoint-9"}},
+			{UID: "sub-4", Name: "missing-again", Endpoint: &ExportedEndpointRef{UID: "endpoint-9"}},
+		},
+	}
+
+	// nothing is created, the sub repo would fail the test if it were called
+	result, err := (&ImportSubscriptionsService{
+		SubRepo:      mocks.NewMockSubscriptionRepository(ctrl),
+		FilterRepo:   mocks.NewMockFilterRepository(ctrl),
+		EndpointRepo: endpointRepo,
+		SourceRepo:   mocks.NewMockSourceRepository(ctrl),
+		ProjectID:    "target-project",
+		Export:       export,
+		DryRun:       true,
+	}).Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, result.Created, 2)
+	require.Equal(t, []ExportedEndpointRef{{UID: "endpoint-9"}}, result.UnmappedEndpoints)
+	require.Equal(t, []string{"sub-3", "sub-4"}, result.SkippedSubscriptions)
+}
+
+func requireJSONEqual(t *testing.T, want, got interface{}) {
+	t.Helper()
+
+	w, err := json.Marshal(want)
+	require.NoError(t, err)
+
+	g, err := json.Marshal(got)
+	require.NoError(t, err)
+
+	require.JSONEq(t, string(w), string(g))
+}
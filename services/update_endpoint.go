@@ -97,6 +97,9 @@ func (a *UpdateEndpointService) ValidateEndpoint(ctx context.Context, enforceSec
 			net.AllowListOption(cfg.Dispatcher.AllowList),
 			net.BlockListOption(cfg.Dispatcher.BlockList),
 			net.TLSConfigOption(cfg.Dispatcher.InsecureSkipVerify, a.Licenser, caCertTLSCfg),
+			net.ForceHTTP2Option(cfg.Dispatcher.ForceHTTP2),
+			net.ConnectionPoolOption(cfg.Dispatcher.MaxIdleConnsPerHost, cfg.Dispatcher.GetIdleConnTimeout()),
+			net.TransientRetryOption(cfg.Dispatcher.TransientRetries),
 		)
 		if innerErr != nil {
 			return "", innerErr
@@ -137,6 +140,49 @@ func (a *UpdateEndpointService) updateEndpoint(endpoint *datastore.Endpoint, e m
 		endpoint.AdvancedSignatures = *e.AdvancedSignatures
 	}
 
+	if !util.IsStringEmpty(string(e.BodyFormat)) {
+		endpoint.BodyFormat = e.BodyFormat
+	}
+
+	if !util.IsStringEmpty(e.HttpMethod) {
+		endpoint.HttpMethod = e.HttpMethod
+	}
+
+	if !util.IsStringEmpty(e.ContentType) {
+		endpoint.ContentType = e.ContentType
+	}
+
+	if !util.IsStringEmpty(string(e.FormEncoding)) {
+		endpoint.FormEncoding = e.FormEncoding
+	}
+
+	if e.MaxConcurrentDeliveries != nil {
+		endpoint.MaxConcurrentDeliveries = *e.MaxConcurrentDeliveries
+	}
+
+	if e.MinDeliveryIntervalMs != nil {
+		endpoint.MinDeliveryIntervalMs = *e.MinDeliveryIntervalMs
+	}
+
+	if e.RetryAfterFloorSeconds != nil {
+		endpoint.RetryAfterFloorSeconds = *e.RetryAfterFloorSeconds
+	}
+
+	if e.RetryAfterCeilingSeconds != nil {
+		endpoint.RetryAfterCeilingSeconds = *e.RetryAfterCeilingSeconds
+	}
+
+	if endpoint.RetryAfterCeilingSeconds > 0 && endpoint.RetryAfterFloorSeconds > endpoint.RetryAfterCeilingSeconds {
+		return nil, errors.New("retry after floor cannot be above its ceiling")
+	}
+
+	if e.PinnedSignatureVersion != nil {
+		if err := validatePinnedSignatureVersion(project, *e.PinnedSignatureVersion); err != nil {
+			return nil, err
+		}
+		endpoint.PinnedSignatureVersion = *e.PinnedSignatureVersion
+	}
+
 	if e.HttpTimeout != 0 {
 		endpoint.HttpTimeout = e.HttpTimeout
 
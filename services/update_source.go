@@ -70,6 +70,18 @@ func (s *UpdateSourceService) Run(ctx context.Context) (*datastore.Source, error
 		s.Source.HeaderFunction = s.SourceUpdate.HeaderFunction
 	}
 
+	if s.SourceUpdate.IngestFailureThreshold != nil {
+		s.Source.IngestFailureThreshold = *s.SourceUpdate.IngestFailureThreshold
+	}
+
+	// unpausing the source also resets its run of ingest failures
+	if s.SourceUpdate.Paused != nil {
+		s.Source.Paused = *s.SourceUpdate.Paused
+		if !s.Source.Paused {
+			s.Source.ConsecutiveIngestFailures = 0
+		}
+	}
+
 	err := s.SourceRepo.UpdateSource(ctx, s.Project.UID, s.Source)
 	if err != nil {
 		log.FromContext(ctx).WithError(err).Error("failed to update source")
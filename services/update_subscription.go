@@ -94,12 +94,24 @@ func (s *UpdateSubscriptionService) Run(ctx context.Context) (*datastore.Subscri
 	}
 
 	if !util.IsStringEmpty(string(s.Update.DeliveryMode)) {
-		if s.Update.DeliveryMode != datastore.AtLeastOnceDeliveryMode && s.Update.DeliveryMode != datastore.AtMostOnceDeliveryMode {
-			return nil, &ServiceError{ErrMsg: "invalid delivery mode value, must be either 'at_least_once' or 'at_most_once'"}
+		if !s.Update.DeliveryMode.IsValid() {
+			return nil, &ServiceError{ErrMsg: "invalid delivery mode value, must be one of 'at_least_once', 'at_most_once' or 'ordered'"}
 		}
 		subscription.DeliveryMode = s.Update.DeliveryMode
 	}
 
+	if s.Update.HeaderAllowList != nil {
+		subscription.HeaderAllowList = s.Update.HeaderAllowList
+	}
+
+	if s.Update.PayloadTransform != nil {
+		subscription.PayloadTransform = *s.Update.PayloadTransform
+	}
+
+	if s.Update.DiffKey != nil {
+		subscription.DiffKey = *s.Update.DiffKey
+	}
+
 	if s.Update.AlertConfig != nil && s.Update.AlertConfig.Count > 0 {
 		if subscription.AlertConfig == nil {
 			subscription.AlertConfig = &datastore.AlertConfiguration{}
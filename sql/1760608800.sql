@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS header_allow_list TEXT[];
+COMMENT ON COLUMN convoy.subscriptions.header_allow_list IS 'Source request headers forwarded onto the event deliveries of this subscription. Every header is forwarded when empty';
+
+-- +migrate Down
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS header_allow_list;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS dead_letter_replay_policy TEXT NOT NULL DEFAULT 'manual';
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS dead_letter_replay_after_hours INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS dead_letter_replay_after_hours;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS dead_letter_replay_policy;
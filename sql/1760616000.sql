@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS body_format TEXT NOT NULL DEFAULT 'raw';
+COMMENT ON COLUMN convoy.endpoints.body_format IS 'How event payloads are sent to the endpoint, either raw or wrapped in a metadata envelope';
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS body_format;
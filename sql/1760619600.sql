@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS signature_unsigned_event_types TEXT[] NOT NULL DEFAULT '{}';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS signature_unsigned_event_types;
@@ -0,0 +1,22 @@
+-- +migrate Up
+ALTER TABLE convoy.event_deliveries ADD COLUMN IF NOT EXISTS deduplication_key TEXT DEFAULT NULL;
+
+-- unique indexes on a partitioned table must include the partition key,
+-- so deduplication is only enforced on the unpartitioned table
+-- +migrate StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = 'convoy' AND c.relname = 'event_deliveries' AND c.relkind = 'r'
+    ) THEN
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deliveries_deduplication_key
+            ON convoy.event_deliveries (deduplication_key) WHERE deduplication_key IS NOT NULL;
+    END IF;
+END;
+$$;
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_deduplication_key;
+ALTER TABLE convoy.event_deliveries DROP COLUMN IF EXISTS deduplication_key;
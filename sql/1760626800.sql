@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS signature_proxy_url TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS signature_proxy_url;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS pinned_signature_version INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.endpoints.pinned_signature_version IS 'The only project signature version sent to the endpoint, 0 sends all of them';
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS pinned_signature_version;
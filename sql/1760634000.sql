@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS http_method TEXT NOT NULL DEFAULT 'POST';
+COMMENT ON COLUMN convoy.endpoints.http_method IS 'The HTTP method webhooks are sent to the endpoint with';
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS http_method;
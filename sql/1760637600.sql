@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS content_idempotency_keys BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN convoy.project_configurations.content_idempotency_keys IS 'Derive the idempotency key sent with deliveries from the event content';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS content_idempotency_keys;
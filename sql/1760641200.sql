@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN convoy.subscriptions.paused IS 'Event deliveries of a paused subscription are rescheduled instead of being sent';
+
+-- +migrate Down
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS paused;
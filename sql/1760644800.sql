@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS max_concurrent_deliveries INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.endpoints.max_concurrent_deliveries IS 'The most deliveries that can be in flight to the endpoint at once, 0 means unlimited';
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS max_concurrent_deliveries;
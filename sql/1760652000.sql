@@ -0,0 +1,19 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ALTER COLUMN delivery_mode DROP NOT NULL;
+ALTER TABLE convoy.subscriptions ALTER COLUMN delivery_mode DROP DEFAULT;
+COMMENT ON COLUMN convoy.subscriptions.delivery_mode IS 'Overrides the project delivery mode for the subscription, NULL inherits it. Can be either at_least_once or at_most_once';
+
+-- pending deliveries follow the delivery mode of their subscription
+UPDATE convoy.event_deliveries ed SET delivery_mode = s.delivery_mode
+FROM convoy.subscriptions s
+WHERE ed.subscription_id = s.id
+AND s.delivery_mode IS NOT NULL
+AND ed.delivery_mode <> s.delivery_mode
+AND ed.status IN ('Scheduled', 'Retry')
+AND ed.deleted_at IS NULL;
+
+-- +migrate Down
+UPDATE convoy.subscriptions SET delivery_mode = 'at_least_once' WHERE delivery_mode IS NULL;
+ALTER TABLE convoy.subscriptions ALTER COLUMN delivery_mode SET DEFAULT 'at_least_once';
+ALTER TABLE convoy.subscriptions ALTER COLUMN delivery_mode SET NOT NULL;
+COMMENT ON COLUMN convoy.subscriptions.delivery_mode IS 'Specifies the delivery mode for the subscription. Can be either at_least_once or at_most_once';
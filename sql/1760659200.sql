@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS max_retry_seconds BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.project_configurations.max_retry_seconds IS 'Ceiling on the delay between retries, 0 falls back to the instance setting';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS max_retry_seconds;
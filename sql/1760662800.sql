@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS min_delivery_interval_ms BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.endpoints.min_delivery_interval_ms IS 'The least time between the start of two deliveries to the endpoint, 0 means deliveries are not spaced';
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS min_delivery_interval_ms;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS retry_after_floor_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS retry_after_ceiling_seconds BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.endpoints.retry_after_floor_seconds IS 'The least delay a Retry-After header from the endpoint can set, 0 means no floor';
+COMMENT ON COLUMN convoy.endpoints.retry_after_ceiling_seconds IS 'The most delay a Retry-After header from the endpoint can set, 0 means no ceiling';
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS retry_after_floor_seconds;
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS retry_after_ceiling_seconds;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS metadata_headers TEXT[] NOT NULL DEFAULT '{}';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS metadata_headers;
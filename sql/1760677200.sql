@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS payload_transform TEXT NOT NULL DEFAULT '';
+COMMENT ON COLUMN convoy.subscriptions.payload_transform IS 'gjson path the event payload is reshaped with before it is signed and delivered, empty means the payload is sent as is';
+
+-- +migrate Down
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS payload_transform;
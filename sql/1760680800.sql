@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS delivery_mode TEXT NOT NULL DEFAULT 'at_least_once';
+COMMENT ON COLUMN convoy.project_configurations.delivery_mode IS 'Delivery mode of subscriptions that do not set their own. Can be either at_least_once or at_most_once';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS delivery_mode;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS signature_secret_epoch_header BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS signature_sign_secret_epoch BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS signature_sign_secret_epoch;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS signature_secret_epoch_header;
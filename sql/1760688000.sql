@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints
+    ADD COLUMN IF NOT EXISTS authentication_type_digest_username TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS authentication_type_digest_password TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS authentication_type_digest_password_cipher bytea;
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints
+    DROP COLUMN IF EXISTS authentication_type_digest_username,
+    DROP COLUMN IF EXISTS authentication_type_digest_password,
+    DROP COLUMN IF EXISTS authentication_type_digest_password_cipher;
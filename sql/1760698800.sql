@@ -0,0 +1,19 @@
+-- +migrate Up
+ALTER TABLE convoy.subscriptions ADD COLUMN IF NOT EXISTS diff_key TEXT NOT NULL DEFAULT '';
+COMMENT ON COLUMN convoy.subscriptions.diff_key IS 'gjson path to the entity key of event payloads, when set deliveries carry only the fields changed since the entity''s previous event';
+
+CREATE TABLE IF NOT EXISTS convoy.delivery_entity_snapshots (
+    subscription_id  VARCHAR NOT NULL REFERENCES convoy.subscriptions (id) ON DELETE CASCADE,
+    entity_key       TEXT NOT NULL,
+    project_id       VARCHAR NOT NULL,
+    event_id         VARCHAR NOT NULL,
+    payload          BYTEA NOT NULL,
+    previous_payload BYTEA,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subscription_id, entity_key)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS convoy.delivery_entity_snapshots;
+ALTER TABLE convoy.subscriptions DROP COLUMN IF EXISTS diff_key;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS content_type TEXT NOT NULL DEFAULT 'application/json';
+ALTER TABLE convoy.endpoints ADD COLUMN IF NOT EXISTS form_encoding TEXT NOT NULL DEFAULT 'fields';
+COMMENT ON COLUMN convoy.endpoints.content_type IS 'The content type webhooks are sent to the endpoint with';
+COMMENT ON COLUMN convoy.endpoints.form_encoding IS 'How payloads are form encoded when the content type is application/x-www-form-urlencoded';
+
+-- +migrate Down
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS form_encoding;
+ALTER TABLE convoy.endpoints DROP COLUMN IF EXISTS content_type;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS strategy_backoff_base DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS strategy_max_interval INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.project_configurations.strategy_backoff_base IS 'What the exponential retry interval is multiplied by after every attempt, 0 means 2';
+COMMENT ON COLUMN convoy.project_configurations.strategy_max_interval IS 'The most seconds the exponential strategy waits between two attempts, 0 means unset';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS strategy_max_interval;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS strategy_backoff_base;
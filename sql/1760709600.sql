@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS strategy_jitter_percent INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.project_configurations.strategy_jitter_percent IS 'The most percent a retry delay is randomly moved either way, 0 disables jitter';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS strategy_jitter_percent;
@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS ingest_failure_threshold INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS consecutive_ingest_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE convoy.sources ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN convoy.sources.ingest_failure_threshold IS 'How many ingests in a row can fail before the source is paused, 0 never pauses it';
+COMMENT ON COLUMN convoy.sources.consecutive_ingest_failures IS 'How many ingests in a row have failed since the last one that succeeded';
+COMMENT ON COLUMN convoy.sources.paused IS 'Whether the source rejects everything ingested through it';
+
+-- +migrate Down
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS paused;
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS consecutive_ingest_failures;
+ALTER TABLE convoy.sources DROP COLUMN IF EXISTS ingest_failure_threshold;
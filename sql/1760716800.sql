@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS max_concurrent_deliveries BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN convoy.project_configurations.max_concurrent_deliveries IS 'The most deliveries of the project that can be in flight at once, 0 is unlimited';
+
+-- +migrate Down
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS max_concurrent_deliveries;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS convoy.endpoint_recoveries (
+    endpoint_id   VARCHAR PRIMARY KEY REFERENCES convoy.endpoints (id) ON DELETE CASCADE,
+    project_id    VARCHAR NOT NULL,
+    attempts      INTEGER NOT NULL DEFAULT 0,
+    next_probe_at TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+COMMENT ON TABLE convoy.endpoint_recoveries IS 'The health probes sent to disabled endpoints, an endpoint''s row is removed once it is re-enabled';
+
+CREATE INDEX IF NOT EXISTS idx_endpoint_recoveries_project_id ON convoy.endpoint_recoveries (project_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS convoy.endpoint_recoveries;
@@ -0,0 +1,24 @@
+-- +migrate Up
+-- unique indexes on a partitioned table must include its partition key, so
+-- deliveries are deduplicated among those created at the same time. Fan-out
+-- creates an event's deliveries at the event's creation time, which a
+-- retried fan-out shares.
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_deduplication_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deliveries_deduplication_key
+    ON convoy.event_deliveries (deduplication_key, project_id, created_at) WHERE deduplication_key IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_deduplication_key;
+-- +migrate StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = 'convoy' AND c.relname = 'event_deliveries' AND c.relkind = 'r'
+    ) THEN
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deliveries_deduplication_key
+            ON convoy.event_deliveries (deduplication_key) WHERE deduplication_key IS NOT NULL;
+    END IF;
+END;
+$$;
+-- +migrate StatementEnd
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- acknowledged_at is set when a delivery is created and is what its latency
+-- is measured from, a device's receipt of the delivery is recorded apart
+ALTER TABLE convoy.event_deliveries ADD COLUMN IF NOT EXISTS device_acknowledged_at TIMESTAMPTZ DEFAULT NULL;
+
+-- +migrate Down
+ALTER TABLE convoy.event_deliveries DROP COLUMN IF EXISTS device_acknowledged_at;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE convoy.delivery_entity_snapshots ADD COLUMN IF NOT EXISTS event_created_at TIMESTAMPTZ;
+COMMENT ON COLUMN convoy.delivery_entity_snapshots.event_created_at IS 'created_at of the event the snapshot was taken from, older events arriving later don''t replace it';
+
+-- +migrate Down
+ALTER TABLE convoy.delivery_entity_snapshots DROP COLUMN IF EXISTS event_created_at;
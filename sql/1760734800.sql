@@ -0,0 +1,5 @@
+-- +migrate Up
+COMMENT ON TABLE convoy.endpoint_recoveries IS 'The health probes sent to endpoints the circuit breaker disabled, an endpoint''s row is removed once it is re-enabled';
+
+-- +migrate Down
+COMMENT ON TABLE convoy.endpoint_recoveries IS 'The health probes sent to disabled endpoints, an endpoint''s row is removed once it is re-enabled';
@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE convoy.project_configurations ADD COLUMN IF NOT EXISTS inspection_token VARCHAR NOT NULL DEFAULT '';
+COMMENT ON COLUMN convoy.project_configurations.test_mode IS 'Turns on the project''s inspection url, which captures test deliveries';
+COMMENT ON COLUMN convoy.project_configurations.inspection_token IS 'The secret token in the project''s inspection url, empty when test mode is off';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_configurations_inspection_token ON convoy.project_configurations (inspection_token) WHERE inspection_token <> '';
+
+-- +migrate Down
+DROP INDEX IF EXISTS convoy.idx_project_configurations_inspection_token;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS inspection_token;
+ALTER TABLE convoy.project_configurations DROP COLUMN IF EXISTS test_mode;
@@ -0,0 +1,31 @@
+-- +migrate Up
+-- deduplication keys are claimed in a table of their own, unique indexes on
+-- the partitioned event_deliveries table would have to include created_at
+-- and only catch duplicates created at the same time.
+CREATE TABLE IF NOT EXISTS convoy.event_delivery_deduplication_keys (
+    project_id        VARCHAR NOT NULL,
+    deduplication_key TEXT NOT NULL,
+    event_id          VARCHAR NOT NULL,
+    event_delivery_id VARCHAR NOT NULL,
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, deduplication_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_delivery_deduplication_keys_event_id
+    ON convoy.event_delivery_deduplication_keys (project_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_event_delivery_deduplication_keys_created_at
+    ON convoy.event_delivery_deduplication_keys (created_at);
+
+INSERT INTO convoy.event_delivery_deduplication_keys (project_id, deduplication_key, event_id, event_delivery_id, created_at)
+SELECT DISTINCT ON (project_id, deduplication_key) project_id, deduplication_key, event_id, id, created_at
+FROM convoy.event_deliveries
+WHERE deduplication_key IS NOT NULL AND deleted_at IS NULL
+ORDER BY project_id, deduplication_key, created_at
+ON CONFLICT DO NOTHING;
+
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_deduplication_key;
+
+-- +migrate Down
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deliveries_deduplication_key
+    ON convoy.event_deliveries (deduplication_key, project_id, created_at) WHERE deduplication_key IS NOT NULL;
+DROP TABLE IF EXISTS convoy.event_delivery_deduplication_keys;
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- deliveries are inserted with ON CONFLICT (id, created_at, project_id), the
+-- partitioned table's primary key, so the unpartitioned table needs a unique
+-- index on the same columns as a conflict target.
+-- +migrate StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = 'convoy' AND c.relname = 'event_deliveries' AND c.relkind = 'r'
+    ) THEN
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deliveries_id_created_at_project_id
+            ON convoy.event_deliveries (id, created_at, project_id);
+    END IF;
+END;
+$$;
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP INDEX IF EXISTS convoy.idx_event_deliveries_id_created_at_project_id;
@@ -0,0 +1,14 @@
+-- +migrate Up notransaction
+-- ordered deliveries are delivered at least once, and are held while an
+-- earlier delivery to the same endpoint is still pending
+ALTER TYPE convoy.delivery_mode ADD VALUE IF NOT EXISTS 'ordered';
+COMMENT ON COLUMN convoy.subscriptions.delivery_mode IS 'Overrides the project delivery mode for the subscription, NULL inherits it. Can be at_least_once, at_most_once or ordered';
+COMMENT ON COLUMN convoy.project_configurations.delivery_mode IS 'Delivery mode of subscriptions that do not set their own. Can be at_least_once, at_most_once or ordered';
+
+-- +migrate Down
+-- enum values can't be dropped, ordered deliveries fall back to at least once
+UPDATE convoy.subscriptions SET delivery_mode = 'at_least_once' WHERE delivery_mode = 'ordered';
+UPDATE convoy.project_configurations SET delivery_mode = 'at_least_once' WHERE delivery_mode = 'ordered';
+UPDATE convoy.event_deliveries SET delivery_mode = 'at_least_once' WHERE delivery_mode = 'ordered';
+COMMENT ON COLUMN convoy.subscriptions.delivery_mode IS 'Overrides the project delivery mode for the subscription, NULL inherits it. Can be either at_least_once or at_most_once';
+COMMENT ON COLUMN convoy.project_configurations.delivery_mode IS 'Delivery mode of subscriptions that do not set their own. Can be either at_least_once or at_most_once';
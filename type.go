@@ -100,6 +100,8 @@ const (
 	DeleteArchivedTasksProcessor     TaskName = "DeleteArchivedTasksProcessor"
 	MatchEventSubscriptionsProcessor TaskName = "MatchEventSubscriptionsProcessor"
 	BatchRetryProcessor              TaskName = "BatchRetryProcessor"
+	ReplayDeadLettersProcessor       TaskName = "ReplayDeadLettersProcessor"
+	RecoverEndpointsProcessor        TaskName = "RecoverEndpointsProcessor"
 
 	TokenCacheKey CacheKey = "tokens"
 )
@@ -50,6 +50,10 @@ func NewConsumer(ctx context.Context, consumerPoolSize int, q queue.Queuer, lo l
 					return false
 				}
 
+				if _, ok := err.(*task.PausedError); ok {
+					return false
+				}
+
 				return true
 			},
 			RetryDelayFunc: task.GetRetryDelay,
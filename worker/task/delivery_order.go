@@ -0,0 +1,43 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+const (
+	// orderedDeliveryDelay is how soon an ordered delivery held behind an
+	// earlier one checks again whether it can be sent.
+	orderedDeliveryDelay = 5 * time.Second
+
+	// orderedDeliveryMaxWait bounds how long an ordered delivery is held, past
+	// it the delivery is sent anyway so one stuck delivery can't hold back
+	// every delivery to its endpoint.
+	orderedDeliveryMaxWait = 10 * time.Minute
+)
+
+// holdOrderedDelivery reports whether an ordered delivery has to wait for an
+// earlier delivery to the same endpoint. When it's sent instead, whether the
+// earlier ones were all done is recorded in its metadata, so consumers can
+// tell a delivery that kept its order from one that overtook another.
+func holdOrderedDelivery(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, eventDelivery *datastore.EventDelivery, now time.Time) (bool, error) {
+	if eventDelivery.DeliveryMode != datastore.OrderedDeliveryMode {
+		return false, nil
+	}
+
+	pending, err := eventDeliveryRepo.HasPendingEventDeliveriesBefore(ctx, eventDelivery)
+	if err != nil {
+		return false, err
+	}
+
+	if pending && now.Sub(eventDelivery.CreatedAt) < orderedDeliveryMaxWait {
+		return true, nil
+	}
+
+	preserved := !pending
+	eventDelivery.Metadata.OrderingPreserved = &preserved
+
+	return false, nil
+}
@@ -0,0 +1,102 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHoldOrderedDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	newDelivery := func(id, endpointID string, createdAt time.Time) *datastore.EventDelivery {
+		return &datastore.EventDelivery{
+			UID:          id,
+			ProjectID:    "project-id-1",
+			EndpointID:   endpointID,
+			DeliveryMode: datastore.OrderedDeliveryMode,
+			Metadata:     &datastore.Metadata{},
+			CreatedAt:    createdAt,
+		}
+	}
+
+	// stands in for the deliveries still pending
+	pending := map[string]*datastore.EventDelivery{}
+	ed := mocks.NewMockEventDeliveryRepository(ctrl)
+	ed.EXPECT().HasPendingEventDeliveriesBefore(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, delivery *datastore.EventDelivery) (bool, error) {
+			for _, p := range pending {
+				if p.EndpointID == delivery.EndpointID && p.CreatedAt.Before(delivery.CreatedAt) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}).AnyTimes()
+
+	t.Run("same endpoint, serialized", func(t *testing.T) {
+		first := newDelivery("ed-1", "endpoint-id-1", now.Add(-2*time.Second))
+		second := newDelivery("ed-2", "endpoint-id-1", now.Add(-time.Second))
+		pending[first.UID] = first
+		defer delete(pending, first.UID)
+
+		hold, err := holdOrderedDelivery(context.Background(), ed, second, now)
+		require.NoError(t, err)
+		require.True(t, hold)
+		require.Nil(t, second.Metadata.OrderingPreserved)
+
+		// the earlier delivery is done, so the held one goes out in order
+		delete(pending, first.UID)
+		hold, err = holdOrderedDelivery(context.Background(), ed, second, now.Add(orderedDeliveryDelay))
+		require.NoError(t, err)
+		require.False(t, hold)
+		require.NotNil(t, second.Metadata.OrderingPreserved)
+		require.True(t, *second.Metadata.OrderingPreserved)
+	})
+
+	t.Run("same endpoint, concurrent", func(t *testing.T) {
+		first := newDelivery("ed-1", "endpoint-id-1", now.Add(-2*orderedDeliveryMaxWait))
+		second := newDelivery("ed-2", "endpoint-id-1", now.Add(-orderedDeliveryMaxWait))
+		pending[first.UID] = first
+		defer delete(pending, first.UID)
+
+		// held for as long as it may be, it overtakes the earlier delivery
+		hold, err := holdOrderedDelivery(context.Background(), ed, second, now)
+		require.NoError(t, err)
+		require.False(t, hold)
+		require.NotNil(t, second.Metadata.OrderingPreserved)
+		require.False(t, *second.Metadata.OrderingPreserved)
+	})
+
+	t.Run("different endpoints", func(t *testing.T) {
+		first := newDelivery("ed-1", "endpoint-id-1", now.Add(-2*time.Second))
+		other := newDelivery("ed-3", "endpoint-id-2", now.Add(-time.Second))
+		pending[first.UID] = first
+		defer delete(pending, first.UID)
+
+		hold, err := holdOrderedDelivery(context.Background(), ed, other, now)
+		require.NoError(t, err)
+		require.False(t, hold)
+		require.NotNil(t, other.Metadata.OrderingPreserved)
+		require.True(t, *other.Metadata.OrderingPreserved)
+	})
+
+	t.Run("unordered delivery", func(t *testing.T) {
+		first := newDelivery("ed-1", "endpoint-id-1", now.Add(-2*time.Second))
+		unordered := newDelivery("ed-4", "endpoint-id-1", now.Add(-time.Second))
+		unordered.DeliveryMode = datastore.AtLeastOnceDeliveryMode
+		pending[first.UID] = first
+		defer delete(pending, first.UID)
+
+		hold, err := holdOrderedDelivery(context.Background(), ed, unordered, now)
+		require.NoError(t, err)
+		require.False(t, hold)
+		require.Nil(t, unordered.Metadata.OrderingPreserved)
+	})
+}
@@ -0,0 +1,89 @@
+package task
+
+import (
+	"context"
+	"errors"
+	stdnet "net"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+const deliveryTracerName = "github.com/frain-dev/convoy/worker/task"
+
+// startAttemptSpan starts the span of one delivery attempt as a child of the
+// span in ctx. Spans are sampled by the tracer provider set up from the otel
+// config, when tracing is disabled the global provider is a no-op so is this.
+func startAttemptSpan(ctx context.Context, endpoint *datastore.Endpoint, eventDelivery *datastore.EventDelivery) (context.Context, trace.Span) {
+	var attempt int64 = 1
+	if eventDelivery.Metadata != nil {
+		attempt = int64(eventDelivery.Metadata.NumTrials) + 1
+	}
+
+	return otel.Tracer(deliveryTracerName).Start(ctx, "event.delivery.attempt",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("endpoint_id", endpoint.UID),
+			attribute.String("event_delivery_id", eventDelivery.UID),
+			attribute.String("project_id", eventDelivery.ProjectID),
+			attribute.Int64("attempt", attempt),
+		))
+}
+
+// recordAttemptResponse adds the endpoint's response to the attempt's span
+func recordAttemptResponse(span trace.Span, statusCode int, latency time.Duration, err error) {
+	span.SetAttributes(
+		attribute.Int("response.status_code", statusCode),
+		attribute.Float64("latency_seconds", latency.Seconds()),
+	)
+
+	switch {
+	case isTimeout(err):
+		span.SetAttributes(attribute.String("delivery.outcome", "timeout"))
+		span.SetStatus(codes.Error, "request timed out")
+	case err != nil:
+		span.SetAttributes(attribute.String("delivery.outcome", "failed"))
+		span.SetStatus(codes.Error, err.Error())
+	case statusCode < 200 || statusCode > 299:
+		span.SetAttributes(attribute.String("delivery.outcome", "failed"))
+		span.SetStatus(codes.Error, "endpoint returned a non-2xx response")
+	default:
+		span.SetAttributes(attribute.String("delivery.outcome", "success"))
+	}
+}
+
+// endAttemptSpan ends the attempt's span, attempts that never went out
+// because of the endpoint's rate limit or circuit breaker are marked as such
+func endAttemptSpan(span trace.Span, err error) {
+	var rateLimitErr *RateLimitError
+	var breakerErr *CircuitBreakerError
+
+	switch {
+	case errors.As(err, &rateLimitErr):
+		span.SetAttributes(attribute.String("delivery.outcome", "rate_limited"))
+		span.SetStatus(codes.Error, err.Error())
+	case errors.As(err, &breakerErr):
+		span.SetAttributes(attribute.String("delivery.outcome", "circuit_open"))
+		span.SetStatus(codes.Error, err.Error())
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// isTimeout reports whether a request failed because the endpoint took too long
+func isTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr stdnet.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,144 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/circuit_breaker"
+)
+
+func setTestTracerProvider(t *testing.T, sampler sdktrace.Sampler) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder), sdktrace.WithSampler(sampler))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestAttemptSpan(t *testing.T) {
+	endpoint := &datastore.Endpoint{UID: "endpoint-id-1"}
+	eventDelivery := &datastore.EventDelivery{
+		UID:       "delivery-id-1",
+		ProjectID: "project-id-1",
+		Metadata:  &datastore.Metadata{NumTrials: 2},
+	}
+
+	tests := []struct {
+		name        string
+		dispatched  bool
+		statusCode  int
+		dispatchErr error
+		err         error
+		wantStatus  codes.Code
+		wantOutcome string
+	}{
+		{
+			name:        "successful attempt",
+			dispatched:  true,
+			statusCode:  http.StatusOK,
+			wantStatus:  codes.Unset,
+			wantOutcome: "success",
+		},
+		{
+			name:        "non-2xx response",
+			dispatched:  true,
+			statusCode:  http.StatusInternalServerError,
+			err:         &DeliveryError{Err: ErrDeliveryAttemptFailed},
+			wantStatus:  codes.Error,
+			wantOutcome: "failed",
+		},
+		{
+			name:        "timed out attempt",
+			dispatched:  true,
+			dispatchErr: context.DeadlineExceeded,
+			err:         &DeliveryError{Err: ErrDeliveryAttemptFailed},
+			wantStatus:  codes.Error,
+			wantOutcome: "timeout",
+		},
+		{
+			name:        "rate limited attempt",
+			err:         &RateLimitError{Err: ErrRateLimit},
+			wantStatus:  codes.Error,
+			wantOutcome: "rate_limited",
+		},
+		{
+			name:        "circuit breaker is open",
+			err:         &CircuitBreakerError{Err: circuit_breaker.ErrOpenState},
+			wantStatus:  codes.Error,
+			wantOutcome: "circuit_open",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := setTestTracerProvider(t, sdktrace.AlwaysSample())
+
+			ctx, parent := otel.Tracer("test").Start(context.Background(), "task")
+
+			_, span := startAttemptSpan(ctx, endpoint, eventDelivery)
+			if tc.dispatched {
+				recordAttemptResponse(span, tc.statusCode, 250*time.Millisecond, tc.dispatchErr)
+			}
+			endAttemptSpan(span, tc.err)
+			parent.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 2)
+
+			attempt := spans[0]
+			require.Equal(t, "event.delivery.attempt", attempt.Name())
+			require.Equal(t, parent.SpanContext().SpanID(), attempt.Parent().SpanID())
+			require.Equal(t, tc.wantStatus, attempt.Status().Code)
+
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range attempt.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+
+			require.Equal(t, "endpoint-id-1", attrs["endpoint_id"].AsString())
+			require.Equal(t, "delivery-id-1", attrs["event_delivery_id"].AsString())
+			require.Equal(t, int64(3), attrs["attempt"].AsInt64())
+			require.Equal(t, tc.wantOutcome, attrs["delivery.outcome"].AsString())
+
+			if tc.dispatched {
+				require.Equal(t, int64(tc.statusCode), attrs["response.status_code"].AsInt64())
+				require.Equal(t, 0.25, attrs["latency_seconds"].AsFloat64())
+			}
+		})
+	}
+}
+
+func TestAttemptSpanSampling(t *testing.T) {
+	endpoint := &datastore.Endpoint{UID: "endpoint-id-1"}
+	eventDelivery := &datastore.EventDelivery{UID: "delivery-id-1"}
+
+	// spans dropped by the configured sample rate aren't recorded
+	recorder := setTestTracerProvider(t, sdktrace.TraceIDRatioBased(0))
+
+	_, span := startAttemptSpan(context.Background(), endpoint, eventDelivery)
+	require.False(t, span.IsRecording())
+	endAttemptSpan(span, errors.New("failed"))
+	require.Empty(t, recorder.Ended())
+
+	// and nothing is recorded when tracing is disabled
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	_, span = startAttemptSpan(context.Background(), endpoint, eventDelivery)
+	require.False(t, span.IsRecording())
+	endAttemptSpan(span, nil)
+}
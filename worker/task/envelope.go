@@ -0,0 +1,58 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/signature"
+)
+
+// deliveryEnvelope is the request body sent to endpoints that use
+// the envelope body format.
+type deliveryEnvelope struct {
+	Metadata envelopeMetadata `json:"metadata"`
+	Data     json.RawMessage  `json:"data"`
+}
+
+type envelopeMetadata struct {
+	EventID         string              `json:"event_id"`
+	EventDeliveryID string              `json:"event_delivery_id"`
+	EventType       datastore.EventType `json:"event_type"`
+	Timestamp       time.Time           `json:"timestamp"`
+	Attempt         uint64              `json:"attempt"`
+}
+
+// deliveryPayload returns the request body for the delivery's next attempt.
+// It is the raw event payload reshaped by the subscription's payload
+// transform, if any, unless the endpoint asks for it to be wrapped in an
+// envelope.
+func deliveryPayload(endpoint *datastore.Endpoint, subscription *datastore.Subscription, eventDelivery *datastore.EventDelivery) (json.RawMessage, error) {
+	raw, err := transformPayload(subscription, json.RawMessage(eventDelivery.Metadata.Raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.GetBodyFormat() != datastore.EnvelopeEndpointBodyFormat {
+		return raw, nil
+	}
+
+	envelope := deliveryEnvelope{
+		Metadata: envelopeMetadata{
+			EventID:         eventDelivery.EventID,
+			EventDeliveryID: eventDelivery.UID,
+			EventType:       eventDelivery.EventType,
+			Timestamp:       eventDelivery.CreatedAt,
+			Attempt:         eventDelivery.Metadata.NumTrials + 1,
+		},
+		Data: raw,
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", signature.ErrFailedToEncodePayload, err)
+	}
+
+	return data, nil
+}
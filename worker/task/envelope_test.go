@@ -0,0 +1,95 @@
+package task
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/signature"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryPayload(t *testing.T) {
+	createdAt := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	eventDelivery := &datastore.EventDelivery{
+		UID:       "ed-1",
+		EventID:   "event-1",
+		EventType: "invoice.paid",
+		CreatedAt: createdAt,
+		Metadata: &datastore.Metadata{
+			Raw:       `{"amount":100,"currency":"NGN"}`,
+			NumTrials: 2,
+		},
+	}
+
+	t.Run("raw body format sends the payload untouched", func(t *testing.T) {
+		for _, format := range []datastore.EndpointBodyFormat{"", datastore.RawEndpointBodyFormat} {
+			payload, err := deliveryPayload(&datastore.Endpoint{BodyFormat: format}, nil, eventDelivery)
+			require.NoError(t, err)
+			require.Equal(t, eventDelivery.Metadata.Raw, string(payload))
+		}
+	})
+
+	t.Run("envelope body format wraps the payload with metadata", func(t *testing.T) {
+		payload, err := deliveryPayload(&datastore.Endpoint{BodyFormat: datastore.EnvelopeEndpointBodyFormat}, nil, eventDelivery)
+		require.NoError(t, err)
+
+		var envelope map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(payload, &envelope))
+		require.Len(t, envelope, 2)
+		require.JSONEq(t, eventDelivery.Metadata.Raw, string(envelope["data"]))
+
+		var metadata map[string]interface{}
+		require.NoError(t, json.Unmarshal(envelope["metadata"], &metadata))
+		require.Equal(t, map[string]interface{}{
+			"event_id":          "event-1",
+			"event_delivery_id": "ed-1",
+			"event_type":        "invoice.paid",
+			"timestamp":         createdAt.Format(time.RFC3339),
+			"attempt":           float64(3),
+		}, metadata)
+	})
+
+	t.Run("signature is computed over the enveloped bytes", func(t *testing.T) {
+		secret := "endpoint-secret"
+		endpoint := &datastore.Endpoint{
+			BodyFormat: datastore.EnvelopeEndpointBodyFormat,
+			Secrets:    []datastore.Secret{{Value: secret}},
+		}
+		project := &datastore.Project{
+			Config: &datastore.ProjectConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Versions: []datastore.SignatureVersion{{Hash: "SHA256", Encoding: datastore.HexEncoding}},
+				},
+			},
+		}
+
+		payload, err := deliveryPayload(endpoint, nil, eventDelivery)
+		require.NoError(t, err)
+
+		sig := newSignature(endpoint, project, payload)
+		header, err := sig.ComputeHeaderValue()
+		require.NoError(t, err)
+
+		// a receiver verifies against the body it was sent
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(sig.Payload)
+		require.Equal(t, hex.EncodeToString(mac.Sum(nil)), header)
+
+		mac = hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(eventDelivery.Metadata.Raw))
+		require.NotEqual(t, hex.EncodeToString(mac.Sum(nil)), header)
+	})
+
+	t.Run("unencodable payload is reported as an encode error", func(t *testing.T) {
+		ed := *eventDelivery
+		ed.Metadata = &datastore.Metadata{Raw: `{"amount":`}
+
+		_, err := deliveryPayload(&datastore.Endpoint{BodyFormat: datastore.EnvelopeEndpointBodyFormat}, nil, &ed)
+		require.ErrorIs(t, err, signature.ErrFailedToEncodePayload)
+	})
+}
@@ -0,0 +1,82 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/signature"
+)
+
+// sendsForm reports whether payloads are form encoded for the endpoint,
+// content type parameters like charset are ignored
+func sendsForm(endpoint *datastore.Endpoint) bool {
+	mediaType, _, err := mime.ParseMediaType(endpoint.GetContentType())
+	if err != nil {
+		return false
+	}
+
+	return mediaType == datastore.FormContentType
+}
+
+// encodeDeliveryBody returns payload encoded the way the endpoint's content
+// type asks for, only the form content type changes it.
+func encodeDeliveryBody(endpoint *datastore.Endpoint, payload json.RawMessage) (json.RawMessage, error) {
+	if !sendsForm(endpoint) {
+		return payload, nil
+	}
+
+	form, err := formValues(endpoint.GetFormEncoding(), payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", signature.ErrFailedToEncodePayload, err)
+	}
+
+	// url.Values encodes its keys sorted, so the body and its
+	// signature are the same on every attempt
+	return json.RawMessage(form.Encode()), nil
+}
+
+func formValues(encoding datastore.EndpointFormEncoding, payload json.RawMessage) (url.Values, error) {
+	form := url.Values{}
+
+	var fields map[string]json.RawMessage
+	if encoding == datastore.PayloadEndpointFormEncoding || json.Unmarshal(payload, &fields) != nil || fields == nil {
+		compact := &bytes.Buffer{}
+		err := json.Compact(compact, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		form.Set("payload", compact.String())
+		return form, nil
+	}
+
+	for k, v := range fields {
+		var str string
+		if bytes.HasPrefix(v, []byte(`"`)) && json.Unmarshal(v, &str) == nil {
+			form.Set(k, str)
+			continue
+		}
+
+		compact := &bytes.Buffer{}
+		err := json.Compact(compact, v)
+		if err != nil {
+			return nil, err
+		}
+
+		form.Set(k, compact.String())
+	}
+
+	return form, nil
+}
+
+// withContentType makes the dispatcher send the delivery with the
+// endpoint's content type
+func withContentType(ctx context.Context, endpoint *datastore.Endpoint) context.Context {
+	return net.ContextWithContentType(ctx, endpoint.GetContentType())
+}
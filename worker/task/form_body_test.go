@@ -0,0 +1,65 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/signature"
+)
+
+func TestEncodeDeliveryBody(t *testing.T) {
+	payload := json.RawMessage(`{"event": "invoice.completed", "amount": 100, "paid": true, "customer": {"id": "c_1"}, "note": null}`)
+
+	tests := []struct {
+		name     string
+		endpoint *datastore.Endpoint
+		payload  json.RawMessage
+		want     string
+	}{
+		{
+			name:     "json is sent as is",
+			endpoint: &datastore.Endpoint{},
+			payload:  payload,
+			want:     string(payload),
+		},
+		{
+			name:     "vendor media types are sent as is",
+			endpoint: &datastore.Endpoint{ContentType: "application/vnd.acme.v2+json"},
+			payload:  payload,
+			want:     string(payload),
+		},
+		{
+			name:     "form fields, strings as is and other values as json",
+			endpoint: &datastore.Endpoint{ContentType: "application/x-www-form-urlencoded; charset=utf-8"},
+			payload:  payload,
+			want:     "amount=100&customer=%7B%22id%22%3A%22c_1%22%7D&event=invoice.completed&note=null&paid=true",
+		},
+		{
+			name:     "form payload",
+			endpoint: &datastore.Endpoint{ContentType: datastore.FormContentType, FormEncoding: datastore.PayloadEndpointFormEncoding},
+			payload:  json.RawMessage(`{"event": "invoice.completed"}`),
+			want:     "payload=%7B%22event%22%3A%22invoice.completed%22%7D",
+		},
+		{
+			name:     "payloads that aren't objects are sent in a payload field",
+			endpoint: &datastore.Endpoint{ContentType: datastore.FormContentType},
+			payload:  json.RawMessage(`[1, 2]`),
+			want:     "payload=%5B1%2C2%5D",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := encodeDeliveryBody(tc.endpoint, tc.payload)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(got))
+		})
+	}
+
+	_, err := encodeDeliveryBody(&datastore.Endpoint{ContentType: datastore.FormContentType}, json.RawMessage(`{"event": `))
+	require.True(t, errors.Is(err, signature.ErrFailedToEncodePayload))
+}
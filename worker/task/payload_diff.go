@@ -0,0 +1,140 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+// diffPayload returns what changed from previous to current as a JSON merge
+// patch (RFC 7396): fields that were added or changed with their new
+// values, removed fields as null, and nested objects diffed field by
+// field. The field at keyPath is always included, so consumers can tell
+// which entity changed. current is returned whole when either isn't a JSON
+// object.
+func diffPayload(previous, current json.RawMessage, keyPath string) (json.RawMessage, error) {
+	var before, after map[string]interface{}
+	if decodeObject(previous, &before) != nil || decodeObject(current, &after) != nil {
+		return current, nil
+	}
+
+	patch := diffObjects(before, after)
+	includePath(patch, after, strings.Split(keyPath, "."))
+
+	return json.Marshal(patch)
+}
+
+// includePath copies the field at path in doc to patch, along with the
+// objects it's nested in.
+func includePath(patch, doc map[string]interface{}, path []string) {
+	v, ok := doc[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		patch[path[0]] = v
+		return
+	}
+
+	nestedDoc, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	nestedPatch, ok := patch[path[0]].(map[string]interface{})
+	if !ok {
+		nestedPatch = make(map[string]interface{})
+	}
+
+	includePath(nestedPatch, nestedDoc, path[1:])
+	if len(nestedPatch) > 0 {
+		patch[path[0]] = nestedPatch
+	}
+}
+
+func decodeObject(raw json.RawMessage, v *map[string]interface{}) error {
+	// numbers are kept as written, so 1.0 and 1 aren't taken as a change
+	// that isn't there or sent back rewritten
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+func diffObjects(before, after map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+
+	for k, v := range after {
+		old, ok := before[k]
+		if !ok {
+			patch[k] = v
+			continue
+		}
+
+		oldObj, oldIsObj := old.(map[string]interface{})
+		newObj, newIsObj := v.(map[string]interface{})
+		if oldIsObj && newIsObj {
+			if nested := diffObjects(oldObj, newObj); len(nested) > 0 {
+				patch[k] = nested
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(old, v) {
+			patch[k] = v
+		}
+	}
+
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			patch[k] = nil
+		}
+	}
+
+	return patch
+}
+
+// diffDeliveryPayload returns what a delivery of data to a subscription
+// with a diff key should carry: the whole of data for the first event of
+// an entity, and only what changed since the entity's previous event after
+// that, in which case diffed is true. Payloads without the entity key, and
+// events created before the entity's latest snapshot, are delivered whole.
+func diffDeliveryPayload(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, subscription *datastore.Subscription, event *datastore.Event, createdAt time.Time, data json.RawMessage) (payload json.RawMessage, diffed bool, err error) {
+	if subscription.DiffKey == "" {
+		return data, false, nil
+	}
+
+	key := gjson.GetBytes(data, subscription.DiffKey)
+	if !key.Exists() || key.String() == "" {
+		return data, false, nil
+	}
+
+	previous, err := eventDeliveryRepo.SwapEntitySnapshot(ctx, event.ProjectID, subscription.UID, key.String(), event.UID, createdAt, data)
+	if err != nil {
+		if errors.Is(err, datastore.ErrStaleEntitySnapshot) {
+			log.FromContext(ctx).Infof("event %s is older than the snapshot of entity %s, delivering it whole", event.UID, key.String())
+			return data, false, nil
+		}
+		return nil, false, err
+	}
+
+	if previous == nil {
+		return data, false, nil
+	}
+
+	payload, err = diffPayload(previous, data, subscription.DiffKey)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return payload, true, nil
+}
@@ -0,0 +1,100 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDiffPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		keyPath  string
+		want     string
+	}{
+		{
+			name:     "should_only_include_changed_fields_and_the_key",
+			previous: `{"id":"cus_1","name":"Ada","plan":"free"}`,
+			current:  `{"id":"cus_1","name":"Ada","plan":"pro"}`,
+			want:     `{"id":"cus_1","plan":"pro"}`,
+		},
+		{
+			name:     "should_include_added_fields_and_null_removed_ones",
+			previous: `{"id":"cus_1","email":"ada@example.com"}`,
+			current:  `{"id":"cus_1","phone":"+234"}`,
+			want:     `{"id":"cus_1","email":null,"phone":"+234"}`,
+		},
+		{
+			name:     "should_include_a_nested_key_with_the_changes_next_to_it",
+			previous: `{"data":{"id":"cus_1","name":"Ada","plan":"free"}}`,
+			current:  `{"data":{"id":"cus_1","name":"Ada","plan":"pro"}}`,
+			keyPath:  "data.id",
+			want:     `{"data":{"id":"cus_1","plan":"pro"}}`,
+		},
+		{
+			name:     "should_diff_nested_objects",
+			previous: `{"address":{"city":"Lagos","zip":"100001"},"tags":["a"]}`,
+			current:  `{"address":{"city":"Abuja","zip":"100001"},"tags":["a","b"]}`,
+			want:     `{"address":{"city":"Abuja"},"tags":["a","b"]}`,
+		},
+		{
+			name:     "should_keep_numbers_as_written",
+			previous: `{"amount":10.0,"count":1}`,
+			current:  `{"amount":10.0,"count":12345678901234567890}`,
+			want:     `{"count":12345678901234567890}`,
+		},
+		{
+			name:     "should_only_include_the_key_when_nothing_changed",
+			previous: `{"id":"cus_1"}`,
+			current:  `{"id":"cus_1"}`,
+			want:     `{"id":"cus_1"}`,
+		},
+		{
+			name:     "should_send_non_objects_whole",
+			previous: `[1,2]`,
+			current:  `[1,2,3]`,
+			want:     `[1,2,3]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPath := tt.keyPath
+			if keyPath == "" {
+				keyPath = "id"
+			}
+
+			got, err := diffPayload([]byte(tt.previous), []byte(tt.current), keyPath)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestDiffDeliveryPayloadStaleEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Now().Add(-time.Minute)
+	data := []byte(`{"data":{"id":"cus_1","name":"Ada"}}`)
+
+	// a newer event for the entity was fanned out first
+	ed := mocks.NewMockEventDeliveryRepository(ctrl)
+	ed.EXPECT().SwapEntitySnapshot(gomock.Any(), "project-id-1", "sub-id-1", "cus_1", "event-id-1", createdAt, gomock.Any()).
+		Return(nil, datastore.ErrStaleEntitySnapshot)
+
+	subscription := &datastore.Subscription{UID: "sub-id-1", DiffKey: "data.id"}
+	event := &datastore.Event{UID: "event-id-1", ProjectID: "project-id-1"}
+
+	payload, diffed, err := diffDeliveryPayload(context.Background(), ed, subscription, event, createdAt, data)
+	require.NoError(t, err)
+	require.False(t, diffed)
+	require.JSONEq(t, string(data), string(payload))
+}
@@ -0,0 +1,52 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+// ErrPayloadTransform is returned when a subscription's payload
+// transform can't be applied to a delivery's payload.
+var ErrPayloadTransform = errors.New("payload transform error")
+
+const (
+	// maxTransformGrowth is how many times larger than the raw payload a
+	// transformed payload can be, so a transform that repeats parts of the
+	// payload can't blow up the worker's memory.
+	maxTransformGrowth = 2
+
+	// minTransformLimit keeps small payloads from being held to a limit
+	// that renaming a couple of fields would exceed.
+	minTransformLimit = 4 * 1024
+)
+
+// transformPayload reshapes raw with the subscription's payload transform,
+// a gjson path such as "data" to unwrap a top-level data envelope or
+// "{id:data.id,type:event}" to pick and rename fields. raw is returned as
+// is when the subscription has no transform.
+func transformPayload(subscription *datastore.Subscription, raw json.RawMessage) (json.RawMessage, error) {
+	if subscription == nil || subscription.PayloadTransform == "" {
+		return raw, nil
+	}
+
+	if !gjson.ValidBytes(raw) {
+		return nil, fmt.Errorf("%w: payload is not valid json", ErrPayloadTransform)
+	}
+
+	result := gjson.GetBytes(raw, subscription.PayloadTransform)
+	if !result.Exists() {
+		return nil, fmt.Errorf("%w: %q matched nothing in the payload", ErrPayloadTransform, subscription.PayloadTransform)
+	}
+
+	limit := max(maxTransformGrowth*len(raw), minTransformLimit)
+	if len(result.Raw) > limit {
+		return nil, fmt.Errorf("%w: transformed payload of %d bytes is over the limit of %d bytes", ErrPayloadTransform, len(result.Raw), limit)
+	}
+
+	return json.RawMessage(result.Raw), nil
+}
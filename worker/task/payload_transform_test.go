@@ -0,0 +1,84 @@
+package task
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformPayload(t *testing.T) {
+	raw := json.RawMessage(`{"event":"invoice.paid","data":{"id":"inv_1","amount":100}}`)
+
+	tests := []struct {
+		name      string
+		transform string
+		want      string
+		wantErr   bool
+	}{
+		{name: "no transform sends the payload as is", want: string(raw)},
+		{name: "unwraps a top-level data envelope", transform: "data", want: `{"id":"inv_1","amount":100}`},
+		{name: "picks and renames fields", transform: "{invoice:data.id,type:event}", want: `{"invoice":"inv_1","type":"invoice.paid"}`},
+		{name: "a path that matches nothing fails", transform: "payload", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := transformPayload(&datastore.Subscription{PayloadTransform: tt.transform}, raw)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrPayloadTransform)
+				return
+			}
+
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(payload))
+		})
+	}
+
+	t.Run("invalid json payload fails", func(t *testing.T) {
+		_, err := transformPayload(&datastore.Subscription{PayloadTransform: "data"}, json.RawMessage(`{"data":`))
+		require.ErrorIs(t, err, ErrPayloadTransform)
+	})
+
+	t.Run("transform can't grow the payload past the limit", func(t *testing.T) {
+		big := json.RawMessage(`{"data":"` + strings.Repeat("a", minTransformLimit) + `"}`)
+		_, err := transformPayload(&datastore.Subscription{PayloadTransform: "{a:data,b:data,c:data}"}, big)
+		require.ErrorIs(t, err, ErrPayloadTransform)
+	})
+}
+
+func TestDeliveryPayload_Transform(t *testing.T) {
+	secret := "endpoint-secret"
+	endpoint := &datastore.Endpoint{Secrets: []datastore.Secret{{Value: secret}}}
+	project := &datastore.Project{
+		Config: &datastore.ProjectConfig{
+			Signature: &datastore.SignatureConfiguration{
+				Versions: []datastore.SignatureVersion{{Hash: "SHA256", Encoding: datastore.HexEncoding}},
+			},
+		},
+	}
+	eventDelivery := &datastore.EventDelivery{
+		Metadata: &datastore.Metadata{Raw: `{"data":{"id":"inv_1"}}`},
+	}
+	subscription := &datastore.Subscription{PayloadTransform: "data"}
+
+	payload, err := deliveryPayload(endpoint, subscription, eventDelivery)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"inv_1"}`, string(payload))
+
+	// the signature matches the transformed body that is sent
+	header, err := newSignature(endpoint, project, payload).ComputeHeaderValue()
+	require.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	require.Equal(t, hex.EncodeToString(mac.Sum(nil)), header)
+
+	// and the stored payload is left alone for the next attempt
+	require.Equal(t, `{"data":{"id":"inv_1"}}`, eventDelivery.Metadata.Raw)
+}
@@ -0,0 +1,93 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/pkg/log"
+)
+
+const (
+	defaultPollMinInterval = time.Second
+	defaultPollMaxInterval = time.Minute
+	defaultPollMultiplier  = 2
+)
+
+// PollBackoff spaces out the polls of a loop looking for work. Every poll
+// that finds nothing waits longer than the one before it, up to a max, so
+// an idle loop doesn't keep the database busy, and the first poll that
+// finds work drops back to the min interval.
+type PollBackoff struct {
+	min        time.Duration
+	max        time.Duration
+	multiplier float64
+	interval   time.Duration
+}
+
+func NewPollBackoff(cfg config.WorkerPollConfiguration) *PollBackoff {
+	b := &PollBackoff{
+		min:        time.Duration(cfg.MinInterval) * time.Millisecond,
+		max:        time.Duration(cfg.MaxInterval) * time.Millisecond,
+		multiplier: cfg.Multiplier,
+	}
+
+	if b.min <= 0 {
+		b.min = defaultPollMinInterval
+	}
+
+	if b.max <= 0 {
+		b.max = defaultPollMaxInterval
+	}
+
+	if b.max < b.min {
+		b.max = b.min
+	}
+
+	if b.multiplier <= 1 {
+		b.multiplier = defaultPollMultiplier
+	}
+
+	b.interval = b.min
+	return b
+}
+
+// Next returns how long to wait before the next poll, given whether the
+// last one found work
+func (b *PollBackoff) Next(foundWork bool) time.Duration {
+	if foundWork {
+		b.interval = b.min
+		return b.interval
+	}
+
+	next := time.Duration(float64(b.interval) * b.multiplier)
+	if next > b.max {
+		next = b.max
+	}
+
+	b.interval = next
+	return b.interval
+}
+
+// pollWithBackoff calls poll until ctx is done, waiting between polls as
+// b says. A poll that fails is treated like one that found no work.
+func pollWithBackoff(ctx context.Context, b *PollBackoff, poll func(context.Context) (bool, error)) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		foundWork, err := poll(ctx)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("poll failed")
+			foundWork = false
+		}
+
+		timer.Reset(b.Next(foundWork))
+	}
+}
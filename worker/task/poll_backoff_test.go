@@ -0,0 +1,99 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/config"
+)
+
+func TestPollBackoff_Next(t *testing.T) {
+	b := NewPollBackoff(config.WorkerPollConfiguration{MinInterval: 100, MaxInterval: 1000, Multiplier: 2})
+
+	// the interval grows while idle, up to the max
+	require.Equal(t, 200*time.Millisecond, b.Next(false))
+	require.Equal(t, 400*time.Millisecond, b.Next(false))
+	require.Equal(t, 800*time.Millisecond, b.Next(false))
+	require.Equal(t, time.Second, b.Next(false))
+	require.Equal(t, time.Second, b.Next(false))
+
+	// and drops back to the min as soon as there's work
+	require.Equal(t, 100*time.Millisecond, b.Next(true))
+	require.Equal(t, 100*time.Millisecond, b.Next(true))
+	require.Equal(t, 200*time.Millisecond, b.Next(false))
+}
+
+func TestNewPollBackoff_Defaults(t *testing.T) {
+	b := NewPollBackoff(config.WorkerPollConfiguration{})
+	require.Equal(t, defaultPollMinInterval, b.min)
+	require.Equal(t, defaultPollMaxInterval, b.max)
+	require.Equal(t, float64(defaultPollMultiplier), b.multiplier)
+
+	// a max below the min never backs off
+	b = NewPollBackoff(config.WorkerPollConfiguration{MinInterval: 500, MaxInterval: 100})
+	require.Equal(t, 500*time.Millisecond, b.Next(false))
+}
+
+func TestPollWithBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewPollBackoff(config.WorkerPollConfiguration{MinInterval: 1, MaxInterval: 8, Multiplier: 2})
+
+	type result struct {
+		foundWork bool
+		err       error
+	}
+
+	results := []result{
+		{foundWork: false},
+		{foundWork: false},
+		{foundWork: false},
+		{foundWork: true},
+		{foundWork: false},
+		// a failed poll backs off like an idle one
+		{foundWork: true, err: errors.New("database unavailable")},
+	}
+
+	// the interval waited before each poll after the first
+	var intervals []time.Duration
+	calls := 0
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pollWithBackoff(ctx, b, func(context.Context) (bool, error) {
+			if calls > 0 {
+				intervals = append(intervals, b.interval)
+			}
+
+			if calls == len(results) {
+				cancel()
+				return false, nil
+			}
+
+			r := results[calls]
+			calls++
+			return r.foundWork, r.err
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("poll loop didn't stop when the context was cancelled")
+	}
+
+	require.Equal(t, []time.Duration{
+		2 * time.Millisecond,
+		4 * time.Millisecond,
+		8 * time.Millisecond,
+		time.Millisecond,
+		2 * time.Millisecond,
+		4 * time.Millisecond,
+	}, intervals)
+}
@@ -62,7 +62,9 @@ func ProcessBatchRetry(
 				batchRetry.Filter.SearchParams,
 				batchRetry.Filter.Pageable,
 				batchRetry.Filter.IdempotencyKey,
-				batchRetry.Filter.EventType)
+				batchRetry.Filter.EventType,
+				batchRetry.Filter.ResponseStatusCodes,
+				datastore.PreferReplica)
 			if innerErr != nil {
 				lo.WithError(innerErr).Error("failed to load deliveries")
 				return innerErr
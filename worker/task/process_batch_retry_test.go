@@ -74,6 +74,8 @@ func TestProcessBatchRetry(t *testing.T) {
 						datastore.Pageable{PerPage: 1000, Direction: datastore.Next, NextCursor: datastore.DefaultCursor},
 						"",
 						"",
+						gomock.Any(),
+						datastore.PreferReplica,
 					).
 					Return([]datastore.EventDelivery{
 						{UID: "delivery-1", Status: datastore.SuccessEventStatus},
@@ -191,6 +193,8 @@ func TestProcessBatchRetry(t *testing.T) {
 						datastore.Pageable{PerPage: 1000, Direction: datastore.Next, NextCursor: datastore.DefaultCursor},
 						"",
 						"",
+						gomock.Any(),
+						datastore.PreferReplica,
 					).
 					Return(nil, datastore.PaginationData{}, datastore.ErrEventDeliveryNotFound).Times(1)
 			},
@@ -237,6 +241,8 @@ func TestProcessBatchRetry(t *testing.T) {
 						datastore.Pageable{PerPage: 1000, Direction: datastore.Next, NextCursor: datastore.DefaultCursor},
 						"",
 						"",
+						gomock.Any(),
+						datastore.PreferReplica,
 					).
 					Return([]datastore.EventDelivery{
 						{UID: "delivery-1", Status: datastore.SuccessEventStatus},
@@ -307,6 +313,8 @@ func TestProcessBatchRetry(t *testing.T) {
 						datastore.Pageable{PerPage: 1000, Direction: datastore.Next, NextCursor: datastore.DefaultCursor},
 						"",
 						"",
+						gomock.Any(),
+						datastore.PreferReplica,
 					).
 					Return([]datastore.EventDelivery{
 						{UID: "delivery-1", Status: datastore.SuccessEventStatus},
@@ -337,6 +345,8 @@ func TestProcessBatchRetry(t *testing.T) {
 						datastore.Pageable{PerPage: 1000, Direction: datastore.Next, NextCursor: "next-cursor"},
 						"",
 						"",
+						gomock.Any(),
+						datastore.PreferReplica,
 					).
 					Return([]datastore.EventDelivery{
 						{UID: "delivery-2", Status: datastore.SuccessEventStatus},
@@ -10,6 +10,7 @@ import (
 
 	"gopkg.in/guregu/null.v4"
 
+	"github.com/frain-dev/convoy/internal/pkg/dedup"
 	"github.com/frain-dev/convoy/internal/pkg/license"
 	"github.com/frain-dev/convoy/internal/pkg/tracer"
 	"github.com/frain-dev/convoy/pkg/flatten"
@@ -207,13 +208,44 @@ func ProcessEventCreation(endpointRepo datastore.EndpointRepository, eventRepo d
 	return ProcessEventCreationByChannel(ch, endpointRepo, eventRepo, projectRepo, eventQueue, subRepo, filterRepo, licenser, tracerBackend)
 }
 
+// deliveryIdempotencyKey returns the idempotency key sent with a delivery of
+// data, derived from the content when the project has opted in.
+func deliveryIdempotencyKey(ctx context.Context, project *datastore.Project, event *datastore.Event, data []byte) string {
+	if project.Config == nil || !project.Config.ContentIdempotencyKeys {
+		return event.IdempotencyKey
+	}
+
+	key, err := dedup.ContentChecksum(project.UID, string(event.EventType), data)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("failed to derive idempotency key for event %s", event.UID)
+		return event.IdempotencyKey
+	}
+
+	return key
+}
+
+// CreatePlannedEventDeliveries creates and queues deliveries of event for
+// subscriptions the caller picked, they aren't matched against the event.
+func CreatePlannedEventDeliveries(ctx context.Context, subscriptions []datastore.Subscription, event *datastore.Event, project *datastore.Project, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, deviceRepo datastore.DeviceRepository, endpointRepo datastore.EndpointRepository, licenser license.Licenser) error {
+	return writeEventDeliveriesToQueue(ctx, subscriptions, event, project, eventDeliveryRepo, eventQueue, deviceRepo, endpointRepo, licenser)
+}
+
 func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.Subscription, event *datastore.Event, project *datastore.Project, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, deviceRepo datastore.DeviceRepository, endpointRepo datastore.EndpointRepository, licenser license.Licenser) error {
 	ec := &EventDeliveryConfig{project: project}
 
+	// entity snapshots are ordered by when their events were created, so a
+	// late fan-out doesn't overwrite a newer event's snapshot
+	eventCreatedAt := event.CreatedAt
+	if eventCreatedAt.IsZero() {
+		eventCreatedAt = time.Now()
+	}
+
 	eventDeliveries := make([]*datastore.EventDelivery, 0)
+	subscriptionTypes := make(map[string]datastore.SubscriptionType, len(subscriptions))
 	for _, s := range subscriptions {
 		ec.subscription = &s
-		headers := event.Headers
+		sourceHeaders := getForwardedHeaders(event, &s)
+		headers := sourceHeaders
 
 		if s.Type == datastore.SubscriptionTypeAPI {
 			endpoint, err := endpointRepo.FindEndpointByID(ctx, s.EndpointID, project.UID)
@@ -228,7 +260,7 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 			if endpoint.Authentication != nil && endpoint.Authentication.Type == datastore.APIKeyAuthentication {
 				headers = make(httpheader.HTTPHeader)
 				headers[endpoint.Authentication.ApiKey.HeaderName] = []string{endpoint.Authentication.ApiKey.HeaderValue}
-				headers.MergeHeaders(event.Headers)
+				headers.MergeHeaders(sourceHeaders)
 			}
 
 			s.Endpoint = endpoint
@@ -264,13 +296,30 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 			data = bytes
 		}
 
+		var fullDocument json.RawMessage
+		if s.DiffKey != "" {
+			diff, diffed, err := diffDeliveryPayload(ctx, eventDeliveryRepo, &s, event, eventCreatedAt, data)
+			if err != nil {
+				return &EndpointError{Err: fmt.Errorf("failed to diff the payload for subscription %s, err: %s", s.UID, err.Error()), delay: defaultDelay}
+			}
+
+			if diffed {
+				fullDocument = data
+				raw = string(diff)
+				data = diff
+			}
+		}
+
 		metadata := &datastore.Metadata{
-			Raw:             raw,
-			Data:            data,
-			Strategy:        rc.Type,
-			NextSendTime:    time.Now(),
-			IntervalSeconds: rc.Duration,
-			RetryLimit:      rc.RetryCount,
+			Raw:                raw,
+			Data:               data,
+			FullDocument:       fullDocument,
+			Strategy:           rc.Type,
+			NextSendTime:       time.Now(),
+			IntervalSeconds:    rc.Duration,
+			RetryLimit:         rc.RetryCount,
+			BackoffBase:        rc.BackoffBase,
+			MaxIntervalSeconds: rc.MaxInterval,
 		}
 
 		eventDelivery := &datastore.EventDelivery{
@@ -283,11 +332,18 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 			EndpointID:     s.EndpointID,
 			DeviceID:       s.DeviceID,
 			Headers:        headers,
-			IdempotencyKey: event.IdempotencyKey,
+			IdempotencyKey: deliveryIdempotencyKey(ctx, project, event, data),
 			URLQueryParams: event.URLQueryParams,
 			Status:         getEventDeliveryStatus(ctx, &s, s.Endpoint, deviceRepo),
 			AcknowledgedAt: null.TimeFrom(time.Now()),
-			DeliveryMode:   s.DeliveryMode,
+			DeliveryMode:   s.GetDeliveryMode(project),
+			CreatedAt:      time.Now(),
+		}
+
+		// an event is delivered to an endpoint at most once, a replay
+		// releases the key before fanning the event out again
+		if !util.IsStringEmpty(s.EndpointID) {
+			eventDelivery.DeduplicationKey = datastore.EventDeliveryDeduplicationKey(s.EndpointID, event.UID)
 		}
 
 		if s.Type == datastore.SubscriptionTypeCLI {
@@ -299,15 +355,16 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 		}
 
 		eventDeliveries = append(eventDeliveries, eventDelivery)
+		subscriptionTypes[eventDelivery.UID] = s.Type
 	}
 
-	err := eventDeliveryRepo.CreateEventDeliveries(ctx, eventDeliveries)
+	created, err := eventDeliveryRepo.CreateEventDeliveries(ctx, eventDeliveries)
 	if err != nil {
 		return &EndpointError{Err: fmt.Errorf("CODE: 1008, err: %s", err.Error()), delay: defaultDelay}
 	}
 
-	for i, eventDelivery := range eventDeliveries {
-		s := subscriptions[i]
+	for _, eventDelivery := range created {
+		subscriptionType := subscriptionTypes[eventDelivery.UID]
 		if eventDelivery.Status != datastore.DiscardedEventStatus {
 			payload := EventDelivery{
 				EventDeliveryID: eventDelivery.UID,
@@ -324,12 +381,12 @@ func writeEventDeliveriesToQueue(ctx context.Context, subscriptions []datastore.
 				Payload: data,
 			}
 
-			if s.Type == datastore.SubscriptionTypeAPI {
+			if subscriptionType == datastore.SubscriptionTypeAPI {
 				err = eventQueue.Write(convoy.EventProcessor, convoy.EventQueue, job)
 				if err != nil {
 					log.FromContext(ctx).WithError(err).Errorf("[asynq]: an error occurred sending event delivery to be dispatched")
 				}
-			} else if s.Type == datastore.SubscriptionTypeCLI {
+			} else if subscriptionType == datastore.SubscriptionTypeCLI {
 				err = eventQueue.Write(convoy.StreamCliEventsProcessor, convoy.StreamQueue, job)
 				if err != nil {
 					log.FromContext(ctx).WithError(err).Error("[asynq]: an error occurred sending event delivery to the stream queue")
@@ -716,3 +773,19 @@ func findEndpoints(ctx context.Context, endpointRepo datastore.EndpointRepositor
 
 	return endpoints, nil
 }
+
+// getForwardedHeaders returns the event headers to forward onto the
+// subscription's event deliveries. Events ingested from a source only
+// forward the headers on the subscription's allow-list when one is set.
+func getForwardedHeaders(event *datastore.Event, subscription *datastore.Subscription) httpheader.HTTPHeader {
+	if util.IsStringEmpty(event.SourceID) || len(subscription.HeaderAllowList) == 0 {
+		return event.Headers
+	}
+
+	headers := event.Headers.Filter(subscription.HeaderAllowList)
+	if sourceID, ok := event.Headers["X-Convoy-Source-Id"]; ok {
+		headers["X-Convoy-Source-Id"] = sourceID
+	}
+
+	return headers
+}
@@ -3,6 +3,7 @@ package task
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/oklog/ulid/v2"
 	"testing"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/hibiken/asynq"
 	"github.com/stretchr/testify/require"
@@ -895,3 +897,271 @@ func TestMatchSubscriptionsUsingFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestGetForwardedHeaders(t *testing.T) {
+	sourceHeaders := httpheader.HTTPHeader{
+		"Stripe-Signature":   []string{"t=1492774577,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd"},
+		"X-Forwarded-For":    []string{"10.0.0.1"},
+		"User-Agent":         []string{"Stripe/1.0"},
+		"X-Convoy-Source-Id": []string{"source-mask-id"},
+	}
+
+	tests := []struct {
+		name        string
+		event       *datastore.Event
+		allowList   []string
+		wantHeaders httpheader.HTTPHeader
+	}{
+		{
+			name:        "should_forward_all_headers_without_allow_list",
+			event:       &datastore.Event{SourceID: "source-id-1", Headers: sourceHeaders},
+			wantHeaders: sourceHeaders,
+		},
+		{
+			name:      "should_forward_only_allow_listed_headers",
+			event:     &datastore.Event{SourceID: "source-id-1", Headers: sourceHeaders},
+			allowList: []string{"stripe-signature"},
+			wantHeaders: httpheader.HTTPHeader{
+				"Stripe-Signature":   sourceHeaders["Stripe-Signature"],
+				"X-Convoy-Source-Id": sourceHeaders["X-Convoy-Source-Id"],
+			},
+		},
+		{
+			name:        "should_ignore_allow_list_for_events_without_a_source",
+			event:       &datastore.Event{Headers: httpheader.HTTPHeader{"X-Custom": []string{"value"}}},
+			allowList:   []string{"Stripe-Signature"},
+			wantHeaders: httpheader.HTTPHeader{"X-Custom": []string{"value"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := getForwardedHeaders(tt.event, &datastore.Subscription{HeaderAllowList: tt.allowList})
+			require.Equal(t, tt.wantHeaders, headers)
+		})
+	}
+}
+
+func TestDeliveryIdempotencyKey(t *testing.T) {
+	project := &datastore.Project{
+		UID:    "project-id-1",
+		Config: &datastore.ProjectConfig{ContentIdempotencyKeys: true},
+	}
+
+	// the same content ingested twice
+	first := &datastore.Event{UID: ulid.Make().String(), EventType: "invoice.paid", IdempotencyKey: "key-1"}
+	second := &datastore.Event{UID: ulid.Make().String(), EventType: "invoice.paid", IdempotencyKey: "key-2"}
+
+	key := deliveryIdempotencyKey(context.Background(), project, first, []byte(`{"id": 1}`))
+	require.NotEqual(t, "key-1", key)
+	require.Equal(t, key, deliveryIdempotencyKey(context.Background(), project, first, []byte(`{"id": 1}`)))
+	require.Equal(t, key, deliveryIdempotencyKey(context.Background(), project, second, []byte(`{"id": 1}`)))
+
+	require.NotEqual(t, key, deliveryIdempotencyKey(context.Background(), project, first, []byte(`{"id": 2}`)))
+
+	// falls back to the event's key when the content can't be hashed
+	require.Equal(t, "key-1", deliveryIdempotencyKey(context.Background(), project, first, []byte(`{"id":`)))
+
+	project.Config.ContentIdempotencyKeys = false
+	require.Equal(t, "key-1", deliveryIdempotencyKey(context.Background(), project, first, []byte(`{"id": 1}`)))
+}
+
+func TestWriteEventDeliveriesDeliveryMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectMode datastore.DeliveryMode
+		subMode     datastore.DeliveryMode
+		want        map[string]datastore.DeliveryMode
+	}{
+		{
+			name:    "project without a default",
+			subMode: datastore.AtMostOnceDeliveryMode,
+			want: map[string]datastore.DeliveryMode{
+				"sub-id-1": datastore.AtMostOnceDeliveryMode,
+				"sub-id-2": datastore.AtLeastOnceDeliveryMode,
+			},
+		},
+		{
+			name:        "project defaults to at most once",
+			projectMode: datastore.AtMostOnceDeliveryMode,
+			subMode:     datastore.AtLeastOnceDeliveryMode,
+			want: map[string]datastore.DeliveryMode{
+				"sub-id-1": datastore.AtLeastOnceDeliveryMode,
+				"sub-id-2": datastore.AtMostOnceDeliveryMode,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			args := provideArgs(ctrl)
+
+			project := &datastore.Project{
+				UID:    "project-id-1",
+				Type:   datastore.OutgoingProject,
+				Config: &datastore.ProjectConfig{Strategy: &datastore.DefaultStrategyConfig, DeliveryMode: tc.projectMode},
+			}
+
+			event := &datastore.Event{UID: ulid.Make().String(), ProjectID: "project-id-1", EventType: "invoice.paid", Data: []byte(`{}`)}
+
+			subscriptions := []datastore.Subscription{
+				{UID: "sub-id-1", Type: datastore.SubscriptionTypeAPI, EndpointID: "endpoint-id-1", DeliveryMode: tc.subMode},
+				{UID: "sub-id-2", Type: datastore.SubscriptionTypeAPI, EndpointID: "endpoint-id-2"},
+			}
+
+			e, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+			e.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), "project-id-1").
+				DoAndReturn(func(_ context.Context, id, _ string) (*datastore.Endpoint, error) {
+					return &datastore.Endpoint{UID: id, Status: datastore.ActiveEndpointStatus}, nil
+				}).Times(2)
+
+			modes := map[string]datastore.DeliveryMode{}
+			ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+			ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, deliveries []*datastore.EventDelivery) ([]*datastore.EventDelivery, error) {
+					for _, d := range deliveries {
+						modes[d.SubscriptionID] = d.DeliveryMode
+					}
+					return deliveries, nil
+				}).Times(1)
+
+			q, _ := args.eventQueue.(*mocks.MockQueuer)
+			q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Return(nil).Times(2)
+
+			err := writeEventDeliveriesToQueue(context.Background(), subscriptions, event, project, args.eventDeliveryRepo, args.eventQueue, args.deviceRepo, args.endpointRepo, args.licenser)
+			require.NoError(t, err)
+
+			// the subscription's own mode wins, the other one inherits the project's
+			require.Equal(t, tc.want, modes)
+		})
+	}
+}
+
+func TestWriteEventDeliveriesRetriedFanOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	args := provideArgs(ctrl)
+
+	project := &datastore.Project{
+		UID:    "project-id-1",
+		Type:   datastore.OutgoingProject,
+		Config: &datastore.ProjectConfig{Strategy: &datastore.DefaultStrategyConfig},
+	}
+
+	event := &datastore.Event{
+		UID: ulid.Make().String(), ProjectID: "project-id-1", EventType: "invoice.paid", Data: []byte(`{}`),
+		CreatedAt: time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond),
+	}
+
+	subscriptions := []datastore.Subscription{
+		{UID: "sub-id-1", Type: datastore.SubscriptionTypeAPI, EndpointID: "endpoint-id-1"},
+	}
+
+	e, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+	e.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{UID: "endpoint-id-1", Status: datastore.ActiveEndpointStatus}, nil).Times(2)
+
+	// stands in for the deduplication keys table, keyed on (project_id, deduplication_key)
+	stored := map[string]bool{}
+	ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+	ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, deliveries []*datastore.EventDelivery) ([]*datastore.EventDelivery, error) {
+			created := make([]*datastore.EventDelivery, 0, len(deliveries))
+			for _, d := range deliveries {
+				require.True(t, d.CreatedAt.After(event.CreatedAt))
+
+				key := fmt.Sprintf("%s/%s", d.ProjectID, d.DeduplicationKey)
+				if stored[key] {
+					continue
+				}
+				stored[key] = true
+				created = append(created, d)
+			}
+			return created, nil
+		}).Times(2)
+
+	q, _ := args.eventQueue.(*mocks.MockQueuer)
+	q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Return(nil).Times(1)
+
+	err := writeEventDeliveriesToQueue(context.Background(), subscriptions, event, project, args.eventDeliveryRepo, args.eventQueue, args.deviceRepo, args.endpointRepo, args.licenser)
+	require.NoError(t, err)
+
+	// the retried fan-out recreates the same delivery, which is skipped
+	err = writeEventDeliveriesToQueue(context.Background(), subscriptions, event, project, args.eventDeliveryRepo, args.eventQueue, args.deviceRepo, args.endpointRepo, args.licenser)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+}
+
+func TestWriteEventDeliveriesPayloadDiff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	args := provideArgs(ctrl)
+
+	project := &datastore.Project{
+		UID:    "project-id-1",
+		Type:   datastore.OutgoingProject,
+		Config: &datastore.ProjectConfig{Strategy: &datastore.DefaultStrategyConfig},
+	}
+
+	subscriptions := []datastore.Subscription{
+		{UID: "sub-id-1", Type: datastore.SubscriptionTypeAPI, EndpointID: "endpoint-id-1", DiffKey: "data.id"},
+	}
+
+	e, _ := args.endpointRepo.(*mocks.MockEndpointRepository)
+	e.EXPECT().FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{UID: "endpoint-id-1", Status: datastore.ActiveEndpointStatus}, nil).AnyTimes()
+
+	// an in-memory snapshot store
+	snapshots := map[string][]byte{}
+	ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+	ed.EXPECT().SwapEntitySnapshot(gomock.Any(), "project-id-1", "sub-id-1", "cus_1", gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, subscriptionID, entityKey, _ string, _ time.Time, payload []byte) ([]byte, error) {
+			previous := snapshots[subscriptionID+entityKey]
+			snapshots[subscriptionID+entityKey] = payload
+			return previous, nil
+		}).Times(2)
+
+	var delivered, fullDocuments []string
+	ed.EXPECT().CreateEventDeliveries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, deliveries []*datastore.EventDelivery) ([]*datastore.EventDelivery, error) {
+			for _, d := range deliveries {
+				delivered = append(delivered, string(d.Metadata.Data))
+				fullDocuments = append(fullDocuments, string(d.Metadata.FullDocument))
+			}
+			return deliveries, nil
+		}).Times(2)
+
+	q, _ := args.eventQueue.(*mocks.MockQueuer)
+	q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Return(nil).Times(2)
+
+	created := &datastore.Event{
+		UID: ulid.Make().String(), ProjectID: "project-id-1", EventType: "customer.created",
+		Data: []byte(`{"data":{"id":"cus_1","name":"Ada","email":"ada@example.com","address":{"city":"Lagos","zip":"100001"}}}`),
+	}
+	updated := &datastore.Event{
+		UID: ulid.Make().String(), ProjectID: "project-id-1", EventType: "customer.updated",
+		Data: []byte(`{"data":{"id":"cus_1","name":"Ada","address":{"city":"Abuja","zip":"100001"}}}`),
+	}
+
+	err := writeEventDeliveriesToQueue(context.Background(), subscriptions, created, project, args.eventDeliveryRepo, args.eventQueue, args.deviceRepo, args.endpointRepo, args.licenser)
+	require.NoError(t, err)
+
+	err = writeEventDeliveriesToQueue(context.Background(), subscriptions, updated, project, args.eventDeliveryRepo, args.eventQueue, args.deviceRepo, args.endpointRepo, args.licenser)
+	require.NoError(t, err)
+
+	require.Len(t, delivered, 2)
+
+	// the first event of the entity is delivered whole
+	require.JSONEq(t, string(created.Data), delivered[0])
+	require.Empty(t, fullDocuments[0])
+
+	// the update only carries the entity's id and what changed, the removed
+	// email as null, the whole document is kept for when it is asked for
+	require.JSONEq(t, `{"data":{"id":"cus_1","address":{"city":"Abuja"},"email":null}}`, delivered[1])
+	require.JSONEq(t, string(updated.Data), fullDocuments[1])
+}
@@ -5,17 +5,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
 	"github.com/frain-dev/convoy/internal/pkg/tracer"
 	"github.com/frain-dev/convoy/pkg/circuit_breaker"
+	"github.com/frain-dev/convoy/pkg/signature"
 
 	"time"
 
 	"github.com/frain-dev/convoy/internal/pkg/license"
 
 	"github.com/frain-dev/convoy/internal/pkg/limiter"
+	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
 
 	"github.com/frain-dev/convoy/pkg/msgpack"
 
@@ -35,7 +40,7 @@ import (
 	"github.com/hibiken/asynq"
 )
 
-func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDeliveryRepo datastore.EventDeliveryRepository, licenser license.Licenser, projectRepo datastore.ProjectRepository, q queue.Queuer, rateLimiter limiter.RateLimiter, dispatch *net.Dispatcher, attemptsRepo datastore.DeliveryAttemptsRepository, circuitBreakerManager *circuit_breaker.CircuitBreakerManager, featureFlag *fflag.FFlag, tracerBackend tracer.Backend) func(context.Context, *asynq.Task) error {
+func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDeliveryRepo datastore.EventDeliveryRepository, licenser license.Licenser, projectRepo datastore.ProjectRepository, subRepo datastore.SubscriptionRepository, q queue.Queuer, rateLimiter limiter.RateLimiter, dispatch *net.Dispatcher, attemptsRepo datastore.DeliveryAttemptsRepository, circuitBreakerManager *circuit_breaker.CircuitBreakerManager, featureFlag *fflag.FFlag, tracerBackend tracer.Backend) func(context.Context, *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) (err error) {
 		// Start a new trace span for event delivery
 		traceStartTime := time.Now()
@@ -52,6 +57,8 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 				return
 			}
 
+			delayDuration = deferDelay(err, delayDuration)
+
 			// set the error to nil, so it's removed from the event queue
 			err = nil
 
@@ -95,9 +102,6 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
 			return &DeliveryError{Err: err}
 		}
-		eventDelivery.Metadata.MaxRetrySeconds = cfg.MaxRetrySeconds
-
-		delayDuration = retrystrategies.NewRetryStrategyFromMetadata(*eventDelivery.Metadata).NextDuration(eventDelivery.Metadata.NumTrials)
 
 		project, err := projectRepo.FetchProjectByID(ctx, eventDelivery.ProjectID)
 		if err != nil {
@@ -105,6 +109,8 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			return &DeliveryError{Err: err}
 		}
 
+		delayDuration = nextRetryDelay(ctx, eventDelivery, project, cfg.MaxRetrySeconds)
+
 		endpoint, err := endpointRepo.FindEndpointByID(ctx, eventDelivery.EndpointID, eventDelivery.ProjectID)
 		if err != nil {
 			if errors.Is(err, datastore.ErrEndpointNotFound) {
@@ -126,13 +132,67 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 		attributes["endpoint.id"] = endpoint.UID
 		attributes["event.id"] = eventDelivery.EventID
 
-		switch eventDelivery.Status {
-		case datastore.ProcessingEventStatus,
-			datastore.SuccessEventStatus:
+		// a claimed delivery is already processing, but nothing is sending it
+		switch {
+		case eventDelivery.Status == datastore.ProcessingEventStatus && !data.Claimed,
+			eventDelivery.Status == datastore.SuccessEventStatus:
 			tracerBackend.Capture(ctx, "event.delivery.success", attributes, traceStartTime, time.Now())
 			return nil
 		}
 
+		ctx, span := startAttemptSpan(ctx, endpoint, eventDelivery)
+		defer func() { endAttemptSpan(span, err) }()
+
+		subscription, err := findDeliverySubscription(ctx, subRepo, eventDelivery)
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+			return &DeliveryError{Err: err}
+		}
+
+		if subscription != nil && subscription.Paused {
+			// the delivery keeps its status and trials, it is only pushed
+			// to the retry queue until the subscription is resumed
+			log.FromContext(ctx).Debugf("subscription %s is paused, deferring event delivery %s", eventDelivery.SubscriptionID, eventDelivery.UID)
+			delayDuration = defaultEventDelay
+			tracerBackend.Capture(ctx, "event.delivery.deferred", attributes, traceStartTime, time.Now())
+			return &DeliveryError{Err: ErrSubscriptionPaused}
+		}
+
+		hold, err := holdOrderedDelivery(ctx, eventDeliveryRepo, eventDelivery, time.Now())
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+			return &DeliveryError{Err: err}
+		}
+
+		if hold {
+			log.FromContext(ctx).Debugf("an earlier delivery to %s is still pending, holding ordered event delivery %s", endpoint.Url, eventDelivery.UID)
+			delayDuration = orderedDeliveryDelay
+			tracerBackend.Capture(ctx, "event.delivery.deferred", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrOrderedDeliveryPending, delay: orderedDeliveryDelay}
+		}
+
+		releaseProject, err := acquireProjectDeliverySlot(ctx, rateLimiter, project, endpoint)
+		if err != nil {
+			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery_id": data.EventDeliveryID}).
+				WithError(err).
+				Debugf("too many deliveries in flight for project %s, limit of %v has been reached", project.UID, project.Config.MaxConcurrentDeliveries)
+
+			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrProjectConcurrencyLimit, delay: projectConcurrencyDelay}
+		}
+		defer releaseProject()
+
+		release, err := acquireDeliverySlot(ctx, rateLimiter, endpoint)
+		if err != nil {
+			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery_id": data.EventDeliveryID}).
+				WithError(err).
+				Debugf("too many deliveries in flight to %s, limit of %v has been reached", endpoint.Url, endpoint.MaxConcurrentDeliveries)
+
+			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrConcurrencyLimit, delay: delayDuration}
+		}
+		defer release()
+
 		err = rateLimiter.AllowWithDuration(ctx, endpoint.UID, endpoint.RateLimit, int(endpoint.RateLimitDuration))
 		if err != nil {
 			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery_id": data.EventDeliveryID}).
@@ -143,12 +203,48 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			return &RateLimitError{Err: ErrRateLimit, delay: time.Duration(endpoint.RateLimitDuration) * time.Second}
 		}
 
+		if cfg.GlobalEgressRate > 0 {
+			err = rateLimiter.Allow(ctx, globalEgressRateLimitKey, cfg.GlobalEgressRate)
+			if err != nil {
+				log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery_id": data.EventDeliveryID}).
+					WithError(err).
+					Debugf("global egress limit of %v reqs/s has been reached", cfg.GlobalEgressRate)
+
+				tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+				return &RateLimitError{Err: ErrGlobalEgressRateLimit, delay: time.Second}
+			}
+		}
+
+		reportProbe := circuit_breaker.ProbeDone(func(bool) {})
 		if featureFlag.CanAccessFeature(fflag.CircuitBreaker) && licenser.CircuitBreaking() {
-			breakerErr := circuitBreakerManager.CanExecute(ctx, endpoint.UID)
+			probeDone, breakerErr := canExecute(ctx, circuitBreakerManager, cfg.CircuitBreaker, endpoint.UID)
 			if breakerErr != nil {
-				tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
-				return &CircuitBreakerError{Err: breakerErr}
+				if !data.ManualRetry || !cfg.CircuitBreaker.SkipForManualRetries {
+					tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+					return breakerError(breakerErr)
+				}
+
+				// the user explicitly asked for this attempt, the outcome is
+				// still recorded and sampled by the breaker like any other
+				log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery_id": data.EventDeliveryID}).
+					WithError(breakerErr).
+					Info("skipping circuit breaker for manual retry")
 			}
+
+			// hands the probe back when we return before sending it
+			defer probeDone(false)
+			reportProbe = probeDone
+		}
+
+		wait, err := spaceDelivery(ctx, rateLimiter, endpoint)
+		if err != nil {
+			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery_id": data.EventDeliveryID}).
+				WithError(err).
+				Debugf("deferring delivery to %s by %s, deliveries are at least %vms apart", endpoint.Url, wait, endpoint.MinDeliveryIntervalMs)
+
+			delayDuration = wait
+			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrMinDeliveryInterval, delay: wait}
 		}
 
 		err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, project.UID, *eventDelivery, datastore.ProcessingEventStatus)
@@ -177,41 +273,73 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			return nil
 		}
 
-		sig := newSignature(endpoint, project, json.RawMessage(eventDelivery.Metadata.Raw))
-		header, err := sig.ComputeHeaderValue()
+		payload, err := deliveryPayload(endpoint, subscription, eventDelivery)
 		if err != nil {
 			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
-			return &DeliveryError{Err: err}
+			return failUnencodablePayload(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+		}
+
+		payload, err = encodeDeliveryBody(endpoint, payload)
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+			return failUnencodablePayload(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+		}
+
+		sig := newSignature(endpoint, project, payload)
+		signed, err := shouldSign(ctx, project, endpoint, eventDelivery)
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+			return failUnsignableDelivery(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+		}
+
+		var header string
+		if signed {
+			header, err = signDelivery(ctx, dispatch, project, endpoint, eventDelivery, sig)
+			if err != nil {
+				tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
+				if errors.Is(err, signature.ErrFailedToEncodePayload) {
+					return failUnencodablePayload(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				if errors.Is(err, signature.ErrEmptySignatureScheme) {
+					return failUnsignableDelivery(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				if errors.Is(err, ErrInvalidDeliveryURL) {
+					return failInvalidDeliveryURL(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				if errors.Is(err, signature.ErrSigningProxyFailed) {
+					releaseForRetry(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				return &DeliveryError{Err: err}
+			}
 		}
 
 		targetURL := endpoint.Url
 		if !util.IsStringEmpty(eventDelivery.URLQueryParams) {
 			targetURL, err = url.ConcatQueryParams(endpoint.Url, eventDelivery.URLQueryParams)
 			if err != nil {
-				log.FromContext(ctx).WithError(err).Error("failed to concat url query params")
 				tracerBackend.Capture(ctx, "event.delivery.error", attributes, traceStartTime, time.Now())
-				return &DeliveryError{Err: err}
+				return failInvalidDeliveryURL(ctx, eventDeliveryRepo, project.UID, eventDelivery, fmt.Errorf("%w: %v", ErrInvalidDeliveryURL, err))
 			}
 		}
 
 		attemptStatus := false
 		httpDispatchStart := time.Now()
 
-		if project.Config.AddEventIDTraceHeaders {
-			if eventDelivery.Headers == nil {
-				eventDelivery.Headers = httpheader.HTTPHeader{}
-			}
-			eventDelivery.Headers["X-Convoy-EventDelivery-ID"] = []string{eventDelivery.UID}
-			eventDelivery.Headers["X-Convoy-Event-ID"] = []string{eventDelivery.EventID}
+		addTraceHeaders(project, eventDelivery)
+		addMetadataHeaders(project, eventDelivery)
+		if signed {
+			addSecretEpochHeader(project, endpoint, eventDelivery)
 		}
 
-		var httpDuration time.Duration
-		if endpoint.HttpTimeout == 0 || !licenser.AdvancedEndpointMgmt() {
-			httpDuration = convoy.HTTP_TIMEOUT_IN_DURATION
+		httpDuration := deliveryTimeout(cfg.Dispatcher, licenser, endpoint)
+		sendCtx := withDeliveryMode(withContentType(withDigestCredentials(ctx, endpoint), endpoint), eventDelivery)
+		var resp *net.Response
+		if signed {
+			resp, err = dispatch.SendWebhook(sendCtx, endpoint.GetHttpMethod(), targetURL, sig.Payload, project.Config.Signature.Header.String(), header, int64(cfg.MaxResponseSize), eventDelivery.Headers, eventDelivery.IdempotencyKey, httpDuration)
 		} else {
-			httpDuration = time.Duration(endpoint.HttpTimeout) * time.Second
+			resp, err = dispatch.SendUnsignedWebhook(sendCtx, endpoint.GetHttpMethod(), targetURL, sig.Payload, int64(cfg.MaxResponseSize), eventDelivery.Headers, eventDelivery.IdempotencyKey, httpDuration)
 		}
-		resp, err := dispatch.SendWebhook(ctx, targetURL, sig.Payload, project.Config.Signature.Header.String(), header, int64(cfg.MaxResponseSize), eventDelivery.Headers, eventDelivery.IdempotencyKey, httpDuration)
+		release()
 
 		status := "-"
 		statusCode := 0
@@ -219,13 +347,16 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			status = resp.Status
 			statusCode = resp.StatusCode
 		}
+		reportProbe(err != nil || statusCode < 200 || statusCode > 299)
+		recordTimeout(err, resp, eventDelivery, httpDuration)
 
 		duration := time.Since(httpDispatchStart)
+		recordAttemptResponse(span, statusCode, duration, err)
 		// log request details
 		requestLogger := log.FromContext(ctx).WithFields(log.Fields{
 			"status":          status,
 			"uri":             targetURL,
-			"method":          convoy.HttpPost,
+			"method":          endpoint.GetHttpMethod(),
 			"duration":        duration,
 			"eventDeliveryID": eventDelivery.UID,
 		})
@@ -240,28 +371,17 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 
 			// register latency
 			mm := metrics.GetDPInstance(licenser)
-			mm.RecordEndToEndLatency(eventDelivery)
+			mm.RecordEndToEndLatency(ctx, eventDelivery)
 		} else {
 			requestLogger.Errorf("%s", eventDelivery.UID)
 			done = false
+			delayDuration = retryAfterDelay(ctx, resp, endpoint, delayDuration, eventDelivery.Metadata.MaxRetrySeconds)
 
-			// For at-most-once delivery, only retry on network failures
 			if eventDelivery.DeliveryMode == datastore.AtMostOnceDeliveryMode {
-				if retryableForAtMostOnceDeliveryMode(resp.StatusCode) {
-					// Network error - retry
-					eventDelivery.Status = datastore.RetryEventStatus
-					nextTime := time.Now().Add(delayDuration)
-					eventDelivery.Metadata.NextSendTime = nextTime
-					attempts := eventDelivery.Metadata.NumTrials + 1
-
-					log.FromContext(ctx).Errorf("%s next retry time is %s (strategy = %s, delay = %d, attempts = %d/%d)\n", eventDelivery.UID,
-						nextTime.Format(time.ANSIC), eventDelivery.Metadata.Strategy, eventDelivery.Metadata.IntervalSeconds, attempts, eventDelivery.Metadata.RetryLimit)
-				} else {
-					// Got a response (even if it's an error status code) - mark as failed
-					eventDelivery.Status = datastore.FailureEventStatus
-					eventDelivery.Description = fmt.Sprintf("Endpoint returned status code %d", statusCode)
-					done = true
-				}
+				// At-most-once delivery - the only attempt is final, whether
+				// the endpoint answered or couldn't be reached at all
+				failAtMostOnceDelivery(eventDelivery, statusCode, err)
+				done = true
 			} else {
 				// At-least-once delivery - retry on any failure
 				eventDelivery.Status = datastore.RetryEventStatus
@@ -322,7 +442,7 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 			}
 		}
 
-		attempt := parseAttemptFromResponse(eventDelivery, endpoint, resp, attemptStatus)
+		attempt := parseAttemptFromResponse(eventDelivery, endpoint, resp, attemptStatus, cfg.GetMaxAttemptBodyBytes())
 
 		eventDelivery.Metadata.NumTrials++
 
@@ -382,6 +502,335 @@ func ProcessEventDelivery(endpointRepo datastore.EndpointRepository, eventDelive
 	}
 }
 
-func retryableForAtMostOnceDeliveryMode(statusCode int) bool {
-	return statusCode < 100
+// failAtMostOnceDelivery marks an at-most-once delivery failed after its
+// attempt, describing the transport error when the request didn't complete
+// and the status code the endpoint returned when it did.
+func failAtMostOnceDelivery(eventDelivery *datastore.EventDelivery, statusCode int, err error) {
+	eventDelivery.Status = datastore.FailureEventStatus
+	if err != nil {
+		eventDelivery.Description = fmt.Sprintf("Request to the endpoint failed: %s", err)
+		return
+	}
+
+	eventDelivery.Description = fmt.Sprintf("Endpoint returned status code %d", statusCode)
+}
+
+// findDeliverySubscription returns the subscription an event delivery was
+// created for, or nil when it has none or it has since been deleted, in
+// which case the delivery is neither held nor transformed.
+func findDeliverySubscription(ctx context.Context, subRepo datastore.SubscriptionRepository, eventDelivery *datastore.EventDelivery) (*datastore.Subscription, error) {
+	if util.IsStringEmpty(eventDelivery.SubscriptionID) {
+		return nil, nil
+	}
+
+	subscription, err := subRepo.FindSubscriptionByID(ctx, eventDelivery.ProjectID, eventDelivery.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, datastore.ErrSubscriptionNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// canExecute checks the endpoint's circuit breaker and takes a probe when it is
+// half-open. When its state can't be read from the store the configured store
+// failure mode decides whether the delivery goes out or is deferred like it
+// would be for an open breaker.
+func canExecute(ctx context.Context, manager *circuit_breaker.CircuitBreakerManager, cfg config.CircuitBreakerConfiguration, key string) (circuit_breaker.ProbeDone, error) {
+	probeDone, err := manager.Allow(ctx, key)
+	if err == nil || circuit_breaker.IsStateError(err) {
+		return probeDone, err
+	}
+
+	if cfg.StoreFailureMode == config.FailOpenStoreFailureMode {
+		log.FromContext(ctx).WithError(err).Warnf("failed to read the circuit breaker state of %s, failing open", key)
+		return probeDone, nil
+	}
+
+	return probeDone, err
+}
+
+// deliveryTimeout is how long a delivery to endpoint can take, endpoints
+// without their own http timeout use the dispatcher's
+func deliveryTimeout(cfg config.DispatcherConfiguration, licenser license.Licenser, endpoint *datastore.Endpoint) time.Duration {
+	if endpoint.HttpTimeout != 0 && licenser.AdvancedEndpointMgmt() {
+		return time.Duration(endpoint.HttpTimeout) * time.Second
+	}
+
+	if cfg.Timeout != 0 {
+		return time.Duration(cfg.Timeout) * time.Second
+	}
+
+	return convoy.HTTP_TIMEOUT_IN_DURATION
+}
+
+// recordTimeout describes a delivery that failed because the endpoint took
+// longer than timeout to respond, on both the delivery and its attempt
+func recordTimeout(err error, resp *net.Response, eventDelivery *datastore.EventDelivery, timeout time.Duration) {
+	if resp == nil || !isTimeout(err) {
+		return
+	}
+
+	resp.Error = fmt.Sprintf("request timed out after %ds", int64(timeout.Seconds()))
+	eventDelivery.Description = resp.Error
+}
+
+// deferDelay returns how long a delivery sent to the retry queue waits,
+// retryDelay unless a limit that frees up soon turned it away. A delivery
+// deferred by its project's concurrency cap is tried again once a slot is
+// likely free, and one held back by the global egress limit or a ramping
+// breaker a second later.
+func deferDelay(err error, retryDelay time.Duration) time.Duration {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) &&
+		(errors.Is(rateLimitErr.Err, ErrProjectConcurrencyLimit) || errors.Is(rateLimitErr.Err, ErrGlobalEgressRateLimit)) {
+		return rateLimitErr.Delay()
+	}
+
+	var breakerErr *CircuitBreakerError
+	if errors.As(err, &breakerErr) && breakerErr.Delay() > 0 {
+		return breakerErr.Delay()
+	}
+
+	return retryDelay
+}
+
+// breakerError defers a delivery turned away by the endpoint's circuit breaker,
+// deliveries held back by a breaker ramping up after closing go out a second later
+func breakerError(err error) *CircuitBreakerError {
+	if errors.Is(err, circuit_breaker.ErrRecoveryRampLimited) {
+		return &CircuitBreakerError{Err: err, delay: time.Second}
+	}
+
+	return &CircuitBreakerError{Err: err}
+}
+
+// acquireDeliverySlot takes one of the endpoint's in-flight delivery slots
+// when it caps concurrent deliveries. The returned func gives the slot back
+// and is safe to call more than once.
+func acquireDeliverySlot(ctx context.Context, rateLimiter limiter.RateLimiter, endpoint *datastore.Endpoint) (func(), error) {
+	if endpoint.MaxConcurrentDeliveries <= 0 {
+		return func() {}, nil
+	}
+
+	key := fmt.Sprintf("endpoint_concurrency:%s", endpoint.UID)
+	return acquireSlot(ctx, rateLimiter, key, endpoint.MaxConcurrentDeliveries, endpoint)
+}
+
+// acquireProjectDeliverySlot takes one of the project's in-flight delivery
+// slots when it caps concurrent deliveries, so a project with a backlog
+// can't hold every worker while other projects' deliveries wait. The
+// returned func is the same as acquireDeliverySlot's.
+func acquireProjectDeliverySlot(ctx context.Context, rateLimiter limiter.RateLimiter, project *datastore.Project, endpoint *datastore.Endpoint) (func(), error) {
+	if project.Config == nil || project.Config.MaxConcurrentDeliveries == 0 {
+		return func() {}, nil
+	}
+
+	key := fmt.Sprintf("project_concurrency:%s", project.UID)
+	return acquireSlot(ctx, rateLimiter, key, int(project.Config.MaxConcurrentDeliveries), endpoint)
+}
+
+// acquireSlot takes one of the limit slots of key for a delivery to
+// endpoint, held for as long as the request can take.
+func acquireSlot(ctx context.Context, rateLimiter limiter.RateLimiter, key string, limit int, endpoint *datastore.Endpoint) (func(), error) {
+	timeout := max(endpoint.HttpTimeout, convoy.HTTP_TIMEOUT)
+
+	token, err := rateLimiter.Acquire(ctx, key, limit, time.Duration(timeout)*time.Second+deliverySlotMargin)
+	if err != nil {
+		return nil, err
+	}
+
+	released := false
+	return func() {
+		if released {
+			return
+		}
+		released = true
+
+		// the slot expires on its own if this fails, until then it's unusable
+		if err := rateLimiter.Release(context.WithoutCancel(ctx), key, token); err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("failed to release delivery slot %s", key)
+		}
+	}, nil
+}
+
+// spaceDelivery holds back a delivery that would start sooner than the
+// endpoint's min delivery interval after the previous one, and returns how
+// long it should be deferred by.
+func spaceDelivery(ctx context.Context, rateLimiter limiter.RateLimiter, endpoint *datastore.Endpoint) (time.Duration, error) {
+	if endpoint.MinDeliveryIntervalMs == 0 {
+		return 0, nil
+	}
+
+	interval := time.Duration(endpoint.MinDeliveryIntervalMs) * time.Millisecond
+	key := fmt.Sprintf("endpoint_interval:%s", endpoint.UID)
+
+	err := rateLimiter.AllowOncePer(ctx, key, interval)
+	if err != nil {
+		// the limiter couldn't tell how long is left, so wait out the whole interval
+		wait := rlimiter.GetRetryAfter(err)
+		if wait <= 0 {
+			wait = interval
+		}
+
+		return wait, err
+	}
+
+	return 0, nil
+}
+
+// addTraceHeaders adds the delivery and event id headers when the project
+// asks for them. The event id is sent under the same name as the event_id
+// metadata header, so enabling both doesn't send it twice.
+func addTraceHeaders(project *datastore.Project, eventDelivery *datastore.EventDelivery) {
+	if !project.Config.AddEventIDTraceHeaders {
+		return
+	}
+
+	eventIDHeader, _ := datastore.EventIDMetadataHeader.HeaderName()
+	setDeliveryHeader(eventDelivery, "X-Convoy-EventDelivery-ID", eventDelivery.UID)
+	setDeliveryHeader(eventDelivery, eventIDHeader, eventDelivery.EventID)
+}
+
+// setDeliveryHeader sets the header name of the delivery to value, replacing
+// any value set under a differently cased name, e.g. by an earlier attempt.
+func setDeliveryHeader(eventDelivery *datastore.EventDelivery, name, value string) {
+	if eventDelivery.Headers == nil {
+		eventDelivery.Headers = httpheader.HTTPHeader{}
+	}
+
+	for k := range eventDelivery.Headers {
+		if strings.EqualFold(k, name) {
+			delete(eventDelivery.Headers, k)
+		}
+	}
+	eventDelivery.Headers[name] = []string{value}
+}
+
+// addMetadataHeaders adds the event metadata headers the project is
+// configured to send to the delivery's headers. Empty values are left out.
+func addMetadataHeaders(project *datastore.Project, eventDelivery *datastore.EventDelivery) {
+	for _, h := range project.Config.MetadataHeaders {
+		header := datastore.DeliveryMetadataHeader(h)
+
+		name, ok := header.HeaderName()
+		if !ok {
+			continue
+		}
+
+		value := header.Value(eventDelivery)
+		if util.IsStringEmpty(value) {
+			continue
+		}
+
+		setDeliveryHeader(eventDelivery, name, value)
+	}
+}
+
+// secretEpochHeader carries the rotation epoch of the secret a delivery is signed with
+const secretEpochHeader = "X-Convoy-Secret-Epoch"
+
+// addSecretEpochHeader tells the endpoint which rotation of its secret the
+// delivery is signed with, when the project is configured to.
+func addSecretEpochHeader(project *datastore.Project, endpoint *datastore.Endpoint, eventDelivery *datastore.EventDelivery) {
+	if !project.Config.GetSignatureConfig().SendsSecretEpoch() {
+		return
+	}
+
+	if eventDelivery.Headers == nil {
+		eventDelivery.Headers = httpheader.HTTPHeader{}
+	}
+	eventDelivery.Headers[secretEpochHeader] = []string{strconv.FormatInt(endpoint.SecretEpoch(), 10)}
+}
+
+// withDigestCredentials lets the dispatcher answer the endpoint's digest
+// authentication challenge, endpoints using other schemes get ctx as is.
+func withDigestCredentials(ctx context.Context, endpoint *datastore.Endpoint) context.Context {
+	auth := endpoint.GetDigestAuth()
+	if auth == nil {
+		return ctx
+	}
+
+	return net.ContextWithDigestCredentials(ctx, &net.DigestCredentials{Username: auth.Username, Password: auth.Password})
+}
+
+// withDeliveryMode keeps the dispatcher from resending an at most once
+// delivery on a transient network error, the endpoint may have received it.
+func withDeliveryMode(ctx context.Context, eventDelivery *datastore.EventDelivery) context.Context {
+	if eventDelivery.DeliveryMode == datastore.AtMostOnceDeliveryMode {
+		return net.ContextWithoutTransientRetries(ctx)
+	}
+
+	return ctx
+}
+
+// retryAfterDelay returns the delay a Retry-After header in resp asks for,
+// in seconds or as an HTTP date, clamped to the endpoint's retry after floor
+// and ceiling and never longer than maxRetrySeconds. When there's no such
+// header fallback is returned as is.
+func retryAfterDelay(ctx context.Context, resp *net.Response, endpoint *datastore.Endpoint, fallback time.Duration, maxRetrySeconds uint64) time.Duration {
+	if resp == nil || resp.ResponseHeader == nil {
+		return fallback
+	}
+
+	v := strings.TrimSpace(resp.ResponseHeader.Get("Retry-After"))
+	if v == "" {
+		return fallback
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(v); err == nil {
+		delay = time.Until(at)
+	} else {
+		return fallback
+	}
+
+	floor := time.Duration(endpoint.RetryAfterFloorSeconds) * time.Second
+	ceiling := time.Duration(endpoint.RetryAfterCeilingSeconds) * time.Second
+
+	clamped := max(delay, floor)
+	if ceiling > 0 {
+		clamped = min(clamped, ceiling)
+	}
+
+	if maxRetrySeconds > 0 {
+		clamped = min(clamped, time.Duration(maxRetrySeconds)*time.Second)
+	}
+
+	if clamped != delay {
+		log.FromContext(ctx).Infof("clamped the Retry-After delay of %s from endpoint %s to %s", delay, endpoint.UID, clamped)
+	}
+
+	return clamped
+}
+
+// nextRetryDelay computes how long to wait before retrying the delivery with
+// its retry strategy, jitters it by the project's jitter percent, and clamps
+// it to the smaller of the project's and the instance's max retry seconds.
+func nextRetryDelay(ctx context.Context, eventDelivery *datastore.EventDelivery, project *datastore.Project, instanceMaxRetrySeconds uint64) time.Duration {
+	maxRetrySeconds := instanceMaxRetrySeconds
+	var jitterPercent uint64
+	if project.Config != nil {
+		maxRetrySeconds = project.Config.GetMaxRetrySeconds(instanceMaxRetrySeconds)
+		jitterPercent = project.Config.GetStrategyConfig().JitterPercent
+	}
+	eventDelivery.Metadata.MaxRetrySeconds = maxRetrySeconds
+
+	delay := retrystrategies.NewRetryStrategyFromMetadata(*eventDelivery.Metadata).NextDuration(eventDelivery.Metadata.NumTrials)
+	delay = jitterDelay(delay, jitterPercent, retryJitter)
+	if maxRetrySeconds == 0 {
+		return delay
+	}
+
+	ceiling := time.Duration(maxRetrySeconds) * time.Second
+	if delay > ceiling {
+		log.FromContext(ctx).Infof("clamped the %s retry delay of event delivery %s from %s to %s", eventDelivery.Metadata.Strategy, eventDelivery.UID, delay, ceiling)
+		return ceiling
+	}
+
+	return delay
 }
@@ -2,19 +2,30 @@ package task
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
+	rlimiter "github.com/frain-dev/convoy/internal/pkg/limiter/redis"
 	"github.com/frain-dev/convoy/pkg/log"
 
 	"github.com/frain-dev/convoy/net"
 	cb "github.com/frain-dev/convoy/pkg/circuit_breaker"
 	"github.com/frain-dev/convoy/pkg/clock"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/signature"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
 
 	"time"
 
@@ -36,6 +47,24 @@ func TestProcessEventDelivery(t *testing.T) {
 		w.WriteHeader(400)
 	}))
 	defer badRequestServer.Close()
+
+	// nothing listens on a closed server's address, so requests to it fail
+	// before there's any response
+	unreachableServer := httptest.NewServer(http.NotFoundHandler())
+	unreachableServer.Close()
+
+	// signing proxies are reached through the dispatcher, like endpoints
+	signerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"signature": "t=1,v1=from-proxy"}`))
+	}))
+	defer signerServer.Close()
+
+	failingSignerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error": "hsm unavailable"}`))
+	}))
+	defer failingSignerServer.Close()
+
 	tt := []struct {
 		name          string
 		cfgPath       string
@@ -123,6 +152,125 @@ func TestProcessEventDelivery(t *testing.T) {
 				l.EXPECT().IpRules().Times(2).Return(true)
 			},
 		},
+		{
+			name:          "Global egress rate limit reached - should reschedule",
+			cfgPath:       "./testdata/Config/basic-convoy-global-egress-rate.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						RateLimit:         10,
+						RateLimitDuration: 60,
+						Status:            datastore.ActiveEndpointStatus,
+					}, nil)
+
+				// the endpoint still has headroom, only the instance-wide budget is exhausted
+				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), 10, 60).Return(nil)
+				r.EXPECT().Allow(gomock.Any(), globalEgressRateLimitKey, 10).Return(errors.New("rate limit exceeded"))
+
+				o.EXPECT().FetchProjectByID(gomock.Any(), gomock.Any()).Return(&datastore.Project{Config: &datastore.DefaultProjectConfig}, nil)
+				m.EXPECT().
+					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						SubscriptionID: "sub-id-1",
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:       datastore.ScheduledEventStatus,
+						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+					}, nil).Times(1)
+
+				q.EXPECT().Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).Times(1).Return(nil)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+				l.EXPECT().UseForwardProxy().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(2).Return(true)
+			},
+		},
+		{
+			name:          "Payload cannot be encoded - should fail without retry",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						ProjectID:         "123",
+						RateLimit:         10,
+						RateLimitDuration: 60,
+						Secrets: []datastore.Secret{
+							{Value: "secret"},
+						},
+						Status: datastore.ActiveEndpointStatus,
+					}, nil)
+
+				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+
+				m.EXPECT().
+					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						SubscriptionID: "sub-id-1",
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"`),
+							Raw:             `{"event": "invoice.completed"`,
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:       datastore.ScheduledEventStatus,
+						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+					}, nil).Times(1)
+
+				o.EXPECT().
+					FetchProjectByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Project{
+						Config: &datastore.ProjectConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: "X-Convoy-Signature",
+								Versions: []datastore.SignatureVersion{
+									{
+										UID:      "abc",
+										Hash:     "SHA256",
+										Encoding: datastore.HexEncoding,
+									},
+								},
+							},
+							SSL:       &datastore.DefaultSSLConfig,
+							Strategy:  &datastore.DefaultStrategyConfig,
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+					Return(nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.FailureEventStatus).
+					DoAndReturn(func(_ context.Context, _ string, ed datastore.EventDelivery, _ datastore.EventDeliveryStatus) error {
+						require.Equal(t, ErrPayloadEncode.Error(), ed.Description)
+						return nil
+					}).Times(1)
+
+				// the delivery is never attempted or written back to the retry queue
+				q.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(0)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+				l.EXPECT().UseForwardProxy().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(2).Return(true)
+			},
+		},
 		{
 			name:          "Endpoint does not respond with 2xx",
 			cfgPath:       "./testdata/Config/basic-convoy.json",
@@ -306,8 +454,8 @@ func TestProcessEventDelivery(t *testing.T) {
 			},
 		},
 		{
-			name:          "Manual retry - disable endpoint - failed",
-			cfgPath:       "./testdata/Config/basic-convoy.json",
+			name:          "Unsigned event type - should omit signature header",
+			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
 				UID: "",
@@ -315,35 +463,36 @@ func TestProcessEventDelivery(t *testing.T) {
 			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
 				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.Endpoint{
-						ProjectID: "123",
 						Secrets: []datastore.Secret{
 							{Value: "secret"},
 						},
 						RateLimit:         10,
 						RateLimitDuration: 60,
+						ProjectID:         "123",
 						Status:            datastore.ActiveEndpointStatus,
 					}, nil)
 
 				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
-				a.EXPECT().
-					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), datastore.InactiveEndpointStatus).
-					Return(nil).Times(1)
-
 				m.EXPECT().
 					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.EventDelivery{
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
-							NumTrials:       3,
+							NumTrials:       2,
 							RetryLimit:      3,
 							IntervalSeconds: 20,
 						},
+						EventType:    "invoice.completed",
 						Status:       datastore.ScheduledEventStatus,
 						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
 					}, nil).Times(1)
 
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
 				o.EXPECT().
 					FetchProjectByID(gomock.Any(), gomock.Any()).
 					Return(&datastore.Project{
@@ -358,10 +507,11 @@ func TestProcessEventDelivery(t *testing.T) {
 										Encoding: datastore.HexEncoding,
 									},
 								},
+								UnsignedEventTypes: []string{"invoice.completed"},
 							},
 							SSL: &datastore.DefaultSSLConfig,
 							Strategy: &datastore.StrategyConfiguration{
-								Type:       datastore.StrategyProvider("default"),
+								Type:       datastore.LinearStrategyProvider,
 								Duration:   60,
 								RetryCount: 1,
 							},
@@ -370,11 +520,15 @@ func TestProcessEventDelivery(t *testing.T) {
 						},
 					}, nil).Times(1)
 
-				m.EXPECT().
-					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				a.EXPECT().
+					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
-				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, attempt *datastore.DeliveryAttempt) error {
+						require.NotContains(t, attempt.RequestHeader, "X-Convoy-Signature")
+						return nil
+					}).Times(1)
 
 				m.EXPECT().
 					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -391,7 +545,7 @@ func TestProcessEventDelivery(t *testing.T) {
 				httpmock.Activate()
 
 				httpmock.RegisterResponder("POST", "https://google.com",
-					httpmock.NewStringResponder(400, ``))
+					httpmock.NewStringResponder(200, ``))
 
 				return func() {
 					httpmock.DeactivateAndReset()
@@ -399,7 +553,7 @@ func TestProcessEventDelivery(t *testing.T) {
 			},
 		},
 		{
-			name:          "Manual retry - disable endpoint - failed",
+			name:          "Event type not skip-listed - should include signature header",
 			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
@@ -408,12 +562,12 @@ func TestProcessEventDelivery(t *testing.T) {
 			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
 				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.Endpoint{
-						ProjectID: "123",
 						Secrets: []datastore.Secret{
 							{Value: "secret"},
 						},
 						RateLimit:         10,
 						RateLimitDuration: 60,
+						ProjectID:         "123",
 						Status:            datastore.ActiveEndpointStatus,
 					}, nil)
 
@@ -425,11 +579,13 @@ func TestProcessEventDelivery(t *testing.T) {
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
-							NumTrials:       3,
+							NumTrials:       2,
 							RetryLimit:      3,
 							IntervalSeconds: 20,
 						},
-						Status: datastore.ScheduledEventStatus,
+						EventType:    "invoice.completed",
+						Status:       datastore.ScheduledEventStatus,
+						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
 					}, nil).Times(1)
 
 				m.EXPECT().
@@ -450,6 +606,7 @@ func TestProcessEventDelivery(t *testing.T) {
 										Encoding: datastore.HexEncoding,
 									},
 								},
+								UnsignedEventTypes: []string{"heartbeat"},
 							},
 							SSL: &datastore.DefaultSSLConfig,
 							Strategy: &datastore.StrategyConfiguration{
@@ -466,7 +623,11 @@ func TestProcessEventDelivery(t *testing.T) {
 					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
-				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, attempt *datastore.DeliveryAttempt) error {
+						require.NotEmpty(t, attempt.RequestHeader["X-Convoy-Signature"])
+						return nil
+					}).Times(1)
 
 				m.EXPECT().
 					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -476,14 +637,14 @@ func TestProcessEventDelivery(t *testing.T) {
 
 				l.EXPECT().UseForwardProxy().Times(1).Return(true)
 				l.EXPECT().IpRules().Times(3).Return(true)
-				l.EXPECT().CircuitBreaking().Times(1).Return(false)
 				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(true)
+				l.EXPECT().CircuitBreaking().Times(1).Return(false)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
 
 				httpmock.RegisterResponder("POST", "https://google.com",
-					httpmock.NewStringResponder(400, ``))
+					httpmock.NewStringResponder(200, ``))
 
 				return func() {
 					httpmock.DeactivateAndReset()
@@ -491,8 +652,8 @@ func TestProcessEventDelivery(t *testing.T) {
 			},
 		},
 		{
-			name:          "Manual retry - disable endpoint - success",
-			cfgPath:       "./testdata/Config/basic-convoy.json",
+			name:          "Signing proxy configured - should use the proxy signature",
+			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
 				UID: "",
@@ -500,13 +661,12 @@ func TestProcessEventDelivery(t *testing.T) {
 			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
 				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.Endpoint{
-						ProjectID: "123",
-						Url:       "https://google.com?source=giphy",
 						Secrets: []datastore.Secret{
 							{Value: "secret"},
 						},
 						RateLimit:         10,
 						RateLimitDuration: 60,
+						ProjectID:         "123",
 						Status:            datastore.ActiveEndpointStatus,
 					}, nil)
 
@@ -515,21 +675,20 @@ func TestProcessEventDelivery(t *testing.T) {
 				m.EXPECT().
 					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.EventDelivery{
-						SubscriptionID: "sub-id-1",
-						Status:         datastore.ScheduledEventStatus,
-						URLQueryParams: "name=ref&category=food",
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
-							NumTrials:       4,
+							NumTrials:       2,
 							RetryLimit:      3,
 							IntervalSeconds: 20,
 						},
+						EventType:    "invoice.completed",
+						Status:       datastore.ScheduledEventStatus,
 						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
 					}, nil).Times(1)
 
-				a.EXPECT().
-					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), datastore.InactiveEndpointStatus).
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
 				o.EXPECT().
@@ -546,6 +705,7 @@ func TestProcessEventDelivery(t *testing.T) {
 										Encoding: datastore.HexEncoding,
 									},
 								},
+								ProxyURL: signerServer.URL,
 							},
 							SSL: &datastore.DefaultSSLConfig,
 							Strategy: &datastore.StrategyConfiguration{
@@ -558,11 +718,15 @@ func TestProcessEventDelivery(t *testing.T) {
 						},
 					}, nil).Times(1)
 
-				m.EXPECT().
-					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				a.EXPECT().
+					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
-				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, attempt *datastore.DeliveryAttempt) error {
+						require.Equal(t, "t=1,v1=from-proxy", attempt.RequestHeader["X-Convoy-Signature"])
+						return nil
+					}).Times(1)
 
 				m.EXPECT().
 					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -571,14 +735,14 @@ func TestProcessEventDelivery(t *testing.T) {
 				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
 				l.EXPECT().UseForwardProxy().Times(1).Return(true)
-				l.EXPECT().IpRules().Times(3).Return(true)
-				l.EXPECT().CircuitBreaking().Times(1).Return(false)
+				l.EXPECT().IpRules().Times(4).Return(false)
 				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(true)
+				l.EXPECT().CircuitBreaking().Times(1).Return(false)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
 
-				httpmock.RegisterResponder("POST", "https://google.com?category=food&name=ref&source=giphy",
+				httpmock.RegisterResponder("POST", "https://google.com",
 					httpmock.NewStringResponder(200, ``))
 
 				return func() {
@@ -587,7 +751,7 @@ func TestProcessEventDelivery(t *testing.T) {
 			},
 		},
 		{
-			name:          "Manual retry - disable endpoint - success",
+			name:          "Signing proxy failed - should retry the delivery",
 			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
@@ -596,33 +760,38 @@ func TestProcessEventDelivery(t *testing.T) {
 			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
 				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.Endpoint{
-						ProjectID: "123",
 						Secrets: []datastore.Secret{
 							{Value: "secret"},
 						},
 						RateLimit:         10,
 						RateLimitDuration: 60,
+						ProjectID:         "123",
 						Status:            datastore.ActiveEndpointStatus,
-					}, nil).Times(1)
+					}, nil)
 
 				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
 				m.EXPECT().
 					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.EventDelivery{
-						Status: datastore.ScheduledEventStatus,
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
-							NumTrials:       4,
+							NumTrials:       2,
 							RetryLimit:      3,
 							IntervalSeconds: 20,
 						},
+						EventType:    "invoice.completed",
+						Status:       datastore.ScheduledEventStatus,
 						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
 					}, nil).Times(1)
 
 				m.EXPECT().
-					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+					Return(nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.RetryEventStatus).
 					Return(nil).Times(1)
 
 				o.EXPECT().
@@ -639,6 +808,7 @@ func TestProcessEventDelivery(t *testing.T) {
 										Encoding: datastore.HexEncoding,
 									},
 								},
+								ProxyURL: failingSignerServer.URL,
 							},
 							SSL: &datastore.DefaultSSLConfig,
 							Strategy: &datastore.StrategyConfiguration{
@@ -651,37 +821,22 @@ func TestProcessEventDelivery(t *testing.T) {
 						},
 					}, nil).Times(1)
 
-				a.EXPECT().
-					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(nil).Times(1)
-
-				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
-
-				m.EXPECT().
-					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(nil).Times(1)
+				// the delivery is never dispatched, and goes to the retry queue
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(0)
+				q.EXPECT().Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).Return(nil).Times(1)
 
-				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 
 				l.EXPECT().UseForwardProxy().Times(1).Return(true)
-				l.EXPECT().IpRules().Times(3).Return(true)
-				l.EXPECT().CircuitBreaking().Times(1).Return(false)
-				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(3).Return(false)
 			},
 			nFn: func() func() {
-				httpmock.Activate()
-
-				httpmock.RegisterResponder("POST", "https://google.com",
-					httpmock.NewStringResponder(200, ``))
-
-				return func() {
-					httpmock.DeactivateAndReset()
-				}
+				return func() {}
 			},
 		},
 		{
-			name:          "Manual retry - disable endpoint - success - advanced endpoint mgmt false",
-			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
+			name:          "Manual retry - disable endpoint - failed",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
 				UID: "",
@@ -696,29 +851,28 @@ func TestProcessEventDelivery(t *testing.T) {
 						RateLimit:         10,
 						RateLimitDuration: 60,
 						Status:            datastore.ActiveEndpointStatus,
-					}, nil).Times(1)
+					}, nil)
 
 				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
+				a.EXPECT().
+					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), datastore.InactiveEndpointStatus).
+					Return(nil).Times(1)
+
 				m.EXPECT().
 					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.EventDelivery{
-						SubscriptionID: "sub-id-1",
-						Status:         datastore.ScheduledEventStatus,
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
-							NumTrials:       4,
+							NumTrials:       3,
 							RetryLimit:      3,
 							IntervalSeconds: 20,
 						},
+						Status:       datastore.ScheduledEventStatus,
 						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
 					}, nil).Times(1)
 
-				m.EXPECT().
-					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(nil).Times(1)
-
 				o.EXPECT().
 					FetchProjectByID(gomock.Any(), gomock.Any()).
 					Return(&datastore.Project{
@@ -736,7 +890,7 @@ func TestProcessEventDelivery(t *testing.T) {
 							},
 							SSL: &datastore.DefaultSSLConfig,
 							Strategy: &datastore.StrategyConfiguration{
-								Type:       datastore.LinearStrategyProvider,
+								Type:       datastore.StrategyProvider("default"),
 								Duration:   60,
 								RetryCount: 1,
 							},
@@ -745,7 +899,8 @@ func TestProcessEventDelivery(t *testing.T) {
 						},
 					}, nil).Times(1)
 
-				a.EXPECT().UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
 				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
@@ -758,14 +913,14 @@ func TestProcessEventDelivery(t *testing.T) {
 
 				l.EXPECT().UseForwardProxy().Times(1).Return(true)
 				l.EXPECT().IpRules().Times(3).Return(true)
+				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(true)
 				l.EXPECT().CircuitBreaking().Times(1).Return(false)
-				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(false)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
 
 				httpmock.RegisterResponder("POST", "https://google.com",
-					httpmock.NewStringResponder(200, ``))
+					httpmock.NewStringResponder(400, ``))
 
 				return func() {
 					httpmock.DeactivateAndReset()
@@ -773,7 +928,7 @@ func TestProcessEventDelivery(t *testing.T) {
 			},
 		},
 		{
-			name:          "Manual retry - send disable endpoint notification",
+			name:          "Manual retry - disable endpoint - failed",
 			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
@@ -782,31 +937,28 @@ func TestProcessEventDelivery(t *testing.T) {
 			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
 				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.Endpoint{
-						ProjectID:    "123",
-						SupportEmail: "test@gmail.com",
+						ProjectID: "123",
 						Secrets: []datastore.Secret{
 							{Value: "secret"},
 						},
 						RateLimit:         10,
-						Status:            datastore.ActiveEndpointStatus,
 						RateLimitDuration: 60,
-					}, nil).Times(1)
+						Status:            datastore.ActiveEndpointStatus,
+					}, nil)
 
 				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
 				m.EXPECT().
 					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.EventDelivery{
-						SubscriptionID: "sub-id-1",
-						Status:         datastore.ScheduledEventStatus,
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
-							NumTrials:       4,
+							NumTrials:       3,
 							RetryLimit:      3,
 							IntervalSeconds: 20,
 						},
-						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+						Status: datastore.ScheduledEventStatus,
 					}, nil).Times(1)
 
 				m.EXPECT().
@@ -839,7 +991,8 @@ func TestProcessEventDelivery(t *testing.T) {
 						},
 					}, nil).Times(1)
 
-				a.EXPECT().UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				a.EXPECT().
+					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
 				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
@@ -848,10 +1001,6 @@ func TestProcessEventDelivery(t *testing.T) {
 					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
-				q.EXPECT().
-					Write(convoy.NotificationProcessor, convoy.DefaultQueue, gomock.Any()).
-					Return(nil).Times(1)
-
 				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
 				l.EXPECT().UseForwardProxy().Times(1).Return(true)
@@ -863,7 +1012,7 @@ func TestProcessEventDelivery(t *testing.T) {
 				httpmock.Activate()
 
 				httpmock.RegisterResponder("POST", "https://google.com",
-					httpmock.NewStringResponder(200, ``))
+					httpmock.NewStringResponder(400, ``))
 
 				return func() {
 					httpmock.DeactivateAndReset()
@@ -871,8 +1020,8 @@ func TestProcessEventDelivery(t *testing.T) {
 			},
 		},
 		{
-			name:          "Manual retry - send endpoint enabled notification",
-			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
+			name:          "Manual retry - disable endpoint - success",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
 				UID: "",
@@ -880,16 +1029,15 @@ func TestProcessEventDelivery(t *testing.T) {
 			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
 				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.Endpoint{
-						ProjectID:    "123",
-						SupportEmail: "test@gmail.com",
+						ProjectID: "123",
+						Url:       "https://google.com?source=giphy",
 						Secrets: []datastore.Secret{
 							{Value: "secret"},
 						},
 						RateLimit:         10,
-						Url:               "https://google.com",
 						RateLimitDuration: 60,
 						Status:            datastore.ActiveEndpointStatus,
-					}, nil).Times(1)
+					}, nil)
 
 				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
@@ -898,6 +1046,7 @@ func TestProcessEventDelivery(t *testing.T) {
 					Return(&datastore.EventDelivery{
 						SubscriptionID: "sub-id-1",
 						Status:         datastore.ScheduledEventStatus,
+						URLQueryParams: "name=ref&category=food",
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
@@ -908,8 +1057,8 @@ func TestProcessEventDelivery(t *testing.T) {
 						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
 					}, nil).Times(1)
 
-				m.EXPECT().
-					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				a.EXPECT().
+					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), datastore.InactiveEndpointStatus).
 					Return(nil).Times(1)
 
 				o.EXPECT().
@@ -918,7 +1067,7 @@ func TestProcessEventDelivery(t *testing.T) {
 						LogoURL: "",
 						Config: &datastore.ProjectConfig{
 							Signature: &datastore.SignatureConfiguration{
-								Header: "X-Convoy-Signature",
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
 								Versions: []datastore.SignatureVersion{
 									{
 										UID:      "abc",
@@ -938,7 +1087,8 @@ func TestProcessEventDelivery(t *testing.T) {
 						},
 					}, nil).Times(1)
 
-				a.EXPECT().UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
 				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
@@ -947,10 +1097,6 @@ func TestProcessEventDelivery(t *testing.T) {
 					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
-				q.EXPECT().
-					Write(convoy.NotificationProcessor, convoy.DefaultQueue, gomock.Any()).
-					Return(nil).Times(1)
-
 				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
 				l.EXPECT().UseForwardProxy().Times(1).Return(true)
@@ -961,7 +1107,7 @@ func TestProcessEventDelivery(t *testing.T) {
 			nFn: func() func() {
 				httpmock.Activate()
 
-				httpmock.RegisterResponder("POST", "https://google.com",
+				httpmock.RegisterResponder("POST", "https://google.com?category=food&name=ref&source=giphy",
 					httpmock.NewStringResponder(200, ``))
 
 				return func() {
@@ -970,8 +1116,8 @@ func TestProcessEventDelivery(t *testing.T) {
 			},
 		},
 		{
-			name:          "At-most-once delivery - non-2xx response - should not retry",
-			cfgPath:       "./testdata/Config/basic-convoy.json",
+			name:          "Manual retry - disable endpoint - success",
+			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
 			expectedError: nil,
 			msg: &datastore.EventDelivery{
 				UID: "",
@@ -979,7 +1125,6 @@ func TestProcessEventDelivery(t *testing.T) {
 			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
 				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.Endpoint{
-						Url:       badRequestServer.URL,
 						ProjectID: "123",
 						Secrets: []datastore.Secret{
 							{Value: "secret"},
@@ -987,31 +1132,35 @@ func TestProcessEventDelivery(t *testing.T) {
 						RateLimit:         10,
 						RateLimitDuration: 60,
 						Status:            datastore.ActiveEndpointStatus,
-					}, nil)
+					}, nil).Times(1)
 
 				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
 				m.EXPECT().
 					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&datastore.EventDelivery{
+						Status: datastore.ScheduledEventStatus,
 						Metadata: &datastore.Metadata{
 							Data:            []byte(`{"event": "invoice.completed"}`),
 							Raw:             `{"event": "invoice.completed"}`,
-							NumTrials:       0,
+							NumTrials:       4,
 							RetryLimit:      3,
 							IntervalSeconds: 20,
 						},
-						Status:       datastore.ScheduledEventStatus,
-						DeliveryMode: datastore.AtMostOnceDeliveryMode,
+						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
 					}, nil).Times(1)
 
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
 				o.EXPECT().
 					FetchProjectByID(gomock.Any(), gomock.Any()).
 					Return(&datastore.Project{
 						LogoURL: "",
 						Config: &datastore.ProjectConfig{
 							Signature: &datastore.SignatureConfiguration{
-								Header: "X-Convoy-Signature",
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
 								Versions: []datastore.SignatureVersion{
 									{
 										UID:      "abc",
@@ -1026,144 +1175,2648 @@ func TestProcessEventDelivery(t *testing.T) {
 								Duration:   60,
 								RetryCount: 1,
 							},
-							RateLimit: &datastore.DefaultRateLimitConfig,
+							RateLimit:       &datastore.DefaultRateLimitConfig,
+							DisableEndpoint: true,
 						},
 					}, nil).Times(1)
 
-				m.EXPECT().
-					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				a.EXPECT().
+					UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
 				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
 
 				m.EXPECT().
-					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&datastore.EventDelivery{})).
-					DoAndReturn(func(ctx context.Context, projectID string, delivery *datastore.EventDelivery) error {
-						assert.Equal(t, "Endpoint returned status code 400", delivery.Description)
-						return nil
-					}).
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
 
 				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
 				l.EXPECT().UseForwardProxy().Times(1).Return(true)
-				l.EXPECT().IpRules().Times(3).Return(false)
+				l.EXPECT().IpRules().Times(3).Return(true)
+				l.EXPECT().CircuitBreaking().Times(1).Return(false)
+				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(true)
+			},
+			nFn: func() func() {
+				httpmock.Activate()
+
+				httpmock.RegisterResponder("POST", "https://google.com",
+					httpmock.NewStringResponder(200, ``))
+
+				return func() {
+					httpmock.DeactivateAndReset()
+				}
 			},
-			nFn: nil,
 		},
-	}
+		{
+			name:          "Manual retry - disable endpoint - success - advanced endpoint mgmt false",
+			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						ProjectID: "123",
+						Secrets: []datastore.Secret{
+							{Value: "secret"},
+						},
+						RateLimit:         10,
+						RateLimitDuration: 60,
+						Status:            datastore.ActiveEndpointStatus,
+					}, nil).Times(1)
 
-	for _, tc := range tt {
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
-			projectRepo := mocks.NewMockProjectRepository(ctrl)
-			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
-			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
-			apiKeyRepo := mocks.NewMockAPIKeyRepository(ctrl)
-			userRepo := mocks.NewMockUserRepository(ctrl)
-			cache := mocks.NewMockCache(ctrl)
-			portalLinkRepo := mocks.NewMockPortalLinkRepository(ctrl)
-			q := mocks.NewMockQueuer(ctrl)
-			rateLimiter := mocks.NewMockRateLimiter(ctrl)
-			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
-			licenser := mocks.NewMockLicenser(ctrl)
-			mt := mocks.NewMockBackend(ctrl)
+				m.EXPECT().
+					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						SubscriptionID: "sub-id-1",
+						Status:         datastore.ScheduledEventStatus,
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							Raw:             `{"event": "invoice.completed"}`,
+							NumTrials:       4,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+					}, nil).Times(1)
 
-			err := config.LoadConfig(tc.cfgPath)
-			if err != nil {
-				t.Errorf("Failed to load config file: %v", err)
-			}
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
 
-			cfg, err := config.Get()
-			if err != nil {
-				t.Errorf("failed to get config: %v", err)
-			}
+				o.EXPECT().
+					FetchProjectByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Project{
+						LogoURL: "",
+						Config: &datastore.ProjectConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Versions: []datastore.SignatureVersion{
+									{
+										UID:      "abc",
+										Hash:     "SHA256",
+										Encoding: datastore.HexEncoding,
+									},
+								},
+							},
+							SSL: &datastore.DefaultSSLConfig,
+							Strategy: &datastore.StrategyConfiguration{
+								Type:       datastore.LinearStrategyProvider,
+								Duration:   60,
+								RetryCount: 1,
+							},
+							RateLimit:       &datastore.DefaultRateLimitConfig,
+							DisableEndpoint: true,
+						},
+					}, nil).Times(1)
 
-			err = realm_chain.Init(&cfg.Auth, apiKeyRepo, userRepo, portalLinkRepo, cache)
-			if err != nil {
-				t.Errorf("failed to initialize realm chain : %v", err)
-			}
+				a.EXPECT().UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
 
-			if tc.nFn != nil {
-				deferFn := tc.nFn()
-				defer deferFn()
-			}
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
 
-			if tc.dbFn != nil {
-				tc.dbFn(endpointRepo, projectRepo, msgRepo, q, rateLimiter, attemptsRepo, licenser, mt)
-			}
+				m.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
 
-			featureFlag := fflag.NewFFlag(cfg.EnableFeatureFlag)
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
-			dispatcher, err := net.NewDispatcher(
-				licenser,
-				fflag.NewFFlag([]string{string(fflag.IpRules)}),
-				net.LoggerOption(log.NewLogger(os.Stdout)),
-				net.BlockListOption([]string{"10.0.0.0/8"}),
-				net.ProxyOption("nil"),
-			)
-			require.NoError(t, err)
+				l.EXPECT().UseForwardProxy().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(3).Return(true)
+				l.EXPECT().CircuitBreaking().Times(1).Return(false)
+				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(false)
+			},
+			nFn: func() func() {
+				httpmock.Activate()
 
-			mockStore := cb.NewTestStore()
-			mockClock := clock.NewSimulatedClock(time.Now())
-			breakerConfig := &cb.CircuitBreakerConfig{
-				SampleRate:                  1,
-				BreakerTimeout:              30,
-				FailureThreshold:            50,
-				SuccessThreshold:            2,
-				ObservabilityWindow:         5,
-				MinimumRequestCount:         10,
-				ConsecutiveFailureThreshold: 3,
-			}
+				httpmock.RegisterResponder("POST", "https://google.com",
+					httpmock.NewStringResponder(200, ``))
 
-			manager, err := cb.NewCircuitBreakerManager(
-				cb.StoreOption(mockStore),
-				cb.ClockOption(mockClock),
-				cb.ConfigOption(breakerConfig),
-				cb.LoggerOption(log.NewLogger(os.Stdout)),
-			)
-			require.NoError(t, err)
+				return func() {
+					httpmock.DeactivateAndReset()
+				}
+			},
+		},
+		{
+			name:          "Manual retry - send disable endpoint notification",
+			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						ProjectID:    "123",
+						SupportEmail: "test@gmail.com",
+						Secrets: []datastore.Secret{
+							{Value: "secret"},
+						},
+						RateLimit:         10,
+						Status:            datastore.ActiveEndpointStatus,
+						RateLimitDuration: 60,
+					}, nil).Times(1)
 
-			processor := ProcessEventDelivery(
-				endpointRepo,
-				msgRepo,
-				licenser,
-				projectRepo,
-				q,
-				rateLimiter,
-				dispatcher,
-				attemptsRepo,
-				manager,
-				featureFlag,
-				mt,
-			)
+				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+
+				m.EXPECT().
+					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						SubscriptionID: "sub-id-1",
+						Status:         datastore.ScheduledEventStatus,
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							Raw:             `{"event": "invoice.completed"}`,
+							NumTrials:       4,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				o.EXPECT().
+					FetchProjectByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Project{
+						LogoURL: "",
+						Config: &datastore.ProjectConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Versions: []datastore.SignatureVersion{
+									{
+										UID:      "abc",
+										Hash:     "SHA256",
+										Encoding: datastore.HexEncoding,
+									},
+								},
+							},
+							SSL: &datastore.DefaultSSLConfig,
+							Strategy: &datastore.StrategyConfiguration{
+								Type:       datastore.LinearStrategyProvider,
+								Duration:   60,
+								RetryCount: 1,
+							},
+							RateLimit:       &datastore.DefaultRateLimitConfig,
+							DisableEndpoint: true,
+						},
+					}, nil).Times(1)
+
+				a.EXPECT().UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.NotificationProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+				l.EXPECT().UseForwardProxy().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(3).Return(true)
+				l.EXPECT().CircuitBreaking().Times(1).Return(false)
+				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(true)
+			},
+			nFn: func() func() {
+				httpmock.Activate()
+
+				httpmock.RegisterResponder("POST", "https://google.com",
+					httpmock.NewStringResponder(200, ``))
+
+				return func() {
+					httpmock.DeactivateAndReset()
+				}
+			},
+		},
+		{
+			name:          "Manual retry - send endpoint enabled notification",
+			cfgPath:       "./testdata/Config/basic-convoy-disable-endpoint.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						ProjectID:    "123",
+						SupportEmail: "test@gmail.com",
+						Secrets: []datastore.Secret{
+							{Value: "secret"},
+						},
+						RateLimit:         10,
+						Url:               "https://google.com",
+						RateLimitDuration: 60,
+						Status:            datastore.ActiveEndpointStatus,
+					}, nil).Times(1)
+
+				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+
+				m.EXPECT().
+					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						SubscriptionID: "sub-id-1",
+						Status:         datastore.ScheduledEventStatus,
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							Raw:             `{"event": "invoice.completed"}`,
+							NumTrials:       4,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				o.EXPECT().
+					FetchProjectByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Project{
+						LogoURL: "",
+						Config: &datastore.ProjectConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: "X-Convoy-Signature",
+								Versions: []datastore.SignatureVersion{
+									{
+										UID:      "abc",
+										Hash:     "SHA256",
+										Encoding: datastore.HexEncoding,
+									},
+								},
+							},
+							SSL: &datastore.DefaultSSLConfig,
+							Strategy: &datastore.StrategyConfiguration{
+								Type:       datastore.LinearStrategyProvider,
+								Duration:   60,
+								RetryCount: 1,
+							},
+							RateLimit:       &datastore.DefaultRateLimitConfig,
+							DisableEndpoint: true,
+						},
+					}, nil).Times(1)
+
+				a.EXPECT().UpdateEndpointStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.NotificationProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+				l.EXPECT().UseForwardProxy().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(3).Return(true)
+				l.EXPECT().CircuitBreaking().Times(1).Return(false)
+				l.EXPECT().AdvancedEndpointMgmt().Times(1).Return(true)
+			},
+			nFn: func() func() {
+				httpmock.Activate()
+
+				httpmock.RegisterResponder("POST", "https://google.com",
+					httpmock.NewStringResponder(200, ``))
+
+				return func() {
+					httpmock.DeactivateAndReset()
+				}
+			},
+		},
+		{
+			name:          "At-most-once delivery - non-2xx response - should not retry",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						Url:       badRequestServer.URL,
+						ProjectID: "123",
+						Secrets: []datastore.Secret{
+							{Value: "secret"},
+						},
+						RateLimit:         10,
+						RateLimitDuration: 60,
+						Status:            datastore.ActiveEndpointStatus,
+					}, nil)
+
+				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+
+				m.EXPECT().
+					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							Raw:             `{"event": "invoice.completed"}`,
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:       datastore.ScheduledEventStatus,
+						DeliveryMode: datastore.AtMostOnceDeliveryMode,
+					}, nil).Times(1)
+
+				o.EXPECT().
+					FetchProjectByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Project{
+						LogoURL: "",
+						Config: &datastore.ProjectConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: "X-Convoy-Signature",
+								Versions: []datastore.SignatureVersion{
+									{
+										UID:      "abc",
+										Hash:     "SHA256",
+										Encoding: datastore.HexEncoding,
+									},
+								},
+							},
+							SSL: &datastore.DefaultSSLConfig,
+							Strategy: &datastore.StrategyConfiguration{
+								Type:       datastore.LinearStrategyProvider,
+								Duration:   60,
+								RetryCount: 1,
+							},
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&datastore.EventDelivery{})).
+					DoAndReturn(func(ctx context.Context, projectID string, delivery *datastore.EventDelivery) error {
+						assert.Equal(t, "Endpoint returned status code 400", delivery.Description)
+						return nil
+					}).
+					Return(nil).Times(1)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+				l.EXPECT().UseForwardProxy().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(3).Return(false)
+			},
+			nFn: nil,
+		},
+		{
+			name:          "At-most-once delivery - connection error - should record one attempt and not retry",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				a.EXPECT().FindEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						Url:       unreachableServer.URL,
+						ProjectID: "123",
+						Secrets: []datastore.Secret{
+							{Value: "secret"},
+						},
+						RateLimit:         10,
+						RateLimitDuration: 60,
+						Status:            datastore.ActiveEndpointStatus,
+					}, nil)
+
+				r.EXPECT().AllowWithDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+
+				m.EXPECT().
+					FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							Raw:             `{"event": "invoice.completed"}`,
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:       datastore.ScheduledEventStatus,
+						DeliveryMode: datastore.AtMostOnceDeliveryMode,
+					}, nil).Times(1)
+
+				o.EXPECT().
+					FetchProjectByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Project{
+						LogoURL: "",
+						Config: &datastore.ProjectConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: "X-Convoy-Signature",
+								Versions: []datastore.SignatureVersion{
+									{
+										UID:      "abc",
+										Hash:     "SHA256",
+										Encoding: datastore.HexEncoding,
+									},
+								},
+							},
+							SSL: &datastore.DefaultSSLConfig,
+							Strategy: &datastore.StrategyConfiguration{
+								Type:       datastore.LinearStrategyProvider,
+								Duration:   60,
+								RetryCount: 3,
+							},
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, attempt *datastore.DeliveryAttempt) error {
+						assert.False(t, attempt.Status)
+						assert.Empty(t, attempt.HttpResponseCode)
+						assert.Contains(t, attempt.Error, "connection refused")
+						return nil
+					}).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&datastore.EventDelivery{})).
+					DoAndReturn(func(ctx context.Context, projectID string, delivery *datastore.EventDelivery) error {
+						assert.Equal(t, datastore.FailureEventStatus, delivery.Status)
+						assert.Equal(t, uint64(1), delivery.Metadata.NumTrials)
+						assert.Contains(t, delivery.Description, "Request to the endpoint failed: ")
+						assert.Contains(t, delivery.Description, "connection refused")
+						return nil
+					}).Times(1)
+
+				// the delivery isn't queued again
+				q.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+				l.EXPECT().UseForwardProxy().Times(1).Return(true)
+				l.EXPECT().IpRules().Times(3).Return(false)
+			},
+			nFn: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			apiKeyRepo := mocks.NewMockAPIKeyRepository(ctrl)
+			userRepo := mocks.NewMockUserRepository(ctrl)
+			cache := mocks.NewMockCache(ctrl)
+			portalLinkRepo := mocks.NewMockPortalLinkRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+			licenser := mocks.NewMockLicenser(ctrl)
+			mt := mocks.NewMockBackend(ctrl)
+
+			err := config.LoadConfig(tc.cfgPath)
+			if err != nil {
+				t.Errorf("Failed to load config file: %v", err)
+			}
+
+			cfg, err := config.Get()
+			if err != nil {
+				t.Errorf("failed to get config: %v", err)
+			}
+
+			err = realm_chain.Init(&cfg.Auth, apiKeyRepo, userRepo, portalLinkRepo, cache)
+			if err != nil {
+				t.Errorf("failed to initialize realm chain : %v", err)
+			}
+
+			if tc.nFn != nil {
+				deferFn := tc.nFn()
+				defer deferFn()
+			}
+
+			if tc.dbFn != nil {
+				tc.dbFn(endpointRepo, projectRepo, msgRepo, q, rateLimiter, attemptsRepo, licenser, mt)
+			}
+
+			featureFlag := fflag.NewFFlag(cfg.EnableFeatureFlag)
+
+			dispatcher, err := net.NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				net.LoggerOption(log.NewLogger(os.Stdout)),
+				net.BlockListOption([]string{"10.0.0.0/8"}),
+				net.ProxyOption("nil"),
+			)
+			require.NoError(t, err)
+
+			mockStore := cb.NewTestStore()
+			mockClock := clock.NewSimulatedClock(time.Now())
+			breakerConfig := &cb.CircuitBreakerConfig{
+				SampleRate:                  1,
+				BreakerTimeout:              30,
+				FailureThreshold:            50,
+				SuccessThreshold:            2,
+				ObservabilityWindow:         5,
+				MinimumRequestCount:         10,
+				ConsecutiveFailureThreshold: 3,
+			}
+
+			manager, err := cb.NewCircuitBreakerManager(
+				cb.StoreOption(mockStore),
+				cb.ClockOption(mockClock),
+				cb.ConfigOption(breakerConfig),
+				cb.LoggerOption(log.NewLogger(os.Stdout)),
+			)
+			require.NoError(t, err)
+
+			processor := ProcessEventDelivery(
+				endpointRepo,
+				msgRepo,
+				licenser,
+				projectRepo,
+				subRepo,
+				q,
+				rateLimiter,
+				dispatcher,
+				attemptsRepo,
+				manager,
+				featureFlag,
+				mt,
+			)
 
 			payload := EventDelivery{
 				EventDeliveryID: tc.msg.UID,
 				ProjectID:       tc.msg.ProjectID,
 			}
 
-			data, err := json.Marshal(payload)
-			if err != nil {
-				t.Errorf("failed to marshal payload: %v", err)
-			}
+			data, err := json.Marshal(payload)
+			if err != nil {
+				t.Errorf("failed to marshal payload: %v", err)
+			}
+
+			job := queue.Job{
+				Payload: data,
+			}
+
+			task := asynq.NewTask(string(convoy.EventProcessor), job.Payload, asynq.Queue(string(convoy.EventQueue)), asynq.ProcessIn(job.Delay))
+
+			err = processor(context.Background(), task)
+
+			// Assert.
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestProcessEventDeliveryWithOpenCircuitBreaker(t *testing.T) {
+	tt := []struct {
+		name        string
+		manualRetry bool
+		// halfOpen puts the breaker in the half-open state with its only probe in flight
+		halfOpen  bool
+		dbFn      func(*mocks.MockEndpointRepository, *mocks.MockProjectRepository, *mocks.MockEventDeliveryRepository, *mocks.MockQueuer, *mocks.MockRateLimiter, *mocks.MockDeliveryAttemptsRepository, *mocks.MockLicenser, *mocks.MockBackend)
+		wantCalls int
+	}{
+		{
+			name:        "Manual retry - should skip the circuit breaker",
+			manualRetry: true,
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+					Return(nil).Times(1)
+
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+				l.EXPECT().IpRules().Times(3).Return(false)
+			},
+			wantCalls: 1,
+		},
+		{
+			name:        "Automatic retry - should be short-circuited",
+			manualRetry: false,
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				q.EXPECT().Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).Times(1).Return(nil)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+				l.EXPECT().IpRules().Times(2).Return(false)
+			},
+			wantCalls: 0,
+		},
+		{
+			name:     "Half-open without a free probe - should be short-circuited",
+			halfOpen: true,
+			dbFn: func(a *mocks.MockEndpointRepository, o *mocks.MockProjectRepository, m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, r *mocks.MockRateLimiter, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				q.EXPECT().Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).Times(1).Return(nil)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+				l.EXPECT().IpRules().Times(2).Return(false)
+			},
+			wantCalls: 0,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+			licenser := mocks.NewMockLicenser(ctrl)
+			mt := mocks.NewMockBackend(ctrl)
+
+			err := config.LoadConfig("./testdata/Config/basic-convoy-circuit-breaker.json")
+			require.NoError(t, err)
+
+			cfg, err := config.Get()
+			require.NoError(t, err)
+
+			msgRepo.EXPECT().
+				FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.EventDelivery{
+					UID:            "delivery-id-1",
+					ProjectID:      "project-id-1",
+					EndpointID:     "endpoint-id-1",
+					SubscriptionID: "sub-id-1",
+					Status:         datastore.FailureEventStatus,
+					Metadata: &datastore.Metadata{
+						Data:            []byte(`{"event": "invoice.completed"}`),
+						Raw:             `{"event": "invoice.completed"}`,
+						NumTrials:       1,
+						RetryLimit:      3,
+						IntervalSeconds: 20,
+					},
+					DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+				}, nil).Times(1)
+
+			projectRepo.EXPECT().
+				FetchProjectByID(gomock.Any(), "project-id-1").
+				Return(&datastore.Project{
+					UID: "project-id-1",
+					Config: &datastore.ProjectConfig{
+						Signature: &datastore.SignatureConfiguration{
+							Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+							Versions: []datastore.SignatureVersion{
+								{
+									UID:      "abc",
+									Hash:     "SHA256",
+									Encoding: datastore.HexEncoding,
+								},
+							},
+						},
+						SSL:       &datastore.DefaultSSLConfig,
+						Strategy:  &datastore.DefaultStrategyConfig,
+						RateLimit: &datastore.DefaultRateLimitConfig,
+					},
+				}, nil).Times(1)
+
+			endpointRepo.EXPECT().
+				FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+				Return(&datastore.Endpoint{
+					UID:       "endpoint-id-1",
+					ProjectID: "project-id-1",
+					Url:       server.URL,
+					Secrets: []datastore.Secret{
+						{Value: "secret"},
+					},
+					RateLimit:         10,
+					RateLimitDuration: 60,
+					Status:            datastore.ActiveEndpointStatus,
+				}, nil).Times(1)
+
+			rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil)
+
+			licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+			licenser.EXPECT().CircuitBreaking().Times(1).Return(true)
+
+			tc.dbFn(endpointRepo, projectRepo, msgRepo, q, rateLimiter, attemptsRepo, licenser, mt)
+
+			dispatcher, err := net.NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				net.LoggerOption(log.NewLogger(os.Stdout)),
+				net.BlockListOption([]string{"10.0.0.0/8"}),
+				net.ProxyOption("nil"),
+			)
+			require.NoError(t, err)
+
+			mockStore := cb.NewTestStore()
+			mockClock := clock.NewSimulatedClock(time.Now())
+
+			breaker := cb.CircuitBreaker{
+				Key:   "endpoint-id-1",
+				State: cb.StateOpen,
+			}
+			if tc.halfOpen {
+				breaker.State = cb.StateHalfOpen
+				breaker.WillResetAt = mockClock.Now().Add(-time.Second)
+			}
+
+			err = mockStore.SetOne(context.Background(), "breaker:endpoint-id-1", breaker, time.Minute)
+			require.NoError(t, err)
+
+			manager, err := cb.NewCircuitBreakerManager(
+				cb.StoreOption(mockStore),
+				cb.ClockOption(mockClock),
+				cb.ConfigOption(&cb.CircuitBreakerConfig{
+					SampleRate:                  1,
+					BreakerTimeout:              30,
+					FailureThreshold:            50,
+					SuccessThreshold:            2,
+					ObservabilityWindow:         5,
+					MinimumRequestCount:         10,
+					ConsecutiveFailureThreshold: 3,
+					HalfOpenMaxProbes:           1,
+				}),
+				cb.LoggerOption(log.NewLogger(os.Stdout)),
+			)
+			require.NoError(t, err)
+
+			if tc.halfOpen {
+				_, err = manager.Allow(context.Background(), "endpoint-id-1")
+				require.NoError(t, err)
+			}
+
+			processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+			payload := EventDelivery{
+				EventDeliveryID: "delivery-id-1",
+				ProjectID:       "project-id-1",
+				ManualRetry:     tc.manualRetry,
+			}
+
+			data, err := json.Marshal(payload)
+			require.NoError(t, err)
+
+			task := asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue)))
+
+			err = processor(context.Background(), task)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+// unreachableBreakerStore fails every read, like a redis outage would
+type unreachableBreakerStore struct {
+	*cb.TestStore
+}
+
+func (s *unreachableBreakerStore) GetOne(context.Context, string) (string, error) {
+	return "", errors.New("dial tcp: connection refused")
+}
+
+func TestProcessEventDeliveryWithUnreachableCircuitBreakerStore(t *testing.T) {
+	tt := []struct {
+		name      string
+		cfgPath   string
+		dbFn      func(*mocks.MockEventDeliveryRepository, *mocks.MockQueuer, *mocks.MockDeliveryAttemptsRepository, *mocks.MockLicenser, *mocks.MockBackend)
+		wantCalls int
+	}{
+		{
+			name:    "fail open - should deliver",
+			cfgPath: "./testdata/Config/basic-convoy-circuit-breaker-fail-open.json",
+			dbFn: func(m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+					Return(nil).Times(1)
+
+				d.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+				l.EXPECT().IpRules().Times(3).Return(false)
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "fail closed - should defer",
+			cfgPath: "./testdata/Config/basic-convoy-circuit-breaker-fail-closed.json",
+			dbFn: func(m *mocks.MockEventDeliveryRepository, q *mocks.MockQueuer, d *mocks.MockDeliveryAttemptsRepository, l *mocks.MockLicenser, mt *mocks.MockBackend) {
+				q.EXPECT().Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).Times(1).Return(nil)
+
+				mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+				l.EXPECT().IpRules().Times(2).Return(false)
+			},
+			wantCalls: 0,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+			licenser := mocks.NewMockLicenser(ctrl)
+			mt := mocks.NewMockBackend(ctrl)
+
+			err := config.LoadConfig(tc.cfgPath)
+			require.NoError(t, err)
+
+			cfg, err := config.Get()
+			require.NoError(t, err)
+
+			msgRepo.EXPECT().
+				FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.EventDelivery{
+					UID:            "delivery-id-1",
+					ProjectID:      "project-id-1",
+					EndpointID:     "endpoint-id-1",
+					SubscriptionID: "sub-id-1",
+					Status:         datastore.ScheduledEventStatus,
+					Metadata: &datastore.Metadata{
+						Data:            []byte(`{"event": "invoice.completed"}`),
+						Raw:             `{"event": "invoice.completed"}`,
+						RetryLimit:      3,
+						IntervalSeconds: 20,
+					},
+					DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+				}, nil).Times(1)
+
+			projectRepo.EXPECT().
+				FetchProjectByID(gomock.Any(), "project-id-1").
+				Return(&datastore.Project{
+					UID: "project-id-1",
+					Config: &datastore.ProjectConfig{
+						Signature: &datastore.SignatureConfiguration{
+							Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+							Versions: []datastore.SignatureVersion{
+								{
+									UID:      "abc",
+									Hash:     "SHA256",
+									Encoding: datastore.HexEncoding,
+								},
+							},
+						},
+						SSL:       &datastore.DefaultSSLConfig,
+						Strategy:  &datastore.DefaultStrategyConfig,
+						RateLimit: &datastore.DefaultRateLimitConfig,
+					},
+				}, nil).Times(1)
+
+			endpointRepo.EXPECT().
+				FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+				Return(&datastore.Endpoint{
+					UID:       "endpoint-id-1",
+					ProjectID: "project-id-1",
+					Url:       server.URL,
+					Secrets: []datastore.Secret{
+						{Value: "secret"},
+					},
+					RateLimit:         10,
+					RateLimitDuration: 60,
+					Status:            datastore.ActiveEndpointStatus,
+				}, nil).Times(1)
+
+			rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil)
+
+			licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+			licenser.EXPECT().CircuitBreaking().Times(1).Return(true)
+
+			tc.dbFn(msgRepo, q, attemptsRepo, licenser, mt)
+
+			dispatcher, err := net.NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				net.LoggerOption(log.NewLogger(os.Stdout)),
+				net.BlockListOption([]string{"10.0.0.0/8"}),
+				net.ProxyOption("nil"),
+			)
+			require.NoError(t, err)
+
+			manager, err := cb.NewCircuitBreakerManager(
+				cb.StoreOption(&unreachableBreakerStore{TestStore: cb.NewTestStore()}),
+				cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+				cb.ConfigOption(&cb.CircuitBreakerConfig{
+					SampleRate:                  1,
+					BreakerTimeout:              30,
+					FailureThreshold:            50,
+					SuccessThreshold:            2,
+					ObservabilityWindow:         5,
+					MinimumRequestCount:         10,
+					ConsecutiveFailureThreshold: 3,
+				}),
+				cb.LoggerOption(log.NewLogger(os.Stdout)),
+			)
+			require.NoError(t, err)
+
+			processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+			data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-id-1", ProjectID: "project-id-1"})
+			require.NoError(t, err)
+
+			task := asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue)))
+
+			err = processor(context.Background(), task)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+func TestProcessEventDeliveryHttpMethod(t *testing.T) {
+	tests := []struct {
+		name       string
+		httpMethod string
+		wantMethod string
+	}{
+		{name: "default method", wantMethod: http.MethodPost},
+		{name: "configured method", httpMethod: http.MethodPut, wantMethod: http.MethodPut},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotMethod, gotSignature, gotQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+				gotSignature = r.Header.Get("X-Convoy-Signature")
+				gotQuery = r.URL.RawQuery
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+			licenser := mocks.NewMockLicenser(ctrl)
+			mt := mocks.NewMockBackend(ctrl)
+
+			err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+			require.NoError(t, err)
+
+			cfg, err := config.Get()
+			require.NoError(t, err)
+
+			msgRepo.EXPECT().
+				FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.EventDelivery{
+					UID:            "delivery-id-1",
+					ProjectID:      "project-id-1",
+					EndpointID:     "endpoint-id-1",
+					SubscriptionID: "sub-id-1",
+					Status:         datastore.ScheduledEventStatus,
+					Metadata: &datastore.Metadata{
+						Data:            []byte(`{"event": "invoice.completed"}`),
+						Raw:             `{"event": "invoice.completed"}`,
+						RetryLimit:      3,
+						IntervalSeconds: 20,
+					},
+					DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+				}, nil).Times(1)
+
+			projectRepo.EXPECT().
+				FetchProjectByID(gomock.Any(), "project-id-1").
+				Return(&datastore.Project{
+					UID: "project-id-1",
+					Config: &datastore.ProjectConfig{
+						Signature: &datastore.SignatureConfiguration{
+							Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+							Versions: []datastore.SignatureVersion{
+								{
+									UID:      "abc",
+									Hash:     "SHA256",
+									Encoding: datastore.HexEncoding,
+								},
+							},
+						},
+						SSL:       &datastore.DefaultSSLConfig,
+						Strategy:  &datastore.DefaultStrategyConfig,
+						RateLimit: &datastore.DefaultRateLimitConfig,
+					},
+				}, nil).Times(1)
+
+			endpointRepo.EXPECT().
+				FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+				Return(&datastore.Endpoint{
+					UID:       "endpoint-id-1",
+					ProjectID: "project-id-1",
+					Url:       server.URL + "/hooks?source=convoy",
+					Secrets: []datastore.Secret{
+						{Value: "secret"},
+					},
+					HttpMethod:        tc.httpMethod,
+					RateLimit:         10,
+					RateLimitDuration: 60,
+					Status:            datastore.ActiveEndpointStatus,
+				}, nil).Times(1)
+
+			rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil)
+
+			msgRepo.EXPECT().
+				UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+				Return(nil).Times(1)
+
+			attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+			msgRepo.EXPECT().
+				UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).Times(1)
+
+			mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+			licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+			licenser.EXPECT().IpRules().Times(3).Return(false)
+
+			dispatcher, err := net.NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				net.LoggerOption(log.NewLogger(os.Stdout)),
+				net.BlockListOption([]string{"10.0.0.0/8"}),
+				net.ProxyOption("nil"),
+			)
+			require.NoError(t, err)
+
+			manager, err := cb.NewCircuitBreakerManager(
+				cb.StoreOption(cb.NewTestStore()),
+				cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+				cb.ConfigOption(&cb.CircuitBreakerConfig{
+					SampleRate:                  1,
+					BreakerTimeout:              30,
+					FailureThreshold:            50,
+					SuccessThreshold:            2,
+					ObservabilityWindow:         5,
+					MinimumRequestCount:         10,
+					ConsecutiveFailureThreshold: 3,
+				}),
+				cb.LoggerOption(log.NewLogger(os.Stdout)),
+			)
+			require.NoError(t, err)
+
+			processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+			data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-id-1", ProjectID: "project-id-1"})
+			require.NoError(t, err)
+
+			task := asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue)))
+
+			err = processor(context.Background(), task)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantMethod, gotMethod)
+
+			// the request is signed and keeps its query params whatever the method
+			require.NotEmpty(t, gotSignature)
+			require.Equal(t, "source=convoy", gotQuery)
+		})
+	}
+}
+
+func TestProcessEventDeliveryContentType(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		formEncoding    datastore.EndpointFormEncoding
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "default content type",
+			wantContentType: "application/json",
+			wantBody:        `{"event":"invoice.completed","amount":100}`,
+		},
+		{
+			name:            "vendor media type",
+			contentType:     "application/vnd.acme+json",
+			wantContentType: "application/vnd.acme+json",
+			wantBody:        `{"event":"invoice.completed","amount":100}`,
+		},
+		{
+			name:            "form fields",
+			contentType:     datastore.FormContentType,
+			wantContentType: datastore.FormContentType,
+			wantBody:        "amount=100&event=invoice.completed",
+		},
+		{
+			name:            "form payload",
+			contentType:     datastore.FormContentType,
+			formEncoding:    datastore.PayloadEndpointFormEncoding,
+			wantContentType: datastore.FormContentType,
+			wantBody:        "payload=%7B%22event%22%3A%22invoice.completed%22%2C%22amount%22%3A100%7D",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotContentType, gotSignature, gotBody string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotContentType = r.Header.Get("Content-Type")
+				gotSignature = r.Header.Get("X-Convoy-Signature")
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+			licenser := mocks.NewMockLicenser(ctrl)
+			mt := mocks.NewMockBackend(ctrl)
+
+			err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+			require.NoError(t, err)
+
+			cfg, err := config.Get()
+			require.NoError(t, err)
+
+			msgRepo.EXPECT().
+				FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.EventDelivery{
+					UID:            "delivery-id-1",
+					ProjectID:      "project-id-1",
+					EndpointID:     "endpoint-id-1",
+					SubscriptionID: "sub-id-1",
+					Status:         datastore.ScheduledEventStatus,
+					Metadata: &datastore.Metadata{
+						Data:            []byte(`{"event":"invoice.completed","amount":100}`),
+						Raw:             `{"event":"invoice.completed","amount":100}`,
+						RetryLimit:      3,
+						IntervalSeconds: 20,
+					},
+					DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+				}, nil).Times(1)
+
+			projectRepo.EXPECT().
+				FetchProjectByID(gomock.Any(), "project-id-1").
+				Return(&datastore.Project{
+					UID: "project-id-1",
+					Config: &datastore.ProjectConfig{
+						Signature: &datastore.SignatureConfiguration{
+							Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+							Versions: []datastore.SignatureVersion{
+								{
+									UID:      "abc",
+									Hash:     "SHA256",
+									Encoding: datastore.HexEncoding,
+								},
+							},
+						},
+						SSL:       &datastore.DefaultSSLConfig,
+						Strategy:  &datastore.DefaultStrategyConfig,
+						RateLimit: &datastore.DefaultRateLimitConfig,
+					},
+				}, nil).Times(1)
+
+			endpointRepo.EXPECT().
+				FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+				Return(&datastore.Endpoint{
+					UID:       "endpoint-id-1",
+					ProjectID: "project-id-1",
+					Url:       server.URL,
+					Secrets: []datastore.Secret{
+						{Value: "secret"},
+					},
+					ContentType:       tc.contentType,
+					FormEncoding:      tc.formEncoding,
+					RateLimit:         10,
+					RateLimitDuration: 60,
+					Status:            datastore.ActiveEndpointStatus,
+				}, nil).Times(1)
+
+			rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil)
+
+			msgRepo.EXPECT().
+				UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+				Return(nil).Times(1)
+
+			attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+			msgRepo.EXPECT().
+				UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).Times(1)
+
+			mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+			licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+			licenser.EXPECT().IpRules().Times(3).Return(false)
+
+			dispatcher, err := net.NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				net.LoggerOption(log.NewLogger(os.Stdout)),
+				net.BlockListOption([]string{"10.0.0.0/8"}),
+				net.ProxyOption("nil"),
+			)
+			require.NoError(t, err)
+
+			manager, err := cb.NewCircuitBreakerManager(
+				cb.StoreOption(cb.NewTestStore()),
+				cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+				cb.ConfigOption(&cb.CircuitBreakerConfig{
+					SampleRate:                  1,
+					BreakerTimeout:              30,
+					FailureThreshold:            50,
+					SuccessThreshold:            2,
+					ObservabilityWindow:         5,
+					MinimumRequestCount:         10,
+					ConsecutiveFailureThreshold: 3,
+				}),
+				cb.LoggerOption(log.NewLogger(os.Stdout)),
+			)
+			require.NoError(t, err)
+
+			processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+			data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-id-1", ProjectID: "project-id-1"})
+			require.NoError(t, err)
+
+			task := asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue)))
+
+			err = processor(context.Background(), task)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantContentType, gotContentType)
+			require.Equal(t, tc.wantBody, gotBody)
+
+			// the signature is over the body as it was sent
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write([]byte(gotBody))
+			require.Equal(t, hex.EncodeToString(mac.Sum(nil)), gotSignature)
+		})
+	}
+}
+
+func TestProcessEventDeliveryMissingSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     datastore.MissingSecretPolicy
+		lapsed     bool
+		signTarget bool
+		signQuery  bool
+		url        string
+		query      string
+		wantSent   bool
+		wantError  string
+	}{
+		{name: "default policy - should fail the delivery", wantError: ErrMissingSigningSecret.Error()},
+		{name: "fail policy - should fail the delivery", policy: datastore.FailMissingSecretPolicy, wantError: ErrMissingSigningSecret.Error()},
+		{name: "unsigned policy - should deliver unsigned", policy: datastore.UnsignedMissingSecretPolicy, wantSent: true},
+		{name: "lapsed versions - should fail the delivery", lapsed: true, wantError: signature.ErrEmptySignatureScheme.Error()},
+		{name: "unsignable url - should fail the delivery", signTarget: true, url: "/hooks", wantError: ErrInvalidDeliveryURL.Error() + `: url "/hooks" has no host`},
+		{name: "invalid signed query params - should fail the delivery", signQuery: true, query: "ref=%zz", wantError: ErrInvalidDeliveryURL.Error() + `: invalid URL escape "%zz"`},
+		{name: "invalid unsigned query params - should fail the delivery", policy: datastore.UnsignedMissingSecretPolicy, query: "ref=%zz", wantError: ErrInvalidDeliveryURL.Error() + `: invalid URL escape "%zz"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var sent bool
+			var gotSignature string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent = true
+				gotSignature = r.Header.Get("X-Convoy-Signature")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+			licenser := mocks.NewMockLicenser(ctrl)
+			mt := mocks.NewMockBackend(ctrl)
+
+			err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+			require.NoError(t, err)
+
+			cfg, err := config.Get()
+			require.NoError(t, err)
+
+			msgRepo.EXPECT().
+				FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.EventDelivery{
+					UID:            "delivery-id-1",
+					ProjectID:      "project-id-1",
+					EndpointID:     "endpoint-id-1",
+					SubscriptionID: "sub-id-1",
+					Status:         datastore.ScheduledEventStatus,
+					Metadata: &datastore.Metadata{
+						Data:            []byte(`{"event": "invoice.completed"}`),
+						Raw:             `{"event": "invoice.completed"}`,
+						RetryLimit:      3,
+						IntervalSeconds: 20,
+					},
+					URLQueryParams: tc.query,
+					DeliveryMode:   datastore.AtLeastOnceDeliveryMode,
+				}, nil).Times(1)
+
+			// every version's window has lapsed, so nothing can sign
+			var activeUntil null.Time
+			secrets := []datastore.Secret{}
+			if tc.lapsed {
+				activeUntil = null.TimeFrom(time.Now().Add(-time.Hour))
+				secrets = []datastore.Secret{{Value: "secret"}}
+			}
+
+			// an endpoint url the request target can't be built from
+			endpointURL := server.URL
+			if tc.url != "" {
+				endpointURL = tc.url
+				secrets = []datastore.Secret{{Value: "secret"}}
+			}
+
+			if tc.signQuery {
+				secrets = []datastore.Secret{{Value: "secret"}}
+			}
+
+			projectRepo.EXPECT().
+				FetchProjectByID(gomock.Any(), "project-id-1").
+				Return(&datastore.Project{
+					UID: "project-id-1",
+					Config: &datastore.ProjectConfig{
+						Signature: &datastore.SignatureConfiguration{
+							Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+							Versions: []datastore.SignatureVersion{
+								{
+									UID:         "abc",
+									Hash:        "SHA256",
+									Encoding:    datastore.HexEncoding,
+									ActiveUntil: activeUntil,
+								},
+							},
+							MissingSecretPolicy: tc.policy,
+							SignRequestTarget:   tc.signTarget,
+							SignQueryParams:     tc.signQuery,
+						},
+						SSL:       &datastore.DefaultSSLConfig,
+						Strategy:  &datastore.DefaultStrategyConfig,
+						RateLimit: &datastore.DefaultRateLimitConfig,
+					},
+				}, nil).Times(1)
+
+			endpointRepo.EXPECT().
+				FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+				Return(&datastore.Endpoint{
+					UID:               "endpoint-id-1",
+					ProjectID:         "project-id-1",
+					Url:               endpointURL,
+					Secrets:           secrets,
+					RateLimit:         10,
+					RateLimitDuration: 60,
+					Status:            datastore.ActiveEndpointStatus,
+				}, nil).Times(1)
+
+			rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			msgRepo.EXPECT().
+				UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+				Return(nil).Times(1)
+
+			if tc.wantSent {
+				attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+				msgRepo.EXPECT().
+					UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, ed *datastore.EventDelivery) error {
+						require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+						return nil
+					}).Times(1)
+			} else {
+				msgRepo.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.FailureEventStatus).
+					DoAndReturn(func(_ context.Context, _ string, ed datastore.EventDelivery, _ datastore.EventDeliveryStatus) error {
+						require.Equal(t, tc.wantError, ed.Description)
+						return nil
+					}).Times(1)
+			}
+
+			mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+			licenser.EXPECT().UseForwardProxy().AnyTimes().Return(true)
+			licenser.EXPECT().IpRules().AnyTimes().Return(false)
+
+			dispatcher, err := net.NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				net.LoggerOption(log.NewLogger(os.Stdout)),
+				net.ProxyOption("nil"),
+			)
+			require.NoError(t, err)
+
+			manager, err := cb.NewCircuitBreakerManager(
+				cb.StoreOption(cb.NewTestStore()),
+				cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+				cb.ConfigOption(&cb.CircuitBreakerConfig{
+					SampleRate:                  1,
+					BreakerTimeout:              30,
+					FailureThreshold:            50,
+					SuccessThreshold:            2,
+					ObservabilityWindow:         5,
+					MinimumRequestCount:         10,
+					ConsecutiveFailureThreshold: 3,
+				}),
+				cb.LoggerOption(log.NewLogger(os.Stdout)),
+			)
+			require.NoError(t, err)
+
+			processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
 
-			job := queue.Job{
-				Payload: data,
-			}
+			data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-id-1", ProjectID: "project-id-1"})
+			require.NoError(t, err)
 
-			task := asynq.NewTask(string(convoy.EventProcessor), job.Payload, asynq.Queue(string(convoy.EventQueue)), asynq.ProcessIn(job.Delay))
+			task := asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue)))
 
 			err = processor(context.Background(), task)
+			require.NoError(t, err)
 
-			// Assert.
-			assert.Equal(t, tc.expectedError, err)
+			require.Equal(t, tc.wantSent, sent)
+			require.Empty(t, gotSignature)
+		})
+	}
+}
+
+func TestAddTraceHeadersWithMetadataHeaders(t *testing.T) {
+	project := &datastore.Project{Config: &datastore.ProjectConfig{
+		AddEventIDTraceHeaders: true,
+		MetadataHeaders:        []string{"event_id"},
+	}}
+
+	// headers saved by an attempt before the event id header was renamed
+	eventDelivery := &datastore.EventDelivery{
+		UID:     "delivery-id-1",
+		EventID: "event-id-1",
+		Headers: httpheader.HTTPHeader{"X-Convoy-Event-ID": []string{"event-id-1"}},
+	}
+
+	addTraceHeaders(project, eventDelivery)
+	addMetadataHeaders(project, eventDelivery)
+
+	var names []string
+	for k := range eventDelivery.Headers {
+		if strings.EqualFold(k, "X-Convoy-Event-Id") {
+			names = append(names, k)
+		}
+	}
+	require.Equal(t, []string{"X-Convoy-Event-Id"}, names)
+	require.Equal(t, []string{"event-id-1"}, eventDelivery.Headers["X-Convoy-Event-Id"])
+	require.Equal(t, []string{"delivery-id-1"}, eventDelivery.Headers["X-Convoy-EventDelivery-ID"])
+}
+
+func TestProcessEventDeliveryMetadataHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		metadataHeaders []string
+		wantHeaders     map[string]string
+	}{
+		{
+			name: "no metadata headers are configured",
+			wantHeaders: map[string]string{
+				"X-Convoy-Event-Id":        "",
+				"X-Convoy-Event-Type":      "",
+				"X-Convoy-Delivery-Id":     "",
+				"X-Convoy-Subscription-Id": "",
+			},
+		},
+		{
+			name:            "all metadata headers are configured",
+			metadataHeaders: []string{"event_id", "event_type", "delivery_id", "subscription_id"},
+			wantHeaders: map[string]string{
+				"X-Convoy-Event-Id":        "event-id-1",
+				"X-Convoy-Event-Type":      "invoice.completed",
+				"X-Convoy-Delivery-Id":     "delivery-id-1",
+				"X-Convoy-Subscription-Id": "sub-id-1",
+			},
+		},
+		{
+			name:            "only the configured metadata headers are sent",
+			metadataHeaders: []string{"event_type", "delivery_id"},
+			wantHeaders: map[string]string{
+				"X-Convoy-Event-Id":        "",
+				"X-Convoy-Event-Type":      "invoice.completed",
+				"X-Convoy-Delivery-Id":     "delivery-id-1",
+				"X-Convoy-Subscription-Id": "",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotHeader http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHeader = r.Header.Clone()
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+			licenser := mocks.NewMockLicenser(ctrl)
+			mt := mocks.NewMockBackend(ctrl)
+
+			err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+			require.NoError(t, err)
+
+			cfg, err := config.Get()
+			require.NoError(t, err)
+
+			msgRepo.EXPECT().
+				FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.EventDelivery{
+					UID:            "delivery-id-1",
+					ProjectID:      "project-id-1",
+					EventID:        "event-id-1",
+					EventType:      "invoice.completed",
+					EndpointID:     "endpoint-id-1",
+					SubscriptionID: "sub-id-1",
+					Status:         datastore.ScheduledEventStatus,
+					Metadata: &datastore.Metadata{
+						Data:            []byte(`{"event": "invoice.completed"}`),
+						Raw:             `{"event": "invoice.completed"}`,
+						RetryLimit:      3,
+						IntervalSeconds: 20,
+					},
+					DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+				}, nil).Times(1)
+
+			projectRepo.EXPECT().
+				FetchProjectByID(gomock.Any(), "project-id-1").
+				Return(&datastore.Project{
+					UID: "project-id-1",
+					Config: &datastore.ProjectConfig{
+						Signature: &datastore.SignatureConfiguration{
+							Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+							Versions: []datastore.SignatureVersion{
+								{
+									UID:      "abc",
+									Hash:     "SHA256",
+									Encoding: datastore.HexEncoding,
+								},
+							},
+						},
+						SSL:             &datastore.DefaultSSLConfig,
+						Strategy:        &datastore.DefaultStrategyConfig,
+						RateLimit:       &datastore.DefaultRateLimitConfig,
+						MetadataHeaders: tc.metadataHeaders,
+					},
+				}, nil).Times(1)
+
+			endpointRepo.EXPECT().
+				FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+				Return(&datastore.Endpoint{
+					UID:       "endpoint-id-1",
+					ProjectID: "project-id-1",
+					Url:       server.URL,
+					Secrets: []datastore.Secret{
+						{Value: "secret"},
+					},
+					RateLimit:         10,
+					RateLimitDuration: 60,
+					Status:            datastore.ActiveEndpointStatus,
+				}, nil).Times(1)
+
+			rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil)
+
+			msgRepo.EXPECT().
+				UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+				Return(nil).Times(1)
+
+			attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+			msgRepo.EXPECT().
+				UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).Times(1)
+
+			mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+			licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+			licenser.EXPECT().IpRules().Times(3).Return(false)
+
+			dispatcher, err := net.NewDispatcher(
+				licenser,
+				fflag.NewFFlag([]string{string(fflag.IpRules)}),
+				net.LoggerOption(log.NewLogger(os.Stdout)),
+				net.BlockListOption([]string{"10.0.0.0/8"}),
+				net.ProxyOption("nil"),
+			)
+			require.NoError(t, err)
+
+			manager, err := cb.NewCircuitBreakerManager(
+				cb.StoreOption(cb.NewTestStore()),
+				cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+				cb.ConfigOption(&cb.CircuitBreakerConfig{
+					SampleRate:                  1,
+					BreakerTimeout:              30,
+					FailureThreshold:            50,
+					SuccessThreshold:            2,
+					ObservabilityWindow:         5,
+					MinimumRequestCount:         10,
+					ConsecutiveFailureThreshold: 3,
+				}),
+				cb.LoggerOption(log.NewLogger(os.Stdout)),
+			)
+			require.NoError(t, err)
+
+			processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+			data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-id-1", ProjectID: "project-id-1"})
+			require.NoError(t, err)
+
+			task := asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue)))
+
+			err = processor(context.Background(), task)
+			require.NoError(t, err)
+
+			for name, want := range tc.wantHeaders {
+				require.Equal(t, want, gotHeader.Get(name), name)
+			}
 		})
 	}
 }
 
+func TestProcessEventDeliveryPausedSubscription(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	mt := mocks.NewMockBackend(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	cfg, err := config.Get()
+	require.NoError(t, err)
+
+	newDelivery := func(id, subscriptionID string) *datastore.EventDelivery {
+		return &datastore.EventDelivery{
+			UID:            id,
+			ProjectID:      "project-id-1",
+			EndpointID:     "endpoint-id-1",
+			SubscriptionID: subscriptionID,
+			Status:         datastore.ScheduledEventStatus,
+			Metadata: &datastore.Metadata{
+				Data:            []byte(`{"event": "invoice.completed"}`),
+				Raw:             `{"event": "invoice.completed"}`,
+				RetryLimit:      3,
+				IntervalSeconds: 20,
+			},
+			DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+		}
+	}
+
+	msgRepo.EXPECT().
+		FindEventDeliveryByIDSlim(gomock.Any(), "project-id-1", "delivery-id-1").
+		Return(newDelivery("delivery-id-1", "sub-id-paused"), nil).Times(1)
+	msgRepo.EXPECT().
+		FindEventDeliveryByIDSlim(gomock.Any(), "project-id-1", "delivery-id-2").
+		Return(newDelivery("delivery-id-2", "sub-id-active"), nil).Times(1)
+
+	subRepo.EXPECT().
+		FindSubscriptionByID(gomock.Any(), "project-id-1", "sub-id-paused").
+		Return(&datastore.Subscription{UID: "sub-id-paused", Paused: true}, nil).Times(1)
+	subRepo.EXPECT().
+		FindSubscriptionByID(gomock.Any(), "project-id-1", "sub-id-active").
+		Return(&datastore.Subscription{UID: "sub-id-active"}, nil).Times(1)
+
+	projectRepo.EXPECT().
+		FetchProjectByID(gomock.Any(), "project-id-1").
+		Return(&datastore.Project{
+			UID: "project-id-1",
+			Config: &datastore.ProjectConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+					Versions: []datastore.SignatureVersion{
+						{
+							UID:      "abc",
+							Hash:     "SHA256",
+							Encoding: datastore.HexEncoding,
+						},
+					},
+				},
+				SSL:       &datastore.DefaultSSLConfig,
+				Strategy:  &datastore.DefaultStrategyConfig,
+				RateLimit: &datastore.DefaultRateLimitConfig,
+			},
+		}, nil).Times(2)
+
+	endpointRepo.EXPECT().
+		FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{
+			UID:       "endpoint-id-1",
+			ProjectID: "project-id-1",
+			Url:       server.URL,
+			Secrets: []datastore.Secret{
+				{Value: "secret"},
+			},
+			RateLimit:         10,
+			RateLimitDuration: 60,
+			Status:            datastore.ActiveEndpointStatus,
+		}, nil).Times(2)
+
+	// the paused delivery is only rescheduled, its status is left untouched
+	var deferred *queue.Job
+	q.EXPECT().
+		Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).
+		DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+			deferred = job
+			return nil
+		}).Times(1)
+
+	rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+		Return(nil).Times(1)
+
+	attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, ed *datastore.EventDelivery) error {
+			require.Equal(t, "delivery-id-2", ed.UID)
+			require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+			return nil
+		}).Times(1)
+
+	mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+	licenser.EXPECT().IpRules().Times(3).Return(false)
+
+	dispatcher, err := net.NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{string(fflag.IpRules)}),
+		net.LoggerOption(log.NewLogger(os.Stdout)),
+		net.BlockListOption([]string{"10.0.0.0/8"}),
+		net.ProxyOption("nil"),
+	)
+	require.NoError(t, err)
+
+	manager, err := cb.NewCircuitBreakerManager(
+		cb.StoreOption(cb.NewTestStore()),
+		cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+		cb.ConfigOption(&cb.CircuitBreakerConfig{
+			SampleRate:                  1,
+			BreakerTimeout:              30,
+			FailureThreshold:            50,
+			SuccessThreshold:            2,
+			ObservabilityWindow:         5,
+			MinimumRequestCount:         10,
+			ConsecutiveFailureThreshold: 3,
+		}),
+		cb.LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+	for _, id := range []string{"delivery-id-1", "delivery-id-2"} {
+		data, err := json.Marshal(EventDelivery{EventDeliveryID: id, ProjectID: "project-id-1"})
+		require.NoError(t, err)
+
+		err = processor(context.Background(), asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue))))
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 1, hits)
+	require.NotNil(t, deferred)
+	require.Equal(t, "delivery-id-1", deferred.ID)
+	require.Equal(t, defaultEventDelay, deferred.Delay)
+}
+
+func TestProcessEventDeliveryPayloadTransform(t *testing.T) {
+	var hits int
+	var gotBody []byte
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get("X-Convoy-Signature")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	mt := mocks.NewMockBackend(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	cfg, err := config.Get()
+	require.NoError(t, err)
+
+	newDelivery := func(id, subscriptionID string) *datastore.EventDelivery {
+		return &datastore.EventDelivery{
+			UID:            id,
+			ProjectID:      "project-id-1",
+			EndpointID:     "endpoint-id-1",
+			SubscriptionID: subscriptionID,
+			Status:         datastore.ScheduledEventStatus,
+			Metadata: &datastore.Metadata{
+				Data:            []byte(`{"event":"invoice.completed","data":{"id":"inv_1"}}`),
+				Raw:             `{"event":"invoice.completed","data":{"id":"inv_1"}}`,
+				RetryLimit:      3,
+				IntervalSeconds: 20,
+			},
+			DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+		}
+	}
+
+	msgRepo.EXPECT().
+		FindEventDeliveryByIDSlim(gomock.Any(), "project-id-1", "delivery-id-1").
+		Return(newDelivery("delivery-id-1", "sub-id-unwrap"), nil).Times(1)
+	msgRepo.EXPECT().
+		FindEventDeliveryByIDSlim(gomock.Any(), "project-id-1", "delivery-id-2").
+		Return(newDelivery("delivery-id-2", "sub-id-broken"), nil).Times(1)
+
+	subRepo.EXPECT().
+		FindSubscriptionByID(gomock.Any(), "project-id-1", "sub-id-unwrap").
+		Return(&datastore.Subscription{UID: "sub-id-unwrap", PayloadTransform: "data"}, nil).Times(1)
+	subRepo.EXPECT().
+		FindSubscriptionByID(gomock.Any(), "project-id-1", "sub-id-broken").
+		Return(&datastore.Subscription{UID: "sub-id-broken", PayloadTransform: "payload.id"}, nil).Times(1)
+
+	projectRepo.EXPECT().
+		FetchProjectByID(gomock.Any(), "project-id-1").
+		Return(&datastore.Project{
+			UID: "project-id-1",
+			Config: &datastore.ProjectConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+					Versions: []datastore.SignatureVersion{
+						{
+							UID:      "abc",
+							Hash:     "SHA256",
+							Encoding: datastore.HexEncoding,
+						},
+					},
+				},
+				SSL:       &datastore.DefaultSSLConfig,
+				Strategy:  &datastore.DefaultStrategyConfig,
+				RateLimit: &datastore.DefaultRateLimitConfig,
+			},
+		}, nil).Times(2)
+
+	endpointRepo.EXPECT().
+		FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{
+			UID:       "endpoint-id-1",
+			ProjectID: "project-id-1",
+			Url:       server.URL,
+			Secrets: []datastore.Secret{
+				{Value: "secret"},
+			},
+			RateLimit:         10,
+			RateLimitDuration: 60,
+			Status:            datastore.ActiveEndpointStatus,
+		}, nil).Times(2)
+
+	rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	msgRepo.EXPECT().
+		UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+		Return(nil).Times(2)
+
+	attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, ed *datastore.EventDelivery) error {
+			require.Equal(t, "delivery-id-1", ed.UID)
+			require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+			return nil
+		}).Times(1)
+
+	// the delivery whose transform fails isn't sent untransformed, it is failed
+	msgRepo.EXPECT().
+		UpdateStatusOfEventDelivery(gomock.Any(), "project-id-1", gomock.Any(), datastore.FailureEventStatus).
+		DoAndReturn(func(_ context.Context, _ string, ed datastore.EventDelivery, _ datastore.EventDeliveryStatus) error {
+			require.Equal(t, "delivery-id-2", ed.UID)
+			require.Contains(t, ed.Description, ErrPayloadTransform.Error())
+			require.Contains(t, ed.Description, "payload.id")
+			return nil
+		}).Times(1)
+
+	mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+	licenser.EXPECT().IpRules().Times(3).Return(false)
+
+	dispatcher, err := net.NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{string(fflag.IpRules)}),
+		net.LoggerOption(log.NewLogger(os.Stdout)),
+		net.BlockListOption([]string{"10.0.0.0/8"}),
+		net.ProxyOption("nil"),
+	)
+	require.NoError(t, err)
+
+	manager, err := cb.NewCircuitBreakerManager(
+		cb.StoreOption(cb.NewTestStore()),
+		cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+		cb.ConfigOption(&cb.CircuitBreakerConfig{
+			SampleRate:                  1,
+			BreakerTimeout:              30,
+			FailureThreshold:            50,
+			SuccessThreshold:            2,
+			ObservabilityWindow:         5,
+			MinimumRequestCount:         10,
+			ConsecutiveFailureThreshold: 3,
+		}),
+		cb.LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+	for _, id := range []string{"delivery-id-1", "delivery-id-2"} {
+		data, err := json.Marshal(EventDelivery{EventDeliveryID: id, ProjectID: "project-id-1"})
+		require.NoError(t, err)
+
+		err = processor(context.Background(), asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue))))
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 1, hits)
+	require.JSONEq(t, `{"id": "inv_1"}`, string(gotBody))
+
+	// the signature is computed over the transformed body
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(gotBody)
+	require.Equal(t, hex.EncodeToString(mac.Sum(nil)), gotSignature)
+}
+
+func TestProcessEventDeliveryConcurrencyLimit(t *testing.T) {
+	var hits int
+	var released bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		// the slot is held for the whole request
+		require.False(t, released)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	mt := mocks.NewMockBackend(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	cfg, err := config.Get()
+	require.NoError(t, err)
+
+	for _, id := range []string{"delivery-id-1", "delivery-id-2"} {
+		msgRepo.EXPECT().
+			FindEventDeliveryByIDSlim(gomock.Any(), "project-id-1", id).
+			Return(&datastore.EventDelivery{
+				UID:            id,
+				ProjectID:      "project-id-1",
+				EndpointID:     "endpoint-id-1",
+				SubscriptionID: "sub-id-1",
+				Status:         datastore.ScheduledEventStatus,
+				Metadata: &datastore.Metadata{
+					Data:            []byte(`{"event": "invoice.completed"}`),
+					Raw:             `{"event": "invoice.completed"}`,
+					RetryLimit:      3,
+					IntervalSeconds: 20,
+				},
+				DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+			}, nil).Times(1)
+	}
+
+	subRepo.EXPECT().
+		FindSubscriptionByID(gomock.Any(), "project-id-1", "sub-id-1").
+		Return(&datastore.Subscription{UID: "sub-id-1"}, nil).Times(2)
+
+	projectRepo.EXPECT().
+		FetchProjectByID(gomock.Any(), "project-id-1").
+		Return(&datastore.Project{
+			UID: "project-id-1",
+			Config: &datastore.ProjectConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+					Versions: []datastore.SignatureVersion{
+						{
+							UID:      "abc",
+							Hash:     "SHA256",
+							Encoding: datastore.HexEncoding,
+						},
+					},
+				},
+				SSL:       &datastore.DefaultSSLConfig,
+				Strategy:  &datastore.DefaultStrategyConfig,
+				RateLimit: &datastore.DefaultRateLimitConfig,
+			},
+		}, nil).Times(2)
+
+	endpointRepo.EXPECT().
+		FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{
+			UID:       "endpoint-id-1",
+			ProjectID: "project-id-1",
+			Url:       server.URL,
+			Secrets: []datastore.Secret{
+				{Value: "secret"},
+			},
+			RateLimit:               10,
+			RateLimitDuration:       60,
+			MaxConcurrentDeliveries: 2,
+			Status:                  datastore.ActiveEndpointStatus,
+		}, nil).Times(2)
+
+	// every slot is taken for the first delivery, it is only rescheduled
+	// without using up the endpoint's rate limit
+	gomock.InOrder(
+		rateLimiter.EXPECT().
+			Acquire(gomock.Any(), "endpoint_concurrency:endpoint-id-1", 2, gomock.Any()).
+			Return("", rlimiter.ErrConcurrencyLimitExceeded),
+		rateLimiter.EXPECT().
+			Acquire(gomock.Any(), "endpoint_concurrency:endpoint-id-1", 2, gomock.Any()).
+			Return("token-1", nil),
+	)
+
+	rateLimiter.EXPECT().
+		Release(gomock.Any(), "endpoint_concurrency:endpoint-id-1", "token-1").
+		DoAndReturn(func(context.Context, string, string) error {
+			released = true
+			return nil
+		}).Times(1)
+
+	var deferred *queue.Job
+	q.EXPECT().
+		Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).
+		DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+			deferred = job
+			return nil
+		}).Times(1)
+
+	rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+		Return(nil).Times(1)
+
+	attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, ed *datastore.EventDelivery) error {
+			require.Equal(t, "delivery-id-2", ed.UID)
+			require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+			return nil
+		}).Times(1)
+
+	mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+	licenser.EXPECT().IpRules().Times(3).Return(false)
+
+	dispatcher, err := net.NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{string(fflag.IpRules)}),
+		net.LoggerOption(log.NewLogger(os.Stdout)),
+		net.BlockListOption([]string{"10.0.0.0/8"}),
+		net.ProxyOption("nil"),
+	)
+	require.NoError(t, err)
+
+	manager, err := cb.NewCircuitBreakerManager(
+		cb.StoreOption(cb.NewTestStore()),
+		cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+		cb.ConfigOption(&cb.CircuitBreakerConfig{
+			SampleRate:                  1,
+			BreakerTimeout:              30,
+			FailureThreshold:            50,
+			SuccessThreshold:            2,
+			ObservabilityWindow:         5,
+			MinimumRequestCount:         10,
+			ConsecutiveFailureThreshold: 3,
+		}),
+		cb.LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+	for _, id := range []string{"delivery-id-1", "delivery-id-2"} {
+		data, err := json.Marshal(EventDelivery{EventDeliveryID: id, ProjectID: "project-id-1"})
+		require.NoError(t, err)
+
+		err = processor(context.Background(), asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue))))
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 1, hits)
+	require.True(t, released)
+	require.NotNil(t, deferred)
+	require.Equal(t, "delivery-id-1", deferred.ID)
+}
+
+func TestProcessEventDeliveryProjectConcurrencyLimit(t *testing.T) {
+	var hits []string
+	var released bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+		// the project's slot is held for the whole request
+		require.False(t, released)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	mt := mocks.NewMockBackend(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	cfg, err := config.Get()
+	require.NoError(t, err)
+
+	// both projects cap their in-flight deliveries at one, the first one's
+	// slot is taken while the second one's is free
+	for _, projectID := range []string{"project-id-1", "project-id-2"} {
+		msgRepo.EXPECT().
+			FindEventDeliveryByIDSlim(gomock.Any(), projectID, "delivery-"+projectID).
+			Return(&datastore.EventDelivery{
+				UID:            "delivery-" + projectID,
+				ProjectID:      projectID,
+				EndpointID:     "endpoint-" + projectID,
+				SubscriptionID: "sub-" + projectID,
+				Status:         datastore.ScheduledEventStatus,
+				Metadata: &datastore.Metadata{
+					Data:            []byte(`{"event": "invoice.completed"}`),
+					Raw:             `{"event": "invoice.completed"}`,
+					RetryLimit:      3,
+					IntervalSeconds: 20,
+				},
+				DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+			}, nil).Times(1)
+
+		subRepo.EXPECT().
+			FindSubscriptionByID(gomock.Any(), projectID, "sub-"+projectID).
+			Return(&datastore.Subscription{UID: "sub-" + projectID}, nil).Times(1)
+
+		projectRepo.EXPECT().
+			FetchProjectByID(gomock.Any(), projectID).
+			Return(&datastore.Project{
+				UID: projectID,
+				Config: &datastore.ProjectConfig{
+					Signature: &datastore.SignatureConfiguration{
+						Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+						Versions: []datastore.SignatureVersion{
+							{
+								UID:      "abc",
+								Hash:     "SHA256",
+								Encoding: datastore.HexEncoding,
+							},
+						},
+					},
+					SSL:                     &datastore.DefaultSSLConfig,
+					Strategy:                &datastore.DefaultStrategyConfig,
+					RateLimit:               &datastore.DefaultRateLimitConfig,
+					MaxConcurrentDeliveries: 1,
+				},
+			}, nil).Times(1)
+
+		endpointRepo.EXPECT().
+			FindEndpointByID(gomock.Any(), "endpoint-"+projectID, projectID).
+			Return(&datastore.Endpoint{
+				UID:       "endpoint-" + projectID,
+				ProjectID: projectID,
+				Url:       server.URL + "/" + projectID,
+				Secrets: []datastore.Secret{
+					{Value: "secret"},
+				},
+				RateLimit:         10,
+				RateLimitDuration: 60,
+				Status:            datastore.ActiveEndpointStatus,
+			}, nil).Times(1)
+	}
+
+	rateLimiter.EXPECT().
+		Acquire(gomock.Any(), "project_concurrency:project-id-1", 1, gomock.Any()).
+		Return("", rlimiter.ErrConcurrencyLimitExceeded).Times(1)
+
+	rateLimiter.EXPECT().
+		Acquire(gomock.Any(), "project_concurrency:project-id-2", 1, gomock.Any()).
+		Return("token-1", nil).Times(1)
+
+	rateLimiter.EXPECT().
+		Release(gomock.Any(), "project_concurrency:project-id-2", "token-1").
+		DoAndReturn(func(context.Context, string, string) error {
+			released = true
+			return nil
+		}).Times(1)
+
+	// the deferred delivery doesn't use up its endpoint's rate limit
+	var deferred *queue.Job
+	q.EXPECT().
+		Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).
+		DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+			deferred = job
+			return nil
+		}).Times(1)
+
+	rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-project-id-2", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateStatusOfEventDelivery(gomock.Any(), "project-id-2", gomock.Any(), datastore.ProcessingEventStatus).
+		Return(nil).Times(1)
+
+	attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateEventDeliveryMetadata(gomock.Any(), "project-id-2", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, ed *datastore.EventDelivery) error {
+			require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+			return nil
+		}).Times(1)
+
+	mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+	licenser.EXPECT().IpRules().Times(3).Return(false)
+
+	dispatcher, err := net.NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{string(fflag.IpRules)}),
+		net.LoggerOption(log.NewLogger(os.Stdout)),
+		net.BlockListOption([]string{"10.0.0.0/8"}),
+		net.ProxyOption("nil"),
+	)
+	require.NoError(t, err)
+
+	manager, err := cb.NewCircuitBreakerManager(
+		cb.StoreOption(cb.NewTestStore()),
+		cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+		cb.ConfigOption(&cb.CircuitBreakerConfig{
+			SampleRate:                  1,
+			BreakerTimeout:              30,
+			FailureThreshold:            50,
+			SuccessThreshold:            2,
+			ObservabilityWindow:         5,
+			MinimumRequestCount:         10,
+			ConsecutiveFailureThreshold: 3,
+		}),
+		cb.LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+	for _, projectID := range []string{"project-id-1", "project-id-2"} {
+		data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-" + projectID, ProjectID: projectID})
+		require.NoError(t, err)
+
+		err = processor(context.Background(), asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue))))
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, []string{"/project-id-2"}, hits)
+	require.True(t, released)
+	require.NotNil(t, deferred)
+	require.Equal(t, "delivery-project-id-1", deferred.ID)
+
+	// and is tried again as soon as a slot could be free, not after its
+	// 20 second retry interval
+	require.Equal(t, projectConcurrencyDelay, deferred.Delay)
+}
+
+func TestDeferDelay(t *testing.T) {
+	retryDelay := 20 * time.Second
+
+	require.Equal(t, projectConcurrencyDelay, deferDelay(&RateLimitError{Err: ErrProjectConcurrencyLimit, delay: projectConcurrencyDelay}, retryDelay))
+
+	// the global egress limit frees up within a second
+	require.Equal(t, time.Second, deferDelay(&RateLimitError{Err: ErrGlobalEgressRateLimit, delay: time.Second}, retryDelay))
+
+	// a ramping breaker holds deliveries back for a second
+	require.Equal(t, time.Second, deferDelay(breakerError(cb.ErrRecoveryRampLimited), retryDelay))
+
+	// an open breaker, like any other failure, waits out the retry interval
+	require.Equal(t, retryDelay, deferDelay(breakerError(errors.New("circuit breaker is open")), retryDelay))
+	require.Equal(t, retryDelay, deferDelay(&DeliveryError{Err: ErrSubscriptionPaused}, retryDelay))
+}
+
+func TestProcessEventDeliveryMinDeliveryInterval(t *testing.T) {
+	interval := 200 * time.Millisecond
+
+	var mu sync.Mutex
+	var hits []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, time.Now())
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	mt := mocks.NewMockBackend(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	cfg, err := config.Get()
+	require.NoError(t, err)
+
+	msgRepo.EXPECT().
+		FindEventDeliveryByIDSlim(gomock.Any(), "project-id-1", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, id string) (*datastore.EventDelivery, error) {
+			return &datastore.EventDelivery{
+				UID:            id,
+				ProjectID:      "project-id-1",
+				EndpointID:     "endpoint-id-1",
+				SubscriptionID: "sub-id-1",
+				Status:         datastore.ScheduledEventStatus,
+				Metadata: &datastore.Metadata{
+					Data:            []byte(`{"event": "invoice.completed"}`),
+					Raw:             `{"event": "invoice.completed"}`,
+					RetryLimit:      3,
+					IntervalSeconds: 20,
+				},
+				DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+			}, nil
+		}).AnyTimes()
+
+	subRepo.EXPECT().
+		FindSubscriptionByID(gomock.Any(), "project-id-1", "sub-id-1").
+		Return(&datastore.Subscription{UID: "sub-id-1"}, nil).AnyTimes()
+
+	projectRepo.EXPECT().
+		FetchProjectByID(gomock.Any(), "project-id-1").
+		Return(&datastore.Project{
+			UID: "project-id-1",
+			Config: &datastore.ProjectConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+					Versions: []datastore.SignatureVersion{
+						{
+							UID:      "abc",
+							Hash:     "SHA256",
+							Encoding: datastore.HexEncoding,
+						},
+					},
+				},
+				SSL:       &datastore.DefaultSSLConfig,
+				Strategy:  &datastore.DefaultStrategyConfig,
+				RateLimit: &datastore.DefaultRateLimitConfig,
+			},
+		}, nil).AnyTimes()
+
+	endpointRepo.EXPECT().
+		FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{
+			UID:       "endpoint-id-1",
+			ProjectID: "project-id-1",
+			Url:       server.URL,
+			Secrets: []datastore.Secret{
+				{Value: "secret"},
+			},
+			RateLimit:             10,
+			RateLimitDuration:     60,
+			MinDeliveryIntervalMs: uint64(interval.Milliseconds()),
+			Status:                datastore.ActiveEndpointStatus,
+		}, nil).AnyTimes()
+
+	rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// lets a delivery through once the interval has passed since the last one
+	var allowedAt time.Time
+	rateLimiter.EXPECT().
+		AllowOncePer(gomock.Any(), "endpoint_interval:endpoint-id-1", interval).
+		DoAndReturn(func(context.Context, string, time.Duration) error {
+			if wait := time.Until(allowedAt.Add(interval)); wait > 0 {
+				return rlimiter.ErrIntervalNotElapsed
+			}
+
+			allowedAt = time.Now()
+			return nil
+		}).AnyTimes()
+
+	var deferred []*queue.Job
+	q.EXPECT().
+		Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).
+		DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+			deferred = append(deferred, job)
+			return nil
+		}).AnyTimes()
+
+	msgRepo.EXPECT().
+		UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+		Return(nil).Times(3)
+
+	attemptsRepo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Times(3)
+
+	msgRepo.EXPECT().
+		UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).Times(3)
+
+	mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+	licenser.EXPECT().IpRules().AnyTimes().Return(false)
+
+	dispatcher, err := net.NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{string(fflag.IpRules)}),
+		net.LoggerOption(log.NewLogger(os.Stdout)),
+		net.BlockListOption([]string{"10.0.0.0/8"}),
+		net.ProxyOption("nil"),
+	)
+	require.NoError(t, err)
+
+	manager, err := cb.NewCircuitBreakerManager(
+		cb.StoreOption(cb.NewTestStore()),
+		cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+		cb.ConfigOption(&cb.CircuitBreakerConfig{
+			SampleRate:                  1,
+			BreakerTimeout:              30,
+			FailureThreshold:            50,
+			SuccessThreshold:            2,
+			ObservabilityWindow:         5,
+			MinimumRequestCount:         10,
+			ConsecutiveFailureThreshold: 3,
+		}),
+		cb.LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+	process := func(id string) {
+		data, err := json.Marshal(EventDelivery{EventDeliveryID: id, ProjectID: "project-id-1"})
+		require.NoError(t, err)
+
+		err = processor(context.Background(), asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue))))
+		require.NoError(t, err)
+	}
+
+	// the first delivery goes out, the ones right behind it are deferred
+	for _, id := range []string{"delivery-id-1", "delivery-id-2", "delivery-id-3"} {
+		process(id)
+	}
+	require.Len(t, hits, 1)
+	require.Len(t, deferred, 2)
+
+	// deferred deliveries are processed again once their delay has passed,
+	// like the retry queue would
+	for len(deferred) > 0 {
+		job := deferred[0]
+		deferred = deferred[1:]
+
+		require.Greater(t, job.Delay, time.Duration(0))
+		require.LessOrEqual(t, job.Delay, interval)
+
+		time.Sleep(job.Delay)
+		process(job.ID)
+	}
+
+	require.Len(t, hits, 3)
+	for i := 1; i < len(hits); i++ {
+		require.GreaterOrEqual(t, hits[i].Sub(hits[i-1]), interval)
+	}
+}
+
 func TestProcessEventDeliveryConfig(t *testing.T) {
 	tt := []struct {
 		name                string
@@ -1230,3 +3883,377 @@ func TestProcessEventDeliveryConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestNextRetryDelay(t *testing.T) {
+	tests := []struct {
+		name            string
+		metadata        *datastore.Metadata
+		projectMax      uint64
+		instanceMax     uint64
+		wantMaxRetrySec uint64
+		wantMin         time.Duration
+		wantMax         time.Duration
+	}{
+		{
+			name:            "linear delay is clamped to the project ceiling",
+			metadata:        &datastore.Metadata{Strategy: datastore.LinearStrategyProvider, IntervalSeconds: 600},
+			projectMax:      120,
+			instanceMax:     7200,
+			wantMaxRetrySec: 120,
+			wantMin:         120 * time.Second,
+			wantMax:         120 * time.Second,
+		},
+		{
+			name:            "linear delay is clamped to the instance ceiling",
+			metadata:        &datastore.Metadata{Strategy: datastore.LinearStrategyProvider, IntervalSeconds: 600},
+			projectMax:      0,
+			instanceMax:     300,
+			wantMaxRetrySec: 300,
+			wantMin:         300 * time.Second,
+			wantMax:         300 * time.Second,
+		},
+		{
+			name:            "exponential delay is clamped to the project ceiling",
+			metadata:        &datastore.Metadata{Strategy: datastore.ExponentialStrategyProvider, IntervalSeconds: 10, NumTrials: 10},
+			projectMax:      60,
+			instanceMax:     7200,
+			wantMaxRetrySec: 60,
+			wantMin:         60 * time.Second,
+			wantMax:         60 * time.Second,
+		},
+		{
+			name:            "exponential delay is clamped to the instance ceiling below the project's",
+			metadata:        &datastore.Metadata{Strategy: datastore.ExponentialStrategyProvider, IntervalSeconds: 10, NumTrials: 10},
+			projectMax:      3600,
+			instanceMax:     90,
+			wantMaxRetrySec: 90,
+			wantMin:         90 * time.Second,
+			wantMax:         90 * time.Second,
+		},
+		{
+			name:            "exponential delay grows by the backoff base",
+			metadata:        &datastore.Metadata{Strategy: datastore.ExponentialStrategyProvider, IntervalSeconds: 10, NumTrials: 2, BackoffBase: 3},
+			projectMax:      0,
+			instanceMax:     7200,
+			wantMaxRetrySec: 7200,
+			wantMin:         90 * time.Second,
+			wantMax:         95 * time.Second,
+		},
+		{
+			name:            "exponential delay is clamped to the strategy's max interval",
+			metadata:        &datastore.Metadata{Strategy: datastore.ExponentialStrategyProvider, IntervalSeconds: 10, NumTrials: 2, BackoffBase: 3, MaxIntervalSeconds: 60},
+			projectMax:      0,
+			instanceMax:     7200,
+			wantMaxRetrySec: 7200,
+			wantMin:         60 * time.Second,
+			wantMax:         65 * time.Second,
+		},
+		{
+			name:            "delay under the ceiling is left alone",
+			metadata:        &datastore.Metadata{Strategy: datastore.LinearStrategyProvider, IntervalSeconds: 20},
+			projectMax:      120,
+			instanceMax:     7200,
+			wantMaxRetrySec: 120,
+			wantMin:         20 * time.Second,
+			wantMax:         20 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventDelivery := &datastore.EventDelivery{UID: "delivery-1", Metadata: tt.metadata}
+			project := &datastore.Project{Config: &datastore.ProjectConfig{MaxRetrySeconds: tt.projectMax}}
+
+			delay := nextRetryDelay(context.Background(), eventDelivery, project, tt.instanceMax)
+
+			require.Equal(t, tt.wantMaxRetrySec, eventDelivery.Metadata.MaxRetrySeconds)
+			require.GreaterOrEqual(t, delay, tt.wantMin)
+			require.LessOrEqual(t, delay, tt.wantMax)
+		})
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	fallback := 30 * time.Second
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		floor      uint64
+		ceiling    uint64
+		maxRetry   uint64
+		want       time.Duration
+	}{
+		{
+			name:       "out of range retry after is clamped to the ceiling",
+			retryAfter: "86400",
+			floor:      10,
+			ceiling:    600,
+			want:       600 * time.Second,
+		},
+		{
+			name:       "too small retry after is raised to the floor",
+			retryAfter: "1",
+			floor:      10,
+			ceiling:    600,
+			want:       10 * time.Second,
+		},
+		{
+			name:       "retry after within range is obeyed",
+			retryAfter: "120",
+			floor:      10,
+			ceiling:    600,
+			want:       120 * time.Second,
+		},
+		{
+			name:       "retry after is obeyed when the endpoint sets no bounds",
+			retryAfter: "86400",
+			want:       24 * time.Hour,
+		},
+		{
+			name:       "retry after is clamped to the max retry seconds when the endpoint sets no bounds",
+			retryAfter: "86400",
+			maxRetry:   7200,
+			want:       2 * time.Hour,
+		},
+		{
+			name:       "max retry seconds wins over a larger ceiling",
+			retryAfter: "86400",
+			ceiling:    10800,
+			maxRetry:   7200,
+			want:       2 * time.Hour,
+		},
+		{
+			name:       "retry after as an http date is clamped to the ceiling",
+			retryAfter: time.Now().Add(24 * time.Hour).UTC().Format(http.TimeFormat),
+			ceiling:    600,
+			want:       600 * time.Second,
+		},
+		{
+			name:    "missing retry after keeps the strategy's delay",
+			floor:   10,
+			ceiling: 600,
+			want:    fallback,
+		},
+		{
+			name:       "unparseable retry after keeps the strategy's delay",
+			retryAfter: "soon",
+			ceiling:    600,
+			want:       fallback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &net.Response{ResponseHeader: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.ResponseHeader.Set("Retry-After", tt.retryAfter)
+			}
+
+			endpoint := &datastore.Endpoint{
+				UID:                      "endpoint-1",
+				RetryAfterFloorSeconds:   tt.floor,
+				RetryAfterCeilingSeconds: tt.ceiling,
+			}
+
+			require.Equal(t, tt.want, retryAfterDelay(context.Background(), resp, endpoint, fallback, tt.maxRetry))
+		})
+	}
+}
+
+func TestProcessEventDeliveryTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	attemptsRepo := mocks.NewMockDeliveryAttemptsRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	mt := mocks.NewMockBackend(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	cfg, err := config.Get()
+	require.NoError(t, err)
+
+	msgRepo.EXPECT().
+		FindEventDeliveryByIDSlim(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&datastore.EventDelivery{
+			UID:            "delivery-id-1",
+			ProjectID:      "project-id-1",
+			EndpointID:     "endpoint-id-1",
+			SubscriptionID: "sub-id-1",
+			Status:         datastore.ScheduledEventStatus,
+			Metadata: &datastore.Metadata{
+				Data:            []byte(`{"event": "invoice.completed"}`),
+				Raw:             `{"event": "invoice.completed"}`,
+				Strategy:        datastore.LinearStrategyProvider,
+				RetryLimit:      3,
+				IntervalSeconds: 20,
+			},
+			DeliveryMode: datastore.AtLeastOnceDeliveryMode,
+		}, nil).Times(1)
+
+	projectRepo.EXPECT().
+		FetchProjectByID(gomock.Any(), "project-id-1").
+		Return(&datastore.Project{
+			UID: "project-id-1",
+			Config: &datastore.ProjectConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+					Versions: []datastore.SignatureVersion{
+						{
+							UID:      "abc",
+							Hash:     "SHA256",
+							Encoding: datastore.HexEncoding,
+						},
+					},
+				},
+				SSL:       &datastore.DefaultSSLConfig,
+				Strategy:  &datastore.DefaultStrategyConfig,
+				RateLimit: &datastore.DefaultRateLimitConfig,
+			},
+		}, nil).Times(1)
+
+	endpointRepo.EXPECT().
+		FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{
+			UID:       "endpoint-id-1",
+			ProjectID: "project-id-1",
+			Url:       server.URL,
+			Secrets: []datastore.Secret{
+				{Value: "secret"},
+			},
+			HttpTimeout:       1,
+			RateLimit:         10,
+			RateLimitDuration: 60,
+			Status:            datastore.ActiveEndpointStatus,
+		}, nil).Times(1)
+
+	rateLimiter.EXPECT().AllowWithDuration(gomock.Any(), "endpoint-id-1", gomock.Any(), gomock.Any()).Return(nil)
+
+	msgRepo.EXPECT().
+		UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+		Return(nil).Times(1)
+
+	attemptsRepo.EXPECT().
+		CreateDeliveryAttempt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, attempt *datastore.DeliveryAttempt) error {
+			require.False(t, attempt.Status)
+			require.Equal(t, "request timed out after 1s", attempt.Error)
+			return nil
+		}).Times(1)
+
+	msgRepo.EXPECT().
+		UpdateEventDeliveryMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, ed *datastore.EventDelivery) error {
+			require.Equal(t, datastore.RetryEventStatus, ed.Status)
+			require.Equal(t, "request timed out after 1s", ed.Description)
+			return nil
+		}).Times(1)
+
+	// the delivery follows the normal retry path
+	q.EXPECT().
+		Write(convoy.RetryEventProcessor, convoy.RetryEventQueue, gomock.Any()).
+		Return(nil).Times(1)
+
+	mt.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	licenser.EXPECT().AdvancedEndpointMgmt().Return(true).AnyTimes()
+	licenser.EXPECT().UseForwardProxy().Times(1).Return(true)
+	licenser.EXPECT().IpRules().AnyTimes().Return(false)
+
+	dispatcher, err := net.NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{string(fflag.IpRules)}),
+		net.LoggerOption(log.NewLogger(os.Stdout)),
+		net.BlockListOption([]string{"10.0.0.0/8"}),
+		net.ProxyOption("nil"),
+	)
+	require.NoError(t, err)
+
+	manager, err := cb.NewCircuitBreakerManager(
+		cb.StoreOption(cb.NewTestStore()),
+		cb.ClockOption(clock.NewSimulatedClock(time.Now())),
+		cb.ConfigOption(&cb.CircuitBreakerConfig{
+			SampleRate:                  1,
+			BreakerTimeout:              30,
+			FailureThreshold:            50,
+			SuccessThreshold:            2,
+			ObservabilityWindow:         5,
+			MinimumRequestCount:         10,
+			ConsecutiveFailureThreshold: 3,
+		}),
+		cb.LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	processor := ProcessEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, fflag.NewFFlag(cfg.EnableFeatureFlag), mt)
+
+	data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-id-1", ProjectID: "project-id-1"})
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = processor(context.Background(), asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.EventQueue))))
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestDeliveryTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		dispatcher config.DispatcherConfiguration
+		endpoint   datastore.Endpoint
+		advanced   bool
+		want       time.Duration
+	}{
+		{
+			name: "falls back to the default",
+			want: convoy.HTTP_TIMEOUT_IN_DURATION,
+		},
+		{
+			name:       "falls back to the dispatcher's timeout",
+			dispatcher: config.DispatcherConfiguration{Timeout: 5},
+			want:       5 * time.Second,
+		},
+		{
+			name:       "uses the endpoint's timeout",
+			dispatcher: config.DispatcherConfiguration{Timeout: 5},
+			endpoint:   datastore.Endpoint{HttpTimeout: 25},
+			advanced:   true,
+			want:       25 * time.Second,
+		},
+		{
+			name:       "ignores the endpoint's timeout without advanced endpoint management",
+			dispatcher: config.DispatcherConfiguration{Timeout: 5},
+			endpoint:   datastore.Endpoint{HttpTimeout: 25},
+			want:       5 * time.Second,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			licenser := mocks.NewMockLicenser(ctrl)
+			licenser.EXPECT().AdvancedEndpointMgmt().Return(tc.advanced).AnyTimes()
+
+			require.Equal(t, tc.want, deliveryTimeout(tc.dispatcher, licenser, &tc.endpoint))
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	tracer2 "github.com/frain-dev/convoy/internal/pkg/tracer"
+	"net/http"
 	"time"
 
 	"github.com/frain-dev/convoy/internal/pkg/dedup"
@@ -148,7 +149,7 @@ func sendUrlRequest(ctx context.Context, project *datastore.Project, metaEvent *
 
 	httpDuration := convoy.HTTP_TIMEOUT_IN_DURATION
 	start := time.Now()
-	resp, err := dispatch.SendWebhook(ctx, url, sig.Payload, "X-Convoy-Signature", header, int64(cfg.MaxResponseSize), httpheader.HTTPHeader{}, dedup.GenerateChecksum(metaEvent.UID), httpDuration)
+	resp, err := dispatch.SendWebhook(ctx, http.MethodPost, url, sig.Payload, "X-Convoy-Signature", header, int64(cfg.MaxResponseSize), httpheader.HTTPHeader{}, dedup.GenerateChecksum(metaEvent.UID), httpDuration)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	neturl "net/url"
 	"time"
 
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
@@ -16,8 +17,6 @@ import (
 
 	"github.com/frain-dev/convoy/pkg/msgpack"
 
-	"github.com/frain-dev/convoy/pkg/httpheader"
-
 	"github.com/frain-dev/convoy/pkg/url"
 
 	"github.com/frain-dev/convoy/pkg/signature"
@@ -30,20 +29,42 @@ import (
 	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/queue"
-	"github.com/frain-dev/convoy/retrystrategies"
 	"github.com/frain-dev/convoy/util"
 	"github.com/hibiken/asynq"
 )
 
 var (
-	ErrDeliveryAttemptFailed = errors.New("error sending event")
-	ErrRateLimit             = errors.New("rate limit error")
-	defaultDelay             = 10 * time.Second
-	defaultEventDelay        = 120 * time.Second
+	ErrDeliveryAttemptFailed   = errors.New("error sending event")
+	ErrRateLimit               = errors.New("rate limit error")
+	ErrGlobalEgressRateLimit   = errors.New("global egress rate limit error")
+	ErrSubscriptionPaused      = errors.New("subscription is paused")
+	ErrOrderedDeliveryPending  = errors.New("an earlier delivery to the endpoint is still pending")
+	ErrConcurrencyLimit        = errors.New("endpoint concurrency limit error")
+	ErrProjectConcurrencyLimit = errors.New("project concurrency limit error")
+	ErrMinDeliveryInterval     = errors.New("endpoint min delivery interval error")
+	ErrPayloadEncode           = errors.New("payload encode error")
+	ErrMissingSigningSecret    = errors.New("endpoint has no secret to sign the delivery with")
+	ErrInvalidDeliveryURL      = errors.New("invalid delivery url")
+	defaultDelay               = 10 * time.Second
+	defaultEventDelay          = 120 * time.Second
 )
 
-func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventDeliveryRepo datastore.EventDeliveryRepository, licenser license.Licenser, projectRepo datastore.ProjectRepository, q queue.Queuer, rateLimiter limiter.RateLimiter, dispatch *net.Dispatcher, attemptsRepo datastore.DeliveryAttemptsRepository, circuitBreakerManager *circuit_breaker.CircuitBreakerManager, featureFlag *fflag.FFlag, tracerBackend tracer2.Backend) func(context.Context, *asynq.Task) error {
-	return func(ctx context.Context, t *asynq.Task) error {
+// globalEgressRateLimitKey is the limiter key shared by every worker
+// for the instance-wide outbound request budget.
+const globalEgressRateLimitKey = "global_egress"
+
+// projectConcurrencyDelay is how soon a delivery deferred by its project's
+// concurrency cap is tried again. A slot frees up as soon as any of the
+// project's in-flight deliveries finishes, so it isn't held back for its
+// retry interval.
+const projectConcurrencyDelay = time.Second
+
+// deliverySlotMargin is how long past the request timeout an endpoint's
+// in-flight delivery slot is held before it's considered leaked.
+const deliverySlotMargin = time.Minute
+
+func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventDeliveryRepo datastore.EventDeliveryRepository, licenser license.Licenser, projectRepo datastore.ProjectRepository, subRepo datastore.SubscriptionRepository, q queue.Queuer, rateLimiter limiter.RateLimiter, dispatch *net.Dispatcher, attemptsRepo datastore.DeliveryAttemptsRepository, circuitBreakerManager *circuit_breaker.CircuitBreakerManager, featureFlag *fflag.FFlag, tracerBackend tracer2.Backend) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) (err error) {
 		// Start a new trace span for retry event delivery
 		traceStartTime := time.Now()
 		attributes := map[string]interface{}{
@@ -52,7 +73,7 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 
 		var data EventDelivery
 
-		err := msgpack.DecodeMsgPack(t.Payload(), &data)
+		err = msgpack.DecodeMsgPack(t.Payload(), &data)
 		if err != nil {
 			innerErr := json.Unmarshal(t.Payload(), &data)
 			if innerErr != nil {
@@ -76,14 +97,14 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			return &EndpointError{Err: err, delay: defaultEventDelay}
 		}
 
-		delayDuration := retrystrategies.NewRetryStrategyFromMetadata(*eventDelivery.Metadata).NextDuration(eventDelivery.Metadata.NumTrials)
-
 		project, err := projectRepo.FetchProjectByID(ctx, eventDelivery.ProjectID)
 		if err != nil {
 			tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
 			return &EndpointError{Err: err, delay: defaultEventDelay}
 		}
 
+		delayDuration := nextRetryDelay(ctx, eventDelivery, project, cfg.MaxRetrySeconds)
+
 		endpoint, err := endpointRepo.FindEndpointByID(ctx, eventDelivery.EndpointID, eventDelivery.ProjectID)
 		if err != nil {
 			if errors.Is(err, datastore.ErrEndpointNotFound) {
@@ -105,13 +126,63 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 		attributes["endpoint.id"] = endpoint.UID
 		attributes["event.id"] = eventDelivery.EventID
 
-		switch eventDelivery.Status {
-		case datastore.ProcessingEventStatus,
-			datastore.SuccessEventStatus:
+		// a claimed delivery is already processing, but nothing is sending it
+		switch {
+		case eventDelivery.Status == datastore.ProcessingEventStatus && !data.Claimed,
+			eventDelivery.Status == datastore.SuccessEventStatus:
 			tracerBackend.Capture(ctx, "event.retry.delivery.success", attributes, traceStartTime, time.Now())
 			return nil
 		}
 
+		ctx, span := startAttemptSpan(ctx, endpoint, eventDelivery)
+		defer func() { endAttemptSpan(span, err) }()
+
+		subscription, err := findDeliverySubscription(ctx, subRepo, eventDelivery)
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
+			return &EndpointError{Err: err, delay: defaultEventDelay}
+		}
+
+		if subscription != nil && subscription.Paused {
+			log.FromContext(ctx).Debugf("subscription %s is paused, deferring event delivery %s", eventDelivery.SubscriptionID, eventDelivery.UID)
+			tracerBackend.Capture(ctx, "event.retry.delivery.deferred", attributes, traceStartTime, time.Now())
+			return &PausedError{Err: ErrSubscriptionPaused, delay: defaultEventDelay}
+		}
+
+		hold, err := holdOrderedDelivery(ctx, eventDeliveryRepo, eventDelivery, time.Now())
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
+			return &EndpointError{Err: err, delay: defaultEventDelay}
+		}
+
+		if hold {
+			log.FromContext(ctx).Debugf("an earlier delivery to %s is still pending, holding ordered event delivery %s", endpoint.Url, eventDelivery.UID)
+			tracerBackend.Capture(ctx, "event.retry.delivery.deferred", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrOrderedDeliveryPending, delay: orderedDeliveryDelay}
+		}
+
+		releaseProject, err := acquireProjectDeliverySlot(ctx, rateLimiter, project, endpoint)
+		if err != nil {
+			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery id": data.EventDeliveryID}).
+				WithError(err).
+				Debugf("too many deliveries in flight for project %s, limit of %v has been reached", project.UID, project.Config.MaxConcurrentDeliveries)
+
+			tracerBackend.Capture(ctx, "event.retry.delivery.rate_limited", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrProjectConcurrencyLimit, delay: projectConcurrencyDelay}
+		}
+		defer releaseProject()
+
+		release, err := acquireDeliverySlot(ctx, rateLimiter, endpoint)
+		if err != nil {
+			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery id": data.EventDeliveryID}).
+				WithError(err).
+				Debugf("too many deliveries in flight to %s, limit of %v has been reached", endpoint.Url, endpoint.MaxConcurrentDeliveries)
+
+			tracerBackend.Capture(ctx, "event.retry.delivery.rate_limited", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrConcurrencyLimit, delay: delayDuration}
+		}
+		defer release()
+
 		err = rateLimiter.AllowWithDuration(ctx, endpoint.UID, endpoint.RateLimit, int(endpoint.RateLimitDuration))
 		if err != nil {
 			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery id": data.EventDeliveryID}).
@@ -122,25 +193,41 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			return &RateLimitError{Err: ErrRateLimit, delay: time.Duration(endpoint.RateLimitDuration) * time.Second}
 		}
 
+		if cfg.GlobalEgressRate > 0 {
+			err = rateLimiter.Allow(ctx, globalEgressRateLimitKey, cfg.GlobalEgressRate)
+			if err != nil {
+				log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery id": data.EventDeliveryID}).
+					WithError(err).
+					Debugf("global egress limit of %v reqs/s has been reached", cfg.GlobalEgressRate)
+
+				tracerBackend.Capture(ctx, "event.retry.delivery.rate_limited", attributes, traceStartTime, time.Now())
+				return &RateLimitError{Err: ErrGlobalEgressRateLimit, delay: time.Second}
+			}
+		}
+
+		reportProbe := circuit_breaker.ProbeDone(func(bool) {})
 		if featureFlag.CanAccessFeature(fflag.CircuitBreaker) && licenser.CircuitBreaking() {
-			breakerErr := circuitBreakerManager.CanExecute(ctx, endpoint.UID)
+			probeDone, breakerErr := canExecute(ctx, circuitBreakerManager, cfg.CircuitBreaker, endpoint.UID)
 			if breakerErr != nil {
 				tracerBackend.Capture(ctx, "event.retry.delivery.circuit_breaker", attributes, traceStartTime, time.Now())
-				return &CircuitBreakerError{Err: breakerErr}
+				return breakerError(breakerErr)
 			}
 
-			// check the circuit breaker state so we can disable the endpoint
+			// hands the probe back when we return before sending it
+			defer probeDone(false)
+			reportProbe = probeDone
+
+			// check the circuit breaker state so we can disable the endpoint,
+			// there's nothing to check when failing open on an unreadable store
 			cb, breakerErr := circuitBreakerManager.GetCircuitBreaker(ctx, endpoint.UID)
-			if breakerErr != nil {
+			if breakerErr != nil && cfg.CircuitBreaker.StoreFailureMode != config.FailOpenStoreFailureMode {
 				tracerBackend.Capture(ctx, "event.retry.delivery.circuit_breaker", attributes, traceStartTime, time.Now())
 				return &CircuitBreakerError{Err: breakerErr}
 			}
 
 			if cb != nil {
 				if cb.ConsecutiveFailures > circuitBreakerManager.GetConfig().ConsecutiveFailureThreshold {
-					endpointStatus := datastore.InactiveEndpointStatus
-
-					breakerErr = endpointRepo.UpdateEndpointStatus(ctx, project.UID, endpoint.UID, endpointStatus)
+					breakerErr = DisableBreakerEndpoint(ctx, endpointRepo, project.UID, endpoint.UID)
 					if breakerErr != nil {
 						log.FromContext(ctx).WithError(breakerErr).Error("failed to deactivate endpoint after failed retry")
 					}
@@ -148,6 +235,16 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			}
 		}
 
+		wait, err := spaceDelivery(ctx, rateLimiter, endpoint)
+		if err != nil {
+			log.FromContext(ctx).WithFields(map[string]interface{}{"event_delivery id": data.EventDeliveryID}).
+				WithError(err).
+				Debugf("deferring delivery to %s by %s, deliveries are at least %vms apart", endpoint.Url, wait, endpoint.MinDeliveryIntervalMs)
+
+			tracerBackend.Capture(ctx, "event.retry.delivery.rate_limited", attributes, traceStartTime, time.Now())
+			return &RateLimitError{Err: ErrMinDeliveryInterval, delay: wait}
+		}
+
 		err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, project.UID, *eventDelivery, datastore.ProcessingEventStatus)
 		if err != nil {
 			tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
@@ -175,41 +272,73 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			return nil
 		}
 
-		sig := newSignature(endpoint, project, json.RawMessage(eventDelivery.Metadata.Raw))
-		header, err := sig.ComputeHeaderValue()
+		payload, err := deliveryPayload(endpoint, subscription, eventDelivery)
 		if err != nil {
 			tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
-			return &EndpointError{Err: err, delay: defaultEventDelay}
+			return failUnencodablePayload(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+		}
+
+		payload, err = encodeDeliveryBody(endpoint, payload)
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
+			return failUnencodablePayload(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+		}
+
+		sig := newSignature(endpoint, project, payload)
+		signed, err := shouldSign(ctx, project, endpoint, eventDelivery)
+		if err != nil {
+			tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
+			return failUnsignableDelivery(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+		}
+
+		var header string
+		if signed {
+			header, err = signDelivery(ctx, dispatch, project, endpoint, eventDelivery, sig)
+			if err != nil {
+				tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
+				if errors.Is(err, signature.ErrFailedToEncodePayload) {
+					return failUnencodablePayload(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				if errors.Is(err, signature.ErrEmptySignatureScheme) {
+					return failUnsignableDelivery(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				if errors.Is(err, ErrInvalidDeliveryURL) {
+					return failInvalidDeliveryURL(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				if errors.Is(err, signature.ErrSigningProxyFailed) {
+					releaseForRetry(ctx, eventDeliveryRepo, project.UID, eventDelivery, err)
+				}
+				return &EndpointError{Err: err, delay: defaultEventDelay}
+			}
 		}
 
 		targetURL := endpoint.Url
 		if !util.IsStringEmpty(eventDelivery.URLQueryParams) {
 			targetURL, err = url.ConcatQueryParams(endpoint.Url, eventDelivery.URLQueryParams)
 			if err != nil {
-				log.FromContext(ctx).WithError(err).Error("failed to concat url query params")
 				tracerBackend.Capture(ctx, "event.retry.delivery.error", attributes, traceStartTime, time.Now())
-				return &EndpointError{Err: err, delay: defaultEventDelay}
+				return failInvalidDeliveryURL(ctx, eventDeliveryRepo, project.UID, eventDelivery, fmt.Errorf("%w: %v", ErrInvalidDeliveryURL, err))
 			}
 		}
 
 		attemptStatus := false
 		httpDispatchStart := time.Now()
 
-		if project.Config.AddEventIDTraceHeaders {
-			if eventDelivery.Headers == nil {
-				eventDelivery.Headers = httpheader.HTTPHeader{}
-			}
-			eventDelivery.Headers["X-Convoy-EventDelivery-ID"] = []string{eventDelivery.UID}
-			eventDelivery.Headers["X-Convoy-Event-ID"] = []string{eventDelivery.EventID}
+		addTraceHeaders(project, eventDelivery)
+		addMetadataHeaders(project, eventDelivery)
+		if signed {
+			addSecretEpochHeader(project, endpoint, eventDelivery)
 		}
 
-		var httpDuration time.Duration
-		if endpoint.HttpTimeout == 0 || !licenser.AdvancedEndpointMgmt() {
-			httpDuration = convoy.HTTP_TIMEOUT_IN_DURATION
+		httpDuration := deliveryTimeout(cfg.Dispatcher, licenser, endpoint)
+		sendCtx := withDeliveryMode(withContentType(withDigestCredentials(ctx, endpoint), endpoint), eventDelivery)
+		var resp *net.Response
+		if signed {
+			resp, err = dispatch.SendWebhook(sendCtx, endpoint.GetHttpMethod(), targetURL, sig.Payload, project.Config.Signature.Header.String(), header, int64(cfg.MaxResponseSize), eventDelivery.Headers, eventDelivery.IdempotencyKey, httpDuration)
 		} else {
-			httpDuration = time.Duration(endpoint.HttpTimeout) * time.Second
+			resp, err = dispatch.SendUnsignedWebhook(sendCtx, endpoint.GetHttpMethod(), targetURL, sig.Payload, int64(cfg.MaxResponseSize), eventDelivery.Headers, eventDelivery.IdempotencyKey, httpDuration)
 		}
-		resp, err := dispatch.SendWebhook(ctx, targetURL, sig.Payload, project.Config.Signature.Header.String(), header, int64(cfg.MaxResponseSize), eventDelivery.Headers, eventDelivery.IdempotencyKey, httpDuration)
+		release()
 
 		status := "-"
 		statusCode := 0
@@ -217,13 +346,16 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			status = resp.Status
 			statusCode = resp.StatusCode
 		}
+		reportProbe(err != nil || statusCode < 200 || statusCode > 299)
+		recordTimeout(err, resp, eventDelivery, httpDuration)
 
 		duration := time.Since(httpDispatchStart)
+		recordAttemptResponse(span, statusCode, duration, err)
 		// log request details
 		requestLogger := log.FromContext(ctx).WithFields(log.Fields{
 			"status":          status,
 			"uri":             targetURL,
-			"method":          convoy.HttpPost,
+			"method":          endpoint.GetHttpMethod(),
 			"duration":        duration,
 			"eventDeliveryID": eventDelivery.UID,
 		})
@@ -237,24 +369,13 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 		} else {
 			requestLogger.Errorf("%s", eventDelivery.UID)
 			done = false
+			delayDuration = retryAfterDelay(ctx, resp, endpoint, delayDuration, eventDelivery.Metadata.MaxRetrySeconds)
 
-			// For at-most-once delivery, only retry on network failures
 			if eventDelivery.DeliveryMode == datastore.AtMostOnceDeliveryMode {
-				if retryableForAtMostOnceDeliveryMode(resp.StatusCode) {
-					// Network error - retry
-					eventDelivery.Status = datastore.RetryEventStatus
-					nextTime := time.Now().Add(delayDuration)
-					eventDelivery.Metadata.NextSendTime = nextTime
-					attempts := eventDelivery.Metadata.NumTrials + 1
-
-					log.FromContext(ctx).Errorf("%s next retry time is %s (strategy = %s, delay = %d, attempts = %d/%d)\n", eventDelivery.UID,
-						nextTime.Format(time.ANSIC), eventDelivery.Metadata.Strategy, eventDelivery.Metadata.IntervalSeconds, attempts, eventDelivery.Metadata.RetryLimit)
-				} else {
-					// Got a response (even if it's an error status code) - mark as failed
-					eventDelivery.Status = datastore.FailureEventStatus
-					eventDelivery.Description = fmt.Sprintf("Endpoint returned status code %d", statusCode)
-					done = true
-				}
+				// At-most-once delivery - the only attempt is final, whether
+				// the endpoint answered or couldn't be reached at all
+				failAtMostOnceDelivery(eventDelivery, statusCode, err)
+				done = true
 			} else {
 				// At-least-once delivery - retry on any failure
 				eventDelivery.Status = datastore.RetryEventStatus
@@ -307,7 +428,7 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 			}
 		}
 
-		attempt = parseAttemptFromResponse(eventDelivery, endpoint, resp, attemptStatus)
+		attempt = parseAttemptFromResponse(eventDelivery, endpoint, resp, attemptStatus, cfg.GetMaxAttemptBodyBytes())
 
 		eventDelivery.Metadata.NumTrials++
 
@@ -370,13 +491,171 @@ func ProcessRetryEventDelivery(endpointRepo datastore.EndpointRepository, eventD
 	}
 }
 
+// failUnencodablePayload marks a delivery whose payload can't be encoded, or
+// transformed by its subscription, as failed.
+// Retrying would never succeed, so the task is not sent back to the retry queue.
+func failUnencodablePayload(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, projectID string, eventDelivery *datastore.EventDelivery, err error) error {
+	log.FromContext(ctx).WithError(err).Errorf("failed to encode payload for event delivery %s", eventDelivery.UID)
+
+	eventDelivery.Description = ErrPayloadEncode.Error()
+	if errors.Is(err, ErrPayloadTransform) {
+		eventDelivery.Description = err.Error()
+	}
+	err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, projectID, *eventDelivery, datastore.FailureEventStatus)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to update event delivery status to failure")
+		return &DeliveryError{Err: err}
+	}
+
+	return nil
+}
+
+// shouldSign reports whether the delivery is sent signed. Deliveries to an
+// endpoint without a secret follow the project's missing secret policy, they
+// are sent unsigned or fail with ErrMissingSigningSecret.
+func shouldSign(ctx context.Context, project *datastore.Project, endpoint *datastore.Endpoint, eventDelivery *datastore.EventDelivery) (bool, error) {
+	sigConfig := project.Config.GetSignatureConfig()
+	if !sigConfig.ShouldSign(string(eventDelivery.EventType)) {
+		return false, nil
+	}
+
+	// the signing proxy holds its own keys
+	if !util.IsStringEmpty(sigConfig.ProxyURL) || endpoint.HasSigningSecret() {
+		return true, nil
+	}
+
+	if sigConfig.GetMissingSecretPolicy() == datastore.UnsignedMissingSecretPolicy {
+		log.FromContext(ctx).Warnf("endpoint %s has no secret, sending event delivery %s unsigned", endpoint.UID, eventDelivery.UID)
+		return false, nil
+	}
+
+	return false, ErrMissingSigningSecret
+}
+
+// failUnsignableDelivery fails a delivery that can't be signed without
+// sending it, retrying won't sign it until the endpoint gets a secret or the
+// project an active signature version.
+func failUnsignableDelivery(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, projectID string, eventDelivery *datastore.EventDelivery, err error) error {
+	log.FromContext(ctx).WithError(err).Errorf("failed to sign event delivery %s", eventDelivery.UID)
+
+	eventDelivery.Description = err.Error()
+	err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, projectID, *eventDelivery, datastore.FailureEventStatus)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to update event delivery status to failure")
+		return &DeliveryError{Err: err}
+	}
+
+	return nil
+}
+
+// failInvalidDeliveryURL fails a delivery whose url can't be built or signed,
+// retrying won't fix it until the endpoint's url or the delivery's query
+// params are.
+func failInvalidDeliveryURL(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, projectID string, eventDelivery *datastore.EventDelivery, err error) error {
+	log.FromContext(ctx).WithError(err).Errorf("invalid url for event delivery %s", eventDelivery.UID)
+
+	eventDelivery.Description = err.Error()
+	err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, projectID, *eventDelivery, datastore.FailureEventStatus)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to update event delivery status to failure")
+		return &DeliveryError{Err: err}
+	}
+
+	return nil
+}
+
+// releaseForRetry moves a delivery that failed before it was dispatched out
+// of processing, so the retry isn't skipped as already in flight.
+func releaseForRetry(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, projectID string, eventDelivery *datastore.EventDelivery, err error) {
+	log.FromContext(ctx).WithError(err).Errorf("failed to sign event delivery %s", eventDelivery.UID)
+
+	eventDelivery.Description = err.Error()
+	err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, projectID, *eventDelivery, datastore.RetryEventStatus)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("failed to update event delivery status to retry")
+	}
+}
+
+// signDelivery computes the delivery's signature header, through the
+// project's signing proxy when one is configured. The proxy is reached
+// through dispatch, like the endpoint itself.
+func signDelivery(ctx context.Context, dispatch *net.Dispatcher, project *datastore.Project, endpoint *datastore.Endpoint, eventDelivery *datastore.EventDelivery, sig *signature.Signature) (string, error) {
+	sigConfig := project.Config.GetSignatureConfig()
+	if sigConfig.SignRequestTarget {
+		// the query string isn't part of the target, so the endpoint's url will do
+		target, err := signature.NewRequestTarget(endpoint.GetHttpMethod(), endpoint.Url)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidDeliveryURL, err)
+		}
+		sig.Target = target
+	}
+
+	var query *string
+	if sigConfig.SignQueryParams {
+		q, err := deliveryQueryParams(endpoint, eventDelivery)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidDeliveryURL, err)
+		}
+		sig.Query = q
+
+		canonical := signature.CanonicalQuery(q)
+		query = &canonical
+	}
+
+	if sigConfig.SignSecretEpoch {
+		epoch := endpoint.SecretEpoch()
+		sig.Epoch = &epoch
+	}
+
+	if util.IsStringEmpty(sigConfig.ProxyURL) {
+		return sig.ComputeHeaderValue()
+	}
+
+	return signature.NewProxySigner(sigConfig.ProxyURL, dispatch).Sign(ctx, signature.ProxySignRequest{
+		ProjectID:       project.UID,
+		EndpointID:      endpoint.UID,
+		EventDeliveryID: eventDelivery.UID,
+		Payload:         sig.Payload,
+		Target:          sig.Target,
+		Query:           query,
+		Epoch:           sig.Epoch,
+	})
+}
+
+// deliveryQueryParams returns the query params the delivery is sent with,
+// the endpoint url's own along with the delivery's.
+func deliveryQueryParams(endpoint *datastore.Endpoint, eventDelivery *datastore.EventDelivery) (neturl.Values, error) {
+	targetURL := endpoint.Url
+	if !util.IsStringEmpty(eventDelivery.URLQueryParams) {
+		var err error
+		targetURL, err = url.ConcatQueryParams(endpoint.Url, eventDelivery.URLQueryParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := neturl.Parse(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.Query(), nil
+}
+
 func newSignature(endpoint *datastore.Endpoint, g *datastore.Project, data json.RawMessage) *signature.Signature {
-	s := &signature.Signature{Advanced: endpoint.AdvancedSignatures, Payload: data}
+	s := &signature.Signature{
+		Advanced: endpoint.AdvancedSignatures,
+		Payload:  data,
+		Encoded:  sendsForm(endpoint),
+		Version:  endpoint.PinnedSignatureVersion,
+	}
 
+	now := time.Now()
 	for _, version := range g.Config.Signature.Versions {
 		scheme := signature.Scheme{
 			Hash:     version.Hash,
 			Encoding: version.Encoding.String(),
+			Inactive: !version.IsActiveAt(now),
 		}
 
 		for _, sc := range endpoint.Secrets {
@@ -391,7 +670,7 @@ func newSignature(endpoint *datastore.Endpoint, g *datastore.Project, data json.
 	return s
 }
 
-func parseAttemptFromResponse(m *datastore.EventDelivery, e *datastore.Endpoint, resp *net.Response, attemptStatus bool) datastore.DeliveryAttempt {
+func parseAttemptFromResponse(m *datastore.EventDelivery, e *datastore.Endpoint, resp *net.Response, attemptStatus bool, maxBodyBytes uint64) datastore.DeliveryAttempt {
 	responseHeader := util.ConvertDefaultHeaderToCustomHeader(&resp.ResponseHeader)
 	requestHeader := util.ConvertDefaultHeaderToCustomHeader(&resp.RequestHeader)
 
@@ -408,7 +687,7 @@ func parseAttemptFromResponse(m *datastore.EventDelivery, e *datastore.Endpoint,
 		ResponseHeader:   *responseHeader,
 		RequestHeader:    *requestHeader,
 		HttpResponseCode: resp.Status,
-		ResponseData:     resp.Body,
+		ResponseData:     truncateAttemptBody(resp.Body, maxBodyBytes),
 		Error:            resp.Error,
 		Status:           attemptStatus,
 
@@ -416,3 +695,17 @@ func parseAttemptFromResponse(m *datastore.EventDelivery, e *datastore.Endpoint,
 		UpdatedAt: time.Now(),
 	}
 }
+
+// truncateAttemptBody cuts down a body stored with a delivery attempt to at
+// most maxBytes, followed by a marker with its original length. It returns a
+// copy so the full body can still be used to judge the attempt.
+func truncateAttemptBody(body []byte, maxBytes uint64) []byte {
+	if maxBytes == 0 || uint64(len(body)) <= maxBytes {
+		return body
+	}
+
+	truncated := make([]byte, maxBytes, maxBytes+64)
+	copy(truncated, body)
+
+	return fmt.Appendf(truncated, "...[truncated] original length: %d bytes", len(body))
+}
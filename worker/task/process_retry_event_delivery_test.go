@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/frain-dev/convoy/internal/pkg/fflag"
@@ -17,6 +19,7 @@ import (
 	"github.com/frain-dev/convoy/net"
 	cb "github.com/frain-dev/convoy/pkg/circuit_breaker"
 	"github.com/frain-dev/convoy/pkg/clock"
+	"github.com/frain-dev/convoy/pkg/signature"
 	"github.com/stretchr/testify/require"
 
 	"github.com/frain-dev/convoy"
@@ -25,6 +28,7 @@ import (
 	"github.com/frain-dev/convoy/queue"
 	"github.com/hibiken/asynq"
 	"github.com/jarcoal/httpmock"
+	"gopkg.in/guregu/null.v4"
 
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/mocks"
@@ -1148,6 +1152,8 @@ func TestProcessRetryEventDelivery(t *testing.T) {
 			defer ctrl.Finish()
 
 			projectRepo := mocks.NewMockProjectRepository(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(&datastore.Subscription{}, nil).AnyTimes()
 			endpointRepo := mocks.NewMockEndpointRepository(ctrl)
 			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
 			apiKeyRepo := mocks.NewMockAPIKeyRepository(ctrl)
@@ -1215,7 +1221,7 @@ func TestProcessRetryEventDelivery(t *testing.T) {
 
 			featureFlag := fflag.NewFFlag(cfg.EnableFeatureFlag)
 
-			processFn := ProcessRetryEventDelivery(endpointRepo, msgRepo, l, projectRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, featureFlag, mt)
+			processFn := ProcessRetryEventDelivery(endpointRepo, msgRepo, l, projectRepo, subRepo, q, rateLimiter, dispatcher, attemptsRepo, manager, featureFlag, mt)
 
 			payload := EventDelivery{
 				EventDeliveryID: tc.msg.UID,
@@ -1241,6 +1247,60 @@ func TestProcessRetryEventDelivery(t *testing.T) {
 	}
 }
 
+func TestProcessRetryEventDeliveryPausedSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	mt := mocks.NewMockBackend(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	msgRepo.EXPECT().
+		FindEventDeliveryByID(gomock.Any(), "project-id-1", "delivery-id-1").
+		Return(&datastore.EventDelivery{
+			UID:            "delivery-id-1",
+			ProjectID:      "project-id-1",
+			EndpointID:     "endpoint-id-1",
+			SubscriptionID: "sub-id-paused",
+			Status:         datastore.RetryEventStatus,
+			Metadata:       &datastore.Metadata{RetryLimit: 3, IntervalSeconds: 20},
+		}, nil).Times(1)
+
+	projectRepo.EXPECT().
+		FetchProjectByID(gomock.Any(), "project-id-1").
+		Return(&datastore.Project{UID: "project-id-1", Config: &datastore.ProjectConfig{Strategy: &datastore.DefaultStrategyConfig}}, nil).Times(1)
+
+	endpointRepo.EXPECT().
+		FindEndpointByID(gomock.Any(), "endpoint-id-1", "project-id-1").
+		Return(&datastore.Endpoint{UID: "endpoint-id-1", ProjectID: "project-id-1", Status: datastore.ActiveEndpointStatus}, nil).Times(1)
+
+	subRepo.EXPECT().
+		FindSubscriptionByID(gomock.Any(), "project-id-1", "sub-id-paused").
+		Return(&datastore.Subscription{UID: "sub-id-paused", Paused: true}, nil).Times(1)
+
+	// the delivery is neither sent nor updated while it is held
+	mt.EXPECT().Capture(gomock.Any(), "event.retry.delivery.deferred", gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+	processor := ProcessRetryEventDelivery(endpointRepo, msgRepo, licenser, projectRepo, subRepo, nil, nil, nil, nil, nil, fflag.NewFFlag(nil), mt)
+
+	data, err := json.Marshal(EventDelivery{EventDeliveryID: "delivery-id-1", ProjectID: "project-id-1"})
+	require.NoError(t, err)
+
+	err = processor(context.Background(), asynq.NewTask(string(convoy.EventProcessor), data, asynq.Queue(string(convoy.RetryEventQueue))))
+
+	// a pause isn't a failed attempt, so it doesn't use up the task's retries
+	var pausedErr *PausedError
+	require.ErrorAs(t, err, &pausedErr)
+	require.ErrorIs(t, pausedErr.Err, ErrSubscriptionPaused)
+	require.Equal(t, defaultEventDelay, GetRetryDelay(1, err, nil))
+}
+
 func TestProcessRetryEventDeliveryConfig(t *testing.T) {
 	tt := []struct {
 		name                string
@@ -1308,3 +1368,284 @@ func TestProcessRetryEventDeliveryConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestNewSignature_PinnedVersion(t *testing.T) {
+	project := &datastore.Project{
+		Config: &datastore.ProjectConfig{
+			Signature: &datastore.SignatureConfiguration{
+				Versions: []datastore.SignatureVersion{
+					{UID: "v1", Hash: "SHA256", Encoding: datastore.HexEncoding},
+					{UID: "v2", Hash: "SHA512", Encoding: datastore.Base64Encoding},
+				},
+			},
+		},
+	}
+	payload := json.RawMessage(`{"event": "invoice.completed"}`)
+
+	tests := []struct {
+		name         string
+		version      int
+		wantVersions []string
+	}{
+		{
+			name:         "unpinned endpoint emits all versions",
+			wantVersions: []string{"v1", "v2"},
+		},
+		{
+			name:         "pinned endpoint only emits the pinned version",
+			version:      2,
+			wantVersions: []string{"v2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &datastore.Endpoint{
+				AdvancedSignatures:     true,
+				PinnedSignatureVersion: tt.version,
+				Secrets:                []datastore.Secret{{Value: "secret"}},
+			}
+
+			header, err := newSignature(endpoint, project, payload).ComputeHeaderValue()
+			require.NoError(t, err)
+
+			var versions []string
+			for _, part := range strings.Split(header, ",")[1:] {
+				versions = append(versions, strings.SplitN(part, "=", 2)[0])
+			}
+			require.Equal(t, tt.wantVersions, versions)
+		})
+	}
+}
+
+func TestNewSignature_RotationWindow(t *testing.T) {
+	now := time.Now()
+	payload := json.RawMessage(`{"event": "invoice.completed"}`)
+
+	tests := []struct {
+		name         string
+		v1Until      time.Time
+		v2From       time.Time
+		wantVersions []string
+		wantSimple   string
+	}{
+		{
+			name:         "before the overlap only the old version is sent",
+			v1Until:      now.Add(48 * time.Hour),
+			v2From:       now.Add(time.Hour),
+			wantVersions: []string{"v1"},
+			wantSimple:   "v1",
+		},
+		{
+			name:         "during the overlap both versions are sent",
+			v1Until:      now.Add(time.Hour),
+			v2From:       now.Add(-time.Hour),
+			wantVersions: []string{"v1", "v2"},
+			wantSimple:   "v2",
+		},
+		{
+			name:         "after the overlap only the new version is sent",
+			v1Until:      now.Add(-time.Hour),
+			v2From:       now.Add(-48 * time.Hour),
+			wantVersions: []string{"v2"},
+			wantSimple:   "v2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &datastore.Project{
+				Config: &datastore.ProjectConfig{
+					Signature: &datastore.SignatureConfiguration{
+						Versions: []datastore.SignatureVersion{
+							{UID: "v1", Hash: "SHA256", Encoding: datastore.HexEncoding, ActiveUntil: null.TimeFrom(tt.v1Until)},
+							{UID: "v2", Hash: "SHA512", Encoding: datastore.HexEncoding, ActiveFrom: null.TimeFrom(tt.v2From)},
+						},
+					},
+				},
+			}
+
+			endpoint := &datastore.Endpoint{
+				AdvancedSignatures: true,
+				Secrets:            []datastore.Secret{{Value: "secret"}},
+			}
+
+			header, err := newSignature(endpoint, project, payload).ComputeHeaderValue()
+			require.NoError(t, err)
+
+			var versions []string
+			for _, part := range strings.Split(header, ",")[1:] {
+				versions = append(versions, strings.SplitN(part, "=", 2)[0])
+			}
+			require.Equal(t, tt.wantVersions, versions)
+
+			// simple signatures are generated with the latest active version,
+			// a SHA256 hex digest is half as long as a SHA512 one
+			endpoint.AdvancedSignatures = false
+			header, err = newSignature(endpoint, project, payload).ComputeHeaderValue()
+			require.NoError(t, err)
+
+			wantLen := map[string]int{"v1": 64, "v2": 128}[tt.wantSimple]
+			require.Len(t, header, wantLen)
+		})
+	}
+}
+
+func TestSignDelivery_RequestTarget(t *testing.T) {
+	project := &datastore.Project{
+		Config: &datastore.ProjectConfig{
+			Signature: &datastore.SignatureConfiguration{
+				Versions:          []datastore.SignatureVersion{{UID: "v1", Hash: "SHA256", Encoding: datastore.HexEncoding}},
+				SignRequestTarget: true,
+			},
+		},
+	}
+	payload := json.RawMessage(`{"event": "invoice.completed"}`)
+
+	sign := func(url string) string {
+		endpoint := &datastore.Endpoint{
+			Url:     url,
+			Secrets: []datastore.Secret{{Value: "secret"}},
+		}
+
+		sig := newSignature(endpoint, project, payload)
+		header, err := signDelivery(context.Background(), nil, project, endpoint, &datastore.EventDelivery{}, sig)
+		require.NoError(t, err)
+		require.Equal(t, &signature.RequestTarget{Method: http.MethodPost, Host: "example.com", Path: "/hooks"}, sig.Target)
+		return header
+	}
+
+	header := sign("https://example.com/hooks")
+	require.Equal(t, header, sign("https://EXAMPLE.com/hooks?ref=1"))
+
+	// the same payload to a different host must not verify
+	endpoint := &datastore.Endpoint{Url: "https://other.example.com/hooks", Secrets: []datastore.Secret{{Value: "secret"}}}
+	other, err := signDelivery(context.Background(), nil, project, endpoint, &datastore.EventDelivery{}, newSignature(endpoint, project, payload))
+	require.NoError(t, err)
+	require.NotEqual(t, header, other)
+
+	// a url without a host has no target to sign
+	endpoint = &datastore.Endpoint{Url: "/hooks", Secrets: []datastore.Secret{{Value: "secret"}}}
+	_, err = signDelivery(context.Background(), nil, project, endpoint, &datastore.EventDelivery{}, newSignature(endpoint, project, payload))
+	require.ErrorIs(t, err, ErrInvalidDeliveryURL)
+}
+
+func TestSignDelivery_QueryParams(t *testing.T) {
+	project := &datastore.Project{
+		Config: &datastore.ProjectConfig{
+			Signature: &datastore.SignatureConfiguration{
+				Versions:        []datastore.SignatureVersion{{UID: "v1", Hash: "SHA256", Encoding: datastore.HexEncoding}},
+				SignQueryParams: true,
+			},
+		},
+	}
+	payload := json.RawMessage(`{"event": "invoice.completed"}`)
+
+	sign := func(url, params string) (string, *signature.Signature) {
+		endpoint := &datastore.Endpoint{
+			Url:     url,
+			Secrets: []datastore.Secret{{Value: "secret"}},
+		}
+
+		sig := newSignature(endpoint, project, payload)
+		header, err := signDelivery(context.Background(), nil, project, endpoint, &datastore.EventDelivery{URLQueryParams: params}, sig)
+		require.NoError(t, err)
+		return header, sig
+	}
+
+	header, sig := sign("https://example.com/hooks?tenant=acme", "ref=2&ref=1")
+	require.Nil(t, sig.Target)
+	require.Equal(t, "ref=1&ref=2&tenant=acme", signature.CanonicalQuery(sig.Query))
+
+	// the order the params come in doesn't matter
+	reordered, _ := sign("https://example.com/hooks?ref=1", "tenant=acme&ref=2")
+	require.Equal(t, header, reordered)
+
+	// but their values do
+	changed, _ := sign("https://example.com/hooks?tenant=acme", "ref=2&ref=3")
+	require.NotEqual(t, header, changed)
+
+	// deliveries without query params still sign an empty query
+	empty, sig := sign("https://example.com/hooks", "")
+	require.NotNil(t, sig.Query)
+	require.Empty(t, signature.CanonicalQuery(sig.Query))
+	require.NotEqual(t, header, empty)
+
+	// query params that don't parse can't be signed
+	endpoint := &datastore.Endpoint{Url: "https://example.com/hooks", Secrets: []datastore.Secret{{Value: "secret"}}}
+	_, err := signDelivery(context.Background(), nil, project, endpoint, &datastore.EventDelivery{URLQueryParams: "ref=%zz"}, newSignature(endpoint, project, payload))
+	require.ErrorIs(t, err, ErrInvalidDeliveryURL)
+}
+
+func TestSignDelivery_SecretEpoch(t *testing.T) {
+	project := &datastore.Project{
+		Config: &datastore.ProjectConfig{
+			Signature: &datastore.SignatureConfiguration{
+				Versions:        []datastore.SignatureVersion{{UID: "v1", Hash: "SHA256", Encoding: datastore.HexEncoding}},
+				SignSecretEpoch: true,
+			},
+		},
+	}
+	payload := json.RawMessage(`{"event": "invoice.completed"}`)
+
+	endpoint := &datastore.Endpoint{
+		Url: "https://example.com/hooks",
+		Secrets: []datastore.Secret{
+			{Value: "old-secret", Epoch: 1, ExpiresAt: null.TimeFrom(time.Now().Add(time.Hour))},
+			{Value: "secret", Epoch: 2},
+		},
+	}
+
+	sig := newSignature(endpoint, project, payload)
+	header, err := signDelivery(context.Background(), nil, project, endpoint, &datastore.EventDelivery{}, sig)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), *sig.Epoch)
+
+	// the epoch header matches the secret the delivery was signed with
+	eventDelivery := &datastore.EventDelivery{}
+	addSecretEpochHeader(project, endpoint, eventDelivery)
+	require.Equal(t, []string{"2"}, eventDelivery.Headers[secretEpochHeader])
+
+	// and goes up when the secret is rotated again
+	endpoint.Secrets[1].ExpiresAt = null.TimeFrom(time.Now().Add(time.Hour))
+	endpoint.Secrets = append(endpoint.Secrets, datastore.Secret{Value: "secret", Epoch: endpoint.NextSecretEpoch()})
+
+	rotated, err := signDelivery(context.Background(), nil, project, endpoint, &datastore.EventDelivery{}, newSignature(endpoint, project, payload))
+	require.NoError(t, err)
+	require.NotEqual(t, header, rotated)
+
+	addSecretEpochHeader(project, endpoint, eventDelivery)
+	require.Equal(t, []string{"3"}, eventDelivery.Headers[secretEpochHeader])
+
+	// projects that don't send the epoch leave the header out
+	project.Config.Signature.SignSecretEpoch = false
+	eventDelivery = &datastore.EventDelivery{}
+	addSecretEpochHeader(project, endpoint, eventDelivery)
+	require.NotContains(t, eventDelivery.Headers, secretEpochHeader)
+}
+
+func TestParseAttemptFromResponse_TruncatesBody(t *testing.T) {
+	body := []byte(strings.Repeat("a", 100))
+	resp := &net.Response{
+		URL:        &url.URL{Scheme: "https", Host: "example.com"},
+		Method:     http.MethodPost,
+		StatusCode: http.StatusOK,
+		Body:       body,
+	}
+
+	ed := &datastore.EventDelivery{UID: "delivery-id-1", ProjectID: "project-id-1"}
+	endpoint := &datastore.Endpoint{UID: "endpoint-id-1"}
+
+	attempt := parseAttemptFromResponse(ed, endpoint, resp, true, 10)
+	require.Equal(t, "aaaaaaaaaa...[truncated] original length: 100 bytes", string(attempt.ResponseData))
+
+	// the response the attempt was judged by is left whole
+	require.Equal(t, strings.Repeat("a", 100), string(resp.Body))
+
+	// bodies within the cap are stored as they are
+	attempt = parseAttemptFromResponse(ed, endpoint, resp, true, 100)
+	require.Equal(t, body, attempt.ResponseData)
+
+	attempt = parseAttemptFromResponse(ed, endpoint, resp, true, 0)
+	require.Equal(t, body, attempt.ResponseData)
+}
@@ -3,6 +3,7 @@ package task
 import (
 	"context"
 	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/pkg/log"
 	"github.com/frain-dev/convoy/pkg/msgpack"
@@ -11,33 +12,73 @@ import (
 	"time"
 )
 
-func QueueStuckEventDeliveries(ctx context.Context, edRepo datastore.EventDeliveryRepository, q queue.Queuer) {
-	for {
-		evs, err := edRepo.FindStuckEventDeliveriesByStatus(context.Background(), datastore.ScheduledEventStatus)
+// QueueStuckEventDeliveries queues scheduled deliveries that are stuck, it
+// polls every cfg.MinInterval while it finds some and backs off up to
+// cfg.MaxInterval while it doesn't, until ctx is done.
+func QueueStuckEventDeliveries(ctx context.Context, edRepo datastore.EventDeliveryRepository, q queue.Queuer, cfg config.WorkerPollConfiguration) {
+	pollWithBackoff(ctx, NewPollBackoff(cfg), func(ctx context.Context) (bool, error) {
+		return queueStuckEventDeliveries(ctx, edRepo, q)
+	})
+}
+
+// queueStuckEventDeliveries queues the stuck scheduled deliveries and
+// reports whether there were any. Every worker runs this poll, so the stuck
+// deliveries are claimed before they are queued, and a delivery is only
+// queued by the worker that claimed it.
+func queueStuckEventDeliveries(ctx context.Context, edRepo datastore.EventDeliveryRepository, q queue.Queuer) (bool, error) {
+	evs, err := edRepo.FindStuckEventDeliveriesByStatus(ctx, datastore.ScheduledEventStatus)
+	if err != nil {
+		return false, err
+	}
+
+	if len(evs) == 0 {
+		return false, nil
+	}
+
+	// claims take a project's oldest scheduled deliveries first, which are
+	// the stuck ones
+	projectIDs := make([]string, 0)
+	stuck := make(map[string]int)
+	for i := 0; i < len(evs); i++ {
+		if _, ok := stuck[evs[i].ProjectID]; !ok {
+			projectIDs = append(projectIDs, evs[i].ProjectID)
+		}
+		stuck[evs[i].ProjectID]++
+	}
+
+	for _, projectID := range projectIDs {
+		claimed, err := edRepo.ClaimScheduledDeliveries(ctx, projectID, stuck[projectID])
 		if err != nil {
-			log.FromContext(ctx).WithError(err).Errorf("an error occurred fetching stuck event deliveries")
+			log.FromContext(ctx).WithError(err).Errorf("an error occurred claiming the stuck event deliveries of project %s", projectID)
+			continue
+		}
+
+		if len(claimed) == 0 {
 			continue
 		}
 
 		ids := func() []string {
-			arr := make([]string, 0, len(evs))
-			for i := 0; i < len(evs); i++ {
-				arr = append(arr, evs[i].UID)
+			arr := make([]string, 0, len(claimed))
+			for i := 0; i < len(claimed); i++ {
+				arr = append(arr, claimed[i].UID)
 			}
 			return arr
 		}()
 
-		err = q.(*redis.RedisQueue).DeleteEventDeliveriesFromQueue(convoy.EventQueue, ids)
-		if err != nil {
-			log.FromContext(ctx).WithError(err).Error("an error occurred removing task with id from the queue")
+		if rq, ok := q.(*redis.RedisQueue); ok {
+			err = rq.DeleteEventDeliveriesFromQueue(convoy.EventQueue, ids)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Error("an error occurred removing task with id from the queue")
+			}
 		}
 
-		for i := 0; i < len(evs); i++ {
-			eventDelivery := evs[i]
+		for i := 0; i < len(claimed); i++ {
+			eventDelivery := claimed[i]
 
 			payload := EventDelivery{
 				EventDeliveryID: eventDelivery.UID,
 				ProjectID:       eventDelivery.ProjectID,
+				Claimed:         true,
 			}
 
 			data, err := msgpack.EncodeMsgPack(payload)
@@ -58,7 +99,7 @@ func QueueStuckEventDeliveries(ctx context.Context, edRepo datastore.EventDelive
 				continue
 			}
 		}
-
-		time.Sleep(time.Minute)
 	}
+
+	return true, nil
 }
@@ -0,0 +1,85 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+)
+
+func TestQueueStuckEventDeliveries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should queue only the claimed deliveries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+		q := mocks.NewMockQueuer(ctrl)
+
+		edRepo.EXPECT().FindStuckEventDeliveriesByStatus(gomock.Any(), datastore.ScheduledEventStatus).
+			Return([]datastore.EventDelivery{
+				{UID: "ed-1", ProjectID: "project-1"},
+				{UID: "ed-2", ProjectID: "project-2"},
+				{UID: "ed-3", ProjectID: "project-1"},
+			}, nil)
+
+		// another worker claimed one of project-1's deliveries first
+		edRepo.EXPECT().ClaimScheduledDeliveries(gomock.Any(), "project-1", 2).
+			Return([]datastore.EventDelivery{{UID: "ed-1", ProjectID: "project-1"}}, nil)
+		edRepo.EXPECT().ClaimScheduledDeliveries(gomock.Any(), "project-2", 1).
+			Return([]datastore.EventDelivery{}, nil)
+
+		q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).
+			DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+				require.Equal(t, "ed-1", job.ID)
+
+				var payload EventDelivery
+				require.NoError(t, msgpack.DecodeMsgPack(job.Payload, &payload))
+				require.Equal(t, EventDelivery{EventDeliveryID: "ed-1", ProjectID: "project-1", Claimed: true}, payload)
+				return nil
+			})
+
+		foundWork, err := queueStuckEventDeliveries(ctx, edRepo, q)
+		require.NoError(t, err)
+		require.True(t, foundWork)
+	})
+
+	t.Run("should report no work without stuck deliveries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+		q := mocks.NewMockQueuer(ctrl)
+
+		edRepo.EXPECT().FindStuckEventDeliveriesByStatus(gomock.Any(), datastore.ScheduledEventStatus).
+			Return([]datastore.EventDelivery{}, nil)
+
+		foundWork, err := queueStuckEventDeliveries(ctx, edRepo, q)
+		require.NoError(t, err)
+		require.False(t, foundWork)
+	})
+
+	t.Run("should return the fetch error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		edRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+		q := mocks.NewMockQueuer(ctrl)
+
+		edRepo.EXPECT().FindStuckEventDeliveriesByStatus(gomock.Any(), datastore.ScheduledEventStatus).
+			Return(nil, errors.New("failed"))
+
+		foundWork, err := queueStuckEventDeliveries(ctx, edRepo, q)
+		require.Error(t, err)
+		require.False(t, foundWork)
+	})
+}
@@ -0,0 +1,115 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/hibiken/asynq"
+)
+
+// RecoverEndpoints probes the endpoints the circuit breaker disabled in
+// every project when endpoint recovery is turned on, and re-enables the ones
+// that answer with a 2xx. Endpoints that keep failing are probed
+// less and less often, and left disabled after the configured number of
+// probes. Endpoints disabled by hand are never probed.
+func RecoverEndpoints(projectRepo datastore.ProjectRepository, endpointRepo datastore.EndpointRepository, dispatcher *net.Dispatcher) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		cfg, err := config.Get()
+		if err != nil {
+			return err
+		}
+
+		if !cfg.EndpointRecovery.IsEnabled {
+			return nil
+		}
+
+		projects, err := projectRepo.LoadProjects(ctx, &datastore.ProjectFilter{})
+		if err != nil {
+			return err
+		}
+
+		for _, project := range projects {
+			err = recoverProjectEndpoints(ctx, endpointRepo, dispatcher, cfg.EndpointRecovery, project.UID, time.Now())
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Errorf("failed to recover the disabled endpoints of project %s", project.UID)
+			}
+		}
+
+		return nil
+	}
+}
+
+// DisableBreakerEndpoint disables an endpoint its circuit breaker tripped
+// on, and records that the breaker did so it's probed for recovery.
+func DisableBreakerEndpoint(ctx context.Context, endpointRepo datastore.EndpointRepository, projectID, endpointID string) error {
+	err := endpointRepo.UpdateEndpointStatus(ctx, projectID, endpointID, datastore.InactiveEndpointStatus)
+	if err != nil {
+		return err
+	}
+
+	return endpointRepo.CreateEndpointRecovery(ctx, projectID, endpointID)
+}
+
+// recoverProjectEndpoints sends a probe to each of the project's breaker
+// disabled endpoints that is due one at now.
+func recoverProjectEndpoints(ctx context.Context, endpointRepo datastore.EndpointRepository, dispatcher *net.Dispatcher, cfg config.EndpointRecoveryConfiguration, projectID string, now time.Time) error {
+	recoveries, err := endpointRepo.LoadEndpointRecoveries(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	due := make(map[string]datastore.EndpointRecovery, len(recoveries))
+	ids := make([]string, 0, len(recoveries))
+	for _, r := range recoveries {
+		if r.Attempts >= cfg.GetMaxAttempts() || now.Before(r.NextProbeAt) {
+			continue
+		}
+
+		due[r.EndpointID] = r
+		ids = append(ids, r.EndpointID)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	endpoints, err := endpointRepo.FindEndpointsByID(ctx, ids, projectID)
+	if err != nil {
+		return err
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		recovery := due[endpoint.UID]
+
+		// only a 2xx counts, a 404 or 401 from a deleted or misconfigured
+		// endpoint would have the breaker trip again right away
+		timeout := time.Duration(max(endpoint.HttpTimeout, convoy.HTTP_TIMEOUT)) * time.Second
+		_, err = dispatcher.PingStatus(ctx, endpoint.Url, timeout)
+		if err == nil {
+			err = endpointRepo.UpdateEndpointStatus(ctx, projectID, endpoint.UID, datastore.ActiveEndpointStatus)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Errorf("failed to re-enable endpoint %s", endpoint.UID)
+				continue
+			}
+
+			log.FromContext(ctx).Infof("re-enabled endpoint %s after %d failed probes", endpoint.UID, recovery.Attempts)
+			continue
+		}
+
+		recovery.Attempts++
+		recovery.NextProbeAt = now.Add(cfg.Backoff(recovery.Attempts))
+
+		err = endpointRepo.UpsertEndpointRecovery(ctx, &recovery)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("failed to record the probe of endpoint %s", endpoint.UID)
+		}
+	}
+
+	return nil
+}
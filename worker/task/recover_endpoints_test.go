@@ -0,0 +1,159 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/fflag"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecoverProjectEndpoints(t *testing.T) {
+	probes := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes[r.URL.Path]++
+		switch r.URL.Path {
+		case "/recovered":
+			w.WriteHeader(http.StatusOK)
+		case "/deleted":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	licenser.EXPECT().UseForwardProxy().Return(false).AnyTimes()
+
+	dispatcher, err := net.NewDispatcher(
+		licenser,
+		fflag.NewFFlag([]string{}),
+		net.LoggerOption(log.NewLogger(os.Stdout)),
+	)
+	require.NoError(t, err)
+
+	now := time.Now()
+	cfg := config.EndpointRecoveryConfiguration{IsEnabled: true, MaxAttempts: 3, InitialBackoff: 60, MaxBackoff: 600}
+
+	endpoint := func(id string) datastore.Endpoint {
+		return datastore.Endpoint{UID: id, ProjectID: "project-1", Url: server.URL + "/" + id, Status: datastore.InactiveEndpointStatus}
+	}
+
+	// only endpoints the breaker disabled have recoveries
+	endpointRepo.EXPECT().LoadEndpointRecoveries(gomock.Any(), "project-1").
+		Return([]datastore.EndpointRecovery{
+			{EndpointID: "recovered", ProjectID: "project-1", Attempts: 0, NextProbeAt: now},
+			{EndpointID: "deleted", ProjectID: "project-1", Attempts: 2, NextProbeAt: now.Add(-time.Second)},
+			{EndpointID: "failing", ProjectID: "project-1", Attempts: 1, NextProbeAt: now.Add(-time.Second)},
+			{EndpointID: "backing-off", ProjectID: "project-1", Attempts: 1, NextProbeAt: now.Add(time.Minute)},
+			{EndpointID: "exhausted", ProjectID: "project-1", Attempts: 3, NextProbeAt: now.Add(-time.Second)},
+		}, nil)
+
+	endpointRepo.EXPECT().FindEndpointsByID(gomock.Any(), []string{"recovered", "deleted", "failing"}, "project-1").
+		Return([]datastore.Endpoint{endpoint("recovered"), endpoint("deleted"), endpoint("failing")}, nil)
+
+	// the endpoint that answers the probe with a 2xx is re-enabled
+	endpointRepo.EXPECT().UpdateEndpointStatus(gomock.Any(), "project-1", "recovered", datastore.ActiveEndpointStatus).Return(nil)
+
+	// one answering with a 4xx isn't, it backs off like a failing one
+	endpointRepo.EXPECT().UpsertEndpointRecovery(gomock.Any(), &datastore.EndpointRecovery{
+		EndpointID:  "deleted",
+		ProjectID:   "project-1",
+		Attempts:    3,
+		NextProbeAt: now.Add(4 * time.Minute),
+	}).Return(nil)
+
+	// the one that still fails stays disabled and backs off further
+	endpointRepo.EXPECT().UpsertEndpointRecovery(gomock.Any(), &datastore.EndpointRecovery{
+		EndpointID:  "failing",
+		ProjectID:   "project-1",
+		Attempts:    2,
+		NextProbeAt: now.Add(2 * time.Minute),
+	}).Return(nil)
+
+	err = recoverProjectEndpoints(context.Background(), endpointRepo, dispatcher, cfg, "project-1", now)
+	require.NoError(t, err)
+
+	// endpoints backing off or out of probes aren't probed
+	require.Equal(t, map[string]int{"/recovered": 1, "/deleted": 1, "/failing": 1}, probes)
+}
+
+func TestRecoverProjectEndpoints_FirstProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+	licenser := mocks.NewMockLicenser(ctrl)
+	licenser.EXPECT().UseForwardProxy().Return(false).AnyTimes()
+
+	dispatcher, err := net.NewDispatcher(licenser, fflag.NewFFlag([]string{}), net.LoggerOption(log.NewLogger(os.Stdout)))
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	// the breaker just disabled the endpoint
+	endpointRepo.EXPECT().LoadEndpointRecoveries(gomock.Any(), "project-1").
+		Return([]datastore.EndpointRecovery{{EndpointID: "endpoint-1", ProjectID: "project-1", NextProbeAt: now}}, nil)
+	endpointRepo.EXPECT().FindEndpointsByID(gomock.Any(), []string{"endpoint-1"}, "project-1").
+		Return([]datastore.Endpoint{{UID: "endpoint-1", ProjectID: "project-1", Url: server.URL, Status: datastore.InactiveEndpointStatus}}, nil)
+
+	// so it's probed right away and stays disabled when it fails, with its
+	// next probe after the initial backoff
+	endpointRepo.EXPECT().UpsertEndpointRecovery(gomock.Any(), &datastore.EndpointRecovery{
+		EndpointID:  "endpoint-1",
+		ProjectID:   "project-1",
+		Attempts:    1,
+		NextProbeAt: now.Add(5 * time.Minute),
+	}).Return(nil)
+
+	err = recoverProjectEndpoints(context.Background(), endpointRepo, dispatcher, config.EndpointRecoveryConfiguration{IsEnabled: true}, "project-1", now)
+	require.NoError(t, err)
+}
+
+func TestRecoverProjectEndpoints_NoneDisabledByBreaker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+
+	// endpoints disabled by hand have no recoveries, so nothing is loaded or probed
+	endpointRepo.EXPECT().LoadEndpointRecoveries(gomock.Any(), "project-1").Return([]datastore.EndpointRecovery{}, nil)
+
+	err := recoverProjectEndpoints(context.Background(), endpointRepo, nil, config.EndpointRecoveryConfiguration{IsEnabled: true}, "project-1", time.Now())
+	require.NoError(t, err)
+}
+
+func TestDisableBreakerEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpointRepo := mocks.NewMockEndpointRepository(ctrl)
+
+	gomock.InOrder(
+		endpointRepo.EXPECT().UpdateEndpointStatus(gomock.Any(), "project-1", "endpoint-1", datastore.InactiveEndpointStatus).Return(nil),
+		endpointRepo.EXPECT().CreateEndpointRecovery(gomock.Any(), "project-1", "endpoint-1").Return(nil),
+	)
+
+	err := DisableBreakerEndpoint(context.Background(), endpointRepo, "project-1", "endpoint-1")
+	require.NoError(t, err)
+}
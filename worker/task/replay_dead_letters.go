@@ -0,0 +1,87 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/log"
+	"github.com/frain-dev/convoy/pkg/msgpack"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/hibiken/asynq"
+)
+
+// ReplayDeadLetters replays the dead-lettered deliveries of every project whose
+// replay policy is auto, once they have been dead for the project's window.
+// A replayed delivery that fails again becomes eligible after another window.
+func ReplayDeadLetters(projectRepo datastore.ProjectRepository, eventDeliveryRepo datastore.EventDeliveryRepository, q queue.Queuer) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		projects, err := projectRepo.LoadProjects(ctx, &datastore.ProjectFilter{})
+		if err != nil {
+			return err
+		}
+
+		for _, project := range projects {
+			if project.Config == nil {
+				continue
+			}
+
+			// without a window a replayed delivery that fails again would
+			// be replayed on every run, so auto replay is off until one is set
+			dlr := project.Config.GetDeadLetterReplayConfig()
+			if dlr.Policy != datastore.AutoDeadLetterReplayPolicy || dlr.AutoReplayAfterHours == 0 {
+				continue
+			}
+
+			failedBefore := time.Now().Add(-time.Duration(dlr.AutoReplayAfterHours) * time.Hour)
+			deliveries, err := eventDeliveryRepo.FindDeadLetteredEventDeliveries(ctx, project.UID, failedBefore)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Errorf("failed to load dead-lettered event deliveries for project %s", project.UID)
+				continue
+			}
+
+			for i := range deliveries {
+				err = ReplayDeadLetteredDelivery(ctx, eventDeliveryRepo, q, &deliveries[i])
+				if err != nil {
+					log.FromContext(ctx).WithError(err).Errorf("failed to replay dead-lettered event delivery %s", deliveries[i].UID)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// ReplayDeadLetteredDelivery resets the delivery's trials and re-enqueues it,
+// so it gets the project's full retry budget again.
+func ReplayDeadLetteredDelivery(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, q queue.Queuer, eventDelivery *datastore.EventDelivery) error {
+	eventDelivery.Status = datastore.ScheduledEventStatus
+	if eventDelivery.Metadata != nil {
+		eventDelivery.Metadata.NumTrials = 0
+		eventDelivery.Metadata.NextSendTime = time.Now()
+	}
+
+	err := eventDeliveryRepo.UpdateEventDeliveryMetadata(ctx, eventDelivery.ProjectID, eventDelivery)
+	if err != nil {
+		return err
+	}
+
+	payload := EventDelivery{
+		EventDeliveryID: eventDelivery.UID,
+		ProjectID:       eventDelivery.ProjectID,
+	}
+
+	data, err := msgpack.EncodeMsgPack(payload)
+	if err != nil {
+		return err
+	}
+
+	job := &queue.Job{
+		ID:      eventDelivery.UID,
+		Payload: data,
+		Delay:   1 * time.Second,
+	}
+
+	return q.Write(convoy.EventProcessor, convoy.EventQueue, job)
+}
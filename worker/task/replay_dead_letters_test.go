@@ -0,0 +1,86 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReplayDeadLetters(t *testing.T) {
+	autoProject := &datastore.Project{
+		UID: "project-auto",
+		Config: &datastore.ProjectConfig{
+			DeadLetterReplay: &datastore.DeadLetterReplayConfiguration{
+				Policy:               datastore.AutoDeadLetterReplayPolicy,
+				AutoReplayAfterHours: 6,
+			},
+		},
+	}
+
+	manualProject := &datastore.Project{
+		UID: "project-manual",
+		Config: &datastore.ProjectConfig{
+			DeadLetterReplay: &datastore.DeadLetterReplayConfiguration{Policy: datastore.ManualDeadLetterReplayPolicy},
+		},
+	}
+
+	// auto without a window is treated as disabled
+	noWindowProject := &datastore.Project{
+		UID: "project-no-window",
+		Config: &datastore.ProjectConfig{
+			DeadLetterReplay: &datastore.DeadLetterReplayConfiguration{Policy: datastore.AutoDeadLetterReplayPolicy},
+		},
+	}
+
+	neverProject := &datastore.Project{
+		UID: "project-never",
+		Config: &datastore.ProjectConfig{
+			DeadLetterReplay: &datastore.DeadLetterReplayConfiguration{Policy: datastore.NeverDeadLetterReplayPolicy},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectRepo := mocks.NewMockProjectRepository(ctrl)
+	eventDeliveryRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+
+	projectRepo.EXPECT().LoadProjects(gomock.Any(), gomock.Any()).
+		Return([]*datastore.Project{autoProject, manualProject, noWindowProject, neverProject}, nil)
+
+	// only the auto project is scanned, for deliveries dead for longer than its window
+	eventDeliveryRepo.EXPECT().FindDeadLetteredEventDeliveries(gomock.Any(), autoProject.UID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, failedBefore time.Time) ([]datastore.EventDelivery, error) {
+			require.WithinDuration(t, time.Now().Add(-6*time.Hour), failedBefore, time.Minute)
+
+			return []datastore.EventDelivery{
+				{
+					UID:       "ed-1",
+					ProjectID: autoProject.UID,
+					Status:    datastore.FailureEventStatus,
+					Metadata:  &datastore.Metadata{NumTrials: 5, RetryLimit: 5},
+				},
+			}, nil
+		})
+
+	eventDeliveryRepo.EXPECT().UpdateEventDeliveryMetadata(gomock.Any(), autoProject.UID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, ed *datastore.EventDelivery) error {
+			require.Equal(t, datastore.ScheduledEventStatus, ed.Status)
+			require.Equal(t, uint64(0), ed.Metadata.NumTrials)
+			return nil
+		})
+
+	q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Return(nil)
+
+	replay := ReplayDeadLetters(projectRepo, eventDeliveryRepo, q)
+	err := replay(context.Background(), asynq.NewTask(string(convoy.ReplayDeadLettersProcessor), nil))
+	require.NoError(t, err)
+}
@@ -76,7 +76,7 @@ func (r *RetentionPoliciesIntegrationTestSuite) Test_Should_Export_Two_Documents
 	err = r.ConvoyApp.eventRepo.PartitionEventsTable(context.Background())
 	require.NoError(r.T(), err)
 
-	err = r.ConvoyApp.eventDeliveryRepo.PartitionEventDeliveriesTable(context.Background())
+	err = r.ConvoyApp.eventDeliveryRepo.PartitionEventDeliveriesTable(context.Background(), datastore.DailyPartitionGranularity)
 	require.NoError(r.T(), err)
 
 	err = r.ConvoyApp.deliveryRepo.PartitionDeliveryAttemptsTable(context.Background())
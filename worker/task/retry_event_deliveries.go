@@ -16,7 +16,9 @@ import (
 	"github.com/frain-dev/convoy/util"
 )
 
-func RetryEventDeliveries(db database.Database, eventQueue queue.Queuer, statuses []datastore.EventDeliveryStatus, lookBackDuration string, eventId string) {
+// RetryEventDeliveries requeues the deliveries with any of statuses created within
+// lookBackDuration, optionally scoped to a single event or event type
+func RetryEventDeliveries(db database.Database, eventQueue queue.Queuer, statuses []datastore.EventDeliveryStatus, lookBackDuration string, eventId string, eventType string) {
 	if len(statuses) == 1 && util.IsStringEmpty(string(statuses[0])) {
 		statuses = []datastore.EventDeliveryStatus{"Retry", "Scheduled", "Processing"}
 	}
@@ -33,6 +35,7 @@ func RetryEventDeliveries(db database.Database, eventQueue queue.Queuer, statuse
 	now := time.Now()
 	then := now.Add(-d)
 
+	total := 0
 	for _, status := range statuses {
 		log.Printf("Searching for events with status %s", status)
 		searchParams := datastore.SearchParams{
@@ -61,16 +64,22 @@ func RetryEventDeliveries(db database.Database, eventQueue queue.Queuer, statuse
 		wg.Add(1)
 		eventDeliveryRepo := postgres.NewEventDeliveryRepo(db)
 
-		go processEventDeliveryBatch(ctx, status, eventDeliveryRepo, deliveryChan, q, &wg)
+		requeued := 0
+		go processEventDeliveryBatch(ctx, status, eventDeliveryRepo, deliveryChan, q, &wg, &requeued)
 
-		counter, err := eventDeliveryRepo.CountDeliveriesByStatus(ctx, "", status, searchParams)
+		var counter int64
+		if util.IsStringEmpty(eventType) {
+			counter, err = eventDeliveryRepo.CountDeliveriesByStatus(ctx, "", status, searchParams)
+		} else {
+			counter, err = eventDeliveryRepo.CountDeliveriesByEventType(ctx, "", eventType, status, searchParams)
+		}
 		if err != nil {
 			log.Error("Failed to count event deliveries")
 		}
 		log.Infof("Total number of event deliveries to requeue is %d", counter)
 
 		for {
-			deliveries, pagination, err := eventDeliveryRepo.LoadEventDeliveriesPaged(ctx, "", []string{}, eventId, "", []datastore.EventDeliveryStatus{status}, searchParams, pageable, "", "")
+			deliveries, pagination, err := eventDeliveryRepo.LoadEventDeliveriesPaged(ctx, "", []string{}, eventId, "", []datastore.EventDeliveryStatus{status}, searchParams, pageable, "", eventType, nil, datastore.PreferReplica)
 			if err != nil {
 				log.WithError(err).Errorf("successfully fetched %d event deliveries but with error", count)
 				close(deliveryChan)
@@ -93,10 +102,19 @@ func RetryEventDeliveries(db database.Database, eventQueue queue.Queuer, statuse
 
 		log.Info("waiting for batch processor to finish")
 		wg.Wait()
+
+		log.Infof("Requeued %d event deliveries with status %s", requeued, status)
+		total += requeued
+	}
+
+	if util.IsStringEmpty(eventType) {
+		log.Infof("Requeued %d event deliveries in total", total)
+	} else {
+		log.Infof("Requeued %d event deliveries of event type %s in total", total, eventType)
 	}
 }
 
-func processEventDeliveryBatch(ctx context.Context, status datastore.EventDeliveryStatus, eventDeliveryRepo datastore.EventDeliveryRepository, deliveryChan <-chan []datastore.EventDelivery, q *redisqueue.RedisQueue, wg *sync.WaitGroup) {
+func processEventDeliveryBatch(ctx context.Context, status datastore.EventDeliveryStatus, eventDeliveryRepo datastore.EventDeliveryRepository, deliveryChan <-chan []datastore.EventDelivery, q *redisqueue.RedisQueue, wg *sync.WaitGroup, requeued *int) {
 	defer wg.Done()
 
 	batchCount := 1
@@ -150,7 +168,9 @@ func processEventDeliveryBatch(ctx context.Context, status datastore.EventDelive
 			err = q.Write(taskName, convoy.EventQueue, job)
 			if err != nil {
 				log.WithError(err).Errorf("batch %d: failed to send event delivery %s to the queue", batchCount, delivery.UID)
+				continue
 			}
+			*requeued++
 			log.Infof("successfully re-queued delivery with id: %s", delivery.UID)
 		}
 
@@ -0,0 +1,26 @@
+package task
+
+import (
+	"math/rand"
+	"time"
+)
+
+// retryJitter returns a random number in [0, 1) to jitter retries with,
+// tests swap it for a seeded source to get the same jitter every run
+var retryJitter = rand.Float64
+
+// jitterDelay moves d randomly by up to percent of it either way, so
+// deliveries that failed together spread their retries out instead of
+// hitting the endpoint again all at once. A percent of 0 returns d as is.
+func jitterDelay(d time.Duration, percent uint64, random func() float64) time.Duration {
+	if percent == 0 || d <= 0 {
+		return d
+	}
+
+	if percent > 100 {
+		percent = 100
+	}
+
+	spread := float64(d) * float64(percent) / 100
+	return d + time.Duration(spread*(2*random()-1))
+}
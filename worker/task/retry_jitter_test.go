@@ -0,0 +1,81 @@
+package task
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+func TestJitterDelay(t *testing.T) {
+	constant := func(v float64) func() float64 {
+		return func() float64 { return v }
+	}
+
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		percent uint64
+		random  float64
+		want    time.Duration
+	}{
+		{name: "no jitter", delay: 10 * time.Second, percent: 0, random: 0, want: 10 * time.Second},
+		{name: "lowest", delay: 10 * time.Second, percent: 20, random: 0, want: 8 * time.Second},
+		{name: "middle", delay: 10 * time.Second, percent: 20, random: 0.5, want: 10 * time.Second},
+		{name: "higher", delay: 10 * time.Second, percent: 20, random: 0.75, want: 11 * time.Second},
+		{name: "percent is capped at 100", delay: 10 * time.Second, percent: 150, random: 0, want: 0},
+		{name: "no delay", delay: 0, percent: 50, random: 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, jitterDelay(tt.delay, tt.percent, constant(tt.random)))
+		})
+	}
+}
+
+func TestNextRetryDelay_Jitter(t *testing.T) {
+	defer func(r func() float64) { retryJitter = r }(retryJitter)
+
+	project := &datastore.Project{Config: &datastore.ProjectConfig{
+		Strategy: &datastore.StrategyConfiguration{Type: datastore.LinearStrategyProvider, Duration: 100, JitterPercent: 10},
+	}}
+
+	delays := func(seed int64) []time.Duration {
+		retryJitter = rand.New(rand.NewSource(seed)).Float64
+
+		var d []time.Duration
+		for i := 0; i < 20; i++ {
+			eventDelivery := &datastore.EventDelivery{UID: "delivery-1", Metadata: &datastore.Metadata{Strategy: datastore.LinearStrategyProvider, IntervalSeconds: 100}}
+			d = append(d, nextRetryDelay(context.Background(), eventDelivery, project, 0))
+		}
+		return d
+	}
+
+	first := delays(42)
+
+	// the same seed jitters the same way
+	require.Equal(t, first, delays(42))
+
+	// deliveries failing together are spread across ±10% of the delay
+	seen := map[time.Duration]bool{}
+	for _, d := range first {
+		require.GreaterOrEqual(t, d, 90*time.Second)
+		require.LessOrEqual(t, d, 110*time.Second)
+		seen[d] = true
+	}
+	require.Greater(t, len(seen), 1)
+
+	// jitter never takes a delay past the max retry seconds
+	retryJitter = func() float64 { return 0.99 }
+	eventDelivery := &datastore.EventDelivery{UID: "delivery-1", Metadata: &datastore.Metadata{Strategy: datastore.LinearStrategyProvider, IntervalSeconds: 100}}
+	require.Equal(t, 100*time.Second, nextRetryDelay(context.Background(), eventDelivery, project, 100))
+
+	// without a jitter percent the delay is left as computed
+	project.Config.Strategy.JitterPercent = 0
+	require.Equal(t, []time.Duration{100 * time.Second, 100 * time.Second}, delays(42)[:2])
+}
@@ -44,6 +44,22 @@ func (e *RateLimitError) Delay() time.Duration {
 func (e *RateLimitError) RateLimit() {
 }
 
+// PausedError is returned while a delivery's subscription is paused. It
+// isn't counted as a failed attempt, so the delivery is held for however
+// long the pause lasts rather than running out of retries.
+type PausedError struct {
+	delay time.Duration
+	Err   error
+}
+
+func (e *PausedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PausedError) Delay() time.Duration {
+	return e.delay
+}
+
 func GetRetryDelay(n int, err error, t *asynq.Task) time.Duration {
 	if endpointError, ok := err.(*EndpointError); ok {
 		return endpointError.Delay()
@@ -54,6 +70,9 @@ func GetRetryDelay(n int, err error, t *asynq.Task) time.Duration {
 	if circuitBreakerError, ok := err.(*CircuitBreakerError); ok {
 		return circuitBreakerError.Delay()
 	}
+	if pausedError, ok := err.(*PausedError); ok {
+		return pausedError.Delay()
+	}
 
 	return asynq.DefaultRetryDelayFunc(n, err, t)
 }
@@ -65,6 +84,13 @@ type SignatureValues struct {
 type EventDelivery struct {
 	EventDeliveryID string
 	ProjectID       string
+
+	// ManualRetry is set when a user explicitly retried the delivery
+	ManualRetry bool
+
+	// Claimed is set when the stuck delivery poller claimed the delivery,
+	// which moves it to processing before it's queued
+	Claimed bool
 }
 
 type EventDeliveryConfig struct {
@@ -74,9 +100,11 @@ type EventDeliveryConfig struct {
 }
 
 type RetryConfig struct {
-	Type       datastore.StrategyProvider
-	Duration   uint64
-	RetryCount uint64
+	Type        datastore.StrategyProvider
+	Duration    uint64
+	RetryCount  uint64
+	BackoffBase float64
+	MaxInterval uint64
 }
 
 type RateLimitConfig struct {
@@ -90,6 +118,8 @@ func (ec *EventDeliveryConfig) RetryConfig() (*RetryConfig, error) {
 	rc.Duration = ec.project.Config.Strategy.Duration
 	rc.RetryCount = ec.project.Config.Strategy.RetryCount
 	rc.Type = ec.project.Config.Strategy.Type
+	rc.BackoffBase = ec.project.Config.Strategy.BackoffBase
+	rc.MaxInterval = ec.project.Config.Strategy.MaxInterval
 
 	return rc, nil
 }